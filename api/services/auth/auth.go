@@ -1,22 +1,46 @@
-// Package auth represent the encore application.
+// Package auth represent the encore application. It is the authentication
+// gateway for the system: it owns the keystore and the authhandler, and other
+// services call its private Authorize API instead of copying that wiring.
 package auth
 
 import (
 	"context"
 	"errors"
 	"fmt"
+	"net/mail"
+	"os"
 	"runtime"
+	"strings"
+	"time"
 
 	"encore.dev"
 	esqldb "encore.dev/storage/sqldb"
 	"github.com/ardanlabs/conf/v3"
+	"github.com/ardanlabs/encore/app/domain/apikeyapp"
+	"github.com/ardanlabs/encore/app/domain/oauthapp"
+	"github.com/ardanlabs/encore/app/domain/permissionapp"
+	"github.com/ardanlabs/encore/app/domain/userapp"
+	"github.com/ardanlabs/encore/app/sdk/allowlist"
 	"github.com/ardanlabs/encore/app/sdk/auth"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/limiter"
+	"github.com/ardanlabs/encore/business/domain/apikeybus"
+	"github.com/ardanlabs/encore/business/domain/apikeybus/stores/apikeydb"
+	"github.com/ardanlabs/encore/business/domain/oauthbus"
+	"github.com/ardanlabs/encore/business/domain/oauthbus/stores/oauthdb"
+	"github.com/ardanlabs/encore/business/domain/tokenbus"
+	"github.com/ardanlabs/encore/business/domain/tokenbus/stores/tokendb"
 	"github.com/ardanlabs/encore/business/domain/userbus"
 	"github.com/ardanlabs/encore/business/domain/userbus/stores/userdb"
 	"github.com/ardanlabs/encore/business/sdk/delegate"
 	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/business/sdk/storecache"
+	"github.com/ardanlabs/encore/foundation/clock"
+	"github.com/ardanlabs/encore/foundation/email"
 	"github.com/ardanlabs/encore/foundation/keystore"
 	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/ardanlabs/encore/foundation/oidc"
+	"github.com/ardanlabs/encore/foundation/web"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -36,22 +60,68 @@ var secrets struct {
 //
 //encore:service
 type Service struct {
-	log     *logger.Logger
-	db      *sqlx.DB
-	auth    *auth.Auth
-	userBus *userbus.Business
+	log       *logger.Logger
+	db        *sqlx.DB
+	auth      *auth.Auth
+	userBus   *userbus.Business
+	apiKeyBus *apikeybus.Business
+	userApp   *userapp.App
+	apiKeyApp *apikeyapp.App
+	permApp   *permissionapp.App
+	oauthApp  *oauthapp.App
+	proxies   *allowlist.List
+	resetAddr *limiter.Window
+	resetIP   *limiter.Window
+}
+
+// Config represents the settings of the service that aren't part of auth.
+// Refresh tokens can be used for the refresh ttl after they are issued. Users
+// can log in with the OIDC providers, and are provisioned the first time they
+// do when OIDC provision is set. Password reset links are sent with the email
+// sender, which logs them when it isn't set. Each email address and client
+// can ask for up to their limit of links in the password reset window. The
+// client is taken from the hops the trusted proxies appended to the
+// X-Forwarded-For header.
+type Config struct {
+	RefreshTTL          time.Duration
+	OIDCProviders       []oidc.Provider
+	OIDCProvision       bool
+	EmailSender         email.Sender
+	PasswordResetURL    string
+	PasswordResetWindow time.Duration
+	PasswordResetAddr   int
+	PasswordResetIP     int
+	TrustedProxies      *allowlist.List
 }
 
 // NewService is called to create a new encore Service.
-func NewService(log *logger.Logger, db *sqlx.DB, ath *auth.Auth) (*Service, error) {
+func NewService(log *logger.Logger, db *sqlx.DB, ath *auth.Auth, cfg Config) (*Service, error) {
 	delegate := delegate.New(log)
-	userBus := userbus.NewBusiness(log, delegate, userdb.NewStore(log, db))
+	userBus := userbus.NewBusiness(log, clock.System{}, delegate, userdb.NewStore(log, db))
+	tokenBus := tokenbus.NewBusiness(log, clock.System{}, cfg.RefreshTTL, tokendb.NewStore(log, db))
+	apiKeyBus := apikeybus.NewBusiness(log, clock.System{}, userBus, apikeydb.NewStore(log, db))
+	oauthBus := oauthbus.NewBusiness(log, clock.System{}, userBus, oauthdb.NewStore(log, db))
+
+	sender := cfg.EmailSender
+	if sender == nil {
+		sender = email.NewLogSender(log)
+	}
+
+	userApp := userapp.NewAppWithAuth(log, userBus, tokenBus, apiKeyBus, ath, sender, cfg.PasswordResetURL)
 
 	s := Service{
-		log:     log,
-		db:      db,
-		auth:    ath,
-		userBus: userBus,
+		log:       log,
+		db:        db,
+		auth:      ath,
+		userBus:   userBus,
+		apiKeyBus: apiKeyBus,
+		userApp:   userApp,
+		apiKeyApp: apikeyapp.NewApp(apiKeyBus),
+		permApp:   permissionapp.NewApp(ath.Permissions()),
+		oauthApp:  oauthapp.NewApp(oauthBus, userApp, ath, cfg.OIDCProvision, cfg.OIDCProviders...),
+		proxies:   cfg.TrustedProxies,
+		resetAddr: limiter.NewWindow(clock.System{}, cfg.PasswordResetAddr, cfg.PasswordResetWindow),
+		resetIP:   limiter.NewWindow(clock.System{}, cfg.PasswordResetIP, cfg.PasswordResetWindow),
 	}
 
 	return &s, nil
@@ -76,15 +146,15 @@ func (s *Service) Shutdown(force context.Context) {
 func initService() (*Service, error) {
 	log := logger.New("auth")
 
-	db, auth, err := startup(log)
+	db, auth, cfg, err := startup(log)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewService(log, db, auth)
+	return NewService(log, db, auth, cfg)
 }
 
-func startup(log *logger.Logger) (*sqlx.DB, *auth.Auth, error) {
+func startup(log *logger.Logger) (*sqlx.DB, *auth.Auth, Config, error) {
 	ctx := context.Background()
 
 	// -------------------------------------------------------------------------
@@ -98,13 +168,61 @@ func startup(log *logger.Logger) (*sqlx.DB, *auth.Auth, error) {
 	cfg := struct {
 		conf.Version
 		Auth struct {
-			ActiveKID string `conf:"default:54bb2165-71e1-41a6-af3e-7da4a0e1e2c1"`
-			Issuer    string `conf:"default:service project"`
+			ActiveKID   string `conf:"default:54bb2165-71e1-41a6-af3e-7da4a0e1e2c1"`
+			KeysFolder  string
+			KeysEnv     string
+			KeyValidity []string
+			Issuer      string        `conf:"default:service project"`
+			TokenTTL    time.Duration `conf:"default:15m"`
+			RefreshTTL  time.Duration `conf:"default:720h"`
 		}
 		DB struct {
 			MaxIdleConns int `conf:"default:0"`
 			MaxOpenConns int `conf:"default:0"`
 		}
+		UserCache struct {
+			TTL        time.Duration `conf:"default:10m"`
+			Capacity   int           `conf:"default:10000"`
+			Shards     int           `conf:"default:10"`
+			RefreshMin time.Duration `conf:"default:2m"`
+			RefreshMax time.Duration `conf:"default:5m"`
+			MissingTTL time.Duration `conf:"default:10s"`
+		}
+		RevocationCache struct {
+			TTL      time.Duration `conf:"default:1m"`
+			Capacity int           `conf:"default:10000"`
+			Shards   int           `conf:"default:10"`
+		}
+		PermissionCache struct {
+			TTL time.Duration `conf:"default:1m"`
+		}
+		PasswordReset struct {
+			URL       string        `conf:"default:http://localhost:4000/reset-password"`
+			Window    time.Duration `conf:"default:1h"`
+			AddrLimit int           `conf:"default:3"`
+			IPLimit   int           `conf:"default:20"`
+		}
+		SMTP struct {
+			Host     string
+			Port     int `conf:"default:587"`
+			Username string
+			Password string `conf:"mask"`
+			From     string `conf:"default:noreply@example.com"`
+		}
+		OIDC struct {
+			Provision bool `conf:"default:true"`
+			Google    struct {
+				ClientID     string
+				ClientSecret string `conf:"mask"`
+				RedirectURL  string `conf:"default:http://localhost:4000/v1/auth/oidc/callback"`
+			}
+			GitHub struct {
+				ClientID     string
+				ClientSecret string `conf:"mask"`
+				RedirectURL  string `conf:"default:http://localhost:4000/v1/auth/oidc/callback"`
+			}
+		}
+		TrustedProxies []string
 	}{
 		Version: conf.Version{
 			Build: encore.Meta().Environment.Name,
@@ -117,9 +235,9 @@ func startup(log *logger.Logger) (*sqlx.DB, *auth.Auth, error) {
 	if err != nil {
 		if errors.Is(err, conf.ErrHelpWanted) {
 			fmt.Println(help)
-			return nil, nil, err
+			return nil, nil, Config{}, err
 		}
-		return nil, nil, fmt.Errorf("parsing config: %w", err)
+		return nil, nil, Config{}, fmt.Errorf("parsing config: %w", err)
 	}
 
 	// -------------------------------------------------------------------------
@@ -129,7 +247,7 @@ func startup(log *logger.Logger) (*sqlx.DB, *auth.Auth, error) {
 
 	out, err := conf.String(&cfg)
 	if err != nil {
-		return nil, nil, fmt.Errorf("generating config for output: %w", err)
+		return nil, nil, Config{}, fmt.Errorf("generating config for output: %w", err)
 	}
 	log.Info(ctx, "initService", "config", out)
 
@@ -144,7 +262,7 @@ func startup(log *logger.Logger) (*sqlx.DB, *auth.Auth, error) {
 		MaxOpenConns: cfg.DB.MaxOpenConns,
 	})
 	if err != nil {
-		return nil, nil, fmt.Errorf("connecting to db: %w", err)
+		return nil, nil, Config{}, fmt.Errorf("connecting to db: %w", err)
 	}
 
 	// -------------------------------------------------------------------------
@@ -152,26 +270,188 @@ func startup(log *logger.Logger) (*sqlx.DB, *auth.Auth, error) {
 
 	log.Info(ctx, "initService", "status", "initializing authentication support")
 
-	// Load the private keys files from disk. We can assume some system like
-	// Vault has created these files already. How that happens is not our
-	// concern.
+	// The private key is kept as an Encore secret. How it gets there is not
+	// our concern.
 
 	ks := keystore.New()
-	if err := ks.LoadKey(secrets.KeyID, secrets.KeyPEM); err != nil {
-		return nil, nil, fmt.Errorf("reading keys: %w", err)
+	if err := ks.LoadBySecrets(ctx, encoreSecrets{}, secrets.KeyID); err != nil {
+		return nil, nil, Config{}, fmt.Errorf("reading keys: %w", err)
+	}
+
+	// Keys are rotated by adding the new key to the keys folder, or to the
+	// environment under the keys prefix. The newest active key signs the
+	// tokens, and the others verify the tokens they signed until they
+	// expire. The validity of a key is set as kid|activeFrom|expires with
+	// RFC3339 times, where either time can be left empty.
+
+	if cfg.Auth.KeysFolder != "" {
+		n, err := ks.LoadByFS(os.DirFS(cfg.Auth.KeysFolder))
+		if err != nil {
+			return nil, nil, Config{}, fmt.Errorf("reading keys folder: %w", err)
+		}
+		log.Info(ctx, "initService", "status", "keys loaded", "folder", cfg.Auth.KeysFolder, "count", n)
+	}
+
+	if cfg.Auth.KeysEnv != "" {
+		n, err := ks.LoadByEnv(cfg.Auth.KeysEnv)
+		if err != nil {
+			return nil, nil, Config{}, fmt.Errorf("reading keys environment: %w", err)
+		}
+		log.Info(ctx, "initService", "status", "keys loaded", "prefix", cfg.Auth.KeysEnv, "count", n)
+	}
+
+	for _, v := range cfg.Auth.KeyValidity {
+		if err := setKeyValidity(ks, v); err != nil {
+			return nil, nil, Config{}, fmt.Errorf("setting key validity: %w", err)
+		}
 	}
 
 	authCfg := auth.Config{
 		Log:       log,
 		DB:        db,
 		KeyLookup: ks,
+		ActiveKID: cfg.Auth.ActiveKID,
 		Issuer:    cfg.Auth.Issuer,
+		TokenTTL:  cfg.Auth.TokenTTL,
+		UserCache: storecache.Config{
+			TTL:        cfg.UserCache.TTL,
+			Capacity:   cfg.UserCache.Capacity,
+			Shards:     cfg.UserCache.Shards,
+			RefreshMin: cfg.UserCache.RefreshMin,
+			RefreshMax: cfg.UserCache.RefreshMax,
+			MissingTTL: cfg.UserCache.MissingTTL,
+		},
+		RevocationCache: storecache.Config{
+			TTL:      cfg.RevocationCache.TTL,
+			Capacity: cfg.RevocationCache.Capacity,
+			Shards:   cfg.RevocationCache.Shards,
+		},
+		PermissionCache: storecache.Config{
+			TTL: cfg.PermissionCache.TTL,
+		},
+		CacheCounters: newCacheMetrics(),
 	}
 
 	auth, err := auth.New(authCfg)
 	if err != nil {
-		return nil, nil, fmt.Errorf("constructing auth: %w", err)
+		return nil, nil, Config{}, fmt.Errorf("constructing auth: %w", err)
+	}
+
+	log.Info(ctx, "initService", "status", "signing key selected", "kid", auth.ActiveKID())
+
+	// -------------------------------------------------------------------------
+	// OIDC Support
+
+	// A provider can only be logged in with once the client registered
+	// with it is configured.
+
+	var providers []oidc.Provider
+
+	if cfg.OIDC.Google.ClientID != "" {
+		providers = append(providers, oidc.NewGoogle(oidc.Config{
+			ClientID:     cfg.OIDC.Google.ClientID,
+			ClientSecret: cfg.OIDC.Google.ClientSecret,
+			RedirectURL:  cfg.OIDC.Google.RedirectURL,
+		}))
+	}
+
+	if cfg.OIDC.GitHub.ClientID != "" {
+		providers = append(providers, oidc.NewGitHub(oidc.Config{
+			ClientID:     cfg.OIDC.GitHub.ClientID,
+			ClientSecret: cfg.OIDC.GitHub.ClientSecret,
+			RedirectURL:  cfg.OIDC.GitHub.RedirectURL,
+		}))
+	}
+
+	for _, p := range providers {
+		log.Info(ctx, "initService", "status", "oidc provider enabled", "provider", p.Name())
+	}
+
+	// -------------------------------------------------------------------------
+	// Email Support
+
+	// Emails are only sent once an SMTP server is configured, until then
+	// they are written to the log.
+
+	var sender email.Sender = email.NewLogSender(log)
+
+	if cfg.SMTP.Host != "" {
+		from, err := mail.ParseAddress(cfg.SMTP.From)
+		if err != nil {
+			return nil, nil, Config{}, fmt.Errorf("parsing smtp from: %w", err)
+		}
+
+		sender = email.NewSMTP(email.SMTPConfig{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     *from,
+		})
+	}
+
+	// -------------------------------------------------------------------------
+	// Client Support
+
+	// The client is taken from the X-Forwarded-For header only for the hops
+	// appended by the proxies in front of the service, since a client can
+	// write anything in it. None are trusted by default.
+
+	proxies, err := allowlist.Parse(cfg.TrustedProxies)
+	if err != nil {
+		return nil, nil, Config{}, fmt.Errorf("parsing trusted proxies: %w", err)
+	}
+
+	// The errors of the raw endpoints are written like the others.
+	web.SetErrorHandler(errs.HTTPError)
+
+	svcCfg := Config{
+		RefreshTTL:          cfg.Auth.RefreshTTL,
+		OIDCProviders:       providers,
+		OIDCProvision:       cfg.OIDC.Provision,
+		EmailSender:         sender,
+		PasswordResetURL:    cfg.PasswordReset.URL,
+		PasswordResetWindow: cfg.PasswordReset.Window,
+		PasswordResetAddr:   cfg.PasswordReset.AddrLimit,
+		PasswordResetIP:     cfg.PasswordReset.IPLimit,
+		TrustedProxies:      proxies,
+	}
+
+	return db, auth, svcCfg, nil
+}
+
+// encoreSecrets looks up the private keys kept as Encore secrets.
+type encoreSecrets struct{}
+
+// Secret implements the keystore.SecretManager interface.
+func (encoreSecrets) Secret(ctx context.Context, name string) (string, error) {
+	if name != secrets.KeyID {
+		return "", fmt.Errorf("secret[%s] not found", name)
+	}
+
+	return secrets.KeyPEM, nil
+}
+
+// setKeyValidity sets the validity of a key from its config value, written
+// as kid|activeFrom|expires with RFC3339 times.
+func setKeyValidity(ks *keystore.KeyStore, value string) error {
+	parts := strings.Split(value, "|")
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid key validity %q: expected kid|activeFrom|expires", value)
+	}
+
+	var times [2]time.Time
+	for i, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, part)
+		if err != nil {
+			return fmt.Errorf("invalid key validity %q: %w", value, err)
+		}
+		times[i] = t
 	}
 
-	return db, auth, nil
+	return ks.SetValidity(parts[0], times[0], times[1])
 }
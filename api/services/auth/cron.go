@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"encore.dev/cron"
+)
+
+// Refresh tokens are removed once they have expired, since they can no
+// longer be traded for a token.
+var _ = cron.NewJob("refresh-token-cleanup", cron.JobConfig{
+	Title:    "Delete expired refresh tokens",
+	Schedule: "45 * * * *",
+	Endpoint: UserTokenDeleteExpired,
+})
+
+// The states of logins with a provider that were never finished are removed
+// once they have expired.
+var _ = cron.NewJob("oidc-state-cleanup", cron.JobConfig{
+	Title:    "Delete expired OIDC login states",
+	Schedule: "15 * * * *",
+	Endpoint: OIDCDeleteExpired,
+})
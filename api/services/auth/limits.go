@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"context"
+	"net/mail"
+	"strings"
+
+	"encore.dev"
+	"github.com/ardanlabs/encore/app/sdk/allowlist"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+)
+
+// limitPasswordReset counts a request for a password reset link against the
+// client that made it and the email it's for, so neither can be used to
+// flood an inbox or the sender. Every email is counted, registered or not,
+// so the limit doesn't tell the caller which emails are registered.
+func (s *Service) limitPasswordReset(ctx context.Context, addr string) error {
+	ip := allowlist.ForwardedIP(encore.CurrentRequest().Headers, s.proxies)
+
+	if st, err := s.resetIP.Allow(ip); err != nil {
+		s.log.Warn(ctx, "password reset", "status", "rate limited", "ip", ip)
+		return errs.NewRateLimited(errs.ResourceExhausted, errs.NewRateLimit(st.Limit, st.Remaining, st.Reset), err)
+	}
+
+	// An address can be written many ways, like with a display name, so the
+	// limit is on the address itself.
+	key := strings.ToLower(strings.TrimSpace(addr))
+	if a, err := mail.ParseAddress(addr); err == nil {
+		key = strings.ToLower(a.Address)
+	}
+
+	if st, err := s.resetAddr.Allow(key); err != nil {
+		s.log.Warn(ctx, "password reset", "status", "rate limited", "ip", ip)
+		return errs.NewRateLimited(errs.ResourceExhausted, errs.NewRateLimit(st.Limit, st.Remaining, st.Reset), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	emetrics "encore.dev/metrics"
+	"github.com/ardanlabs/encore/app/sdk/metrics"
+)
+
+// Encore currently requires these metrics to be declared in the same package
+// as the service type.
+//
+//lint:ignore U1000 "used by encore"
+var (
+	cacheHits      = emetrics.NewCounterGroup[metrics.CacheLabels, uint64]("cache_hits", emetrics.CounterConfig{})
+	cacheMisses    = emetrics.NewCounterGroup[metrics.CacheLabels, uint64]("cache_misses", emetrics.CounterConfig{})
+	cacheEvictions = emetrics.NewCounterGroup[metrics.CacheLabels, uint64]("cache_evictions", emetrics.CounterConfig{})
+)
+
+// newCacheMetrics constructs the counters the caches of the business layer
+// report to, since business layer packages can't import app layer packages.
+func newCacheMetrics() *metrics.Caches {
+	return metrics.NewCaches(metrics.CacheConfig{
+		Hits:      cacheHits,
+		Misses:    cacheMisses,
+		Evictions: cacheEvictions,
+	})
+}
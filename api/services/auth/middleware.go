@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"encore.dev/middleware"
+	"github.com/ardanlabs/encore/app/sdk/mid"
+)
+
+// =============================================================================
+// Global middleware functions
+
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=all
+func (s *Service) errors(req middleware.Request, next middleware.Next) middleware.Response {
+	return mid.Errors(s.log, req, next)
+}
+
+// =============================================================================
+// Authorization middleware functions
+
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=tag:authorize_permission
+func (s *Service) authorizePermission(req middleware.Request, next middleware.Next) middleware.Response {
+	return mid.AuthorizePermission(authPermissions, req, next)
+}
@@ -2,16 +2,24 @@ package auth
 
 import (
 	"context"
+	"net/http"
 	"strings"
 
 	eauth "encore.dev/beta/auth"
+	"github.com/ardanlabs/encore/app/domain/apikeyapp"
+	"github.com/ardanlabs/encore/app/domain/oauthapp"
+	"github.com/ardanlabs/encore/app/domain/permissionapp"
+	"github.com/ardanlabs/encore/app/domain/userapp"
 	"github.com/ardanlabs/encore/app/sdk/auth"
 	"github.com/ardanlabs/encore/app/sdk/errs"
 	"github.com/ardanlabs/encore/app/sdk/mid"
+	"github.com/ardanlabs/encore/business/sdk/cachemetrics"
+	"github.com/ardanlabs/encore/foundation/web"
+	"github.com/google/uuid"
 )
 
 // =============================================================================
-// JWT or Basic Athentication handling
+// JWT, Basic or API key Athentication handling
 
 type authParams struct {
 	Authorization string `header:"Authorization"`
@@ -28,6 +36,9 @@ func (s *Service) AuthHandler(ctx context.Context, ap *authParams) (eauth.UID, *
 
 	case "Basic":
 		return mid.Basic(ctx, s.auth, s.userBus, ap.Authorization)
+
+	case "ApiKey":
+		return mid.APIKey(ctx, s.auth, s.apiKeyBus, ap.Authorization)
 	}
 
 	return "", nil, errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action")
@@ -36,23 +47,87 @@ func (s *Service) AuthHandler(ctx context.Context, ap *authParams) (eauth.UID, *
 // =============================================================================
 // Auth related APIs
 
-type token struct {
-	Token string `json:"token"`
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=GET path=/v1/token
+func (s *Service) UserTokenActive(ctx context.Context) (userapp.Token, error) {
+	claims := eauth.Data().(*auth.Claims)
+
+	return s.userApp.Token(ctx, s.auth.ActiveKID(), *claims)
 }
 
 //lint:ignore U1000 "called by encore"
 //encore:api auth method=GET path=/v1/token/:kid
-func (s *Service) UserToken(ctx context.Context, kid string) (token, error) {
+func (s *Service) UserToken(ctx context.Context, kid string) (userapp.Token, error) {
 
 	// The BearerBasic middleware function generates the claims.
 	claims := eauth.Data().(*auth.Claims)
 
-	tkn, err := s.auth.GenerateToken(kid, *claims)
+	return s.userApp.Token(ctx, kid, *claims)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api public method=POST path=/v1/auth/refresh
+func (s *Service) UserTokenRefresh(ctx context.Context, app userapp.RefreshToken) (userapp.Token, error) {
+	return s.userApp.Refresh(ctx, app)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api public method=POST path=/v1/auth/revoke
+func (s *Service) UserTokenRevoke(ctx context.Context, app userapp.RefreshToken) error {
+	return s.userApp.Revoke(ctx, app)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=POST path=/v1/auth/logout
+func (s *Service) UserLogout(ctx context.Context) error {
+	claims := eauth.Data().(*auth.Claims)
+
+	return s.userApp.Logout(ctx, *claims)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=POST path=/v1/auth/users/:userID/logout
+func (s *Service) UserForceLogout(ctx context.Context, userID string) error {
+	claims := eauth.Data().(*auth.Claims)
+
+	if err := s.auth.Authorize(ctx, *claims, uuid.Nil, auth.RuleAdminOnly); err != nil {
+		return errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[%v] rule[%v]: %s", claims.Roles, auth.RuleAdminOnly, err)
+	}
+
+	return s.userApp.ForceLogout(ctx, userID)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api public method=POST path=/v1/auth/password/forgot
+func (s *Service) UserPasswordForgot(ctx context.Context, app userapp.PasswordResetRequest) error {
+	if err := s.limitPasswordReset(ctx, app.Email); err != nil {
+		return err
+	}
+
+	return s.userApp.RequestPasswordReset(ctx, app)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api public method=POST path=/v1/auth/password/reset
+func (s *Service) UserPasswordReset(ctx context.Context, app userapp.PasswordReset) error {
+	return s.userApp.ResetPassword(ctx, app)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api private method=POST path=/v1/auth/expire
+func (s *Service) UserTokenDeleteExpired(ctx context.Context) error {
+	return s.userApp.DeleteExpiredTokens(ctx)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api private method=GET path=/v1/auth/jwks
+func (s *Service) KeySet(ctx context.Context) (auth.KeySet, error) {
+	set, err := s.auth.KeySet()
 	if err != nil {
-		return token{}, errs.New(errs.Internal, err)
+		return auth.KeySet{}, errs.Newf(errs.Internal, "keyset: %s", err)
 	}
 
-	return token{tkn}, nil
+	return set, nil
 }
 
 //lint:ignore U1000 "called by encore"
@@ -64,3 +139,89 @@ func (s *Service) Authorize(ctx context.Context, authInfo mid.AuthInfo) error {
 
 	return nil
 }
+
+// =============================================================================
+// OIDC login related APIs
+
+//lint:ignore U1000 "called by encore"
+//encore:api public raw method=GET path=/v1/auth/oidc/start
+func (s *Service) OIDCStart(w http.ResponseWriter, r *http.Request) {
+	redirect, err := s.oauthApp.Start(r.Context(), r.URL.Query().Get("provider"))
+	if err != nil {
+		web.Error(w, r, err)
+		return
+	}
+
+	w.Header().Add("Set-Cookie", redirect.SetCookie)
+	http.Redirect(w, r, redirect.URL, http.StatusFound)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=POST path=/v1/auth/oidc/link/:provider
+func (s *Service) OIDCLink(ctx context.Context, provider string) (oauthapp.Redirect, error) {
+	return s.oauthApp.Link(ctx, provider)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api public method=GET path=/v1/auth/oidc/callback
+func (s *Service) OIDCCallback(ctx context.Context, app oauthapp.Callback) (userapp.Token, error) {
+	return s.oauthApp.Callback(ctx, app)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api private method=POST path=/v1/auth/oidc/expire
+func (s *Service) OIDCDeleteExpired(ctx context.Context) error {
+	return s.oauthApp.DeleteExpiredStates(ctx)
+}
+
+// =============================================================================
+// API key related APIs
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=POST path=/v1/apikeys
+func (s *Service) APIKeyCreate(ctx context.Context, app apikeyapp.NewAPIKey) (apikeyapp.APIKey, error) {
+	return s.apiKeyApp.Create(ctx, app)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=GET path=/v1/apikeys
+func (s *Service) APIKeyQuery(ctx context.Context) (apikeyapp.APIKeys, error) {
+	return s.apiKeyApp.Query(ctx)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=DELETE path=/v1/apikeys/:keyID
+func (s *Service) APIKeyDelete(ctx context.Context, keyID string) error {
+	return s.apiKeyApp.Delete(ctx, keyID)
+}
+
+// =============================================================================
+// Permission related APIs
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=GET path=/v1/permissions tag:authorize_permission
+func (s *Service) PermissionQuery(ctx context.Context) (permissionapp.RolePermissions, error) {
+	return s.permApp.Query(ctx)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=PUT path=/v1/permissions/:role/:permission tag:authorize_permission
+func (s *Service) PermissionGrant(ctx context.Context, role string, permission string) error {
+	return s.permApp.Grant(ctx, role, permission)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=DELETE path=/v1/permissions/:role/:permission tag:authorize_permission
+func (s *Service) PermissionRevoke(ctx context.Context, role string, permission string) error {
+	return s.permApp.Revoke(ctx, role, permission)
+}
+
+// =============================================================================
+// Debug APIs
+
+//lint:ignore U1000 "called by encore"
+//encore:api private method=GET path=/v1/debug/cache/users
+func (s *Service) UserCacheStats(ctx context.Context) (cachemetrics.Stats, error) {
+	const top = 20
+	return s.auth.UserCacheStats(top), nil
+}
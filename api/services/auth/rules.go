@@ -0,0 +1,13 @@
+package auth
+
+import (
+	"github.com/ardanlabs/encore/app/sdk/mid"
+)
+
+// authPermissions is the permission each endpoint tagged with the
+// authorize_permission middleware requires.
+var authPermissions = mid.Permissions{
+	"PermissionQuery":  "permission:read",
+	"PermissionGrant":  "permission:write",
+	"PermissionRevoke": "permission:write",
+}
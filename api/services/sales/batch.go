@@ -0,0 +1,345 @@
+package sales
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"encore.dev"
+	eauth "encore.dev/beta/auth"
+
+	"github.com/ardanlabs/encore/app/domain/homeapp"
+	"github.com/ardanlabs/encore/app/domain/productapp"
+	"github.com/ardanlabs/encore/app/domain/userapp"
+	productv2app "github.com/ardanlabs/encore/app/domain/v2/productapp"
+	"github.com/ardanlabs/encore/app/domain/vproductapp"
+	"github.com/ardanlabs/encore/app/sdk/auth"
+	"github.com/ardanlabs/encore/app/sdk/batch"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/etag"
+	"github.com/ardanlabs/encore/app/sdk/mid"
+	"github.com/ardanlabs/encore/app/sdk/query"
+	"github.com/ardanlabs/encore/business/domain/homebus"
+	"github.com/ardanlabs/encore/business/domain/productbus"
+	bpubsub "github.com/ardanlabs/encore/business/sdk/pubsub"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/google/uuid"
+)
+
+// newBatchRouter registers the endpoints operations in a batch request can
+// call. Operations call the endpoints through Encore instead of the service
+// methods so they run through the middleware with the caller's
+// authentication, just like a request made on its own. Encore only allows
+// endpoints to be called, so each one is wrapped in a function.
+//
+// Operations that share a transaction can't go through Encore, since each
+// endpoint call runs on its own. The home and product changes are
+// registered a second time to call the app layer directly, applying the
+// authorization and audit middleware of the endpoint themselves.
+func (s *Service) newBatchRouter() *batch.Router {
+	r := batch.NewRouter(s.batchTx)
+
+	r.Handle(http.MethodPost, "/v1/homes", batch.Endpoint(func(ctx context.Context, app homeapp.NewHome) (homeapp.Home, error) {
+		return HomeCreate(ctx, app)
+	}))
+	r.Handle(http.MethodPut, "/v1/homes/:homeID", batch.EndpointWithParam("homeID", func(ctx context.Context, homeID string, app homeapp.UpdateHome) (homeapp.Home, error) {
+		return HomeUpdate(ctx, homeID, app)
+	}))
+	r.Handle(http.MethodPatch, "/v1/homes/:homeID", batch.EndpointWithParam("homeID", func(ctx context.Context, homeID string, app homeapp.PatchHome) (homeapp.Home, error) {
+		return HomePatch(ctx, homeID, app)
+	}))
+	r.Handle(http.MethodDelete, "/v1/homes/:homeID", batch.EndpointNoContent("homeID", func(ctx context.Context, homeID string, pc etag.Precondition) error {
+		return HomeDelete(ctx, homeID, pc)
+	}))
+	r.Handle(http.MethodGet, "/v1/homes", batch.Endpoint(func(ctx context.Context, qp homeapp.QueryParams) (query.Result[homeapp.Home], error) {
+		return HomeQuery(ctx, qp)
+	}))
+	r.Handle(http.MethodGet, "/v1/homes/:homeID", batch.EndpointByParam("homeID", func(ctx context.Context, homeID string) (homeapp.Home, error) {
+		return HomeQueryByID(ctx, homeID)
+	}))
+
+	r.Handle(http.MethodPost, "/v1/products", batch.Endpoint(func(ctx context.Context, app productapp.NewProduct) (productapp.Product, error) {
+		return ProductCreate(ctx, app)
+	}))
+	r.Handle(http.MethodPut, "/v1/products/:productID", batch.EndpointWithParam("productID", func(ctx context.Context, productID string, app productapp.UpdateProduct) (productapp.Product, error) {
+		return ProductUpdate(ctx, productID, app)
+	}))
+	r.Handle(http.MethodDelete, "/v1/products/:productID", batch.EndpointNoContent("productID", func(ctx context.Context, productID string, pc etag.Precondition) error {
+		return ProductDelete(ctx, productID, pc)
+	}))
+	r.Handle(http.MethodGet, "/v1/products", batch.Endpoint(func(ctx context.Context, qp productapp.QueryParams) (query.Result[productapp.Product], error) {
+		return ProductQuery(ctx, qp)
+	}))
+	r.Handle(http.MethodGet, "/v1/products/:productID", batch.EndpointByParam("productID", func(ctx context.Context, productID string) (productapp.Product, error) {
+		return ProductQueryByID(ctx, productID)
+	}))
+
+	r.Handle(http.MethodPost, "/v2/products", batch.Endpoint(func(ctx context.Context, app productv2app.NewProduct) (productv2app.Product, error) {
+		return ProductV2Create(ctx, app)
+	}))
+	r.Handle(http.MethodPut, "/v2/products/:productID", batch.EndpointWithParam("productID", func(ctx context.Context, productID string, app productv2app.UpdateProduct) (productv2app.Product, error) {
+		return ProductV2Update(ctx, productID, app)
+	}))
+	r.Handle(http.MethodGet, "/v2/products", batch.Endpoint(func(ctx context.Context, qp productapp.QueryParams) (query.Result[productv2app.Product], error) {
+		return ProductV2Query(ctx, qp)
+	}))
+	r.Handle(http.MethodGet, "/v2/products/:productID", batch.EndpointByParam("productID", func(ctx context.Context, productID string) (productv2app.Product, error) {
+		return ProductV2QueryByID(ctx, productID)
+	}))
+
+	r.Handle(http.MethodPost, "/v1/users", batch.Endpoint(func(ctx context.Context, app userapp.NewUser) (userapp.User, error) {
+		return UserCreate(ctx, app)
+	}))
+	r.Handle(http.MethodPut, "/v1/users/:userID", batch.EndpointWithParam("userID", func(ctx context.Context, userID string, app userapp.UpdateUser) (userapp.User, error) {
+		return UserUpdate(ctx, userID, app)
+	}))
+	r.Handle(http.MethodPatch, "/v1/users/:userID", batch.EndpointWithParam("userID", func(ctx context.Context, userID string, app userapp.PatchUser) (userapp.User, error) {
+		return UserPatch(ctx, userID, app)
+	}))
+	r.Handle(http.MethodPut, "/v1/role/:userID", batch.EndpointWithParam("userID", func(ctx context.Context, userID string, app userapp.UpdateUserRole) (userapp.User, error) {
+		return UserUpdateRole(ctx, userID, app)
+	}))
+	r.Handle(http.MethodDelete, "/v1/users/:userID", batch.EndpointNoContent("userID", func(ctx context.Context, userID string, pc etag.Precondition) error {
+		return UserDelete(ctx, userID, pc)
+	}))
+	r.Handle(http.MethodGet, "/v1/users", batch.Endpoint(func(ctx context.Context, qp userapp.QueryParams) (query.Result[userapp.User], error) {
+		return UserQuery(ctx, qp)
+	}))
+	r.Handle(http.MethodGet, "/v1/users/:userID", batch.EndpointByParam("userID", func(ctx context.Context, userID string) (userapp.User, error) {
+		return UserQueryByID(ctx, userID)
+	}))
+
+	r.Handle(http.MethodGet, "/v1/vproducts", batch.Endpoint(func(ctx context.Context, qp vproductapp.QueryParams) (query.Result[vproductapp.Product], error) {
+		return VProductQuery(ctx, qp)
+	}))
+
+	r.HandleTx(http.MethodPost, "/v1/homes", txCreate(s, "HomeCreate", s.homeApp.Create))
+	r.HandleTx(http.MethodPut, "/v1/homes/:homeID", txUpdate(s, "HomeUpdate", "homeID", s.batchLoadHome, s.homeApp.Update))
+	r.HandleTx(http.MethodPatch, "/v1/homes/:homeID", txUpdate(s, "HomePatch", "homeID", s.batchLoadHome, s.homeApp.Patch))
+	r.HandleTx(http.MethodDelete, "/v1/homes/:homeID", txDelete(s, "HomeDelete", "homeID", s.batchLoadHome, s.homeApp.Delete))
+
+	r.HandleTx(http.MethodPost, "/v1/products", txCreate(s, "ProductCreate", s.productApp.Create))
+	r.HandleTx(http.MethodPut, "/v1/products/:productID", txUpdate(s, "ProductUpdate", "productID", s.batchLoadProduct, s.productApp.Update))
+	r.HandleTx(http.MethodDelete, "/v1/products/:productID", txDelete(s, "ProductDelete", "productID", s.batchLoadProduct, s.productApp.Delete))
+
+	return r
+}
+
+// =============================================================================
+
+// batchAuditsKey is the context key for the audit entries of the operations
+// in a transaction, which are published once it commits.
+type batchAuditsKey struct{}
+
+// batchTx runs the operations of a batch in a single transaction, like the
+// transaction middleware does for a single request.
+func (s *Service) batchTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	s.log.Info(ctx, "BEGIN TRANSACTION")
+	tx, err := sqldb.NewBeginner(s.db).Begin(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "BEGIN TRANSACTION: %s", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil {
+			if errors.Is(err, sql.ErrTxDone) {
+				return
+			}
+			s.log.Info(ctx, "ROLLBACK TRANSACTION", "ERROR", err)
+		}
+	}()
+
+	var audits []bpubsub.AuditData
+	txCtx := context.WithValue(mid.WithTran(ctx, tx), batchAuditsKey{}, &audits)
+
+	if err := fn(txCtx); err != nil {
+		s.log.Info(ctx, "ROLLBACK TRANSACTION")
+		return err
+	}
+
+	s.log.Info(ctx, "COMMIT TRANSACTION")
+	if err := tx.Commit(); err != nil {
+		return errs.Newf(errs.Internal, "COMMIT TRANSACTION: %s", err)
+	}
+
+	for _, ad := range audits {
+		mid.PublishAudit(ctx, s.log, ad)
+	}
+
+	return nil
+}
+
+// batchAuthorize applies the permission and rule of the endpoint, like the
+// authorize_permission and authorize middleware do. The owner is the user
+// that owns the entity on the route, if there is one.
+func (s *Service) batchAuthorize(ctx context.Context, endpoint string, ownerID uuid.UUID, defaultRule string) error {
+	claims, ok := eauth.Data().(*auth.Claims)
+	if !ok {
+		return errs.Newf(errs.Unauthenticated, "claims missing from request")
+	}
+
+	perm, exists := authPermissions[endpoint]
+	if !exists {
+		return errs.From(fmt.Errorf("endpoint[%s] has no permission: %w", endpoint, auth.ErrForbidden))
+	}
+
+	if !claims.HasPermission(perm) {
+		return errs.From(fmt.Errorf("permission[%s]: %w", perm, auth.ErrForbidden))
+	}
+
+	p := mid.AuthInfo{
+		Claims: *claims,
+		UserID: ownerID,
+		Rule:   authRules.For(endpoint, defaultRule),
+	}
+
+	return s.gatewayAuthorize(ctx, p)
+}
+
+// batchAudit queues the audit entry for an operation until the transaction
+// commits.
+func (s *Service) batchAudit(ctx context.Context, endpoint string, params encore.PathParams, request any, response any) {
+	audits, ok := ctx.Value(batchAuditsKey{}).(*[]bpubsub.AuditData)
+	if !ok {
+		return
+	}
+
+	ad, err := mid.NewAudit(ctx, endpoint, params, request, response)
+	if err != nil {
+		s.log.Error(ctx, "audit", "msg", "build entry", "endpoint", endpoint, "ERROR", err)
+		return
+	}
+
+	*audits = append(*audits, ad)
+}
+
+// batchLoad loads the entity on the route with the transaction, like the
+// authorize middleware for the entity does, and returns the context holding
+// it with the user that owns it.
+type batchLoad func(ctx context.Context, id string) (context.Context, uuid.UUID, error)
+
+func (s *Service) batchLoadHome(ctx context.Context, id string) (context.Context, uuid.UUID, error) {
+	homeID, err := uuid.Parse(id)
+	if err != nil {
+		return ctx, uuid.UUID{}, errs.From(mid.ErrInvalidID)
+	}
+
+	tx, err := mid.GetTran(ctx)
+	if err != nil {
+		return ctx, uuid.UUID{}, errs.New(errs.Internal, err)
+	}
+
+	homeBus, err := s.homeBus.NewWithTx(tx)
+	if err != nil {
+		return ctx, uuid.UUID{}, errs.New(errs.Internal, err)
+	}
+
+	hme, err := homeBus.QueryByID(ctx, homeID)
+	if err != nil {
+		if errors.Is(err, homebus.ErrNotFound) {
+			return ctx, uuid.UUID{}, errs.From(fmt.Errorf("homeID[%s]: %w", homeID, mid.ErrNotFound))
+		}
+		return ctx, uuid.UUID{}, errs.From(fmt.Errorf("querybyid: homeID[%s]: %w", homeID, err))
+	}
+
+	return mid.WithHome(ctx, hme), hme.UserID, nil
+}
+
+func (s *Service) batchLoadProduct(ctx context.Context, id string) (context.Context, uuid.UUID, error) {
+	productID, err := uuid.Parse(id)
+	if err != nil {
+		return ctx, uuid.UUID{}, errs.From(mid.ErrInvalidID)
+	}
+
+	tx, err := mid.GetTran(ctx)
+	if err != nil {
+		return ctx, uuid.UUID{}, errs.New(errs.Internal, err)
+	}
+
+	productBus, err := s.productBus.NewWithTx(tx)
+	if err != nil {
+		return ctx, uuid.UUID{}, errs.New(errs.Internal, err)
+	}
+
+	prd, err := productBus.QueryByID(ctx, productID)
+	if err != nil {
+		if errors.Is(err, productbus.ErrNotFound) {
+			return ctx, uuid.UUID{}, errs.From(fmt.Errorf("productID[%s]: %w", productID, mid.ErrNotFound))
+		}
+		return ctx, uuid.UUID{}, errs.From(fmt.Errorf("querybyid: productID[%s]: %w", productID, err))
+	}
+
+	return mid.WithProduct(ctx, prd), prd.UserID, nil
+}
+
+// txCreate adapts an app layer call that creates an entity for an operation
+// in a transaction.
+func txCreate[Req any, Resp any](s *Service, endpoint string, fn func(ctx context.Context, req Req) (Resp, error)) batch.HandlerFunc {
+	return batch.Endpoint(func(ctx context.Context, req Req) (Resp, error) {
+		var zero Resp
+
+		if err := s.batchAuthorize(ctx, endpoint, uuid.UUID{}, auth.RuleAdminOnly); err != nil {
+			return zero, err
+		}
+
+		resp, err := fn(ctx, req)
+		if err != nil {
+			return zero, errs.From(err)
+		}
+
+		s.batchAudit(ctx, endpoint, nil, req, resp)
+
+		return resp, nil
+	})
+}
+
+// txUpdate adapts an app layer call that changes the entity on the route
+// for an operation in a transaction.
+func txUpdate[Req any, Resp any](s *Service, endpoint string, param string, load batchLoad, fn func(ctx context.Context, req Req) (Resp, error)) batch.HandlerFunc {
+	return batch.EndpointWithParam(param, func(ctx context.Context, id string, req Req) (Resp, error) {
+		var zero Resp
+
+		ctx, ownerID, err := load(ctx, id)
+		if err != nil {
+			return zero, err
+		}
+
+		if err := s.batchAuthorize(ctx, endpoint, ownerID, auth.RuleAdminOrSubject); err != nil {
+			return zero, err
+		}
+
+		resp, err := fn(ctx, req)
+		if err != nil {
+			return zero, errs.From(err)
+		}
+
+		s.batchAudit(ctx, endpoint, encore.PathParams{{Name: param, Value: id}}, req, resp)
+
+		return resp, nil
+	})
+}
+
+// txDelete adapts an app layer call that removes the entity on the route
+// for an operation in a transaction.
+func txDelete(s *Service, endpoint string, param string, load batchLoad, fn func(ctx context.Context, pc etag.Precondition) error) batch.HandlerFunc {
+	return batch.EndpointNoContent(param, func(ctx context.Context, id string, pc etag.Precondition) error {
+		ctx, ownerID, err := load(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := s.batchAuthorize(ctx, endpoint, ownerID, auth.RuleAdminOrSubject); err != nil {
+			return err
+		}
+
+		if err := fn(ctx, pc); err != nil {
+			return errs.From(err)
+		}
+
+		s.batchAudit(ctx, endpoint, encore.PathParams{{Name: param, Value: id}}, pc, nil)
+
+		return nil
+	})
+}
@@ -0,0 +1,52 @@
+package sales
+
+import (
+	"github.com/ardanlabs/encore/app/sdk/cache"
+	"github.com/ardanlabs/encore/business/domain/homebus"
+	"github.com/ardanlabs/encore/business/domain/productbus"
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/sdk/delegate"
+)
+
+// The cached responses each kind of entity appears in. The view of products
+// holds the name of the user, so it's a list for both.
+var (
+	productEntity = cache.Entity{
+		IDField: "ProductID",
+		Paths:   []string{"/v1/products", "/v2/products"},
+		Lists:   []string{"/v1/products", "/v2/products", "/v1/vproducts"},
+	}
+
+	homeEntity = cache.Entity{
+		IDField: "HomeID",
+		Paths:   []string{"/v1/homes"},
+		Lists:   []string{"/v1/homes"},
+	}
+
+	userEntity = cache.Entity{
+		IDField: "UserID",
+		Paths:   []string{"/v1/users"},
+		Lists:   []string{"/v1/users", "/v1/vproducts"},
+	}
+)
+
+// registerCacheInvalidation clears the cached responses a mutation reported
+// by a business domain makes stale, so a client reads its own change right
+// away instead of after the cached responses expire.
+func registerCacheInvalidation(d *delegate.Delegate, c *cache.Cache) {
+	for _, action := range []string{productbus.ActionCreated, productbus.ActionUpdated, productbus.ActionDeleted} {
+		d.Register(productbus.DomainName, action, c.InvalidateEntityFunc(productEntity))
+	}
+
+	for _, action := range []string{homebus.ActionCreated, homebus.ActionUpdated, homebus.ActionDeleted} {
+		d.Register(homebus.DomainName, action, c.InvalidateEntityFunc(homeEntity))
+	}
+
+	d.Register(userbus.DomainName, userbus.ActionCreated, c.InvalidateEntityFunc(userEntity))
+
+	// Updating a user can disable them and deleting one removes what they
+	// own, which changes the responses for everything that belongs to them.
+	ownedBy := c.InvalidateFunc("/v1/users", "/v1/products", "/v1/homes", "/v1/vproducts", "/v2/products")
+	d.Register(userbus.DomainName, userbus.ActionUpdated, ownedBy)
+	d.Register(userbus.DomainName, userbus.ActionDeleted, ownedBy)
+}
@@ -0,0 +1,21 @@
+package sales
+
+import (
+	"encore.dev/cron"
+)
+
+// The daily report is built shortly after midnight UTC so the aggregates
+// cover the full previous day.
+var _ = cron.NewJob("daily-report", cron.JobConfig{
+	Title:    "Build the daily report",
+	Schedule: "15 0 * * *",
+	Endpoint: ReportBuildDaily,
+})
+
+// Stored responses for idempotency keys are removed once they can no
+// longer be replayed.
+var _ = cron.NewJob("idempotency-cleanup", cron.JobConfig{
+	Title:    "Delete expired idempotency keys",
+	Schedule: "30 * * * *",
+	Endpoint: IdempotencyDeleteExpired,
+})
@@ -0,0 +1,14 @@
+package sales
+
+import (
+	"github.com/ardanlabs/encore/app/sdk/deprecation"
+)
+
+// deprecations declares the endpoints that are deprecated. Requests to them
+// are counted in the deprecated_requests metric by endpoint so they can be
+// removed once clients have moved off them. An endpoint is added with the
+// date it was deprecated, the date it will be removed and its successor,
+// like:
+//
+//	deprecation.Endpoint{Name: "ProductQuery", Since: since, Sunset: sunset, Successor: "/v2/products"}
+var deprecations = deprecation.New()
@@ -0,0 +1,129 @@
+package sales
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"encore.dev"
+	"github.com/ardanlabs/encore/app/sdk/auth"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/export"
+	"github.com/ardanlabs/encore/foundation/web"
+	"github.com/ardanlabs/encore/foundation/xlsx"
+)
+
+// Set of formats a query can be exported in.
+const (
+	formatCSV  = "csv"
+	formatXLSX = "xlsx"
+)
+
+var contentTypes = map[string]string{
+	formatCSV:  web.ContentTypeCSV,
+	formatXLSX: xlsx.ContentType,
+}
+
+// exporter represents a query that can be downloaded and the auth rule the
+// caller must pass, matching the rule of the JSON endpoint.
+type exporter struct {
+	rule    string
+	formats map[string]export.Func
+}
+
+func newExporters(app appDomain) map[string]exporter {
+	return map[string]exporter{
+		"homes": {
+			rule: auth.RuleAny,
+			formats: map[string]export.Func{
+				formatCSV: export.CSV(app.homeApp.QueryStream),
+			},
+		},
+		"products": {
+			rule: auth.RuleAny,
+			formats: map[string]export.Func{
+				formatCSV:  export.CSV(app.productApp.QueryStream),
+				formatXLSX: export.XLSX("Products", app.productApp.QueryStream),
+			},
+		},
+		"users": {
+			rule: auth.RuleAdminOnly,
+			formats: map[string]export.Func{
+				formatCSV:  export.CSV(app.userApp.QueryStream),
+				formatXLSX: export.XLSX("Users", app.userApp.QueryStream),
+			},
+		},
+		"vproducts": {
+			rule: auth.RuleAdminOnly,
+			formats: map[string]export.Func{
+				formatCSV: export.CSV(app.vproductApp.QueryStream),
+			},
+		},
+	}
+}
+
+// exportFormat returns the format requested by the format query string
+// or the Accept header, defaulting to CSV.
+func exportFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), xlsx.ContentType) {
+		return formatXLSX
+	}
+
+	return formatCSV
+}
+
+// Export streams the results of a query as CSV or an Excel workbook using
+// the same query string as the JSON endpoint. Raw endpoints don't write
+// errors returned by middleware, so authorization and limiting happen here.
+// Errors are written as problem details when the client accepts them,
+// unless the export fails part way and the status has already been sent.
+//
+//encore:api auth raw method=GET path=/v1/export/:resource tag:metrics
+func (s *Service) Export(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	resource := encore.CurrentRequest().PathParams.Get("resource")
+
+	exp, exists := s.exporters[resource]
+	if !exists {
+		web.Error(w, r, errs.NewNotFound("export", resource))
+		return
+	}
+
+	format := exportFormat(r)
+
+	fn, exists := exp.formats[format]
+	if !exists {
+		web.Error(w, r, errs.Newf(errs.InvalidArgument, "export %s is not available as %s", resource, format))
+		return
+	}
+
+	if err := s.authorizeRule(ctx, exp.rule); err != nil {
+		web.Error(w, r, err)
+		return
+	}
+
+	l, _ := s.limiters.For("Export")
+
+	release, err := l.Acquire(ctx)
+	if err != nil {
+		web.Error(w, r, limitError(l, err))
+		return
+	}
+	defer release()
+
+	w.Header().Set("Content-Type", contentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", resource+"."+format))
+
+	if err := fn(ctx, w, r.URL.Query()); err != nil {
+		s.log.Error(ctx, "export", "resource", resource, "ERROR", err)
+		if !errors.Is(err, export.ErrInterrupted) {
+			web.Error(w, r, err)
+		}
+	}
+}
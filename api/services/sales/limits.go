@@ -0,0 +1,47 @@
+package sales
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/limiter"
+)
+
+// limitGroups is the group of expensive endpoints each endpoint belongs to,
+// applied by the limit middleware and by the raw endpoints themselves. The
+// endpoints in a group share its slots, so a burst on one group can't take
+// the database connections the other needs.
+var limitGroups = map[string]string{
+	"Search":            "search",
+	"ProductQuerySaved": "search",
+	"VProductQuery":     "search",
+
+	"Export": "export",
+	"Stream": "export",
+}
+
+// newLimiters constructs the limiter for each group. The slots are per
+// instance. An export holds a connection for much longer than a search, so
+// fewer run at the same time and rejected clients wait longer.
+func newLimiters() (*limiter.Groups, error) {
+	limiters := map[string]*limiter.Limiter{
+		"search": limiter.New(4, 16, 5*time.Second),
+		"export": limiter.New(2, 4, 30*time.Second),
+	}
+
+	return limiter.NewGroups(limiters, limitGroups)
+}
+
+// limitError returns the error a raw endpoint writes when it can't get a slot
+// from its limiter. When the queue is full the state of the limiter is added
+// so the client knows how long to wait before trying again.
+func limitError(l *limiter.Limiter, err error) error {
+	if !errors.Is(err, limiter.ErrQueueFull) {
+		return errs.New(errs.Canceled, err)
+	}
+
+	st := l.Status()
+
+	return errs.NewRateLimited(errs.ResourceExhausted, errs.NewRateLimit(st.Limit, st.Remaining, st.Reset), err)
+}
@@ -14,6 +14,10 @@ var (
 	requests   = emetrics.NewCounter[uint64]("requests", emetrics.CounterConfig{})
 	failures   = emetrics.NewCounter[uint64]("errors", emetrics.CounterConfig{})
 	panics     = emetrics.NewCounter[uint64]("panics", emetrics.CounterConfig{})
+	deprecated = emetrics.NewCounterGroup[metrics.EndpointLabels, uint64]("deprecated_requests", emetrics.CounterConfig{})
+	endpoints  = emetrics.NewCounterGroup[metrics.EndpointStatusLabels, uint64]("endpoint_requests", emetrics.CounterConfig{})
+	duration   = emetrics.NewCounterGroup[metrics.EndpointStatusLabels, uint64]("endpoint_duration_ms", emetrics.CounterConfig{})
+	denied     = emetrics.NewCounterGroup[metrics.EndpointLabels, uint64]("denied_requests", emetrics.CounterConfig{})
 )
 
 // newMetrics will construct a business layer metrics value that will allow
@@ -25,5 +29,9 @@ func newMetrics() *metrics.Values {
 		Requests:   requests,
 		Failures:   failures,
 		Panics:     panics,
+		Deprecated: deprecated,
+		Endpoints:  endpoints,
+		Duration:   duration,
+		Denied:     denied,
 	})
 }
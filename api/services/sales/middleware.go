@@ -2,14 +2,18 @@ package sales
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"time"
 
+	eauth "encore.dev/beta/auth"
+	eerrs "encore.dev/beta/errs"
 	"encore.dev/middleware"
 	authsrv "github.com/ardanlabs/encore/api/services/auth"
+	"github.com/ardanlabs/encore/app/sdk/auth"
 	"github.com/ardanlabs/encore/app/sdk/errs"
 	"github.com/ardanlabs/encore/app/sdk/mid"
 	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/google/uuid"
 )
 
 // NOTE: The order matters so be careful when injecting new middleware. Global
@@ -19,94 +23,223 @@ import (
 // =============================================================================
 // Global middleware functions
 
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=all
+func (s *Service) requestID(req middleware.Request, next middleware.Next) middleware.Response {
+	return mid.RequestID(req, next)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=all
+func (s *Service) otel(req middleware.Request, next middleware.Next) middleware.Response {
+	return mid.Otel(req, next)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=all
+func (s *Service) logging(req middleware.Request, next middleware.Next) middleware.Response {
+	return mid.Logging(s.log, s.reqLog, req, next)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=all
+func (s *Service) language(req middleware.Request, next middleware.Next) middleware.Response {
+	return mid.Language(req, next)
+}
+
 //lint:ignore U1000 "called by encore"
 //encore:middleware target=all
 func (s *Service) panics(req middleware.Request, next middleware.Next) middleware.Response {
-	return mid.Panics(s.mtrcs, req, next)
+	return mid.Panics(s.log, s.mtrcs, req, next)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=all
+func (s *Service) version(req middleware.Request, next middleware.Next) middleware.Response {
+	return mid.Version(req, next)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=all
+func (s *Service) deprecation(req middleware.Request, next middleware.Next) middleware.Response {
+	return mid.Deprecation(s.mtrcs, deprecations, req, next)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=all
+func (s *Service) maintenance(req middleware.Request, next middleware.Next) middleware.Response {
+	return mid.Maintenance(s.mode, req, next)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=all
+func (s *Service) limitBody(req middleware.Request, next middleware.Next) middleware.Response {
+	return mid.BodyLimit(s.bodyLimit, req, next)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=all
+func (s *Service) timeout(req middleware.Request, next middleware.Next) middleware.Response {
+	return mid.Timeout(timeouts, req, next)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=all
+func (s *Service) errors(req middleware.Request, next middleware.Next) middleware.Response {
+	return mid.Errors(s.log, req, next)
 }
 
 // =============================================================================
 // Authorization related middleware
 
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=tag:allowlist
+func (s *Service) allowList(req middleware.Request, next middleware.Next) middleware.Response {
+	return mid.AllowList(s.log, s.mtrcs, s.adminNets, s.proxies, req, next)
+}
+
 //lint:ignore U1000 "called by encore"
 //encore:middleware target=tag:authorize
 func (s *Service) authorize(req middleware.Request, next middleware.Next) middleware.Response {
-	p, req, err := mid.Authorize(req)
+	p, req, err := mid.Authorize(authRules, req)
 	if err != nil {
 		return errs.NewResponse(errs.Unauthenticated, err)
 	}
 
-	ctx, cancel := context.WithTimeout(req.Context(), 5*time.Second)
-	defer cancel()
+	return s.authorizeWithGateway(req, next, p)
+}
 
-	if err := authsrv.Authorize(ctx, p); err != nil {
-		err = fmt.Errorf("%s", err.Error()[17:]) // Remove "unauthenticated:" from the error string.
-		return errs.NewResponse(errs.Unauthenticated, err)
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=tag:authorize_user
+func (s *Service) authorizeUser(req middleware.Request, next middleware.Next) middleware.Response {
+	p, req, err := mid.AuthorizeUser(authRules, s.userBus, req)
+	if err != nil {
+		return loadError(err)
 	}
 
-	return next(req)
+	return s.authorizeWithGateway(req, next, p)
 }
 
 //lint:ignore U1000 "called by encore"
-//encore:middleware target=tag:authorize_user
-func (s *Service) authorizeUser(req middleware.Request, next middleware.Next) middleware.Response {
-	p, req, err := mid.AuthorizeUser(s.userBus, req)
+//encore:middleware target=tag:authorize_product
+func (s *Service) authorizeProduct(req middleware.Request, next middleware.Next) middleware.Response {
+	p, req, err := mid.AuthorizeProduct(authRules, s.productBus, req)
 	if err != nil {
-		return errs.NewResponse(errs.Unauthenticated, err)
+		return loadError(err)
 	}
 
-	ctx, cancel := context.WithTimeout(req.Context(), 5*time.Second)
-	defer cancel()
+	return s.authorizeWithGateway(req, next, p)
+}
 
-	if err := authsrv.Authorize(ctx, p); err != nil {
-		err = fmt.Errorf("%s", err.Error()[17:]) // Remove "unauthenticated:" from the error string.
-		return errs.NewResponse(errs.Unauthenticated, err)
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=tag:authorize_home
+func (s *Service) authorizeHome(req middleware.Request, next middleware.Next) middleware.Response {
+	p, req, err := mid.AuthorizeHome(authRules, s.homeBus, req)
+	if err != nil {
+		return loadError(err)
 	}
 
-	return next(req)
+	return s.authorizeWithGateway(req, next, p)
 }
 
 //lint:ignore U1000 "called by encore"
-//encore:middleware target=tag:authorize_product
-func (s *Service) authorizeProduct(req middleware.Request, next middleware.Next) middleware.Response {
-	p, req, err := mid.AuthorizeProduct(s.productBus, req)
+//encore:middleware target=tag:authorize_job
+func (s *Service) authorizeJob(req middleware.Request, next middleware.Next) middleware.Response {
+	p, req, err := mid.AuthorizeJob(authRules, s.jobBus, req)
 	if err != nil {
-		return errs.NewResponse(errs.Unauthenticated, err)
+		return loadError(err)
 	}
 
-	ctx, cancel := context.WithTimeout(req.Context(), 5*time.Second)
-	defer cancel()
+	return s.authorizeWithGateway(req, next, p)
+}
 
-	if err := authsrv.Authorize(ctx, p); err != nil {
-		err = fmt.Errorf("%s", err.Error()[17:]) // Remove "unauthenticated:" from the error string.
-		return errs.NewResponse(errs.Unauthenticated, err)
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=tag:authorize_permission
+func (s *Service) authorizePermission(req middleware.Request, next middleware.Next) middleware.Response {
+	return mid.AuthorizePermission(authPermissions, req, next)
+}
+
+// authorizeWithGateway asks the auth service to apply the authorization rule.
+// The auth service is the gateway every service shares for authentication and
+// authorization, so no other service needs to load the keystore.
+func (s *Service) authorizeWithGateway(req middleware.Request, next middleware.Next, p mid.AuthInfo) middleware.Response {
+	if err := s.gatewayAuthorize(req.Context(), p); err != nil {
+		return middleware.Response{Err: err}
 	}
 
 	return next(req)
 }
 
-//lint:ignore U1000 "called by encore"
-//encore:middleware target=tag:authorize_home
-func (s *Service) authorizeHome(req middleware.Request, next middleware.Next) middleware.Response {
-	p, req, err := mid.AuthorizeHome(s.homeBus, req)
-	if err != nil {
-		return errs.NewResponse(errs.Unauthenticated, err)
+// authorizeRule applies the rule to the authenticated user for handlers that
+// can't use the authorization middleware, like raw endpoints.
+func (s *Service) authorizeRule(ctx context.Context, rule string) error {
+	claims, ok := eauth.Data().(*auth.Claims)
+	if !ok {
+		return errs.Newf(errs.Unauthenticated, "claims missing from request")
+	}
+
+	p := mid.AuthInfo{
+		Claims: *claims,
+		Rule:   rule,
 	}
 
-	ctx, cancel := context.WithTimeout(req.Context(), 5*time.Second)
+	return s.gatewayAuthorize(ctx, p)
+}
+
+// authorizeOwner applies the admin or subject rule to the authenticated user
+// for an item owned by the specified user, for handlers that act on many
+// items at once.
+func (s *Service) authorizeOwner(ctx context.Context, userID uuid.UUID) error {
+	claims, ok := eauth.Data().(*auth.Claims)
+	if !ok {
+		return errs.Newf(errs.Unauthenticated, "claims missing from request")
+	}
+
+	p := mid.AuthInfo{
+		Claims: *claims,
+		UserID: userID,
+		Rule:   auth.RuleAdminOrSubject,
+	}
+
+	return s.gatewayAuthorize(ctx, p)
+}
+
+// loadError converts a failure to load the entity specified on the route
+// into a response the client can act on.
+func loadError(err error) middleware.Response {
+	return middleware.Response{Err: errs.From(err)}
+}
+
+func (s *Service) gatewayAuthorize(ctx context.Context, p mid.AuthInfo) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	if err := authsrv.Authorize(ctx, p); err != nil {
-		err = fmt.Errorf("%s", err.Error()[17:]) // Remove "unauthenticated:" from the error string.
-		return errs.NewResponse(errs.Unauthenticated, err)
+		var eerr *eerrs.Error
+		if errors.As(err, &eerr) {
+			return errs.Newf(errs.Unauthenticated, "%s", eerr.Message)
+		}
+		return errs.New(errs.Unauthenticated, err)
 	}
 
-	return next(req)
+	return nil
 }
 
 // =============================================================================
 // Specific middleware functions
 
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=tag:idempotent
+func (s *Service) idempotency(req middleware.Request, next middleware.Next) middleware.Response {
+	return mid.Idempotency(s.log, s.idempotencyBus, req, next)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=tag:audit
+func (s *Service) audit(req middleware.Request, next middleware.Next) middleware.Response {
+	return mid.Audit(s.log, req, next)
+}
+
 //lint:ignore U1000 "called by encore"
 //encore:middleware target=tag:transaction
 func (s *Service) beginCommitRollback(req middleware.Request, next middleware.Next) middleware.Response {
@@ -118,3 +251,21 @@ func (s *Service) beginCommitRollback(req middleware.Request, next middleware.Ne
 func (s *Service) metrics(req middleware.Request, next middleware.Next) middleware.Response {
 	return mid.Metrics(s.mtrcs, req, next)
 }
+
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=tag:preferences
+func (s *Service) preferences(req middleware.Request, next middleware.Next) middleware.Response {
+	return mid.Preferences(s.userPrefsBus, req, next)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=tag:cache
+func (s *Service) cacheResponse(req middleware.Request, next middleware.Next) middleware.Response {
+	return mid.Cache(s.cache, req, next)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:middleware target=tag:limit
+func (s *Service) limit(req middleware.Request, next middleware.Next) middleware.Response {
+	return mid.Limit(s.limiters, req, next)
+}
@@ -1,28 +1,56 @@
 package sales
 
 import (
+	adminapp "github.com/ardanlabs/encore/app/domain/adminapp"
+	deadletterapp "github.com/ardanlabs/encore/app/domain/deadletterapp"
 	homeapp "github.com/ardanlabs/encore/app/domain/homeapp"
+	idempotencyapp "github.com/ardanlabs/encore/app/domain/idempotencyapp"
+	jobapp "github.com/ardanlabs/encore/app/domain/jobapp"
 	productapp "github.com/ardanlabs/encore/app/domain/productapp"
+	reportapp "github.com/ardanlabs/encore/app/domain/reportapp"
+	savedsearchapp "github.com/ardanlabs/encore/app/domain/savedsearchapp"
+	searchapp "github.com/ardanlabs/encore/app/domain/searchapp"
 	tranapp "github.com/ardanlabs/encore/app/domain/tranapp"
 	userapp "github.com/ardanlabs/encore/app/domain/userapp"
+	userprefsapp "github.com/ardanlabs/encore/app/domain/userprefsapp"
+	productv2app "github.com/ardanlabs/encore/app/domain/v2/productapp"
 	vproductapp "github.com/ardanlabs/encore/app/domain/vproductapp"
+	"github.com/ardanlabs/encore/business/domain/auditbus"
+	"github.com/ardanlabs/encore/business/domain/deadletterbus"
 	"github.com/ardanlabs/encore/business/domain/homebus"
+	"github.com/ardanlabs/encore/business/domain/idempotencybus"
+	"github.com/ardanlabs/encore/business/domain/jobbus"
 	"github.com/ardanlabs/encore/business/domain/productbus"
 	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/domain/userprefsbus"
 	"github.com/ardanlabs/encore/business/sdk/delegate"
 )
 
 type appDomain struct {
-	homeApp     *homeapp.App
-	productApp  *productapp.App
-	tranApp     *tranapp.App
-	userApp     *userapp.App
-	vproductApp *vproductapp.App
+	adminApp       *adminapp.App
+	deadLetterApp  *deadletterapp.App
+	homeApp        *homeapp.App
+	idemApp        *idempotencyapp.App
+	jobApp         *jobapp.App
+	productApp     *productapp.App
+	productV2App   *productv2app.App
+	reportApp      *reportapp.App
+	savedSearchApp *savedsearchapp.App
+	searchApp      *searchapp.App
+	tranApp        *tranapp.App
+	userApp        *userapp.App
+	userPrefsApp   *userprefsapp.App
+	vproductApp    *vproductapp.App
 }
 
 type busDomain struct {
-	delegate   *delegate.Delegate
-	homeBus    *homebus.Business
-	productBus *productbus.Business
-	userBus    *userbus.Business
+	delegate       *delegate.Delegate
+	auditBus       *auditbus.Business
+	deadLetterBus  *deadletterbus.Business
+	homeBus        *homebus.Business
+	idempotencyBus *idempotencybus.Business
+	jobBus         *jobbus.Business
+	productBus     *productbus.Business
+	userBus        *userbus.Business
+	userPrefsBus   *userprefsbus.Business
 }
@@ -0,0 +1,117 @@
+package sales
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ardanlabs/encore/app/domain/deadletterapp"
+	"github.com/ardanlabs/encore/app/domain/homeapp"
+	"github.com/ardanlabs/encore/app/domain/jobapp"
+	"github.com/ardanlabs/encore/app/domain/productapp"
+	"github.com/ardanlabs/encore/app/domain/reportapp"
+	"github.com/ardanlabs/encore/app/domain/savedsearchapp"
+	"github.com/ardanlabs/encore/app/domain/searchapp"
+	"github.com/ardanlabs/encore/app/domain/tranapp"
+	"github.com/ardanlabs/encore/app/domain/userapp"
+	"github.com/ardanlabs/encore/app/domain/userprefsapp"
+	productv2app "github.com/ardanlabs/encore/app/domain/v2/productapp"
+	"github.com/ardanlabs/encore/app/domain/vproductapp"
+	"github.com/ardanlabs/encore/app/sdk/about"
+	"github.com/ardanlabs/encore/app/sdk/auth"
+	"github.com/ardanlabs/encore/app/sdk/batch"
+	"github.com/ardanlabs/encore/app/sdk/bulk"
+	"github.com/ardanlabs/encore/app/sdk/etag"
+	"github.com/ardanlabs/encore/app/sdk/openapi"
+	"github.com/ardanlabs/encore/app/sdk/query"
+	"github.com/ardanlabs/encore/foundation/web"
+)
+
+// openAPIRoutes describes the public endpoints in routes.go. A route added
+// there needs to be added here to be part of the document. Raw endpoints
+// aren't described since Encore doesn't know their shapes.
+var openAPIRoutes = []openapi.Route{
+	{Name: "About", Method: http.MethodGet, Path: "/about", Tag: "about", Response: about.Info{}},
+
+	{Name: "KeySet", Method: http.MethodGet, Path: "/.well-known/jwks.json", Tag: "auth", Response: auth.KeySet{}},
+
+	{Name: "BatchExecute", Method: http.MethodPost, Path: "/v1/batch", Tag: "batch", Auth: true, Request: batch.Request{}, Response: batch.Response{}},
+
+	{Name: "DeadLetterQuery", Method: http.MethodGet, Path: "/v1/deadletters", Tag: "deadletters", Auth: true, Request: deadletterapp.QueryParams{}, Response: query.Result[deadletterapp.DeadLetter]{}},
+	{Name: "DeadLetterQueryByID", Method: http.MethodGet, Path: "/v1/deadletters/:deadLetterID", Tag: "deadletters", Auth: true, Response: deadletterapp.DeadLetter{}},
+	{Name: "DeadLetterReplay", Method: http.MethodPost, Path: "/v1/deadletters/:deadLetterID/replay", Tag: "deadletters", Auth: true, Response: deadletterapp.DeadLetter{}},
+
+	{Name: "HomeCreate", Method: http.MethodPost, Path: "/v1/homes", Tag: "homes", Auth: true, Request: homeapp.NewHome{}, Response: homeapp.Home{}},
+	{Name: "HomeUpdate", Method: http.MethodPut, Path: "/v1/homes/:homeID", Tag: "homes", Auth: true, Request: homeapp.UpdateHome{}, Response: homeapp.Home{}},
+	{Name: "HomePatch", Method: http.MethodPatch, Path: "/v1/homes/:homeID", Tag: "homes", Auth: true, Request: homeapp.PatchHome{}, Response: homeapp.Home{}},
+	{Name: "HomeDelete", Method: http.MethodDelete, Path: "/v1/homes/:homeID", Tag: "homes", Auth: true, Request: etag.Precondition{}},
+	{Name: "HomeDeleteMany", Method: http.MethodPost, Path: "/v1/bulk/homes/delete", Tag: "homes", Auth: true, Request: bulk.IDs{}, Response: bulk.Result{}},
+	{Name: "HomeQuery", Method: http.MethodGet, Path: "/v1/homes", Tag: "homes", Auth: true, Request: homeapp.QueryParams{}, Response: query.Result[homeapp.Home]{}},
+	{Name: "HomeQueryByID", Method: http.MethodGet, Path: "/v1/homes/:productID", Tag: "homes", Auth: true, Response: homeapp.Home{}},
+
+	{Name: "JobQueryByID", Method: http.MethodGet, Path: "/v1/jobs/:jobID", Tag: "jobs", Auth: true, Response: jobapp.Job{}},
+
+	{Name: "PreferenceQuery", Method: http.MethodGet, Path: "/v1/preferences", Tag: "preferences", Auth: true, Response: userprefsapp.Preferences{}},
+	{Name: "PreferenceSet", Method: http.MethodPut, Path: "/v1/preferences/:entity", Tag: "preferences", Auth: true, Request: userprefsapp.SetPreference{}, Response: userprefsapp.Preference{}},
+	{Name: "PreferenceDelete", Method: http.MethodDelete, Path: "/v1/preferences/:entity", Tag: "preferences", Auth: true},
+
+	{Name: "ProductCreate", Method: http.MethodPost, Path: "/v1/products", Tag: "products", Auth: true, Request: productapp.NewProduct{}, Response: productapp.Product{}},
+	{Name: "ProductUpdate", Method: http.MethodPut, Path: "/v1/products/:productID", Tag: "products", Auth: true, Request: productapp.UpdateProduct{}, Response: productapp.Product{}},
+	{Name: "ProductDelete", Method: http.MethodDelete, Path: "/v1/products/:productID", Tag: "products", Auth: true, Request: etag.Precondition{}},
+	{Name: "ProductDeleteMany", Method: http.MethodPost, Path: "/v1/bulk/products/delete", Tag: "products", Auth: true, Request: bulk.IDs{}, Response: bulk.Result{}},
+	{Name: "ProductQuery", Method: http.MethodGet, Path: "/v1/products", Tag: "products", Auth: true, Request: productapp.QueryParams{}, Response: query.Result[productapp.Product]{}},
+	{Name: "ProductQuerySaved", Method: http.MethodGet, Path: "/v1/products/search/:name", Tag: "products", Auth: true, Request: productapp.QueryParams{}, Response: query.Result[productapp.Product]{}},
+	{Name: "ProductQueryByID", Method: http.MethodGet, Path: "/v1/products/:productID", Tag: "products", Auth: true, Response: productapp.Product{}},
+
+	{Name: "ProductV2Create", Method: http.MethodPost, Path: "/v2/products", Tag: "products", Auth: true, Request: productv2app.NewProduct{}, Response: productv2app.Product{}},
+	{Name: "ProductV2Update", Method: http.MethodPut, Path: "/v2/products/:productID", Tag: "products", Auth: true, Request: productv2app.UpdateProduct{}, Response: productv2app.Product{}},
+	{Name: "ProductV2Query", Method: http.MethodGet, Path: "/v2/products", Tag: "products", Auth: true, Request: productapp.QueryParams{}, Response: query.Result[productv2app.Product]{}},
+	{Name: "ProductV2QueryByID", Method: http.MethodGet, Path: "/v2/products/:productID", Tag: "products", Auth: true, Response: productv2app.Product{}},
+
+	{Name: "ReportBuild", Method: http.MethodPost, Path: "/v1/reports/build", Tag: "reports", Auth: true, Request: reportapp.BuildReport{}, Response: jobapp.Accepted{}},
+
+	{Name: "Search", Method: http.MethodGet, Path: "/v1/search", Tag: "search", Auth: true, Request: searchapp.QueryParams{}, Response: searchapp.Result{}},
+	{Name: "SavedSearchQuery", Method: http.MethodGet, Path: "/v1/searches", Tag: "search", Auth: true, Response: savedsearchapp.SavedSearches{}},
+	{Name: "SavedSearchSave", Method: http.MethodPut, Path: "/v1/searches/:entity/:name", Tag: "search", Auth: true, Request: savedsearchapp.SaveSearch{}, Response: savedsearchapp.SavedSearch{}},
+	{Name: "SavedSearchDelete", Method: http.MethodDelete, Path: "/v1/searches/:entity/:name", Tag: "search", Auth: true},
+
+	{Name: "TranCreate", Method: http.MethodPost, Path: "/v1/tran", Tag: "tran", Auth: true, Request: tranapp.NewTran{}, Response: tranapp.Product{}},
+
+	{Name: "UserCreate", Method: http.MethodPost, Path: "/v1/users", Tag: "users", Auth: true, Request: userapp.NewUser{}, Response: userapp.User{}},
+	{Name: "UserUpdate", Method: http.MethodPut, Path: "/v1/users/:userID", Tag: "users", Auth: true, Request: userapp.UpdateUser{}, Response: userapp.User{}},
+	{Name: "UserPatch", Method: http.MethodPatch, Path: "/v1/users/:userID", Tag: "users", Auth: true, Request: userapp.PatchUser{}, Response: userapp.User{}},
+	{Name: "UserUpdateRole", Method: http.MethodPut, Path: "/v1/role/:userID", Tag: "users", Auth: true, Request: userapp.UpdateUserRole{}, Response: userapp.User{}},
+	{Name: "UserDelete", Method: http.MethodDelete, Path: "/v1/users/:userID", Tag: "users", Auth: true, Request: etag.Precondition{}},
+	{Name: "UserQuery", Method: http.MethodGet, Path: "/v1/users", Tag: "users", Auth: true, Request: userapp.QueryParams{}, Response: query.Result[userapp.User]{}},
+	{Name: "UserQueryByID", Method: http.MethodGet, Path: "/v1/users/:userID", Tag: "users", Auth: true, Response: userapp.User{}},
+
+	{Name: "VProductQuery", Method: http.MethodGet, Path: "/v1/vproducts", Tag: "vproducts", Auth: true, Request: vproductapp.QueryParams{}, Response: query.Result[vproductapp.Product]{}},
+}
+
+// OpenAPIDocument generates the OpenAPI document for the service. It's
+// exported for the contract tests that validate the traffic recorded from
+// the app against it.
+func OpenAPIDocument(version string) openapi.Document {
+	gen := openapi.New("Sales API", version)
+	for _, route := range openAPIRoutes {
+		if _, exists := deprecations.Lookup(route.Name); exists {
+			route.Deprecated = true
+		}
+		gen.Add(route)
+	}
+
+	return gen.Document()
+}
+
+// newOpenAPI generates the encoded OpenAPI document for the service.
+func newOpenAPI(version string) ([]byte, error) {
+	return json.Marshal(OpenAPIDocument(version))
+}
+
+// OpenAPI returns the OpenAPI document describing the endpoints so external
+// clients and contract tests have a source of truth for the API.
+//
+//encore:api public raw method=GET path=/openapi.json
+func (s *Service) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", web.ContentTypeJSON)
+	w.Write(s.openapi)
+}
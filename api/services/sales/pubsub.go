@@ -2,12 +2,40 @@ package sales
 
 import (
 	"context"
+	"time"
 
+	"encore.dev"
 	"encore.dev/pubsub"
+	"github.com/ardanlabs/encore/business/domain/auditbus"
+	"github.com/ardanlabs/encore/business/domain/deadletterbus"
 	"github.com/ardanlabs/encore/business/sdk/delegate"
 	bpubsub "github.com/ardanlabs/encore/business/sdk/pubsub"
 )
 
+// maxDeliveryAttempts is the number of times a message is delivered to a
+// subscriber before it's recorded as a dead letter. This must be less than
+// the MaxRetries of each subscription so the message isn't dropped first.
+const maxDeliveryAttempts = 10
+
+// delivery returns the details of the message being handled by the current
+// request. A call that isn't a message delivery reports no attempts, so it's
+// never recorded as a dead letter.
+func delivery() deadletterbus.Delivery {
+	md := encore.CurrentRequest().Message
+	if md == nil {
+		return deadletterbus.Delivery{}
+	}
+
+	return deadletterbus.Delivery{
+		Topic:        md.Topic,
+		Subscription: md.Subscription,
+		MessageID:    md.ID,
+		Attempt:      md.DeliveryAttempt,
+	}
+}
+
+// =============================================================================
+
 // We need a single subscription which will route a message to the
 // delegate system.
 var _ = pubsub.NewSubscription(bpubsub.Delegate, "handle-delegate-call",
@@ -20,5 +48,70 @@ var _ = pubsub.NewSubscription(bpubsub.Delegate, "handle-delegate-call",
 // into the delegate system.
 func (s *Service) DelegateHandler(ctx context.Context, data delegate.Data) error {
 	s.log.Info(ctx, "DelegateHandler", "data", data)
-	return s.delegate.Call(ctx, data)
+	return deadletterbus.Handle(ctx, s.deadLetterBus, maxDeliveryAttempts, delivery(), data, s.delegate.Call)
+}
+
+// =============================================================================
+
+// The worker subscription runs background jobs. A failed attempt returns an
+// error so Encore redelivers the message with backoff. The job business layer
+// tracks the attempts and stops returning errors once a job runs out of
+// attempts, so MaxRetries only needs to be larger than any job's attempts.
+// Jobs that keep failing without being tracked are recorded as dead letters.
+var _ = pubsub.NewSubscription(bpubsub.Jobs, "run-job",
+	pubsub.SubscriptionConfig[bpubsub.JobData]{
+		Handler:        pubsub.MethodHandler((*Service).JobHandler),
+		AckDeadline:    10 * time.Minute,
+		MaxConcurrency: 10,
+		RetryPolicy: &pubsub.RetryPolicy{
+			MinBackoff: 10 * time.Second,
+			MaxBackoff: 10 * time.Minute,
+			MaxRetries: 20,
+		},
+	},
+)
+
+// JobHandler receives a job from the pubsub system and performs a single
+// attempt of the work.
+func (s *Service) JobHandler(ctx context.Context, data bpubsub.JobData) error {
+	s.log.Info(ctx, "JobHandler", "jobID", data.JobID)
+
+	f := func(ctx context.Context, data bpubsub.JobData) error {
+		return s.jobBus.Run(ctx, data.JobID)
+	}
+
+	return deadletterbus.Handle(ctx, s.deadLetterBus, maxDeliveryAttempts, delivery(), data, f)
+}
+
+// =============================================================================
+
+// The audit subscription records the changes made through the API. Entries
+// are published by the audit middleware once a change succeeds, so writing
+// them doesn't slow down the request.
+var _ = pubsub.NewSubscription(bpubsub.Audits, "record-audit",
+	pubsub.SubscriptionConfig[bpubsub.AuditData]{
+		Handler: pubsub.MethodHandler((*Service).AuditHandler),
+	},
+)
+
+// AuditHandler receives a change from the pubsub system and records it in
+// the audit log.
+func (s *Service) AuditHandler(ctx context.Context, data bpubsub.AuditData) error {
+	f := func(ctx context.Context, data bpubsub.AuditData) error {
+		na := auditbus.NewAudit{
+			ActorID:  data.ActorID,
+			Action:   data.Action,
+			Endpoint: data.Endpoint,
+			EntityID: data.EntityID,
+			Payload:  data.Payload,
+		}
+
+		if _, err := s.auditBus.Create(ctx, na); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	return deadletterbus.Handle(ctx, s.deadLetterBus, maxDeliveryAttempts, delivery(), data, f)
 }
@@ -5,18 +5,44 @@ import (
 	"net/http"
 
 	"encore.dev"
+	authsrv "github.com/ardanlabs/encore/api/services/auth"
+	"github.com/ardanlabs/encore/app/domain/adminapp"
+	"github.com/ardanlabs/encore/app/domain/deadletterapp"
 	"github.com/ardanlabs/encore/app/domain/homeapp"
+	"github.com/ardanlabs/encore/app/domain/jobapp"
 	"github.com/ardanlabs/encore/app/domain/productapp"
+	"github.com/ardanlabs/encore/app/domain/reportapp"
+	"github.com/ardanlabs/encore/app/domain/savedsearchapp"
+	"github.com/ardanlabs/encore/app/domain/searchapp"
 	"github.com/ardanlabs/encore/app/domain/tranapp"
 	"github.com/ardanlabs/encore/app/domain/userapp"
+	"github.com/ardanlabs/encore/app/domain/userprefsapp"
+	productv2app "github.com/ardanlabs/encore/app/domain/v2/productapp"
 	"github.com/ardanlabs/encore/app/domain/vproductapp"
+	"github.com/ardanlabs/encore/app/sdk/about"
+	"github.com/ardanlabs/encore/app/sdk/allowlist"
+	"github.com/ardanlabs/encore/app/sdk/auth"
+	"github.com/ardanlabs/encore/app/sdk/batch"
+	"github.com/ardanlabs/encore/app/sdk/bulk"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/etag"
+	"github.com/ardanlabs/encore/app/sdk/health"
 	"github.com/ardanlabs/encore/app/sdk/query"
+	"github.com/ardanlabs/encore/foundation/web"
 )
 
-// Fallback is called for the debug enpoints.
+// Fallback is called for the debug enpoints. Raw endpoints don't write
+// errors returned by middleware, so the allow list is checked here.
 //
-//encore:api public raw path=/!fallback
+//encore:api public raw path=/!fallback tag:operations
 func (s *Service) Fallback(w http.ResponseWriter, r *http.Request) {
+	if ip := allowlist.ClientIP(r.Header, r.RemoteAddr, s.proxies); !s.adminNets.Allowed(ip) {
+		s.log.Warn(r.Context(), "allowlist", "status", "denied", "endpoint", "Fallback", "path", r.URL.Path, "ip", ip)
+		s.mtrcs.IncDenied("Fallback")
+
+		web.Error(w, r, errs.New(errs.PermissionDenied, allowlist.ErrDenied))
+		return
+	}
 
 	// If this is a web socket call for statsviz and we are in development.
 	if r.URL.String() == "/debug/statsviz/ws" && encore.Meta().Environment.Type == encore.EnvDevelopment {
@@ -29,34 +55,139 @@ func (s *Service) Fallback(w http.ResponseWriter, r *http.Request) {
 	s.debug.ServeHTTP(w, r)
 }
 
+// About returns information about the running build and environment so a
+// deploy can be verified. It's only reachable from the admin networks since
+// it describes the deployment.
+//
+//encore:api public method=GET path=/about tag:allowlist tag:operations
+func (s *Service) About(ctx context.Context) (about.Info, error) {
+	return about.Collect(ctx, s.db, s.features), nil
+}
+
+// KeySet returns the public keys tokens can be verified with, so clients and
+// other systems can verify the tokens on their own. The keys are kept by the
+// auth service, which is why it's asked for them.
+//
+//encore:api public method=GET path=/.well-known/jwks.json tag:metrics
+func (s *Service) KeySet(ctx context.Context) (auth.KeySet, error) {
+	return authsrv.KeySet(ctx)
+}
+
+// =============================================================================
+
+//lint:ignore U1000 "called by encore"
+//encore:api private method=GET path=/v1/healthz tag:operations
+func (s *Service) Liveness(ctx context.Context) (health.Report, error) {
+	return s.health.Liveness(), nil
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api private method=GET path=/v1/readyz tag:operations
+func (s *Service) Readiness(ctx context.Context) (health.Report, error) {
+	report := s.health.Readiness(ctx)
+	if !report.Up() {
+		err := errs.Newf(errs.Unavailable, "readiness: one or more dependencies are down")
+		err.Details = report
+		return health.Report{}, err
+	}
+
+	return report, nil
+}
+
+// =============================================================================
+
+//lint:ignore U1000 "called by encore"
+//encore:api private method=GET path=/v1/admin tag:allowlist tag:authorize tag:operations
+func (s *Service) AdminStatus(ctx context.Context) (adminapp.Status, error) {
+	return s.adminApp.Status(ctx), nil
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api private method=PUT path=/v1/admin/loglevel tag:allowlist tag:authorize tag:operations
+func (s *Service) AdminSetLogLevel(ctx context.Context, app adminapp.LogLevel) (adminapp.Status, error) {
+	return s.adminApp.SetLogLevel(ctx, app)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api private method=POST path=/v1/admin/cache/flush tag:allowlist tag:authorize tag:operations
+func (s *Service) AdminFlushCache(ctx context.Context) (adminapp.Status, error) {
+	return s.adminApp.FlushCache(ctx)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api private method=PUT path=/v1/admin/maintenance tag:allowlist tag:authorize tag:operations
+func (s *Service) AdminSetMaintenance(ctx context.Context, app adminapp.Maintenance) (adminapp.Status, error) {
+	return s.adminApp.SetMaintenance(ctx, app)
+}
+
 // =============================================================================
 
 //lint:ignore U1000 "called by encore"
-//encore:api auth method=POST path=/v1/homes tag:metrics tag:authorize tag:as_user_role
+//encore:api auth method=POST path=/v1/batch tag:metrics tag:body_large
+func (s *Service) BatchExecute(ctx context.Context, req batch.Request) (batch.Response, error) {
+	return s.batch.Execute(ctx, req)
+}
+
+// =============================================================================
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=GET path=/v1/deadletters tag:metrics tag:authorize
+func (s *Service) DeadLetterQuery(ctx context.Context, qp deadletterapp.QueryParams) (query.Result[deadletterapp.DeadLetter], error) {
+	return s.deadLetterApp.Query(ctx, qp)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=GET path=/v1/deadletters/:deadLetterID tag:metrics tag:authorize
+func (s *Service) DeadLetterQueryByID(ctx context.Context, deadLetterID string) (deadletterapp.DeadLetter, error) {
+	return s.deadLetterApp.QueryByID(ctx, deadLetterID)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=POST path=/v1/deadletters/:deadLetterID/replay tag:metrics tag:authorize tag:audit
+func (s *Service) DeadLetterReplay(ctx context.Context, deadLetterID string) (deadletterapp.DeadLetter, error) {
+	return s.deadLetterApp.Replay(ctx, deadLetterID)
+}
+
+// =============================================================================
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=POST path=/v1/homes tag:idempotent tag:metrics tag:authorize tag:authorize_permission tag:audit
 func (s *Service) HomeCreate(ctx context.Context, app homeapp.NewHome) (homeapp.Home, error) {
 	return s.homeApp.Create(ctx, app)
 }
 
 //lint:ignore U1000 "called by encore"
-//encore:api auth method=PUT path=/v1/homes/:homeID tag:metrics tag:authorize_home
+//encore:api auth method=PUT path=/v1/homes/:homeID tag:metrics tag:authorize_home tag:authorize_permission tag:audit
 func (s *Service) HomeUpdate(ctx context.Context, homeID string, app homeapp.UpdateHome) (homeapp.Home, error) {
 	return s.homeApp.Update(ctx, app)
 }
 
 //lint:ignore U1000 "called by encore"
-//encore:api auth method=DELETE path=/v1/homes/:homeID tag:metrics tag:authorize_home
-func (s *Service) HomeDelete(ctx context.Context, homeID string) error {
-	return s.homeApp.Delete(ctx)
+//encore:api auth method=PATCH path=/v1/homes/:homeID tag:metrics tag:authorize_home tag:authorize_permission tag:audit
+func (s *Service) HomePatch(ctx context.Context, homeID string, app homeapp.PatchHome) (homeapp.Home, error) {
+	return s.homeApp.Patch(ctx, app)
 }
 
 //lint:ignore U1000 "called by encore"
-//encore:api auth method=GET path=/v1/homes tag:metrics tag:authorize tag:as_any_role
+//encore:api auth method=DELETE path=/v1/homes/:homeID tag:metrics tag:authorize_home tag:authorize_permission tag:audit
+func (s *Service) HomeDelete(ctx context.Context, homeID string, pc etag.Precondition) error {
+	return s.homeApp.Delete(ctx, pc)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=POST path=/v1/bulk/homes/delete tag:body_large tag:transaction tag:metrics tag:authorize tag:authorize_permission tag:audit
+func (s *Service) HomeDeleteMany(ctx context.Context, app bulk.IDs) (bulk.Result, error) {
+	return s.homeApp.DeleteMany(ctx, app, s.authorizeOwner)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=GET path=/v1/homes tag:metrics tag:authorize tag:preferences tag:cache
 func (s *Service) HomeQuery(ctx context.Context, qp homeapp.QueryParams) (query.Result[homeapp.Home], error) {
 	return s.homeApp.Query(ctx, qp)
 }
 
 //lint:ignore U1000 "called by encore"
-//encore:api auth method=GET path=/v1/homes/:productID tag:metrics tag:authorize_home
+//encore:api auth method=GET path=/v1/homes/:productID tag:metrics tag:authorize_home tag:cache
 func (s *Service) HomeQueryByID(ctx context.Context, productID string) (homeapp.Home, error) {
 	return s.homeApp.QueryByID(ctx)
 }
@@ -64,39 +195,161 @@ func (s *Service) HomeQueryByID(ctx context.Context, productID string) (homeapp.
 // =============================================================================
 
 //lint:ignore U1000 "called by encore"
-//encore:api auth method=POST path=/v1/products tag:metrics tag:authorize tag:as_user_role
+//encore:api auth method=GET path=/v1/jobs/:jobID tag:metrics tag:authorize_job
+func (s *Service) JobQueryByID(ctx context.Context, jobID string) (jobapp.Job, error) {
+	return s.jobApp.QueryByID(ctx)
+}
+
+// =============================================================================
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=GET path=/v1/preferences tag:metrics
+func (s *Service) PreferenceQuery(ctx context.Context) (userprefsapp.Preferences, error) {
+	return s.userPrefsApp.Query(ctx)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=PUT path=/v1/preferences/:entity tag:metrics tag:audit
+func (s *Service) PreferenceSet(ctx context.Context, entity string, app userprefsapp.SetPreference) (userprefsapp.Preference, error) {
+	return s.userPrefsApp.Set(ctx, entity, app)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=DELETE path=/v1/preferences/:entity tag:metrics tag:audit
+func (s *Service) PreferenceDelete(ctx context.Context, entity string) error {
+	return s.userPrefsApp.Delete(ctx, entity)
+}
+
+// =============================================================================
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=POST path=/v1/products tag:idempotent tag:metrics tag:authorize tag:authorize_permission tag:audit
 func (s *Service) ProductCreate(ctx context.Context, app productapp.NewProduct) (productapp.Product, error) {
 	return s.productApp.Create(ctx, app)
 }
 
 //lint:ignore U1000 "called by encore"
-//encore:api auth method=PUT path=/v1/products/:productID tag:metrics tag:authorize_product
+//encore:api auth method=PUT path=/v1/products/:productID tag:metrics tag:authorize_product tag:authorize_permission tag:audit
 func (s *Service) ProductUpdate(ctx context.Context, productID string, app productapp.UpdateProduct) (productapp.Product, error) {
 	return s.productApp.Update(ctx, app)
 }
 
 //lint:ignore U1000 "called by encore"
-//encore:api auth method=DELETE path=/v1/products/:productID tag:metrics tag:authorize_product
-func (s *Service) ProductDelete(ctx context.Context, productID string) error {
-	return s.productApp.Delete(ctx)
+//encore:api auth method=DELETE path=/v1/products/:productID tag:metrics tag:authorize_product tag:authorize_permission tag:audit
+func (s *Service) ProductDelete(ctx context.Context, productID string, pc etag.Precondition) error {
+	return s.productApp.Delete(ctx, pc)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=POST path=/v1/bulk/products/delete tag:body_large tag:transaction tag:metrics tag:authorize tag:authorize_permission tag:audit
+func (s *Service) ProductDeleteMany(ctx context.Context, app bulk.IDs) (bulk.Result, error) {
+	return s.productApp.DeleteMany(ctx, app, s.authorizeOwner)
 }
 
 //lint:ignore U1000 "called by encore"
-//encore:api auth method=GET path=/v1/products tag:metrics tag:authorize tag:as_any_role
+//encore:api auth method=GET path=/v1/products tag:metrics tag:authorize tag:preferences tag:cache
 func (s *Service) ProductQuery(ctx context.Context, qp productapp.QueryParams) (query.Result[productapp.Product], error) {
 	return s.productApp.Query(ctx, qp)
 }
 
 //lint:ignore U1000 "called by encore"
-//encore:api auth method=GET path=/v1/products/:productID tag:metrics tag:authorize_product
+//encore:api auth method=GET path=/v1/products/search/:name tag:metrics tag:authorize tag:preferences tag:limit
+func (s *Service) ProductQuerySaved(ctx context.Context, name string, qp productapp.QueryParams) (query.Result[productapp.Product], error) {
+	if err := s.savedSearchApp.Apply(ctx, savedsearchapp.Products, name, &qp); err != nil {
+		return query.Result[productapp.Product]{}, err
+	}
+
+	return s.productApp.Query(ctx, qp)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=GET path=/v1/products/:productID tag:metrics tag:authorize_product tag:cache
 func (s *Service) ProductQueryByID(ctx context.Context, productID string) (productapp.Product, error) {
 	return s.productApp.QueryByID(ctx)
 }
 
+// =============================================================================
+// Version 2 of the product api reports the cost as money. The v1 endpoints
+// above remain for existing clients and share the same business rules.
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=POST path=/v2/products tag:idempotent tag:metrics tag:authorize tag:authorize_permission tag:audit
+func (s *Service) ProductV2Create(ctx context.Context, app productv2app.NewProduct) (productv2app.Product, error) {
+	return s.productV2App.Create(ctx, app)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=PUT path=/v2/products/:productID tag:metrics tag:authorize_product tag:authorize_permission tag:audit
+func (s *Service) ProductV2Update(ctx context.Context, productID string, app productv2app.UpdateProduct) (productv2app.Product, error) {
+	return s.productV2App.Update(ctx, app)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=GET path=/v2/products tag:metrics tag:authorize tag:preferences tag:cache
+func (s *Service) ProductV2Query(ctx context.Context, qp productapp.QueryParams) (query.Result[productv2app.Product], error) {
+	return s.productV2App.Query(ctx, qp)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=GET path=/v2/products/:productID tag:metrics tag:authorize_product tag:cache
+func (s *Service) ProductV2QueryByID(ctx context.Context, productID string) (productv2app.Product, error) {
+	return s.productV2App.QueryByID(ctx)
+}
+
+// =============================================================================
+
+//lint:ignore U1000 "called by encore"
+//encore:api private method=POST path=/v1/idempotency/expire
+func (s *Service) IdempotencyDeleteExpired(ctx context.Context) error {
+	return s.idemApp.DeleteExpired(ctx)
+}
+
+// =============================================================================
+
+//lint:ignore U1000 "called by encore"
+//encore:api private method=POST path=/v1/reports/daily
+func (s *Service) ReportBuildDaily(ctx context.Context) error {
+	return s.reportApp.BuildDaily(ctx)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=POST path=/v1/reports/build tag:metrics tag:authorize tag:audit
+func (s *Service) ReportBuild(ctx context.Context, app reportapp.BuildReport) (jobapp.Accepted, error) {
+	return s.reportApp.Build(ctx, app)
+}
+
 // =============================================================================
 
 //lint:ignore U1000 "called by encore"
-//encore:api auth method=POST path=/v1/tran tag:transaction tag:metrics tag:authorize tag:as_admin_role
+//encore:api auth method=GET path=/v1/search tag:metrics tag:limit
+func (s *Service) Search(ctx context.Context, qp searchapp.QueryParams) (searchapp.Result, error) {
+	return s.searchApp.Query(ctx, qp)
+}
+
+// =============================================================================
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=GET path=/v1/searches tag:metrics
+func (s *Service) SavedSearchQuery(ctx context.Context) (savedsearchapp.SavedSearches, error) {
+	return s.savedSearchApp.Query(ctx)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=PUT path=/v1/searches/:entity/:name tag:metrics tag:audit
+func (s *Service) SavedSearchSave(ctx context.Context, entity string, name string, app savedsearchapp.SaveSearch) (savedsearchapp.SavedSearch, error) {
+	return s.savedSearchApp.Save(ctx, entity, name, app)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=DELETE path=/v1/searches/:entity/:name tag:metrics tag:audit
+func (s *Service) SavedSearchDelete(ctx context.Context, entity string, name string) error {
+	return s.savedSearchApp.Delete(ctx, entity, name)
+}
+
+// =============================================================================
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=POST path=/v1/tran tag:idempotent tag:transaction tag:metrics tag:authorize tag:audit
 func (s *Service) TranCreate(ctx context.Context, app tranapp.NewTran) (tranapp.Product, error) {
 	return s.tranApp.Create(ctx, app)
 }
@@ -104,37 +357,43 @@ func (s *Service) TranCreate(ctx context.Context, app tranapp.NewTran) (tranapp.
 // =============================================================================
 
 //lint:ignore U1000 "called by encore"
-//encore:api auth method=POST path=/v1/users tag:metrics tag:authorize tag:as_admin_role
+//encore:api auth method=POST path=/v1/users tag:idempotent tag:metrics tag:authorize tag:audit
 func (s *Service) UserCreate(ctx context.Context, app userapp.NewUser) (userapp.User, error) {
 	return s.userApp.Create(ctx, app)
 }
 
 //lint:ignore U1000 "called by encore"
-//encore:api auth method=PUT path=/v1/users/:userID tag:metrics tag:authorize_user
+//encore:api auth method=PUT path=/v1/users/:userID tag:metrics tag:authorize_user tag:audit
 func (s *Service) UserUpdate(ctx context.Context, userID string, app userapp.UpdateUser) (userapp.User, error) {
 	return s.userApp.Update(ctx, app)
 }
 
 //lint:ignore U1000 "called by encore"
-//encore:api auth method=PUT path=/v1/role/:userID tag:metrics tag:authorize_user tag:as_admin_role
+//encore:api auth method=PATCH path=/v1/users/:userID tag:metrics tag:authorize_user tag:audit
+func (s *Service) UserPatch(ctx context.Context, userID string, app userapp.PatchUser) (userapp.User, error) {
+	return s.userApp.Patch(ctx, app)
+}
+
+//lint:ignore U1000 "called by encore"
+//encore:api auth method=PUT path=/v1/role/:userID tag:metrics tag:authorize_user tag:audit
 func (s *Service) UserUpdateRole(ctx context.Context, userID string, app userapp.UpdateUserRole) (userapp.User, error) {
 	return s.userApp.UpdateRole(ctx, app)
 }
 
 //lint:ignore U1000 "called by encore"
-//encore:api auth method=DELETE path=/v1/users/:userID tag:metrics tag:authorize_user
-func (s *Service) UserDelete(ctx context.Context, userID string) error {
-	return s.userApp.Delete(ctx)
+//encore:api auth method=DELETE path=/v1/users/:userID tag:metrics tag:authorize_user tag:audit
+func (s *Service) UserDelete(ctx context.Context, userID string, pc etag.Precondition) error {
+	return s.userApp.Delete(ctx, pc)
 }
 
 //lint:ignore U1000 "called by encore"
-//encore:api auth method=GET path=/v1/users tag:metrics tag:authorize tag:as_admin_role
+//encore:api auth method=GET path=/v1/users tag:metrics tag:authorize tag:preferences tag:cache
 func (s *Service) UserQuery(ctx context.Context, qp userapp.QueryParams) (query.Result[userapp.User], error) {
 	return s.userApp.Query(ctx, qp)
 }
 
 //lint:ignore U1000 "called by encore"
-//encore:api auth method=GET path=/v1/users/:userID tag:metrics tag:authorize_user
+//encore:api auth method=GET path=/v1/users/:userID tag:metrics tag:authorize_user tag:cache
 func (s *Service) UserQueryByID(ctx context.Context, userID string) (userapp.User, error) {
 	return s.userApp.QueryByID(ctx)
 }
@@ -142,7 +401,7 @@ func (s *Service) UserQueryByID(ctx context.Context, userID string) (userapp.Use
 // =============================================================================
 
 //lint:ignore U1000 "called by encore"
-//encore:api auth method=GET path=/v1/vproducts tag:metrics tag:authorize tag:as_admin_role
+//encore:api auth method=GET path=/v1/vproducts tag:metrics tag:authorize tag:cache tag:limit
 func (s *Service) VProductQuery(ctx context.Context, qp vproductapp.QueryParams) (query.Result[vproductapp.Product], error) {
 	return s.vproductApp.Query(ctx, qp)
 }
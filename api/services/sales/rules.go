@@ -0,0 +1,78 @@
+package sales
+
+import (
+	"github.com/ardanlabs/encore/app/sdk/auth"
+	"github.com/ardanlabs/encore/app/sdk/mid"
+)
+
+// authRules is the authorization rule each endpoint requires, applied by the
+// authorize middleware the endpoint is tagged with. An endpoint that isn't
+// listed gets the default of its middleware, admins only for authorize and
+// admins or the owner for the middleware that load an entity from the route.
+// The raw endpoints pick their rule by the resource they are called for.
+var authRules = mid.Rules{
+	"AdminStatus":         auth.RuleAdminOnly,
+	"AdminSetLogLevel":    auth.RuleAdminOnly,
+	"AdminFlushCache":     auth.RuleAdminOnly,
+	"AdminSetMaintenance": auth.RuleAdminOnly,
+
+	"DeadLetterQuery":     auth.RuleAdminOnly,
+	"DeadLetterQueryByID": auth.RuleAdminOnly,
+	"DeadLetterReplay":    auth.RuleAdminOnly,
+
+	"HomeCreate":     auth.RuleUserOnly,
+	"HomeUpdate":     auth.RuleAdminOrSubject,
+	"HomePatch":      auth.RuleAdminOrSubject,
+	"HomeDelete":     auth.RuleAdminOrSubject,
+	"HomeDeleteMany": auth.RuleAny,
+	"HomeQuery":      auth.RuleAny,
+	"HomeQueryByID":  auth.RuleAdminOrSubject,
+
+	"JobQueryByID": auth.RuleAdminOrSubject,
+
+	"ProductCreate":     auth.RuleUserOnly,
+	"ProductUpdate":     auth.RuleAdminOrSubject,
+	"ProductDelete":     auth.RuleAdminOrSubject,
+	"ProductDeleteMany": auth.RuleAny,
+	"ProductQuery":      auth.RuleAny,
+	"ProductQuerySaved": auth.RuleAny,
+	"ProductQueryByID":  auth.RuleAdminOrSubject,
+
+	"ProductV2Create":    auth.RuleUserOnly,
+	"ProductV2Update":    auth.RuleAdminOrSubject,
+	"ProductV2Query":     auth.RuleAny,
+	"ProductV2QueryByID": auth.RuleAdminOrSubject,
+
+	"ReportBuild": auth.RuleAdminOnly,
+
+	"TranCreate": auth.RuleAdminOnly,
+
+	"UserCreate":     auth.RuleAdminOnly,
+	"UserUpdate":     auth.RuleAdminOrSubject,
+	"UserPatch":      auth.RuleAdminOrSubject,
+	"UserUpdateRole": auth.RuleAdminOnly,
+	"UserDelete":     auth.RuleAdminOrSubject,
+	"UserQuery":      auth.RuleAdminOnly,
+	"UserQueryByID":  auth.RuleAdminOrSubject,
+
+	"VProductQuery": auth.RuleAdminOnly,
+}
+
+// authPermissions is the permission each endpoint tagged with the
+// authorize_permission middleware requires, on top of its rule. The
+// permissions granted to each role are stored in the database.
+var authPermissions = mid.Permissions{
+	"HomeCreate":     "home:write",
+	"HomeUpdate":     "home:write",
+	"HomePatch":      "home:write",
+	"HomeDelete":     "home:delete",
+	"HomeDeleteMany": "home:delete",
+
+	"ProductCreate":     "product:write",
+	"ProductUpdate":     "product:write",
+	"ProductDelete":     "product:delete",
+	"ProductDeleteMany": "product:delete",
+
+	"ProductV2Create": "product:write",
+	"ProductV2Update": "product:write",
+}
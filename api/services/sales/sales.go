@@ -7,29 +7,71 @@ import (
 	"fmt"
 	"net/http"
 	"runtime"
+	"time"
 
 	"encore.dev"
 	esqldb "encore.dev/storage/sqldb"
 	"github.com/ardanlabs/conf/v3"
+	"github.com/ardanlabs/encore/app/domain/adminapp"
+	"github.com/ardanlabs/encore/app/domain/deadletterapp"
 	"github.com/ardanlabs/encore/app/domain/homeapp"
+	"github.com/ardanlabs/encore/app/domain/idempotencyapp"
+	"github.com/ardanlabs/encore/app/domain/jobapp"
 	"github.com/ardanlabs/encore/app/domain/productapp"
+	"github.com/ardanlabs/encore/app/domain/reportapp"
+	"github.com/ardanlabs/encore/app/domain/savedsearchapp"
+	"github.com/ardanlabs/encore/app/domain/searchapp"
 	"github.com/ardanlabs/encore/app/domain/tranapp"
 	"github.com/ardanlabs/encore/app/domain/userapp"
+	"github.com/ardanlabs/encore/app/domain/userprefsapp"
+	productv2app "github.com/ardanlabs/encore/app/domain/v2/productapp"
 	"github.com/ardanlabs/encore/app/domain/vproductapp"
+	"github.com/ardanlabs/encore/app/sdk/about"
+	"github.com/ardanlabs/encore/app/sdk/allowlist"
+	"github.com/ardanlabs/encore/app/sdk/batch"
+	"github.com/ardanlabs/encore/app/sdk/bodylimit"
+	"github.com/ardanlabs/encore/app/sdk/cache"
 	"github.com/ardanlabs/encore/app/sdk/debug"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/health"
+	"github.com/ardanlabs/encore/app/sdk/i18n"
+	"github.com/ardanlabs/encore/app/sdk/limiter"
+	"github.com/ardanlabs/encore/app/sdk/links"
+	"github.com/ardanlabs/encore/app/sdk/maintenance"
 	"github.com/ardanlabs/encore/app/sdk/metrics"
+	"github.com/ardanlabs/encore/app/sdk/prefs"
+	"github.com/ardanlabs/encore/app/sdk/reqlog"
+	"github.com/ardanlabs/encore/app/sdk/requestid"
+	"github.com/ardanlabs/encore/business/domain/auditbus"
+	"github.com/ardanlabs/encore/business/domain/auditbus/stores/auditdb"
+	"github.com/ardanlabs/encore/business/domain/deadletterbus"
+	"github.com/ardanlabs/encore/business/domain/deadletterbus/stores/deadletterdb"
 	"github.com/ardanlabs/encore/business/domain/homebus"
 	"github.com/ardanlabs/encore/business/domain/homebus/stores/homedb"
+	"github.com/ardanlabs/encore/business/domain/idempotencybus"
+	"github.com/ardanlabs/encore/business/domain/idempotencybus/stores/idempotencydb"
+	"github.com/ardanlabs/encore/business/domain/jobbus"
+	"github.com/ardanlabs/encore/business/domain/jobbus/stores/jobdb"
 	"github.com/ardanlabs/encore/business/domain/productbus"
 	"github.com/ardanlabs/encore/business/domain/productbus/stores/productdb"
+	"github.com/ardanlabs/encore/business/domain/reportbus"
+	"github.com/ardanlabs/encore/business/domain/reportbus/stores/reportdb"
+	"github.com/ardanlabs/encore/business/domain/savedsearchbus"
+	"github.com/ardanlabs/encore/business/domain/savedsearchbus/stores/savedsearchdb"
 	"github.com/ardanlabs/encore/business/domain/userbus"
 	"github.com/ardanlabs/encore/business/domain/userbus/stores/userdb"
+	"github.com/ardanlabs/encore/business/domain/userprefsbus"
+	"github.com/ardanlabs/encore/business/domain/userprefsbus/stores/userprefsdb"
 	"github.com/ardanlabs/encore/business/domain/vproductbus"
 	"github.com/ardanlabs/encore/business/domain/vproductbus/stores/vproductdb"
 	"github.com/ardanlabs/encore/business/sdk/appdb/migrate"
 	"github.com/ardanlabs/encore/business/sdk/delegate"
+	bpubsub "github.com/ardanlabs/encore/business/sdk/pubsub"
 	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/foundation/clock"
 	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/ardanlabs/encore/foundation/otel"
+	"github.com/ardanlabs/encore/foundation/web"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -43,10 +85,24 @@ var appDB = esqldb.Named("app")
 //
 //encore:service
 type Service struct {
-	log   *logger.Logger
-	mtrcs *metrics.Values
-	db    *sqlx.DB
-	debug http.Handler
+	log             *logger.Logger
+	mtrcs           *metrics.Values
+	db              *sqlx.DB
+	debug           http.Handler
+	cache           *cache.Cache
+	features        map[string]bool
+	limiters        *limiter.Groups
+	bodyLimit       bodylimit.Limits
+	reqLog          reqlog.Config
+	exporters       map[string]exporter
+	streamers       map[string]streamer
+	openapi         []byte
+	health          *health.Checker
+	mode            *maintenance.Mode
+	batch           *batch.Router
+	adminNets       *allowlist.List
+	proxies         *allowlist.List
+	shutdownTracing func(ctx context.Context) error
 	appDomain
 	busDomain
 }
@@ -54,31 +110,175 @@ type Service struct {
 // NewService is called to create a new encore Service.
 func NewService(log *logger.Logger, db *sqlx.DB) (*Service, error) {
 	delegate := delegate.New(log)
-	userBus := userbus.NewBusiness(log, delegate, userdb.NewStore(log, db))
-	productBus := productbus.NewBusiness(log, userBus, delegate, productdb.NewStore(log, db))
-	homeBus := homebus.NewBusiness(log, userBus, delegate, homedb.NewStore(log, db))
+	userBus := userbus.NewBusiness(log, clock.System{}, delegate, userdb.NewStore(log, db))
+	productBus := productbus.NewBusiness(log, clock.System{}, userBus, delegate, productdb.NewStore(log, db))
+	homeBus := homebus.NewBusiness(log, clock.System{}, userBus, delegate, homedb.NewStore(log, db))
 	vproductBus := vproductbus.NewBusiness(vproductdb.NewStore(log, db))
+	jobBus := jobbus.NewBusiness(log, bpubsub.JobPublisher{}, jobdb.NewStore(log, db))
+
+	// There is no notification system in place yet, so the daily report is
+	// built without sending a summary.
+	reportBus := reportbus.NewBusiness(log, nil, reportdb.NewStore(log, db))
+
+	// Responses to requests made with an idempotency key are replayed for a
+	// day, which covers any reasonable client retry policy.
+	idempotencyBus := idempotencybus.NewBusiness(log, clock.System{}, 24*time.Hour, idempotencydb.NewStore(log, db))
+
+	// Admin controls and other sensitive actions are recorded here.
+	auditBus := auditbus.NewBusiness(log, auditdb.NewStore(log, db))
+
+	// The page size and order a user prefers for a list are used when a
+	// query doesn't provide them.
+	userPrefsBus := userprefsbus.NewBusiness(log, userprefsdb.NewStore(log, db))
+
+	// Users can save a search by name and run it again later.
+	savedSearchBus := savedsearchbus.NewBusiness(log, savedsearchdb.NewStore(log, db))
+
+	// Dead letters can be replayed back to the topic they were received on.
+	deadLetterBus := deadletterbus.NewBusiness(log, clock.System{}, deadletterdb.NewStore(log, db))
+	deadLetterBus.RegisterReplay(bpubsub.Delegate.Meta().Name, bpubsub.Replay(bpubsub.Delegate))
+	deadLetterBus.RegisterReplay(bpubsub.Jobs.Meta().Name, bpubsub.Replay(bpubsub.Jobs))
+	deadLetterBus.RegisterReplay(bpubsub.Audits.Meta().Name, bpubsub.Replay(bpubsub.Audits))
+
+	// Cached responses are kept for a short period of time and are cleared
+	// when a domain reports a mutation through the delegate system.
+	respCache := cache.New(30 * time.Second)
+	registerCacheInvalidation(delegate, respCache)
+
+	// The set of optional features enabled for this instance, reported by
+	// the about endpoint for deploy verification.
+	features := map[string]bool{
+		"responseCache":       true,
+		"demoSeed":            migrate.CanSeed(encore.Meta().Environment),
+		"reportNotifications": false,
+		"deadLetterReplay":    true,
+	}
+
+	// Expensive endpoints share a small number of slots per group so a
+	// burst can't exhaust the database connections.
+	limiters, err := newLimiters()
+	if err != nil {
+		return nil, fmt.Errorf("limiters: %w", err)
+	}
+
+	// The service is ready when it can talk to the database and the
+	// response cache.
+	checker := health.New(
+		health.Check{Name: "database", Check: func(ctx context.Context) error { return sqldb.StatusCheck(ctx, db) }},
+		health.Check{Name: "cache", Check: respCache.Ping},
+	)
+
+	// Request bodies are kept small except for the endpoints that work on
+	// many items at once.
+	bodyLimits := bodylimit.Limits{
+		Default: 64 << 10,
+		Large:   5 << 20,
+	}
+
+	// Every request is logged, with the body of a share of them once their
+	// secrets are redacted. No bodies are logged in production.
+	reqLog := reqlog.ForEnvironment(encore.Meta().Environment)
+
+	errs.SetDebug(encore.Meta().Environment)
+	errs.SetTranslator(i18n.Translator{})
+	web.SetErrorHandler(errs.HTTPError)
+
+	mux := debug.Mux()
+	mux.HandleFunc("/debug/about", about.Handler(db, features))
+	mux.HandleFunc("/healthz", checker.LivenessHandler())
+	mux.HandleFunc("/readyz", checker.ReadinessHandler())
+
+	// Links in responses are built from the base URL clients use to reach
+	// the service.
+	lb := links.New(encore.Meta().APIBaseURL.String())
+
+	productApp := productapp.NewApp(productBus, userBus, lb)
+
+	// The entities a user can store list preferences for, with the check
+	// for the orders their queries accept.
+	prefEntities := map[string]userprefsapp.OrderByFunc{
+		prefs.Homes:    homeapp.ValidateOrderBy,
+		prefs.Products: productapp.ValidateOrderBy,
+		prefs.Users:    userapp.ValidateOrderBy,
+	}
+
+	// The entities a user can save searches for.
+	searchEntities := map[string]savedsearchapp.Entity{
+		savedsearchapp.Products: {Params: productapp.QueryParams{}, Validate: productapp.ValidateSearch},
+	}
+
+	// Maintenance mode starts off and is turned on by an admin.
+	mode := maintenance.Mode{}
+
+	// Reports can be built on demand for any day, which is long running
+	// work that's handed off to a background job.
+	jobApp := jobapp.NewApp(jobBus)
+	reportApp := reportapp.NewApp(reportBus, jobApp)
+	jobBus.Register(reportapp.JobBuild, reportApp.RunBuild)
+
+	app := appDomain{
+		adminApp:       adminapp.NewApp(log, respCache, &mode, auditBus),
+		deadLetterApp:  deadletterapp.NewApp(deadLetterBus),
+		userApp:        userapp.NewApp(userBus, lb),
+		userPrefsApp:   userprefsapp.NewApp(userPrefsBus, prefEntities),
+		productApp:     productApp,
+		productV2App:   productv2app.NewApp(productApp),
+		reportApp:      reportApp,
+		savedSearchApp: savedsearchapp.NewApp(savedSearchBus, searchEntities),
+		searchApp:      searchapp.NewApp(searchSources()...),
+		homeApp:        homeapp.NewApp(homeBus, userBus, lb),
+		idemApp:        idempotencyapp.NewApp(idempotencyBus),
+		jobApp:         jobApp,
+		tranApp:        tranapp.NewApp(userBus, productBus),
+		vproductApp:    vproductapp.NewApp(vproductBus),
+	}
+
+	openapi, err := newOpenAPI(encore.Meta().Build.Revision)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+
+	// The admin and debug endpoints are only reachable from the private
+	// networks until initService applies the networks configured for the
+	// environment.
+	adminNets, err := allowlist.Parse(allowlist.Private)
+	if err != nil {
+		return nil, fmt.Errorf("admin allow list: %w", err)
+	}
 
 	s := Service{
-		log:   log,
-		mtrcs: newMetrics(),
-		db:    db,
-		debug: debug.Mux(),
-		appDomain: appDomain{
-			userApp:     userapp.NewApp(userBus),
-			productApp:  productapp.NewApp(productBus),
-			homeApp:     homeapp.NewApp(homeBus),
-			tranApp:     tranapp.NewApp(userBus, productBus),
-			vproductApp: vproductapp.NewApp(vproductBus),
-		},
+		log:       log,
+		mtrcs:     newMetrics(),
+		db:        db,
+		debug:     mux,
+		cache:     respCache,
+		features:  features,
+		limiters:  limiters,
+		bodyLimit: bodyLimits,
+		reqLog:    reqLog,
+		exporters: newExporters(app),
+		streamers: newStreamers(app),
+		openapi:   openapi,
+		health:    checker,
+		mode:      &mode,
+		adminNets: adminNets,
+		proxies:   &allowlist.List{},
+		appDomain: app,
 		busDomain: busDomain{
-			delegate:   delegate,
-			userBus:    userBus,
-			productBus: productBus,
-			homeBus:    homeBus,
+			delegate:       delegate,
+			auditBus:       auditBus,
+			deadLetterBus:  deadLetterBus,
+			userBus:        userBus,
+			userPrefsBus:   userPrefsBus,
+			productBus:     productBus,
+			homeBus:        homeBus,
+			idempotencyBus: idempotencyBus,
+			jobBus:         jobBus,
 		},
 	}
 
+	s.batch = s.newBatchRouter()
+
 	return &s, nil
 }
 
@@ -91,6 +291,13 @@ func (s *Service) Shutdown(force context.Context) {
 
 	s.log.Info(ctx, "shutdown", "status", "stopping database support")
 	s.db.Close()
+
+	if s.shutdownTracing != nil {
+		s.log.Info(ctx, "shutdown", "status", "flushing traces")
+		if err := s.shutdownTracing(force); err != nil {
+			s.log.Error(ctx, "shutdown", "status", "flushing traces", "ERROR", err)
+		}
+	}
 }
 
 // =============================================================================
@@ -99,17 +306,33 @@ func (s *Service) Shutdown(force context.Context) {
 //
 //lint:ignore U1000 "called by encore"
 func initService() (*Service, error) {
-	log := logger.New("sales")
+	log := logger.NewWithRequestID("sales", logger.Events{}, requestid.Get)
 
-	db, err := startup(log)
+	sup, err := startup(log)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewService(log, db)
+	s, err := NewService(log, sup.db)
+	if err != nil {
+		return nil, err
+	}
+	s.shutdownTracing = sup.shutdownTracing
+	s.adminNets = sup.adminNets
+	s.proxies = sup.proxies
+
+	return s, nil
+}
+
+// support holds what startup builds from the configuration for the service.
+type support struct {
+	db              *sqlx.DB
+	shutdownTracing func(ctx context.Context) error
+	adminNets       *allowlist.List
+	proxies         *allowlist.List
 }
 
-func startup(log *logger.Logger) (*sqlx.DB, error) {
+func startup(log *logger.Logger) (support, error) {
 	ctx := context.Background()
 
 	// -------------------------------------------------------------------------
@@ -126,6 +349,15 @@ func startup(log *logger.Logger) (*sqlx.DB, error) {
 			MaxIdleConns int `conf:"default:0"`
 			MaxOpenConns int `conf:"default:0"`
 		}
+		Tempo struct {
+			Host        string
+			ServiceName string  `conf:"default:sales"`
+			Probability float64 `conf:"default:0.05"`
+		}
+		Admin struct {
+			AllowedCIDRs   []string
+			TrustedProxies []string
+		}
 	}{
 		Version: conf.Version{
 			Build: encore.Meta().Environment.Name,
@@ -138,9 +370,9 @@ func startup(log *logger.Logger) (*sqlx.DB, error) {
 	if err != nil {
 		if errors.Is(err, conf.ErrHelpWanted) {
 			fmt.Println(help)
-			return nil, err
+			return support{}, err
 		}
-		return nil, fmt.Errorf("parsing config: %w", err)
+		return support{}, fmt.Errorf("parsing config: %w", err)
 	}
 
 	// -------------------------------------------------------------------------
@@ -150,10 +382,52 @@ func startup(log *logger.Logger) (*sqlx.DB, error) {
 
 	out, err := conf.String(&cfg)
 	if err != nil {
-		return nil, fmt.Errorf("generating config for output: %w", err)
+		return support{}, fmt.Errorf("generating config for output: %w", err)
 	}
 	log.Info(ctx, "initService", "config", out)
 
+	// -------------------------------------------------------------------------
+	// Start Tracing Support
+
+	// Spans are only exported when a collector host is configured. Encore's own
+	// tracing is always available.
+
+	log.Info(ctx, "initService", "status", "initializing tracing support", "host", cfg.Tempo.Host)
+
+	shutdownTracing, err := otel.InitTracing(ctx, otel.Config{
+		ServiceName: cfg.Tempo.ServiceName,
+		Host:        cfg.Tempo.Host,
+		Probability: cfg.Tempo.Probability,
+	})
+	if err != nil {
+		return support{}, fmt.Errorf("starting tracing: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+	// Admin Access Support
+
+	// The admin and debug endpoints are only reachable from the networks
+	// configured for the environment, the private networks when none are.
+
+	cidrs := cfg.Admin.AllowedCIDRs
+	if len(cidrs) == 0 {
+		cidrs = allowlist.Private
+	}
+
+	adminNets, err := allowlist.Parse(cidrs)
+	if err != nil {
+		return support{}, fmt.Errorf("parsing admin allow list: %w", err)
+	}
+
+	// The client is taken from the X-Forwarded-For header only for the hops
+	// appended by the proxies in front of the service, since a client can
+	// write anything in it. None are trusted by default.
+
+	proxies, err := allowlist.Parse(cfg.Admin.TrustedProxies)
+	if err != nil {
+		return support{}, fmt.Errorf("parsing trusted proxies: %w", err)
+	}
+
 	// -------------------------------------------------------------------------
 	// Database Support
 
@@ -165,12 +439,27 @@ func startup(log *logger.Logger) (*sqlx.DB, error) {
 		MaxOpenConns: cfg.DB.MaxOpenConns,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("connecting to db: %w", err)
+		return support{}, fmt.Errorf("connecting to db: %w", err)
 	}
 
-	if err := migrate.Seed(context.Background(), db); err != nil {
-		return nil, fmt.Errorf("seeding the db: %w", err)
+	// -------------------------------------------------------------------------
+	// Seeding Support
+
+	// Demo data is only seeded for local and preview environments. The seed
+	// is recorded in the database so restarts don't apply it again.
+
+	if migrate.CanSeed(encore.Meta().Environment) {
+		log.Info(ctx, "initService", "status", "seeding database")
+
+		if err := migrate.Seed(ctx, db); err != nil {
+			return support{}, fmt.Errorf("seeding the db: %w", err)
+		}
 	}
 
-	return db, nil
+	return support{
+		db:              db,
+		shutdownTracing: shutdownTracing,
+		adminNets:       adminNets,
+		proxies:         proxies,
+	}, nil
 }
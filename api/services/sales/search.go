@@ -0,0 +1,97 @@
+package sales
+
+import (
+	"context"
+	"encoding/json"
+	"net/mail"
+	"strconv"
+
+	"github.com/ardanlabs/encore/app/domain/homeapp"
+	"github.com/ardanlabs/encore/app/domain/productapp"
+	"github.com/ardanlabs/encore/app/domain/searchapp"
+	"github.com/ardanlabs/encore/app/domain/userapp"
+)
+
+// searchSources returns the domains the search endpoint fans out to. The
+// sources call the query endpoints through Encore so the authorization for
+// each type applies to the caller, a user that can't query users won't find
+// any.
+func searchSources() []searchapp.Source {
+	return []searchapp.Source{
+		{Type: "user", Search: searchUsers},
+		{Type: "product", Search: searchProducts},
+		{Type: "home", Search: searchHomes},
+	}
+}
+
+func searchUsers(ctx context.Context, q string, limit int) ([]searchapp.Hit, error) {
+	qp := userapp.QueryParams{
+		Rows: strconv.Itoa(limit),
+		Name: q,
+	}
+
+	if _, err := mail.ParseAddress(q); err == nil {
+		qp.Name = ""
+		qp.Email = q
+	}
+
+	result, err := UserQuery(ctx, qp)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]searchapp.Hit, len(result.Items))
+	for i, usr := range result.Items {
+		hits[i] = newHit(usr.ID, usr.Name, usr)
+	}
+
+	return hits, nil
+}
+
+func searchProducts(ctx context.Context, q string, limit int) ([]searchapp.Hit, error) {
+	qp := productapp.QueryParams{
+		Rows: strconv.Itoa(limit),
+		Name: q,
+	}
+
+	result, err := ProductQuery(ctx, qp)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]searchapp.Hit, len(result.Items))
+	for i, prd := range result.Items {
+		hits[i] = newHit(prd.ID, prd.Name, prd)
+	}
+
+	return hits, nil
+}
+
+func searchHomes(ctx context.Context, q string, limit int) ([]searchapp.Hit, error) {
+	qp := homeapp.QueryParams{
+		Rows:    strconv.Itoa(limit),
+		Address: q,
+	}
+
+	result, err := HomeQuery(ctx, qp)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]searchapp.Hit, len(result.Items))
+	for i, hme := range result.Items {
+		hits[i] = newHit(hme.ID, hme.Address.Address1+", "+hme.Address.City, hme)
+	}
+
+	return hits, nil
+}
+
+func newHit(id string, title string, item any) searchapp.Hit {
+	data, _ := json.Marshal(item)
+
+	return searchapp.Hit{
+		ID:    id,
+		Title: title,
+		Item:  data,
+	}
+}
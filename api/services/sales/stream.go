@@ -0,0 +1,85 @@
+package sales
+
+import (
+	"errors"
+	"net/http"
+
+	"encore.dev"
+	"github.com/ardanlabs/encore/app/sdk/auth"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/stream"
+	"github.com/ardanlabs/encore/foundation/web"
+)
+
+// streamer represents a query that can be streamed and the auth rule the
+// caller must pass, matching the rule of the JSON endpoint.
+type streamer struct {
+	rule string
+	fn   stream.Func
+}
+
+func newStreamers(app appDomain) map[string]streamer {
+	return map[string]streamer{
+		"homes": {
+			rule: auth.RuleAny,
+			fn:   stream.NDJSON(app.homeApp.QueryStream),
+		},
+		"products": {
+			rule: auth.RuleAny,
+			fn:   stream.NDJSON(app.productApp.QueryStream),
+		},
+		"users": {
+			rule: auth.RuleAdminOnly,
+			fn:   stream.NDJSON(app.userApp.QueryStream),
+		},
+	}
+}
+
+// Stream writes every result of a query as newline delimited JSON using the
+// same query string as the JSON endpoint, without paging. The rows are read
+// from the database one at a time so a client can process a large result
+// as it arrives. Raw endpoints don't write errors returned by middleware,
+// so authorization and limiting happen here. Errors are written as problem
+// details when the client accepts them.
+//
+//encore:api auth raw method=GET path=/v1/stream/:resource tag:metrics
+func (s *Service) Stream(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	resource := encore.CurrentRequest().PathParams.Get("resource")
+
+	str, exists := s.streamers[resource]
+	if !exists {
+		web.Error(w, r, errs.NewNotFound("stream", resource))
+		return
+	}
+
+	if !stream.Accepts(r.Header.Get("Accept")) {
+		web.Error(w, r, errs.Newf(errs.InvalidArgument, "stream %s is only available as %s", resource, stream.ContentType))
+		return
+	}
+
+	if err := s.authorizeRule(ctx, str.rule); err != nil {
+		web.Error(w, r, err)
+		return
+	}
+
+	l, _ := s.limiters.For("Stream")
+
+	release, err := l.Acquire(ctx)
+	if err != nil {
+		web.Error(w, r, limitError(l, err))
+		return
+	}
+	defer release()
+
+	w.Header().Set("Content-Type", stream.ContentType)
+
+	if err := str.fn(ctx, w, r.URL.Query()); err != nil {
+		s.log.Error(ctx, "stream", "resource", resource, "ERROR", err)
+
+		if !errors.Is(err, stream.ErrInterrupted) {
+			web.Error(w, r, err)
+		}
+	}
+}
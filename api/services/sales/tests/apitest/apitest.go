@@ -7,12 +7,16 @@ import (
 	"testing"
 	"time"
 
+	"encore.dev"
 	eauth "encore.dev/beta/auth"
 	eerrs "encore.dev/beta/errs"
 	"github.com/ardanlabs/encore/app/sdk/auth"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/links"
 	"github.com/ardanlabs/encore/business/domain/userbus"
 	"github.com/ardanlabs/encore/business/domain/userbus/stores/userdb"
 	"github.com/ardanlabs/encore/business/sdk/dbtest"
+	"github.com/ardanlabs/encore/business/sdk/unitest"
 	"github.com/golang-jwt/jwt/v4"
 )
 
@@ -32,6 +36,34 @@ func New(db *dbtest.Database, ath *auth.Auth, handler AuthHandler) *Test {
 	}
 }
 
+// LoadFixtures inserts the data declared in the fixtures file and returns
+// the users with a token generated for each of them.
+func (at *Test) LoadFixtures(t *testing.T, path string) SeedData {
+	t.Helper()
+
+	usd := at.DB.LoadFixtures(t, path)
+
+	toUsers := func(usrs []unitest.User) []User {
+		users := make([]User, len(usrs))
+		for i, usr := range usrs {
+			users[i] = User{
+				User:     usr.User,
+				Products: usr.Products,
+				Homes:    usr.Homes,
+				Token:    Token(at.DB, at.Auth, usr.Email.Address),
+			}
+		}
+		return users
+	}
+
+	sd := SeedData{
+		Users:  toUsers(usd.Users),
+		Admins: toUsers(usd.Admins),
+	}
+
+	return sd
+}
+
 // Run performs the actual test logic based on the table data.
 func (at *Test) Run(t *testing.T, table []Table, testName string) {
 	for _, tt := range table {
@@ -39,24 +71,24 @@ func (at *Test) Run(t *testing.T, table []Table, testName string) {
 			t.Logf("\n***** Running Test: %s *****\n", testName+"-"+tt.Name)
 			defer t.Logf("\n***** Finished Test: %s *****\n", testName+"-"+tt.Name)
 
-			ctx := context.Background()
+			ctx, cancel := dbtest.Context()
+			defer cancel()
+
+			var got any
 
 			ctx, err := at.authHandler(ctx, tt.Token)
-			if err != nil {
-				diff := tt.CmpFunc(err, tt.ExpResp)
-				if diff != "" {
-					t.Log("DIFF")
-					t.Logf("%s", diff)
-					t.Log("GOT")
-					t.Logf("%#v", err)
-					t.Log("EXP")
-					t.Logf("%#v", tt.ExpResp)
-					t.Fatalf("Should get the expected response")
-				}
-				return
+			switch {
+			case err != nil:
+				got = err
+			default:
+				got = tt.ExcFunc(ctx)
 			}
 
-			got := tt.ExcFunc(ctx)
+			checkResponse(t, tt, got)
+
+			if tt.CmpFunc == nil {
+				return
+			}
 
 			diff := tt.CmpFunc(got, tt.ExpResp)
 			if diff != "" {
@@ -93,15 +125,29 @@ func (at *Test) authHandler(ctx context.Context, token string) (context.Context,
 func CmpAppErrors(got any, exp any) string {
 	expResp := exp.(*eerrs.Error)
 
-	gotResp, exists := got.(*eerrs.Error)
+	gotErr, exists := got.(error)
+	if !exists {
+		return "no error occurred"
+	}
+
+	gotResp, exists := errs.As(gotErr)
 	if !exists {
 		return "no error occurred"
 	}
 
-	if gotResp.Code != expResp.Code {
+	if !errs.IsCode(gotResp, expResp.Code) {
 		return "code does not match"
 	}
 
+	// An application error code is stable, so when one is expected it's
+	// all that needs to match.
+	if code := errs.GetAppCode(expResp); code != "" {
+		if !errs.IsAppCode(gotResp, code) {
+			return "app code does not match"
+		}
+		return ""
+	}
+
 	if gotResp.Message != expResp.Message {
 		return "message does not match"
 	}
@@ -109,12 +155,20 @@ func CmpAppErrors(got any, exp any) string {
 	return ""
 }
 
+// Links constructs the builder the service uses for the links in responses.
+func Links() *links.Builder {
+	return links.New(encore.Meta().APIBaseURL.String())
+}
+
 // Token generates an authenticated token for a user.
 func Token(db *dbtest.Database, ath *auth.Auth, email string) string {
 	addr, _ := mail.ParseAddress(email)
 
+	ctx, cancel := dbtest.Context()
+	defer cancel()
+
 	store := userdb.NewStore(db.Log, db.DB)
-	dbUsr, err := store.QueryByEmail(context.Background(), *addr)
+	dbUsr, err := store.QueryByEmail(ctx, *addr)
 	if err != nil {
 		return ""
 	}
@@ -123,8 +177,8 @@ func Token(db *dbtest.Database, ath *auth.Auth, email string) string {
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   dbUsr.ID.String(),
 			Issuer:    ath.Issuer(),
-			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+			ExpiresAt: jwt.NewNumericDate(db.Clock.Now().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(db.Clock.Now()),
 		},
 		Roles: userbus.ParseRolesToString(dbUsr.Roles),
 	}
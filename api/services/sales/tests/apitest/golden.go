@@ -0,0 +1,101 @@
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// update is set to write the responses to the golden files instead of
+// comparing them, like with go test ./tests/productapi -update.
+var update = flag.Bool("update", false, "update the golden files with the responses")
+
+// Ignored replaces the values of the ignored fields in the golden files.
+const Ignored = "<ignored>"
+
+// Golden returns a function for the CmpFunc field of a table that compares
+// the response with the golden file once it's written as JSON. The values of
+// the fields with the specified JSON names are replaced at any depth, so
+// values like IDs and dates that change on every run aren't compared. The
+// ExpResp field of the table isn't used.
+func Golden(path string, ignore ...string) func(got any, exp any) string {
+	return func(got any, _ any) string {
+		data, err := snapshot(got, ignore)
+		if err != nil {
+			return fmt.Sprintf("snapshot: %s", err)
+		}
+
+		if *update {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return fmt.Sprintf("creating golden directory: %s", err)
+			}
+
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				return fmt.Sprintf("writing golden file: %s", err)
+			}
+
+			return ""
+		}
+
+		exp, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Sprintf("reading golden file, run with -update to create it: %s", err)
+		}
+
+		return cmp.Diff(string(data), string(exp))
+	}
+}
+
+func snapshot(v any, ignore []string) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	fields := make(map[string]bool, len(ignore))
+	for _, name := range ignore {
+		fields[name] = true
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(replaceIgnored(doc, fields)); err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func replaceIgnored(v any, fields map[string]bool) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for name, value := range v {
+			switch {
+			case fields[name]:
+				v[name] = Ignored
+			default:
+				v[name] = replaceIgnored(value, fields)
+			}
+		}
+
+	case []any:
+		for i, value := range v {
+			v[i] = replaceIgnored(value, fields)
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,45 @@
+package apitest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/encore/api/services/sales/tests/apitest"
+	"github.com/google/uuid"
+)
+
+type item struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DateCreated string `json:"dateCreated"`
+}
+
+type result struct {
+	Items []item `json:"items"`
+	Total int    `json:"total"`
+}
+
+func Test_Golden(t *testing.T) {
+	cmpFunc := apitest.Golden("testdata/golden.json", "id", "dateCreated")
+
+	got := result{
+		Items: []item{
+			{
+				ID:          uuid.NewString(),
+				Name:        "Comic Books",
+				DateCreated: time.Now().Format(time.RFC3339),
+			},
+		},
+		Total: 1,
+	}
+
+	if diff := cmpFunc(got, nil); diff != "" {
+		t.Fatalf("Should match the golden file with the ignored fields: %s", diff)
+	}
+
+	got.Items[0].Name = "McDonalds Toys"
+
+	if diff := cmpFunc(got, nil); diff == "" {
+		t.Fatalf("Should not match the golden file with a different name")
+	}
+}
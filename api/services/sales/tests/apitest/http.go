@@ -0,0 +1,232 @@
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// activeKID is the kid of the key the auth service signs tokens with by
+// default.
+const activeKID = "54bb2165-71e1-41a6-af3e-7da4a0e1e2c1"
+
+// HTTP sends requests to a running app so they go through the encore routing,
+// the auth handler, the middleware and the encoding of the responses like the
+// requests of any client. The base url of the app is read from the
+// APITEST_URL variable, like APITEST_URL=http://localhost:4000 after encore
+// run, and the test is skipped when it isn't set.
+//
+// When the APITEST_RECORD variable is set to a directory, every request sent
+// with Do is written there with its response as an Interaction, for the
+// contract tests to validate against the OpenAPI document.
+type HTTP struct {
+	t         *testing.T
+	baseURL   string
+	client    *http.Client
+	recordDir string
+	recorded  int
+}
+
+// NewHTTP constructs an HTTP value for running http tests or skips the test
+// when no app is running.
+func NewHTTP(t *testing.T) *HTTP {
+	baseURL := os.Getenv("APITEST_URL")
+	if baseURL == "" {
+		t.Skip("APITEST_URL is not set to the url of a running app")
+	}
+
+	return &HTTP{
+		t:       t,
+		baseURL: baseURL,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		recordDir: os.Getenv("APITEST_RECORD"),
+	}
+}
+
+// Response represents the response to a request.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Decode decodes the JSON body of the response into the value pointed to
+// by v, failing the test if it can't be decoded.
+func (r Response) Decode(t *testing.T, v any) {
+	t.Helper()
+
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		t.Fatalf("Should be able to decode the response %q: %s", r.Body, err)
+	}
+}
+
+// ErrorResponse represents the body encore writes for an error.
+type ErrorResponse struct {
+	Code    string          `json:"code"`
+	Message string          `json:"message"`
+	Details json.RawMessage `json:"details"`
+}
+
+// Token requests a token from the auth service for the user with the
+// specified credentials, failing the test if one isn't returned.
+func (h *HTTP) Token(email string, password string) string {
+	h.t.Helper()
+
+	req := h.newRequest(http.MethodGet, "/v1/token/"+activeKID, nil)
+	req.SetBasicAuth(email, password)
+
+	resp := h.send(req)
+	if resp.StatusCode != http.StatusOK {
+		h.t.Fatalf("Should get a token for %s, got status %d: %s", email, resp.StatusCode, resp.Body)
+	}
+
+	var tkn struct {
+		Token string `json:"token"`
+	}
+	resp.Decode(h.t, &tkn)
+
+	return tkn.Token
+}
+
+// Do sends a request with the body encoded as JSON, when there is one, and
+// the token as a bearer token, when there is one. The test fails if the
+// request can't be sent.
+func (h *HTTP) Do(method string, path string, token string, body any) Response {
+	h.t.Helper()
+
+	var data []byte
+	var r io.Reader
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		if err != nil {
+			h.t.Fatalf("Should be able to encode the request body: %s", err)
+		}
+		r = bytes.NewReader(data)
+	}
+
+	req := h.newRequest(method, path, r)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp := h.send(req)
+
+	if h.recordDir != "" {
+		h.record(Interaction{
+			Method:   method,
+			Path:     path,
+			Request:  data,
+			Status:   resp.StatusCode,
+			Response: resp.Body,
+		})
+	}
+
+	return resp
+}
+
+func (h *HTTP) newRequest(method string, path string, body io.Reader) *http.Request {
+	h.t.Helper()
+
+	req, err := http.NewRequest(method, h.baseURL+path, body)
+	if err != nil {
+		h.t.Fatalf("Should be able to create the request: %s", err)
+	}
+
+	return req
+}
+
+func (h *HTTP) send(req *http.Request) Response {
+	h.t.Helper()
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		h.t.Fatalf("Should be able to send the request %s %s: %s", req.Method, req.URL, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		h.t.Fatalf("Should be able to read the response: %s", err)
+	}
+
+	return Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}
+}
+
+// =============================================================================
+
+// Interaction represents a request sent to the app and the response to it.
+type Interaction struct {
+	Method   string          `json:"method"`
+	Path     string          `json:"path"`
+	Request  json.RawMessage `json:"request,omitempty"`
+	Status   int             `json:"status"`
+	Response json.RawMessage `json:"response,omitempty"`
+}
+
+// LoadInteractions reads the interactions written to the directory, failing
+// the test if they can't be read.
+func LoadInteractions(t *testing.T, dir string) []Interaction {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatalf("Should be able to list the interactions: %s", err)
+	}
+
+	if len(files) == 0 {
+		t.Fatalf("Should find interactions in %s", dir)
+	}
+
+	inters := make([]Interaction, len(files))
+	for i, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Should be able to read the interaction: %s", err)
+		}
+
+		if err := json.Unmarshal(data, &inters[i]); err != nil {
+			t.Fatalf("Should be able to decode the interaction %s: %s", file, err)
+		}
+	}
+
+	return inters
+}
+
+func (h *HTTP) record(inter Interaction) {
+	h.t.Helper()
+
+	h.recorded++
+
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(h.t.Name())
+	file := filepath.Join(h.recordDir, fmt.Sprintf("%s_%03d.json", name, h.recorded))
+
+	data, err := json.MarshalIndent(inter, "", "  ")
+	if err != nil {
+		h.t.Fatalf("Should be able to encode the interaction: %s", err)
+	}
+
+	if err := os.MkdirAll(h.recordDir, 0755); err != nil {
+		h.t.Fatalf("Should be able to create the record directory: %s", err)
+	}
+
+	if err := os.WriteFile(file, append(data, '\n'), 0644); err != nil {
+		h.t.Fatalf("Should be able to write the interaction: %s", err)
+	}
+}
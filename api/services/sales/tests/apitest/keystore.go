@@ -1,16 +1,19 @@
 package apitest
 
-// KeyStore represents a mock keystore with a hardcoded key.
-type KeyStore struct{}
+import (
+	"testing"
 
-// PrivateKey implements the auth interface.
-func (ks *KeyStore) PrivateKey(kid string) (string, error) {
-	return privateKeyPEM, nil
-}
+	"github.com/ardanlabs/encore/foundation/keystore"
+)
+
+// KeyStore constructs a keystore holding the hardcoded test key.
+func KeyStore(t *testing.T) *keystore.KeyStore {
+	ks := keystore.New()
+	if err := ks.LoadKey(kid, privateKeyPEM); err != nil {
+		t.Fatalf("Loading test key: %s", err)
+	}
 
-// PublicKey implements the auth interface.
-func (ks *KeyStore) PublicKey(kid string) (string, error) {
-	return publicKeyPEM, nil
+	return ks
 }
 
 const (
@@ -44,13 +47,4 @@ xumKGh//G0AYsjqP02ItzOm2mWnbI3FrNlKmGFvR6VxIZMOyXvpLofHucjJ5SWli
 eYjPklKcXaMftt1FVO4n+EKj1k1+Tv14nytq/J5WN+r4FBlNEYj/6vg=
 -----END PRIVATE KEY-----
 `
-	publicKeyPEM = `-----BEGIN PUBLIC KEY-----
-MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEAvMAHb0IoLvoYuW2kA+LT
-mnk+hfnBq1eYIh4CT/rMPCxgtzjqU0guQOMnLg69ydyA5uu37v6rbS1+stuBTEiM
-Ql/bxAhgLkGrUhgpZ10Bt6GzSEgwQNloZoGaxe4p20wMPpT4kcMKNHkQds3uONNc
-LxPUmfjbbH64g+seg28pbgQPwKFKtF7bIsOBgz0g5Ptn5mrkdzqMPUSy9k9VCu+R
-42LH9c75JsRzz4FeN+VzwMAL6yQnZvOi7/zOgNyxeVia8XVKykrnhgcpiOn5oaLR
-BzQGN00Z7TuBRIfDJWU21qQN4Cq7keZmMP4gqCVWjYneK4bzrG/+H2w9BJ2TsmMG
-vwIDAQAB
------END PUBLIC KEY-----`
 )
@@ -0,0 +1,132 @@
+package apitest
+
+import (
+	"context"
+
+	eerrs "encore.dev/beta/errs"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+)
+
+// The errors a request gets when its token can't be used.
+var (
+	ErrMalformedToken = errs.Newf(errs.Unauthenticated, "error parsing token: token contains an invalid number of segments")
+	ErrBadSignature   = errs.Newf(errs.Unauthenticated, "authentication failed : bindings results[[{[true] map[x:false]}]] ok[true]")
+)
+
+// ErrNotAuthorized constructs the error a request gets when the roles of its
+// token don't satisfy the rule of the endpoint, like USER and rule_admin_only.
+func ErrNotAuthorized(role string, rule string) *eerrs.Error {
+	return errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[%s]] rule[%s]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]", role, rule)
+}
+
+// =============================================================================
+
+type personaSet struct {
+	Anonymous    Persona
+	BadToken     Persona
+	BadSignature Persona
+	User         Persona
+	Owner        Persona
+	Admin        Persona
+}
+
+// Personas represents the set of personas a matrix makes a call as.
+var Personas = personaSet{
+	Anonymous:    Persona{"anonymous"},
+	BadToken:     Persona{"badtoken"},
+	BadSignature: Persona{"badsig"},
+	User:         Persona{"user"},
+	Owner:        Persona{"owner"},
+	Admin:        Persona{"admin"},
+}
+
+// Persona represents who a call is made as.
+type Persona struct {
+	name string
+}
+
+// String returns the name of the persona.
+func (p Persona) String() string {
+	return p.name
+}
+
+// Tokens represents the tokens of the personas that sign in. The user isn't
+// the owner of the data the call is made for. The admin isn't either unless
+// it's the same token as the owner.
+type Tokens struct {
+	User  string
+	Owner string
+	Admin string
+}
+
+// Matrix represents a call made once as each persona with the response that
+// is expected for the persona. A persona without an expected response isn't
+// run, so a call that changes data can leave out the personas it would
+// succeed for. The personas without a usable token expect the errors for
+// such a token unless the matrix sets them.
+type Matrix struct {
+	Tokens  Tokens
+	Exp     map[Persona]any
+	ExcFunc func(ctx context.Context) any
+	CmpFunc func(got any, exp any) string
+}
+
+// Tables returns the table for each persona in the order they're run. The
+// expected errors are compared with CmpAppErrors, along with the status they
+// are written with, and the other responses with CmpFunc, which can be left
+// out when no persona succeeds.
+func (m Matrix) Tables() []Table {
+	signed := m.Tokens.Admin
+	if signed == "" {
+		signed = m.Tokens.Owner
+	}
+	if signed == "" {
+		signed = m.Tokens.User
+	}
+
+	exp := map[Persona]any{
+		Personas.Anonymous:    ErrMalformedToken,
+		Personas.BadToken:     ErrMalformedToken,
+		Personas.BadSignature: ErrBadSignature,
+	}
+	for p, resp := range m.Exp {
+		exp[p] = resp
+	}
+
+	personas := []struct {
+		persona Persona
+		token   string
+	}{
+		{Personas.Anonymous, "&nbsp;"},
+		{Personas.BadToken, signed[:min(len(signed), 10)]},
+		{Personas.BadSignature, signed + "A"},
+		{Personas.User, m.Tokens.User},
+		{Personas.Owner, m.Tokens.Owner},
+		{Personas.Admin, m.Tokens.Admin},
+	}
+
+	var table []Table
+	for _, p := range personas {
+		resp, exists := exp[p.persona]
+		if !exists {
+			continue
+		}
+
+		tt := Table{
+			Name:    p.persona.String(),
+			Token:   p.token,
+			ExpResp: resp,
+			ExcFunc: m.ExcFunc,
+			CmpFunc: m.CmpFunc,
+		}
+
+		if err, ok := resp.(*eerrs.Error); ok {
+			tt.ExpStatus = err.Code.HTTPStatus()
+			tt.CmpFunc = CmpAppErrors
+		}
+
+		table = append(table, tt)
+	}
+
+	return table
+}
@@ -24,13 +24,20 @@ type SeedData struct {
 	Admins []User
 }
 
-// Table represent fields needed for running an app test.
+// Table represent fields needed for running an app test. The status and
+// headers are checked when they're set. The headers of a typed response are
+// read from its header fields and a raw endpoint's from the Response that
+// Raw returns. An expected header of Ignored only needs to be set and an
+// empty one must not be. CmpFunc can be left out when only the status and
+// headers matter.
 type Table struct {
-	Name    string
-	Token   string
-	ExpResp any
-	ExcFunc func(ctx context.Context) any
-	CmpFunc func(got any, exp any) string
+	Name       string
+	Token      string
+	ExpResp    any
+	ExpStatus  int
+	ExpHeaders map[string]string
+	ExcFunc    func(ctx context.Context) any
+	CmpFunc    func(got any, exp any) string
 }
 
 // AuthParams provides access to the authorization header.
@@ -0,0 +1,64 @@
+package apitest
+
+import (
+	"fmt"
+	"testing"
+
+	"encore.dev/et"
+	"encore.dev/pubsub"
+	"github.com/ardanlabs/encore/business/sdk/delegate"
+)
+
+// Messages returns all the messages published to the specified topic during
+// the current test. Encore scopes the captured messages to the running test
+// so parallel tests will not see each other's messages.
+func Messages[T any](topic *pubsub.Topic[T]) []T {
+	return et.Topic(topic).PublishedMessages()
+}
+
+// FindMessages returns the messages published to the specified topic during
+// the current test that match the specified function.
+func FindMessages[T any](topic *pubsub.Topic[T], match func(msg T) bool) []T {
+	var found []T
+	for _, msg := range Messages(topic) {
+		if match(msg) {
+			found = append(found, msg)
+		}
+	}
+
+	return found
+}
+
+// CmpMessages checks the number of messages published to the specified topic
+// that match the specified function. If they are not equal, the reason is
+// returned.
+func CmpMessages[T any](topic *pubsub.Topic[T], exp int, match func(msg T) bool) string {
+	got := FindMessages(topic, match)
+	if len(got) != exp {
+		return fmt.Sprintf("expected %d matching messages, got %d: %v", exp, len(got), Messages(topic))
+	}
+
+	return ""
+}
+
+// ExpectMessages fails the test if the number of messages published to the
+// specified topic that match the specified function is not what is expected.
+func ExpectMessages[T any](t *testing.T, topic *pubsub.Topic[T], exp int, match func(msg T) bool) []T {
+	t.Helper()
+
+	if diff := CmpMessages(topic, exp, match); diff != "" {
+		t.Fatalf("Should get the expected published messages: %s", diff)
+	}
+
+	return FindMessages(topic, match)
+}
+
+// =============================================================================
+
+// MatchDelegate returns a match function that identifies delegate messages
+// for the specified domain and action.
+func MatchDelegate(domain string, action string) func(delegate.Data) bool {
+	return func(data delegate.Data) bool {
+		return data.Domain == domain && data.Action == action
+	}
+}
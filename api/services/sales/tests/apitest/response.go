@@ -0,0 +1,105 @@
+package apitest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+)
+
+// Raw calls the handler of a raw endpoint with the request and returns the
+// response it wrote, so a table can check the status and headers of raw
+// endpoints like it does for the typed ones.
+func Raw(handler http.HandlerFunc, r *http.Request) Response {
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	return Response{
+		StatusCode: w.Code,
+		Header:     w.Header(),
+		Body:       w.Body.Bytes(),
+	}
+}
+
+// checkResponse fails the test when the status or headers of the response
+// don't match the ones expected by the table.
+func checkResponse(t *testing.T, tt Table, got any) {
+	t.Helper()
+
+	if tt.ExpStatus != 0 {
+		if status := statusOf(got); status != tt.ExpStatus {
+			t.Fatalf("Should get the %d status, got %d", tt.ExpStatus, status)
+		}
+	}
+
+	if len(tt.ExpHeaders) == 0 {
+		return
+	}
+
+	header := headerOf(got)
+
+	for name, exp := range tt.ExpHeaders {
+		value := header.Get(name)
+
+		switch {
+		case exp == Ignored && value == "":
+			t.Fatalf("Should set the %s header", name)
+
+		case exp == "" && value != "":
+			t.Fatalf("Should not set the %s header, got %q", name, value)
+
+		case exp != Ignored && value != exp:
+			t.Fatalf("Should get %q for the %s header, got %q", exp, name, value)
+		}
+	}
+}
+
+// statusOf returns the status a client gets for the response.
+func statusOf(got any) int {
+	switch v := got.(type) {
+	case Response:
+		return v.StatusCode
+
+	case error:
+		if err, ok := errs.As(v); ok {
+			return err.Code.HTTPStatus()
+		}
+		return http.StatusInternalServerError
+	}
+
+	return http.StatusOK
+}
+
+// headerOf returns the headers a client gets for the response. Encore writes
+// the fields of a typed response with a header tag as headers.
+func headerOf(got any) http.Header {
+	if resp, ok := got.(Response); ok {
+		return resp.Header
+	}
+
+	header := make(http.Header)
+
+	v := reflect.ValueOf(got)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return header
+	}
+
+	for i := range v.NumField() {
+		name := v.Type().Field(i).Tag.Get("header")
+		if name == "" {
+			continue
+		}
+
+		if fv := v.Field(i); fv.Kind() == reflect.String && fv.String() != "" {
+			header.Set(name, fv.String())
+		}
+	}
+
+	return header
+}
@@ -0,0 +1,57 @@
+package apitest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	eauth "encore.dev/beta/auth"
+	"github.com/ardanlabs/encore/api/services/sales/tests/apitest"
+	"github.com/ardanlabs/encore/app/sdk/auth"
+)
+
+type tagged struct {
+	Name string `json:"name"`
+	ETag string `json:"-" header:"ETag"`
+	Link string `json:"-" header:"Link"`
+}
+
+func Test_Headers(t *testing.T) {
+	handler := func(ctx context.Context, ap *apitest.AuthParams) (eauth.UID, *auth.Claims, error) {
+		return "user", &auth.Claims{}, nil
+	}
+
+	test := apitest.New(nil, nil, handler)
+
+	raw := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		w.WriteHeader(http.StatusAccepted)
+	}
+
+	table := []apitest.Table{
+		{
+			Name:      "typed",
+			ExpStatus: http.StatusOK,
+			ExpHeaders: map[string]string{
+				"ETag": apitest.Ignored,
+				"Link": "",
+			},
+			ExcFunc: func(ctx context.Context) any {
+				return tagged{Name: "Bill", ETag: `"123"`}
+			},
+		},
+		{
+			Name:      "raw",
+			ExpStatus: http.StatusAccepted,
+			ExpHeaders: map[string]string{
+				"Content-Type": "text/csv",
+			},
+			ExcFunc: func(ctx context.Context) any {
+				return apitest.Raw(raw, httptest.NewRequest(http.MethodGet, "/v1/export/products", nil))
+			},
+		},
+	}
+
+	test.Run(t, table, "headers")
+}
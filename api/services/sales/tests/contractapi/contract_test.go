@@ -0,0 +1,34 @@
+package contract_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/encore/api/services/sales"
+	"github.com/ardanlabs/encore/api/services/sales/tests/apitest"
+)
+
+// These tests validate the traffic recorded from the app against the OpenAPI
+// document, so a change to a model that isn't reflected in the document fails
+// here. The recordings in testdata are refreshed from the http tests, like:
+//
+//	$ APITEST_URL=http://localhost:4000 APITEST_RECORD=$PWD/api/services/sales/tests/contractapi/testdata encore test ./api/services/sales/tests/httpapi
+
+func Test_Contract(t *testing.T) {
+	t.Parallel()
+
+	doc := sales.OpenAPIDocument("test")
+
+	for _, inter := range apitest.LoadInteractions(t, "testdata") {
+		name := inter.Method + " " + inter.Path
+
+		t.Run(name, func(t *testing.T) {
+			if err := doc.ValidateRequest(inter.Method, inter.Path, inter.Request); err != nil {
+				t.Fatalf("Should match the document for the request: %s", err)
+			}
+
+			if err := doc.ValidateResponse(inter.Method, inter.Path, inter.Status, inter.Response); err != nil {
+				t.Fatalf("Should match the document for the %d response: %s", inter.Status, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package contract_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/encore/api/services/sales"
+)
+
+// endpoint represents an endpoint declared by an encore:api directive.
+type endpoint struct {
+	name   string
+	method string
+	path   string
+	auth   bool
+}
+
+// Test_OpenAPIRoutes validates the routes described in the OpenAPI document
+// against the encore:api directives in the service, so an endpoint that is
+// added, moved or removed without updating the document fails here.
+func Test_OpenAPIRoutes(t *testing.T) {
+	t.Parallel()
+
+	doc := sales.OpenAPIDocument("test")
+
+	endpoints := directives(t, filepath.Join("..", ".."))
+	if len(endpoints) == 0 {
+		t.Fatal("Should find the encore:api directives")
+	}
+
+	var described int
+	for _, item := range doc.Paths {
+		described += len(item)
+	}
+
+	if described != len(endpoints) {
+		t.Errorf("Should describe every public endpoint: got %d, exp %d", described, len(endpoints))
+	}
+
+	for _, ep := range endpoints {
+		item, exists := doc.Paths[openAPIPath(ep.path)]
+		if !exists {
+			t.Errorf("%s: Should find the path %s in the document", ep.name, ep.path)
+			continue
+		}
+
+		op, exists := item[strings.ToLower(ep.method)]
+		if !exists {
+			t.Errorf("%s: Should find the method %s for %s in the document", ep.name, ep.method, ep.path)
+			continue
+		}
+
+		if op.OperationID != ep.name {
+			t.Errorf("%s %s: Should get the endpoint name: got %s, exp %s", ep.method, ep.path, op.OperationID, ep.name)
+		}
+
+		if auth := len(op.Security) > 0; auth != ep.auth {
+			t.Errorf("%s: Should get the auth requirement: got %t, exp %t", ep.name, auth, ep.auth)
+		}
+	}
+}
+
+// directives returns the endpoints declared in the service source that are
+// part of the document. Raw and private endpoints aren't described.
+func directives(t *testing.T, dir string) []endpoint {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatalf("Should be able to list the service files: %s", err)
+	}
+
+	fset := token.NewFileSet()
+
+	var endpoints []endpoint
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") || strings.HasPrefix(filepath.Base(file), "zz_") {
+			continue
+		}
+
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Should be able to read %s: %s", file, err)
+		}
+
+		f, err := parser.ParseFile(fset, file, src, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("Should be able to parse %s: %s", file, err)
+		}
+
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Doc == nil {
+				continue
+			}
+
+			for _, c := range fn.Doc.List {
+				directive, found := strings.CutPrefix(c.Text, "//encore:api ")
+				if !found {
+					continue
+				}
+
+				ep, described := parseDirective(fn.Name.Name, directive)
+				if !described {
+					continue
+				}
+
+				if ep.method == "" || ep.path == "" {
+					t.Fatalf("%s: Should declare a method and path: %s", fn.Name.Name, c.Text)
+				}
+
+				endpoints = append(endpoints, ep)
+			}
+		}
+	}
+
+	return endpoints
+}
+
+func parseDirective(name string, directive string) (endpoint, bool) {
+	ep := endpoint{
+		name: name,
+	}
+
+	for _, field := range strings.Fields(directive) {
+		switch {
+		case field == "raw", field == "private":
+			return endpoint{}, false
+
+		case field == "auth":
+			ep.auth = true
+
+		case strings.HasPrefix(field, "method="):
+			ep.method = strings.TrimPrefix(field, "method=")
+
+		case strings.HasPrefix(field, "path="):
+			ep.path = strings.TrimPrefix(field, "path=")
+		}
+	}
+
+	return ep, true
+}
+
+// openAPIPath converts an Encore path like /v1/users/:userID into an
+// OpenAPI path like /v1/users/{userID}.
+func openAPIPath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			segments[i] = "{" + seg[1:] + "}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -56,6 +56,7 @@ func createOk(sd apitest.SeedData) []apitest.Table {
 				expResp.ID = gotResp.ID
 				expResp.DateCreated = gotResp.DateCreated
 				expResp.DateUpdated = gotResp.DateUpdated
+				expResp.Links = homeLinks(gotResp.ID, expResp.UserID)
 
 				return cmp.Diff(gotResp, expResp)
 			},
@@ -84,7 +85,7 @@ func createBad(sd apitest.SeedData) []apitest.Table {
 		{
 			Name:    "type",
 			Token:   sd.Users[0].Token,
-			ExpResp: errs.Newf(errs.InvalidArgument, "parse: invalid type \"BAD TYPE\""),
+			ExpResp: errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"type\",\"error\":\"type must be one of SINGLE FAMILY, CONDO\"}]"),
 			ExcFunc: func(ctx context.Context) any {
 				app := homeapp.NewHome{
 					Type: "BAD TYPE",
@@ -112,75 +113,34 @@ func createBad(sd apitest.SeedData) []apitest.Table {
 }
 
 func createAuth(sd apitest.SeedData) []apitest.Table {
-	table := []apitest.Table{
-		{
-			Name:    "emptytoken",
-			Token:   "&nbsp;",
-			ExpResp: errs.Newf(errs.Unauthenticated, "error parsing token: token contains an invalid number of segments"),
-			ExcFunc: func(ctx context.Context) any {
-				resp, err := sales.HomeCreate(ctx, homeapp.NewHome{})
-				if err != nil {
-					return err
-				}
-
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
+	m := apitest.Matrix{
+		Tokens: apitest.Tokens{
+			User:  sd.Users[0].Token,
+			Admin: sd.Admins[0].Token,
 		},
-		{
-			Name:    "token",
-			Token:   sd.Admins[0].Token[:10],
-			ExpResp: errs.Newf(errs.Unauthenticated, "error parsing token: token contains an invalid number of segments"),
-			ExcFunc: func(ctx context.Context) any {
-				resp, err := sales.HomeCreate(ctx, homeapp.NewHome{})
-				if err != nil {
-					return err
-				}
-
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
-		},
-		{
-			Name:    "sig",
-			Token:   sd.Admins[0].Token + "A",
-			ExpResp: errs.Newf(errs.Unauthenticated, "authentication failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
-			ExcFunc: func(ctx context.Context) any {
-				resp, err := sales.HomeCreate(ctx, homeapp.NewHome{})
-				if err != nil {
-					return err
-				}
-
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
+		Exp: map[apitest.Persona]any{
+			apitest.Personas.Admin: apitest.ErrNotAuthorized("ADMIN", "rule_user_only"),
 		},
-		{
-			Name:    "wronguser",
-			Token:   sd.Admins[0].Token,
-			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[ADMIN]] rule[rule_user_only]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
-			ExcFunc: func(ctx context.Context) any {
-				app := homeapp.NewHome{
-					Type: "SINGLE FAMILY",
-					Address: homeapp.NewAddress{
-						Address1: "123 Mocking Bird Lane",
-						ZipCode:  "35810",
-						City:     "Huntsville",
-						State:    "AL",
-						Country:  "US",
-					},
-				}
+		ExcFunc: func(ctx context.Context) any {
+			app := homeapp.NewHome{
+				Type: "SINGLE FAMILY",
+				Address: homeapp.NewAddress{
+					Address1: "123 Mocking Bird Lane",
+					ZipCode:  "35810",
+					City:     "Huntsville",
+					State:    "AL",
+					Country:  "US",
+				},
+			}
 
-				resp, err := sales.HomeCreate(ctx, app)
-				if err != nil {
-					return err
-				}
+			resp, err := sales.HomeCreate(ctx, app)
+			if err != nil {
+				return err
+			}
 
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
+			return resp
 		},
 	}
 
-	return table
+	return m.Tables()
 }
@@ -3,7 +3,10 @@ package home_test
 import (
 	"time"
 
+	"github.com/ardanlabs/encore/api/services/sales/tests/apitest"
 	"github.com/ardanlabs/encore/app/domain/homeapp"
+	"github.com/ardanlabs/encore/app/sdk/etag"
+	"github.com/ardanlabs/encore/app/sdk/links"
 	"github.com/ardanlabs/encore/business/domain/homebus"
 )
 
@@ -22,9 +25,24 @@ func toAppHome(hme homebus.Home) homeapp.Home {
 		},
 		DateCreated: hme.DateCreated.Format(time.RFC3339),
 		DateUpdated: hme.DateUpdated.Format(time.RFC3339),
+		Links:       homeLinks(hme.ID.String(), hme.UserID.String()),
 	}
 }
 
+func homeLinks(homeID string, userID string) links.Links {
+	return links.Links{
+		links.Self:  apitest.Links().Link("/v1/homes/"+homeID, nil),
+		links.Owner: apitest.Links().Link("/v1/users/"+userID, nil),
+	}
+}
+
+func toAppHomeWithETag(hme homebus.Home) homeapp.Home {
+	app := toAppHome(hme)
+	app.ETag = etag.New(hme.DateUpdated)
+
+	return app
+}
+
 func toAppHomes(homes []homebus.Home) []homeapp.Home {
 	items := make([]homeapp.Home, len(homes))
 	for i, hme := range homes {
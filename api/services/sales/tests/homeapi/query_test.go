@@ -21,6 +21,12 @@ func queryOk(sd apitest.SeedData) []apitest.Table {
 		return hmes[i].ID.String() <= hmes[j].ID.String()
 	})
 
+	qp := homeapp.QueryParams{
+		Page:    "1",
+		Rows:    "10",
+		OrderBy: "home_id,ASC",
+	}
+
 	table := []apitest.Table{
 		{
 			Name:  "all",
@@ -30,14 +36,8 @@ func queryOk(sd apitest.SeedData) []apitest.Table {
 				RowsPerPage: 10,
 				Total:       len(hmes),
 				Items:       toAppHomes(hmes),
-			},
+			}.WithLinks(apitest.Links(), "/v1/homes", qp),
 			ExcFunc: func(ctx context.Context) any {
-				qp := homeapp.QueryParams{
-					Page:    "1",
-					Rows:    "10",
-					OrderBy: "home_id,ASC",
-				}
-
 				resp, err := sales.HomeQuery(ctx, qp)
 				if err != nil {
 					return err
@@ -59,7 +59,7 @@ func queryByIDOk(sd apitest.SeedData) []apitest.Table {
 		{
 			Name:    "byid",
 			Token:   sd.Users[0].Token,
-			ExpResp: toAppHome(sd.Users[0].Homes[0]),
+			ExpResp: toAppHomeWithETag(sd.Users[0].Homes[0]),
 			ExcFunc: func(ctx context.Context) any {
 				resp, err := sales.HomeQueryByID(ctx, sd.Users[0].Homes[0].ID.String())
 				if err != nil {
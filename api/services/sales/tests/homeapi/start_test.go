@@ -12,6 +12,7 @@ import (
 	"github.com/ardanlabs/encore/app/sdk/auth"
 	"github.com/ardanlabs/encore/app/sdk/mid"
 	"github.com/ardanlabs/encore/business/sdk/dbtest"
+	"time"
 )
 
 func startTest(t *testing.T) *apitest.Test {
@@ -27,7 +28,8 @@ func startTest(t *testing.T) *apitest.Test {
 	ath, err := auth.New(auth.Config{
 		Log:       db.Log,
 		DB:        db.DB,
-		KeyLookup: &apitest.KeyStore{},
+		KeyLookup: apitest.KeyStore(t),
+		Clock:     db.Clock,
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -35,7 +37,7 @@ func startTest(t *testing.T) *apitest.Test {
 
 	// -------------------------------------------------------------------------
 
-	authService, err := authsrv.NewService(db.Log, db.DB, ath)
+	authService, err := authsrv.NewService(db.Log, db.DB, ath, authsrv.Config{RefreshTTL: time.Hour})
 	if err != nil {
 		t.Fatalf("Auth service init error: %s", err)
 	}
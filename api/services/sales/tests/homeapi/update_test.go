@@ -8,6 +8,7 @@ import (
 	"github.com/ardanlabs/encore/api/services/sales/tests/apitest"
 	"github.com/ardanlabs/encore/app/domain/homeapp"
 	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/etag"
 	"github.com/ardanlabs/encore/business/sdk/dbtest"
 	"github.com/google/go-cmp/cmp"
 )
@@ -31,6 +32,7 @@ func updateOk(sd apitest.SeedData) []apitest.Table {
 				},
 				DateCreated: sd.Users[0].Homes[0].DateCreated.Format(time.RFC3339),
 				DateUpdated: sd.Users[0].Homes[0].DateCreated.Format(time.RFC3339),
+				Links:       homeLinks(sd.Users[0].Homes[0].ID.String(), sd.Users[0].ID.String()),
 			},
 			ExcFunc: func(ctx context.Context) any {
 				app := homeapp.UpdateHome{
@@ -43,6 +45,7 @@ func updateOk(sd apitest.SeedData) []apitest.Table {
 						State:    dbtest.StringPointer("AL"),
 						Country:  dbtest.StringPointer("US"),
 					},
+					IfMatch: etag.New(sd.Users[0].Homes[0].DateUpdated),
 				}
 
 				resp, err := sales.HomeUpdate(ctx, sd.Users[0].Homes[0].ID.String(), app)
@@ -51,6 +54,7 @@ func updateOk(sd apitest.SeedData) []apitest.Table {
 				}
 
 				resp.DateUpdated = resp.DateCreated
+				resp.ETag = ""
 
 				return resp
 			},
@@ -68,7 +72,7 @@ func updateBad(sd apitest.SeedData) []apitest.Table {
 		{
 			Name:    "input",
 			Token:   sd.Users[0].Token,
-			ExpResp: errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"address1\",\"error\":\"address1 must be at least 1 character in length\"},{\"field\":\"zipCode\",\"error\":\"zipCode must be a valid numeric value\"},{\"field\":\"state\",\"error\":\"state must be at least 1 character in length\"},{\"field\":\"country\",\"error\":\"Key: 'UpdateHome.address.country' Error:Field validation for 'country' failed on the 'iso3166_1_alpha2' tag\"}]"),
+			ExpResp: errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"address1\",\"error\":\"address1 must be at least 1 character in length\"},{\"field\":\"zipCode\",\"error\":\"zipCode must be a valid numeric value\"},{\"field\":\"state\",\"error\":\"state must be at least 1 character in length\"},{\"field\":\"country\",\"error\":\"country must be a valid country code\"}]"),
 			ExcFunc: func(ctx context.Context) any {
 				app := homeapp.UpdateHome{
 					Address: &homeapp.UpdateAddress{
@@ -93,7 +97,7 @@ func updateBad(sd apitest.SeedData) []apitest.Table {
 		{
 			Name:    "type",
 			Token:   sd.Users[0].Token,
-			ExpResp: errs.Newf(errs.InvalidArgument, "parse: invalid type \"BAD TYPE\""),
+			ExpResp: errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"type\",\"error\":\"type must be one of SINGLE FAMILY, CONDO\"}]"),
 			ExcFunc: func(ctx context.Context) any {
 				app := homeapp.UpdateHome{
 					Type: dbtest.StringPointer("BAD TYPE"),
@@ -114,76 +118,36 @@ func updateBad(sd apitest.SeedData) []apitest.Table {
 }
 
 func updateAuth(sd apitest.SeedData) []apitest.Table {
-	table := []apitest.Table{
-		{
-			Name:    "emptytoken",
-			Token:   "&nbsp;",
-			ExpResp: errs.Newf(errs.Unauthenticated, "error parsing token: token contains an invalid number of segments"),
-			ExcFunc: func(ctx context.Context) any {
-				resp, err := sales.HomeUpdate(ctx, "", homeapp.UpdateHome{})
-				if err != nil {
-					return err
-				}
-
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
+	m := apitest.Matrix{
+		Tokens: apitest.Tokens{
+			User:  sd.Users[0].Token,
+			Owner: sd.Admins[0].Token,
+			Admin: sd.Admins[0].Token,
 		},
-		{
-			Name:    "token",
-			Token:   sd.Admins[0].Token[:10],
-			ExpResp: errs.Newf(errs.Unauthenticated, "error parsing token: token contains an invalid number of segments"),
-			ExcFunc: func(ctx context.Context) any {
-				resp, err := sales.HomeUpdate(ctx, "", homeapp.UpdateHome{})
-				if err != nil {
-					return err
-				}
-
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
+		Exp: map[apitest.Persona]any{
+			apitest.Personas.User: apitest.ErrNotAuthorized("USER", "rule_admin_or_subject"),
 		},
-		{
-			Name:    "sig",
-			Token:   sd.Admins[0].Token + "A",
-			ExpResp: errs.Newf(errs.Unauthenticated, "authentication failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
-			ExcFunc: func(ctx context.Context) any {
-				resp, err := sales.HomeUpdate(ctx, "", homeapp.UpdateHome{})
-				if err != nil {
-					return err
-				}
-
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
-		},
-		{
-			Name:    "wronguser",
-			Token:   sd.Users[0].Token,
-			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_admin_or_subject]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
-			ExcFunc: func(ctx context.Context) any {
-				app := homeapp.UpdateHome{
-					Type: dbtest.StringPointer("SINGLE FAMILY"),
-					Address: &homeapp.UpdateAddress{
-						Address1: dbtest.StringPointer("123 Mocking Bird Lane"),
-						Address2: dbtest.StringPointer("apt 105"),
-						ZipCode:  dbtest.StringPointer("35810"),
-						City:     dbtest.StringPointer("Huntsville"),
-						State:    dbtest.StringPointer("AL"),
-						Country:  dbtest.StringPointer("US"),
-					},
-				}
+		ExcFunc: func(ctx context.Context) any {
+			app := homeapp.UpdateHome{
+				Type: dbtest.StringPointer("SINGLE FAMILY"),
+				Address: &homeapp.UpdateAddress{
+					Address1: dbtest.StringPointer("123 Mocking Bird Lane"),
+					Address2: dbtest.StringPointer("apt 105"),
+					ZipCode:  dbtest.StringPointer("35810"),
+					City:     dbtest.StringPointer("Huntsville"),
+					State:    dbtest.StringPointer("AL"),
+					Country:  dbtest.StringPointer("US"),
+				},
+			}
 
-				resp, err := sales.HomeUpdate(ctx, sd.Admins[0].Homes[0].ID.String(), app)
-				if err != nil {
-					return err
-				}
+			resp, err := sales.HomeUpdate(ctx, sd.Admins[0].Homes[0].ID.String(), app)
+			if err != nil {
+				return err
+			}
 
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
+			return resp
 		},
 	}
 
-	return table
+	return m.Tables()
 }
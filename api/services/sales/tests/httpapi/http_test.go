@@ -0,0 +1,102 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/ardanlabs/encore/api/services/sales/tests/apitest"
+)
+
+// These tests run against the app started with encore run and the seed
+// data, like:
+//
+//	$ APITEST_URL=http://localhost:4000 encore test ./api/services/sales/tests/httpapi
+
+func Test_HTTP(t *testing.T) {
+	t.Parallel()
+
+	h := apitest.NewHTTP(t)
+
+	adminToken := h.Token("admin@example.com", "gophers")
+
+	// -------------------------------------------------------------------------
+
+	t.Run("about", func(t *testing.T) {
+		resp := h.Do(http.MethodGet, "/about", "", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Should get a %d from an admin network, got %d: %s", http.StatusOK, resp.StatusCode, resp.Body)
+		}
+	})
+
+	t.Run("no-token", func(t *testing.T) {
+		resp := h.Do(http.MethodGet, "/v1/products", "", nil)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("Should get a %d without a token, got %d: %s", http.StatusUnauthorized, resp.StatusCode, resp.Body)
+		}
+
+		var errResp apitest.ErrorResponse
+		resp.Decode(t, &errResp)
+
+		if errResp.Code != "unauthenticated" {
+			t.Fatalf("Should get the unauthenticated code, got %q", errResp.Code)
+		}
+	})
+
+	t.Run("bad-token", func(t *testing.T) {
+		resp := h.Do(http.MethodGet, "/v1/products", "not-a-token", nil)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("Should get a %d with a bad token, got %d: %s", http.StatusUnauthorized, resp.StatusCode, resp.Body)
+		}
+	})
+
+	t.Run("query", func(t *testing.T) {
+		resp := h.Do(http.MethodGet, "/v1/users?page=1&rows=2", adminToken, nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Should get a %d for the query, got %d: %s", http.StatusOK, resp.StatusCode, resp.Body)
+		}
+
+		var result struct {
+			Items       []json.RawMessage `json:"items"`
+			Page        int               `json:"page"`
+			RowsPerPage int               `json:"rowsPerPage"`
+		}
+		resp.Decode(t, &result)
+
+		if result.Page != 1 || result.RowsPerPage != 2 {
+			t.Fatalf("Should get the requested page, got page %d rows %d", result.Page, result.RowsPerPage)
+		}
+
+		if len(result.Items) == 0 || len(result.Items) > 2 {
+			t.Fatalf("Should get the seeded users on the page, got %d", len(result.Items))
+		}
+	})
+
+	t.Run("bad-filter", func(t *testing.T) {
+		resp := h.Do(http.MethodGet, "/v1/products?cost=abc", adminToken, nil)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("Should get a %d for a bad filter, got %d: %s", http.StatusBadRequest, resp.StatusCode, resp.Body)
+		}
+
+		var errResp apitest.ErrorResponse
+		resp.Decode(t, &errResp)
+
+		if errResp.Code != "failed_precondition" {
+			t.Fatalf("Should get the failed_precondition code, got %q", errResp.Code)
+		}
+	})
+
+	t.Run("bad-body", func(t *testing.T) {
+		resp := h.Do(http.MethodPost, "/v1/users", adminToken, map[string]any{})
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("Should get a %d for a bad body, got %d: %s", http.StatusBadRequest, resp.StatusCode, resp.Body)
+		}
+
+		var errResp apitest.ErrorResponse
+		resp.Decode(t, &errResp)
+
+		if errResp.Code != "invalid_argument" || len(errResp.Details) == 0 {
+			t.Fatalf("Should get the invalid_argument code with the fields, got %q %s", errResp.Code, errResp.Details)
+		}
+	})
+}
@@ -46,6 +46,7 @@ func createOk(sd apitest.SeedData) []apitest.Table {
 				expResp.ID = gotResp.ID
 				expResp.DateCreated = gotResp.DateCreated
 				expResp.DateUpdated = gotResp.DateUpdated
+				expResp.Links = productLinks(gotResp.ID, expResp.UserID)
 
 				return cmp.Diff(gotResp, expResp)
 			},
@@ -77,70 +78,29 @@ func createBad(sd apitest.SeedData) []apitest.Table {
 }
 
 func createAuth(sd apitest.SeedData) []apitest.Table {
-	table := []apitest.Table{
-		{
-			Name:    "emptytoken",
-			Token:   "&nbsp;",
-			ExpResp: errs.Newf(errs.Unauthenticated, "error parsing token: token contains an invalid number of segments"),
-			ExcFunc: func(ctx context.Context) any {
-				resp, err := sales.ProductCreate(ctx, productapp.NewProduct{})
-				if err != nil {
-					return err
-				}
-
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
+	m := apitest.Matrix{
+		Tokens: apitest.Tokens{
+			User:  sd.Users[0].Token,
+			Admin: sd.Admins[0].Token,
 		},
-		{
-			Name:    "token",
-			Token:   sd.Admins[0].Token[:10],
-			ExpResp: errs.Newf(errs.Unauthenticated, "error parsing token: token contains an invalid number of segments"),
-			ExcFunc: func(ctx context.Context) any {
-				resp, err := sales.ProductCreate(ctx, productapp.NewProduct{})
-				if err != nil {
-					return err
-				}
-
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
-		},
-		{
-			Name:    "sig",
-			Token:   sd.Admins[0].Token + "A",
-			ExpResp: errs.Newf(errs.Unauthenticated, "authentication failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
-			ExcFunc: func(ctx context.Context) any {
-				resp, err := sales.ProductCreate(ctx, productapp.NewProduct{})
-				if err != nil {
-					return err
-				}
-
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
+		Exp: map[apitest.Persona]any{
+			apitest.Personas.Admin: apitest.ErrNotAuthorized("ADMIN", "rule_user_only"),
 		},
-		{
-			Name:    "wronguser",
-			Token:   sd.Admins[0].Token,
-			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[ADMIN]] rule[rule_user_only]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
-			ExcFunc: func(ctx context.Context) any {
-				app := productapp.NewProduct{
-					Name:     "Guitar",
-					Cost:     10.34,
-					Quantity: 10,
-				}
+		ExcFunc: func(ctx context.Context) any {
+			app := productapp.NewProduct{
+				Name:     "Guitar",
+				Cost:     10.34,
+				Quantity: 10,
+			}
 
-				resp, err := sales.ProductCreate(ctx, app)
-				if err != nil {
-					return err
-				}
+			resp, err := sales.ProductCreate(ctx, app)
+			if err != nil {
+				return err
+			}
 
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
+			return resp
 		},
 	}
 
-	return table
+	return m.Tables()
 }
@@ -3,7 +3,10 @@ package product_test
 import (
 	"time"
 
+	"github.com/ardanlabs/encore/api/services/sales/tests/apitest"
 	"github.com/ardanlabs/encore/app/domain/productapp"
+	"github.com/ardanlabs/encore/app/sdk/etag"
+	"github.com/ardanlabs/encore/app/sdk/links"
 	"github.com/ardanlabs/encore/business/domain/productbus"
 )
 
@@ -16,9 +19,24 @@ func toAppProduct(prd productbus.Product) productapp.Product {
 		Quantity:    prd.Quantity,
 		DateCreated: prd.DateCreated.Format(time.RFC3339),
 		DateUpdated: prd.DateUpdated.Format(time.RFC3339),
+		Links:       productLinks(prd.ID.String(), prd.UserID.String()),
 	}
 }
 
+func productLinks(productID string, userID string) links.Links {
+	return links.Links{
+		links.Self:  apitest.Links().Link("/v1/products/"+productID, nil),
+		links.Owner: apitest.Links().Link("/v1/users/"+userID, nil),
+	}
+}
+
+func toAppProductWithETag(prd productbus.Product) productapp.Product {
+	app := toAppProduct(prd)
+	app.ETag = etag.New(prd.DateUpdated)
+
+	return app
+}
+
 func toAppProducts(prds []productbus.Product) []productapp.Product {
 	items := make([]productapp.Product, len(prds))
 	for i, prd := range prds {
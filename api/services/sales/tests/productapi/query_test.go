@@ -2,6 +2,7 @@ package product_test
 
 import (
 	"context"
+	"net/http"
 	"sort"
 
 	"github.com/ardanlabs/encore/api/services/sales"
@@ -21,6 +22,13 @@ func queryOk(sd apitest.SeedData) []apitest.Table {
 		return prds[i].ID.String() <= prds[j].ID.String()
 	})
 
+	qp := productapp.QueryParams{
+		Page:    "1",
+		Rows:    "10",
+		OrderBy: "product_id,ASC",
+		Name:    "Name",
+	}
+
 	table := []apitest.Table{
 		{
 			Name:  "all",
@@ -30,15 +38,8 @@ func queryOk(sd apitest.SeedData) []apitest.Table {
 				RowsPerPage: 10,
 				Total:       len(prds),
 				Items:       toAppProducts(prds),
-			},
+			}.WithLinks(apitest.Links(), "/v1/products", qp),
 			ExcFunc: func(ctx context.Context) any {
-				qp := productapp.QueryParams{
-					Page:    "1",
-					Rows:    "10",
-					OrderBy: "product_id,ASC",
-					Name:    "Name",
-				}
-
 				resp, err := sales.ProductQuery(ctx, qp)
 				if err != nil {
 					return err
@@ -60,7 +61,7 @@ func queryByIDOk(sd apitest.SeedData) []apitest.Table {
 		{
 			Name:    "byid",
 			Token:   sd.Users[0].Token,
-			ExpResp: toAppProduct(sd.Users[0].Products[0]),
+			ExpResp: toAppProductWithETag(sd.Users[0].Products[0]),
 			ExcFunc: func(ctx context.Context) any {
 				resp, err := sales.ProductQueryByID(ctx, sd.Users[0].Products[0].ID.String())
 				if err != nil {
@@ -73,6 +74,22 @@ func queryByIDOk(sd apitest.SeedData) []apitest.Table {
 				return cmp.Diff(got, exp)
 			},
 		},
+		{
+			Name:      "byid-headers",
+			Token:     sd.Users[0].Token,
+			ExpStatus: http.StatusOK,
+			ExpHeaders: map[string]string{
+				"ETag": apitest.Ignored,
+			},
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := sales.ProductQueryByID(ctx, sd.Users[0].Products[0].ID.String())
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+		},
 	}
 
 	return table
@@ -1,60 +0,0 @@
-package product_test
-
-import (
-	"context"
-	"fmt"
-
-	"github.com/ardanlabs/encore/api/services/sales/tests/apitest"
-	"github.com/ardanlabs/encore/app/sdk/auth"
-	"github.com/ardanlabs/encore/business/domain/productbus"
-	"github.com/ardanlabs/encore/business/domain/userbus"
-	"github.com/ardanlabs/encore/business/sdk/dbtest"
-)
-
-func insertSeedData(db *dbtest.Database, ath *auth.Auth) (apitest.SeedData, error) {
-	ctx := context.Background()
-	busDomain := db.BusDomain
-
-	usrs, err := userbus.TestSeedUsers(ctx, 1, userbus.Roles.User, busDomain.User)
-	if err != nil {
-		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
-	}
-
-	prds, err := productbus.TestGenerateSeedProducts(ctx, 2, busDomain.Product, usrs[0].ID)
-	if err != nil {
-		return apitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
-	}
-
-	tu1 := apitest.User{
-		User:     usrs[0],
-		Products: prds,
-		Token:    apitest.Token(db, ath, usrs[0].Email.Address),
-	}
-
-	// -------------------------------------------------------------------------
-
-	usrs, err = userbus.TestSeedUsers(ctx, 1, userbus.Roles.Admin, busDomain.User)
-	if err != nil {
-		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
-	}
-
-	prds, err = productbus.TestGenerateSeedProducts(ctx, 2, busDomain.Product, usrs[0].ID)
-	if err != nil {
-		return apitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
-	}
-
-	tu2 := apitest.User{
-		User:     usrs[0],
-		Products: prds,
-		Token:    apitest.Token(db, ath, usrs[0].Email.Address),
-	}
-
-	// -------------------------------------------------------------------------
-
-	sd := apitest.SeedData{
-		Admins: []apitest.User{tu2},
-		Users:  []apitest.User{tu1},
-	}
-
-	return sd, nil
-}
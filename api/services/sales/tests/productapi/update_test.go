@@ -8,6 +8,7 @@ import (
 	"github.com/ardanlabs/encore/api/services/sales/tests/apitest"
 	"github.com/ardanlabs/encore/app/domain/productapp"
 	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/etag"
 	"github.com/ardanlabs/encore/business/sdk/dbtest"
 	"github.com/google/go-cmp/cmp"
 )
@@ -25,12 +26,14 @@ func updateOk(sd apitest.SeedData) []apitest.Table {
 				Quantity:    10,
 				DateCreated: sd.Users[0].Products[0].DateCreated.Format(time.RFC3339),
 				DateUpdated: sd.Users[0].Products[0].DateCreated.Format(time.RFC3339),
+				Links:       productLinks(sd.Users[0].Products[0].ID.String(), sd.Users[0].ID.String()),
 			},
 			ExcFunc: func(ctx context.Context) any {
 				app := productapp.UpdateProduct{
 					Name:     dbtest.StringPointer("Guitar"),
 					Cost:     dbtest.FloatPointer(10.34),
 					Quantity: dbtest.IntPointer(10),
+					IfMatch:  etag.New(sd.Users[0].Products[0].DateUpdated),
 				}
 
 				resp, err := sales.ProductUpdate(ctx, sd.Users[0].Products[0].ID.String(), app)
@@ -39,6 +42,7 @@ func updateOk(sd apitest.SeedData) []apitest.Table {
 				}
 
 				resp.DateUpdated = resp.DateCreated
+				resp.ETag = ""
 
 				return resp
 			},
@@ -82,70 +86,30 @@ func updateBad(sd apitest.SeedData) []apitest.Table {
 }
 
 func updateAuth(sd apitest.SeedData) []apitest.Table {
-	table := []apitest.Table{
-		{
-			Name:    "emptytoken",
-			Token:   "&nbsp;",
-			ExpResp: errs.Newf(errs.Unauthenticated, "error parsing token: token contains an invalid number of segments"),
-			ExcFunc: func(ctx context.Context) any {
-				resp, err := sales.ProductUpdate(ctx, "", productapp.UpdateProduct{})
-				if err != nil {
-					return err
-				}
-
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
+	m := apitest.Matrix{
+		Tokens: apitest.Tokens{
+			User:  sd.Users[0].Token,
+			Owner: sd.Admins[0].Token,
+			Admin: sd.Admins[0].Token,
 		},
-		{
-			Name:    "token",
-			Token:   sd.Admins[0].Token[:10],
-			ExpResp: errs.Newf(errs.Unauthenticated, "error parsing token: token contains an invalid number of segments"),
-			ExcFunc: func(ctx context.Context) any {
-				resp, err := sales.ProductUpdate(ctx, "", productapp.UpdateProduct{})
-				if err != nil {
-					return err
-				}
-
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
-		},
-		{
-			Name:    "sig",
-			Token:   sd.Admins[0].Token + "A",
-			ExpResp: errs.Newf(errs.Unauthenticated, "authentication failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
-			ExcFunc: func(ctx context.Context) any {
-				resp, err := sales.ProductUpdate(ctx, "", productapp.UpdateProduct{})
-				if err != nil {
-					return err
-				}
-
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
+		Exp: map[apitest.Persona]any{
+			apitest.Personas.User: apitest.ErrNotAuthorized("USER", "rule_admin_or_subject"),
 		},
-		{
-			Name:    "wronguser",
-			Token:   sd.Users[0].Token,
-			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_admin_or_subject]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
-			ExcFunc: func(ctx context.Context) any {
-				app := productapp.UpdateProduct{
-					Name:     dbtest.StringPointer("Guitar"),
-					Cost:     dbtest.FloatPointer(10.34),
-					Quantity: dbtest.IntPointer(10),
-				}
-
-				resp, err := sales.ProductUpdate(ctx, sd.Admins[0].Products[0].ID.String(), app)
-				if err != nil {
-					return err
-				}
-
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
+		ExcFunc: func(ctx context.Context) any {
+			app := productapp.UpdateProduct{
+				Name:     dbtest.StringPointer("Guitar"),
+				Cost:     dbtest.FloatPointer(10.34),
+				Quantity: dbtest.IntPointer(10),
+			}
+
+			resp, err := sales.ProductUpdate(ctx, sd.Admins[0].Products[0].ID.String(), app)
+			if err != nil {
+				return err
+			}
+
+			return resp
 		},
 	}
 
-	return table
+	return m.Tables()
 }
@@ -11,10 +11,7 @@ func Test_Tran(t *testing.T) {
 
 	// -------------------------------------------------------------------------
 
-	sd, err := insertSeedData(test.DB, test.Auth)
-	if err != nil {
-		t.Fatalf("Seeding error: %s", err)
-	}
+	sd := test.LoadFixtures(t, "fixtures/seed.yaml")
 
 	// -------------------------------------------------------------------------
 
@@ -50,6 +50,7 @@ func createOk(sd apitest.SeedData) []apitest.Table {
 				expResp.ID = gotResp.ID
 				expResp.DateCreated = gotResp.DateCreated
 				expResp.DateUpdated = gotResp.DateUpdated
+				expResp.Links = userLinks(gotResp.ID)
 
 				return cmp.Diff(gotResp, expResp)
 			},
@@ -78,7 +79,7 @@ func createBad(sd apitest.SeedData) []apitest.Table {
 		{
 			Name:    "role",
 			Token:   sd.Admins[0].Token,
-			ExpResp: errs.Newf(errs.InvalidArgument, "parse: invalid role \"BAD ROLE\""),
+			ExpResp: errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"roles[0]\",\"error\":\"roles[0] must be one of ADMIN, USER\"}]"),
 			ExcFunc: func(ctx context.Context) any {
 				app := userapp.NewUser{
 					Name:            "Bill Kennedy",
@@ -104,73 +105,32 @@ func createBad(sd apitest.SeedData) []apitest.Table {
 }
 
 func createAuth(sd apitest.SeedData) []apitest.Table {
-	table := []apitest.Table{
-		{
-			Name:    "emptytoken",
-			Token:   "&nbsp;",
-			ExpResp: errs.Newf(errs.Unauthenticated, "error parsing token: token contains an invalid number of segments"),
-			ExcFunc: func(ctx context.Context) any {
-				resp, err := sales.UserCreate(ctx, userapp.NewUser{})
-				if err != nil {
-					return err
-				}
-
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
+	m := apitest.Matrix{
+		Tokens: apitest.Tokens{
+			User:  sd.Users[0].Token,
+			Admin: sd.Admins[0].Token,
 		},
-		{
-			Name:    "token",
-			Token:   sd.Admins[0].Token[:10],
-			ExpResp: errs.Newf(errs.Unauthenticated, "error parsing token: token contains an invalid number of segments"),
-			ExcFunc: func(ctx context.Context) any {
-				resp, err := sales.UserCreate(ctx, userapp.NewUser{})
-				if err != nil {
-					return err
-				}
-
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
+		Exp: map[apitest.Persona]any{
+			apitest.Personas.User: apitest.ErrNotAuthorized("USER", "rule_admin_only"),
 		},
-		{
-			Name:    "sig",
-			Token:   sd.Admins[0].Token + "A",
-			ExpResp: errs.Newf(errs.Unauthenticated, "authentication failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
-			ExcFunc: func(ctx context.Context) any {
-				resp, err := sales.UserCreate(ctx, userapp.NewUser{})
-				if err != nil {
-					return err
-				}
-
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
-		},
-		{
-			Name:    "wronguser",
-			Token:   sd.Users[0].Token,
-			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_admin_only]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
-			ExcFunc: func(ctx context.Context) any {
-				app := userapp.NewUser{
-					Name:            "Bill Kennedy",
-					Email:           "bill2@ardanlabs.com",
-					Roles:           []string{"USER"},
-					Department:      "IT",
-					Password:        "123",
-					PasswordConfirm: "123",
-				}
-
-				resp, err := sales.UserCreate(ctx, app)
-				if err != nil {
-					return err
-				}
-
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
+		ExcFunc: func(ctx context.Context) any {
+			app := userapp.NewUser{
+				Name:            "Bill Kennedy",
+				Email:           "bill2@ardanlabs.com",
+				Roles:           []string{"USER"},
+				Department:      "IT",
+				Password:        "123",
+				PasswordConfirm: "123",
+			}
+
+			resp, err := sales.UserCreate(ctx, app)
+			if err != nil {
+				return err
+			}
+
+			return resp
 		},
 	}
 
-	return table
+	return m.Tables()
 }
@@ -5,7 +5,7 @@ import (
 
 	"github.com/ardanlabs/encore/api/services/sales"
 	"github.com/ardanlabs/encore/api/services/sales/tests/apitest"
-	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/etag"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -16,7 +16,7 @@ func deleteOk(sd apitest.SeedData) []apitest.Table {
 			Token:   sd.Users[1].Token,
 			ExpResp: nil,
 			ExcFunc: func(ctx context.Context) any {
-				if err := sales.UserDelete(ctx, sd.Users[1].ID.String()); err != nil {
+				if err := sales.UserDelete(ctx, sd.Users[1].ID.String(), etag.Precondition{IfMatch: etag.New(sd.Users[1].DateUpdated)}); err != nil {
 					return err
 				}
 
@@ -31,7 +31,7 @@ func deleteOk(sd apitest.SeedData) []apitest.Table {
 			Token:   sd.Admins[1].Token,
 			ExpResp: nil,
 			ExcFunc: func(ctx context.Context) any {
-				if err := sales.UserDelete(ctx, sd.Admins[1].ID.String()); err != nil {
+				if err := sales.UserDelete(ctx, sd.Admins[1].ID.String(), etag.Precondition{IfMatch: etag.New(sd.Admins[1].DateUpdated)}); err != nil {
 					return err
 				}
 
@@ -47,50 +47,24 @@ func deleteOk(sd apitest.SeedData) []apitest.Table {
 }
 
 func deleteAuth(sd apitest.SeedData) []apitest.Table {
-	table := []apitest.Table{
-		{
-			Name:    "emptytoken",
-			Token:   "&nbsp;",
-			ExpResp: errs.Newf(errs.Unauthenticated, "error parsing token: token contains an invalid number of segments"),
-			ExcFunc: func(ctx context.Context) any {
-				err := sales.UserDelete(ctx, "")
-				if err != nil {
-					return err
-				}
-
-				return nil
-			},
-			CmpFunc: apitest.CmpAppErrors,
+	m := apitest.Matrix{
+		Tokens: apitest.Tokens{
+			User:  sd.Users[2].Token,
+			Owner: sd.Users[0].Token,
+			Admin: sd.Admins[0].Token,
 		},
-		{
-			Name:    "sig",
-			Token:   sd.Users[0].Token + "A",
-			ExpResp: errs.Newf(errs.Unauthenticated, "authentication failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
-			ExcFunc: func(ctx context.Context) any {
-				err := sales.UserDelete(ctx, "")
-				if err != nil {
-					return err
-				}
-
-				return nil
-			},
-			CmpFunc: apitest.CmpAppErrors,
+		Exp: map[apitest.Persona]any{
+			apitest.Personas.User: apitest.ErrNotAuthorized("USER", "rule_admin_or_subject"),
 		},
-		{
-			Name:    "wronguser",
-			Token:   sd.Users[2].Token,
-			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_admin_or_subject]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
-			ExcFunc: func(ctx context.Context) any {
-				err := sales.UserDelete(ctx, sd.Users[0].ID.String())
-				if err != nil {
-					return err
-				}
+		ExcFunc: func(ctx context.Context) any {
+			err := sales.UserDelete(ctx, sd.Users[0].ID.String(), etag.Precondition{IfMatch: etag.New(sd.Users[0].DateUpdated)})
+			if err != nil {
+				return err
+			}
 
-				return nil
-			},
-			CmpFunc: apitest.CmpAppErrors,
+			return nil
 		},
 	}
 
-	return table
+	return m.Tables()
 }
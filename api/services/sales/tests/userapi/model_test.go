@@ -1,9 +1,13 @@
 package user_test
 
 import (
+	"net/url"
 	"time"
 
+	"github.com/ardanlabs/encore/api/services/sales/tests/apitest"
 	"github.com/ardanlabs/encore/app/domain/userapp"
+	"github.com/ardanlabs/encore/app/sdk/etag"
+	"github.com/ardanlabs/encore/app/sdk/links"
 	"github.com/ardanlabs/encore/business/domain/userbus"
 )
 
@@ -23,9 +27,24 @@ func toAppUser(usr userbus.User) userapp.User {
 		Enabled:      usr.Enabled,
 		DateCreated:  usr.DateCreated.Format(time.RFC3339),
 		DateUpdated:  usr.DateUpdated.Format(time.RFC3339),
+		Links:        userLinks(usr.ID.String()),
 	}
 }
 
+func userLinks(userID string) links.Links {
+	return links.Links{
+		links.Self:    apitest.Links().Link("/v1/users/"+userID, nil),
+		links.Related: apitest.Links().Link("/v1/homes", url.Values{"user_id": {userID}}),
+	}
+}
+
+func toAppUserWithETag(usr userbus.User) userapp.User {
+	app := toAppUser(usr)
+	app.ETag = etag.New(usr.DateUpdated)
+
+	return app
+}
+
 func toAppUsers(users []userbus.User) []userapp.User {
 	items := make([]userapp.User, len(users))
 	for i, usr := range users {
@@ -27,6 +27,13 @@ func queryOk(sd apitest.SeedData) []apitest.Table {
 		return usrs[i].ID.String() <= usrs[j].ID.String()
 	})
 
+	qp := userapp.QueryParams{
+		Page:    "1",
+		Rows:    "10",
+		OrderBy: "user_id,ASC",
+		Name:    "Name",
+	}
+
 	table := []apitest.Table{
 		{
 			Name:  "all",
@@ -36,15 +43,8 @@ func queryOk(sd apitest.SeedData) []apitest.Table {
 				RowsPerPage: 10,
 				Total:       len(usrs),
 				Items:       toAppUsers(usrs),
-			},
+			}.WithLinks(apitest.Links(), "/v1/users", qp),
 			ExcFunc: func(ctx context.Context) any {
-				qp := userapp.QueryParams{
-					Page:    "1",
-					Rows:    "10",
-					OrderBy: "user_id,ASC",
-					Name:    "Name",
-				}
-
 				resp, err := sales.UserQuery(ctx, qp)
 				if err != nil {
 					return err
@@ -66,7 +66,7 @@ func queryByIDOk(sd apitest.SeedData) []apitest.Table {
 		{
 			Name:    "byid",
 			Token:   sd.Users[0].Token,
-			ExpResp: toAppUser(sd.Users[0].User),
+			ExpResp: toAppUserWithETag(sd.Users[0].User),
 			ExcFunc: func(ctx context.Context) any {
 				resp, err := sales.UserQueryByID(ctx, sd.Users[0].ID.String())
 				if err != nil {
@@ -8,6 +8,7 @@ import (
 	"github.com/ardanlabs/encore/api/services/sales/tests/apitest"
 	"github.com/ardanlabs/encore/app/domain/userapp"
 	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/etag"
 	"github.com/ardanlabs/encore/business/sdk/dbtest"
 	"github.com/google/go-cmp/cmp"
 )
@@ -26,6 +27,7 @@ func updateOk(sd apitest.SeedData) []apitest.Table {
 				Enabled:     true,
 				DateCreated: sd.Users[0].DateCreated.Format(time.RFC3339),
 				DateUpdated: sd.Users[0].DateCreated.Format(time.RFC3339),
+				Links:       userLinks(sd.Users[0].ID.String()),
 			},
 			ExcFunc: func(ctx context.Context) any {
 				app := userapp.UpdateUser{
@@ -34,6 +36,7 @@ func updateOk(sd apitest.SeedData) []apitest.Table {
 					Department:      dbtest.StringPointer("IT"),
 					Password:        dbtest.StringPointer("123"),
 					PasswordConfirm: dbtest.StringPointer("123"),
+					IfMatch:         etag.New(sd.Users[0].DateUpdated),
 				}
 
 				resp, err := sales.UserUpdate(ctx, sd.Users[0].ID.String(), app)
@@ -42,6 +45,7 @@ func updateOk(sd apitest.SeedData) []apitest.Table {
 				}
 
 				resp.DateUpdated = resp.DateCreated
+				resp.ETag = ""
 
 				return resp
 			},
@@ -78,7 +82,7 @@ func updateBad(sd apitest.SeedData) []apitest.Table {
 		{
 			Name:    "role",
 			Token:   sd.Admins[0].Token,
-			ExpResp: errs.Newf(errs.InvalidArgument, "parse: invalid role \"BAD ROLE\""),
+			ExpResp: errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"roles[0]\",\"error\":\"roles[0] must be one of ADMIN, USER\"}]"),
 			ExcFunc: func(ctx context.Context) any {
 				app := userapp.UpdateUserRole{
 					Roles: []string{"BAD ROLE"},
@@ -99,90 +103,63 @@ func updateBad(sd apitest.SeedData) []apitest.Table {
 }
 
 func updateAuth(sd apitest.SeedData) []apitest.Table {
-	table := []apitest.Table{
-		{
-			Name:    "emptytoken",
-			Token:   "&nbsp;",
-			ExpResp: errs.Newf(errs.Unauthenticated, "error parsing token: token contains an invalid number of segments"),
-			ExcFunc: func(ctx context.Context) any {
-				resp, err := sales.UserUpdate(ctx, "", userapp.UpdateUser{})
-				if err != nil {
-					return err
-				}
-
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
+	update := apitest.Matrix{
+		Tokens: apitest.Tokens{
+			User:  sd.Users[0].Token,
+			Owner: sd.Users[1].Token,
+			Admin: sd.Admins[0].Token,
 		},
-		{
-			Name:    "token",
-			Token:   sd.Admins[0].Token[:10],
-			ExpResp: errs.Newf(errs.Unauthenticated, "error parsing token: token contains an invalid number of segments"),
-			ExcFunc: func(ctx context.Context) any {
-				resp, err := sales.UserUpdate(ctx, sd.Admins[0].ID.String(), userapp.UpdateUser{})
-				if err != nil {
-					return err
-				}
-
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
+		Exp: map[apitest.Persona]any{
+			apitest.Personas.User: apitest.ErrNotAuthorized("USER", "rule_admin_or_subject"),
 		},
-		{
-			Name:    "sig",
-			Token:   sd.Admins[0].Token + "A",
-			ExpResp: errs.Newf(errs.Unauthenticated, "authentication failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
-			ExcFunc: func(ctx context.Context) any {
-				resp, err := sales.UserUpdate(ctx, sd.Admins[0].ID.String(), userapp.UpdateUser{})
-				if err != nil {
-					return err
-				}
-
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
+		ExcFunc: func(ctx context.Context) any {
+			app := userapp.UpdateUser{
+				Name:            dbtest.StringPointer("Jack Kennedy"),
+				Email:           dbtest.StringPointer("jack2@ardanlabs.com"),
+				Department:      dbtest.StringPointer("IT"),
+				Password:        dbtest.StringPointer("123"),
+				PasswordConfirm: dbtest.StringPointer("123"),
+			}
+
+			resp, err := sales.UserUpdate(ctx, sd.Users[1].ID.String(), app)
+			if err != nil {
+				return err
+			}
+
+			return resp
 		},
-		{
-			Name:    "wronguser",
-			Token:   sd.Users[0].Token,
-			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_admin_or_subject]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
-			ExcFunc: func(ctx context.Context) any {
-				app := userapp.UpdateUser{
-					Name:            dbtest.StringPointer("Jack Kennedy"),
-					Email:           dbtest.StringPointer("jack2@ardanlabs.com"),
-					Department:      dbtest.StringPointer("IT"),
-					Password:        dbtest.StringPointer("123"),
-					PasswordConfirm: dbtest.StringPointer("123"),
-				}
-
-				resp, err := sales.UserUpdate(ctx, sd.Users[1].ID.String(), app)
-				if err != nil {
-					return err
-				}
+	}
 
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
+	role := apitest.Matrix{
+		Tokens: apitest.Tokens{
+			User:  sd.Users[0].Token,
+			Owner: sd.Users[1].Token,
+			Admin: sd.Admins[0].Token,
 		},
-		{
-			Name:    "roleadminonly",
-			Token:   sd.Users[0].Token,
-			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_admin_only]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
-			ExcFunc: func(ctx context.Context) any {
-				app := userapp.UpdateUserRole{
-					Roles: []string{"ADMIN"},
-				}
+		Exp: map[apitest.Persona]any{
+			apitest.Personas.User:  apitest.ErrNotAuthorized("USER", "rule_admin_only"),
+			apitest.Personas.Owner: apitest.ErrNotAuthorized("USER", "rule_admin_only"),
+		},
+		ExcFunc: func(ctx context.Context) any {
+			app := userapp.UpdateUserRole{
+				Roles: []string{"ADMIN"},
+			}
 
-				resp, err := sales.UserUpdateRole(ctx, sd.Users[1].ID.String(), app)
-				if err != nil {
-					return err
-				}
+			resp, err := sales.UserUpdateRole(ctx, sd.Users[1].ID.String(), app)
+			if err != nil {
+				return err
+			}
 
-				return resp
-			},
-			CmpFunc: apitest.CmpAppErrors,
+			return resp
 		},
 	}
 
+	var table []apitest.Table
+	table = append(table, update.Tables()...)
+	for _, tt := range role.Tables() {
+		tt.Name = "role-" + tt.Name
+		table = append(table, tt)
+	}
+
 	return table
 }
@@ -1,6 +1,7 @@
 package vproduct_test
 
 import (
+	"math"
 	"time"
 
 	"github.com/ardanlabs/encore/app/domain/vproductapp"
@@ -8,23 +9,26 @@ import (
 	"github.com/ardanlabs/encore/business/domain/userbus"
 )
 
-func toAppVProduct(usr userbus.User, prd productbus.Product) vproductapp.Product {
+func toAppVProduct(usr userbus.User, prd productbus.Product, userProducts int) vproductapp.Product {
 	return vproductapp.Product{
-		ID:          prd.ID.String(),
-		UserID:      prd.UserID.String(),
-		Name:        prd.Name.String(),
-		Cost:        prd.Cost,
-		Quantity:    prd.Quantity,
-		DateCreated: prd.DateCreated.Format(time.RFC3339),
-		DateUpdated: prd.DateUpdated.Format(time.RFC3339),
-		UserName:    usr.Name.String(),
+		ID:           prd.ID.String(),
+		UserID:       prd.UserID.String(),
+		Name:         prd.Name.String(),
+		Cost:         prd.Cost,
+		Quantity:     prd.Quantity,
+		DateCreated:  prd.DateCreated.Format(time.RFC3339),
+		DateUpdated:  prd.DateUpdated.Format(time.RFC3339),
+		UserName:     usr.Name.String(),
+		UserEmail:    usr.Email.Address,
+		Value:        math.Round(prd.Cost*float64(prd.Quantity)*100) / 100,
+		UserProducts: userProducts,
 	}
 }
 
 func toAppVProducts(usr userbus.User, prds []productbus.Product) []vproductapp.Product {
 	items := make([]vproductapp.Product, len(prds))
 	for i, prd := range prds {
-		items[i] = toAppVProduct(usr, prd)
+		items[i] = toAppVProduct(usr, prd, len(prds))
 	}
 
 	return items
@@ -19,6 +19,12 @@ func queryOk(sd apitest.SeedData) []apitest.Table {
 		return prds[i].ID <= prds[j].ID
 	})
 
+	byValue := toAppVProducts(sd.Users[0].User, sd.Users[0].Products)
+
+	sort.Slice(byValue, func(i, j int) bool {
+		return byValue[i].Value > byValue[j].Value
+	})
+
 	table := []apitest.Table{
 		{
 			Name:  "all",
@@ -48,6 +54,34 @@ func queryOk(sd apitest.SeedData) []apitest.Table {
 				return cmp.Diff(got, exp)
 			},
 		},
+		{
+			Name:  "user-email-by-value",
+			Token: sd.Admins[0].Token,
+			ExpResp: query.Result[vproductapp.Product]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       len(byValue),
+				Items:       byValue,
+			},
+			ExcFunc: func(ctx context.Context) any {
+				qp := vproductapp.QueryParams{
+					Page:      "1",
+					Rows:      "10",
+					OrderBy:   "value,DESC",
+					UserEmail: sd.Users[0].User.Email.Address,
+				}
+
+				resp, err := sales.VProductQuery(ctx, qp)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
 	}
 
 	return table
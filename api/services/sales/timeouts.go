@@ -0,0 +1,27 @@
+package sales
+
+import (
+	"time"
+
+	"github.com/ardanlabs/encore/app/sdk/mid"
+)
+
+// timeouts is the deadline each endpoint runs under, applied by the timeout
+// middleware. An endpoint that isn't listed gets the default, which is
+// enough for any single CRUD call. Endpoints that work on many items or
+// write a whole result set get longer, and a stream stays open for as long
+// as the client reads it.
+var timeouts = mid.Timeouts{
+	Default: 10 * time.Second,
+	Endpoints: map[string]time.Duration{
+		"BatchExecute":      time.Minute,
+		"HomeDeleteMany":    30 * time.Second,
+		"ProductDeleteMany": 30 * time.Second,
+
+		"Export": 5 * time.Minute,
+		"Stream": 0,
+
+		"IdempotencyDeleteExpired": 5 * time.Minute,
+		"ReportBuildDaily":         5 * time.Minute,
+	},
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// The app packages can't be loaded by a program that isn't run by encore,
+// since they declare the pubsub topics, so the models the scenarios send and
+// receive are declared here with the fields of the productapp models. The
+// tests check the fields still match.
+
+type newProduct struct {
+	Name     string  `json:"name"`
+	Cost     float64 `json:"cost"`
+	Quantity int     `json:"quantity"`
+}
+
+type product struct {
+	ID          string  `json:"id"`
+	UserID      string  `json:"userID"`
+	Name        string  `json:"name"`
+	Cost        float64 `json:"cost"`
+	Quantity    int     `json:"quantity"`
+	DateCreated string  `json:"dateCreated"`
+	DateUpdated string  `json:"dateUpdated"`
+}
+
+type queryResult[T any] struct {
+	Items       []T `json:"items"`
+	Total       int `json:"total"`
+	Page        int `json:"page"`
+	RowsPerPage int `json:"rowsPerPage"`
+}
+
+// =============================================================================
+
+// client makes the calls of the scenarios to the environment.
+type client struct {
+	cfg  Config
+	http *http.Client
+
+	mu  sync.RWMutex
+	tkn string
+}
+
+func newClient(cfg Config) *client {
+	return &client{
+		cfg: cfg,
+		http: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				MaxIdleConnsPerHost: cfg.Concurrency,
+			},
+		},
+	}
+}
+
+// login gets the token the other scenarios make their calls with.
+func (c *client) login(ctx context.Context) error {
+	tkn, err := c.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.tkn = tkn
+	c.mu.Unlock()
+
+	return nil
+}
+
+// token requests a token for the configured user.
+func (c *client) token(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.BaseURL+"/v1/token/"+c.cfg.KID, nil)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(c.cfg.Email, c.cfg.Password)
+
+	var tkn struct {
+		Token string `json:"token"`
+	}
+
+	if err := c.send(req, &tkn); err != nil {
+		return "", err
+	}
+
+	return tkn.Token, nil
+}
+
+// do makes a call with the token, encoding the body as JSON when there is
+// one and decoding the response into v.
+func (c *client) do(ctx context.Context, method string, path string, body any, v any) error {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encode: %w", err)
+		}
+		r = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.cfg.BaseURL+path, r)
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	c.mu.RLock()
+	req.Header.Set("Authorization", "Bearer "+c.tkn)
+	c.mu.RUnlock()
+
+	return c.send(req, v)
+}
+
+func (c *client) send(req *http.Request, v any) error {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: status %d: %s", req.Method, req.URL.Path, resp.StatusCode, data)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+
+	return nil
+}
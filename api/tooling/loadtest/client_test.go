@@ -0,0 +1,57 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/encore/app/domain/productapp"
+	"github.com/ardanlabs/encore/app/sdk/query"
+)
+
+// Test_Models checks the models of the scenarios still have the fields of
+// the app models. It loads the app packages, so it runs with encore test.
+func Test_Models(t *testing.T) {
+	table := []struct {
+		name string
+		got  any
+		exp  any
+	}{
+		{name: "newProduct", got: newProduct{}, exp: productapp.NewProduct{}},
+		{name: "product", got: product{}, exp: productapp.Product{}},
+		{name: "queryResult", got: queryResult[product]{}, exp: query.Result[productapp.Product]{}},
+	}
+
+	for _, tt := range table {
+		exp := jsonFields(reflect.TypeOf(tt.exp))
+
+		for name, typ := range jsonFields(reflect.TypeOf(tt.got)) {
+			expTyp, exists := exp[name]
+			if !exists {
+				t.Errorf("%s: Should have the %s field in the app model", tt.name, name)
+				continue
+			}
+
+			if typ.Kind() != expTyp.Kind() {
+				t.Errorf("%s: Should have the %s field as a %s, got %s", tt.name, name, expTyp.Kind(), typ.Kind())
+			}
+		}
+	}
+}
+
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		fields[name] = f.Type
+	}
+
+	return fields
+}
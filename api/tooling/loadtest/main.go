@@ -0,0 +1,180 @@
+// This program drives load against a running environment of the sales app
+// with scenarios that make the calls of a client, and reports the latency
+// percentiles of each scenario.
+//
+//	$ go run ./api/tooling/loadtest -url http://localhost:4000 -c 20 -d 30s
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Println("ERROR:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var cfg Config
+	var scenarios string
+
+	flag.StringVar(&cfg.BaseURL, "url", "http://localhost:4000", "base url of the environment")
+	flag.StringVar(&cfg.Email, "email", "admin@example.com", "email of the user to log in as")
+	flag.StringVar(&cfg.Password, "password", "gophers", "password of the user to log in as")
+	flag.StringVar(&cfg.KID, "kid", "54bb2165-71e1-41a6-af3e-7da4a0e1e2c1", "kid of the key to sign tokens with")
+	flag.IntVar(&cfg.Concurrency, "c", 10, "number of concurrent clients")
+	flag.DurationVar(&cfg.Duration, "d", 30*time.Second, "how long to drive load")
+	flag.StringVar(&scenarios, "scenarios", "login,products,create", "comma separated scenarios to run")
+	flag.Parse()
+
+	for _, name := range strings.Split(scenarios, ",") {
+		sc, exists := allScenarios[strings.TrimSpace(name)]
+		if !exists {
+			return fmt.Errorf("unknown scenario %q", name)
+		}
+		cfg.Scenarios = append(cfg.Scenarios, sc)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client := newClient(cfg)
+
+	// The scenarios that aren't logging in share a token, like the clients
+	// of the app that log in once.
+	if err := client.login(ctx); err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+
+	fmt.Printf("driving %d clients against %s for %s\n\n", cfg.Concurrency, cfg.BaseURL, cfg.Duration)
+
+	rpt := drive(ctx, cfg, client)
+	rpt.print(os.Stdout)
+
+	return nil
+}
+
+// =============================================================================
+
+// Config represents the settings for a run.
+type Config struct {
+	BaseURL     string
+	Email       string
+	Password    string
+	KID         string
+	Concurrency int
+	Duration    time.Duration
+	Scenarios   []Scenario
+}
+
+// Scenario represents a set of calls a client makes. The weight sets how
+// often the scenario is chosen compared to the others.
+type Scenario struct {
+	Name   string
+	Weight int
+	Run    func(c *client, ctx context.Context, rnd *rand.Rand) error
+}
+
+var allScenarios = map[string]Scenario{
+	"login":    {Name: "login", Weight: 1, Run: (*client).loginScenario},
+	"products": {Name: "products", Weight: 6, Run: (*client).productsScenario},
+	"create":   {Name: "create", Weight: 3, Run: (*client).createScenario},
+}
+
+// drive runs the scenarios from the clients until the duration passes or
+// the context is cancelled.
+func drive(ctx context.Context, cfg Config, c *client) *report {
+	ctx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	var total int
+	for _, sc := range cfg.Scenarios {
+		total += sc.Weight
+	}
+
+	rpt := newReport()
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	wg.Add(cfg.Concurrency)
+
+	for i := range cfg.Concurrency {
+		go func() {
+			defer wg.Done()
+
+			rnd := rand.New(rand.NewSource(time.Now().UnixNano() + int64(i)))
+
+			for ctx.Err() == nil {
+				sc := choose(cfg.Scenarios, rnd.Intn(total))
+
+				began := time.Now()
+				err := sc.Run(c, ctx, rnd)
+
+				// A call cut short by the end of the run isn't a failure.
+				if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					return
+				}
+
+				rpt.add(sc.Name, time.Since(began), err)
+			}
+		}()
+	}
+
+	wg.Wait()
+	rpt.elapsed = time.Since(start)
+
+	return rpt
+}
+
+func choose(scenarios []Scenario, roll int) Scenario {
+	for _, sc := range scenarios {
+		roll -= sc.Weight
+		if roll < 0 {
+			return sc
+		}
+	}
+
+	return scenarios[len(scenarios)-1]
+}
+
+// =============================================================================
+
+func (c *client) loginScenario(ctx context.Context, rnd *rand.Rand) error {
+	_, err := c.token(ctx)
+	return err
+}
+
+func (c *client) productsScenario(ctx context.Context, rnd *rand.Rand) error {
+	path := fmt.Sprintf("/v1/products?page=%d&rows=10&order_by=name", rnd.Intn(5)+1)
+
+	var result queryResult[product]
+	return c.do(ctx, http.MethodGet, path, nil, &result)
+}
+
+func (c *client) createScenario(ctx context.Context, rnd *rand.Rand) error {
+	np := newProduct{
+		Name:     fmt.Sprintf("Load Test %d", rnd.Int63()),
+		Cost:     float64(rnd.Intn(10000)+1) / 100,
+		Quantity: rnd.Intn(100) + 1,
+	}
+
+	var prd product
+	if err := c.do(ctx, http.MethodPost, "/v1/products", np, &prd); err != nil {
+		return err
+	}
+
+	return c.do(ctx, http.MethodGet, "/v1/products/"+prd.ID, nil, &prd)
+}
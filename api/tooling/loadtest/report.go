@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// report collects the latencies and failures of the scenarios.
+type report struct {
+	mu        sync.Mutex
+	latencies map[string][]time.Duration
+	failures  map[string]int
+	lastErr   map[string]error
+	elapsed   time.Duration
+}
+
+func newReport() *report {
+	return &report{
+		latencies: make(map[string][]time.Duration),
+		failures:  make(map[string]int),
+		lastErr:   make(map[string]error),
+	}
+}
+
+func (r *report) add(scenario string, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err != nil {
+		r.failures[scenario]++
+		r.lastErr[scenario] = err
+		return
+	}
+
+	r.latencies[scenario] = append(r.latencies[scenario], latency)
+}
+
+func (r *report) print(w io.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.latencies)+len(r.failures))
+	for name := range r.latencies {
+		names = append(names, name)
+	}
+	for name := range r.failures {
+		if _, exists := r.latencies[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "scenario\tok\tfailed\trate/s\tp50\tp90\tp99\tmax\t")
+
+	for _, name := range names {
+		lats := r.latencies[name]
+		slices.Sort(lats)
+
+		rate := float64(len(lats)) / r.elapsed.Seconds()
+
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%s\t%s\t%s\t%s\t\n",
+			name, len(lats), r.failures[name], rate,
+			percentile(lats, 50), percentile(lats, 90), percentile(lats, 99), percentile(lats, 100))
+	}
+
+	tw.Flush()
+
+	for _, name := range names {
+		if err := r.lastErr[name]; err != nil {
+			fmt.Fprintf(w, "\n%s last failure: %s", name, err)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// percentile returns the latency at the percentile p of the sorted
+// latencies using the nearest rank.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1].Round(time.Microsecond)
+}
@@ -0,0 +1,118 @@
+// Package adminapp maintains the app layer api for changing how the service
+// runs without a deploy.
+package adminapp
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"encore.dev"
+	"github.com/ardanlabs/encore/app/sdk/cache"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/maintenance"
+	"github.com/ardanlabs/encore/app/sdk/mid"
+	"github.com/ardanlabs/encore/business/domain/auditbus"
+	"github.com/ardanlabs/encore/foundation/logger"
+)
+
+// Set of actions recorded in the audit log.
+const (
+	ActionLogLevel    = "admin.loglevel"
+	ActionCacheFlush  = "admin.cacheflush"
+	ActionMaintenance = "admin.maintenance"
+)
+
+// App manages the set of app layer api functions for the admin controls.
+type App struct {
+	log      *logger.Logger
+	cache    *cache.Cache
+	mode     *maintenance.Mode
+	auditBus *auditbus.Business
+}
+
+// NewApp constructs an admin API for use.
+func NewApp(log *logger.Logger, cache *cache.Cache, mode *maintenance.Mode, auditBus *auditbus.Business) *App {
+	return &App{
+		log:      log,
+		cache:    cache,
+		mode:     mode,
+		auditBus: auditBus,
+	}
+}
+
+// Status returns the current runtime settings.
+func (a *App) Status(ctx context.Context) Status {
+	return Status{
+		LogLevel:    a.log.Level().String(),
+		Maintenance: a.mode.Enabled(),
+	}
+}
+
+// SetLogLevel changes the minimum level that is logged.
+func (a *App) SetLogLevel(ctx context.Context, app LogLevel) (Status, error) {
+	level, err := logger.ParseLevel(app.Level)
+	if err != nil {
+		return Status{}, errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := a.audit(ctx, ActionLogLevel, app); err != nil {
+		return Status{}, err
+	}
+
+	a.log.SetLevel(level)
+
+	return a.Status(ctx), nil
+}
+
+// FlushCache removes all the cached responses.
+func (a *App) FlushCache(ctx context.Context) (Status, error) {
+	if err := a.audit(ctx, ActionCacheFlush, nil); err != nil {
+		return Status{}, err
+	}
+
+	a.cache.Flush()
+
+	return a.Status(ctx), nil
+}
+
+// SetMaintenance turns maintenance mode on or off.
+func (a *App) SetMaintenance(ctx context.Context, app Maintenance) (Status, error) {
+	if err := a.audit(ctx, ActionMaintenance, app); err != nil {
+		return Status{}, err
+	}
+
+	a.mode.SetRetryAfter(time.Duration(app.RetryAfterSeconds) * time.Second)
+	a.mode.Set(app.Enabled)
+
+	return a.Status(ctx), nil
+}
+
+// audit records the action before it's applied, so a change is never made
+// without a record of who made it.
+func (a *App) audit(ctx context.Context, action string, payload any) error {
+	actorID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.Newf(errs.Unauthenticated, "audit: %s", err)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return errs.Newf(errs.Internal, "audit: marshal: %s", err)
+	}
+
+	na := auditbus.NewAudit{
+		ActorID:  actorID,
+		Action:   action,
+		Endpoint: encore.CurrentRequest().Endpoint,
+		Payload:  data,
+	}
+
+	if _, err := a.auditBus.Create(ctx, na); err != nil {
+		return errs.Newf(errs.Internal, "audit: %s", err)
+	}
+
+	a.log.Info(ctx, "admin", "action", action, "actorID", actorID, "payload", string(data))
+
+	return nil
+}
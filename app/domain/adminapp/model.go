@@ -0,0 +1,55 @@
+package adminapp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+)
+
+// Status represents the runtime settings of the service.
+type Status struct {
+	LogLevel    string `json:"logLevel"`
+	Maintenance bool   `json:"maintenance"`
+}
+
+// Encode implments the encoder interface.
+func (app Status) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// =============================================================================
+
+// LogLevel defines the data needed to change the log verbosity.
+type LogLevel struct {
+	Level string `json:"level" validate:"required,oneof=debug info warn error"`
+}
+
+// Decode implments the decoder interface.
+func (app *LogLevel) Decode(data []byte) error {
+	return json.Unmarshal(data, &app)
+}
+
+// Validate checks if the data in the model is considered clean.
+func (app LogLevel) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// Maintenance defines the data needed to turn maintenance mode on or off.
+// The retry is how long clients are told to wait while it's on.
+type Maintenance struct {
+	Enabled           bool `json:"enabled"`
+	RetryAfterSeconds int  `json:"retryAfterSeconds"`
+}
+
+// Decode implments the decoder interface.
+func (app *Maintenance) Decode(data []byte) error {
+	return json.Unmarshal(data, &app)
+}
@@ -0,0 +1,102 @@
+// Package apikeyapp maintains the app layer api for the API keys users
+// create for callers that authenticate without a token.
+package apikeyapp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/mid"
+	"github.com/ardanlabs/encore/business/domain/apikeybus"
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/google/uuid"
+)
+
+// App manages the set of app layer api functions for the API key domain.
+type App struct {
+	apiKeyBus *apikeybus.Business
+}
+
+// NewApp constructs an API key app API for use.
+func NewApp(apiKeyBus *apikeybus.Business) *App {
+	return &App{
+		apiKeyBus: apiKeyBus,
+	}
+}
+
+// Create adds a new API key for the user. The key can't be scoped to more
+// roles than the caller has. The key is only returned this once.
+func (a *App) Create(ctx context.Context, app NewAPIKey) (APIKey, error) {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return APIKey{}, errs.New(errs.Unauthenticated, err)
+	}
+
+	claims, err := mid.GetClaims(ctx)
+	if err != nil {
+		return APIKey{}, errs.New(errs.Unauthenticated, err)
+	}
+
+	scope, err := userbus.ParseRoles(claims.Roles)
+	if err != nil {
+		return APIKey{}, errs.Newf(errs.Unauthenticated, "parsing roles: %s", err)
+	}
+
+	nk, err := toBusNewAPIKey(userID, scope, app)
+	if err != nil {
+		return APIKey{}, errs.New(errs.InvalidArgument, err)
+	}
+
+	key, err := a.apiKeyBus.Create(ctx, nk)
+	if err != nil {
+		return APIKey{}, fmt.Errorf("create: %w", err)
+	}
+
+	return toAppAPIKey(key), nil
+}
+
+// Query returns the API keys of the user.
+func (a *App) Query(ctx context.Context) (APIKeys, error) {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return APIKeys{}, errs.New(errs.Unauthenticated, err)
+	}
+
+	keys, err := a.apiKeyBus.QueryByUserID(ctx, userID)
+	if err != nil {
+		return APIKeys{}, errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	return toAppAPIKeys(keys), nil
+}
+
+// Delete removes the user's API key, so it can no longer be used.
+func (a *App) Delete(ctx context.Context, keyID string) error {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	id, err := uuid.Parse(keyID)
+	if err != nil {
+		return fmt.Errorf("parse keyID[%s]: %w", keyID, mid.ErrInvalidID)
+	}
+
+	key, err := a.apiKeyBus.QueryByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("querybyid: %w", err)
+	}
+
+	// The keys of other users are reported as not found, so a caller can't
+	// learn which key ids exist.
+	if key.UserID != userID {
+		return fmt.Errorf("querybyid: keyID[%s]: %w", id, apikeybus.ErrNotFound)
+	}
+
+	if err := a.apiKeyBus.Delete(ctx, key); err != nil {
+		return errs.Newf(errs.Internal, "delete: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,15 @@
+package apikeyapp
+
+import (
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/apikeybus"
+)
+
+// init registers the API key sentinel errors so they reach clients with the
+// right code.
+func init() {
+	errs.Register(apikeybus.ErrNotFound, errs.Class{Code: errs.NotFound, AppCode: errs.AppAPIKeyNotFound})
+	errs.Register(apikeybus.ErrExpired, errs.Class{Code: errs.Unauthenticated, AppCode: errs.AppAPIKeyExpired})
+	errs.Register(apikeybus.ErrUserDisabled, errs.Class{Code: errs.FailedPrecondition, AppCode: errs.AppAPIKeyUserDisabled})
+	errs.Register(apikeybus.ErrRoles, errs.Class{Code: errs.PermissionDenied, AppCode: errs.AppAPIKeyRoles})
+}
@@ -0,0 +1,98 @@
+package apikeyapp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/apikeybus"
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/google/uuid"
+)
+
+// APIKey represents an API key. The key itself is only set when the key is
+// created.
+type APIKey struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Key         string   `json:"key,omitempty"`
+	Roles       []string `json:"roles"`
+	DateExpires string   `json:"dateExpires,omitempty"`
+	DateCreated string   `json:"dateCreated"`
+}
+
+func toAppAPIKey(bus apikeybus.APIKey) APIKey {
+	var expires string
+	if !bus.DateExpires.IsZero() {
+		expires = bus.DateExpires.Format(time.RFC3339)
+	}
+
+	return APIKey{
+		ID:          bus.ID.String(),
+		Name:        bus.Name,
+		Key:         bus.Key,
+		Roles:       userbus.ParseRolesToString(bus.Roles),
+		DateExpires: expires,
+		DateCreated: bus.DateCreated.Format(time.RFC3339),
+	}
+}
+
+// APIKeys represents the API keys of a user.
+type APIKeys struct {
+	Items []APIKey `json:"items"`
+}
+
+func toAppAPIKeys(keys []apikeybus.APIKey) APIKeys {
+	items := make([]APIKey, len(keys))
+	for i, key := range keys {
+		items[i] = toAppAPIKey(key)
+	}
+
+	return APIKeys{
+		Items: items,
+	}
+}
+
+// =============================================================================
+
+// NewAPIKey defines the data needed to add a new API key. A key without an
+// expiry never expires.
+type NewAPIKey struct {
+	Name        string   `json:"name" validate:"required"`
+	Roles       []string `json:"roles" validate:"required,dive,enum=role"`
+	DateExpires string   `json:"dateExpires"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewAPIKey) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
+	}
+
+	return nil
+}
+
+func toBusNewAPIKey(userID uuid.UUID, scope []userbus.Role, app NewAPIKey) (apikeybus.NewAPIKey, error) {
+	roles, err := userbus.ParseRoles(app.Roles)
+	if err != nil {
+		return apikeybus.NewAPIKey{}, fmt.Errorf("parse: %w", err)
+	}
+
+	var expires time.Time
+	if app.DateExpires != "" {
+		expires, err = time.Parse(time.RFC3339, app.DateExpires)
+		if err != nil {
+			return apikeybus.NewAPIKey{}, fmt.Errorf("parse dateExpires: %w", err)
+		}
+	}
+
+	nk := apikeybus.NewAPIKey{
+		UserID:      userID,
+		Name:        app.Name,
+		Roles:       roles,
+		Scope:       scope,
+		DateExpires: expires,
+	}
+
+	return nk, nil
+}
@@ -0,0 +1,84 @@
+// Package deadletterapp maintains the app layer api for the dead letter domain.
+package deadletterapp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/query"
+	"github.com/ardanlabs/encore/business/domain/deadletterbus"
+	"github.com/ardanlabs/encore/business/sdk/page"
+	"github.com/google/uuid"
+)
+
+// App manages the set of app layer api functions for the dead letter domain.
+type App struct {
+	deadLetterBus *deadletterbus.Business
+}
+
+// NewApp constructs a dead letter domain API for use.
+func NewApp(deadLetterBus *deadletterbus.Business) *App {
+	return &App{
+		deadLetterBus: deadLetterBus,
+	}
+}
+
+// Query returns a list of dead letters with paging.
+func (a *App) Query(ctx context.Context, qp QueryParams) (query.Result[DeadLetter], error) {
+	page, err := page.Parse(qp.Page, qp.Rows)
+	if err != nil {
+		return query.Result[DeadLetter]{}, errs.New(errs.InvalidArgument, err)
+	}
+
+	dls, err := a.deadLetterBus.Query(ctx, page)
+	if err != nil {
+		return query.Result[DeadLetter]{}, errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	total, err := a.deadLetterBus.Count(ctx)
+	if err != nil {
+		return query.Result[DeadLetter]{}, errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	return query.NewResult(toAppDeadLetters(dls), total, page), nil
+}
+
+// QueryByID returns a dead letter by its ID.
+func (a *App) QueryByID(ctx context.Context, deadLetterID string) (DeadLetter, error) {
+	dl, err := a.queryByID(ctx, deadLetterID)
+	if err != nil {
+		return DeadLetter{}, err
+	}
+
+	return toAppDeadLetter(dl), nil
+}
+
+// Replay publishes the dead letter back to its topic.
+func (a *App) Replay(ctx context.Context, deadLetterID string) (DeadLetter, error) {
+	dl, err := a.queryByID(ctx, deadLetterID)
+	if err != nil {
+		return DeadLetter{}, err
+	}
+
+	dl, err = a.deadLetterBus.Replay(ctx, dl)
+	if err != nil {
+		return DeadLetter{}, fmt.Errorf("replay: deadLetterID[%s]: %w", deadLetterID, err)
+	}
+
+	return toAppDeadLetter(dl), nil
+}
+
+func (a *App) queryByID(ctx context.Context, deadLetterID string) (deadletterbus.DeadLetter, error) {
+	id, err := uuid.Parse(deadLetterID)
+	if err != nil {
+		return deadletterbus.DeadLetter{}, errs.New(errs.InvalidArgument, err)
+	}
+
+	dl, err := a.deadLetterBus.QueryByID(ctx, id)
+	if err != nil {
+		return deadletterbus.DeadLetter{}, fmt.Errorf("querybyid: deadLetterID[%s]: %w", deadLetterID, err)
+	}
+
+	return dl, nil
+}
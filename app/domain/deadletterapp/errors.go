@@ -0,0 +1,14 @@
+package deadletterapp
+
+import (
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/deadletterbus"
+)
+
+// init registers the dead letter sentinel errors so they reach clients with the
+// right code.
+func init() {
+	errs.Register(deadletterbus.ErrNotFound, errs.Class{Code: errs.NotFound, AppCode: errs.AppDeadLetterNotFound})
+	errs.Register(deadletterbus.ErrAlreadyReplayed, errs.Class{Code: errs.FailedPrecondition, AppCode: errs.AppDeadLetterAlreadyReplayed})
+	errs.Register(deadletterbus.ErrNoReplay, errs.Class{Code: errs.FailedPrecondition, AppCode: errs.AppDeadLetterNoReplay})
+}
@@ -0,0 +1,64 @@
+package deadletterapp
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/deadletterbus"
+)
+
+// QueryParams represents the set of possible query strings.
+type QueryParams struct {
+	Page string
+	Rows string
+}
+
+// =============================================================================
+
+// DeadLetter represents information about a message a subscriber could not
+// process.
+type DeadLetter struct {
+	ID           string          `json:"id"`
+	Topic        string          `json:"topic"`
+	Subscription string          `json:"subscription"`
+	MessageID    string          `json:"messageID"`
+	Payload      json.RawMessage `json:"payload"`
+	Error        string          `json:"error"`
+	Attempts     int             `json:"attempts"`
+	DateCreated  string          `json:"dateCreated"`
+	DateReplayed string          `json:"dateReplayed,omitempty"`
+}
+
+// Encode implments the encoder interface.
+func (app DeadLetter) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppDeadLetter(dl deadletterbus.DeadLetter) DeadLetter {
+	var replayed string
+	if !dl.DateReplayed.IsZero() {
+		replayed = dl.DateReplayed.Format(time.RFC3339)
+	}
+
+	return DeadLetter{
+		ID:           dl.ID.String(),
+		Topic:        dl.Topic,
+		Subscription: dl.Subscription,
+		MessageID:    dl.MessageID,
+		Payload:      dl.Payload,
+		Error:        dl.Error,
+		Attempts:     dl.Attempts,
+		DateCreated:  dl.DateCreated.Format(time.RFC3339),
+		DateReplayed: replayed,
+	}
+}
+
+func toAppDeadLetters(dls []deadletterbus.DeadLetter) []DeadLetter {
+	app := make([]DeadLetter, len(dls))
+	for i, dl := range dls {
+		app[i] = toAppDeadLetter(dl)
+	}
+
+	return app
+}
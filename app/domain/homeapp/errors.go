@@ -0,0 +1,14 @@
+package homeapp
+
+import (
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/homebus"
+)
+
+// init registers the home sentinel errors so they reach clients with the
+// right code.
+func init() {
+	errs.Register(homebus.ErrNotFound, errs.Class{Code: errs.NotFound, AppCode: errs.AppHomeNotFound})
+	errs.Register(homebus.ErrUserDisabled, errs.Class{Code: errs.FailedPrecondition, AppCode: errs.AppHomeUserDisabled})
+	errs.Register(homebus.ErrModified, errs.Class{Code: errs.FailedPrecondition, AppCode: errs.AppHomeModified})
+}
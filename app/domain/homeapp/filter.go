@@ -1,54 +1,29 @@
 package homeapp
 
 import (
-	"time"
-
 	"github.com/ardanlabs/encore/app/sdk/errs"
 	"github.com/ardanlabs/encore/business/domain/homebus"
-	"github.com/google/uuid"
+	"github.com/ardanlabs/encore/business/sdk/queryfilter"
+	"github.com/ardanlabs/encore/business/sdk/where"
 )
 
 func parseFilter(qp QueryParams) (homebus.QueryFilter, error) {
-	var filter homebus.QueryFilter
-
-	if qp.ID != "" {
-		id, err := uuid.Parse(qp.ID)
-		if err != nil {
-			return homebus.QueryFilter{}, errs.NewFieldsError("home_id", err)
-		}
-		filter.ID = &id
-	}
-
-	if qp.UserID != "" {
-		id, err := uuid.Parse(qp.UserID)
-		if err != nil {
-			return homebus.QueryFilter{}, errs.NewFieldsError("user_id", err)
-		}
-		filter.UserID = &id
-	}
-
-	if qp.Type != "" {
-		typ, err := homebus.ParseType(qp.Type)
-		if err != nil {
-			return homebus.QueryFilter{}, errs.NewFieldsError("type", err)
-		}
-		filter.Type = &typ
-	}
-
-	if qp.StartCreatedDate != "" {
-		t, err := time.Parse(time.RFC3339, qp.StartCreatedDate)
-		if err != nil {
-			return homebus.QueryFilter{}, errs.NewFieldsError("start_created_date", err)
-		}
-		filter.StartCreatedDate = &t
+	p := queryfilter.New()
+
+	filter := homebus.QueryFilter{
+		ID:      queryfilter.Value(p, "home_id", qp.ID, queryfilter.UUID),
+		UserID:  queryfilter.Value(p, "user_id", qp.UserID, queryfilter.UUID),
+		Address: queryfilter.Value(p, "address", qp.Address, queryfilter.String),
+		Type: queryfilter.Where(p, "type", map[where.Op]string{
+			where.EQ: qp.Type,
+			where.IN: qp.TypeIn,
+		}, homebus.ParseType),
+		StartCreatedDate: queryfilter.Value(p, "start_created_date", qp.StartCreatedDate, queryfilter.Time),
+		EndCreatedDate:   queryfilter.Value(p, "end_created_date", qp.EndCreatedDate, queryfilter.Time),
 	}
 
-	if qp.EndCreatedDate != "" {
-		t, err := time.Parse(time.RFC3339, qp.EndCreatedDate)
-		if err != nil {
-			return homebus.QueryFilter{}, errs.NewFieldsError("end_created_date", err)
-		}
-		filter.EndCreatedDate = &t
+	if err := p.Err(); err != nil {
+		return homebus.QueryFilter{}, errs.NewFilterErrors(err)
 	}
 
 	return filter, nil
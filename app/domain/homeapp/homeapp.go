@@ -3,29 +3,82 @@ package homeapp
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"iter"
 
+	"github.com/ardanlabs/encore/app/sdk/bulk"
 	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/etag"
+	"github.com/ardanlabs/encore/app/sdk/fields"
+	"github.com/ardanlabs/encore/app/sdk/include"
+	"github.com/ardanlabs/encore/app/sdk/links"
 	"github.com/ardanlabs/encore/app/sdk/mid"
+	"github.com/ardanlabs/encore/app/sdk/prefs"
 	"github.com/ardanlabs/encore/app/sdk/query"
 	"github.com/ardanlabs/encore/business/domain/homebus"
+	"github.com/ardanlabs/encore/business/domain/userbus"
 	"github.com/ardanlabs/encore/business/sdk/order"
 	"github.com/ardanlabs/encore/business/sdk/page"
+	"github.com/google/uuid"
 )
 
 // App manages the set of app layer api functions for the home domain.
 type App struct {
 	homeBus *homebus.Business
+	userBus *userbus.Business
+	links   *links.Builder
 }
 
 // NewApp constructs a home domain API for use.
-func NewApp(homeBus *homebus.Business) *App {
+func NewApp(homeBus *homebus.Business, userBus *userbus.Business, lb *links.Builder) *App {
 	return &App{
 		homeBus: homeBus,
+		userBus: userBus,
+		links:   lb,
 	}
 }
 
+// newWithTx constructs a new App value with the domain apis
+// using a store transaction that was created via middleware.
+func (a *App) newWithTx(ctx context.Context) (*App, error) {
+	tx, err := mid.GetTran(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	homeBus, err := a.homeBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	app := App{
+		homeBus: homeBus,
+		userBus: a.userBus,
+		links:   a.links,
+	}
+
+	return &app, nil
+}
+
+// withTx returns an App value that uses the transaction in the context
+// when there is one, as for the operations of a batch that share one, else
+// the App value itself.
+func (a *App) withTx(ctx context.Context) (*App, error) {
+	if _, err := mid.GetTran(ctx); err != nil {
+		return a, nil
+	}
+
+	return a.newWithTx(ctx)
+}
+
 // Create adds a new home to the system.
 func (a *App) Create(ctx context.Context, app NewHome) (Home, error) {
+	a, err := a.withTx(ctx)
+	if err != nil {
+		return Home{}, errs.New(errs.Internal, err)
+	}
+
 	nh, err := toBusNewHome(ctx, app)
 	if err != nil {
 		return Home{}, errs.New(errs.InvalidArgument, err)
@@ -33,14 +86,19 @@ func (a *App) Create(ctx context.Context, app NewHome) (Home, error) {
 
 	hme, err := a.homeBus.Create(ctx, nh)
 	if err != nil {
-		return Home{}, errs.Newf(errs.Internal, "create: hme[%+v]: %s", app, err)
+		return Home{}, fmt.Errorf("create: %w", err)
 	}
 
-	return toAppHome(hme), nil
+	return toAppHome(a.links, hme), nil
 }
 
 // Update updates an existing home.
 func (a *App) Update(ctx context.Context, app UpdateHome) (Home, error) {
+	a, err := a.withTx(ctx)
+	if err != nil {
+		return Home{}, errs.New(errs.Internal, err)
+	}
+
 	uh, err := toBusUpdateHome(app)
 	if err != nil {
 		return Home{}, errs.New(errs.InvalidArgument, err)
@@ -51,30 +109,191 @@ func (a *App) Update(ctx context.Context, app UpdateHome) (Home, error) {
 		return Home{}, errs.Newf(errs.Internal, "home missing in context: %s", err)
 	}
 
+	if err := etag.Check(app.IfMatch, hme.DateUpdated); err != nil {
+		return Home{}, err
+	}
+
 	updUsr, err := a.homeBus.Update(ctx, hme, uh)
 	if err != nil {
-		return Home{}, errs.Newf(errs.Internal, "update: homeID[%s] uh[%+v]: %s", hme.ID, uh, err)
+		return Home{}, fmt.Errorf("update: homeID[%s] uh[%+v]: %w", hme.ID, uh, err)
+	}
+
+	return toAppHomeWithETag(a.links, updUsr), nil
+}
+
+// Patch applies a JSON merge patch to an existing home.
+func (a *App) Patch(ctx context.Context, app PatchHome) (Home, error) {
+	uh, err := toUpdateHome(app)
+	if err != nil {
+		return Home{}, err
 	}
 
-	return toAppHome(updUsr), nil
+	return a.Update(ctx, uh)
 }
 
 // Delete removes a home from the system.
-func (a *App) Delete(ctx context.Context) error {
+func (a *App) Delete(ctx context.Context, pc etag.Precondition) error {
+	a, err := a.withTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
 	hme, err := mid.GetHome(ctx)
 	if err != nil {
 		return errs.Newf(errs.Internal, "homeID missing in context: %s", err)
 	}
 
+	if err := etag.Check(pc.IfMatch, hme.DateUpdated); err != nil {
+		return err
+	}
+
 	if err := a.homeBus.Delete(ctx, hme); err != nil {
-		return errs.Newf(errs.Internal, "delete: homeID[%s]: %s", hme.ID, err)
+		return fmt.Errorf("delete: homeID[%s]: %w", hme.ID, err)
 	}
 
 	return nil
 }
 
+// DeleteMany removes the specified homes under a single transaction. Each
+// home is checked on its own and the result reports what happened to each.
+func (a *App) DeleteMany(ctx context.Context, app bulk.IDs, authorize bulk.AuthorizeFunc) (bulk.Result, error) {
+	a, err := a.newWithTx(ctx)
+	if err != nil {
+		return bulk.Result{}, errs.New(errs.Internal, err)
+	}
+
+	var result bulk.Result
+
+	for _, id := range app.IDs {
+		homeID, err := uuid.Parse(id)
+		if err != nil {
+			result.Add(id, bulk.StatusInvalid, mid.ErrInvalidID)
+			continue
+		}
+
+		hme, err := a.homeBus.QueryByID(ctx, homeID)
+		if err != nil {
+			if errors.Is(err, homebus.ErrNotFound) {
+				result.Add(id, bulk.StatusNotFound, homebus.ErrNotFound)
+				continue
+			}
+			return bulk.Result{}, errs.Newf(errs.Internal, "querybyid: homeID[%s]: %s", id, err)
+		}
+
+		if err := authorize(ctx, hme.UserID); err != nil {
+			result.Add(id, bulk.StatusForbidden, bulk.ErrForbidden)
+			continue
+		}
+
+		if tag := app.ETags[id]; tag != "" {
+			if err := etag.Check(tag, hme.DateUpdated); err != nil {
+				result.Add(id, bulk.StatusModified, etag.ErrMismatch)
+				continue
+			}
+		}
+
+		if err := a.homeBus.Delete(ctx, hme); err != nil {
+			return bulk.Result{}, errs.Newf(errs.Internal, "delete: homeID[%s]: %s", id, err)
+		}
+
+		result.Add(id, bulk.StatusDeleted, nil)
+	}
+
+	return result, nil
+}
+
 // Query returns a list of homes with paging.
 func (a *App) Query(ctx context.Context, qp QueryParams) (query.Result[Home], error) {
+	fs, err := fields.Parse[Home](qp.Fields)
+	if err != nil {
+		return query.Result[Home]{}, errs.NewFieldsError("fields", err)
+	}
+
+	inc, err := include.Parse(qp.Include, include.User)
+	if err != nil {
+		return query.Result[Home]{}, errs.NewFieldsError("include", err)
+	}
+
+	var result query.Result[Home]
+
+	switch {
+	case qp.Cursor != "" || qp.Limit != "":
+		result, err = a.queryByKeyset(ctx, qp)
+	default:
+		result, err = a.queryByPage(ctx, qp)
+	}
+
+	if err != nil {
+		return query.Result[Home]{}, err
+	}
+
+	if inc.Has(include.User) {
+		if err := a.includeUsers(ctx, result.Items); err != nil {
+			return query.Result[Home]{}, err
+		}
+	}
+
+	result.Fields = fs
+	result = result.WithLinks(a.links, "/v1/homes", qp)
+
+	return result, nil
+}
+
+// QueryStream returns every home that matches the query one at a time
+// instead of a page. The query is checked before the sequence is returned
+// so an invalid query is reported before anything is written.
+func (a *App) QueryStream(ctx context.Context, qp QueryParams) (iter.Seq2[Home, error], error) {
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return nil, err
+	}
+
+	orderBy, err := order.Parse(orderByFields, qp.OrderBy, defaultOrderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	seq := func(yield func(Home, error) bool) {
+		for hme, err := range a.homeBus.QueryStream(ctx, filter, orderBy) {
+			if err != nil {
+				yield(Home{}, errs.Newf(errs.Internal, "querystream: %s", err))
+				return
+			}
+
+			if !yield(toAppHome(a.links, hme), nil) {
+				return
+			}
+		}
+	}
+
+	return seq, nil
+}
+
+// includeUsers embeds the summary of the user that owns each home.
+func (a *App) includeUsers(ctx context.Context, hmes []Home) error {
+	userIDs := make([]string, len(hmes))
+	for i, hme := range hmes {
+		userIDs[i] = hme.UserID
+	}
+
+	users, err := include.Users(ctx, a.userBus, userIDs)
+	if err != nil {
+		return errs.Newf(errs.Internal, "include users: %s", err)
+	}
+
+	for i := range hmes {
+		if usr, exists := users[hmes[i].UserID]; exists {
+			hmes[i].User = &usr
+		}
+	}
+
+	return nil
+}
+
+// queryByPage returns a list of homes using the page and rows.
+func (a *App) queryByPage(ctx context.Context, qp QueryParams) (query.Result[Home], error) {
+	qp.Rows, qp.OrderBy = prefs.Apply(ctx, prefs.Homes, qp.Rows, qp.OrderBy)
+
 	page, err := page.Parse(qp.Page, qp.Rows)
 	if err != nil {
 		return query.Result[Home]{}, err
@@ -100,7 +319,41 @@ func (a *App) Query(ctx context.Context, qp QueryParams) (query.Result[Home], er
 		return query.Result[Home]{}, errs.Newf(errs.Internal, "count: %s", err)
 	}
 
-	return query.NewResult(toAppHomes(hmes), total, page), nil
+	return query.NewResult(toAppHomes(a.links, hmes), total, page), nil
+}
+
+// queryByKeyset returns a list of homes using the cursor and limit. Keyset
+// paging always orders by the home id.
+func (a *App) queryByKeyset(ctx context.Context, qp QueryParams) (query.Result[Home], error) {
+	if qp.OrderBy != "" {
+		return query.Result[Home]{}, errs.Newf(errs.InvalidArgument, "orderBy can't be used with a cursor or limit")
+	}
+
+	keyset, err := page.ParseKeyset(qp.Cursor, qp.Limit)
+	if err != nil {
+		return query.Result[Home]{}, errs.New(errs.InvalidArgument, err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return query.Result[Home]{}, err
+	}
+
+	hmes, err := a.homeBus.QueryByKeyset(ctx, filter, keyset)
+	if err != nil {
+		return query.Result[Home]{}, errs.Newf(errs.Internal, "querybykeyset: %s", err)
+	}
+
+	total, err := a.homeBus.Count(ctx, filter)
+	if err != nil {
+		return query.Result[Home]{}, errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	hmes, next, prev := page.Cursors(keyset, hmes, func(hme homebus.Home) string {
+		return hme.ID.String()
+	})
+
+	return query.NewKeysetResult(toAppHomes(a.links, hmes), total, keyset, next, prev), nil
 }
 
 // QueryByID returns a home by its Ia.
@@ -110,5 +363,5 @@ func (a *App) QueryByID(ctx context.Context) (Home, error) {
 		return Home{}, errs.Newf(errs.Internal, "querybyid: %s", err)
 	}
 
-	return toAppHome(hme), nil
+	return toAppHomeWithETag(a.links, hme), nil
 }
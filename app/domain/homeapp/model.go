@@ -7,7 +7,11 @@ import (
 	"time"
 
 	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/etag"
+	"github.com/ardanlabs/encore/app/sdk/include"
+	"github.com/ardanlabs/encore/app/sdk/links"
 	"github.com/ardanlabs/encore/app/sdk/mid"
+	"github.com/ardanlabs/encore/app/sdk/patch"
 	"github.com/ardanlabs/encore/business/domain/homebus"
 )
 
@@ -15,10 +19,16 @@ import (
 type QueryParams struct {
 	Page             string
 	Rows             string
+	Cursor           string
+	Limit            string
+	Fields           string
 	OrderBy          string
+	Include          string
 	ID               string
 	UserID           string
+	Address          string
 	Type             string
+	TypeIn           string `query:"type[in]"`
 	StartCreatedDate string
 	EndCreatedDate   string
 }
@@ -37,12 +47,15 @@ type Address struct {
 
 // Home represents information about an individual home.
 type Home struct {
-	ID          string  `json:"id"`
-	UserID      string  `json:"userID"`
-	Type        string  `json:"type"`
-	Address     Address `json:"address"`
-	DateCreated string  `json:"dateCreated"`
-	DateUpdated string  `json:"dateUpdated"`
+	ID          string               `json:"id"`
+	UserID      string               `json:"userID"`
+	Type        string               `json:"type"`
+	Address     Address              `json:"address"`
+	DateCreated string               `json:"dateCreated"`
+	DateUpdated string               `json:"dateUpdated"`
+	User        *include.UserSummary `json:"user,omitempty"`
+	ETag        string               `json:"etag,omitempty" header:"ETag"`
+	Links       links.Links          `json:"links,omitempty"`
 }
 
 // Encode implments the encoder interface.
@@ -51,7 +64,7 @@ func (app Home) Encode() ([]byte, string, error) {
 	return data, "application/json", err
 }
 
-func toAppHome(hme homebus.Home) Home {
+func toAppHome(lb *links.Builder, hme homebus.Home) Home {
 	return Home{
 		ID:     hme.ID.String(),
 		UserID: hme.UserID.String(),
@@ -66,13 +79,26 @@ func toAppHome(hme homebus.Home) Home {
 		},
 		DateCreated: hme.DateCreated.Format(time.RFC3339),
 		DateUpdated: hme.DateUpdated.Format(time.RFC3339),
+		Links: links.Links{
+			links.Self:  lb.Link("/v1/homes/"+hme.ID.String(), nil),
+			links.Owner: lb.Link("/v1/users/"+hme.UserID.String(), nil),
+		},
 	}
 }
 
-func toAppHomes(homes []homebus.Home) []Home {
+// toAppHomeWithETag converts the home and sets the entity tag clients
+// send back with an If-Match header when changing the home.
+func toAppHomeWithETag(lb *links.Builder, hme homebus.Home) Home {
+	app := toAppHome(lb, hme)
+	app.ETag = etag.New(hme.DateUpdated)
+
+	return app
+}
+
+func toAppHomes(lb *links.Builder, homes []homebus.Home) []Home {
 	app := make([]Home, len(homes))
 	for i, hme := range homes {
-		app[i] = toAppHome(hme)
+		app[i] = toAppHome(lb, hme)
 	}
 
 	return app
@@ -92,7 +118,7 @@ type NewAddress struct {
 
 // NewHome defines the data needed to add a new home.
 type NewHome struct {
-	Type    string     `json:"type" validate:"required"`
+	Type    string     `json:"type" validate:"required,enum=hometype"`
 	Address NewAddress `json:"address"`
 }
 
@@ -104,7 +130,7 @@ func (app *NewHome) Decode(data []byte) error {
 // Validate checks if the data in the model is considered clean.
 func (app NewHome) Validate() error {
 	if err := errs.Check(app); err != nil {
-		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
 	}
 
 	return nil
@@ -151,8 +177,9 @@ type UpdateAddress struct {
 
 // UpdateHome defines the data needed to update a home.
 type UpdateHome struct {
-	Type    *string        `json:"type"`
+	Type    *string        `json:"type" validate:"omitempty,enum=hometype"`
 	Address *UpdateAddress `json:"address"`
+	IfMatch string         `header:"If-Match"`
 }
 
 // Decode implments the decoder interface.
@@ -163,24 +190,24 @@ func (app *UpdateHome) Decode(data []byte) error {
 // Validate checks the data in the model is considered clean.
 func (app UpdateHome) Validate() error {
 	if err := errs.Check(app); err != nil {
-		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
 	}
 
 	return nil
 }
 
 func toBusUpdateHome(app UpdateHome) (homebus.UpdateHome, error) {
-	var typ homebus.Type
+	var typ *homebus.Type
 	if app.Type != nil {
-		var err error
-		typ, err = homebus.ParseType(*app.Type)
+		t, err := homebus.ParseType(*app.Type)
 		if err != nil {
 			return homebus.UpdateHome{}, fmt.Errorf("parse: %w", err)
 		}
+		typ = &t
 	}
 
 	bus := homebus.UpdateHome{
-		Type: &typ,
+		Type: typ,
 	}
 
 	if app.Address != nil {
@@ -196,3 +223,82 @@ func toBusUpdateHome(app UpdateHome) (homebus.UpdateHome, error) {
 
 	return bus, nil
 }
+
+// =============================================================================
+
+// PatchAddress defines the address data in a JSON merge patch for a home.
+type PatchAddress struct {
+	Address1 patch.Field[string] `json:"address1"`
+	Address2 patch.Field[string] `json:"address2"`
+	ZipCode  patch.Field[string] `json:"zipCode"`
+	City     patch.Field[string] `json:"city"`
+	State    patch.Field[string] `json:"state"`
+	Country  patch.Field[string] `json:"country"`
+}
+
+// PatchHome defines the data in a JSON merge patch for a home. Fields that
+// are missing are left alone and only address2 can be cleared with a null.
+type PatchHome struct {
+	Type    patch.Field[string]       `json:"type"`
+	Address patch.Field[PatchAddress] `json:"address"`
+	IfMatch string                    `header:"If-Match"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app PatchHome) Validate() error {
+	uh, err := toUpdateHome(app)
+	if err != nil {
+		return err
+	}
+
+	return uh.Validate()
+}
+
+func toUpdateHome(app PatchHome) (UpdateHome, error) {
+	uh := UpdateHome{
+		IfMatch: app.IfMatch,
+	}
+
+	var err error
+	if uh.Type, err = app.Type.Required(); err != nil {
+		return UpdateHome{}, errs.NewFieldsError("type", err)
+	}
+
+	if !app.Address.Set {
+		return uh, nil
+	}
+
+	if app.Address.Null {
+		return UpdateHome{}, errs.NewFieldsError("address", patch.ErrNull)
+	}
+
+	addr := app.Address.Value
+
+	ua := UpdateAddress{
+		Address2: addr.Address2.Optional(),
+	}
+
+	if ua.Address1, err = addr.Address1.Required(); err != nil {
+		return UpdateHome{}, errs.NewFieldsError("address1", err)
+	}
+
+	if ua.ZipCode, err = addr.ZipCode.Required(); err != nil {
+		return UpdateHome{}, errs.NewFieldsError("zipCode", err)
+	}
+
+	if ua.City, err = addr.City.Required(); err != nil {
+		return UpdateHome{}, errs.NewFieldsError("city", err)
+	}
+
+	if ua.State, err = addr.State.Required(); err != nil {
+		return UpdateHome{}, errs.NewFieldsError("state", err)
+	}
+
+	if ua.Country, err = addr.Country.Required(); err != nil {
+		return UpdateHome{}, errs.NewFieldsError("country", err)
+	}
+
+	uh.Address = &ua
+
+	return uh, nil
+}
@@ -0,0 +1,32 @@
+// Package idempotencyapp maintains the app layer api for the idempotency
+// domain.
+package idempotencyapp
+
+import (
+	"context"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/idempotencybus"
+)
+
+// App manages the set of app layer api functions for the idempotency domain.
+type App struct {
+	idempotencyBus *idempotencybus.Business
+}
+
+// NewApp constructs an idempotency domain API for use.
+func NewApp(idempotencyBus *idempotencybus.Business) *App {
+	return &App{
+		idempotencyBus: idempotencyBus,
+	}
+}
+
+// DeleteExpired removes the stored responses that can no longer be
+// replayed.
+func (a *App) DeleteExpired(ctx context.Context) error {
+	if _, err := a.idempotencyBus.DeleteExpired(ctx); err != nil {
+		return errs.Newf(errs.Internal, "deleteexpired: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,12 @@
+package jobapp
+
+import (
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/jobbus"
+)
+
+// init registers the job sentinel errors so they reach clients with the
+// right code.
+func init() {
+	errs.Register(jobbus.ErrNotFound, errs.Class{Code: errs.NotFound, AppCode: errs.AppJobNotFound})
+}
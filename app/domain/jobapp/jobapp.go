@@ -0,0 +1,61 @@
+// Package jobapp maintains the app layer api for the job domain.
+package jobapp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/mid"
+	"github.com/ardanlabs/encore/business/domain/jobbus"
+)
+
+// App manages the set of app layer api functions for the job domain.
+type App struct {
+	jobBus *jobbus.Business
+}
+
+// NewApp constructs a job domain API for use.
+func NewApp(jobBus *jobbus.Business) *App {
+	return &App{
+		jobBus: jobBus,
+	}
+}
+
+// Enqueue hands long running work off to a background job on behalf of the
+// user making the request. The returned value tells Encore to respond with a
+// 202 and where the client can poll for the status of the job.
+func (a *App) Enqueue(ctx context.Context, kind string, payload any) (Accepted, error) {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return Accepted{}, errs.Newf(errs.Unauthenticated, "enqueue: %s", err)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Accepted{}, errs.Newf(errs.InvalidArgument, "enqueue: marshal payload: %s", err)
+	}
+
+	nj := jobbus.NewJob{
+		UserID:  userID,
+		Kind:    kind,
+		Payload: data,
+	}
+
+	job, err := a.jobBus.Enqueue(ctx, nj)
+	if err != nil {
+		return Accepted{}, errs.Newf(errs.Internal, "enqueue: kind[%s]: %s", kind, err)
+	}
+
+	return toAppAccepted(job), nil
+}
+
+// QueryByID returns the status of a job by its ID.
+func (a *App) QueryByID(ctx context.Context) (Job, error) {
+	job, err := mid.GetJob(ctx)
+	if err != nil {
+		return Job{}, errs.Newf(errs.Internal, "querybyid: %s", err)
+	}
+
+	return toAppJob(job), nil
+}
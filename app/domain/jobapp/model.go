@@ -0,0 +1,69 @@
+package jobapp
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/jobbus"
+)
+
+// Job represents information about a background job.
+type Job struct {
+	ID          string          `json:"id"`
+	UserID      string          `json:"userID"`
+	Kind        string          `json:"kind"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"maxAttempts"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	DateCreated string          `json:"dateCreated"`
+	DateUpdated string          `json:"dateUpdated"`
+}
+
+// Encode implments the encoder interface.
+func (app Job) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppJob(job jobbus.Job) Job {
+	var result json.RawMessage
+	if json.Valid(job.Result) {
+		result = job.Result
+	}
+
+	return Job{
+		ID:          job.ID.String(),
+		UserID:      job.UserID.String(),
+		Kind:        job.Kind,
+		Status:      job.Status.String(),
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		Result:      result,
+		Error:       job.Error,
+		DateCreated: job.DateCreated.Format(time.RFC3339),
+		DateUpdated: job.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+// =============================================================================
+
+// Accepted is returned by endpoints that hand work off to a background job.
+// The client polls the location to learn the status of the job.
+type Accepted struct {
+	HTTPStatus int    `json:"-" encore:"httpstatus"`
+	Location   string `json:"-" header:"Location"`
+	JobID      string `json:"jobID"`
+	Status     string `json:"status"`
+}
+
+func toAppAccepted(job jobbus.Job) Accepted {
+	return Accepted{
+		HTTPStatus: http.StatusAccepted,
+		Location:   "/v1/jobs/" + job.ID.String(),
+		JobID:      job.ID.String(),
+		Status:     job.Status.String(),
+	}
+}
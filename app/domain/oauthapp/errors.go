@@ -0,0 +1,19 @@
+package oauthapp
+
+import (
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/oauthbus"
+)
+
+// init registers the oauth sentinel errors so they reach clients with the
+// right code.
+func init() {
+	errs.Register(oauthbus.ErrStateNotFound, errs.Class{Code: errs.Unauthenticated, AppCode: errs.AppOAuthStateNotFound})
+	errs.Register(oauthbus.ErrStateExpired, errs.Class{Code: errs.Unauthenticated, AppCode: errs.AppOAuthStateExpired})
+	errs.Register(oauthbus.ErrEmailNotVerified, errs.Class{Code: errs.PermissionDenied, AppCode: errs.AppOAuthEmailNotVerified})
+	errs.Register(oauthbus.ErrNotProvisioned, errs.Class{Code: errs.PermissionDenied, AppCode: errs.AppOAuthNotProvisioned})
+	errs.Register(oauthbus.ErrUserDisabled, errs.Class{Code: errs.FailedPrecondition, AppCode: errs.AppOAuthUserDisabled})
+	errs.Register(oauthbus.ErrLinkRequired, errs.Class{Code: errs.PermissionDenied, AppCode: errs.AppOAuthLinkRequired})
+	errs.Register(oauthbus.ErrIdentityLinked, errs.Class{Code: errs.AlreadyExists, AppCode: errs.AppOAuthIdentityLinked})
+	errs.Register(oauthbus.ErrLinkScope, errs.Class{Code: errs.PermissionDenied, AppCode: errs.AppOAuthLinkScope})
+}
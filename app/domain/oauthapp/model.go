@@ -0,0 +1,94 @@
+package oauthapp
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/mail"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/oauthbus"
+	"github.com/ardanlabs/encore/foundation/oidc"
+)
+
+// stateCookie is the cookie that ties a login to the browser that started
+// it, so a callback from another browser isn't accepted.
+const stateCookie = "oidc_state"
+
+// Redirect represents where the user is sent to log in with the provider,
+// along with the cookie that ties the login to their browser.
+type Redirect struct {
+	URL       string `json:"url"`
+	SetCookie string `json:"-" header:"Set-Cookie"`
+}
+
+func toAppRedirect(url string, st oauthbus.State) Redirect {
+	cookie := http.Cookie{
+		Name:     stateCookie,
+		Value:    st.State,
+		Path:     "/v1/auth/oidc",
+		Expires:  st.DateExpires,
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+
+	return Redirect{
+		URL:       url,
+		SetCookie: cookie.String(),
+	}
+}
+
+// Callback represents what the provider sends back with the user. The
+// provider sends an error instead of a code when the user didn't log in.
+// The cookie is the one set when the login was started.
+type Callback struct {
+	Code             string `query:"code" validate:"required_without=Error"`
+	State            string `query:"state" validate:"required"`
+	Error            string `query:"error"`
+	ErrorDescription string `query:"error_description"`
+	Cookie           string `header:"Cookie"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app Callback) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
+	}
+
+	return nil
+}
+
+// matchState reports if the state the provider sent back is the one the
+// browser started the login with.
+func (app Callback) matchState() bool {
+	cookies, err := http.ParseCookie(app.Cookie)
+	if err != nil {
+		return false
+	}
+
+	for _, c := range cookies {
+		if c.Name == stateCookie {
+			return subtle.ConstantTimeCompare([]byte(c.Value), []byte(app.State)) == 1
+		}
+	}
+
+	return false
+}
+
+func toBusProfile(provider string, ident oidc.Identity) (oauthbus.Profile, error) {
+	addr, err := mail.ParseAddress(ident.Email)
+	if err != nil {
+		return oauthbus.Profile{}, fmt.Errorf("parse email: %w", err)
+	}
+
+	prof := oauthbus.Profile{
+		Provider:      provider,
+		Subject:       ident.Subject,
+		Email:         *addr,
+		EmailVerified: ident.EmailVerified,
+		Name:          ident.Name,
+	}
+
+	return prof, nil
+}
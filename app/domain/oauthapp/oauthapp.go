@@ -0,0 +1,160 @@
+// Package oauthapp maintains the app layer api for logging users in with an
+// external identity provider like Google or GitHub.
+package oauthapp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ardanlabs/encore/app/domain/userapp"
+	"github.com/ardanlabs/encore/app/sdk/auth"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/mid"
+	"github.com/ardanlabs/encore/business/domain/oauthbus"
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/foundation/oidc"
+)
+
+// App manages the set of app layer api functions for the oauth domain.
+type App struct {
+	oauthBus  *oauthbus.Business
+	userApp   *userapp.App
+	auth      *auth.Auth
+	provision bool
+	providers map[string]oidc.Provider
+}
+
+// NewApp constructs an oauth app API for use. Users that log in for the first
+// time are provisioned when provision is set. Only the providers that are
+// passed can be logged in with.
+func NewApp(oauthBus *oauthbus.Business, userApp *userapp.App, ath *auth.Auth, provision bool, providers ...oidc.Provider) *App {
+	m := make(map[string]oidc.Provider, len(providers))
+	for _, p := range providers {
+		m[p.Name()] = p
+	}
+
+	return &App{
+		oauthBus:  oauthBus,
+		userApp:   userApp,
+		auth:      ath,
+		provision: provision,
+		providers: m,
+	}
+}
+
+// Start begins a login with the provider and returns where to send the user.
+func (a *App) Start(ctx context.Context, provider string) (Redirect, error) {
+	p, exists := a.providers[provider]
+	if !exists {
+		return Redirect{}, errs.NewNotFound("provider", provider)
+	}
+
+	st, err := a.oauthBus.Start(ctx, p.Name())
+	if err != nil {
+		return Redirect{}, errs.Newf(errs.Internal, "start: %s", err)
+	}
+
+	return a.redirect(ctx, p, st)
+}
+
+// Link begins linking the authenticated user to who they are with the
+// provider and returns where to send the user. This is how a user that isn't
+// linked by their email on login links a provider.
+func (a *App) Link(ctx context.Context, provider string) (Redirect, error) {
+	p, exists := a.providers[provider]
+	if !exists {
+		return Redirect{}, errs.NewNotFound("provider", provider)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return Redirect{}, errs.New(errs.Unauthenticated, err)
+	}
+
+	claims, err := mid.GetClaims(ctx)
+	if err != nil {
+		return Redirect{}, errs.New(errs.Unauthenticated, err)
+	}
+
+	scope, err := userbus.ParseRoles(claims.Roles)
+	if err != nil {
+		return Redirect{}, errs.Newf(errs.Unauthenticated, "parsing roles: %s", err)
+	}
+
+	st, err := a.oauthBus.StartLink(ctx, p.Name(), userID, scope)
+	if err != nil {
+		return Redirect{}, fmt.Errorf("startlink: %w", err)
+	}
+
+	return a.redirect(ctx, p, st)
+}
+
+// Callback finishes the login the provider sent the user back from. The
+// login has to come back to the browser that started it. The user is linked,
+// found or provisioned for who the provider says logged in, and gets a token
+// signed with the active kid.
+func (a *App) Callback(ctx context.Context, app Callback) (userapp.Token, error) {
+	if app.Error != "" {
+		return userapp.Token{}, errs.Newf(errs.Unauthenticated, "provider: %s: %s", app.Error, app.ErrorDescription)
+	}
+
+	if !app.matchState() {
+		return userapp.Token{}, errs.Newf(errs.Unauthenticated, "state: the login wasn't started by this browser")
+	}
+
+	st, err := a.oauthBus.Consume(ctx, app.State)
+	if err != nil {
+		return userapp.Token{}, fmt.Errorf("consume: %w", err)
+	}
+
+	p, exists := a.providers[st.Provider]
+	if !exists {
+		return userapp.Token{}, errs.NewNotFound("provider", st.Provider)
+	}
+
+	ident, err := p.Identify(ctx, app.Code, st.Nonce, st.Verifier)
+	if err != nil {
+		return userapp.Token{}, errs.Newf(errs.Unauthenticated, "identify: provider[%s]: %s", st.Provider, err)
+	}
+
+	prof, err := toBusProfile(st.Provider, ident)
+	if err != nil {
+		return userapp.Token{}, errs.Newf(errs.Unauthenticated, "profile: provider[%s]: %s", st.Provider, err)
+	}
+
+	var usr userbus.User
+	switch {
+	case st.Linking():
+		if usr, err = a.oauthBus.Link(ctx, prof, st.UserID); err != nil {
+			return userapp.Token{}, fmt.Errorf("link: %w", err)
+		}
+
+	default:
+		if usr, err = a.oauthBus.Login(ctx, prof, a.provision); err != nil {
+			return userapp.Token{}, fmt.Errorf("login: %w", err)
+		}
+	}
+
+	return a.userApp.Token(ctx, a.auth.ActiveKID(), a.auth.NewClaims(usr))
+}
+
+// redirect returns where to send the user to log in with the provider for
+// the state.
+func (a *App) redirect(ctx context.Context, p oidc.Provider, st oauthbus.State) (Redirect, error) {
+	url, err := p.AuthCodeURL(ctx, st.State, st.Nonce, st.Verifier)
+	if err != nil {
+		return Redirect{}, errs.Newf(errs.Unavailable, "authcodeurl: provider[%s]: %s", p.Name(), err)
+	}
+
+	return toAppRedirect(url, st), nil
+}
+
+// DeleteExpiredStates removes the states of the logins that were never
+// finished.
+func (a *App) DeleteExpiredStates(ctx context.Context) error {
+	if _, err := a.oauthBus.DeleteExpired(ctx); err != nil {
+		return errs.Newf(errs.Internal, "deleteexpired: %s", err)
+	}
+
+	return nil
+}
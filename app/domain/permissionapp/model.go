@@ -0,0 +1,30 @@
+package permissionapp
+
+import (
+	"github.com/ardanlabs/encore/business/domain/permissionbus"
+)
+
+// RolePermission represents the permissions granted to a role.
+type RolePermission struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
+
+// RolePermissions represents the permissions granted to each role.
+type RolePermissions struct {
+	Items []RolePermission `json:"items"`
+}
+
+func toAppRolePermissions(rps []permissionbus.RolePermissions) RolePermissions {
+	items := make([]RolePermission, len(rps))
+	for i, rp := range rps {
+		items[i] = RolePermission{
+			Role:        rp.Role.String(),
+			Permissions: permissionbus.ParsePermissionsToString(rp.Permissions),
+		}
+	}
+
+	return RolePermissions{
+		Items: items,
+	}
+}
@@ -0,0 +1,75 @@
+// Package permissionapp maintains the app layer api for the permissions
+// granted to each role.
+package permissionapp
+
+import (
+	"context"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/permissionbus"
+	"github.com/ardanlabs/encore/business/domain/userbus"
+)
+
+// App manages the set of app layer api functions for the permission domain.
+type App struct {
+	permissionBus *permissionbus.Business
+}
+
+// NewApp constructs a permission app API for use.
+func NewApp(permissionBus *permissionbus.Business) *App {
+	return &App{
+		permissionBus: permissionBus,
+	}
+}
+
+// Query returns the permissions granted to each role.
+func (a *App) Query(ctx context.Context) (RolePermissions, error) {
+	rps, err := a.permissionBus.Query(ctx)
+	if err != nil {
+		return RolePermissions{}, errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	return toAppRolePermissions(rps), nil
+}
+
+// Grant grants the permission to the role.
+func (a *App) Grant(ctx context.Context, role string, permission string) error {
+	r, p, err := parse(role, permission)
+	if err != nil {
+		return err
+	}
+
+	if err := a.permissionBus.Grant(ctx, r, p); err != nil {
+		return errs.Newf(errs.Internal, "grant: %s", err)
+	}
+
+	return nil
+}
+
+// Revoke takes the permission away from the role.
+func (a *App) Revoke(ctx context.Context, role string, permission string) error {
+	r, p, err := parse(role, permission)
+	if err != nil {
+		return err
+	}
+
+	if err := a.permissionBus.Revoke(ctx, r, p); err != nil {
+		return errs.Newf(errs.Internal, "revoke: %s", err)
+	}
+
+	return nil
+}
+
+func parse(role string, permission string) (userbus.Role, permissionbus.Permission, error) {
+	r, err := userbus.ParseRole(role)
+	if err != nil {
+		return userbus.Role{}, permissionbus.Permission{}, errs.NewFieldsError("role", err)
+	}
+
+	p, err := permissionbus.ParsePermission(permission)
+	if err != nil {
+		return userbus.Role{}, permissionbus.Permission{}, errs.NewFieldsError("permission", err)
+	}
+
+	return r, p, nil
+}
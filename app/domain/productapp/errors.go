@@ -0,0 +1,15 @@
+package productapp
+
+import (
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/productbus"
+)
+
+// init registers the product sentinel errors so they reach clients with the
+// right code.
+func init() {
+	errs.Register(productbus.ErrNotFound, errs.Class{Code: errs.NotFound, AppCode: errs.AppProductNotFound})
+	errs.Register(productbus.ErrUserDisabled, errs.Class{Code: errs.FailedPrecondition, AppCode: errs.AppProductUserDisabled})
+	errs.Register(productbus.ErrInvalidCost, errs.Class{Code: errs.InvalidArgument, AppCode: errs.AppProductInvalidCost})
+	errs.Register(productbus.ErrModified, errs.Class{Code: errs.FailedPrecondition, AppCode: errs.AppProductModified})
+}
@@ -1,48 +1,61 @@
 package productapp
 
 import (
-	"strconv"
+	"net/url"
 
 	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/export"
 	"github.com/ardanlabs/encore/business/domain/productbus"
-	"github.com/google/uuid"
+	"github.com/ardanlabs/encore/business/sdk/queryfilter"
+	"github.com/ardanlabs/encore/business/sdk/where"
 )
 
 func parseFilter(qp QueryParams) (productbus.QueryFilter, error) {
-	var filter productbus.QueryFilter
-
-	if qp.ID != "" {
-		id, err := uuid.Parse(qp.ID)
-		if err != nil {
-			return productbus.QueryFilter{}, errs.NewFieldsError("product_id", err)
-		}
-		filter.ID = &id
+	p := queryfilter.New()
+
+	filter := productbus.QueryFilter{
+		ID:   queryfilter.Value(p, "product_id", qp.ID, queryfilter.UUID),
+		Name: queryfilter.Value(p, "name", qp.Name, productbus.ParseName),
+		Cost: queryfilter.Where(p, "cost", map[where.Op]string{
+			where.EQ:  qp.Cost,
+			where.GT:  qp.CostGT,
+			where.GTE: qp.CostGTE,
+			where.LT:  qp.CostLT,
+			where.LTE: qp.CostLTE,
+			where.IN:  qp.CostIn,
+		}, queryfilter.Float),
+		Quantity: queryfilter.Where(p, "quantity", map[where.Op]string{
+			where.EQ:  qp.Quantity,
+			where.GT:  qp.QuantityGT,
+			where.GTE: qp.QuantityGTE,
+			where.LT:  qp.QuantityLT,
+			where.LTE: qp.QuantityLTE,
+			where.IN:  qp.QuantityIn,
+		}, queryfilter.Int),
 	}
 
-	if qp.Name != "" {
-		name, err := productbus.ParseName(qp.Name)
-		if err != nil {
-			return productbus.QueryFilter{}, errs.NewFieldsError("name", err)
-		}
-		filter.Name = &name
+	if err := p.Err(); err != nil {
+		return productbus.QueryFilter{}, errs.NewFilterErrors(err)
 	}
 
-	if qp.Cost != "" {
-		cst, err := strconv.ParseFloat(qp.Cost, 64)
-		if err != nil {
-			return productbus.QueryFilter{}, errs.NewFieldsError("cost", err)
-		}
-		filter.Cost = &cst
+	return filter, nil
+}
+
+// ValidateSearch reports if the values are a filter and order a query would
+// accept, so a search can be checked before it's saved for later use.
+func ValidateSearch(values url.Values) error {
+	var qp QueryParams
+	if err := export.Decode(values, &qp); err != nil {
+		return errs.New(errs.InvalidArgument, err)
 	}
 
-	if qp.Quantity != "" {
-		qua, err := strconv.ParseInt(qp.Quantity, 10, 64)
-		if err != nil {
-			return productbus.QueryFilter{}, errs.NewFieldsError("quantity", err)
-		}
-		i := int(qua)
-		filter.Quantity = &i
+	if _, err := parseFilter(qp); err != nil {
+		return err
 	}
 
-	return filter, nil
+	if err := ValidateOrderBy(qp.OrderBy); err != nil {
+		return errs.NewFieldsError("orderBy", err)
+	}
+
+	return nil
 }
@@ -6,33 +6,57 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ardanlabs/encore/app/sdk/deprecation"
 	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/etag"
+	"github.com/ardanlabs/encore/app/sdk/include"
+	"github.com/ardanlabs/encore/app/sdk/links"
 	"github.com/ardanlabs/encore/app/sdk/mid"
 	"github.com/ardanlabs/encore/business/domain/productbus"
 )
 
 // QueryParams represents the set of possible query strings.
 type QueryParams struct {
-	Page     string
-	Rows     string
-	OrderBy  string
-	ID       string
-	Name     string
-	Cost     string
-	Quantity string
+	Page        string
+	Rows        string
+	Cursor      string
+	Limit       string
+	Fields      string
+	OrderBy     string
+	Include     string
+	ID          string
+	Name        string
+	Cost        string
+	CostGT      string `query:"cost[gt]"`
+	CostGTE     string `query:"cost[gte]"`
+	CostLT      string `query:"cost[lt]"`
+	CostLTE     string `query:"cost[lte]"`
+	CostIn      string `query:"cost[in]"`
+	Quantity    string
+	QuantityGT  string `query:"quantity[gt]"`
+	QuantityGTE string `query:"quantity[gte]"`
+	QuantityLT  string `query:"quantity[lt]"`
+	QuantityLTE string `query:"quantity[lte]"`
+	QuantityIn  string `query:"quantity[in]"`
 }
 
 // =============================================================================
 
 // Product represents information about an individual product.
 type Product struct {
-	ID          string  `json:"id"`
-	UserID      string  `json:"userID"`
-	Name        string  `json:"name"`
-	Cost        float64 `json:"cost"`
-	Quantity    int     `json:"quantity"`
-	DateCreated string  `json:"dateCreated"`
-	DateUpdated string  `json:"dateUpdated"`
+	ID          string               `json:"id"`
+	UserID      string               `json:"userID"`
+	Name        string               `json:"name"`
+	Cost        float64              `json:"cost"`
+	Quantity    int                  `json:"quantity"`
+	DateCreated string               `json:"dateCreated"`
+	DateUpdated string               `json:"dateUpdated"`
+	User        *include.UserSummary `json:"user,omitempty"`
+	ETag        string               `json:"etag,omitempty" header:"ETag"`
+	Links       links.Links          `json:"links,omitempty"`
+	Deprecation string               `json:"-" header:"Deprecation"`
+	Sunset      string               `json:"-" header:"Sunset"`
+	Link        string               `json:"-" header:"Link"`
 }
 
 // Encode implments the encoder interface.
@@ -41,7 +65,16 @@ func (app Product) Encode() ([]byte, string, error) {
 	return data, "application/json", err
 }
 
-func toAppProduct(prd productbus.Product) Product {
+// WithDeprecation returns the product with the deprecation headers set.
+func (app Product) WithDeprecation(n deprecation.Notice) any {
+	app.Deprecation = n.Deprecation
+	app.Sunset = n.Sunset
+	app.Link = n.Link
+
+	return app
+}
+
+func toAppProduct(lb *links.Builder, prd productbus.Product) Product {
 	return Product{
 		ID:          prd.ID.String(),
 		UserID:      prd.UserID.String(),
@@ -50,13 +83,26 @@ func toAppProduct(prd productbus.Product) Product {
 		Quantity:    prd.Quantity,
 		DateCreated: prd.DateCreated.Format(time.RFC3339),
 		DateUpdated: prd.DateUpdated.Format(time.RFC3339),
+		Links: links.Links{
+			links.Self:  lb.Link("/v1/products/"+prd.ID.String(), nil),
+			links.Owner: lb.Link("/v1/users/"+prd.UserID.String(), nil),
+		},
 	}
 }
 
-func toAppProducts(prds []productbus.Product) []Product {
+// toAppProductWithETag converts the product and sets the entity tag clients
+// send back with an If-Match header when changing the product.
+func toAppProductWithETag(lb *links.Builder, prd productbus.Product) Product {
+	app := toAppProduct(lb, prd)
+	app.ETag = etag.New(prd.DateUpdated)
+
+	return app
+}
+
+func toAppProducts(lb *links.Builder, prds []productbus.Product) []Product {
 	app := make([]Product, len(prds))
 	for i, prd := range prds {
-		app[i] = toAppProduct(prd)
+		app[i] = toAppProduct(lb, prd)
 	}
 
 	return app
@@ -79,7 +125,7 @@ func (app *NewProduct) Decode(data []byte) error {
 // Validate checks the data in the model is considered clean.
 func (app NewProduct) Validate() error {
 	if err := errs.Check(app); err != nil {
-		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
 	}
 
 	return nil
@@ -113,6 +159,7 @@ type UpdateProduct struct {
 	Name     *string  `json:"name"`
 	Cost     *float64 `json:"cost" validate:"omitempty,gte=0"`
 	Quantity *int     `json:"quantity" validate:"omitempty,gte=1"`
+	IfMatch  string   `header:"If-Match"`
 }
 
 // Decode implments the decoder interface.
@@ -123,7 +170,7 @@ func (app *UpdateProduct) Decode(data []byte) error {
 // Validate checks the data in the model is considered clean.
 func (app UpdateProduct) Validate() error {
 	if err := errs.Check(app); err != nil {
-		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
 	}
 
 	return nil
@@ -3,29 +3,82 @@ package productapp
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"iter"
 
+	"github.com/ardanlabs/encore/app/sdk/bulk"
 	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/etag"
+	"github.com/ardanlabs/encore/app/sdk/fields"
+	"github.com/ardanlabs/encore/app/sdk/include"
+	"github.com/ardanlabs/encore/app/sdk/links"
 	"github.com/ardanlabs/encore/app/sdk/mid"
+	"github.com/ardanlabs/encore/app/sdk/prefs"
 	"github.com/ardanlabs/encore/app/sdk/query"
 	"github.com/ardanlabs/encore/business/domain/productbus"
+	"github.com/ardanlabs/encore/business/domain/userbus"
 	"github.com/ardanlabs/encore/business/sdk/order"
 	"github.com/ardanlabs/encore/business/sdk/page"
+	"github.com/google/uuid"
 )
 
 // App manages the set of app layer api functions for the product domain.
 type App struct {
 	productBus *productbus.Business
+	userBus    *userbus.Business
+	links      *links.Builder
 }
 
 // NewApp constructs a product app API for use.
-func NewApp(productBus *productbus.Business) *App {
+func NewApp(productBus *productbus.Business, userBus *userbus.Business, lb *links.Builder) *App {
 	return &App{
 		productBus: productBus,
+		userBus:    userBus,
+		links:      lb,
 	}
 }
 
+// newWithTx constructs a new App value with the domain apis
+// using a store transaction that was created via middleware.
+func (a *App) newWithTx(ctx context.Context) (*App, error) {
+	tx, err := mid.GetTran(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	productBus, err := a.productBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	app := App{
+		productBus: productBus,
+		userBus:    a.userBus,
+		links:      a.links,
+	}
+
+	return &app, nil
+}
+
+// withTx returns an App value that uses the transaction in the context
+// when there is one, as for the operations of a batch that share one, else
+// the App value itself.
+func (a *App) withTx(ctx context.Context) (*App, error) {
+	if _, err := mid.GetTran(ctx); err != nil {
+		return a, nil
+	}
+
+	return a.newWithTx(ctx)
+}
+
 // Create adds a new product to the system.
 func (a *App) Create(ctx context.Context, app NewProduct) (Product, error) {
+	a, err := a.withTx(ctx)
+	if err != nil {
+		return Product{}, errs.New(errs.Internal, err)
+	}
+
 	np, err := toBusNewProduct(ctx, app)
 	if err != nil {
 		return Product{}, errs.New(errs.InvalidArgument, err)
@@ -33,14 +86,19 @@ func (a *App) Create(ctx context.Context, app NewProduct) (Product, error) {
 
 	prd, err := a.productBus.Create(ctx, np)
 	if err != nil {
-		return Product{}, errs.Newf(errs.Internal, "create: prd[%+v]: %s", prd, err)
+		return Product{}, fmt.Errorf("create: %w", err)
 	}
 
-	return toAppProduct(prd), nil
+	return toAppProduct(a.links, prd), nil
 }
 
 // Update updates an existing product.
 func (a *App) Update(ctx context.Context, app UpdateProduct) (Product, error) {
+	a, err := a.withTx(ctx)
+	if err != nil {
+		return Product{}, errs.New(errs.Internal, err)
+	}
+
 	up, err := toBusUpdateProduct(app)
 	if err != nil {
 		return Product{}, errs.New(errs.InvalidArgument, err)
@@ -51,30 +109,181 @@ func (a *App) Update(ctx context.Context, app UpdateProduct) (Product, error) {
 		return Product{}, errs.Newf(errs.Internal, "product missing in context: %s", err)
 	}
 
+	if err := etag.Check(app.IfMatch, prd.DateUpdated); err != nil {
+		return Product{}, err
+	}
+
 	updPrd, err := a.productBus.Update(ctx, prd, up)
 	if err != nil {
-		return Product{}, errs.Newf(errs.Internal, "update: productID[%s] up[%+v]: %s", prd.ID, app, err)
+		return Product{}, fmt.Errorf("update: productID[%s] up[%+v]: %w", prd.ID, app, err)
 	}
 
-	return toAppProduct(updPrd), nil
+	return toAppProductWithETag(a.links, updPrd), nil
 }
 
 // Delete removes a product from the system.
-func (a *App) Delete(ctx context.Context) error {
+func (a *App) Delete(ctx context.Context, pc etag.Precondition) error {
+	a, err := a.withTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
 	prd, err := mid.GetProduct(ctx)
 	if err != nil {
 		return errs.Newf(errs.Internal, "productID missing in context: %s", err)
 	}
 
+	if err := etag.Check(pc.IfMatch, prd.DateUpdated); err != nil {
+		return err
+	}
+
 	if err := a.productBus.Delete(ctx, prd); err != nil {
-		return errs.Newf(errs.Internal, "delete: productID[%s]: %s", prd.ID, err)
+		return fmt.Errorf("delete: productID[%s]: %w", prd.ID, err)
 	}
 
 	return nil
 }
 
+// DeleteMany removes the specified products under a single transaction. Each
+// product is checked on its own and the result reports what happened to each.
+func (a *App) DeleteMany(ctx context.Context, app bulk.IDs, authorize bulk.AuthorizeFunc) (bulk.Result, error) {
+	a, err := a.newWithTx(ctx)
+	if err != nil {
+		return bulk.Result{}, errs.New(errs.Internal, err)
+	}
+
+	var result bulk.Result
+
+	for _, id := range app.IDs {
+		productID, err := uuid.Parse(id)
+		if err != nil {
+			result.Add(id, bulk.StatusInvalid, mid.ErrInvalidID)
+			continue
+		}
+
+		prd, err := a.productBus.QueryByID(ctx, productID)
+		if err != nil {
+			if errors.Is(err, productbus.ErrNotFound) {
+				result.Add(id, bulk.StatusNotFound, productbus.ErrNotFound)
+				continue
+			}
+			return bulk.Result{}, errs.Newf(errs.Internal, "querybyid: productID[%s]: %s", id, err)
+		}
+
+		if err := authorize(ctx, prd.UserID); err != nil {
+			result.Add(id, bulk.StatusForbidden, bulk.ErrForbidden)
+			continue
+		}
+
+		if tag := app.ETags[id]; tag != "" {
+			if err := etag.Check(tag, prd.DateUpdated); err != nil {
+				result.Add(id, bulk.StatusModified, etag.ErrMismatch)
+				continue
+			}
+		}
+
+		if err := a.productBus.Delete(ctx, prd); err != nil {
+			return bulk.Result{}, errs.Newf(errs.Internal, "delete: productID[%s]: %s", id, err)
+		}
+
+		result.Add(id, bulk.StatusDeleted, nil)
+	}
+
+	return result, nil
+}
+
 // Query returns a list of products with paging.
 func (a *App) Query(ctx context.Context, qp QueryParams) (query.Result[Product], error) {
+	fs, err := fields.Parse[Product](qp.Fields)
+	if err != nil {
+		return query.Result[Product]{}, errs.NewFieldsError("fields", err)
+	}
+
+	inc, err := include.Parse(qp.Include, include.User)
+	if err != nil {
+		return query.Result[Product]{}, errs.NewFieldsError("include", err)
+	}
+
+	var result query.Result[Product]
+
+	switch {
+	case qp.Cursor != "" || qp.Limit != "":
+		result, err = a.queryByKeyset(ctx, qp)
+	default:
+		result, err = a.queryByPage(ctx, qp)
+	}
+
+	if err != nil {
+		return query.Result[Product]{}, err
+	}
+
+	if inc.Has(include.User) {
+		if err := a.includeUsers(ctx, result.Items); err != nil {
+			return query.Result[Product]{}, err
+		}
+	}
+
+	result.Fields = fs
+	result = result.WithLinks(a.links, "/v1/products", qp)
+
+	return result, nil
+}
+
+// QueryStream returns every product that matches the query one at a time
+// instead of a page. The query is checked before the sequence is returned
+// so an invalid query is reported before anything is written.
+func (a *App) QueryStream(ctx context.Context, qp QueryParams) (iter.Seq2[Product, error], error) {
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return nil, err
+	}
+
+	orderBy, err := order.Parse(orderByFields, qp.OrderBy, defaultOrderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	seq := func(yield func(Product, error) bool) {
+		for prd, err := range a.productBus.QueryStream(ctx, filter, orderBy) {
+			if err != nil {
+				yield(Product{}, errs.Newf(errs.Internal, "querystream: %s", err))
+				return
+			}
+
+			if !yield(toAppProduct(a.links, prd), nil) {
+				return
+			}
+		}
+	}
+
+	return seq, nil
+}
+
+// includeUsers embeds the summary of the user that owns each product.
+func (a *App) includeUsers(ctx context.Context, prds []Product) error {
+	userIDs := make([]string, len(prds))
+	for i, prd := range prds {
+		userIDs[i] = prd.UserID
+	}
+
+	users, err := include.Users(ctx, a.userBus, userIDs)
+	if err != nil {
+		return errs.Newf(errs.Internal, "include users: %s", err)
+	}
+
+	for i := range prds {
+		if usr, exists := users[prds[i].UserID]; exists {
+			prds[i].User = &usr
+		}
+	}
+
+	return nil
+}
+
+// queryByPage returns a list of products using the page and rows.
+func (a *App) queryByPage(ctx context.Context, qp QueryParams) (query.Result[Product], error) {
+	qp.Rows, qp.OrderBy = prefs.Apply(ctx, prefs.Products, qp.Rows, qp.OrderBy)
+
 	page, err := page.Parse(qp.Page, qp.Rows)
 	if err != nil {
 		return query.Result[Product]{}, err
@@ -100,7 +309,41 @@ func (a *App) Query(ctx context.Context, qp QueryParams) (query.Result[Product],
 		return query.Result[Product]{}, errs.Newf(errs.Internal, "count: %s", err)
 	}
 
-	return query.NewResult(toAppProducts(prds), total, page), nil
+	return query.NewResult(toAppProducts(a.links, prds), total, page), nil
+}
+
+// queryByKeyset returns a list of products using the cursor and limit. Keyset
+// paging always orders by the product id.
+func (a *App) queryByKeyset(ctx context.Context, qp QueryParams) (query.Result[Product], error) {
+	if qp.OrderBy != "" {
+		return query.Result[Product]{}, errs.Newf(errs.InvalidArgument, "orderBy can't be used with a cursor or limit")
+	}
+
+	keyset, err := page.ParseKeyset(qp.Cursor, qp.Limit)
+	if err != nil {
+		return query.Result[Product]{}, errs.New(errs.InvalidArgument, err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return query.Result[Product]{}, err
+	}
+
+	prds, err := a.productBus.QueryByKeyset(ctx, filter, keyset)
+	if err != nil {
+		return query.Result[Product]{}, errs.Newf(errs.Internal, "querybykeyset: %s", err)
+	}
+
+	total, err := a.productBus.Count(ctx, filter)
+	if err != nil {
+		return query.Result[Product]{}, errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	prds, next, prev := page.Cursors(keyset, prds, func(prd productbus.Product) string {
+		return prd.ID.String()
+	})
+
+	return query.NewKeysetResult(toAppProducts(a.links, prds), total, keyset, next, prev), nil
 }
 
 // QueryByID returns a product by its Ia.
@@ -110,5 +353,5 @@ func (a *App) QueryByID(ctx context.Context) (Product, error) {
 		return Product{}, errs.Newf(errs.Internal, "querybyid: %s", err)
 	}
 
-	return toAppProduct(prd), nil
+	return toAppProductWithETag(a.links, prd), nil
 }
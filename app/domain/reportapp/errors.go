@@ -0,0 +1,12 @@
+package reportapp
+
+import (
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/reportbus"
+)
+
+// init registers the report sentinel errors so they reach clients with the
+// right code.
+func init() {
+	errs.Register(reportbus.ErrNotFound, errs.Class{Code: errs.NotFound, AppCode: errs.AppReportNotFound})
+}
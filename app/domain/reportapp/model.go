@@ -0,0 +1,52 @@
+package reportapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/reportbus"
+)
+
+// BuildReport defines the data needed to build the report for a day. The
+// date is a UTC day in the form 2006-01-02.
+type BuildReport struct {
+	Date string `json:"date" validate:"required,datetime=2006-01-02"`
+}
+
+// Decode implments the decoder interface.
+func (app *BuildReport) Decode(data []byte) error {
+	return json.Unmarshal(data, &app)
+}
+
+// Validate checks if the data in the model is considered clean.
+func (app BuildReport) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// Report represents the aggregates for a single day. This is the result
+// stored with the job that built it.
+type Report struct {
+	Date           string  `json:"date"`
+	NewUsers       int     `json:"newUsers"`
+	NewProducts    int     `json:"newProducts"`
+	InventoryValue float64 `json:"inventoryValue"`
+	DateCreated    string  `json:"dateCreated"`
+}
+
+func toAppReport(rpt reportbus.Report) Report {
+	return Report{
+		Date:           rpt.Date.Format(time.DateOnly),
+		NewUsers:       rpt.NewUsers,
+		NewProducts:    rpt.NewProducts,
+		InventoryValue: rpt.InventoryValue,
+		DateCreated:    rpt.DateCreated.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,71 @@
+// Package reportapp maintains the app layer api for the report domain.
+package reportapp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/app/domain/jobapp"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/jobbus"
+	"github.com/ardanlabs/encore/business/domain/reportbus"
+)
+
+// JobBuild is the kind of job that builds the report for a day.
+const JobBuild = "report.build"
+
+// App manages the set of app layer api functions for the report domain.
+type App struct {
+	reportBus *reportbus.Business
+	jobApp    *jobapp.App
+}
+
+// NewApp constructs a report domain API for use.
+func NewApp(reportBus *reportbus.Business, jobApp *jobapp.App) *App {
+	return &App{
+		reportBus: reportBus,
+		jobApp:    jobApp,
+	}
+}
+
+// BuildDaily builds the aggregates for the previous UTC day. This is
+// expected to be called shortly after midnight UTC.
+func (a *App) BuildDaily(ctx context.Context) error {
+	day := time.Now().UTC().Add(-24 * time.Hour)
+
+	if _, err := a.reportBus.BuildDaily(ctx, day); err != nil {
+		return errs.Newf(errs.Internal, "builddaily: %s", err)
+	}
+
+	return nil
+}
+
+// Build hands building the report for the specified day off to a background
+// job, since aggregating a day can take longer than a request should. The
+// client polls the job for the report.
+func (a *App) Build(ctx context.Context, app BuildReport) (jobapp.Accepted, error) {
+	return a.jobApp.Enqueue(ctx, JobBuild, app)
+}
+
+// RunBuild performs the work for a job enqueued by Build. It's registered
+// with the job business layer as the handler for JobBuild.
+func (a *App) RunBuild(ctx context.Context, job jobbus.Job) ([]byte, error) {
+	var app BuildReport
+	if err := json.Unmarshal(job.Payload, &app); err != nil {
+		return nil, fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	day, err := time.Parse(time.DateOnly, app.Date)
+	if err != nil {
+		return nil, fmt.Errorf("parse date: %w", err)
+	}
+
+	rpt, err := a.reportBus.BuildDaily(ctx, day)
+	if err != nil {
+		return nil, fmt.Errorf("builddaily: %w", err)
+	}
+
+	return json.Marshal(toAppReport(rpt))
+}
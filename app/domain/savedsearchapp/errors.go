@@ -0,0 +1,12 @@
+package savedsearchapp
+
+import (
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/savedsearchbus"
+)
+
+// init registers the saved search sentinel errors so they reach clients with the
+// right code.
+func init() {
+	errs.Register(savedsearchbus.ErrNotFound, errs.Class{Code: errs.NotFound, AppCode: errs.AppSavedSearchNotFound})
+}
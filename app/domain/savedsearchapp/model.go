@@ -0,0 +1,89 @@
+package savedsearchapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/savedsearchbus"
+)
+
+// SavedSearch represents a named filter and order saved for an entity.
+type SavedSearch struct {
+	Entity      string            `json:"entity"`
+	Name        string            `json:"name"`
+	Query       map[string]string `json:"query"`
+	DateCreated string            `json:"dateCreated"`
+	DateUpdated string            `json:"dateUpdated"`
+}
+
+// Encode implments the encoder interface.
+func (app SavedSearch) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppSavedSearch(ss savedsearchbus.SavedSearch) SavedSearch {
+	query := make(map[string]string)
+
+	// The query was encoded from validated values when it was saved.
+	values, _ := url.ParseQuery(ss.Query)
+	for key := range values {
+		query[key] = values.Get(key)
+	}
+
+	return SavedSearch{
+		Entity:      ss.Entity,
+		Name:        ss.Name,
+		Query:       query,
+		DateCreated: ss.DateCreated.Format(time.RFC3339),
+		DateUpdated: ss.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+// SavedSearches represents every search the user has saved.
+type SavedSearches struct {
+	Items []SavedSearch `json:"items"`
+}
+
+// Encode implments the encoder interface.
+func (app SavedSearches) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppSavedSearches(searches []savedsearchbus.SavedSearch) SavedSearches {
+	items := make([]SavedSearch, len(searches))
+	for i, ss := range searches {
+		items[i] = toAppSavedSearch(ss)
+	}
+
+	return SavedSearches{
+		Items: items,
+	}
+}
+
+// =============================================================================
+
+// SaveSearch defines the data needed to save a search. The query holds the
+// filter and order_by query string parameters of the entity's query
+// endpoint, like "name" or "cost[gte]".
+type SaveSearch struct {
+	Query map[string]string `json:"query" validate:"required"`
+}
+
+// Decode implments the decoder interface.
+func (app *SaveSearch) Decode(data []byte) error {
+	return json.Unmarshal(data, &app)
+}
+
+// Validate checks if the data in the model is considered clean.
+func (app SaveSearch) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
+	}
+
+	return nil
+}
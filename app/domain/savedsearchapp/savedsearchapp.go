@@ -0,0 +1,215 @@
+// Package savedsearchapp maintains the app layer api for the searches users
+// save by name and run again later.
+package savedsearchapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/export"
+	"github.com/ardanlabs/encore/app/sdk/mid"
+	"github.com/ardanlabs/encore/app/sdk/query"
+	"github.com/ardanlabs/encore/business/domain/savedsearchbus"
+)
+
+// Products is the entity name for saved product searches.
+const Products = "products"
+
+// Entity describes an entity whose searches can be saved.
+type Entity struct {
+	// Params is the zero value of the entity's query parameters, used to
+	// know which parameters a search can hold.
+	Params any
+
+	// Validate reports if the values are a filter and order the entity's
+	// queries accept.
+	Validate func(values url.Values) error
+}
+
+// The paging and shape of a response are picked when a search is run, so
+// they aren't part of what is saved.
+var notSaved = map[string]struct{}{
+	"page":    {},
+	"rows":    {},
+	"cursor":  {},
+	"limit":   {},
+	"fields":  {},
+	"include": {},
+}
+
+var validName = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// App manages the set of app layer api functions for the saved search
+// domain.
+type App struct {
+	savedSearchBus *savedsearchbus.Business
+	entities       map[string]Entity
+}
+
+// NewApp constructs a saved search app API for use. The entities are the
+// ones a user can save searches for.
+func NewApp(savedSearchBus *savedsearchbus.Business, entities map[string]Entity) *App {
+	return &App{
+		savedSearchBus: savedSearchBus,
+		entities:       entities,
+	}
+}
+
+// Query returns the searches the user has saved.
+func (a *App) Query(ctx context.Context) (SavedSearches, error) {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return SavedSearches{}, errs.New(errs.Unauthenticated, err)
+	}
+
+	searches, err := a.savedSearchBus.QueryByUserID(ctx, userID)
+	if err != nil {
+		return SavedSearches{}, errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	return toAppSavedSearches(searches), nil
+}
+
+// Save stores the search for the entity under the name, replacing a search
+// the user saved with the same name.
+func (a *App) Save(ctx context.Context, entity string, name string, app SaveSearch) (SavedSearch, error) {
+	ent, err := a.entity(entity, name)
+	if err != nil {
+		return SavedSearch{}, err
+	}
+
+	allowed := params(ent.Params)
+
+	values := make(url.Values)
+	for key, value := range app.Query {
+		if _, exists := allowed[key]; !exists {
+			return SavedSearch{}, errs.NewFieldsError(key, errors.New("parameter can't be saved"))
+		}
+		values.Set(key, value)
+	}
+
+	if err := ent.Validate(values); err != nil {
+		return SavedSearch{}, err
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return SavedSearch{}, errs.New(errs.Unauthenticated, err)
+	}
+
+	ss := savedsearchbus.SaveSearch{
+		UserID: userID,
+		Entity: entity,
+		Name:   name,
+		Query:  values.Encode(),
+	}
+
+	saved, err := a.savedSearchBus.Save(ctx, ss)
+	if err != nil {
+		return SavedSearch{}, errs.Newf(errs.Internal, "save: %s", err)
+	}
+
+	return toAppSavedSearch(saved), nil
+}
+
+// Delete removes the user's saved search for the entity.
+func (a *App) Delete(ctx context.Context, entity string, name string) error {
+	ss, err := a.queryByName(ctx, entity, name)
+	if err != nil {
+		return err
+	}
+
+	if err := a.savedSearchBus.Delete(ctx, ss); err != nil {
+		return errs.Newf(errs.Internal, "delete: %s", err)
+	}
+
+	return nil
+}
+
+// Apply sets the filter and order of the user's saved search on the query
+// parameters, so the entity's query runs the search. Parameters the search
+// doesn't set, like the page, are left as they are.
+func (a *App) Apply(ctx context.Context, entity string, name string, qp any) error {
+	ss, err := a.queryByName(ctx, entity, name)
+	if err != nil {
+		return err
+	}
+
+	values, err := url.ParseQuery(ss.Query)
+	if err != nil {
+		return errs.Newf(errs.Internal, "parse query: %s", err)
+	}
+
+	if err := export.Decode(values, qp); err != nil {
+		return errs.Newf(errs.Internal, "decode query: %s", err)
+	}
+
+	return nil
+}
+
+func (a *App) queryByName(ctx context.Context, entity string, name string) (savedsearchbus.SavedSearch, error) {
+	if _, err := a.entity(entity, name); err != nil {
+		return savedsearchbus.SavedSearch{}, err
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return savedsearchbus.SavedSearch{}, errs.New(errs.Unauthenticated, err)
+	}
+
+	ss, err := a.savedSearchBus.QueryByName(ctx, userID, entity, name)
+	if err != nil {
+		return savedsearchbus.SavedSearch{}, fmt.Errorf("querybyname: name[%s]: %w", name, err)
+	}
+
+	return ss, nil
+}
+
+func (a *App) entity(entity string, name string) (Entity, error) {
+	ent, exists := a.entities[entity]
+	if !exists {
+		names := make([]string, 0, len(a.entities))
+		for name := range a.entities {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return Entity{}, errs.Newf(errs.InvalidArgument, "unknown entity %q, supported: %s", entity, strings.Join(names, ","))
+	}
+
+	if !validName.MatchString(name) {
+		return Entity{}, errs.Newf(errs.InvalidArgument, "name must be 1 to 64 letters, digits, dashes or underscores")
+	}
+
+	return ent, nil
+}
+
+// params returns the query parameter names of the query params type that
+// can be saved.
+func params(qp any) map[string]struct{} {
+	t := reflect.TypeOf(qp)
+
+	names := make(map[string]struct{})
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() || f.Type.Kind() != reflect.String {
+			continue
+		}
+
+		name := query.ParamName(f)
+		if _, exists := notSaved[name]; exists {
+			continue
+		}
+
+		names[name] = struct{}{}
+	}
+
+	return names
+}
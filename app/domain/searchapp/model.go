@@ -0,0 +1,47 @@
+package searchapp
+
+import (
+	"encoding/json"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+)
+
+// QueryParams represents the set of possible query strings.
+type QueryParams struct {
+	Q     string `query:"q"`
+	Limit string
+}
+
+// Validate checks the data in the model is considered clean.
+func (qp QueryParams) Validate() error {
+	if qp.Q == "" {
+		return errs.Newf(errs.InvalidArgument, "validate: %s", errs.NewFieldsError("q", errMissingQuery))
+	}
+
+	return nil
+}
+
+// Hit represents a single item that matched the search. The item is the
+// same model the endpoint for its type returns.
+type Hit struct {
+	Type  string          `json:"type"`
+	ID    string          `json:"id"`
+	Title string          `json:"title"`
+	Score float64         `json:"score"`
+	Item  json.RawMessage `json:"item"`
+}
+
+// Result represents the hits for a search ranked from best to worst match.
+// Types the caller isn't authorized to search are listed in Skipped.
+type Result struct {
+	Query   string   `json:"query"`
+	Items   []Hit    `json:"items"`
+	Total   int      `json:"total"`
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// Encode implments the encoder interface.
+func (app Result) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
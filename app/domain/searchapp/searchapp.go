@@ -0,0 +1,177 @@
+// Package searchapp maintains the app layer api for searching across the
+// domains.
+package searchapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	eerrs "encore.dev/beta/errs"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+)
+
+var errMissingQuery = errors.New("a search query is required")
+
+// Set of values for the number of hits returned.
+const (
+	defaultLimit = 10
+	maxLimit     = 50
+)
+
+// SearchFunc searches a domain for the query and returns up to limit hits.
+// The score of the hits is set by the app.
+type SearchFunc func(ctx context.Context, q string, limit int) ([]Hit, error)
+
+// Source is a domain that can be searched.
+type Source struct {
+	Type   string
+	Search SearchFunc
+}
+
+// App manages the set of app layer api functions for searching.
+type App struct {
+	sources []Source
+}
+
+// NewApp constructs a search API for use. When hits from different sources
+// have the same score, they are ranked in the order of the sources.
+func NewApp(sources ...Source) *App {
+	return &App{
+		sources: sources,
+	}
+}
+
+// Query searches all the sources at the same time and returns the hits
+// ranked by how well they match. A source the caller isn't authorized to
+// use is skipped instead of failing the search, so each caller only sees
+// what the endpoint for the type would show them.
+func (a *App) Query(ctx context.Context, qp QueryParams) (Result, error) {
+	limit, err := parseLimit(qp.Limit)
+	if err != nil {
+		return Result{}, err
+	}
+
+	type response struct {
+		hits []Hit
+		err  error
+	}
+
+	responses := make([]response, len(a.sources))
+
+	var wg sync.WaitGroup
+	wg.Add(len(a.sources))
+
+	for i, src := range a.sources {
+		go func() {
+			defer wg.Done()
+
+			hits, err := src.Search(ctx, qp.Q, limit)
+			responses[i] = response{hits: hits, err: err}
+		}()
+	}
+
+	wg.Wait()
+
+	result := Result{
+		Query: qp.Q,
+		Items: []Hit{},
+	}
+
+	rank := make(map[string]int, len(a.sources))
+
+	for i, resp := range responses {
+		src := a.sources[i]
+		rank[src.Type] = i
+
+		if resp.err != nil {
+			switch code(resp.err) {
+			case errs.Unauthenticated, errs.PermissionDenied:
+				result.Skipped = append(result.Skipped, src.Type)
+				continue
+
+			// The query isn't valid for every type, like a name that's too
+			// short, which means nothing of that type can match.
+			case errs.InvalidArgument:
+				continue
+			}
+
+			return Result{}, errs.Newf(errs.Internal, "query: %s: %s", src.Type, resp.err)
+		}
+
+		for _, hit := range resp.hits {
+			hit.Type = src.Type
+			hit.Score = Score(qp.Q, hit.Title)
+			result.Items = append(result.Items, hit)
+		}
+	}
+
+	sort.SliceStable(result.Items, func(i, j int) bool {
+		a, b := result.Items[i], result.Items[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return rank[a.Type] < rank[b.Type]
+	})
+
+	if len(result.Items) > limit {
+		result.Items = result.Items[:limit]
+	}
+	result.Total = len(result.Items)
+
+	return result, nil
+}
+
+// =============================================================================
+
+// Score returns how well the title matches the query between 0 and 1. An
+// exact match scores highest, then a match at the start of the title, then
+// the start of a word and then anywhere. A title that doesn't contain the
+// query, since the source matched on another field, scores lowest.
+func Score(q string, title string) float64 {
+	q = strings.ToLower(strings.TrimSpace(q))
+	title = strings.ToLower(title)
+
+	switch {
+	case title == q:
+		return 1
+	case strings.HasPrefix(title, q):
+		return 0.75
+	case strings.Contains(title, " "+q):
+		return 0.5
+	case strings.Contains(title, q):
+		return 0.25
+	default:
+		return 0.1
+	}
+}
+
+func parseLimit(value string) (int, error) {
+	if value == "" {
+		return defaultLimit, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errs.NewFieldsError("limit", err)
+	}
+
+	if limit <= 0 || limit > maxLimit {
+		return 0, errs.NewFieldsError("limit", fmt.Errorf("limit must be between 1 and %d", maxLimit))
+	}
+
+	return limit, nil
+}
+
+func code(err error) eerrs.ErrCode {
+	var eerr *eerrs.Error
+	if !errors.As(err, &eerr) {
+		return errs.Unknown
+	}
+
+	return eerr.Code
+}
@@ -60,7 +60,7 @@ func (app *NewTran) Decode(data []byte) error {
 type NewUser struct {
 	Name            string   `json:"name" validate:"required"`
 	Email           string   `json:"email" validate:"required,email"`
-	Roles           []string `json:"roles" validate:"required"`
+	Roles           []string `json:"roles" validate:"required,dive,enum=role"`
 	Department      string   `json:"department"`
 	Password        string   `json:"password" validate:"required"`
 	PasswordConfirm string   `json:"passwordConfirm" validate:"eqfield=Password"`
@@ -69,7 +69,7 @@ type NewUser struct {
 // Validate checks the data in the model is considered clean.
 func (app NewUser) Validate() error {
 	if err := errs.Check(app); err != nil {
-		return err
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
 	}
 
 	return nil
@@ -118,7 +118,7 @@ type NewProduct struct {
 // Validate checks the data in the model is considered clean.
 func (app NewProduct) Validate() error {
 	if err := errs.Check(app); err != nil {
-		return err
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
 	}
 
 	return nil
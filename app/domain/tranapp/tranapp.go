@@ -3,7 +3,7 @@ package tranapp
 
 import (
 	"context"
-	"errors"
+	"fmt"
 
 	"github.com/ardanlabs/encore/app/sdk/errs"
 	"github.com/ardanlabs/encore/app/sdk/mid"
@@ -70,10 +70,7 @@ func (a *App) Create(ctx context.Context, nt NewTran) (Product, error) {
 
 	usr, err := a.userBus.Create(ctx, nu)
 	if err != nil {
-		if errors.Is(err, userbus.ErrUniqueEmail) {
-			return Product{}, errs.New(errs.Aborted, userbus.ErrUniqueEmail)
-		}
-		return Product{}, errs.Newf(errs.Internal, "create: usr[%+v]: %s", usr, err)
+		return Product{}, fmt.Errorf("create: %w", err)
 	}
 
 	np.UserID = usr.ID
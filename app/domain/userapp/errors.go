@@ -0,0 +1,21 @@
+package userapp
+
+import (
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/tokenbus"
+	"github.com/ardanlabs/encore/business/domain/userbus"
+)
+
+// init registers the user sentinel errors so they reach clients with the
+// right code.
+func init() {
+	errs.Register(userbus.ErrNotFound, errs.Class{Code: errs.NotFound, AppCode: errs.AppUserNotFound})
+	errs.Register(userbus.ErrUniqueEmail, errs.Class{Code: errs.Aborted, AppCode: errs.AppUserEmailTaken})
+	errs.Register(userbus.ErrModified, errs.Class{Code: errs.FailedPrecondition, AppCode: errs.AppUserModified})
+	errs.Register(userbus.ErrAuthenticationFailure, errs.Class{Code: errs.Unauthenticated, AppCode: errs.AppUserAuthenticationFailed})
+	errs.Register(userbus.ErrResetNotFound, errs.Class{Code: errs.Unauthenticated, AppCode: errs.AppUserPasswordResetNotFound})
+	errs.Register(userbus.ErrResetExpired, errs.Class{Code: errs.Unauthenticated, AppCode: errs.AppUserPasswordResetExpired})
+	errs.Register(tokenbus.ErrNotFound, errs.Class{Code: errs.Unauthenticated, AppCode: errs.AppTokenNotFound})
+	errs.Register(tokenbus.ErrExpired, errs.Class{Code: errs.Unauthenticated, AppCode: errs.AppTokenExpired})
+	errs.Register(tokenbus.ErrRevoked, errs.Class{Code: errs.Unauthenticated, AppCode: errs.AppTokenRevoked})
+}
@@ -1,55 +1,24 @@
 package userapp
 
 import (
-	"net/mail"
-	"time"
-
 	"github.com/ardanlabs/encore/app/sdk/errs"
 	"github.com/ardanlabs/encore/business/domain/userbus"
-	"github.com/google/uuid"
+	"github.com/ardanlabs/encore/business/sdk/queryfilter"
 )
 
 func parseFilter(qp QueryParams) (userbus.QueryFilter, error) {
-	var filter userbus.QueryFilter
-
-	if qp.ID != "" {
-		id, err := uuid.Parse(qp.ID)
-		if err != nil {
-			return userbus.QueryFilter{}, errs.NewFieldsError("user_id", err)
-		}
-		filter.ID = &id
-	}
-
-	if qp.Name != "" {
-		name, err := userbus.ParseName(qp.Name)
-		if err != nil {
-			return userbus.QueryFilter{}, errs.NewFieldsError("name", err)
-		}
-		filter.Name = &name
-	}
-
-	if qp.Email != "" {
-		addr, err := mail.ParseAddress(qp.Email)
-		if err != nil {
-			return userbus.QueryFilter{}, errs.NewFieldsError("email", err)
-		}
-		filter.Email = addr
-	}
-
-	if qp.StartCreatedDate != "" {
-		t, err := time.Parse(time.RFC3339, qp.StartCreatedDate)
-		if err != nil {
-			return userbus.QueryFilter{}, errs.NewFieldsError("start_created_date", err)
-		}
-		filter.StartCreatedDate = &t
+	p := queryfilter.New()
+
+	filter := userbus.QueryFilter{
+		ID:               queryfilter.Value(p, "user_id", qp.ID, queryfilter.UUID),
+		Name:             queryfilter.Value(p, "name", qp.Name, userbus.ParseName),
+		Email:            queryfilter.Value(p, "email", qp.Email, queryfilter.Email),
+		StartCreatedDate: queryfilter.Value(p, "start_created_date", qp.StartCreatedDate, queryfilter.Time),
+		EndCreatedDate:   queryfilter.Value(p, "end_created_date", qp.EndCreatedDate, queryfilter.Time),
 	}
 
-	if qp.EndCreatedDate != "" {
-		t, err := time.Parse(time.RFC3339, qp.EndCreatedDate)
-		if err != nil {
-			return userbus.QueryFilter{}, errs.NewFieldsError("end_created_date", err)
-		}
-		filter.EndCreatedDate = &t
+	if err := p.Err(); err != nil {
+		return userbus.QueryFilter{}, errs.NewFilterErrors(err)
 	}
 
 	return filter, nil
@@ -3,9 +3,14 @@ package userapp
 import (
 	"fmt"
 	"net/mail"
+	"net/url"
 	"time"
 
 	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/etag"
+	"github.com/ardanlabs/encore/app/sdk/links"
+	"github.com/ardanlabs/encore/app/sdk/patch"
+	"github.com/ardanlabs/encore/business/domain/tokenbus"
 	"github.com/ardanlabs/encore/business/domain/userbus"
 )
 
@@ -13,6 +18,9 @@ import (
 type QueryParams struct {
 	Page             string
 	Rows             string
+	Cursor           string
+	Limit            string
+	Fields           string
 	OrderBy          string
 	ID               string
 	Name             string
@@ -25,18 +33,20 @@ type QueryParams struct {
 
 // User represents information about an individual user.
 type User struct {
-	ID           string   `json:"id"`
-	Name         string   `json:"name"`
-	Email        string   `json:"email"`
-	Roles        []string `json:"roles"`
-	PasswordHash []byte   `json:"-"`
-	Department   string   `json:"department"`
-	Enabled      bool     `json:"enabled"`
-	DateCreated  string   `json:"dateCreated"`
-	DateUpdated  string   `json:"dateUpdated"`
-}
-
-func toAppUser(bus userbus.User) User {
+	ID           string      `json:"id"`
+	Name         string      `json:"name"`
+	Email        string      `json:"email"`
+	Roles        []string    `json:"roles"`
+	PasswordHash []byte      `json:"-"`
+	Department   string      `json:"department"`
+	Enabled      bool        `json:"enabled"`
+	DateCreated  string      `json:"dateCreated"`
+	DateUpdated  string      `json:"dateUpdated"`
+	ETag         string      `json:"etag,omitempty" header:"ETag"`
+	Links        links.Links `json:"links,omitempty"`
+}
+
+func toAppUser(lb *links.Builder, bus userbus.User) User {
 	roles := make([]string, len(bus.Roles))
 	for i, role := range bus.Roles {
 		roles[i] = role.String()
@@ -52,13 +62,26 @@ func toAppUser(bus userbus.User) User {
 		Enabled:      bus.Enabled,
 		DateCreated:  bus.DateCreated.Format(time.RFC3339),
 		DateUpdated:  bus.DateUpdated.Format(time.RFC3339),
+		Links: links.Links{
+			links.Self:    lb.Link("/v1/users/"+bus.ID.String(), nil),
+			links.Related: lb.Link("/v1/homes", url.Values{"user_id": {bus.ID.String()}}),
+		},
 	}
 }
 
-func toAppUsers(users []userbus.User) []User {
+// toAppUserWithETag converts the user and sets the entity tag clients
+// send back with an If-Match header when changing the user.
+func toAppUserWithETag(lb *links.Builder, bus userbus.User) User {
+	app := toAppUser(lb, bus)
+	app.ETag = etag.New(bus.DateUpdated)
+
+	return app
+}
+
+func toAppUsers(lb *links.Builder, users []userbus.User) []User {
 	app := make([]User, len(users))
 	for i, usr := range users {
-		app[i] = toAppUser(usr)
+		app[i] = toAppUser(lb, usr)
 	}
 
 	return app
@@ -70,7 +93,7 @@ func toAppUsers(users []userbus.User) []User {
 type NewUser struct {
 	Name            string   `json:"name" validate:"required"`
 	Email           string   `json:"email" validate:"required,email"`
-	Roles           []string `json:"roles" validate:"required"`
+	Roles           []string `json:"roles" validate:"required,dive,enum=role"`
 	Department      string   `json:"department"`
 	Password        string   `json:"password" validate:"required"`
 	PasswordConfirm string   `json:"passwordConfirm" validate:"eqfield=Password"`
@@ -79,7 +102,7 @@ type NewUser struct {
 // Validate checks the data in the model is considered clean.
 func (app NewUser) Validate() error {
 	if err := errs.Check(app); err != nil {
-		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
 	}
 
 	return nil
@@ -120,13 +143,14 @@ func toBusNewUser(app NewUser) (userbus.NewUser, error) {
 
 // UpdateUserRole defines the data needed to update a user role.
 type UpdateUserRole struct {
-	Roles []string `json:"roles" validate:"required"`
+	Roles   []string `json:"roles" validate:"required,dive,enum=role"`
+	IfMatch string   `header:"If-Match"`
 }
 
 // Validate checks the data in the model is considered clean.
 func (app UpdateUserRole) Validate() error {
 	if err := errs.Check(app); err != nil {
-		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
 	}
 
 	return nil
@@ -162,12 +186,13 @@ type UpdateUser struct {
 	Password        *string `json:"password"`
 	PasswordConfirm *string `json:"passwordConfirm" validate:"omitempty,eqfield=Password"`
 	Enabled         *bool   `json:"enabled"`
+	IfMatch         string  `header:"If-Match"`
 }
 
 // Validate checks the data in the model is considered clean.
 func (app UpdateUser) Validate() error {
 	if err := errs.Check(app); err != nil {
-		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
 	}
 
 	return nil
@@ -202,3 +227,122 @@ func toBusUpdateUser(app UpdateUser) (userbus.UpdateUser, error) {
 
 	return bus, nil
 }
+
+// =============================================================================
+
+// PatchUser defines the data in a JSON merge patch for a user. Fields that
+// are missing are left alone and only department can be cleared with a null.
+type PatchUser struct {
+	Name            patch.Field[string] `json:"name"`
+	Email           patch.Field[string] `json:"email"`
+	Department      patch.Field[string] `json:"department"`
+	Password        patch.Field[string] `json:"password"`
+	PasswordConfirm patch.Field[string] `json:"passwordConfirm"`
+	Enabled         patch.Field[bool]   `json:"enabled"`
+	IfMatch         string              `header:"If-Match"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app PatchUser) Validate() error {
+	uu, err := toUpdateUser(app)
+	if err != nil {
+		return err
+	}
+
+	return uu.Validate()
+}
+
+func toUpdateUser(app PatchUser) (UpdateUser, error) {
+	uu := UpdateUser{
+		Department: app.Department.Optional(),
+		IfMatch:    app.IfMatch,
+	}
+
+	var err error
+	if uu.Name, err = app.Name.Required(); err != nil {
+		return UpdateUser{}, errs.NewFieldsError("name", err)
+	}
+
+	if uu.Email, err = app.Email.Required(); err != nil {
+		return UpdateUser{}, errs.NewFieldsError("email", err)
+	}
+
+	if uu.Password, err = app.Password.Required(); err != nil {
+		return UpdateUser{}, errs.NewFieldsError("password", err)
+	}
+
+	if uu.PasswordConfirm, err = app.PasswordConfirm.Required(); err != nil {
+		return UpdateUser{}, errs.NewFieldsError("passwordConfirm", err)
+	}
+
+	if uu.Enabled, err = app.Enabled.Required(); err != nil {
+		return UpdateUser{}, errs.NewFieldsError("enabled", err)
+	}
+
+	return uu, nil
+}
+
+// =============================================================================
+
+// Token represents a token issued to a user along with the refresh token
+// that can be traded for the next one.
+type Token struct {
+	Token            string `json:"token"`
+	RefreshToken     string `json:"refreshToken"`
+	RefreshExpiresAt string `json:"refreshExpiresAt"`
+}
+
+func toAppToken(tkn string, rt tokenbus.RefreshToken) Token {
+	return Token{
+		Token:            tkn,
+		RefreshToken:     rt.Token,
+		RefreshExpiresAt: rt.DateExpires.Format(time.RFC3339),
+	}
+}
+
+// RefreshToken defines the data needed to refresh or revoke a token.
+type RefreshToken struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app RefreshToken) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// PasswordResetRequest defines the data needed to request a password reset.
+type PasswordResetRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app PasswordResetRequest) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
+	}
+
+	return nil
+}
+
+// PasswordReset defines the data needed to reset a password with the token
+// the user was emailed.
+type PasswordReset struct {
+	Token           string `json:"token" validate:"required"`
+	Password        string `json:"password" validate:"required"`
+	PasswordConfirm string `json:"passwordConfirm" validate:"eqfield=Password"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app PasswordReset) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
+	}
+
+	return nil
+}
@@ -14,3 +14,10 @@ var orderByFields = map[string]string{
 	"roles":   userbus.OrderByRoles,
 	"enabled": userbus.OrderByEnabled,
 }
+
+// ValidateOrderBy reports if the order is one a query would accept, so it
+// can be checked before it's stored for later use.
+func ValidateOrderBy(orderBy string) error {
+	_, err := order.Parse(orderByFields, orderBy, defaultOrderBy)
+	return err
+}
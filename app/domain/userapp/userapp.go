@@ -4,34 +4,66 @@ package userapp
 import (
 	"context"
 	"errors"
+	"fmt"
+	"iter"
+	"net/mail"
+	"net/url"
+	"slices"
+	"time"
 
 	"github.com/ardanlabs/encore/app/sdk/auth"
 	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/etag"
+	"github.com/ardanlabs/encore/app/sdk/fields"
+	"github.com/ardanlabs/encore/app/sdk/links"
 	"github.com/ardanlabs/encore/app/sdk/mid"
+	"github.com/ardanlabs/encore/app/sdk/prefs"
 	"github.com/ardanlabs/encore/app/sdk/query"
+	"github.com/ardanlabs/encore/business/domain/apikeybus"
+	"github.com/ardanlabs/encore/business/domain/tokenbus"
 	"github.com/ardanlabs/encore/business/domain/userbus"
 	"github.com/ardanlabs/encore/business/sdk/order"
 	"github.com/ardanlabs/encore/business/sdk/page"
+	"github.com/ardanlabs/encore/foundation/email"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
 )
 
 // App manages the set of app layer api functions for the user domain.
 type App struct {
-	userBus *userbus.Business
-	auth    *auth.Auth
+	log       *logger.Logger
+	userBus   *userbus.Business
+	tokenBus  *tokenbus.Business
+	apiKeyBus *apikeybus.Business
+	auth      *auth.Auth
+	sender    email.Sender
+	resetURL  string
+	links     *links.Builder
 }
 
 // NewApp constructs a user app API for use.
-func NewApp(userBus *userbus.Business) *App {
+func NewApp(userBus *userbus.Business, lb *links.Builder) *App {
 	return &App{
 		userBus: userBus,
+		links:   lb,
 	}
 }
 
-// NewAppWithAuth constructs a user app API for use with auth support.
-func NewAppWithAuth(userBus *userbus.Business, ath *auth.Auth) *App {
+// NewAppWithAuth constructs a user app API for use with auth support. The
+// refresh tokens are issued, rotated and revoked with the token business,
+// and the API keys of a user are removed with the API key business when the
+// user is logged out everywhere. Password reset links are emailed with the
+// sender and point to the reset url with the token added to the query. The
+// emails that can't be sent are logged.
+func NewAppWithAuth(log *logger.Logger, userBus *userbus.Business, tokenBus *tokenbus.Business, apiKeyBus *apikeybus.Business, ath *auth.Auth, sender email.Sender, resetURL string) *App {
 	return &App{
-		auth:    ath,
-		userBus: userBus,
+		log:       log,
+		auth:      ath,
+		userBus:   userBus,
+		tokenBus:  tokenBus,
+		apiKeyBus: apiKeyBus,
+		sender:    sender,
+		resetURL:  resetURL,
 	}
 }
 
@@ -44,13 +76,10 @@ func (a *App) Create(ctx context.Context, app NewUser) (User, error) {
 
 	usr, err := a.userBus.Create(ctx, nc)
 	if err != nil {
-		if errors.Is(err, userbus.ErrUniqueEmail) {
-			return User{}, errs.New(errs.Aborted, userbus.ErrUniqueEmail)
-		}
-		return User{}, errs.Newf(errs.Internal, "create: usr[%+v]: %s", usr, err)
+		return User{}, fmt.Errorf("create: %w", err)
 	}
 
-	return toAppUser(usr), nil
+	return toAppUser(a.links, usr), nil
 }
 
 // Update updates an existing user.
@@ -65,12 +94,26 @@ func (a *App) Update(ctx context.Context, app UpdateUser) (User, error) {
 		return User{}, errs.Newf(errs.Internal, "user missing in context: %s", err)
 	}
 
+	if err := etag.Check(app.IfMatch, usr.DateUpdated); err != nil {
+		return User{}, err
+	}
+
 	updUsr, err := a.userBus.Update(ctx, usr, uu)
 	if err != nil {
-		return User{}, errs.Newf(errs.Internal, "update: userID[%s] uu[%+v]: %s", usr.ID, uu, err)
+		return User{}, fmt.Errorf("update: userID[%s] uu[%+v]: %w", usr.ID, uu, err)
 	}
 
-	return toAppUser(updUsr), nil
+	return toAppUserWithETag(a.links, updUsr), nil
+}
+
+// Patch applies a JSON merge patch to an existing user.
+func (a *App) Patch(ctx context.Context, app PatchUser) (User, error) {
+	uu, err := toUpdateUser(app)
+	if err != nil {
+		return User{}, err
+	}
+
+	return a.Update(ctx, uu)
 }
 
 // UpdateRole updates an existing user's role.
@@ -85,23 +128,31 @@ func (a *App) UpdateRole(ctx context.Context, app UpdateUserRole) (User, error)
 		return User{}, errs.Newf(errs.Internal, "user missing in context: %s", err)
 	}
 
+	if err := etag.Check(app.IfMatch, usr.DateUpdated); err != nil {
+		return User{}, err
+	}
+
 	updUsr, err := a.userBus.Update(ctx, usr, uu)
 	if err != nil {
-		return User{}, errs.Newf(errs.Internal, "updaterole: userID[%s] uu[%+v]: %s", usr.ID, uu, err)
+		return User{}, fmt.Errorf("updaterole: userID[%s] uu[%+v]: %w", usr.ID, uu, err)
 	}
 
-	return toAppUser(updUsr), nil
+	return toAppUserWithETag(a.links, updUsr), nil
 }
 
 // Delete removes a user from the system.
-func (a *App) Delete(ctx context.Context) error {
+func (a *App) Delete(ctx context.Context, pc etag.Precondition) error {
 	usr, err := mid.GetUser(ctx)
 	if err != nil {
 		return errs.Newf(errs.Internal, "userID missing in context: %s", err)
 	}
 
+	if err := etag.Check(pc.IfMatch, usr.DateUpdated); err != nil {
+		return err
+	}
+
 	if err := a.userBus.Delete(ctx, usr); err != nil {
-		return errs.Newf(errs.Internal, "delete: userID[%s]: %s", usr.ID, err)
+		return fmt.Errorf("delete: userID[%s]: %w", usr.ID, err)
 	}
 
 	return nil
@@ -109,6 +160,64 @@ func (a *App) Delete(ctx context.Context) error {
 
 // Query returns a list of users with paging.
 func (a *App) Query(ctx context.Context, qp QueryParams) (query.Result[User], error) {
+	fs, err := fields.Parse[User](qp.Fields)
+	if err != nil {
+		return query.Result[User]{}, errs.NewFieldsError("fields", err)
+	}
+
+	var result query.Result[User]
+
+	switch {
+	case qp.Cursor != "" || qp.Limit != "":
+		result, err = a.queryByKeyset(ctx, qp)
+	default:
+		result, err = a.queryByPage(ctx, qp)
+	}
+
+	if err != nil {
+		return query.Result[User]{}, err
+	}
+
+	result.Fields = fs
+	result = result.WithLinks(a.links, "/v1/users", qp)
+
+	return result, nil
+}
+
+// QueryStream returns every user that matches the query one at a time
+// instead of a page. The query is checked before the sequence is returned
+// so an invalid query is reported before anything is written.
+func (a *App) QueryStream(ctx context.Context, qp QueryParams) (iter.Seq2[User, error], error) {
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return nil, err
+	}
+
+	orderBy, err := order.Parse(orderByFields, qp.OrderBy, defaultOrderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	seq := func(yield func(User, error) bool) {
+		for usr, err := range a.userBus.QueryStream(ctx, filter, orderBy) {
+			if err != nil {
+				yield(User{}, errs.Newf(errs.Internal, "querystream: %s", err))
+				return
+			}
+
+			if !yield(toAppUser(a.links, usr), nil) {
+				return
+			}
+		}
+	}
+
+	return seq, nil
+}
+
+// queryByPage returns a list of users using the page and rows.
+func (a *App) queryByPage(ctx context.Context, qp QueryParams) (query.Result[User], error) {
+	qp.Rows, qp.OrderBy = prefs.Apply(ctx, prefs.Users, qp.Rows, qp.OrderBy)
+
 	page, err := page.Parse(qp.Page, qp.Rows)
 	if err != nil {
 		return query.Result[User]{}, err
@@ -134,7 +243,41 @@ func (a *App) Query(ctx context.Context, qp QueryParams) (query.Result[User], er
 		return query.Result[User]{}, errs.Newf(errs.Internal, "count: %s", err)
 	}
 
-	return query.NewResult(toAppUsers(usrs), total, page), nil
+	return query.NewResult(toAppUsers(a.links, usrs), total, page), nil
+}
+
+// queryByKeyset returns a list of users using the cursor and limit. Keyset
+// paging always orders by the user id.
+func (a *App) queryByKeyset(ctx context.Context, qp QueryParams) (query.Result[User], error) {
+	if qp.OrderBy != "" {
+		return query.Result[User]{}, errs.Newf(errs.InvalidArgument, "orderBy can't be used with a cursor or limit")
+	}
+
+	keyset, err := page.ParseKeyset(qp.Cursor, qp.Limit)
+	if err != nil {
+		return query.Result[User]{}, errs.New(errs.InvalidArgument, err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return query.Result[User]{}, err
+	}
+
+	usrs, err := a.userBus.QueryByKeyset(ctx, filter, keyset)
+	if err != nil {
+		return query.Result[User]{}, errs.Newf(errs.Internal, "querybykeyset: %s", err)
+	}
+
+	total, err := a.userBus.Count(ctx, filter)
+	if err != nil {
+		return query.Result[User]{}, errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	usrs, next, prev := page.Cursors(keyset, usrs, func(usr userbus.User) string {
+		return usr.ID.String()
+	})
+
+	return query.NewKeysetResult(toAppUsers(a.links, usrs), total, keyset, next, prev), nil
 }
 
 // QueryByID returns a user by its Ia.
@@ -144,5 +287,227 @@ func (a *App) QueryByID(ctx context.Context) (User, error) {
 		return User{}, errs.Newf(errs.Internal, "querybyid: %s", err)
 	}
 
-	return toAppUser(usr), nil
+	return toAppUserWithETag(a.links, usr), nil
+}
+
+// =============================================================================
+
+// Token issues a token signed with the kid for the claims of the user along
+// with a refresh token the user can trade for a new token later. The token
+// carries the family of the refresh token so logging out ends both. The
+// refresh token is bound to the roles of the claims, so a caller scoped to
+// fewer roles than the user, like with an API key, can't refresh into more.
+func (a *App) Token(ctx context.Context, kid string, claims auth.Claims) (Token, error) {
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return Token{}, errs.Newf(errs.Unauthenticated, "parsing subject: %s", err)
+	}
+
+	roles, err := userbus.ParseRoles(claims.Roles)
+	if err != nil {
+		return Token{}, errs.Newf(errs.Unauthenticated, "parsing roles: %s", err)
+	}
+
+	rt, err := a.tokenBus.Issue(ctx, userID, roles)
+	if err != nil {
+		return Token{}, errs.Newf(errs.Internal, "issue: %s", err)
+	}
+
+	claims.FamilyID = rt.FamilyID.String()
+
+	tkn, err := a.auth.GenerateToken(kid, claims)
+	if err != nil {
+		return Token{}, errs.New(errs.Internal, err)
+	}
+
+	return toAppToken(tkn, rt), nil
+}
+
+// Refresh rotates the refresh token and issues a new token for the user it
+// belongs to, signed with the active kid. The user has to still be enabled.
+// The token grants the roles the refresh token is bound to that the user
+// still holds.
+func (a *App) Refresh(ctx context.Context, app RefreshToken) (Token, error) {
+	rt, err := a.tokenBus.Rotate(ctx, app.RefreshToken)
+	if err != nil {
+		return Token{}, fmt.Errorf("rotate: %w", err)
+	}
+
+	usr, err := a.userBus.QueryByID(ctx, rt.UserID)
+	if err != nil {
+		return Token{}, errs.Newf(errs.Unauthenticated, "query user: userID[%s]: %s", rt.UserID, err)
+	}
+
+	if !usr.Enabled {
+		return Token{}, errs.Newf(errs.Unauthenticated, "user disabled: userID[%s]", usr.ID)
+	}
+
+	claims := a.auth.NewScopedClaims(usr.ID, heldRoles(rt.Roles, usr.Roles))
+	claims.FamilyID = rt.FamilyID.String()
+
+	tkn, err := a.auth.GenerateToken(a.auth.ActiveKID(), claims)
+	if err != nil {
+		return Token{}, errs.New(errs.Internal, err)
+	}
+
+	return toAppToken(tkn, rt), nil
+}
+
+// heldRoles returns the roles that were granted that are still held.
+func heldRoles(granted []userbus.Role, held []userbus.Role) []userbus.Role {
+	var roles []userbus.Role
+	for _, role := range granted {
+		if slices.ContainsFunc(held, role.Equal) {
+			roles = append(roles, role)
+		}
+	}
+
+	return roles
+}
+
+// Revoke revokes the refresh token and the tokens rotated from the same
+// login, so none of them can be refreshed anymore.
+func (a *App) Revoke(ctx context.Context, app RefreshToken) error {
+	if err := a.tokenBus.Revoke(ctx, app.RefreshToken); err != nil {
+		return fmt.Errorf("revoke: %w", err)
+	}
+
+	return nil
+}
+
+// Logout revokes the token the claims were issued with, so it can't be used
+// again even though it hasn't expired, along with the refresh tokens of the
+// login it came from.
+func (a *App) Logout(ctx context.Context, claims auth.Claims) error {
+	if err := a.auth.RevokeToken(ctx, claims); err != nil {
+		return errs.Newf(errs.Internal, "revoketoken: %s", err)
+	}
+
+	if claims.FamilyID == "" {
+		return nil
+	}
+
+	familyID, err := uuid.Parse(claims.FamilyID)
+	if err != nil {
+		return errs.Newf(errs.InvalidArgument, "parse familyID: %s", err)
+	}
+
+	if err := a.tokenBus.RevokeFamily(ctx, familyID); err != nil {
+		return errs.Newf(errs.Internal, "revokefamily: familyID[%s]: %s", familyID, err)
+	}
+
+	return nil
+}
+
+// ForceLogout revokes every token and refresh token issued to the user so
+// far and removes the API keys of the user, like when the account has been
+// compromised.
+func (a *App) ForceLogout(ctx context.Context, userID string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return errs.Newf(errs.InvalidArgument, "parse userID: %s", err)
+	}
+
+	if _, err := a.userBus.QueryByID(ctx, id); err != nil {
+		return fmt.Errorf("querybyid: userID[%s]: %w", id, err)
+	}
+
+	if err := a.auth.RevokeUser(ctx, id); err != nil {
+		return errs.Newf(errs.Internal, "revokeuser: userID[%s]: %s", id, err)
+	}
+
+	if err := a.tokenBus.RevokeUser(ctx, id); err != nil {
+		return errs.Newf(errs.Internal, "revokeuser: userID[%s]: %s", id, err)
+	}
+
+	if err := a.apiKeyBus.DeleteUser(ctx, id); err != nil {
+		return errs.Newf(errs.Internal, "deleteuser: userID[%s]: %s", id, err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredTokens removes the refresh tokens and the revocations of the
+// tokens that can no longer be used.
+func (a *App) DeleteExpiredTokens(ctx context.Context) error {
+	if _, err := a.tokenBus.DeleteExpired(ctx); err != nil {
+		return errs.Newf(errs.Internal, "deleteexpired: %s", err)
+	}
+
+	if _, err := a.auth.DeleteExpiredRevocations(ctx); err != nil {
+		return errs.Newf(errs.Internal, "deleteexpiredrevocations: %s", err)
+	}
+
+	if _, err := a.userBus.DeleteExpiredPasswordResets(ctx); err != nil {
+		return errs.Newf(errs.Internal, "deleteexpiredpasswordresets: %s", err)
+	}
+
+	return nil
+}
+
+// RequestPasswordReset emails the user a link to reset the password with.
+// Nothing is returned when the email doesn't belong to a user, so the call
+// can't be used to learn which emails are registered.
+func (a *App) RequestPasswordReset(ctx context.Context, app PasswordResetRequest) error {
+	addr, err := mail.ParseAddress(app.Email)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, pr, err := a.userBus.RequestPasswordReset(ctx, *addr)
+	if err != nil {
+		if errors.Is(err, userbus.ErrNotFound) {
+			return nil
+		}
+		return errs.Newf(errs.Internal, "requestpasswordreset: %s", err)
+	}
+
+	link, err := url.Parse(a.resetURL)
+	if err != nil {
+		return errs.Newf(errs.Internal, "parse reset url: %s", err)
+	}
+
+	q := link.Query()
+	q.Set("token", pr.Token)
+	link.RawQuery = q.Encode()
+
+	msg := email.Message{
+		To:      usr.Email,
+		Subject: "Reset your password",
+		Body:    fmt.Sprintf("Use the link below to reset your password. It can be used once and expires at %s.\n\n%s\n", pr.DateExpires.Format(time.RFC1123), link),
+	}
+
+	// The email is sent in the background, so the response for a registered
+	// email doesn't take longer or fail when the sender does, which would
+	// tell the caller the email is registered.
+	go a.send(context.WithoutCancel(ctx), msg)
+
+	return nil
+}
+
+// send sends the email and logs when it can't be sent.
+func (a *App) send(ctx context.Context, msg email.Message) {
+	if err := a.sender.Send(ctx, msg); err != nil {
+		a.log.Error(ctx, "send email", "subject", msg.Subject, "ERROR", err)
+	}
+}
+
+// ResetPassword sets the password of the user the reset token was issued to.
+// Every token and refresh token issued to the user so far is revoked, since
+// whoever had the old password could have them.
+func (a *App) ResetPassword(ctx context.Context, app PasswordReset) error {
+	usr, err := a.userBus.ResetPassword(ctx, app.Token, app.Password)
+	if err != nil {
+		return fmt.Errorf("resetpassword: %w", err)
+	}
+
+	if err := a.auth.RevokeUser(ctx, usr.ID); err != nil {
+		return errs.Newf(errs.Internal, "revokeuser: userID[%s]: %s", usr.ID, err)
+	}
+
+	if err := a.tokenBus.RevokeUser(ctx, usr.ID); err != nil {
+		return errs.Newf(errs.Internal, "revokeuser: userID[%s]: %s", usr.ID, err)
+	}
+
+	return nil
 }
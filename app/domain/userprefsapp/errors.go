@@ -0,0 +1,12 @@
+package userprefsapp
+
+import (
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/userprefsbus"
+)
+
+// init registers the user preference sentinel errors so they reach clients with the
+// right code.
+func init() {
+	errs.Register(userprefsbus.ErrNotFound, errs.Class{Code: errs.NotFound, AppCode: errs.AppUserPrefsNotFound})
+}
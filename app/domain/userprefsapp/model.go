@@ -0,0 +1,79 @@
+package userprefsapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/userprefsbus"
+)
+
+// Preference represents how the user wants the items of an entity listed
+// when a query doesn't say.
+type Preference struct {
+	Entity      string `json:"entity"`
+	Rows        int    `json:"rows,omitempty"`
+	OrderBy     string `json:"orderBy,omitempty"`
+	DateUpdated string `json:"dateUpdated"`
+}
+
+// Encode implments the encoder interface.
+func (app Preference) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppPreference(pref userprefsbus.Preference) Preference {
+	return Preference{
+		Entity:      pref.Entity,
+		Rows:        pref.Rows,
+		OrderBy:     pref.OrderBy,
+		DateUpdated: pref.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+// Preferences represents every preference the user has stored.
+type Preferences struct {
+	Items []Preference `json:"items"`
+}
+
+// Encode implments the encoder interface.
+func (app Preferences) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppPreferences(prefs []userprefsbus.Preference) Preferences {
+	items := make([]Preference, len(prefs))
+	for i, pref := range prefs {
+		items[i] = toAppPreference(pref)
+	}
+
+	return Preferences{
+		Items: items,
+	}
+}
+
+// =============================================================================
+
+// SetPreference defines the data needed to store a preference. A field left
+// empty uses the app default.
+type SetPreference struct {
+	Rows    int    `json:"rows" validate:"omitempty,min=1,max=100"`
+	OrderBy string `json:"orderBy"`
+}
+
+// Decode implments the decoder interface.
+func (app *SetPreference) Decode(data []byte) error {
+	return json.Unmarshal(data, &app)
+}
+
+// Validate checks if the data in the model is considered clean.
+func (app SetPreference) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
+	}
+
+	return nil
+}
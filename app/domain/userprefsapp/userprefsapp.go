@@ -0,0 +1,108 @@
+// Package userprefsapp maintains the app layer api for the list preferences
+// a user has stored.
+package userprefsapp
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/mid"
+	"github.com/ardanlabs/encore/business/domain/userprefsbus"
+)
+
+// OrderByFunc reports if the order is one the entity's queries accept.
+type OrderByFunc func(orderBy string) error
+
+// App manages the set of app layer api functions for the preference domain.
+type App struct {
+	userPrefsBus *userprefsbus.Business
+	entities     map[string]OrderByFunc
+}
+
+// NewApp constructs a preference app API for use. The entities are the ones
+// a user can store preferences for.
+func NewApp(userPrefsBus *userprefsbus.Business, entities map[string]OrderByFunc) *App {
+	return &App{
+		userPrefsBus: userPrefsBus,
+		entities:     entities,
+	}
+}
+
+// Query returns the preferences the user has stored.
+func (a *App) Query(ctx context.Context) (Preferences, error) {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return Preferences{}, errs.New(errs.Unauthenticated, err)
+	}
+
+	prefs, err := a.userPrefsBus.QueryByUserID(ctx, userID)
+	if err != nil {
+		return Preferences{}, errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	return toAppPreferences(prefs), nil
+}
+
+// Set stores the user's preference for the entity.
+func (a *App) Set(ctx context.Context, entity string, app SetPreference) (Preference, error) {
+	validOrderBy, exists := a.entities[entity]
+	if !exists {
+		return Preference{}, errs.Newf(errs.InvalidArgument, "unknown entity %q, supported: %s", entity, a.supported())
+	}
+
+	if app.OrderBy != "" {
+		if err := validOrderBy(app.OrderBy); err != nil {
+			return Preference{}, errs.NewFieldsError("orderBy", err)
+		}
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return Preference{}, errs.New(errs.Unauthenticated, err)
+	}
+
+	sp := userprefsbus.SetPreference{
+		UserID:  userID,
+		Entity:  entity,
+		Rows:    app.Rows,
+		OrderBy: app.OrderBy,
+	}
+
+	pref, err := a.userPrefsBus.Set(ctx, sp)
+	if err != nil {
+		return Preference{}, errs.Newf(errs.Internal, "set: %s", err)
+	}
+
+	return toAppPreference(pref), nil
+}
+
+// Delete removes the user's preference for the entity so the app defaults
+// are used again.
+func (a *App) Delete(ctx context.Context, entity string) error {
+	if _, exists := a.entities[entity]; !exists {
+		return errs.Newf(errs.InvalidArgument, "unknown entity %q, supported: %s", entity, a.supported())
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	if err := a.userPrefsBus.Delete(ctx, userID, entity); err != nil {
+		return errs.Newf(errs.Internal, "delete: %s", err)
+	}
+
+	return nil
+}
+
+func (a *App) supported() string {
+	names := make([]string, 0, len(a.entities))
+	for name := range a.entities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ",")
+}
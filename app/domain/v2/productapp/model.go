@@ -0,0 +1,131 @@
+package productapp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ardanlabs/encore/app/domain/productapp"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/money"
+)
+
+// Product represents information about an individual product. Version 2
+// reports the cost as money instead of a float.
+type Product struct {
+	ID          string      `json:"id"`
+	UserID      string      `json:"userID"`
+	Name        string      `json:"name"`
+	Cost        money.Money `json:"cost"`
+	Quantity    int         `json:"quantity"`
+	DateCreated string      `json:"dateCreated"`
+	DateUpdated string      `json:"dateUpdated"`
+	ETag        string      `json:"etag,omitempty" header:"ETag"`
+}
+
+// Encode implments the encoder interface.
+func (app Product) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppProduct(prd productapp.Product) Product {
+	return Product{
+		ID:          prd.ID,
+		UserID:      prd.UserID,
+		Name:        prd.Name,
+		Cost:        money.New(prd.Cost),
+		Quantity:    prd.Quantity,
+		DateCreated: prd.DateCreated,
+		DateUpdated: prd.DateUpdated,
+		ETag:        prd.ETag,
+	}
+}
+
+func toAppProducts(prds []productapp.Product) []Product {
+	app := make([]Product, len(prds))
+	for i, prd := range prds {
+		app[i] = toAppProduct(prd)
+	}
+
+	return app
+}
+
+// =============================================================================
+
+// NewProduct defines the data needed to add a new product.
+type NewProduct struct {
+	Name     string      `json:"name" validate:"required"`
+	Cost     money.Money `json:"cost" validate:"required,money"`
+	Quantity int         `json:"quantity" validate:"required,gte=1"`
+}
+
+// Decode implments the decoder interface.
+func (app *NewProduct) Decode(data []byte) error {
+	return json.Unmarshal(data, &app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewProduct) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
+	}
+
+	return nil
+}
+
+func toV1NewProduct(app NewProduct) (productapp.NewProduct, error) {
+	cost, err := app.Cost.Float()
+	if err != nil {
+		return productapp.NewProduct{}, errs.NewFieldsError("cost", err)
+	}
+
+	v1 := productapp.NewProduct{
+		Name:     app.Name,
+		Cost:     cost,
+		Quantity: app.Quantity,
+	}
+
+	return v1, nil
+}
+
+// =============================================================================
+
+// UpdateProduct defines the data needed to update a product.
+type UpdateProduct struct {
+	Name     *string      `json:"name"`
+	Cost     *money.Money `json:"cost" validate:"omitempty,money"`
+	Quantity *int         `json:"quantity" validate:"omitempty,gte=1"`
+	IfMatch  string       `header:"If-Match"`
+}
+
+// Decode implments the decoder interface.
+func (app *UpdateProduct) Decode(data []byte) error {
+	return json.Unmarshal(data, &app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app UpdateProduct) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
+	}
+
+	return nil
+}
+
+func toV1UpdateProduct(app UpdateProduct) (productapp.UpdateProduct, error) {
+	v1 := productapp.UpdateProduct{
+		Name:     app.Name,
+		Quantity: app.Quantity,
+		IfMatch:  app.IfMatch,
+	}
+
+	if app.Cost != nil {
+		cost, err := app.Cost.Float()
+		if err != nil {
+			return productapp.UpdateProduct{}, errs.NewFieldsError("cost", err)
+		}
+		v1.Cost = &cost
+	}
+
+	return v1, nil
+}
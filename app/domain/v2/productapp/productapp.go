@@ -0,0 +1,84 @@
+// Package productapp maintains version 2 of the app layer api for the product
+// domain. The business rules live in version 1 and this version adapts the
+// models, so a fix in one version applies to both.
+package productapp
+
+import (
+	"context"
+
+	"github.com/ardanlabs/encore/app/domain/productapp"
+	"github.com/ardanlabs/encore/app/sdk/query"
+)
+
+// App manages the set of app layer api functions for the product domain.
+type App struct {
+	productApp *productapp.App
+}
+
+// NewApp constructs a product app API for use.
+func NewApp(productApp *productapp.App) *App {
+	return &App{
+		productApp: productApp,
+	}
+}
+
+// Create adds a new product to the system.
+func (a *App) Create(ctx context.Context, app NewProduct) (Product, error) {
+	np, err := toV1NewProduct(app)
+	if err != nil {
+		return Product{}, err
+	}
+
+	prd, err := a.productApp.Create(ctx, np)
+	if err != nil {
+		return Product{}, err
+	}
+
+	return toAppProduct(prd), nil
+}
+
+// Update updates an existing product.
+func (a *App) Update(ctx context.Context, app UpdateProduct) (Product, error) {
+	up, err := toV1UpdateProduct(app)
+	if err != nil {
+		return Product{}, err
+	}
+
+	prd, err := a.productApp.Update(ctx, up)
+	if err != nil {
+		return Product{}, err
+	}
+
+	return toAppProduct(prd), nil
+}
+
+// Query returns a list of products with paging. The query string is the
+// same as version 1.
+func (a *App) Query(ctx context.Context, qp productapp.QueryParams) (query.Result[Product], error) {
+	result, err := a.productApp.Query(ctx, qp)
+	if err != nil {
+		return query.Result[Product]{}, err
+	}
+
+	v2 := query.Result[Product]{
+		Items:       toAppProducts(result.Items),
+		Total:       result.Total,
+		Page:        result.Page,
+		RowsPerPage: result.RowsPerPage,
+		NextCursor:  result.NextCursor,
+		PrevCursor:  result.PrevCursor,
+		Fields:      result.Fields,
+	}
+
+	return v2, nil
+}
+
+// QueryByID returns a product by its ID.
+func (a *App) QueryByID(ctx context.Context) (Product, error) {
+	prd, err := a.productApp.QueryByID(ctx)
+	if err != nil {
+		return Product{}, err
+	}
+
+	return toAppProduct(prd), nil
+}
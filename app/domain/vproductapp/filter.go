@@ -1,57 +1,58 @@
 package vproductapp
 
 import (
-	"strconv"
-
 	"github.com/ardanlabs/encore/app/sdk/errs"
 	"github.com/ardanlabs/encore/business/domain/productbus"
 	"github.com/ardanlabs/encore/business/domain/userbus"
 	"github.com/ardanlabs/encore/business/domain/vproductbus"
-	"github.com/google/uuid"
+	"github.com/ardanlabs/encore/business/sdk/queryfilter"
+	"github.com/ardanlabs/encore/business/sdk/where"
 )
 
 func parseFilter(qp QueryParams) (vproductbus.QueryFilter, error) {
-	var filter vproductbus.QueryFilter
-
-	if qp.ID != "" {
-		id, err := uuid.Parse(qp.ID)
-		if err != nil {
-			return vproductbus.QueryFilter{}, errs.NewFieldsError("product_id", err)
-		}
-		filter.ID = &id
-	}
-
-	if qp.Name != "" {
-		name, err := productbus.ParseName(qp.Name)
-		if err != nil {
-			return vproductbus.QueryFilter{}, errs.NewFieldsError("name", err)
-		}
-		filter.Name = &name
-	}
-
-	if qp.Cost != "" {
-		cst, err := strconv.ParseFloat(qp.Cost, 64)
-		if err != nil {
-			return vproductbus.QueryFilter{}, errs.NewFieldsError("cost", err)
-		}
-		filter.Cost = &cst
-	}
-
-	if qp.Quantity != "" {
-		qua, err := strconv.ParseInt(qp.Quantity, 10, 64)
-		if err != nil {
-			return vproductbus.QueryFilter{}, errs.NewFieldsError("quantity", err)
-		}
-		i := int(qua)
-		filter.Quantity = &i
+	p := queryfilter.New()
+
+	filter := vproductbus.QueryFilter{
+		ID:   queryfilter.Value(p, "product_id", qp.ID, queryfilter.UUID),
+		Name: queryfilter.Value(p, "name", qp.Name, productbus.ParseName),
+		Cost: queryfilter.Where(p, "cost", map[where.Op]string{
+			where.EQ:  qp.Cost,
+			where.GT:  qp.CostGT,
+			where.GTE: qp.CostGTE,
+			where.LT:  qp.CostLT,
+			where.LTE: qp.CostLTE,
+			where.IN:  qp.CostIn,
+		}, queryfilter.Float),
+		Quantity: queryfilter.Where(p, "quantity", map[where.Op]string{
+			where.EQ:  qp.Quantity,
+			where.GT:  qp.QuantityGT,
+			where.GTE: qp.QuantityGTE,
+			where.LT:  qp.QuantityLT,
+			where.LTE: qp.QuantityLTE,
+			where.IN:  qp.QuantityIn,
+		}, queryfilter.Int),
+		UserID:    queryfilter.Value(p, "user_id", qp.UserID, queryfilter.UUID),
+		UserName:  queryfilter.Value(p, "user_name", qp.UserName, userbus.ParseName),
+		UserEmail: queryfilter.Value(p, "user_email", qp.UserEmail, queryfilter.Email),
+		Value: queryfilter.Where(p, "value", map[where.Op]string{
+			where.EQ:  qp.Value,
+			where.GT:  qp.ValueGT,
+			where.GTE: qp.ValueGTE,
+			where.LT:  qp.ValueLT,
+			where.LTE: qp.ValueLTE,
+			where.IN:  qp.ValueIn,
+		}, queryfilter.Float),
+		UserProducts: queryfilter.Where(p, "user_products", map[where.Op]string{
+			where.EQ:  qp.UserProducts,
+			where.GT:  qp.UserProductsGT,
+			where.GTE: qp.UserProductsGTE,
+			where.LT:  qp.UserProductsLT,
+			where.LTE: qp.UserProductsLTE,
+		}, queryfilter.Int),
 	}
 
-	if qp.Name != "" {
-		name, err := userbus.ParseName(qp.Name)
-		if err != nil {
-			return vproductbus.QueryFilter{}, errs.NewFieldsError("name", err)
-		}
-		filter.UserName = &name
+	if err := p.Err(); err != nil {
+		return vproductbus.QueryFilter{}, errs.NewFilterErrors(err)
 	}
 
 	return filter, nil
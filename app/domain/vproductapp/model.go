@@ -9,14 +9,37 @@ import (
 
 // QueryParams represents the set of possible query strings.
 type QueryParams struct {
-	Page     string
-	Rows     string
-	OrderBy  string
-	ID       string
-	Name     string
-	Cost     string
-	Quantity string
-	UserName string
+	Page            string
+	Rows            string
+	OrderBy         string
+	ID              string
+	Name            string
+	Cost            string
+	CostGT          string `query:"cost[gt]"`
+	CostGTE         string `query:"cost[gte]"`
+	CostLT          string `query:"cost[lt]"`
+	CostLTE         string `query:"cost[lte]"`
+	CostIn          string `query:"cost[in]"`
+	Quantity        string
+	QuantityGT      string `query:"quantity[gt]"`
+	QuantityGTE     string `query:"quantity[gte]"`
+	QuantityLT      string `query:"quantity[lt]"`
+	QuantityLTE     string `query:"quantity[lte]"`
+	QuantityIn      string `query:"quantity[in]"`
+	UserID          string
+	UserName        string
+	UserEmail       string
+	Value           string
+	ValueGT         string `query:"value[gt]"`
+	ValueGTE        string `query:"value[gte]"`
+	ValueLT         string `query:"value[lt]"`
+	ValueLTE        string `query:"value[lte]"`
+	ValueIn         string `query:"value[in]"`
+	UserProducts    string
+	UserProductsGT  string `query:"user_products[gt]"`
+	UserProductsGTE string `query:"user_products[gte]"`
+	UserProductsLT  string `query:"user_products[lt]"`
+	UserProductsLTE string `query:"user_products[lte]"`
 }
 
 // =============================================================================
@@ -24,14 +47,17 @@ type QueryParams struct {
 // Product represents information about an individual product with
 // extended information.
 type Product struct {
-	ID          string  `json:"id"`
-	UserID      string  `json:"userID"`
-	Name        string  `json:"name"`
-	Cost        float64 `json:"cost"`
-	Quantity    int     `json:"quantity"`
-	DateCreated string  `json:"dateCreated"`
-	DateUpdated string  `json:"dateUpdated"`
-	UserName    string  `json:"userName"`
+	ID           string  `json:"id"`
+	UserID       string  `json:"userID"`
+	Name         string  `json:"name"`
+	Cost         float64 `json:"cost"`
+	Quantity     int     `json:"quantity"`
+	DateCreated  string  `json:"dateCreated"`
+	DateUpdated  string  `json:"dateUpdated"`
+	UserName     string  `json:"userName"`
+	UserEmail    string  `json:"userEmail"`
+	Value        float64 `json:"value"`
+	UserProducts int     `json:"userProducts"`
 }
 
 // Encode implments the encoder interface.
@@ -42,14 +68,17 @@ func (app Product) Encode() ([]byte, string, error) {
 
 func toAppProduct(prd vproductbus.Product) Product {
 	return Product{
-		ID:          prd.ID.String(),
-		UserID:      prd.UserID.String(),
-		Name:        prd.Name.String(),
-		Cost:        prd.Cost,
-		Quantity:    prd.Quantity,
-		DateCreated: prd.DateCreated.Format(time.RFC3339),
-		DateUpdated: prd.DateUpdated.Format(time.RFC3339),
-		UserName:    prd.UserName.String(),
+		ID:           prd.ID.String(),
+		UserID:       prd.UserID.String(),
+		Name:         prd.Name.String(),
+		Cost:         prd.Cost,
+		Quantity:     prd.Quantity,
+		DateCreated:  prd.DateCreated.Format(time.RFC3339),
+		DateUpdated:  prd.DateUpdated.Format(time.RFC3339),
+		UserName:     prd.UserName.String(),
+		UserEmail:    prd.UserEmail.Address,
+		Value:        prd.Value,
+		UserProducts: prd.UserProducts,
 	}
 }
 
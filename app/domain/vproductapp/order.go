@@ -8,10 +8,13 @@ import (
 var defaultOrderBy = order.NewBy("product_id", order.ASC)
 
 var orderByFields = map[string]string{
-	"product_id": vproductbus.OrderByProductID,
-	"user_id":    vproductbus.OrderByUserID,
-	"name":       vproductbus.OrderByName,
-	"cost":       vproductbus.OrderByCost,
-	"quantity":   vproductbus.OrderByQuantity,
-	"user_name":  vproductbus.OrderByUserName,
+	"product_id":    vproductbus.OrderByProductID,
+	"user_id":       vproductbus.OrderByUserID,
+	"name":          vproductbus.OrderByName,
+	"cost":          vproductbus.OrderByCost,
+	"quantity":      vproductbus.OrderByQuantity,
+	"user_name":     vproductbus.OrderByUserName,
+	"user_email":    vproductbus.OrderByUserEmail,
+	"value":         vproductbus.OrderByValue,
+	"user_products": vproductbus.OrderByUserProducts,
 }
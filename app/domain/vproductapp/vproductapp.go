@@ -3,6 +3,7 @@ package vproductapp
 
 import (
 	"context"
+	"iter"
 
 	"github.com/ardanlabs/encore/app/sdk/errs"
 	"github.com/ardanlabs/encore/app/sdk/query"
@@ -52,3 +53,33 @@ func (a *App) Query(ctx context.Context, qp QueryParams) (query.Result[Product],
 
 	return query.NewResult(toAppProducts(prds), total, page), nil
 }
+
+// QueryStream returns every product that matches the query one at a time
+// instead of a page. The query is checked before the sequence is returned
+// so an invalid query is reported before anything is written.
+func (a *App) QueryStream(ctx context.Context, qp QueryParams) (iter.Seq2[Product, error], error) {
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return nil, err
+	}
+
+	orderBy, err := order.Parse(orderByFields, qp.OrderBy, defaultOrderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	seq := func(yield func(Product, error) bool) {
+		for prd, err := range a.vproductBus.QueryStream(ctx, filter, orderBy) {
+			if err != nil {
+				yield(Product{}, errs.Newf(errs.Internal, "querystream: %s", err))
+				return
+			}
+
+			if !yield(toAppProduct(prd), nil) {
+				return
+			}
+		}
+	}
+
+	return seq, nil
+}
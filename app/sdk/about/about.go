@@ -0,0 +1,101 @@
+// Package about provides support for reporting what build is running and
+// where, so a deploy can be verified.
+package about
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"encore.dev"
+	"github.com/ardanlabs/encore/app/sdk/encoder"
+	"github.com/ardanlabs/encore/business/sdk/appdb/migrate"
+	"github.com/jmoiron/sqlx"
+)
+
+// Info represents information about the running build and environment.
+type Info struct {
+	AppID              string          `json:"appID"`
+	APIBaseURL         string          `json:"apiBaseURL"`
+	Environment        string          `json:"environment"`
+	EnvironmentType    string          `json:"environmentType"`
+	Cloud              string          `json:"cloud"`
+	Revision           string          `json:"revision"`
+	UncommittedChanges bool            `json:"uncommittedChanges"`
+	BuildTime          string          `json:"buildTime,omitempty"`
+	GoVersion          string          `json:"goVersion"`
+	DeployID           string          `json:"deployID,omitempty"`
+	DeployTime         string          `json:"deployTime,omitempty"`
+	SchemaVersion      int             `json:"schemaVersion"`
+	SchemaDirty        bool            `json:"schemaDirty"`
+	Features           map[string]bool `json:"features"`
+}
+
+// Encode implments the encoder interface.
+func (app Info) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// Collect gathers the information about the running build. If the schema
+// version can't be read, the version is reported as -1 so the endpoint is
+// still useful when the database is down.
+func Collect(ctx context.Context, db *sqlx.DB, features map[string]bool) Info {
+	meta := encore.Meta()
+
+	info := Info{
+		AppID:              meta.AppID,
+		APIBaseURL:         meta.APIBaseURL.String(),
+		Environment:        meta.Environment.Name,
+		EnvironmentType:    string(meta.Environment.Type),
+		Cloud:              string(meta.Environment.Cloud),
+		Revision:           meta.Build.Revision,
+		UncommittedChanges: meta.Build.UncommittedChanges,
+		BuildTime:          buildTime(),
+		GoVersion:          runtime.Version(),
+		DeployID:           meta.Deploy.ID,
+		Features:           features,
+	}
+
+	if !meta.Deploy.Time.IsZero() {
+		info.DeployTime = meta.Deploy.Time.Format(time.RFC3339)
+	}
+
+	version, dirty, err := migrate.SchemaVersion(ctx, db)
+	if err != nil {
+		version = -1
+	}
+
+	info.SchemaVersion = version
+	info.SchemaDirty = dirty
+
+	return info
+}
+
+// Handler returns a handler that writes the information about the running
+// build for the debug mux in the format the request accepts.
+func Handler(db *sqlx.DB, features map[string]bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		encoder.Default.Write(w, r, http.StatusOK, Collect(r.Context(), db, features))
+	}
+}
+
+// buildTime returns the time of the commit the binary was built from when
+// the Go toolchain recorded it.
+func buildTime() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	for _, s := range bi.Settings {
+		if s.Key == "vcs.time" {
+			return s.Value
+		}
+	}
+
+	return ""
+}
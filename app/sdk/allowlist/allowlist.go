@@ -0,0 +1,139 @@
+// Package allowlist provides support for restricting endpoints to clients
+// connecting from a set of networks.
+package allowlist
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ErrDenied is returned when a client isn't connecting from an allowed
+// network.
+var ErrDenied = errors.New("access from this address is not allowed")
+
+// Private are the loopback and private networks, which are allowed by
+// default so the operations endpoints stay reachable from inside the
+// deployment.
+var Private = []string{
+	"127.0.0.0/8",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::1/128",
+	"fc00::/7",
+}
+
+// List is a set of networks clients are allowed to connect from.
+type List struct {
+	prefixes []netip.Prefix
+}
+
+// Parse constructs a list from the specified CIDRs. A single address is
+// accepted as a network of its own.
+func Parse(cidrs []string) (*List, error) {
+	var l List
+
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("parse: address[%s]: %w", cidr, err)
+			}
+			l.prefixes = append(l.prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("parse: cidr[%s]: %w", cidr, err)
+		}
+		l.prefixes = append(l.prefixes, prefix.Masked())
+	}
+
+	return &l, nil
+}
+
+// Allowed reports whether the address is in one of the networks of the
+// list. An address that can't be parsed is never allowed, and neither is
+// any address by a nil list.
+func (l *List) Allowed(ip string) bool {
+	if l == nil {
+		return false
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range l.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClientIP returns the address of the client that made the request, or an
+// empty string when it can't be known, which no list allows. The peer the
+// connection came from is the client unless it's one of the trusted
+// proxies, then the client is taken from the hops the proxies appended to
+// the X-Forwarded-For header.
+func ClientIP(headers http.Header, remoteAddr string, proxies *List) string {
+	peer := remoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		peer = host
+	}
+
+	if _, err := netip.ParseAddr(peer); err != nil {
+		return ""
+	}
+
+	if !proxies.Allowed(peer) {
+		return peer
+	}
+
+	if ip := ForwardedIP(headers, proxies); ip != "" {
+		return ip
+	}
+
+	return peer
+}
+
+// ForwardedIP returns the address of the client from the X-Forwarded-For
+// header, or an empty string when there is none. Each proxy appends the
+// address it received the request from, so the header is read from the
+// right and the first address that isn't one of the trusted proxies is the
+// client. A client can write anything to the left of that, so those
+// addresses are never used.
+func ForwardedIP(headers http.Header, proxies *List) string {
+	hops := strings.Split(strings.Join(headers.Values("X-Forwarded-For"), ","), ",")
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+
+		if _, err := netip.ParseAddr(hop); err != nil {
+			return ""
+		}
+
+		if !proxies.Allowed(hop) {
+			return hop
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,102 @@
+package allowlist_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ardanlabs/encore/app/sdk/allowlist"
+)
+
+func Test_Allowed(t *testing.T) {
+	l, err := allowlist.Parse([]string{"10.0.0.0/8", " 203.0.113.7 ", "2001:db8::/32", ""})
+	if err != nil {
+		t.Fatalf("Should be able to parse the list: %s", err)
+	}
+
+	tt := []struct {
+		ip      string
+		allowed bool
+	}{
+		{ip: "10.1.2.3", allowed: true},
+		{ip: "203.0.113.7", allowed: true},
+		{ip: "203.0.113.8", allowed: false},
+		{ip: "::ffff:10.1.2.3", allowed: true},
+		{ip: "2001:db8::1", allowed: true},
+		{ip: "192.168.1.1", allowed: false},
+		{ip: "bad", allowed: false},
+	}
+
+	for _, tst := range tt {
+		if got := l.Allowed(tst.ip); got != tst.allowed {
+			t.Fatalf("%s: Should get %t: got %t", tst.ip, tst.allowed, got)
+		}
+	}
+
+	if _, err := allowlist.Parse([]string{"10.0.0.0/33"}); err == nil {
+		t.Fatalf("Should not be able to parse a bad cidr")
+	}
+
+	if _, err := allowlist.Parse(allowlist.Private); err != nil {
+		t.Fatalf("Should be able to parse the private networks: %s", err)
+	}
+}
+
+func Test_ClientIP(t *testing.T) {
+	proxies, err := allowlist.Parse([]string{"10.0.0.0/24"})
+	if err != nil {
+		t.Fatalf("Should be able to parse the proxies: %s", err)
+	}
+
+	h := http.Header{}
+	h.Set("X-Forwarded-For", "127.0.0.1, 203.0.113.7, 10.0.0.1")
+
+	tt := []struct {
+		name       string
+		headers    http.Header
+		remoteAddr string
+		exp        string
+	}{
+		{name: "proxied", headers: h, remoteAddr: "10.0.0.2:5000", exp: "203.0.113.7"},
+		{name: "untrusted-peer", headers: h, remoteAddr: "198.51.100.1:5000", exp: "198.51.100.1"},
+		{name: "no-header", headers: http.Header{}, remoteAddr: "10.0.0.2:5000", exp: "10.0.0.2"},
+		{name: "no-peer", headers: h, remoteAddr: "", exp: ""},
+		{name: "bad-peer", headers: http.Header{}, remoteAddr: "bad", exp: ""},
+	}
+
+	for _, tst := range tt {
+		if ip := allowlist.ClientIP(tst.headers, tst.remoteAddr, proxies); ip != tst.exp {
+			t.Fatalf("%s: Should get %q: got %q", tst.name, tst.exp, ip)
+		}
+	}
+}
+
+func Test_ForwardedIP(t *testing.T) {
+	proxies, err := allowlist.Parse([]string{"10.0.0.0/24"})
+	if err != nil {
+		t.Fatalf("Should be able to parse the proxies: %s", err)
+	}
+
+	tt := []struct {
+		name    string
+		values  []string
+		proxies *allowlist.List
+		exp     string
+	}{
+		{name: "rightmost", values: []string{"127.0.0.1, 203.0.113.7"}, exp: "203.0.113.7"},
+		{name: "skip-proxies", values: []string{"127.0.0.1, 203.0.113.7, 10.0.0.1"}, proxies: proxies, exp: "203.0.113.7"},
+		{name: "many-headers", values: []string{"127.0.0.1", "203.0.113.7"}, exp: "203.0.113.7"},
+		{name: "spoofed", values: []string{"127.0.0.1, bad"}, exp: ""},
+		{name: "none", exp: ""},
+	}
+
+	for _, tst := range tt {
+		h := http.Header{}
+		for _, v := range tst.values {
+			h.Add("X-Forwarded-For", v)
+		}
+
+		if ip := allowlist.ForwardedIP(h, tst.proxies); ip != tst.exp {
+			t.Fatalf("%s: Should get %q: got %q", tst.name, tst.exp, ip)
+		}
+	}
+}
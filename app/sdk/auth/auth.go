@@ -5,14 +5,26 @@ package auth
 
 import (
 	"context"
+	"crypto/rsa"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/ardanlabs/encore/business/domain/permissionbus"
+	"github.com/ardanlabs/encore/business/domain/permissionbus/stores/permissioncache"
+	"github.com/ardanlabs/encore/business/domain/permissionbus/stores/permissiondb"
+	"github.com/ardanlabs/encore/business/domain/revocationbus"
+	"github.com/ardanlabs/encore/business/domain/revocationbus/stores/revocationcache"
+	"github.com/ardanlabs/encore/business/domain/revocationbus/stores/revocationdb"
 	"github.com/ardanlabs/encore/business/domain/userbus"
 	"github.com/ardanlabs/encore/business/domain/userbus/stores/usercache"
 	"github.com/ardanlabs/encore/business/domain/userbus/stores/userdb"
+	"github.com/ardanlabs/encore/business/sdk/cachemetrics"
+	"github.com/ardanlabs/encore/business/sdk/storecache"
+	"github.com/ardanlabs/encore/foundation/clock"
 	"github.com/ardanlabs/encore/foundation/logger"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
@@ -23,10 +35,53 @@ import (
 // ErrForbidden is returned when a auth issue is identified.
 var ErrForbidden = errors.New("attempted action is not allowed")
 
-// Claims represents the authorization claims transmitted via a JWT.
+// ErrRevoked is returned when a token has been revoked before it expired.
+var ErrRevoked = errors.New("token revoked")
+
+// Claims represents the authorization claims transmitted via a JWT. The
+// permissions are loaded for the roles on each request and are never part of
+// a signed token, so a change to the permissions of a role is seen without
+// issuing new tokens. The family is the login of the refresh token the token
+// was issued with, so logging out can end the login as well.
 type Claims struct {
 	jwt.RegisteredClaims
-	Roles []string `json:"roles"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions,omitempty"`
+	FamilyID    string   `json:"fid,omitempty"`
+}
+
+// HasPermission reports if the claims grant the permission, like
+// product:write. A permission like product:* grants every action on the
+// resource.
+func (c Claims) HasPermission(permission string) bool {
+	required, err := permissionbus.ParsePermission(permission)
+	if err != nil {
+		return false
+	}
+
+	for _, value := range c.Permissions {
+		granted, err := permissionbus.ParsePermission(value)
+		if err != nil {
+			continue
+		}
+
+		if granted.Grants(required) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasAnyPermission reports if the claims grant any of the permissions.
+func (c Claims) HasAnyPermission(permissions ...string) bool {
+	for _, permission := range permissions {
+		if c.HasPermission(permission) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // KeyLookup declares a method set of behavior for looking up
@@ -37,63 +92,235 @@ type KeyLookup interface {
 	PublicKey(kid string) (key string, err error)
 }
 
-// Config represents information required to initialize auth.
+// rsaKeyLookup is implemented by a key lookup that keeps the private keys
+// parsed, so a token can be signed without parsing the PEM again.
+type rsaKeyLookup interface {
+	RSAPrivateKey(kid string) (*rsa.PrivateKey, error)
+}
+
+// activeKeyLookup is implemented by a key lookup that rotates its keys and
+// picks the key tokens are signed with.
+type activeKeyLookup interface {
+	ActiveKID() (string, error)
+}
+
+// keySetLookup is implemented by a key lookup that can list the public keys
+// tokens can be verified with, so they can be published.
+type keySetLookup interface {
+	RSAPublicKeys() map[string]*rsa.PublicKey
+}
+
+// Config represents information required to initialize auth. The clock is
+// used to check if a token has expired and defaults to the system clock. The
+// users checked for each request are cached for 10 minutes unless the user
+// cache sets a TTL, and the cache reports to the cache counters when they
+// are provided. The revoked tokens of each user are cached for a minute unless
+// the revocation cache sets a TTL, which should stay short since a token
+// revoked on another instance is only seen once the entry expires. The
+// permissions of each role are cached the same way. The active kid is the
+// key the tokens the system issues on its own are signed with, like the ones
+// issued for a refresh token, unless the key lookup picks the key itself.
+// The tokens issued for a user can be used for the token TTL, 15 minutes
+// unless it's set, and are renewed with a refresh token after that.
 type Config struct {
-	Log       *logger.Logger
-	DB        *sqlx.DB
-	KeyLookup KeyLookup
-	Issuer    string
+	Log             *logger.Logger
+	DB              *sqlx.DB
+	KeyLookup       KeyLookup
+	ActiveKID       string
+	Issuer          string
+	TokenTTL        time.Duration
+	Clock           clock.Clock
+	UserCache       storecache.Config
+	RevocationCache storecache.Config
+	PermissionCache storecache.Config
+	CacheCounters   cachemetrics.Counters
 }
 
 // Auth is used to authenticate clients. It can generate a token for a
 // set of user claims and recreate the claims by parsing the token.
 type Auth struct {
-	keyLookup KeyLookup
-	userBus   *userbus.Business
-	method    jwt.SigningMethod
-	parser    *jwt.Parser
-	issuer    string
+	keyLookup     KeyLookup
+	userBus       *userbus.Business
+	userCache     *usercache.Store
+	revocationBus *revocationbus.Business
+	permissionBus *permissionbus.Business
+	method        jwt.SigningMethod
+	parser        *jwt.Parser
+	activeKID     string
+	issuer        string
+	tokenTTL      time.Duration
+	clock         clock.Clock
 }
 
 // New creates an Auth to support authentication/authorization.
 func New(cfg Config) (*Auth, error) {
+	if cfg.Clock == nil {
+		cfg.Clock = clock.System{}
+	}
+
+	if cfg.TokenTTL <= 0 {
+		cfg.TokenTTL = 15 * time.Minute
+	}
+
+	if cfg.UserCache.TTL <= 0 {
+		cfg.UserCache.TTL = 10 * time.Minute
+	}
+
+	if cfg.RevocationCache.TTL <= 0 {
+		cfg.RevocationCache.TTL = time.Minute
+	}
+
+	if cfg.PermissionCache.TTL <= 0 {
+		cfg.PermissionCache.TTL = time.Minute
+	}
 
 	// If a database connection is not provided, we won't perform the
-	// user enabled check.
+	// user enabled and revoked token checks or load permissions.
 	var userBus *userbus.Business
+	var userCache *usercache.Store
+	var revocationBus *revocationbus.Business
+	var permissionBus *permissionbus.Business
 	if cfg.DB != nil {
-		userBus = userbus.NewBusiness(cfg.Log, nil, usercache.NewStore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB), 10*time.Minute))
+		userCache = usercache.NewStore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB), cfg.UserCache, cfg.Clock, cfg.CacheCounters)
+		userBus = userbus.NewBusiness(cfg.Log, cfg.Clock, nil, userCache)
+
+		revocationCache := revocationcache.NewStore(cfg.Log, revocationdb.NewStore(cfg.Log, cfg.DB), cfg.RevocationCache, cfg.Clock, cfg.CacheCounters)
+		revocationBus = revocationbus.NewBusiness(cfg.Log, cfg.Clock, cfg.TokenTTL, revocationCache)
+
+		permissionCache := permissioncache.NewStore(cfg.Log, permissiondb.NewStore(cfg.Log, cfg.DB), cfg.PermissionCache, cfg.Clock, cfg.CacheCounters)
+		permissionBus = permissionbus.NewBusiness(cfg.Log, permissionCache)
 	}
 
 	a := Auth{
-		keyLookup: cfg.KeyLookup,
-		userBus:   userBus,
-		method:    jwt.GetSigningMethod(jwt.SigningMethodRS256.Name),
-		parser:    jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Name})),
-		issuer:    cfg.Issuer,
+		keyLookup:     cfg.KeyLookup,
+		userBus:       userBus,
+		userCache:     userCache,
+		revocationBus: revocationBus,
+		permissionBus: permissionBus,
+		method:        jwt.GetSigningMethod(jwt.SigningMethodRS256.Name),
+		parser:        jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Name})),
+		activeKID:     cfg.ActiveKID,
+		issuer:        cfg.Issuer,
+		tokenTTL:      cfg.TokenTTL,
+		clock:         cfg.Clock,
 	}
 
 	return &a, nil
 }
 
+// UserCacheStats returns what the cache of the users checked for each request
+// has done, with the top number of users it has served the most. Nothing is
+// reported when there is no database to check the users with.
+func (a *Auth) UserCacheStats(top int) cachemetrics.Stats {
+	if a.userCache == nil {
+		return cachemetrics.Stats{Cache: usercache.CacheName}
+	}
+
+	return a.userCache.Stats(top)
+}
+
 // Issuer provides the configured issuer used to authenticate tokens.
 func (a *Auth) Issuer() string {
 	return a.issuer
 }
 
+// ActiveKID provides the kid of the key the system signs tokens with when the
+// caller doesn't pick one. A key lookup that rotates its keys picks its
+// newest key, and the configured kid is used when it has none.
+func (a *Auth) ActiveKID() string {
+	if kl, ok := a.keyLookup.(activeKeyLookup); ok {
+		if kid, err := kl.ActiveKID(); err == nil {
+			return kid
+		}
+	}
+
+	return a.activeKID
+}
+
+// KeySet returns the public keys tokens can be verified with as a JSON Web
+// Key Set, so other systems can verify the tokens on their own. The keys are
+// ordered by kid.
+func (a *Auth) KeySet() (KeySet, error) {
+	kl, ok := a.keyLookup.(keySetLookup)
+	if !ok {
+		return KeySet{}, errors.New("key lookup can't list its keys")
+	}
+
+	keys := kl.RSAPublicKeys()
+
+	set := KeySet{
+		Keys: make([]JWK, 0, len(keys)),
+	}
+
+	for _, kid := range slices.Sorted(maps.Keys(keys)) {
+		set.Keys = append(set.Keys, toJWK(kid, keys[kid]))
+	}
+
+	return set, nil
+}
+
+// NewClaims constructs the claims for a token issued to the user. Each token
+// gets its own id so it can be revoked on its own.
+func (a *Auth) NewClaims(usr userbus.User) Claims {
+	return a.NewScopedClaims(usr.ID, usr.Roles)
+}
+
+// NewScopedClaims constructs the claims for a token issued to the user that
+// only grants the roles, like the roles an API key is scoped to.
+func (a *Auth) NewScopedClaims(userID uuid.UUID, roles []userbus.Role) Claims {
+	now := a.clock.Now().UTC()
+
+	return Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   userID.String(),
+			Issuer:    a.issuer,
+			ExpiresAt: jwt.NewNumericDate(now.Add(a.tokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Roles: userbus.ParseRolesToString(roles),
+	}
+}
+
+// Permissions returns the permissions business the claims are loaded with,
+// so a change to the permissions of a role is seen by this instance right
+// away. Nil is returned when there is no database.
+func (a *Auth) Permissions() *permissionbus.Business {
+	return a.permissionBus
+}
+
+// LoadPermissions sets the permissions granted to the roles of the claims.
+// The claims get no permissions when there is no database to load them from.
+func (a *Auth) LoadPermissions(ctx context.Context, claims *Claims) error {
+	if a.permissionBus == nil {
+		return nil
+	}
+
+	roles, err := userbus.ParseRoles(claims.Roles)
+	if err != nil {
+		return fmt.Errorf("parse roles: %w", err)
+	}
+
+	perms, err := a.permissionBus.QueryByRoles(ctx, roles)
+	if err != nil {
+		return fmt.Errorf("query permissions: %w", err)
+	}
+
+	claims.Permissions = permissionbus.ParsePermissionsToString(perms)
+
+	return nil
+}
+
 // GenerateToken generates a signed JWT token string representing the user Claims.
 func (a *Auth) GenerateToken(kid string, claims Claims) (string, error) {
+	claims.Permissions = nil
+
 	token := jwt.NewWithClaims(a.method, claims)
 	token.Header["kid"] = kid
 
-	privateKeyPEM, err := a.keyLookup.PrivateKey(kid)
-	if err != nil {
-		return "", fmt.Errorf("private key: %w", err)
-	}
-
-	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(privateKeyPEM))
+	privateKey, err := a.privateKey(kid)
 	if err != nil {
-		return "", fmt.Errorf("parsing private pem: %w", err)
+		return "", err
 	}
 
 	str, err := token.SignedString(privateKey)
@@ -104,6 +331,29 @@ func (a *Auth) GenerateToken(kid string, claims Claims) (string, error) {
 	return str, nil
 }
 
+// privateKey returns the parsed private key for the kid.
+func (a *Auth) privateKey(kid string) (*rsa.PrivateKey, error) {
+	if kl, ok := a.keyLookup.(rsaKeyLookup); ok {
+		privateKey, err := kl.RSAPrivateKey(kid)
+		if err != nil {
+			return nil, fmt.Errorf("private key: %w", err)
+		}
+		return privateKey, nil
+	}
+
+	privateKeyPEM, err := a.keyLookup.PrivateKey(kid)
+	if err != nil {
+		return nil, fmt.Errorf("private key: %w", err)
+	}
+
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(privateKeyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("parsing private pem: %w", err)
+	}
+
+	return privateKey, nil
+}
+
 // Authenticate processes the token to validate the sender's token is valid.
 func (a *Auth) Authenticate(ctx context.Context, bearerToken string) (Claims, error) {
 	if !strings.HasPrefix(bearerToken, "Bearer ") {
@@ -137,6 +387,7 @@ func (a *Auth) Authenticate(ctx context.Context, bearerToken string) (Claims, er
 		"Key":   pem,
 		"Token": jwt,
 		"ISS":   a.issuer,
+		"Time":  a.clock.Now().UnixNano(),
 	}
 
 	if err := a.opaPolicyEvaluation(ctx, regoAuthentication, RuleAuthenticate, input); err != nil {
@@ -149,9 +400,68 @@ func (a *Auth) Authenticate(ctx context.Context, bearerToken string) (Claims, er
 		return Claims{}, fmt.Errorf("user not enabled : %w", err)
 	}
 
+	// Check the token hasn't been revoked before it expired.
+
+	if err := a.isRevoked(ctx, claims); err != nil {
+		return Claims{}, fmt.Errorf("token not valid : %w", err)
+	}
+
 	return claims, nil
 }
 
+// RevokeToken revokes the token the claims were issued with until it
+// expires. A token without an id can only be revoked with every other token
+// of the user.
+func (a *Auth) RevokeToken(ctx context.Context, claims Claims) error {
+	if a.revocationBus == nil {
+		return errors.New("revoking tokens requires a database")
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return fmt.Errorf("parse user: %w", err)
+	}
+
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return fmt.Errorf("parse token id: %w", err)
+	}
+
+	if claims.ExpiresAt == nil {
+		return errors.New("token has no expiry")
+	}
+
+	if err := a.revocationBus.RevokeToken(ctx, userID, tokenID, claims.ExpiresAt.Time); err != nil {
+		return fmt.Errorf("revoke token: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeUser revokes every token issued to the user so far, which forces
+// the user to log in again.
+func (a *Auth) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	if a.revocationBus == nil {
+		return errors.New("revoking tokens requires a database")
+	}
+
+	if err := a.revocationBus.RevokeUser(ctx, userID); err != nil {
+		return fmt.Errorf("revoke user: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredRevocations removes the revocations of tokens that have
+// expired and returns the number removed.
+func (a *Auth) DeleteExpiredRevocations(ctx context.Context) (int, error) {
+	if a.revocationBus == nil {
+		return 0, nil
+	}
+
+	return a.revocationBus.DeleteExpired(ctx)
+}
+
 // Authorize attempts to authorize the user with the provided input roles, if
 // none of the input roles are within the user's claims, we return an error
 // otherwise the user is authorized.
@@ -222,3 +532,41 @@ func (a *Auth) isUserEnabled(ctx context.Context, claims Claims) error {
 
 	return nil
 }
+
+// isRevoked checks the token the claims were issued with hasn't been revoked.
+// If no database connection was provided, this check is skipped.
+func (a *Auth) isRevoked(ctx context.Context, claims Claims) error {
+	if a.revocationBus == nil {
+		return nil
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return fmt.Errorf("parse user: %w", err)
+	}
+
+	// A token without an id can still be revoked with every token of the
+	// user, so it's checked with the nil id.
+	var tokenID uuid.UUID
+	if claims.ID != "" {
+		if tokenID, err = uuid.Parse(claims.ID); err != nil {
+			return fmt.Errorf("parse token id: %w", err)
+		}
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+
+	revoked, err := a.revocationBus.IsRevoked(ctx, userID, tokenID, issuedAt)
+	if err != nil {
+		return fmt.Errorf("query revocations: %w", err)
+	}
+
+	if revoked {
+		return ErrRevoked
+	}
+
+	return nil
+}
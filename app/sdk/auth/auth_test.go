@@ -3,12 +3,19 @@ package auth_test
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
+	"log/slog"
 	"testing"
 	"time"
 
 	"github.com/ardanlabs/encore/app/sdk/auth"
 	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/foundation/clock"
+	"github.com/ardanlabs/encore/foundation/keystore"
 	"github.com/ardanlabs/encore/foundation/logger"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
@@ -20,7 +27,7 @@ func Test_Auth(t *testing.T) {
 	ath, err := auth.New(auth.Config{
 		Log:       log,
 		DB:        nil,
-		KeyLookup: &keyStore{},
+		KeyLookup: newKeyStore(t),
 		Issuer:    "service project",
 	})
 	if err != nil {
@@ -73,6 +80,11 @@ func test1(ath *auth.Auth) func(t *testing.T) {
 		if err != nil {
 			t.Errorf("Should be able to authorize the RuleAdminOrSubject claim with Roles.Admin only : %s", err)
 		}
+
+		err = ath.Authorize(context.Background(), parsedClaims, userID, auth.RuleOwnerOnly)
+		if err != nil {
+			t.Errorf("Should be able to authorize the RuleOwnerOnly claim with the same userID : %s", err)
+		}
 	}
 
 	return f
@@ -154,6 +166,11 @@ func test3(ath *auth.Auth) func(t *testing.T) {
 		if err == nil {
 			t.Error("Should NOT be able to authorize the RuleAdminOrSubject claim with Roles.User only and different userID")
 		}
+
+		err = ath.Authorize(context.Background(), parsedClaims, userID, auth.RuleOwnerOnly)
+		if err == nil {
+			t.Error("Should NOT be able to authorize the RuleOwnerOnly claim with a different userID")
+		}
 	}
 
 	return f
@@ -255,11 +272,258 @@ func test6(ath *auth.Auth) func(t *testing.T) {
 	return f
 }
 
+func Test_Expiry(t *testing.T) {
+	clk := clock.NewFrozen(time.Now().UTC())
+
+	ath, err := auth.New(auth.Config{
+		Log:       newUnit(t),
+		KeyLookup: newKeyStore(t),
+		Issuer:    "service project",
+		Clock:     clk,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create an authenticator: %s", err)
+	}
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    ath.Issuer(),
+			Subject:   "5cf37266-3473-4006-984f-9325122678b7",
+			ExpiresAt: jwt.NewNumericDate(clk.Now().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(clk.Now()),
+		},
+		Roles: []string{userbus.Roles.User.String()},
+	}
+
+	token, err := ath.GenerateToken(kid, claims)
+	if err != nil {
+		t.Fatalf("Should be able to generate a JWT : %s", err)
+	}
+
+	if _, err := ath.Authenticate(context.Background(), "Bearer "+token); err != nil {
+		t.Fatalf("Should be able to authenticate the claims before they expire : %s", err)
+	}
+
+	clk.Advance(2 * time.Hour)
+
+	if _, err := ath.Authenticate(context.Background(), "Bearer "+token); err == nil {
+		t.Fatalf("Should NOT be able to authenticate the claims once the clock passes the expiry")
+	}
+}
+
+func Test_TokenTTL(t *testing.T) {
+	clk := clock.NewFrozen(time.Now().UTC())
+
+	tt := []struct {
+		name string
+		ttl  time.Duration
+		exp  time.Duration
+	}{
+		{name: "default", exp: 15 * time.Minute},
+		{name: "configured", ttl: 5 * time.Minute, exp: 5 * time.Minute},
+	}
+
+	for _, tst := range tt {
+		ath, err := auth.New(auth.Config{
+			Log:       newUnit(t),
+			KeyLookup: newKeyStore(t),
+			Issuer:    "service project",
+			TokenTTL:  tst.ttl,
+			Clock:     clk,
+		})
+		if err != nil {
+			t.Fatalf("%s: Should be able to create an authenticator: %s", tst.name, err)
+		}
+
+		claims := ath.NewClaims(userbus.User{ID: uuid.New(), Roles: []userbus.Role{userbus.Roles.User}})
+
+		if got := claims.ExpiresAt.Sub(claims.IssuedAt.Time); got != tst.exp {
+			t.Fatalf("%s: Should get a token that expires after %s: got %s", tst.name, tst.exp, got)
+		}
+	}
+}
+
+func Test_Permissions(t *testing.T) {
+	claims := auth.Claims{
+		Permissions: []string{"home:*", "product:read"},
+	}
+
+	table := []struct {
+		permission string
+		exp        bool
+	}{
+		{"home:write", true},
+		{"home:delete", true},
+		{"product:read", true},
+		{"product:write", false},
+		{"user:read", false},
+		{"not a permission", false},
+	}
+
+	for _, tt := range table {
+		if got := claims.HasPermission(tt.permission); got != tt.exp {
+			t.Errorf("Should get %t for %s, got %t", tt.exp, tt.permission, got)
+		}
+	}
+
+	if !claims.HasAnyPermission("user:read", "product:read") {
+		t.Fatalf("Should grant one of the permissions")
+	}
+
+	admin := auth.Claims{
+		Permissions: []string{"*"},
+	}
+
+	if !admin.HasPermission("user:delete") {
+		t.Fatalf("Should grant every permission for *")
+	}
+}
+
+func Test_TokenPermissions(t *testing.T) {
+	ath, err := auth.New(auth.Config{
+		Log:       newUnit(t),
+		KeyLookup: newKeyStore(t),
+		Issuer:    "service project",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create an authenticator: %s", err)
+	}
+
+	usr := userbus.User{
+		ID:    uuid.New(),
+		Roles: []userbus.Role{userbus.Roles.User},
+	}
+
+	claims := ath.NewClaims(usr)
+	claims.Permissions = []string{"product:write"}
+
+	token, err := ath.GenerateToken(kid, claims)
+	if err != nil {
+		t.Fatalf("Should be able to generate a JWT : %s", err)
+	}
+
+	parsed, err := ath.Authenticate(context.Background(), "Bearer "+token)
+	if err != nil {
+		t.Fatalf("Should be able to authenticate the claims : %s", err)
+	}
+
+	if len(parsed.Permissions) != 0 {
+		t.Fatalf("Should not sign the permissions into the token, got %v", parsed.Permissions)
+	}
+}
+
+func Test_Rotation(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFrozen(time.Now())
+
+	ks := keystore.NewWithClock(clk)
+	if err := ks.LoadKey(kid, privateKeyPEM); err != nil {
+		t.Fatalf("Should be able to load the key: %s", err)
+	}
+
+	// The tokens outlive the rotation so the old key is what stops them.
+	ath, err := auth.New(auth.Config{
+		Log:       newUnit(t),
+		KeyLookup: ks,
+		Issuer:    "service project",
+		TokenTTL:  24 * time.Hour,
+		Clock:     clk,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create an authenticator: %s", err)
+	}
+
+	usr := userbus.User{
+		ID:    uuid.New(),
+		Roles: []userbus.Role{userbus.Roles.User},
+	}
+
+	oldToken, err := ath.GenerateToken(ath.ActiveKID(), ath.NewClaims(usr))
+	if err != nil {
+		t.Fatalf("Should be able to generate a JWT : %s", err)
+	}
+
+	// The new key is published an hour before it starts signing tokens.
+
+	const newKID = "rotated"
+
+	if err := ks.LoadKey(newKID, newPrivatePEM(t)); err != nil {
+		t.Fatalf("Should be able to load the new key: %s", err)
+	}
+
+	if err := ks.SetValidity(newKID, clk.Now().Add(time.Hour), time.Time{}); err != nil {
+		t.Fatalf("Should be able to set the validity of the new key: %s", err)
+	}
+
+	if got := ath.ActiveKID(); got != kid {
+		t.Fatalf("Should keep signing with the old key until the new one is active, got %s", got)
+	}
+
+	if set, _ := ath.KeySet(); len(set.Keys) != 2 {
+		t.Fatalf("Should publish the new key before it's active, got %d keys", len(set.Keys))
+	}
+
+	clk.Advance(2 * time.Hour)
+
+	if got := ath.ActiveKID(); got != newKID {
+		t.Fatalf("Should sign with the newest active key, got %s", got)
+	}
+
+	if err := ks.SetValidity(kid, time.Time{}, clk.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Should be able to set the validity of the old key: %s", err)
+	}
+
+	newToken, err := ath.GenerateToken(ath.ActiveKID(), ath.NewClaims(usr))
+	if err != nil {
+		t.Fatalf("Should be able to generate a JWT with the new key : %s", err)
+	}
+
+	for name, token := range map[string]string{"old": oldToken, "new": newToken} {
+		if _, err := ath.Authenticate(ctx, "Bearer "+token); err != nil {
+			t.Fatalf("Should be able to authenticate the %s token : %s", name, err)
+		}
+	}
+
+	clk.Advance(2 * time.Hour)
+
+	if _, err := ath.Authenticate(ctx, "Bearer "+oldToken); err == nil {
+		t.Fatalf("Should not authenticate a token signed with an expired key")
+	}
+
+	if _, err := ath.GenerateToken(kid, ath.NewClaims(usr)); err == nil {
+		t.Fatalf("Should not sign with an expired key")
+	}
+
+	set, err := ath.KeySet()
+	if err != nil {
+		t.Fatalf("Should be able to get the key set: %s", err)
+	}
+
+	if len(set.Keys) != 1 || set.Keys[0].Kid != newKID || set.Keys[0].Alg != "RS256" {
+		t.Fatalf("Should only publish the keys that haven't expired, got %+v", set.Keys)
+	}
+}
+
 // =============================================================================
 
+func newPrivatePEM(t *testing.T) string {
+	pk, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Should be able to generate a key: %s", err)
+	}
+
+	block := pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(pk),
+	}
+
+	return string(pem.EncodeToMemory(&block))
+}
+
 func newUnit(t *testing.T) *logger.Logger {
 	var buf bytes.Buffer
-	log := logger.New("TEST")
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	log := logger.NewWithHandler(slog.New(handler), logger.Events{}, nil)
 
 	// teardown is the function that should be invoked when the caller is done
 	// with the database.
@@ -276,14 +540,13 @@ func newUnit(t *testing.T) *logger.Logger {
 
 // =============================================================================
 
-type keyStore struct{}
-
-func (ks *keyStore) PrivateKey(kid string) (string, error) {
-	return privateKeyPEM, nil
-}
+func newKeyStore(t *testing.T) *keystore.KeyStore {
+	ks := keystore.New()
+	if err := ks.LoadKey(kid, privateKeyPEM); err != nil {
+		t.Fatalf("Should be able to load the key: %s", err)
+	}
 
-func (ks *keyStore) PublicKey(kid string) (string, error) {
-	return publicKeyPEM, nil
+	return ks
 }
 
 const (
@@ -316,15 +579,5 @@ eMyv0/UCgYEAhX9MPzmTO4+N8naGFof1o8YP97pZj0HkEvM0hTaeAQFKJiwX5ijQ
 xumKGh//G0AYsjqP02ItzOm2mWnbI3FrNlKmGFvR6VxIZMOyXvpLofHucjJ5SWli
 eYjPklKcXaMftt1FVO4n+EKj1k1+Tv14nytq/J5WN+r4FBlNEYj/6vg=
 -----END PRIVATE KEY-----
-`
-	publicKeyPEM = `-----BEGIN PUBLIC KEY-----
-MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEAvMAHb0IoLvoYuW2kA+LT
-mnk+hfnBq1eYIh4CT/rMPCxgtzjqU0guQOMnLg69ydyA5uu37v6rbS1+stuBTEiM
-Ql/bxAhgLkGrUhgpZ10Bt6GzSEgwQNloZoGaxe4p20wMPpT4kcMKNHkQds3uONNc
-LxPUmfjbbH64g+seg28pbgQPwKFKtF7bIsOBgz0g5Ptn5mrkdzqMPUSy9k9VCu+R
-42LH9c75JsRzz4FeN+VzwMAL6yQnZvOi7/zOgNyxeVia8XVKykrnhgcpiOn5oaLR
-BzQGN00Z7TuBRIfDJWU21qQN4Cq7keZmMP4gqCVWjYneK4bzrG/+H2w9BJ2TsmMG
-vwIDAQAB
------END PUBLIC KEY-----
 `
 )
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+)
+
+// KeySet represents a JSON Web Key Set, the format public keys are published
+// in for verifying tokens.
+type KeySet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK represents a public key in a JSON Web Key Set.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func toJWK(kid string, key *rsa.PublicKey) JWK {
+	return JWK{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: "RS256",
+		Kid: kid,
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
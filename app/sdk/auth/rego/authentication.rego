@@ -12,4 +12,5 @@ auth if {
 verify_jwt := io.jwt.decode_verify(input.Token, {
 	"cert": input.Key,
 	"iss": input.ISS,
+	"time": input.Time,
 })
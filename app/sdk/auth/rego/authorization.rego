@@ -10,6 +10,8 @@ default rule_user_only := false
 
 default rule_admin_or_subject := false
 
+default rule_owner_only := false
+
 role_user := "USER"
 
 role_admin := "ADMIN"
@@ -44,3 +46,10 @@ rule_admin_or_subject if {
 	count(input_user) > 0
 	input.UserID == input.Subject
 }
+
+rule_owner_only if {
+	claim_roles := {role | some role in input.Roles}
+	input_roles := role_all & claim_roles
+	count(input_roles) > 0
+	input.UserID == input.Subject
+}
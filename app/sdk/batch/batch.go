@@ -0,0 +1,441 @@
+// Package batch provides support for running a list of operations against
+// the API in one request to reduce round trips for clients.
+//
+// Each operation is dispatched to the endpoint function that handles its
+// method and path, so it runs with the caller's authentication and through
+// the same middleware as a request made on its own. For the same reason
+// each operation commits on its own. StopOnError skips the remaining
+// operations after the first failure.
+//
+// When Transaction is set, the operations share a single transaction and
+// are dispatched to the handlers registered with HandleTx instead, which
+// call the app layer directly with the transaction in the context. The
+// first failure skips the remaining operations and rolls back the ones
+// that succeeded. Only operations with a transaction handler are allowed.
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	eerrs "encore.dev/beta/errs"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/query"
+)
+
+// MaxOperations is the most operations a request can contain.
+const MaxOperations = 20
+
+// Set of statuses reported for each operation.
+const (
+	StatusOK         = "OK"
+	StatusFailed     = "FAILED"
+	StatusSkipped    = "SKIPPED"
+	StatusRolledBack = "ROLLED_BACK"
+)
+
+// errRollback is returned to the transaction function so it rolls back when
+// an operation fails.
+var errRollback = errors.New("operation failed")
+
+// Request represents the list of operations to run in order.
+type Request struct {
+	Operations  []Operation `json:"operations" validate:"required,min=1,max=20,dive"`
+	StopOnError bool        `json:"stopOnError"`
+	Transaction bool        `json:"transaction"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app Request) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
+	}
+
+	return nil
+}
+
+// Operation represents a single request to run. The path can contain a
+// query string for GET and DELETE requests. Headers are for values like
+// If-Match that are read from request headers.
+type Operation struct {
+	Method  string            `json:"method" validate:"required,oneof=GET POST PUT PATCH DELETE"`
+	Path    string            `json:"path" validate:"required,startswith=/"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// Result represents the outcome of a single operation.
+type Result struct {
+	Status     string          `json:"status"`
+	HTTPStatus int             `json:"httpStatus,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	Error      *Error          `json:"error,omitempty"`
+}
+
+// Error represents the error returned by a failed operation.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Response represents the outcome of a batch request with a result for each
+// operation in the order they were requested.
+type Response struct {
+	Results    []Result `json:"results"`
+	Succeeded  int      `json:"succeeded"`
+	Failed     int      `json:"failed"`
+	Skipped    int      `json:"skipped"`
+	RolledBack int      `json:"rolledBack"`
+}
+
+// =============================================================================
+
+// Call is the set of values an operation is dispatched with. The params are
+// the values of the path parameters.
+type Call struct {
+	Params  map[string]string
+	Query   url.Values
+	Headers map[string]string
+	Body    json.RawMessage
+}
+
+// Decode decodes the operation into the request type of an endpoint. The
+// body is used for the JSON fields, the query string for GET and DELETE
+// requests, and the headers for fields with a header tag. The value is
+// validated the way Encore validates a request, since the handlers run in
+// a transaction call the app layer directly.
+func (c Call) Decode(v any) error {
+	if err := c.decode(v); err != nil {
+		return err
+	}
+
+	if vd, ok := v.(validator); ok {
+		return vd.Validate()
+	}
+
+	return nil
+}
+
+// validator is implemented by request types that validate themselves.
+type validator interface {
+	Validate() error
+}
+
+func (c Call) decode(v any) error {
+	if len(c.Body) > 0 {
+		if err := json.Unmarshal(c.Body, v); err != nil {
+			return errs.Newf(errs.InvalidArgument, "decode body: %s", err)
+		}
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		f := rt.Field(i)
+		if !f.IsExported() || f.Type.Kind() != reflect.String {
+			continue
+		}
+
+		if name := f.Tag.Get("header"); name != "" {
+			if value, exists := header(c.Headers, name); exists {
+				rv.Field(i).SetString(value)
+			}
+			continue
+		}
+
+		if c.Query.Has(query.ParamName(f)) {
+			rv.Field(i).SetString(c.Query.Get(query.ParamName(f)))
+		}
+	}
+
+	return nil
+}
+
+func header(headers map[string]string, name string) (string, bool) {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+
+	return "", false
+}
+
+// HandlerFunc runs an operation and returns the response of the endpoint.
+type HandlerFunc func(ctx context.Context, call Call) (any, error)
+
+// Endpoint adapts an endpoint that takes a request value.
+func Endpoint[Req any, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) HandlerFunc {
+	return func(ctx context.Context, call Call) (any, error) {
+		var req Req
+		if err := call.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return fn(ctx, req)
+	}
+}
+
+// EndpointByParam adapts an endpoint that only takes a path parameter.
+func EndpointByParam[Resp any](param string, fn func(ctx context.Context, id string) (Resp, error)) HandlerFunc {
+	return func(ctx context.Context, call Call) (any, error) {
+		return fn(ctx, call.Params[param])
+	}
+}
+
+// EndpointWithParam adapts an endpoint that takes a path parameter and a
+// request value.
+func EndpointWithParam[Req any, Resp any](param string, fn func(ctx context.Context, id string, req Req) (Resp, error)) HandlerFunc {
+	return func(ctx context.Context, call Call) (any, error) {
+		var req Req
+		if err := call.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return fn(ctx, call.Params[param], req)
+	}
+}
+
+// EndpointNoContent adapts an endpoint that takes a path parameter and a
+// request value and doesn't return a response.
+func EndpointNoContent[Req any](param string, fn func(ctx context.Context, id string, req Req) error) HandlerFunc {
+	return func(ctx context.Context, call Call) (any, error) {
+		var req Req
+		if err := call.Decode(&req); err != nil {
+			return nil, err
+		}
+
+		return nil, fn(ctx, call.Params[param], req)
+	}
+}
+
+type route struct {
+	method   string
+	segments []string
+	handler  HandlerFunc
+}
+
+// TxFunc runs fn in a single transaction, calling it with a context that
+// carries the transaction. The transaction is committed when fn returns nil
+// and rolled back otherwise.
+type TxFunc func(ctx context.Context, fn func(ctx context.Context) error) error
+
+// Router dispatches operations to the endpoints that handle them.
+type Router struct {
+	routes   []route
+	txRoutes []route
+	tx       TxFunc
+}
+
+// NewRouter constructs a router for dispatching operations. The tx function
+// runs the operations of a request that asks for a transaction, which are
+// rejected when it's nil.
+func NewRouter(tx TxFunc) *Router {
+	return &Router{
+		tx: tx,
+	}
+}
+
+// Handle registers the handler for a method and path. Path parameters are
+// written like Encore paths, /v1/products/:productID.
+func (r *Router) Handle(method string, path string, handler HandlerFunc) {
+	r.routes = append(r.routes, newRoute(method, path, handler))
+}
+
+// HandleTx registers the handler for a method and path that is used when
+// the operations share a transaction. The handler is called with the
+// context from the tx function and must apply the authorization the
+// middleware applies to the endpoint.
+func (r *Router) HandleTx(method string, path string, handler HandlerFunc) {
+	r.txRoutes = append(r.txRoutes, newRoute(method, path, handler))
+}
+
+func newRoute(method string, path string, handler HandlerFunc) route {
+	return route{
+		method:   method,
+		segments: strings.Split(strings.Trim(path, "/"), "/"),
+		handler:  handler,
+	}
+}
+
+func match(routes []route, method string, path string) (HandlerFunc, map[string]string, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+next:
+	for _, rt := range routes {
+		if rt.method != method || len(rt.segments) != len(segments) {
+			continue
+		}
+
+		params := make(map[string]string)
+		for i, seg := range rt.segments {
+			switch {
+			case strings.HasPrefix(seg, ":"):
+				params[seg[1:]] = segments[i]
+			case seg != segments[i]:
+				continue next
+			}
+		}
+
+		return rt.handler, params, true
+	}
+
+	return nil, nil, false
+}
+
+// Execute runs the operations in order and returns the result of each. An
+// error is only returned when the operations can't run in a transaction or
+// the transaction fails.
+func (r *Router) Execute(ctx context.Context, req Request) (Response, error) {
+	if req.Transaction {
+		return r.executeTx(ctx, req)
+	}
+
+	return r.run(ctx, r.routes, req.Operations, req.StopOnError), nil
+}
+
+// executeTx runs the operations in a transaction that is rolled back when
+// one of them fails. Every operation is checked before the transaction is
+// started so an operation that can't be part of it doesn't cost one.
+func (r *Router) executeTx(ctx context.Context, req Request) (Response, error) {
+	if r.tx == nil {
+		return Response{}, errs.Newf(errs.InvalidArgument, "operations can't run in a transaction")
+	}
+
+	for i, op := range req.Operations {
+		path, _, _ := strings.Cut(op.Path, "?")
+		if _, _, exists := match(r.txRoutes, op.Method, path); !exists {
+			return Response{}, errs.Newf(errs.InvalidArgument, "operation[%d] %s %s can't run in a transaction", i, op.Method, path)
+		}
+	}
+
+	var resp Response
+	err := r.tx(ctx, func(ctx context.Context) error {
+		resp = r.run(ctx, r.txRoutes, req.Operations, true)
+		if resp.Failed > 0 {
+			return errRollback
+		}
+
+		return nil
+	})
+
+	switch {
+	case errors.Is(err, errRollback):
+		for i := range resp.Results {
+			if resp.Results[i].Status == StatusOK {
+				resp.Results[i].Status = StatusRolledBack
+			}
+		}
+		resp.RolledBack = resp.Succeeded
+		resp.Succeeded = 0
+
+	case err != nil:
+		return Response{}, err
+	}
+
+	return resp, nil
+}
+
+func (r *Router) run(ctx context.Context, routes []route, ops []Operation, stopOnError bool) Response {
+	resp := Response{
+		Results: make([]Result, 0, len(ops)),
+	}
+
+	for _, op := range ops {
+		if stopOnError && resp.Failed > 0 {
+			resp.Results = append(resp.Results, Result{Status: StatusSkipped})
+			resp.Skipped++
+			continue
+		}
+
+		result := execute(ctx, routes, op)
+		switch result.Status {
+		case StatusOK:
+			resp.Succeeded++
+		default:
+			resp.Failed++
+		}
+
+		resp.Results = append(resp.Results, result)
+	}
+
+	return resp
+}
+
+func execute(ctx context.Context, routes []route, op Operation) Result {
+	path, rawQuery, _ := strings.Cut(op.Path, "?")
+
+	handler, params, exists := match(routes, op.Method, path)
+	if !exists {
+		return failed(errs.Newf(errs.NotFound, "no endpoint for %s %s", op.Method, path))
+	}
+
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return failed(errs.Newf(errs.InvalidArgument, "parse query: %s", err))
+	}
+
+	call := Call{
+		Params:  params,
+		Query:   values,
+		Headers: op.Headers,
+		Body:    op.Body,
+	}
+
+	v, err := handler(ctx, call)
+	if err != nil {
+		return failed(err)
+	}
+
+	result := Result{
+		Status:     StatusOK,
+		HTTPStatus: http.StatusNoContent,
+	}
+
+	if v != nil {
+		result.HTTPStatus = http.StatusOK
+
+		data, err := json.Marshal(v)
+		if err != nil {
+			return failed(fmt.Errorf("encode response: %w", err))
+		}
+		result.Body = data
+	}
+
+	return result
+}
+
+func failed(err error) Result {
+	var code eerrs.ErrCode
+	var msg string
+
+	var eerr *eerrs.Error
+	switch {
+	case errors.As(err, &eerr):
+		code = eerr.Code
+		msg = eerr.Message
+
+	default:
+		code = errs.Unknown
+		msg = err.Error()
+	}
+
+	return Result{
+		Status:     StatusFailed,
+		HTTPStatus: code.HTTPStatus(),
+		Error: &Error{
+			Code:    code.String(),
+			Message: msg,
+		},
+	}
+}
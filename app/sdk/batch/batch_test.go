@@ -0,0 +1,226 @@
+package batch_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	eerrs "encore.dev/beta/errs"
+	"github.com/ardanlabs/encore/app/sdk/batch"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+)
+
+type newItem struct {
+	Name string `json:"name"`
+}
+
+type updateItem struct {
+	Name    string `json:"name"`
+	IfMatch string `header:"If-Match"`
+}
+
+type queryParams struct {
+	Page    string
+	OrderBy string
+}
+
+type item struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	IfMatch string `json:"ifMatch,omitempty"`
+}
+
+// tx records the outcome of the transaction a batch runs in.
+type tx struct {
+	committed  bool
+	rolledBack bool
+}
+
+func (t *tx) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		t.rolledBack = true
+		return err
+	}
+
+	t.committed = true
+	return nil
+}
+
+func newRouter(t *tx) *batch.Router {
+	r := batch.NewRouter(t.run)
+
+	r.Handle(http.MethodPost, "/v1/items", batch.Endpoint(func(ctx context.Context, app newItem) (item, error) {
+		if app.Name == "" {
+			return item{}, errs.Newf(errs.InvalidArgument, "name is required")
+		}
+		return item{ID: "1", Name: app.Name}, nil
+	}))
+
+	r.Handle(http.MethodPut, "/v1/items/:itemID", batch.EndpointWithParam("itemID", func(ctx context.Context, itemID string, app updateItem) (item, error) {
+		return item{ID: itemID, Name: app.Name, IfMatch: app.IfMatch}, nil
+	}))
+
+	r.Handle(http.MethodGet, "/v1/items", batch.Endpoint(func(ctx context.Context, qp queryParams) (item, error) {
+		return item{ID: qp.Page, Name: qp.OrderBy}, nil
+	}))
+
+	r.Handle(http.MethodDelete, "/v1/items/:itemID", batch.EndpointNoContent("itemID", func(ctx context.Context, itemID string, app struct{}) error {
+		return errors.New("boom")
+	}))
+
+	r.HandleTx(http.MethodPost, "/v1/items", batch.Endpoint(func(ctx context.Context, app newItem) (item, error) {
+		if app.Name == "" {
+			return item{}, errs.Newf(errs.InvalidArgument, "name is required")
+		}
+		return item{ID: "1", Name: app.Name}, nil
+	}))
+
+	return r
+}
+
+func Test_Execute(t *testing.T) {
+	req := batch.Request{
+		Operations: []batch.Operation{
+			{Method: http.MethodPost, Path: "/v1/items", Body: json.RawMessage(`{"name":"a"}`)},
+			{Method: http.MethodPut, Path: "/v1/items/7", Headers: map[string]string{"if-match": "v1"}, Body: json.RawMessage(`{"name":"b"}`)},
+			{Method: http.MethodGet, Path: "/v1/items?page=2&order_by=name"},
+			{Method: http.MethodGet, Path: "/v1/missing"},
+			{Method: http.MethodPost, Path: "/v1/items", Body: json.RawMessage(`{}`)},
+		},
+	}
+
+	resp, err := newRouter(&tx{}).Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Should be able to execute the batch: %s", err)
+	}
+
+	if resp.Succeeded != 3 || resp.Failed != 2 || resp.Skipped != 0 {
+		t.Fatalf("Should get 3 succeeded and 2 failed: got %d/%d/%d", resp.Succeeded, resp.Failed, resp.Skipped)
+	}
+
+	bodies := []string{
+		`{"id":"1","name":"a"}`,
+		`{"id":"7","name":"b","ifMatch":"v1"}`,
+		`{"id":"2","name":"name"}`,
+	}
+
+	for i, exp := range bodies {
+		if got := string(resp.Results[i].Body); got != exp {
+			t.Fatalf("%d: Should get %s: got %s", i, exp, got)
+		}
+	}
+
+	if res := resp.Results[3]; res.HTTPStatus != http.StatusNotFound || res.Error.Code != "not_found" {
+		t.Fatalf("Should get not found for an unknown path: got %d %+v", res.HTTPStatus, res.Error)
+	}
+
+	if res := resp.Results[4]; res.HTTPStatus != http.StatusBadRequest || res.Error.Message != "name is required" {
+		t.Fatalf("Should get the endpoint error: got %d %+v", res.HTTPStatus, res.Error)
+	}
+}
+
+func Test_ExecuteStopOnError(t *testing.T) {
+	req := batch.Request{
+		StopOnError: true,
+		Operations: []batch.Operation{
+			{Method: http.MethodDelete, Path: "/v1/items/1"},
+			{Method: http.MethodPost, Path: "/v1/items", Body: json.RawMessage(`{"name":"a"}`)},
+		},
+	}
+
+	resp, err := newRouter(&tx{}).Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Should be able to execute the batch: %s", err)
+	}
+
+	if resp.Failed != 1 || resp.Skipped != 1 {
+		t.Fatalf("Should skip the operations after a failure: got %d/%d", resp.Failed, resp.Skipped)
+	}
+
+	if res := resp.Results[0]; res.Error.Code != "unknown" || res.Error.Message != "boom" {
+		t.Fatalf("Should report a go error as unknown: got %+v", res.Error)
+	}
+
+	if res := resp.Results[1]; res.Status != batch.StatusSkipped {
+		t.Fatalf("Should skip the second operation: got %s", res.Status)
+	}
+}
+
+func Test_ExecuteTransaction(t *testing.T) {
+	req := batch.Request{
+		Transaction: true,
+		Operations: []batch.Operation{
+			{Method: http.MethodPost, Path: "/v1/items", Body: json.RawMessage(`{"name":"a"}`)},
+			{Method: http.MethodPost, Path: "/v1/items", Body: json.RawMessage(`{"name":"b"}`)},
+		},
+	}
+
+	var tr tx
+
+	resp, err := newRouter(&tr).Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Should be able to execute the batch: %s", err)
+	}
+
+	if !tr.committed || resp.Succeeded != 2 {
+		t.Fatalf("Should commit the operations: got %t %d", tr.committed, resp.Succeeded)
+	}
+}
+
+func Test_ExecuteTransactionRollback(t *testing.T) {
+	req := batch.Request{
+		Transaction: true,
+		Operations: []batch.Operation{
+			{Method: http.MethodPost, Path: "/v1/items", Body: json.RawMessage(`{"name":"a"}`)},
+			{Method: http.MethodPost, Path: "/v1/items", Body: json.RawMessage(`{}`)},
+			{Method: http.MethodPost, Path: "/v1/items", Body: json.RawMessage(`{"name":"c"}`)},
+		},
+	}
+
+	var tr tx
+
+	resp, err := newRouter(&tr).Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Should be able to execute the batch: %s", err)
+	}
+
+	if !tr.rolledBack {
+		t.Fatal("Should roll back the transaction")
+	}
+
+	if resp.Succeeded != 0 || resp.RolledBack != 1 || resp.Failed != 1 || resp.Skipped != 1 {
+		t.Fatalf("Should get 1 rolled back, 1 failed and 1 skipped: got %d/%d/%d/%d", resp.Succeeded, resp.RolledBack, resp.Failed, resp.Skipped)
+	}
+
+	statuses := []string{batch.StatusRolledBack, batch.StatusFailed, batch.StatusSkipped}
+	for i, exp := range statuses {
+		if got := resp.Results[i].Status; got != exp {
+			t.Fatalf("%d: Should get %s: got %s", i, exp, got)
+		}
+	}
+}
+
+func Test_ExecuteTransactionUnsupported(t *testing.T) {
+	req := batch.Request{
+		Transaction: true,
+		Operations: []batch.Operation{
+			{Method: http.MethodPost, Path: "/v1/items", Body: json.RawMessage(`{"name":"a"}`)},
+			{Method: http.MethodGet, Path: "/v1/items"},
+		},
+	}
+
+	var tr tx
+
+	_, err := newRouter(&tr).Execute(context.Background(), req)
+
+	var eerr *eerrs.Error
+	if !errors.As(err, &eerr) || eerr.Code != eerrs.InvalidArgument {
+		t.Fatalf("Should reject an operation without a transaction handler: got %v", err)
+	}
+
+	if tr.committed || tr.rolledBack {
+		t.Fatal("Should not start a transaction")
+	}
+}
@@ -0,0 +1,54 @@
+// Package bodylimit provides support for limiting the size of request bodies
+// per endpoint.
+package bodylimit
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// TagLarge is the endpoint tag for endpoints that accept large bodies, like
+// bulk operations and imports.
+const TagLarge = "body_large"
+
+// ErrTooLarge is returned when a request body is larger than the limit for
+// the endpoint.
+var ErrTooLarge = errors.New("request body too large")
+
+// Limits represents the maximum number of bytes a request body can have.
+type Limits struct {
+	Default int64
+	Large   int64
+}
+
+// For returns the limit for an endpoint with the specified tags.
+func (l Limits) For(tags []string) int64 {
+	for _, tag := range tags {
+		if tag == TagLarge {
+			return l.Large
+		}
+	}
+
+	return l.Default
+}
+
+// Check validates the Content-Length header against the limit. A request
+// without the header, like a chunked request, can't be checked and is
+// allowed.
+func Check(limit int64, contentLength string) error {
+	if contentLength == "" {
+		return nil
+	}
+
+	n, err := strconv.ParseInt(contentLength, 10, 64)
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid content length %q", contentLength)
+	}
+
+	if n > limit {
+		return fmt.Errorf("%w: %d bytes exceeds the limit of %d bytes for this endpoint", ErrTooLarge, n, limit)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package bodylimit_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ardanlabs/encore/app/sdk/bodylimit"
+)
+
+func Test_For(t *testing.T) {
+	limits := bodylimit.Limits{Default: 10, Large: 100}
+
+	if got := limits.For([]string{"metrics"}); got != 10 {
+		t.Fatalf("Should get the default limit: got %d", got)
+	}
+
+	if got := limits.For([]string{"metrics", bodylimit.TagLarge}); got != 100 {
+		t.Fatalf("Should get the large limit: got %d", got)
+	}
+}
+
+func Test_Check(t *testing.T) {
+	tests := []struct {
+		contentLength string
+		tooLarge      bool
+		invalid       bool
+	}{
+		{"", false, false},
+		{"10", false, false},
+		{"11", true, false},
+		{"abc", false, true},
+		{"-1", false, true},
+	}
+
+	for _, tt := range tests {
+		err := bodylimit.Check(10, tt.contentLength)
+
+		switch {
+		case tt.tooLarge:
+			if !errors.Is(err, bodylimit.ErrTooLarge) {
+				t.Fatalf("%q: Should get a too large error: %v", tt.contentLength, err)
+			}
+
+		case tt.invalid:
+			if err == nil || errors.Is(err, bodylimit.ErrTooLarge) {
+				t.Fatalf("%q: Should get an invalid error: %v", tt.contentLength, err)
+			}
+
+		default:
+			if err != nil {
+				t.Fatalf("%q: Should be within the limit: %s", tt.contentLength, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Package bulk provides support for requests that act on many items at once.
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/google/uuid"
+)
+
+// ErrForbidden is reported for an item the caller isn't allowed to change.
+var ErrForbidden = errors.New("not authorized to change this item")
+
+// Set of statuses reported for each item.
+const (
+	StatusDeleted   = "DELETED"
+	StatusInvalid   = "INVALID"
+	StatusNotFound  = "NOT_FOUND"
+	StatusForbidden = "FORBIDDEN"
+	StatusModified  = "MODIFIED"
+)
+
+// AuthorizeFunc checks the caller is allowed to change an item owned by the
+// specified user. The authorization middleware can't do this for a request
+// that acts on many items.
+type AuthorizeFunc func(ctx context.Context, userID uuid.UUID) error
+
+// IDs represents the list of item ids a bulk request acts on. An entity tag
+// can be given for an id, so the item is only changed if it hasn't been
+// modified since the tag was read, like If-Match does for a single item.
+// Items without an entity tag are changed unconditionally.
+type IDs struct {
+	IDs   []string          `json:"ids" validate:"required,min=1,max=100"`
+	ETags map[string]string `json:"etags" validate:"max=100"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app IDs) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.NewFieldErrors(fmt.Errorf("validate: %w", err))
+	}
+
+	return nil
+}
+
+// Item represents what happened to a single item in a bulk request.
+type Item struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Result represents the outcome of a bulk request with an entry for each
+// item in the order they were requested.
+type Result struct {
+	Items     []Item `json:"items"`
+	Succeeded int    `json:"succeeded"`
+	Failed    int    `json:"failed"`
+}
+
+// Add records the outcome for the specified item. A nil error means the
+// operation on the item succeeded.
+func (r *Result) Add(id string, status string, err error) {
+	item := Item{
+		ID:     id,
+		Status: status,
+	}
+
+	switch err {
+	case nil:
+		r.Succeeded++
+	default:
+		item.Error = err.Error()
+		r.Failed++
+	}
+
+	r.Items = append(r.Items, item)
+}
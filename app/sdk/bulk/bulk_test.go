@@ -0,0 +1,51 @@
+package bulk_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/ardanlabs/encore/app/sdk/bulk"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Result(t *testing.T) {
+	var result bulk.Result
+
+	result.Add("1", bulk.StatusDeleted, nil)
+	result.Add("2", bulk.StatusNotFound, errors.New("not found"))
+	result.Add("3", bulk.StatusDeleted, nil)
+
+	exp := bulk.Result{
+		Items: []bulk.Item{
+			{ID: "1", Status: bulk.StatusDeleted},
+			{ID: "2", Status: bulk.StatusNotFound, Error: "not found"},
+			{ID: "3", Status: bulk.StatusDeleted},
+		},
+		Succeeded: 2,
+		Failed:    1,
+	}
+
+	if diff := cmp.Diff(result, exp); diff != "" {
+		t.Fatalf("Should get an entry for each item in order: %s", diff)
+	}
+}
+
+func Test_Validate(t *testing.T) {
+	app := bulk.IDs{
+		IDs:   []string{"1"},
+		ETags: map[string]string{},
+	}
+
+	if err := app.Validate(); err != nil {
+		t.Fatalf("Should be able to validate the ids: %s", err)
+	}
+
+	for i := range 101 {
+		app.ETags[strconv.Itoa(i)] = `"tag"`
+	}
+
+	if err := app.Validate(); err == nil {
+		t.Fatalf("Should get an error for too many entity tags")
+	}
+}
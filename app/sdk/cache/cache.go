@@ -0,0 +1,142 @@
+// Package cache provides support for caching idempotent API responses.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/encore/business/sdk/delegate"
+	"github.com/viccon/sturdyc"
+)
+
+// Cache maintains a short lived cache of API responses.
+type Cache struct {
+	client *sturdyc.Client[any]
+}
+
+// New constructs a response cache where entries live for the specified ttl.
+func New(ttl time.Duration) *Cache {
+	const capacity = 10000
+	const numShards = 10
+	const evictionPercentage = 10
+
+	return &Cache{
+		client: sturdyc.New[any](capacity, numShards, ttl, evictionPercentage),
+	}
+}
+
+// Key constructs the cache key for a request. The key starts with the path
+// so all the responses for a path can be invalidated together. The roles are
+// sorted so the order the roles are stored in the claims doesn't matter.
+func Key(path string, query any, roles []string) string {
+	r := make([]string, len(roles))
+	copy(r, roles)
+	sort.Strings(r)
+
+	return fmt.Sprintf("%s?%+v#%s", path, query, strings.Join(r, ","))
+}
+
+// Get returns the cached response for the specified key.
+func (c *Cache) Get(key string) (any, bool) {
+	return c.client.Get(key)
+}
+
+// Set stores the response for the specified key.
+func (c *Cache) Set(key string, resp any) {
+	c.client.Set(key, resp)
+}
+
+// Ping returns nil if a response can be stored and read back from the cache.
+func (c *Cache) Ping(ctx context.Context) error {
+	const key = "/health/ping"
+
+	c.client.Set(key, true)
+	defer c.client.Delete(key)
+
+	if _, exists := c.client.Get(key); !exists {
+		return errors.New("unable to read back from the cache")
+	}
+
+	return nil
+}
+
+// Invalidate removes all the cached responses for paths that begin with
+// any of the specified prefixes.
+func (c *Cache) Invalidate(prefixes ...string) {
+	for _, key := range c.client.ScanKeys() {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(key, prefix) {
+				c.client.Delete(key)
+				break
+			}
+		}
+	}
+}
+
+// Flush removes all the cached responses.
+func (c *Cache) Flush() {
+	for _, key := range c.client.ScanKeys() {
+		c.client.Delete(key)
+	}
+}
+
+// InvalidateFunc returns a delegate function that invalidates the cached
+// responses for the specified prefixes. This allows the cache to be cleared
+// when a business domain reports a mutation.
+func (c *Cache) InvalidateFunc(prefixes ...string) delegate.Func {
+	return func(ctx context.Context, data delegate.Data) error {
+		c.Invalidate(prefixes...)
+		return nil
+	}
+}
+
+// =============================================================================
+
+// Entity describes the cached responses a kind of entity appears in, so a
+// change to one entity only invalidates the responses that can hold it.
+type Entity struct {
+	IDField string   // Name of the entity's ID in the delegate parameters.
+	Paths   []string // Paths the entity is read from as path/{id}.
+	Lists   []string // Paths of the lists the entity can appear in.
+}
+
+// InvalidateEntity removes the cached responses for the entity with the
+// specified ID and every page of the lists it can appear in.
+func (c *Cache) InvalidateEntity(e Entity, id string) {
+	prefixes := make([]string, 0, len(e.Paths)+len(e.Lists))
+
+	for _, path := range e.Paths {
+		prefixes = append(prefixes, path+"/"+id+"?")
+	}
+
+	for _, list := range e.Lists {
+		prefixes = append(prefixes, list+"?")
+	}
+
+	c.Invalidate(prefixes...)
+}
+
+// InvalidateEntityFunc returns a delegate function that invalidates the
+// cached responses for the entity a business domain reports a mutation for.
+func (c *Cache) InvalidateEntityFunc(e Entity) delegate.Func {
+	return func(ctx context.Context, data delegate.Data) error {
+		var params map[string]any
+		if err := json.Unmarshal(data.RawParams, &params); err != nil {
+			return fmt.Errorf("unmarshal: %w", err)
+		}
+
+		id, ok := params[e.IDField].(string)
+		if !ok {
+			return fmt.Errorf("%s: missing from %s %s params", e.IDField, data.Domain, data.Action)
+		}
+
+		c.InvalidateEntity(e, id)
+
+		return nil
+	}
+}
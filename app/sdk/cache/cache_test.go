@@ -0,0 +1,103 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/encore/app/sdk/cache"
+	"github.com/ardanlabs/encore/business/sdk/delegate"
+)
+
+func Test_Cache(t *testing.T) {
+	c := cache.New(time.Minute)
+
+	prdKey := cache.Key("/v1/products", struct{ Page string }{"1"}, []string{"USER", "ADMIN"})
+	usrKey := cache.Key("/v1/users", struct{ Page string }{"1"}, []string{"ADMIN"})
+
+	if prdKey != cache.Key("/v1/products", struct{ Page string }{"1"}, []string{"ADMIN", "USER"}) {
+		t.Fatalf("Should get the same key regardless of role order")
+	}
+
+	if prdKey == cache.Key("/v1/products", struct{ Page string }{"2"}, []string{"ADMIN", "USER"}) {
+		t.Fatalf("Should get a different key for a different query")
+	}
+
+	if err := c.Ping(context.Background()); err != nil {
+		t.Fatalf("Should be able to ping the cache: %s", err)
+	}
+
+	c.Set(prdKey, "products")
+	c.Set(usrKey, "users")
+
+	if v, exists := c.Get(prdKey); !exists || v != "products" {
+		t.Fatalf("Should get the cached products response: %v", v)
+	}
+
+	if err := c.InvalidateFunc("/v1/products")(context.Background(), delegate.Data{}); err != nil {
+		t.Fatalf("Should be able to invalidate: %s", err)
+	}
+
+	if _, exists := c.Get(prdKey); exists {
+		t.Fatalf("Should not get the products response after invalidation")
+	}
+
+	if _, exists := c.Get(usrKey); !exists {
+		t.Fatalf("Should still get the users response after invalidation")
+	}
+
+	c.Flush()
+
+	if _, exists := c.Get(usrKey); exists {
+		t.Fatalf("Should not get the users response after a flush")
+	}
+}
+
+func Test_InvalidateEntity(t *testing.T) {
+	c := cache.New(time.Minute)
+
+	const id = "5cf37266-3473-4006-984f-9325122678b7"
+	const other = "45b5fbd3-755f-4379-8f07-a58d4a30fa2f"
+
+	prdKey := cache.Key("/v1/products/"+id, nil, []string{"USER"})
+	otherKey := cache.Key("/v1/products/"+other, nil, []string{"USER"})
+	listKey := cache.Key("/v1/products", struct{ Page string }{"1"}, []string{"USER"})
+	usrKey := cache.Key("/v1/users", struct{ Page string }{"1"}, []string{"USER"})
+
+	for _, key := range []string{prdKey, otherKey, listKey, usrKey} {
+		c.Set(key, key)
+	}
+
+	e := cache.Entity{
+		IDField: "ProductID",
+		Paths:   []string{"/v1/products"},
+		Lists:   []string{"/v1/products"},
+	}
+
+	data := delegate.Data{
+		Domain:    "product",
+		Action:    "updated",
+		RawParams: []byte(`{"ProductID":"` + id + `"}`),
+	}
+
+	if err := c.InvalidateEntityFunc(e)(context.Background(), data); err != nil {
+		t.Fatalf("Should be able to invalidate the entity: %s", err)
+	}
+
+	for _, key := range []string{prdKey, listKey} {
+		if _, exists := c.Get(key); exists {
+			t.Fatalf("Should not get %s after invalidation", key)
+		}
+	}
+
+	for _, key := range []string{otherKey, usrKey} {
+		if _, exists := c.Get(key); !exists {
+			t.Fatalf("Should still get %s after invalidation", key)
+		}
+	}
+
+	data.RawParams = []byte(`{"UserID":"` + id + `"}`)
+	if err := c.InvalidateEntityFunc(e)(context.Background(), data); err == nil {
+		t.Fatalf("Should not be able to invalidate without the entity ID")
+	}
+}
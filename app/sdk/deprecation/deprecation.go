@@ -0,0 +1,81 @@
+// Package deprecation provides support for declaring the endpoints that are
+// deprecated so clients are told before an endpoint is removed and its usage
+// can be tracked to decide when it's safe to remove.
+package deprecation
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Set of response headers used to tell clients an endpoint is deprecated.
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+	HeaderLink        = "Link"
+)
+
+// Endpoint declares an endpoint as deprecated. The name is the name of the
+// endpoint function. The successor is the path of the endpoint clients
+// should move to.
+type Endpoint struct {
+	Name      string
+	Since     time.Time
+	Sunset    time.Time
+	Successor string
+}
+
+// Notice returns the values of the response headers for the endpoint.
+func (e Endpoint) Notice() Notice {
+	var n Notice
+
+	if !e.Since.IsZero() {
+		n.Deprecation = fmt.Sprintf("@%d", e.Since.Unix())
+	}
+
+	if !e.Sunset.IsZero() {
+		n.Sunset = e.Sunset.UTC().Format(http.TimeFormat)
+	}
+
+	if e.Successor != "" {
+		n.Link = fmt.Sprintf("<%s>; rel=\"successor-version\"", e.Successor)
+	}
+
+	return n
+}
+
+// Notice represents the values of the response headers for a deprecated
+// endpoint. Encore middleware can't set response headers so response types
+// carry these values in fields with a header tag.
+type Notice struct {
+	Deprecation string
+	Sunset      string
+	Link        string
+}
+
+// =============================================================================
+
+// Registry holds the set of deprecated endpoints.
+type Registry struct {
+	endpoints map[string]Endpoint
+}
+
+// New constructs a registry for the specified endpoints.
+func New(endpoints ...Endpoint) *Registry {
+	r := Registry{
+		endpoints: make(map[string]Endpoint, len(endpoints)),
+	}
+
+	for _, e := range endpoints {
+		r.endpoints[e.Name] = e
+	}
+
+	return &r
+}
+
+// Lookup returns the deprecation for the named endpoint if it's deprecated.
+func (r *Registry) Lookup(name string) (Endpoint, bool) {
+	e, exists := r.endpoints[name]
+	return e, exists
+}
@@ -0,0 +1,40 @@
+package deprecation_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/encore/app/sdk/deprecation"
+)
+
+func Test_Registry(t *testing.T) {
+	reg := deprecation.New(deprecation.Endpoint{
+		Name:      "ProductCreate",
+		Since:     time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC),
+		Sunset:    time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC),
+		Successor: "/v2/products",
+	})
+
+	if _, exists := reg.Lookup("ProductQuery"); exists {
+		t.Fatalf("Should not find an endpoint that isn't deprecated")
+	}
+
+	e, exists := reg.Lookup("ProductCreate")
+	if !exists {
+		t.Fatalf("Should find the deprecated endpoint")
+	}
+
+	exp := deprecation.Notice{
+		Deprecation: "@1790812800",
+		Sunset:      "Thu, 01 Apr 2027 00:00:00 GMT",
+		Link:        `</v2/products>; rel="successor-version"`,
+	}
+
+	if got := e.Notice(); got != exp {
+		t.Fatalf("Should get the notice:\nexp: %+v\ngot: %+v", exp, got)
+	}
+
+	if got := (deprecation.Endpoint{Name: "X"}).Notice(); got != (deprecation.Notice{}) {
+		t.Fatalf("Should get an empty notice without dates or a successor: got %+v", got)
+	}
+}
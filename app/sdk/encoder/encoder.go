@@ -0,0 +1,180 @@
+// Package encoder provides support for writing responses in the format the
+// client asks for with the Accept header. JSON is the default and the other
+// formats are built from the JSON encoding of the value, so any model that
+// can be written as JSON can be written in every registered format without
+// code of its own.
+package encoder
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrNotAcceptable is returned when none of the registered formats are
+// accepted by the client.
+var ErrNotAcceptable = errors.New("no acceptable format")
+
+// Encoder is the interface models implement to encode themselves as JSON.
+type Encoder interface {
+	Encode() ([]byte, string, error)
+}
+
+// Format represents a media type and how to encode a value as it.
+type Format struct {
+	ContentType string
+	Encode      func(v any) ([]byte, error)
+}
+
+// Registry maintains the set of formats a response can be written in.
+type Registry struct {
+	def     Format
+	formats map[string]Format
+}
+
+// New constructs a registry where the default format is used when the
+// client doesn't ask for a specific format.
+func New(def Format, formats ...Format) *Registry {
+	r := Registry{
+		def:     def,
+		formats: make(map[string]Format),
+	}
+
+	r.Register(def)
+	for _, f := range formats {
+		r.Register(f)
+	}
+
+	return &r
+}
+
+// Default is the registry shared by the handlers with JSON as the default
+// and CSV and XML available.
+var Default = New(JSON, CSV, XML)
+
+// Register adds a format to the registry, replacing any format for the same
+// content type.
+func (r *Registry) Register(f Format) {
+	r.formats[f.ContentType] = f
+}
+
+// Negotiate returns the format for the Accept header. The media types are
+// tried from the highest quality down and a wildcard matches the default.
+func (r *Registry) Negotiate(accept string) (Format, error) {
+	if strings.TrimSpace(accept) == "" {
+		return r.def, nil
+	}
+
+	for _, mediaType := range parseAccept(accept) {
+		switch {
+		case mediaType == "*/*":
+			return r.def, nil
+
+		case strings.HasSuffix(mediaType, "/*"):
+			prefix := strings.TrimSuffix(mediaType, "*")
+			if strings.HasPrefix(r.def.ContentType, prefix) {
+				return r.def, nil
+			}
+
+			for _, ct := range r.contentTypes() {
+				if strings.HasPrefix(ct, prefix) {
+					return r.formats[ct], nil
+				}
+			}
+
+		default:
+			if f, exists := r.formats[mediaType]; exists {
+				return f, nil
+			}
+		}
+	}
+
+	return Format{}, ErrNotAcceptable
+}
+
+// Write encodes the value in the format the request asks for and writes it
+// with the status code. A request that doesn't accept any of the formats
+// gets a 406.
+func (r *Registry) Write(w http.ResponseWriter, req *http.Request, status int, v any) {
+	f, err := r.Negotiate(req.Header.Get("Accept"))
+	if err != nil {
+		http.Error(w, "acceptable formats: "+strings.Join(r.contentTypes(), ", "), http.StatusNotAcceptable)
+		return
+	}
+
+	data, err := f.Encode(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", f.ContentType)
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+func (r *Registry) contentTypes() []string {
+	cts := make([]string, 0, len(r.formats))
+	for ct := range r.formats {
+		cts = append(cts, ct)
+	}
+	sort.Strings(cts)
+
+	return cts
+}
+
+// =============================================================================
+
+// parseAccept returns the media types in the Accept header ordered by their
+// quality. Media types with a quality of 0 aren't acceptable and are left
+// out.
+func parseAccept(accept string) []string {
+	type entry struct {
+		mediaType string
+		q         float64
+	}
+
+	var entries []entry
+	for _, value := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, exists := params["q"]; exists {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+
+		if q > 0 {
+			entries = append(entries, entry{mediaType: mediaType, q: q})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].q > entries[j].q
+	})
+
+	mediaTypes := make([]string, len(entries))
+	for i, e := range entries {
+		mediaTypes[i] = e.mediaType
+	}
+
+	return mediaTypes
+}
+
+// marshalJSON uses the model's own encoding when it has one.
+func marshalJSON(v any) ([]byte, error) {
+	if enc, ok := v.(Encoder); ok {
+		data, _, err := enc.Encode()
+		return data, err
+	}
+
+	return json.Marshal(v)
+}
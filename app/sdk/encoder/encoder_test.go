@@ -0,0 +1,105 @@
+package encoder_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/encore/app/sdk/encoder"
+)
+
+type address struct {
+	City string `json:"city"`
+}
+
+type home struct {
+	ID      string   `json:"id"`
+	Address address  `json:"address"`
+	Tags    []string `json:"tags"`
+}
+
+type result struct {
+	Items []home `json:"items"`
+	Total int    `json:"total"`
+}
+
+func Test_Negotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		exp    string
+	}{
+		{"", "application/json"},
+		{"*/*", "application/json"},
+		{"text/csv", "text/csv"},
+		{"text/*", "text/csv"},
+		{"application/xml;q=0.5, text/csv", "text/csv"},
+		{"application/xml, text/csv;q=0.5", "application/xml"},
+		{"text/html, application/json;q=0.1", "application/json"},
+	}
+
+	for _, tt := range tests {
+		f, err := encoder.Default.Negotiate(tt.accept)
+		if err != nil {
+			t.Fatalf("%q: Should be able to negotiate: %s", tt.accept, err)
+		}
+
+		if f.ContentType != tt.exp {
+			t.Fatalf("%q: Should get %s: got %s", tt.accept, tt.exp, f.ContentType)
+		}
+	}
+
+	if _, err := encoder.Default.Negotiate("text/html"); err == nil {
+		t.Fatalf("Should not be able to negotiate an unregistered format")
+	}
+}
+
+func Test_Formats(t *testing.T) {
+	v := result{
+		Items: []home{
+			{ID: "1", Address: address{City: "Miami"}, Tags: []string{"a", "b"}},
+			{ID: "2", Address: address{City: "Austin, TX"}},
+		},
+		Total: 2,
+	}
+
+	data, err := encoder.CSV.Encode(v)
+	if err != nil {
+		t.Fatalf("Should be able to encode CSV: %s", err)
+	}
+
+	exp := "id,address.city,tags\n1,Miami,\"a,b\"\n2,\"Austin, TX\",\n"
+	if string(data) != exp {
+		t.Fatalf("Should get the items as rows:\nexp: %q\ngot: %q", exp, string(data))
+	}
+
+	data, err = encoder.XML.Encode(v.Items[0])
+	if err != nil {
+		t.Fatalf("Should be able to encode XML: %s", err)
+	}
+
+	exp = `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<response><id>1</id><address><city>Miami</city></address><tags><item>a</item><item>b</item></tags></response>`
+	if string(data) != exp {
+		t.Fatalf("Should get the fields as elements:\nexp: %s\ngot: %s", exp, string(data))
+	}
+}
+
+func Test_Write(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "application/xml")
+
+	w := httptest.NewRecorder()
+	encoder.Default.Write(w, r, http.StatusOK, home{ID: "1"})
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/xml" {
+		t.Fatalf("Should get the negotiated content type: got %s", ct)
+	}
+
+	r.Header.Set("Accept", "image/png")
+
+	w = httptest.NewRecorder()
+	encoder.Default.Write(w, r, http.StatusOK, home{ID: "1"})
+
+	if w.Code != http.StatusNotAcceptable {
+		t.Fatalf("Should get a 406 for an unregistered format: got %d", w.Code)
+	}
+}
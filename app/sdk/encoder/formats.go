@@ -0,0 +1,276 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// JSON writes the value as JSON.
+var JSON = Format{
+	ContentType: "application/json",
+	Encode:      marshalJSON,
+}
+
+// CSV writes the value as CSV with a header row. The items of a query
+// result or a list are written a row each and any other value is written
+// as a single row. Nested objects are flattened using dotted names.
+var CSV = Format{
+	ContentType: "text/csv",
+	Encode:      encodeCSV,
+}
+
+// XML writes the value as XML with a response root element. Objects use an
+// element per field and the entries of a list are item elements.
+var XML = Format{
+	ContentType: "application/xml",
+	Encode:      encodeXML,
+}
+
+// =============================================================================
+
+func encodeCSV(v any) ([]byte, error) {
+	root, err := decode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []node
+	switch {
+	case root.kind == kindArray:
+		rows = root.items
+
+	case root.kind == kindObject && root.field("items").kind == kindArray:
+		rows = root.field("items").items
+
+	default:
+		rows = []node{root}
+	}
+
+	var header []string
+	seen := make(map[string]struct{})
+	records := make([]map[string]string, len(rows))
+
+	for i, row := range rows {
+		records[i] = make(map[string]string)
+		for _, c := range flatten(row, "") {
+			if _, exists := seen[c.name]; !exists {
+				seen[c.name] = struct{}{}
+				header = append(header, c.name)
+			}
+			records[i][c.name] = c.value
+		}
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	for _, rec := range records {
+		record := make([]string, len(header))
+		for i, name := range header {
+			record[i] = rec[name]
+		}
+
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+
+	return buf.Bytes(), w.Error()
+}
+
+type cell struct {
+	name  string
+	value string
+}
+
+// flatten returns the fields of an object using dotted names for nested
+// objects. Lists of values are joined with commas.
+func flatten(n node, prefix string) []cell {
+	switch n.kind {
+	case kindObject:
+		var cells []cell
+		for i, key := range n.keys {
+			cells = append(cells, flatten(n.items[i], prefix+key+".")...)
+		}
+		return cells
+
+	case kindArray:
+		values := make([]string, len(n.items))
+		for i, item := range n.items {
+			values[i] = item.text()
+		}
+		return []cell{{name: strings.TrimSuffix(prefix, "."), value: strings.Join(values, ",")}}
+
+	default:
+		name := strings.TrimSuffix(prefix, ".")
+		if name == "" {
+			name = "value"
+		}
+		return []cell{{name: name, value: n.text()}}
+	}
+}
+
+// =============================================================================
+
+func encodeXML(v any) ([]byte, error) {
+	root, err := decode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	enc := xml.NewEncoder(&buf)
+	if err := writeXML(enc, "response", root); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeXML(enc *xml.Encoder, name string, n node) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch n.kind {
+	case kindObject:
+		for i, key := range n.keys {
+			if err := writeXML(enc, key, n.items[i]); err != nil {
+				return err
+			}
+		}
+
+	case kindArray:
+		for _, item := range n.items {
+			if err := writeXML(enc, "item", item); err != nil {
+				return err
+			}
+		}
+
+	default:
+		if err := enc.EncodeToken(xml.CharData(n.text())); err != nil {
+			return err
+		}
+	}
+
+	return enc.EncodeToken(start.End())
+}
+
+// =============================================================================
+
+type kind int
+
+const (
+	kindValue kind = iota
+	kindObject
+	kindArray
+)
+
+// node represents a decoded JSON value that keeps the order of the fields
+// in an object, so the other formats use the same order as the JSON.
+type node struct {
+	kind  kind
+	keys  []string
+	items []node
+	value any
+}
+
+func (n node) field(key string) node {
+	for i, k := range n.keys {
+		if k == key {
+			return n.items[i]
+		}
+	}
+
+	return node{}
+}
+
+func (n node) text() string {
+	if n.value == nil {
+		return ""
+	}
+
+	return fmt.Sprint(n.value)
+}
+
+// decode encodes the value as JSON and decodes it into a node.
+func decode(v any) (node, error) {
+	data, err := marshalJSON(v)
+	if err != nil {
+		return node{}, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	return decodeNode(dec)
+}
+
+func decodeNode(dec *json.Decoder) (node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return node{}, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		n := node{kind: kindObject}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return node{}, err
+			}
+
+			item, err := decodeNode(dec)
+			if err != nil {
+				return node{}, err
+			}
+
+			n.keys = append(n.keys, key.(string))
+			n.items = append(n.items, item)
+		}
+
+		if _, err := dec.Token(); err != nil {
+			return node{}, err
+		}
+
+		return n, nil
+
+	case json.Delim('['):
+		n := node{kind: kindArray}
+		for dec.More() {
+			item, err := decodeNode(dec)
+			if err != nil {
+				return node{}, err
+			}
+
+			n.items = append(n.items, item)
+		}
+
+		if _, err := dec.Token(); err != nil {
+			return node{}, err
+		}
+
+		return n, nil
+	}
+
+	return node{kind: kindValue, value: tok}, nil
+}
@@ -0,0 +1,109 @@
+package errs
+
+import "encore.dev/beta/errs"
+
+// AppCode is a stable, machine-readable code for an application error.
+// Clients can branch on it instead of parsing the message, which is free
+// text and may be translated. Once published a code must never change.
+type AppCode string
+
+// The catalog of application error codes.
+const (
+	AppInternal      AppCode = "INTERNAL"
+	AppInvalidID     AppCode = "INVALID_ID"
+	AppNotFound      AppCode = "NOT_FOUND"
+	AppForbidden     AppCode = "FORBIDDEN"
+	AppValidation    AppCode = "VALIDATION_FAILED"
+	AppConflict      AppCode = "CONFLICT"
+	AppInvalidFilter AppCode = "INVALID_FILTER"
+
+	AppAPIKeyNotFound     AppCode = "API_KEY_NOT_FOUND"
+	AppAPIKeyExpired      AppCode = "API_KEY_EXPIRED"
+	AppAPIKeyUserDisabled AppCode = "API_KEY_USER_DISABLED"
+	AppAPIKeyRoles        AppCode = "API_KEY_ROLES"
+
+	AppDeadLetterNotFound        AppCode = "DEADLETTER_NOT_FOUND"
+	AppDeadLetterAlreadyReplayed AppCode = "DEADLETTER_ALREADY_REPLAYED"
+	AppDeadLetterNoReplay        AppCode = "DEADLETTER_NO_REPLAY"
+
+	AppHomeNotFound     AppCode = "HOME_NOT_FOUND"
+	AppHomeUserDisabled AppCode = "HOME_USER_DISABLED"
+	AppHomeModified     AppCode = "HOME_MODIFIED"
+
+	AppJobNotFound AppCode = "JOB_NOT_FOUND"
+
+	AppOAuthStateNotFound    AppCode = "OAUTH_STATE_NOT_FOUND"
+	AppOAuthStateExpired     AppCode = "OAUTH_STATE_EXPIRED"
+	AppOAuthEmailNotVerified AppCode = "OAUTH_EMAIL_NOT_VERIFIED"
+	AppOAuthNotProvisioned   AppCode = "OAUTH_NOT_PROVISIONED"
+	AppOAuthUserDisabled     AppCode = "OAUTH_USER_DISABLED"
+	AppOAuthLinkRequired     AppCode = "OAUTH_LINK_REQUIRED"
+	AppOAuthIdentityLinked   AppCode = "OAUTH_IDENTITY_LINKED"
+	AppOAuthLinkScope        AppCode = "OAUTH_LINK_SCOPE"
+
+	AppProductNotFound     AppCode = "PRODUCT_NOT_FOUND"
+	AppProductUserDisabled AppCode = "PRODUCT_USER_DISABLED"
+	AppProductInvalidCost  AppCode = "PRODUCT_INVALID_COST"
+	AppProductModified     AppCode = "PRODUCT_MODIFIED"
+
+	AppReportNotFound AppCode = "REPORT_NOT_FOUND"
+
+	AppSavedSearchNotFound AppCode = "SAVED_SEARCH_NOT_FOUND"
+
+	AppTokenNotFound AppCode = "TOKEN_NOT_FOUND"
+	AppTokenExpired  AppCode = "TOKEN_EXPIRED"
+	AppTokenRevoked  AppCode = "TOKEN_REVOKED"
+
+	AppUserNotFound              AppCode = "USER_NOT_FOUND"
+	AppUserEmailTaken            AppCode = "USER_EMAIL_TAKEN"
+	AppUserAuthenticationFailed  AppCode = "USER_AUTHENTICATION_FAILED"
+	AppUserPasswordResetNotFound AppCode = "USER_PASSWORD_RESET_NOT_FOUND"
+	AppUserPasswordResetExpired  AppCode = "USER_PASSWORD_RESET_EXPIRED"
+	AppUserModified              AppCode = "USER_MODIFIED"
+
+	AppUserPrefsNotFound AppCode = "USER_PREFS_NOT_FOUND"
+)
+
+// NewCode constructs an encore error based on an app error with the
+// application error code in the details.
+func NewCode(code errs.ErrCode, appCode AppCode, err error) *errs.Error {
+	return &errs.Error{
+		Code:    code,
+		Message: sanitize(err.Error()),
+		Details: CodeDetails{
+			Code: appCode,
+		},
+	}
+}
+
+// CodeDetails provides the application error code to clients, and the
+// cause chain of the error when debugging is on.
+type CodeDetails struct {
+	Code   AppCode  `json:"code"`
+	Causes []string `json:"causes,omitempty"`
+}
+
+// ErrDetails implements the encore ErrDetails interface.
+func (CodeDetails) ErrDetails() {}
+
+// GetAppCode returns the application error code of the error, or an empty
+// code when it has none.
+func GetAppCode(err error) AppCode {
+	e := toError(err)
+
+	switch d := e.Details.(type) {
+	case CodeDetails:
+		return d.Code
+	case FieldDetails:
+		return d.Code
+	case RequestDetails:
+		switch rd := d.Details.(type) {
+		case CodeDetails:
+			return rd.Code
+		case FieldDetails:
+			return rd.Code
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,43 @@
+package errs
+
+import (
+	"errors"
+	"strings"
+
+	"encore.dev/beta/errs"
+)
+
+// InternalMessage is the message clients get for an internal error when
+// debugging is off.
+const InternalMessage = "internal error"
+
+// Causes returns the path an error took through the layers, from the
+// outermost wrap to the error at the root, as in a store error wrapped by
+// the bus and again by the app. Each cause is the message added at that
+// level of the chain, with any secrets removed.
+func Causes(err error) []string {
+	var causes []string
+
+	for err != nil {
+		next := errors.Unwrap(err)
+		if next == nil {
+			causes = append(causes, sanitize(message(err)))
+			break
+		}
+
+		causes = append(causes, sanitize(strings.TrimSuffix(message(err), ": "+message(next))))
+		err = next
+	}
+
+	return causes
+}
+
+// message returns the message of the error, which for an encore error is
+// the message it was constructed with.
+func message(err error) string {
+	if ee, ok := err.(*errs.Error); ok {
+		return ee.Message
+	}
+
+	return err.Error()
+}
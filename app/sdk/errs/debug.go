@@ -0,0 +1,55 @@
+package errs
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+
+	"encore.dev"
+	"encore.dev/beta/errs"
+)
+
+// maxFrames is the most frames of the call stack kept for an error.
+const maxFrames = 32
+
+// debug decides if errors carry the details that help with debugging.
+var debug atomic.Bool
+
+// SetDebug turns on the details that help with debugging when running
+// locally or in a preview environment: the call stack of errors created by
+// New and Newf, and the full message and cause chain of classified errors.
+// It stays off everywhere else so internals don't leak to clients.
+func SetDebug(env encore.EnvironmentMeta) {
+	debug.Store(env.Cloud == encore.CloudLocal || env.Type == encore.EnvEphemeral)
+}
+
+// StackDetails provides the call stack an error was created at.
+type StackDetails struct {
+	Stack []string `json:"stack"`
+}
+
+// ErrDetails implements the encore ErrDetails interface.
+func (StackDetails) ErrDetails() {}
+
+// stackDetails returns the call stack of the caller of the constructor when
+// debugging is on.
+func stackDetails() errs.ErrDetails {
+	if !debug.Load() {
+		return nil
+	}
+
+	pc := make([]uintptr, maxFrames)
+	n := runtime.Callers(3, pc)
+	frames := runtime.CallersFrames(pc[:n])
+
+	var sd StackDetails
+	for {
+		frame, more := frames.Next()
+		sd.Stack = append(sd.Stack, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+		if !more {
+			break
+		}
+	}
+
+	return sd
+}
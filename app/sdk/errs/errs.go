@@ -5,24 +5,33 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
+	"time"
 
 	"encore.dev/beta/errs"
 	"encore.dev/middleware"
+	"github.com/ardanlabs/encore/business/sdk/queryfilter"
+	"github.com/go-playground/validator/v10"
 )
 
-// New constructs an encore error based on an app error.
+// New constructs an encore error based on an app error. The call stack is
+// added to the details when debugging is on.
 func New(code errs.ErrCode, err error) *errs.Error {
 	return &errs.Error{
 		Code:    code,
-		Message: err.Error(),
+		Message: sanitize(err.Error()),
+		Details: stackDetails(),
 	}
 }
 
-// Newf constructs an encore error based on a error message.
+// Newf constructs an encore error based on a error message. The call stack
+// is added to the details when debugging is on.
 func Newf(code errs.ErrCode, format string, v ...any) *errs.Error {
 	return &errs.Error{
 		Code:    code,
-		Message: fmt.Sprintf(format, v...),
+		Message: sanitize(fmt.Sprintf(format, v...)),
+		Details: stackDetails(),
 	}
 }
 
@@ -31,7 +40,7 @@ func NewResponse(code errs.ErrCode, err error) middleware.Response {
 	return middleware.Response{
 		Err: &errs.Error{
 			Code:    code,
-			Message: err.Error(),
+			Message: sanitize(err.Error()),
 		},
 	}
 }
@@ -41,17 +50,242 @@ func NewResponsef(code errs.ErrCode, format string, v ...any) middleware.Respons
 	return middleware.Response{
 		Err: &errs.Error{
 			Code:    code,
-			Message: fmt.Sprintf(format, v...),
+			Message: sanitize(fmt.Sprintf(format, v...)),
+		},
+	}
+}
+
+// NewRetry constructs an encore error that tells the client how long to
+// wait before trying the request again.
+func NewRetry(code errs.ErrCode, retryAfter time.Duration, err error) *errs.Error {
+	return &errs.Error{
+		Code:    code,
+		Message: sanitize(err.Error()),
+		Details: RetryDetails{
+			RetryAfterSeconds: seconds(retryAfter),
 		},
 	}
 }
 
+// NewRateLimited constructs an encore error that tells the client how long
+// to wait before trying the request again and the state of the rate limit
+// it hit.
+func NewRateLimited(code errs.ErrCode, rl RateLimit, err error) *errs.Error {
+	return &errs.Error{
+		Code:    code,
+		Message: sanitize(err.Error()),
+		Details: RetryDetails{
+			RetryAfterSeconds: rl.ResetSeconds,
+			RateLimit:         &rl,
+		},
+	}
+}
+
+// NewRetryResponse constructs an encore middleware response that tells the
+// client how long to wait before trying the request again.
+func NewRetryResponse(code errs.ErrCode, retryAfter time.Duration, err error) middleware.Response {
+	return middleware.Response{
+		Err: NewRetry(code, retryAfter, err),
+	}
+}
+
+// NewRateLimitResponse constructs an encore middleware response that tells
+// the client how long to wait before trying the request again and the state
+// of the rate limit it hit.
+func NewRateLimitResponse(code errs.ErrCode, rl RateLimit, err error) middleware.Response {
+	return middleware.Response{
+		Err: NewRateLimited(code, rl, err),
+	}
+}
+
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// =============================================================================
+
+// RetryDetails provides the number of seconds a client should wait before
+// trying the request again. Encore middleware can't set response headers so
+// this takes the place of the Retry-After and X-RateLimit headers.
+type RetryDetails struct {
+	RetryAfterSeconds int        `json:"retryAfterSeconds"`
+	RateLimit         *RateLimit `json:"rateLimit,omitempty"`
+}
+
+// RateLimit provides the state of a rate limit so clients can back off
+// before they are rejected.
+type RateLimit struct {
+	Limit        int `json:"limit"`
+	Remaining    int `json:"remaining"`
+	ResetSeconds int `json:"resetSeconds"`
+}
+
+// NewRateLimit constructs the state of a rate limit with the reset rounded
+// up to the second.
+func NewRateLimit(limit int, remaining int, reset time.Duration) RateLimit {
+	return RateLimit{
+		Limit:        limit,
+		Remaining:    remaining,
+		ResetSeconds: seconds(reset),
+	}
+}
+
+// ErrDetails implements the encore ErrDetails interface.
+func (RetryDetails) ErrDetails() {}
+
+// =============================================================================
+
+// RequestDetails adds the ID of the request to the details of an error so a
+// user can quote it when reporting the failure. Any details already on the
+// error are kept and written alongside the request ID.
+type RequestDetails struct {
+	RequestID string
+	Details   errs.ErrDetails
+}
+
+// WithRequestID returns a copy of the error with the request ID added to the
+// details.
+func WithRequestID(err error, requestID string) *errs.Error {
+	e := toError(err)
+	e.Details = RequestDetails{
+		RequestID: requestID,
+		Details:   e.Details,
+	}
+
+	return &e
+}
+
+// ErrDetails implements the encore ErrDetails interface.
+func (RequestDetails) ErrDetails() {}
+
+// MarshalJSON implements the json.Marshaler interface so the original
+// details stay at the same level for clients.
+func (rd RequestDetails) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any)
+
+	if rd.Details != nil {
+		data, err := json.Marshal(rd.Details)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(data, &m); err != nil {
+			m["details"] = json.RawMessage(data)
+		}
+	}
+
+	m["requestID"] = rd.RequestID
+
+	return json.Marshal(m)
+}
+
+// =============================================================================
+
+// Translate returns a copy of the error with the message translated. When
+// the message or details hold field errors, the message for each field is
+// translated so the field names stay the same for clients.
+func Translate(err error, translate func(msg string) string) *errs.Error {
+	e := toError(err)
+
+	if fd, ok := e.Details.(FieldDetails); ok {
+		fields := make(FieldErrors, len(fd.Fields))
+		for j, fld := range fd.Fields {
+			fields[j] = FieldError{Field: fld.Field, Err: translate(fld.Err)}
+		}
+		fd.Fields = fields
+		e.Details = fd
+	}
+
+	if i := strings.Index(e.Message, "["); i != -1 {
+		var fe FieldErrors
+		if json.Unmarshal([]byte(e.Message[i:]), &fe) == nil {
+			for j := range fe {
+				fe[j].Err = translate(fe[j].Err)
+			}
+
+			e.Message = e.Message[:i] + fe.Error()
+			return &e
+		}
+	}
+
+	e.Message = translate(e.Message)
+
+	return &e
+}
+
+// toError returns a copy of the error as an encore error so the original
+// isn't changed.
+func toError(err error) errs.Error {
+	var ee *errs.Error
+	if errors.As(err, &ee) {
+		return *ee
+	}
+
+	return errs.Error{
+		Code:    errs.Unknown,
+		Message: err.Error(),
+	}
+}
+
+// =============================================================================
+
+// Class is the code and application error code an app error is given for a
+// sentinel error.
+type Class struct {
+	Code    errs.ErrCode
+	AppCode AppCode
+}
+
+// Classes maps the sentinel errors returned by the business layer to the
+// class an app error is given for them, so the translation is declared in
+// one table instead of in each handler.
+type Classes map[error]Class
+
+// Classify returns the error as an encore error. An encore error is returned
+// as is, an error that wraps one of the sentinel errors is given its class
+// and any other error is internal. When debugging is on the message is the
+// full message and the cause chain is added to the details, otherwise only
+// the message of the sentinel error is kept, or a generic message for an
+// internal error, so nothing about the internals reaches the client.
+func (c Classes) Classify(err error) *errs.Error {
+	var ee *errs.Error
+	if errors.As(err, &ee) {
+		return ee
+	}
+
+	for target, class := range c {
+		if errors.Is(err, target) {
+			return classified(class, err, target.Error())
+		}
+	}
+
+	return classified(Class{Code: errs.Internal, AppCode: AppInternal}, err, InternalMessage)
+}
+
+func classified(class Class, err error, safeMsg string) *errs.Error {
+	if !debug.Load() {
+		return NewCode(class.Code, class.AppCode, errors.New(safeMsg))
+	}
+
+	e := NewCode(class.Code, class.AppCode, err)
+	e.Details = CodeDetails{
+		Code:   class.AppCode,
+		Causes: Causes(err),
+	}
+
+	return e
+}
+
 // =============================================================================
 
 // FieldError is used to indicate an error with a specific request field.
 type FieldError struct {
 	Field string `json:"field"`
 	Err   string `json:"error"`
+
+	// verror is the validation failure the error came from, which is kept
+	// so the message can be written again in the locale of the request.
+	verror validator.FieldError
 }
 
 // FieldErrors represents a collection of field errors.
@@ -85,6 +319,76 @@ func (fe FieldErrors) Fields() map[string]string {
 	return m
 }
 
+// NewFieldErrors constructs an InvalidArgument encore error for a failed
+// validation. The field errors the error holds are added to the details so
+// clients don't have to parse them out of the message.
+func NewFieldErrors(err error) *errs.Error {
+	return &errs.Error{
+		Code:    errs.InvalidArgument,
+		Message: sanitize(err.Error()),
+		Details: FieldDetails{
+			Code:   AppValidation,
+			Fields: GetFieldErrors(err),
+		},
+	}
+}
+
+// NewFilterErrors constructs a FailedPrecondition encore error for a query
+// filter that couldn't be parsed. The fields that failed are added to the
+// details the same way they are for a failed validation.
+func NewFilterErrors(err error) *errs.Error {
+	var qfe queryfilter.FieldErrors
+	errors.As(err, &qfe)
+
+	fields := make(FieldErrors, len(qfe))
+	for i, fe := range qfe {
+		fields[i] = FieldError{
+			Field: fe.Field,
+			Err:   fe.Err,
+		}
+	}
+
+	return &errs.Error{
+		Code:    errs.FailedPrecondition,
+		Message: sanitize(err.Error()),
+		Details: FieldDetails{
+			Code:   AppInvalidFilter,
+			Fields: fields,
+		},
+	}
+}
+
+// FieldDetails provides the fields that failed validation to clients.
+type FieldDetails struct {
+	Code   AppCode     `json:"code"`
+	Fields FieldErrors `json:"fields"`
+}
+
+// ErrDetails implements the encore ErrDetails interface.
+func (FieldDetails) ErrDetails() {}
+
+// Localize returns a copy of the field errors with the message of each
+// validation failure written in the locale by the validator, when it has a
+// message for it. Any other message is left as is.
+func (fe FieldErrors) Localize(locale string) FieldErrors {
+	trans, exists := translators[locale]
+
+	fields := make(FieldErrors, len(fe))
+	for i, fld := range fe {
+		fields[i] = fld
+
+		if !exists || fld.verror == nil {
+			continue
+		}
+
+		if msg, ok := fieldMessage(fld.verror, trans); ok {
+			fields[i].Err = msg
+		}
+	}
+
+	return fields
+}
+
 // IsFieldErrors checks if an error of type FieldErrors exists.
 func IsFieldErrors(err error) bool {
 	var fe FieldErrors
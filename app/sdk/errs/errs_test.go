@@ -0,0 +1,524 @@
+package errs_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"encore.dev"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/homebus"
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/sdk/queryfilter"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func Test_WithRequestID(t *testing.T) {
+	err := errs.WithRequestID(errors.New("boom"), "abc")
+	if err.Code != errs.Unknown {
+		t.Fatalf("Should convert a go error to unknown: got %s", err.Code)
+	}
+
+	data, _ := json.Marshal(err.Details)
+	if exp := `{"requestID":"abc"}`; string(data) != exp {
+		t.Fatalf("Should get %s: got %s", exp, data)
+	}
+
+	resp := errs.NewRetryResponse(errs.ResourceExhausted, 1500*time.Millisecond, errors.New("slow down"))
+
+	err = errs.WithRequestID(resp.Err, "abc")
+	if err.Code != errs.ResourceExhausted {
+		t.Fatalf("Should keep the code: got %s", err.Code)
+	}
+
+	data, _ = json.Marshal(err.Details)
+	if exp := `{"requestID":"abc","retryAfterSeconds":2}`; string(data) != exp {
+		t.Fatalf("Should get %s: got %s", exp, data)
+	}
+}
+
+func Test_Translate(t *testing.T) {
+	upper := func(msg string) string {
+		return strings.ToUpper(msg)
+	}
+
+	fe := errs.NewFieldsError("name", errors.New("name is a required field"))
+
+	err := errs.Translate(errs.Newf(errs.InvalidArgument, "validate: %s", fe), upper)
+	if exp := `validate: [{"field":"name","error":"NAME IS A REQUIRED FIELD"}]`; err.Message != exp {
+		t.Fatalf("Should translate the field error only: got %s", err.Message)
+	}
+
+	err = errs.Translate(errs.Newf(errs.NotFound, "product not found"), upper)
+	if err.Message != "PRODUCT NOT FOUND" || err.Code != errs.NotFound {
+		t.Fatalf("Should translate the message and keep the code: got %s %s", err.Code, err.Message)
+	}
+}
+
+func Test_NewRateLimitResponse(t *testing.T) {
+	rl := errs.NewRateLimit(20, 0, 1500*time.Millisecond)
+
+	resp := errs.NewRateLimitResponse(errs.ResourceExhausted, rl, errors.New("slow down"))
+
+	data, _ := json.Marshal(errs.WithRequestID(resp.Err, "abc").Details)
+	if exp := `{"rateLimit":{"limit":20,"remaining":0,"resetSeconds":2},"requestID":"abc","retryAfterSeconds":2}`; string(data) != exp {
+		t.Fatalf("Should get %s: got %s", exp, data)
+	}
+}
+
+func Test_Classify(t *testing.T) {
+	errNotFound := errors.New("product not found")
+
+	classes := errs.Classes{
+		errNotFound: {Code: errs.NotFound, AppCode: errs.AppProductNotFound},
+	}
+
+	err := classes.Classify(fmt.Errorf("querybyid: %w", errNotFound))
+	if err.Code != errs.NotFound || err.Message != "product not found" {
+		t.Fatalf("Should give a wrapped sentinel its code: got %s %s", err.Code, err.Message)
+	}
+
+	if code := errs.GetAppCode(err); code != errs.AppProductNotFound {
+		t.Fatalf("Should give a wrapped sentinel its app code: got %s", code)
+	}
+
+	if code := errs.GetAppCode(errs.WithRequestID(err, "1234")); code != errs.AppProductNotFound {
+		t.Fatalf("Should keep the app code with the request ID: got %s", code)
+	}
+
+	err = classes.Classify(errs.Newf(errs.InvalidArgument, "bad"))
+	if err.Code != errs.InvalidArgument || err.Message != "bad" {
+		t.Fatalf("Should keep an app error as is: got %s %s", err.Code, err.Message)
+	}
+
+	err = classes.Classify(errors.New("boom"))
+	if err.Code != errs.Internal || err.Message != errs.InternalMessage {
+		t.Fatalf("Should make any other error internal: got %s %s", err.Code, err.Message)
+	}
+
+	if code := errs.GetAppCode(err); code != errs.AppInternal {
+		t.Fatalf("Should give any other error the internal app code: got %s", code)
+	}
+}
+
+func Test_ClassifyDebug(t *testing.T) {
+	errNotFound := errors.New("product not found")
+
+	classes := errs.Classes{
+		errNotFound: {Code: errs.NotFound, AppCode: errs.AppProductNotFound},
+	}
+
+	errs.SetDebug(encore.EnvironmentMeta{Type: encore.EnvEphemeral})
+	defer errs.SetDebug(encore.EnvironmentMeta{Type: encore.EnvProduction})
+
+	storeErr := fmt.Errorf("namedquerystruct: %w", errNotFound)
+	busErr := fmt.Errorf("querybyid: productID[1]: %w", storeErr)
+
+	err := classes.Classify(fmt.Errorf("query: %w", busErr))
+	if err.Message != "query: querybyid: productID[1]: namedquerystruct: product not found" {
+		t.Fatalf("Should keep the full message: got %s", err.Message)
+	}
+
+	cd, ok := err.Details.(errs.CodeDetails)
+	if !ok {
+		t.Fatalf("Should get code details: got %T", err.Details)
+	}
+
+	exp := []string{"query", "querybyid: productID[1]", "namedquerystruct", "product not found"}
+	if diff := cmp.Diff(cd.Causes, exp); diff != "" {
+		t.Fatalf("Should get the cause chain:\n%s", diff)
+	}
+
+	err = classes.Classify(fmt.Errorf("query: %w", errors.New("connection refused")))
+	if err.Code != errs.Internal || err.Message != "query: connection refused" {
+		t.Fatalf("Should keep the full message of an internal error: got %s %s", err.Code, err.Message)
+	}
+}
+
+func Test_StackTraces(t *testing.T) {
+	err := errs.Newf(errs.InvalidArgument, "bad")
+	if err.Details != nil {
+		t.Fatalf("Should not add the stack by default: got %v", err.Details)
+	}
+
+	errs.SetDebug(encore.EnvironmentMeta{Cloud: encore.CloudLocal})
+	defer errs.SetDebug(encore.EnvironmentMeta{Type: encore.EnvProduction})
+
+	err = errs.Newf(errs.InvalidArgument, "bad")
+
+	sd, ok := err.Details.(errs.StackDetails)
+	if !ok || len(sd.Stack) == 0 {
+		t.Fatalf("Should add the stack locally: got %v", err.Details)
+	}
+
+	if !strings.Contains(sd.Stack[0], "Test_StackTraces") {
+		t.Fatalf("Should start the stack at the caller: got %s", sd.Stack[0])
+	}
+}
+
+func Test_NewFieldErrors(t *testing.T) {
+	type user struct {
+		Name string `json:"name" validate:"required"`
+	}
+
+	err := errs.NewFieldErrors(fmt.Errorf("validate: %w", errs.Check(user{})))
+	if err.Code != errs.InvalidArgument {
+		t.Fatalf("Should get an invalid argument code: got %s", err.Code)
+	}
+
+	fd, ok := err.Details.(errs.FieldDetails)
+	if !ok || len(fd.Fields) != 1 || fd.Fields[0].Field != "name" {
+		t.Fatalf("Should add the fields to the details: got %v", err.Details)
+	}
+
+	if fd.Code != errs.AppValidation {
+		t.Fatalf("Should add the validation app code: got %s", fd.Code)
+	}
+
+	tErr := errs.Translate(err, strings.ToUpper)
+
+	tfd := tErr.Details.(errs.FieldDetails)
+	if tfd.Fields[0].Err != "NAME IS A REQUIRED FIELD" {
+		t.Fatalf("Should translate the fields in the details: got %s", tfd.Fields[0].Err)
+	}
+
+	if fd.Fields[0].Err != "name is a required field" {
+		t.Fatalf("Should not change the original details: got %s", fd.Fields[0].Err)
+	}
+}
+
+func Test_NewFilterErrors(t *testing.T) {
+	p := queryfilter.New()
+	queryfilter.Value(p, "user_id", "abc", queryfilter.UUID)
+
+	err := errs.NewFilterErrors(p.Err())
+	if err.Code != errs.FailedPrecondition {
+		t.Fatalf("Should get a failed precondition code: got %s", err.Code)
+	}
+
+	if err.Message != `[{"field":"user_id","error":"invalid UUID length: 3"}]` {
+		t.Fatalf("Should keep the fields in the message: got %s", err.Message)
+	}
+
+	fd, ok := err.Details.(errs.FieldDetails)
+	if !ok || len(fd.Fields) != 1 || fd.Fields[0].Field != "user_id" {
+		t.Fatalf("Should add the fields to the details: got %v", err.Details)
+	}
+
+	if fd.Code != errs.AppInvalidFilter {
+		t.Fatalf("Should add the invalid filter app code: got %s", fd.Code)
+	}
+}
+
+func Test_From(t *testing.T) {
+	errDisabled := errors.New("user disabled")
+
+	errs.Register(errDisabled, errs.Class{Code: errs.FailedPrecondition, AppCode: errs.AppHomeUserDisabled})
+
+	err := errs.From(fmt.Errorf("create: %w", errDisabled))
+	if err.Code != errs.FailedPrecondition || errs.GetAppCode(err) != errs.AppHomeUserDisabled {
+		t.Fatalf("Should classify a registered sentinel: got %s %s", err.Code, errs.GetAppCode(err))
+	}
+
+	err = errs.From(errors.New("boom"))
+	if err.Code != errs.Internal {
+		t.Fatalf("Should make an unregistered error internal: got %s", err.Code)
+	}
+}
+
+func Test_Sanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		exp  string
+	}{
+		{
+			name: "url",
+			msg:  "connect: postgres://postgres:s3cr3t@db:5432/postgres: refused",
+			exp:  "connect: postgres://[REDACTED]@db:5432/postgres: refused",
+		},
+		{
+			name: "dsn",
+			msg:  "connect: host=db user=postgres password=s3cr3t dbname=app",
+			exp:  "connect: host=db user=postgres password=[REDACTED] dbname=app",
+		},
+		{
+			name: "json",
+			msg:  `decode: {"email":"bill@example.com","password":"s3cr3t"}`,
+			exp:  `decode: {"email":"bill@example.com","password":"[REDACTED]"}`,
+		},
+		{
+			name: "header",
+			msg:  "call: Authorization: Bearer abc.def.ghi",
+			exp:  "call: Authorization: Bearer [REDACTED]",
+		},
+		{
+			name: "jwt",
+			msg:  "parse: eyJhbGciOiJSUzI1NiJ9.eyJzdWIiOiIxIn0.c2ln is expired",
+			exp:  "parse: [REDACTED] is expired",
+		},
+		{
+			name: "clean",
+			msg:  "error parsing token: token contains an invalid number of segments",
+			exp:  "error parsing token: token contains an invalid number of segments",
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			err := errs.New(errs.Internal, errors.New(tst.msg))
+			if err.Message != tst.exp {
+				t.Fatalf("Should remove the secrets:\nexp: %s\ngot: %s", tst.exp, err.Message)
+			}
+		})
+	}
+}
+
+func Test_Helpers(t *testing.T) {
+	err := errs.NewNotFound("product", "45b5fbd3")
+	if !errs.IsCode(err, errs.NotFound) || !errs.IsAppCode(err, errs.AppNotFound) {
+		t.Fatalf("Should get a not found error: got %s %s", err.Code, errs.GetAppCode(err))
+	}
+
+	if err.Message != "product[45b5fbd3] not found" {
+		t.Fatalf("Should name the entity: got %s", err.Message)
+	}
+
+	err = errs.NewConflict("email")
+	if !errs.IsCode(err, errs.AlreadyExists) || !errs.IsAppCode(err, errs.AppConflict) {
+		t.Fatalf("Should get a conflict error: got %s %s", err.Code, errs.GetAppCode(err))
+	}
+
+	if fd := err.Details.(errs.FieldDetails); fd.Fields[0].Field != "email" {
+		t.Fatalf("Should name the field: got %s", fd.Fields[0].Field)
+	}
+
+	if _, ok := errs.As(fmt.Errorf("wrap: %w", err)); !ok {
+		t.Fatalf("Should find the encore error in the chain")
+	}
+
+	if errs.IsCode(errors.New("boom"), errs.Internal) {
+		t.Fatalf("Should not match an error that isn't an encore error")
+	}
+}
+
+type upperTranslator struct{}
+
+func (upperTranslator) Translate(locale string, code errs.AppCode, msg string) string {
+	if code != "" {
+		return locale + ":" + string(code)
+	}
+	return locale + ":" + strings.ToUpper(msg)
+}
+
+func Test_Localize(t *testing.T) {
+	err := errs.Localize(errs.NewNotFound("product", 1), "es")
+	if err.Message != "product[1] not found" {
+		t.Fatalf("Should pass the message through by default: got %s", err.Message)
+	}
+
+	errs.SetTranslator(upperTranslator{})
+	defer errs.SetTranslator(errs.PassThrough{})
+
+	err = errs.Localize(errs.NewNotFound("product", 1), "es")
+	if err.Message != "es:NOT_FOUND" {
+		t.Fatalf("Should translate by the code: got %s", err.Message)
+	}
+
+	type user struct {
+		Name string `json:"name" validate:"required"`
+	}
+
+	err = errs.Localize(errs.NewFieldErrors(errs.Check(user{})), "es")
+
+	fd := err.Details.(errs.FieldDetails)
+	if fd.Fields[0].Err != "es:NAME ES UN CAMPO REQUERIDO" {
+		t.Fatalf("Should write the fields in the locale before translating them: got %s", fd.Fields[0].Err)
+	}
+}
+
+func Test_RegisterValidation(t *testing.T) {
+	errs.RegisterValidation("even", "{0} must be even", func(value int) error {
+		if value%2 != 0 {
+			return errors.New("odd")
+		}
+		return nil
+	})
+
+	type contact struct {
+		ID    string `json:"id" validate:"id"`
+		Phone string `json:"phone" validate:"omitempty,phone"`
+		Count int    `json:"count" validate:"even"`
+	}
+
+	ok := contact{ID: "45b5fbd3-755f-4379-8f07-a58d4a30fa2f", Phone: "+14155552671", Count: 2}
+	if err := errs.Check(ok); err != nil {
+		t.Fatalf("Should accept valid values: %s", err)
+	}
+
+	err := errs.Check(contact{ID: "abc", Phone: "555-1234", Count: 3})
+
+	exp := errs.FieldErrors{
+		{Field: "id", Err: "id must be a valid ID"},
+		{Field: "phone", Err: "phone must be a valid phone number"},
+		{Field: "count", Err: "count must be even"},
+	}
+
+	if diff := cmp.Diff(errs.GetFieldErrors(err), exp, cmpopts.IgnoreUnexported(errs.FieldError{})); diff != "" {
+		t.Fatalf("Should get the registered messages:\n%s", diff)
+	}
+}
+
+func Test_FieldErrorsLocalize(t *testing.T) {
+	type address struct {
+		Address1 string `json:"address1" validate:"max=5"`
+		Country  string `json:"country" validate:"iso3166_1_alpha2"`
+		Path     string `json:"path" validate:"startswith=/"`
+		Phone    string `json:"phone" validate:"phone"`
+		Code     string `json:"code" validate:"alphaunicode"`
+	}
+
+	fields := errs.GetFieldErrors(errs.Check(address{Address1: "123 Mocking Bird Lane", Country: "XX", Path: "v1", Phone: "1", Code: "1"}))
+
+	exp := []string{
+		"address1 must be a maximum of 5 characters in length",
+		"country must be a valid country code",
+		"path must start with /",
+		"phone must be a valid phone number",
+		"code is not valid",
+	}
+
+	for i, fld := range fields {
+		if fld.Err != exp[i] {
+			t.Fatalf("Should get a readable message: exp %q, got %q", exp[i], fld.Err)
+		}
+	}
+
+	exp = []string{
+		"address1 debe tener un máximo de 5 caracteres de longitud",
+		"country debe ser un código de país válido",
+		"path debe empezar con /",
+		"phone must be a valid phone number",
+		"code is not valid",
+	}
+
+	for i, fld := range fields.Localize("es") {
+		if fld.Err != exp[i] {
+			t.Fatalf("Should write the message in the locale: exp %q, got %q", exp[i], fld.Err)
+		}
+	}
+
+	if fields[0].Err != "address1 must be a maximum of 5 characters in length" {
+		t.Fatalf("Should not change the original messages: got %s", fields[0].Err)
+	}
+}
+
+func Test_ConditionalValidation(t *testing.T) {
+	type product struct {
+		HasVariants *bool     `json:"hasVariants"`
+		Variants    *[]string `json:"variants" validate:"required_if=HasVariants true"`
+		Cost        *float64  `json:"cost" validate:"excluded_with=Variants"`
+	}
+
+	yes := true
+	no := false
+	variants := []string{}
+	cost := 0.0
+
+	// A pointer field is provided when it isn't nil, even when it points
+	// to a zero value, and a condition on a nil pointer is never met.
+	tt := []struct {
+		name string
+		val  product
+		exp  []string
+	}{
+		{
+			name: "nil-condition",
+			val:  product{},
+		},
+		{
+			name: "required",
+			val:  product{HasVariants: &yes},
+			exp:  []string{"variants is required when hasVariants is true"},
+		},
+		{
+			name: "empty-pointer-provided",
+			val:  product{HasVariants: &yes, Variants: &variants},
+		},
+		{
+			name: "condition-false",
+			val:  product{HasVariants: &no},
+		},
+		{
+			name: "excluded",
+			val:  product{Variants: &variants, Cost: &cost},
+			exp:  []string{"cost must not be provided with variants"},
+		},
+		{
+			name: "zero-pointer-excluded",
+			val:  product{HasVariants: &yes, Variants: &variants, Cost: new(float64)},
+			exp:  []string{"cost must not be provided with variants"},
+		},
+		{
+			name: "nil-not-excluded",
+			val:  product{Cost: &cost},
+		},
+	}
+
+	for _, tst := range tt {
+		t.Run(tst.name, func(t *testing.T) {
+			var got []string
+			for _, fld := range errs.GetFieldErrors(errs.Check(tst.val)) {
+				got = append(got, fld.Err)
+			}
+
+			if diff := cmp.Diff(got, tst.exp); diff != "" {
+				t.Fatalf("Should get the expected messages:\n%s", diff)
+			}
+		})
+	}
+
+	fields := errs.GetFieldErrors(errs.Check(product{HasVariants: &yes}))
+
+	if msg := fields.Localize("es")[0].Err; msg != "variants es requerido cuando hasVariants es true" {
+		t.Fatalf("Should write the condition in the locale: got %s", msg)
+	}
+}
+
+func Test_EnumValidation(t *testing.T) {
+	type user struct {
+		Roles []string `json:"roles" validate:"required,dive,enum=role"`
+		Type  *string  `json:"type" validate:"omitempty,enum=hometype"`
+	}
+
+	typ := homebus.Types.Condo.String()
+	if err := errs.Check(user{Roles: []string{userbus.Roles.Admin.String()}, Type: &typ}); err != nil {
+		t.Fatalf("Should accept the values of the business enums: %s", err)
+	}
+
+	typ = "TENT"
+	fields := errs.GetFieldErrors(errs.Check(user{Roles: []string{"OWNER"}, Type: &typ}))
+
+	exp := []string{
+		"roles[0] must be one of ADMIN, USER",
+		"type must be one of SINGLE FAMILY, CONDO",
+	}
+
+	if len(fields) != len(exp) {
+		t.Fatalf("Should get a field error for each value: got %v", fields)
+	}
+
+	for i, fld := range fields {
+		if fld.Err != exp[i] {
+			t.Fatalf("Should list the accepted values: exp %q, got %q", exp[i], fld.Err)
+		}
+	}
+
+	if msg := fields.Localize("es")[0].Err; msg != "roles[0] debe ser uno de ADMIN, USER" {
+		t.Fatalf("Should write the message in the locale: got %s", msg)
+	}
+}
@@ -0,0 +1,57 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+
+	"encore.dev/beta/errs"
+)
+
+// NewNotFound constructs an encore error for an entity that doesn't exist.
+func NewNotFound(entity string, id any) *errs.Error {
+	return &errs.Error{
+		Code:    errs.NotFound,
+		Message: sanitize(fmt.Sprintf("%s[%v] not found", entity, id)),
+		Details: CodeDetails{
+			Code: AppNotFound,
+		},
+	}
+}
+
+// NewConflict constructs an encore error for a value of a field that must be
+// unique and is already in use.
+func NewConflict(field string) *errs.Error {
+	return &errs.Error{
+		Code:    errs.AlreadyExists,
+		Message: fmt.Sprintf("%s is already in use", field),
+		Details: FieldDetails{
+			Code: AppConflict,
+			Fields: FieldErrors{
+				{Field: field, Err: "already in use"},
+			},
+		},
+	}
+}
+
+// As returns the encore error in the error's chain.
+func As(err error) (*errs.Error, bool) {
+	var ee *errs.Error
+	if !errors.As(err, &ee) {
+		return nil, false
+	}
+
+	return ee, true
+}
+
+// IsCode reports whether the error is an encore error with the code.
+func IsCode(err error, code errs.ErrCode) bool {
+	ee, ok := As(err)
+	return ok && ee.Code == code
+}
+
+// IsAppCode reports whether the error is an encore error with the
+// application error code.
+func IsAppCode(err error, code AppCode) bool {
+	ee, ok := As(err)
+	return ok && GetAppCode(ee) == code
+}
@@ -0,0 +1,77 @@
+package errs
+
+import (
+	"strings"
+	"sync"
+
+	"encore.dev/beta/errs"
+)
+
+// Translator translates the message of an error into the locale of the
+// request. The application code of the error is provided, or an empty code
+// for a field error, so a message can be looked up by its code instead of
+// its text.
+type Translator interface {
+	Translate(locale string, code AppCode, msg string) string
+}
+
+// PassThrough is the translator used until one is set. It returns every
+// message as is.
+type PassThrough struct{}
+
+// Translate implements the Translator interface.
+func (PassThrough) Translate(locale string, code AppCode, msg string) string {
+	return msg
+}
+
+// localizer holds the translator consulted by Localize.
+var localizer = struct {
+	sync.RWMutex
+	t Translator
+}{
+	t: PassThrough{},
+}
+
+// SetTranslator sets the translator consulted by Localize.
+func SetTranslator(t Translator) {
+	localizer.Lock()
+	defer localizer.Unlock()
+
+	localizer.t = t
+}
+
+// Localize returns a copy of the error with the message translated into the
+// locale by the translator that is set. The messages of any field errors
+// are written in the locale by the validator when it can, and are otherwise
+// translated by their text.
+func Localize(err error, locale string) *errs.Error {
+	localizer.RLock()
+	t := localizer.t
+	localizer.RUnlock()
+
+	e := toError(err)
+
+	code := GetAppCode(&e)
+	if fd, ok := e.Details.(FieldDetails); ok {
+		code = ""
+
+		fd.Fields = fd.Fields.Localize(locale)
+		e.Details = fd
+
+		if i := strings.Index(e.Message, "["); i != -1 {
+			e.Message = e.Message[:i] + fd.Fields.Error()
+		}
+	}
+
+	msg := e.Message
+
+	le := Translate(&e, func(msg string) string {
+		return t.Translate(locale, "", msg)
+	})
+
+	if code != "" {
+		le.Message = t.Translate(locale, code, msg)
+	}
+
+	return le
+}
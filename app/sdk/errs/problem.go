@@ -0,0 +1,100 @@
+package errs
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"encore.dev/beta/errs"
+)
+
+// ProblemContentType is the content type of an RFC 7807 problem details
+// response.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an error rendered as an RFC 7807 problem details object for
+// consumers that expect the standard instead of the encore error format.
+// The application code, request ID, fields and retry are extension members.
+type Problem struct {
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail"`
+	Instance  string      `json:"instance,omitempty"`
+	Code      AppCode     `json:"code,omitempty"`
+	RequestID string      `json:"requestID,omitempty"`
+	Fields    FieldErrors `json:"fields,omitempty"`
+
+	RetryAfterSeconds int        `json:"retryAfterSeconds,omitempty"`
+	RateLimit         *RateLimit `json:"rateLimit,omitempty"`
+}
+
+// NewProblem constructs the problem details for the error. The type is
+// about:blank, so the title is the text of the HTTP status.
+func NewProblem(err error, instance string) Problem {
+	e := toError(err)
+	status := e.Code.HTTPStatus()
+
+	p := Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   e.Message,
+		Instance: instance,
+		Code:     GetAppCode(&e),
+	}
+
+	details := e.Details
+	if rd, ok := details.(RequestDetails); ok {
+		p.RequestID = rd.RequestID
+		details = rd.Details
+	}
+
+	switch d := details.(type) {
+	case FieldDetails:
+		p.Fields = d.Fields
+	case RetryDetails:
+		p.RetryAfterSeconds = d.RetryAfterSeconds
+		p.RateLimit = d.RateLimit
+	}
+
+	return p
+}
+
+// WantsProblem reports whether the Accept header asks for problem details.
+func WantsProblem(accept string) bool {
+	for _, value := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err == nil && mediaType == ProblemContentType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WriteProblem writes the error to a raw endpoint response as problem
+// details.
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error) {
+	p := NewProblem(err, r.URL.Path)
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// HTTPError writes the error to a raw endpoint response, as problem details
+// when the client asks for them and in the encore error format otherwise.
+// The error is classified with the registered sentinel errors first, like
+// the errors of the other endpoints are by the errors middleware.
+func HTTPError(w http.ResponseWriter, r *http.Request, err error) {
+	err = From(err)
+
+	if WantsProblem(r.Header.Get("Accept")) {
+		WriteProblem(w, r, err)
+		return
+	}
+
+	errs.HTTPError(w, err)
+}
@@ -0,0 +1,79 @@
+package errs_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func Test_WriteProblem(t *testing.T) {
+	type user struct {
+		Name string `json:"name" validate:"required"`
+	}
+
+	err := errs.NewFieldErrors(fmt.Errorf("validate: %w", errs.Check(user{})))
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/export/users", nil)
+	r.Header.Set("Accept", "text/csv, application/problem+json")
+
+	if !errs.WantsProblem(r.Header.Get("Accept")) {
+		t.Fatalf("Should want problem details")
+	}
+
+	w := httptest.NewRecorder()
+	errs.WriteProblem(w, r, errs.WithRequestID(err, "abc"))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Should get a bad request status: got %d", w.Code)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != errs.ProblemContentType {
+		t.Fatalf("Should get the problem content type: got %s", ct)
+	}
+
+	var p errs.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("Should decode the problem: %s", err)
+	}
+
+	exp := errs.Problem{
+		Type:      "about:blank",
+		Title:     "Bad Request",
+		Status:    http.StatusBadRequest,
+		Detail:    `validate: [{"field":"name","error":"name is a required field"}]`,
+		Instance:  "/v1/export/users",
+		Code:      errs.AppValidation,
+		RequestID: "abc",
+		Fields:    errs.FieldErrors{{Field: "name", Err: "name is a required field"}},
+	}
+
+	if diff := cmp.Diff(p, exp, cmpopts.IgnoreUnexported(errs.FieldError{})); diff != "" {
+		t.Fatalf("Should get the problem details:\n%s", diff)
+	}
+
+	if errs.WantsProblem("application/json") {
+		t.Fatalf("Should not want problem details for json")
+	}
+}
+
+func Test_ProblemRetry(t *testing.T) {
+	rl := errs.NewRateLimit(2, 0, 1500*time.Millisecond)
+
+	p := errs.NewProblem(errs.NewRateLimited(errs.ResourceExhausted, rl, errors.New("queue full")), "/v1/export/users")
+
+	if p.Status != http.StatusTooManyRequests {
+		t.Fatalf("Should get a too many requests status: got %d", p.Status)
+	}
+
+	if p.RetryAfterSeconds != 2 || p.RateLimit == nil || p.RateLimit.Limit != 2 {
+		t.Fatalf("Should get the retry: got %d %+v", p.RetryAfterSeconds, p.RateLimit)
+	}
+}
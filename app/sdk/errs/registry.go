@@ -0,0 +1,35 @@
+package errs
+
+import (
+	"sync"
+
+	"encore.dev/beta/errs"
+)
+
+// registry holds the class registered for each sentinel error.
+var registry = struct {
+	sync.RWMutex
+	classes Classes
+}{
+	classes: Classes{},
+}
+
+// Register records the class an app error is given for a sentinel error.
+// The app package that owns a domain registers the sentinel errors of its
+// business package when it's initialized, so the mapping lives next to the
+// code that returns them instead of in each handler.
+func Register(target error, class Class) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.classes[target] = class
+}
+
+// From returns the error as an encore error, classified with the registered
+// sentinel errors.
+func From(err error) *errs.Error {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	return registry.classes.Classify(err)
+}
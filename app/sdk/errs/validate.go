@@ -1,13 +1,19 @@
 package errs
 
 import (
+	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 
+	"github.com/ardanlabs/encore/business/sdk/enum"
 	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	en_translations "github.com/go-playground/validator/v10/translations/en"
+	es_translations "github.com/go-playground/validator/v10/translations/es"
+	"github.com/google/uuid"
 )
 
 // validate holds the settings and caches for validating request struct values.
@@ -16,17 +22,52 @@ var validate *validator.Validate
 // translator is a cache of locale and translation information.
 var translator ut.Translator
 
+// translators holds the translator for each locale the messages of field
+// errors can be written in.
+var translators = map[string]ut.Translator{}
+
 func init() {
 
 	// Instantiate a validator.
 	validate = validator.New(validator.WithRequiredStructEnabled())
 
-	// Create a translator for english so the error messages are
-	// more human-readable than technical.
-	translator, _ = ut.New(en.New(), en.New()).GetTranslator("en")
+	// Create a translator for each supported language so the error
+	// messages are more human-readable than technical.
+	uni := ut.New(en.New(), en.New(), es.New())
+	translator, _ = uni.GetTranslator("en")
+	esTranslator, _ := uni.GetTranslator("es")
+
+	translators["en"] = translator
+	translators["es"] = esTranslator
 
-	// Register the english error messages for use.
+	// Register the error messages for use.
 	en_translations.RegisterDefaultTranslations(validate, translator)
+	es_translations.RegisterDefaultTranslations(validate, esTranslator)
+
+	// Register the messages for the tags in use that don't have one.
+	registerTranslation(translator, "iso3166_1_alpha2", "{0} must be a valid country code")
+	registerTranslation(esTranslator, "iso3166_1_alpha2", "{0} debe ser un código de país válido")
+	registerTranslation(translator, "startswith", "{0} must start with {1}")
+	registerTranslation(esTranslator, "startswith", "{0} debe empezar con {1}")
+
+	// Replace the messages of the conditional tags so they say what the
+	// field depends on.
+	registerCondition(translator, "required_if", "{0} is required when {1}", "is")
+	registerCondition(esTranslator, "required_if", "{0} es requerido cuando {1}", "es")
+	registerCondition(translator, "required_unless", "{0} is required unless {1}", "is")
+	registerCondition(esTranslator, "required_unless", "{0} es requerido a menos que {1}", "es")
+	registerCondition(translator, "required_with", "{0} is required when {1} is provided", "is")
+	registerCondition(esTranslator, "required_with", "{0} es requerido cuando se proporciona {1}", "es")
+	registerCondition(translator, "required_without", "{0} is required when {1} is not provided", "is")
+	registerCondition(esTranslator, "required_without", "{0} es requerido cuando no se proporciona {1}", "es")
+	registerCondition(translator, "excluded_if", "{0} must not be provided when {1}", "is")
+	registerCondition(esTranslator, "excluded_if", "{0} no debe proporcionarse cuando {1}", "es")
+	registerCondition(translator, "excluded_unless", "{0} must not be provided unless {1}", "is")
+	registerCondition(esTranslator, "excluded_unless", "{0} no debe proporcionarse a menos que {1}", "es")
+	registerCondition(translator, "excluded_with", "{0} must not be provided with {1}", "is")
+	registerCondition(esTranslator, "excluded_with", "{0} no debe proporcionarse con {1}", "es")
+	registerCondition(translator, "excluded_without", "{0} must not be provided without {1}", "is")
+	registerCondition(esTranslator, "excluded_without", "{0} no debe proporcionarse sin {1}", "es")
 
 	// Use JSON tag names for errors instead of Go struct names.
 	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
@@ -36,6 +77,171 @@ func init() {
 		}
 		return name
 	})
+
+	RegisterValidation("id", "{0} must be a valid ID", func(value string) error {
+		_, err := uuid.Parse(value)
+		return err
+	})
+
+	RegisterValidation("phone", "{0} must be a valid phone number", func(value string) error {
+		if !phone.MatchString(value) {
+			return fmt.Errorf("invalid phone number %q", value)
+		}
+		return nil
+	})
+
+	// The enum tag accepts a value of the business enum named by the
+	// parameter, like enum=role, so the API accepts the same values the
+	// business layer parses.
+	if err := validate.RegisterValidation("enum", validEnum); err != nil {
+		panic(fmt.Sprintf("register validation enum: %s", err))
+	}
+
+	registerEnum(translator, "{0} must be one of {1}")
+	registerEnum(esTranslator, "{0} debe ser uno de {1}")
+}
+
+// phone matches a phone number in the E.164 format, like +14155552671.
+var phone = regexp.MustCompile(`^\+[1-9]\d{1,14}$`)
+
+// RegisterValidation adds a validator for the tag that accepts the value of
+// a field when check returns no error, so a model can declare the check in
+// its tags instead of repeating it where the model is converted. The message
+// is returned for a value that fails, with {0} for the name of the field.
+// It's meant to be called when a package is initialized and panics if the
+// tag can't be registered.
+func RegisterValidation[T any](tag string, msg string, check func(value T) error) {
+	fn := func(fl validator.FieldLevel) bool {
+		value, ok := fl.Field().Interface().(T)
+		return ok && check(value) == nil
+	}
+
+	if err := validate.RegisterValidation(tag, fn); err != nil {
+		panic(fmt.Sprintf("register validation %s: %s", tag, err))
+	}
+
+	register := func(ut ut.Translator) error {
+		return ut.Add(tag, msg, true)
+	}
+
+	translate := func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T(tag, fe.Field())
+		return t
+	}
+
+	if err := validate.RegisterTranslation(tag, translator, register, translate); err != nil {
+		panic(fmt.Sprintf("register translation %s: %s", tag, err))
+	}
+}
+
+// validEnum reports whether the value of the field is in the enum named by
+// the parameter of the tag.
+func validEnum(fl validator.FieldLevel) bool {
+	e, exists := enum.Lookup(fl.Param())
+	if !exists {
+		return false
+	}
+
+	value, ok := fl.Field().Interface().(string)
+	return ok && e.Valid(value)
+}
+
+// registerEnum adds the message for the enum tag to the translator, with {0}
+// for the name of the field and {1} for the values the enum accepts.
+func registerEnum(trans ut.Translator, msg string) {
+	register := func(ut ut.Translator) error {
+		return ut.Add("enum", msg, true)
+	}
+
+	translate := func(ut ut.Translator, fe validator.FieldError) string {
+		var names []string
+		if e, exists := enum.Lookup(fe.Param()); exists {
+			names = e.Names()
+		}
+
+		t, _ := ut.T("enum", fe.Field(), strings.Join(names, ", "))
+		return t
+	}
+
+	if err := validate.RegisterTranslation("enum", trans, register, translate); err != nil {
+		panic(fmt.Sprintf("register translation enum: %s", err))
+	}
+}
+
+// registerTranslation adds the message for the tag to the translator, with
+// {0} for the name of the field and {1} for the parameter of the tag.
+func registerTranslation(trans ut.Translator, tag string, msg string) {
+	register := func(ut ut.Translator) error {
+		return ut.Add(tag, msg, true)
+	}
+
+	translate := func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T(tag, fe.Field(), fe.Param())
+		return t
+	}
+
+	if err := validate.RegisterTranslation(tag, trans, register, translate); err != nil {
+		panic(fmt.Sprintf("register translation %s: %s", tag, err))
+	}
+}
+
+// registerCondition adds the message for a conditional tag to the translator,
+// with {0} for the name of the field and {1} for the condition. The
+// parameter of the tag names the other fields by their struct name, so
+// they are written in lower camel case like the JSON names. The _if and
+// _unless tags pair each field with a value, which are joined by is.
+func registerCondition(trans ut.Translator, tag string, msg string, is string) {
+	register := func(ut ut.Translator) error {
+		return ut.Add(tag, msg, true)
+	}
+
+	translate := func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T(tag, fe.Field(), condition(tag, fe.Param(), is))
+		return t
+	}
+
+	if err := validate.RegisterTranslation(tag, trans, register, translate); err != nil {
+		panic(fmt.Sprintf("register translation %s: %s", tag, err))
+	}
+}
+
+// condition writes the parameter of a conditional tag for a message.
+func condition(tag string, param string, is string) string {
+	words := strings.Fields(param)
+
+	if !strings.HasSuffix(tag, "_if") && !strings.HasSuffix(tag, "_unless") {
+		for i := range words {
+			words[i] = lowerFirst(words[i])
+		}
+		return strings.Join(words, ", ")
+	}
+
+	var pairs []string
+	for i := 0; i+1 < len(words); i += 2 {
+		pairs = append(pairs, lowerFirst(words[i])+" "+is+" "+words[i+1])
+	}
+
+	return strings.Join(pairs, ", ")
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// fieldMessage returns the message for the field error from the translator. A
+// tag without a message would return the technical text of the validator,
+// so false is returned instead.
+func fieldMessage(fe validator.FieldError, trans ut.Translator) (string, bool) {
+	msg := fe.Translate(trans)
+	if msg == "" || msg == fe.Error() {
+		return "", false
+	}
+
+	return msg, true
 }
 
 // Check validates the provided model against it's declared tags.
@@ -48,9 +254,15 @@ func Check(val any) error {
 
 		var fields FieldErrors
 		for _, verror := range verrors {
+			msg, ok := fieldMessage(verror, translator)
+			if !ok {
+				msg = verror.Field() + " is not valid"
+			}
+
 			fields = append(fields, FieldError{
-				Field: verror.Field(),
-				Err:   verror.Translate(translator),
+				Field:  verror.Field(),
+				Err:    msg,
+				verror: verror,
 			})
 		}
 
@@ -0,0 +1,57 @@
+// Package etag provides support for entity tags and conditional requests so
+// clients can't overwrite changes they haven't seen.
+package etag
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+)
+
+// Set of error variables for conditional requests.
+var (
+	ErrMissing  = errors.New("If-Match header is required")
+	ErrMismatch = errors.New("entity has been modified")
+)
+
+// Precondition represents the conditional headers for a request that
+// doesn't have a body, like a delete.
+type Precondition struct {
+	IfMatch string `header:"If-Match"`
+}
+
+// New constructs an entity tag from the time the entity was last updated.
+// The time is truncated to microseconds like the database does when it's
+// stored, so the tag for a value that was just written matches the tag after
+// it's read back.
+func New(dateUpdated time.Time) string {
+	micro := dateUpdated.Truncate(time.Microsecond).UnixMicro()
+	return `"` + strconv.FormatInt(micro, 36) + `"`
+}
+
+// Check validates the If-Match header against the entity tag for the time
+// the entity was last updated. A FailedPrecondition error is returned when
+// the header is missing or none of the tags match.
+func Check(ifMatch string, dateUpdated time.Time) error {
+	ifMatch = strings.TrimSpace(ifMatch)
+	if ifMatch == "" {
+		return errs.New(errs.FailedPrecondition, ErrMissing)
+	}
+
+	if ifMatch == "*" {
+		return nil
+	}
+
+	current := New(dateUpdated)
+
+	for _, tag := range strings.Split(ifMatch, ",") {
+		if strings.TrimSpace(tag) == current {
+			return nil
+		}
+	}
+
+	return errs.New(errs.FailedPrecondition, ErrMismatch)
+}
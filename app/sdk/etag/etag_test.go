@@ -0,0 +1,47 @@
+package etag_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	eerrs "encore.dev/beta/errs"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/etag"
+)
+
+func Test_Check(t *testing.T) {
+	updated := time.Date(2024, 6, 1, 12, 30, 0, 123456789, time.UTC)
+
+	// The database truncates to microseconds so the tag must not change
+	// when the value is read back.
+	if etag.New(updated) != etag.New(updated.Truncate(time.Microsecond)) {
+		t.Fatalf("Should get the same tag after the time is stored")
+	}
+
+	tests := []struct {
+		name    string
+		ifMatch string
+		code    eerrs.ErrCode
+	}{
+		{"match", etag.New(updated), eerrs.OK},
+		{"list", `"other", ` + etag.New(updated), eerrs.OK},
+		{"any", "*", eerrs.OK},
+		{"missing", "", errs.FailedPrecondition},
+		{"mismatch", etag.New(updated.Add(time.Second)), errs.FailedPrecondition},
+	}
+
+	for _, tt := range tests {
+		err := etag.Check(tt.ifMatch, updated)
+
+		var code eerrs.ErrCode
+		var eerr *eerrs.Error
+		if errors.As(err, &eerr) {
+			code = eerr.Code
+		}
+
+		if code != tt.code {
+			t.Fatalf("%s: Should get code %s: got %s", tt.name, tt.code, code)
+		}
+	}
+}
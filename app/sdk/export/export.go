@@ -0,0 +1,296 @@
+// Package export provides support for downloading query results as CSV or
+// Excel workbooks. The results come from the app layer stream functions which
+// read the rows from the database one at a time, using the same filters and
+// ordering as the JSON endpoints.
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/ardanlabs/encore/app/sdk/fields"
+	"github.com/ardanlabs/encore/app/sdk/query"
+	"github.com/ardanlabs/encore/foundation/web"
+	"github.com/ardanlabs/encore/foundation/xlsx"
+)
+
+// flushEvery is the number of rows written before they are flushed to the
+// client.
+const flushEvery = 100
+
+// ErrInterrupted is returned when the export fails after the header has
+// been written. The response is left incomplete since the status code has
+// already been sent and an error can't be written in the format.
+var ErrInterrupted = errors.New("export interrupted")
+
+// QueryFunc represents an app layer function that returns every item that
+// matches the query one at a time.
+type QueryFunc[Q any, T any] func(ctx context.Context, qp Q) (iter.Seq2[T, error], error)
+
+// Func represents a function that writes the results of a query described
+// by the query string values.
+type Func func(ctx context.Context, w io.Writer, values url.Values) error
+
+// CSV constructs a function that writes every result from the query
+// function as CSV. The values are decoded into the query params the same
+// way Encore decodes a query string. Paging values are ignored since every
+// result is written. The fields value limits the columns that are written.
+func CSV[Q any, T any](fn QueryFunc[Q, T]) Func {
+	return func(ctx context.Context, w io.Writer, values url.Values) error {
+		return run(ctx, values, fn, newCSVEncoder(w))
+	}
+}
+
+// XLSX constructs a function that writes every result from the query
+// function as an Excel workbook with a single sheet using the specified
+// name. The values are handled the same way as CSV.
+func XLSX[Q any, T any](sheetName string, fn QueryFunc[Q, T]) Func {
+	return func(ctx context.Context, w io.Writer, values url.Values) error {
+		enc, err := newXLSXEncoder(w, sheetName)
+		if err != nil {
+			return err
+		}
+
+		return run(ctx, values, fn, enc)
+	}
+}
+
+// =============================================================================
+
+// encoder represents the behavior needed to write the results in a format.
+type encoder interface {
+	write(values []any) error
+	flush() error
+	close() error
+}
+
+func run[Q any, T any](ctx context.Context, values url.Values, fn QueryFunc[Q, T], enc encoder) error {
+	set, err := fields.Parse[T](values.Get("fields"))
+	if err != nil {
+		return fmt.Errorf("fields: %w", err)
+	}
+
+	var qp Q
+	if err := Decode(values, &qp); err != nil {
+		return err
+	}
+
+	// The query is checked before the header is written so an invalid
+	// query can still be reported as an error.
+	seq, err := fn(ctx, qp)
+	if err != nil {
+		return err
+	}
+
+	cols := columns(reflect.TypeFor[T](), nil, "", set)
+
+	header := make([]any, len(cols))
+	for i, col := range cols {
+		header[i] = col.name
+	}
+
+	if err := enc.write(header); err != nil {
+		return interrupted(fmt.Errorf("write header: %w", err))
+	}
+
+	var n int
+	for item, err := range seq {
+		if err != nil {
+			return interrupted(err)
+		}
+
+		if err := enc.write(row(reflect.ValueOf(item), cols)); err != nil {
+			return interrupted(fmt.Errorf("write row: %w", err))
+		}
+
+		if n++; n%flushEvery == 0 {
+			if err := enc.flush(); err != nil {
+				return interrupted(fmt.Errorf("flush: %w", err))
+			}
+		}
+	}
+
+	if err := enc.flush(); err != nil {
+		return interrupted(fmt.Errorf("flush: %w", err))
+	}
+
+	if err := enc.close(); err != nil {
+		return interrupted(fmt.Errorf("close: %w", err))
+	}
+
+	return nil
+}
+
+// interrupted marks an error that happened after the header was written.
+func interrupted(err error) error {
+	return fmt.Errorf("%w: %w", ErrInterrupted, err)
+}
+
+type csvEncoder struct {
+	cw *web.CSV
+}
+
+func newCSVEncoder(w io.Writer) *csvEncoder {
+	return &csvEncoder{
+		cw: web.NewCSV(w),
+	}
+}
+
+func (e *csvEncoder) write(values []any) error {
+	return e.cw.Write(values)
+}
+
+func (e *csvEncoder) flush() error {
+	return e.cw.Flush()
+}
+
+func (e *csvEncoder) close() error {
+	return nil
+}
+
+type xlsxEncoder struct {
+	w  io.Writer
+	xw *xlsx.Writer
+}
+
+func newXLSXEncoder(w io.Writer, sheetName string) (*xlsxEncoder, error) {
+	xw, err := xlsx.NewWriter(w, sheetName)
+	if err != nil {
+		return nil, fmt.Errorf("xlsx: %w", err)
+	}
+
+	enc := xlsxEncoder{
+		w:  w,
+		xw: xw,
+	}
+
+	return &enc, nil
+}
+
+func (e *xlsxEncoder) write(values []any) error {
+	return e.xw.WriteRow(values)
+}
+
+func (e *xlsxEncoder) flush() error {
+	if err := e.xw.Flush(); err != nil {
+		return err
+	}
+
+	web.Flush(e.w)
+
+	return nil
+}
+
+func (e *xlsxEncoder) close() error {
+	return e.xw.Close()
+}
+
+// =============================================================================
+
+// Decode sets the string fields of the struct pointed to by dest from the
+// query string values, using the same names as Encore.
+func Decode(values url.Values, dest any) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decode: dest must be a pointer to a struct: %T", dest)
+	}
+
+	v = v.Elem()
+	t := v.Type()
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() || f.Type.Kind() != reflect.String {
+			continue
+		}
+
+		if value := values.Get(query.ParamName(f)); value != "" {
+			v.Field(i).SetString(value)
+		}
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+type column struct {
+	name  string
+	index []int
+}
+
+// columns returns a column for every field that is encoded to JSON with
+// nested structs flattened using a dotted name.
+func columns(t reflect.Type, index []int, prefix string, set fields.Set) []column {
+	var cols []column
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			tag, _, _ = strings.Cut(tag, ",")
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+
+		// The fields set only applies to the top level fields.
+		if prefix == "" && set != nil {
+			if _, exists := set[name]; !exists {
+				continue
+			}
+		}
+
+		// Links point at other resources and included resources are
+		// optional, so neither are part of the data.
+		if f.Type.Kind() == reflect.Map || f.Type.Kind() == reflect.Pointer {
+			continue
+		}
+
+		idx := append(append([]int{}, index...), i)
+
+		if f.Type.Kind() == reflect.Struct {
+			cols = append(cols, columns(f.Type, idx, prefix+name+".", nil)...)
+			continue
+		}
+
+		cols = append(cols, column{name: prefix + name, index: idx})
+	}
+
+	return cols
+}
+
+func row(v reflect.Value, cols []column) []any {
+	record := make([]any, len(cols))
+
+	for i, col := range cols {
+		fv := v.FieldByIndex(col.index)
+
+		switch fv.Kind() {
+		case reflect.Slice:
+			items := make([]string, fv.Len())
+			for j := range fv.Len() {
+				items[j] = fmt.Sprint(fv.Index(j).Interface())
+			}
+			record[i] = strings.Join(items, ",")
+
+		default:
+			record[i] = fv.Interface()
+		}
+	}
+
+	return record
+}
@@ -0,0 +1,202 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/encore/app/sdk/export"
+)
+
+type queryParams struct {
+	Page    string
+	Rows    string
+	OrderBy string
+	UserID  string
+	CostGTE string `query:"cost[gte]"`
+}
+
+type address struct {
+	City string `json:"city"`
+}
+
+type item struct {
+	ID      string   `json:"id"`
+	Tags    []string `json:"tags"`
+	Address address  `json:"address"`
+	Secret  string   `json:"-"`
+}
+
+func Test_Decode(t *testing.T) {
+	values := url.Values{
+		"page":      {"2"},
+		"order_by":  {"name,DESC"},
+		"user_id":   {"123"},
+		"cost[gte]": {"10"},
+	}
+
+	var qp queryParams
+	if err := export.Decode(values, &qp); err != nil {
+		t.Fatalf("Should be able to decode the values: %s", err)
+	}
+
+	exp := queryParams{Page: "2", OrderBy: "name,DESC", UserID: "123", CostGTE: "10"}
+	if qp != exp {
+		t.Fatalf("Should get the values by name:\ngot: %+v\nexp: %+v", qp, exp)
+	}
+}
+
+func Test_CSV(t *testing.T) {
+	const total = 150
+
+	fn := func(ctx context.Context, qp queryParams) (iter.Seq2[item, error], error) {
+		seq := func(yield func(item, error) bool) {
+			for i := range total {
+				if !yield(item{ID: strconv.Itoa(i), Tags: []string{"a", "b"}, Address: address{City: "Miami"}}, nil) {
+					return
+				}
+			}
+		}
+
+		return seq, nil
+	}
+
+	var buf bytes.Buffer
+	if err := export.CSV(fn)(context.Background(), &buf, url.Values{"fields": {"id,address"}}); err != nil {
+		t.Fatalf("Should be able to export the results: %s", err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != total+1 {
+		t.Fatalf("Should get a line for the header and each item: %d", len(lines))
+	}
+
+	if string(lines[0]) != "id,address.city" || string(lines[1]) != "0,Miami" {
+		t.Fatalf("Should get only the requested columns: %s / %s", lines[0], lines[1])
+	}
+}
+
+func Test_CSVFormula(t *testing.T) {
+	fn := func(ctx context.Context, qp queryParams) (iter.Seq2[item, error], error) {
+		items := []item{
+			{ID: "=1+2", Address: address{City: "-10"}},
+			{ID: "@SUM(A1)", Address: address{City: "+cmd"}},
+		}
+
+		return values(items), nil
+	}
+
+	var buf bytes.Buffer
+	if err := export.CSV(fn)(context.Background(), &buf, url.Values{"fields": {"id,address"}}); err != nil {
+		t.Fatalf("Should be able to export the results: %s", err)
+	}
+
+	exp := "id,address.city\n'=1+2,-10\n'@SUM(A1),'+cmd\n"
+	if buf.String() != exp {
+		t.Fatalf("Should prefix values that start like a formula:\ngot: %q\nexp: %q", buf.String(), exp)
+	}
+}
+
+func Test_Interrupted(t *testing.T) {
+	fn := func(ctx context.Context, qp queryParams) (iter.Seq2[item, error], error) {
+		seq := func(yield func(item, error) bool) {
+			if !yield(item{ID: "1"}, nil) {
+				return
+			}
+
+			yield(item{}, errors.New("connection reset"))
+		}
+
+		return seq, nil
+	}
+
+	var buf bytes.Buffer
+	err := export.CSV(fn)(context.Background(), &buf, url.Values{"fields": {"id"}})
+	if !errors.Is(err, export.ErrInterrupted) {
+		t.Fatalf("Should get an interrupted error: %v", err)
+	}
+
+	if strings.Contains(buf.String(), "connection reset") {
+		t.Fatalf("Should not write the error into the export: %s", buf.String())
+	}
+}
+
+func Test_QueryError(t *testing.T) {
+	fn := func(ctx context.Context, qp queryParams) (iter.Seq2[item, error], error) {
+		return nil, errors.New("invalid order")
+	}
+
+	var buf bytes.Buffer
+	err := export.CSV(fn)(context.Background(), &buf, url.Values{})
+	if err == nil || errors.Is(err, export.ErrInterrupted) {
+		t.Fatalf("Should get the query error before anything is written: %v", err)
+	}
+
+	if buf.Len() != 0 {
+		t.Fatalf("Should not write anything: %s", buf.String())
+	}
+}
+
+func Test_XLSX(t *testing.T) {
+	fn := func(ctx context.Context, qp queryParams) (iter.Seq2[item, error], error) {
+		items := []item{
+			{ID: "1", Address: address{City: "Miami & Beach"}},
+		}
+
+		return values(items), nil
+	}
+
+	var buf bytes.Buffer
+	if err := export.XLSX("Items", fn)(context.Background(), &buf, url.Values{"fields": {"id,address"}}); err != nil {
+		t.Fatalf("Should be able to export the results: %s", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Should be able to open the workbook: %s", err)
+	}
+
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("Should be able to open the sheet: %s", err)
+		}
+
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("Should be able to read the sheet: %s", err)
+		}
+
+		sheet = string(data)
+	}
+
+	for _, cell := range []string{">id<", ">address.city<", ">1<", ">Miami &amp; Beach<"} {
+		if !strings.Contains(sheet, cell) {
+			t.Fatalf("Should find %q in the sheet: %s", cell, sheet)
+		}
+	}
+}
+
+// values returns a sequence of the items without errors.
+func values(items []item) iter.Seq2[item, error] {
+	return func(yield func(item, error) bool) {
+		for _, itm := range items {
+			if !yield(itm, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Package fields provides support for sparse fieldsets, allowing clients to
+// request only specific fields of a response.
+package fields
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Set represents the fields a client asked for. A nil set means all fields.
+type Set map[string]struct{}
+
+// Parse parses a comma separated list of field names and validates each name
+// against the json names of the specified type's fields.
+func Parse[T any](fields string) (Set, error) {
+	if fields == "" {
+		return nil, nil
+	}
+
+	known := names(reflect.TypeFor[T]())
+
+	set := make(Set)
+	for _, name := range strings.Split(fields, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		if _, exists := known[name]; !exists {
+			return nil, fmt.Errorf("unknown field: %s", name)
+		}
+
+		set[name] = struct{}{}
+	}
+
+	return set, nil
+}
+
+// Marshal encodes the value to JSON keeping only the fields in the set. The
+// value must encode to a JSON object. A nil set encodes all the fields.
+func Marshal(v any, set Set) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || set == nil {
+		return data, err
+	}
+
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	for key := range m {
+		if _, exists := set[key]; !exists {
+			delete(m, key)
+		}
+	}
+
+	return json.Marshal(m)
+}
+
+// names returns the set of json names for the fields of the struct type.
+func names(t reflect.Type) map[string]struct{} {
+	known := make(map[string]struct{})
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			tag, _, _ = strings.Cut(tag, ",")
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+
+		known[name] = struct{}{}
+	}
+
+	return known
+}
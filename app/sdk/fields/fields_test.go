@@ -0,0 +1,76 @@
+package fields_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/encore/app/sdk/fields"
+)
+
+type user struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"-"`
+}
+
+func Test_Parse(t *testing.T) {
+	set, err := fields.Parse[user]("")
+	if err != nil {
+		t.Fatalf("Should be able to parse an empty list: %s", err)
+	}
+
+	if set != nil {
+		t.Fatalf("Should get a nil set for an empty list: %v", set)
+	}
+
+	set, err = fields.Parse[user]("id, name")
+	if err != nil {
+		t.Fatalf("Should be able to parse known fields: %s", err)
+	}
+
+	if len(set) != 2 {
+		t.Fatalf("Should get two fields in the set: %v", set)
+	}
+
+	if _, err := fields.Parse[user]("id,unknown"); err == nil {
+		t.Fatalf("Should get an error for an unknown field")
+	}
+
+	if _, err := fields.Parse[user]("Password"); err == nil {
+		t.Fatalf("Should get an error for a field that isn't encoded")
+	}
+}
+
+func Test_Marshal(t *testing.T) {
+	u := user{
+		ID:       "1",
+		Name:     "Bill",
+		Email:    "bill@example.com",
+		Password: "secret",
+	}
+
+	data, err := fields.Marshal(u, nil)
+	if err != nil {
+		t.Fatalf("Should be able to marshal all fields: %s", err)
+	}
+
+	exp := `{"id":"1","name":"Bill","email":"bill@example.com"}`
+	if string(data) != exp {
+		t.Fatalf("Should get all the fields:\ngot: %s\nexp: %s", data, exp)
+	}
+
+	set, err := fields.Parse[user]("name,id")
+	if err != nil {
+		t.Fatalf("Should be able to parse known fields: %s", err)
+	}
+
+	data, err = fields.Marshal(u, set)
+	if err != nil {
+		t.Fatalf("Should be able to marshal the projected fields: %s", err)
+	}
+
+	exp = `{"id":"1","name":"Bill"}`
+	if string(data) != exp {
+		t.Fatalf("Should get only the requested fields:\ngot: %s\nexp: %s", data, exp)
+	}
+}
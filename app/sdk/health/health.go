@@ -0,0 +1,162 @@
+// Package health provides support for the liveness and readiness checks.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ardanlabs/encore/app/sdk/encoder"
+)
+
+// Set of statuses a service or dependency can report.
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// timeout is how long a dependency has to respond when the caller doesn't
+// set a deadline.
+const timeout = 2 * time.Second
+
+// CheckFunc returns nil if the dependency can be used.
+type CheckFunc func(ctx context.Context) error
+
+// Check represents a named dependency of the service.
+type Check struct {
+	Name  string
+	Check CheckFunc
+}
+
+// Dependency represents the status of a single dependency.
+type Dependency struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report represents the status of the service and its dependencies.
+type Report struct {
+	Status       string                `json:"status"`
+	Dependencies map[string]Dependency `json:"dependencies,omitempty"`
+}
+
+// Encode implments the encoder interface.
+func (r Report) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	return data, "application/json", err
+}
+
+// ErrDetails implements the encore ErrDetails interface so the report can
+// be returned with an error.
+func (Report) ErrDetails() {}
+
+// Up reports if the service and all its dependencies are up.
+func (r Report) Up() bool {
+	return r.Status == StatusUp
+}
+
+// =============================================================================
+
+// Checker runs the checks for the dependencies of the service.
+type Checker struct {
+	checks []Check
+}
+
+// New constructs a checker for the specified dependencies.
+func New(checks ...Check) *Checker {
+	return &Checker{
+		checks: checks,
+	}
+}
+
+// Liveness reports the service is running. The dependencies aren't checked
+// since a restart won't fix a database that is down.
+func (c *Checker) Liveness() Report {
+	return Report{
+		Status: StatusUp,
+	}
+}
+
+// Readiness runs all the checks at the same time and reports the status of
+// each dependency. The service is only up when every dependency is up.
+func (c *Checker) Readiness(ctx context.Context) Report {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	deps := make([]Dependency, len(c.checks))
+
+	var wg sync.WaitGroup
+	wg.Add(len(c.checks))
+
+	for i, chk := range c.checks {
+		go func() {
+			defer wg.Done()
+
+			start := time.Now()
+			err := chk.Check(ctx)
+
+			dep := Dependency{
+				Status:   StatusUp,
+				Duration: time.Since(start).String(),
+			}
+
+			if err != nil {
+				dep.Status = StatusDown
+				dep.Error = err.Error()
+			}
+
+			deps[i] = dep
+		}()
+	}
+
+	wg.Wait()
+
+	report := Report{
+		Status:       StatusUp,
+		Dependencies: make(map[string]Dependency, len(c.checks)),
+	}
+
+	for i, chk := range c.checks {
+		report.Dependencies[chk.Name] = deps[i]
+
+		if deps[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+
+	return report
+}
+
+// =============================================================================
+
+// LivenessHandler returns a handler that writes the liveness report for the
+// debug mux.
+func (c *Checker) LivenessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		write(w, r, c.Liveness())
+	}
+}
+
+// ReadinessHandler returns a handler that writes the readiness report for
+// the debug mux. A service that isn't ready responds with a 503 so a load
+// balancer stops sending it traffic.
+func (c *Checker) ReadinessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		write(w, r, c.Readiness(r.Context()))
+	}
+}
+
+func write(w http.ResponseWriter, r *http.Request, report Report) {
+	status := http.StatusOK
+	if !report.Up() {
+		status = http.StatusServiceUnavailable
+	}
+
+	encoder.Default.Write(w, r, status, report)
+}
@@ -0,0 +1,64 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/encore/app/sdk/health"
+)
+
+func Test_Readiness(t *testing.T) {
+	up := health.Check{
+		Name:  "database",
+		Check: func(ctx context.Context) error { return nil },
+	}
+
+	down := health.Check{
+		Name:  "cache",
+		Check: func(ctx context.Context) error { return errors.New("connection refused") },
+	}
+
+	report := health.New(up).Readiness(context.Background())
+	if !report.Up() {
+		t.Fatalf("Should be up when every dependency is up: %+v", report)
+	}
+
+	report = health.New(up, down).Readiness(context.Background())
+	if report.Up() {
+		t.Fatalf("Should be down when a dependency is down: %+v", report)
+	}
+
+	if dep := report.Dependencies["database"]; dep.Status != health.StatusUp {
+		t.Fatalf("Should get the database as up: %+v", dep)
+	}
+
+	if dep := report.Dependencies["cache"]; dep.Status != health.StatusDown || dep.Error != "connection refused" {
+		t.Fatalf("Should get the cache as down with the error: %+v", dep)
+	}
+}
+
+func Test_Handlers(t *testing.T) {
+	down := health.Check{
+		Name:  "database",
+		Check: func(ctx context.Context) error { return errors.New("timeout") },
+	}
+
+	chk := health.New(down)
+
+	w := httptest.NewRecorder()
+	chk.LivenessHandler()(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should get a 200 for liveness with a dependency down: got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	chk.ReadinessHandler()(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Should get a 503 for readiness with a dependency down: got %d", w.Code)
+	}
+}
@@ -0,0 +1,241 @@
+// Package i18n provides support for translating the messages returned to
+// users into the language they ask for with the Accept-Language header.
+// Messages are written in English in the code and the catalogs embedded in
+// the binary map them to the other supported languages.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"golang.org/x/text/language"
+)
+
+// Header is the request header a client uses to ask for a language.
+const Header = "Accept-Language"
+
+// Language represents a language messages can be returned in.
+type Language string
+
+// Set of supported languages.
+const (
+	English Language = "en"
+	Spanish Language = "es"
+)
+
+// Default is the language the messages are written in and is used when the
+// client doesn't ask for a supported language.
+const Default = English
+
+// supported is the set of languages in the order they are preferred when a
+// client's preferences are equal.
+var supported = []language.Tag{
+	language.English,
+	language.Spanish,
+}
+
+var matcher = language.NewMatcher(supported)
+
+// Parse returns the supported language that best matches the value of an
+// Accept-Language header.
+func Parse(acceptLanguage string) Language {
+	if acceptLanguage == "" {
+		return Default
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+
+	_, idx, conf := matcher.Match(tags...)
+	if conf == language.No {
+		return Default
+	}
+
+	base, _ := supported[idx].Base()
+	return Language(base.String())
+}
+
+// =============================================================================
+
+//go:embed catalogs/*.json
+var catalogFS embed.FS
+
+// message is a message from a catalog. The English template is compiled to
+// match a message with the values of the placeholders, like {0}, captured so
+// they can be written into the translation.
+type message struct {
+	match       *regexp.Regexp
+	names       []string
+	translation string
+}
+
+// catalogs holds the messages for each language other than the default.
+var catalogs = map[Language][]message{}
+
+// codes holds the messages for each language other than the default that
+// are looked up by the application error code instead of the text.
+var codes = map[Language]map[string]string{}
+
+var placeholder = regexp.MustCompile(`\\\{(\d+)\\\}`)
+
+// code matches a catalog entry keyed by an application error code.
+var code = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+func init() {
+	files, err := catalogFS.ReadDir("catalogs")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, file := range files {
+		data, err := catalogFS.ReadFile(path.Join("catalogs", file.Name()))
+		if err != nil {
+			panic(err)
+		}
+
+		lang := Language(strings.TrimSuffix(file.Name(), ".json"))
+
+		msgs, byCode, err := parseCatalog(data)
+		if err != nil {
+			panic(fmt.Sprintf("catalog %s: %s", file.Name(), err))
+		}
+
+		catalogs[lang] = msgs
+		codes[lang] = byCode
+	}
+}
+
+func parseCatalog(data []byte) ([]message, map[string]string, error) {
+	var entries map[string]string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, nil, err
+	}
+
+	msgs := make([]message, 0, len(entries))
+	byCode := make(map[string]string)
+
+	for template, translation := range entries {
+		if code.MatchString(template) {
+			byCode[template] = translation
+			continue
+		}
+
+		var names []string
+		expr := placeholder.ReplaceAllStringFunc(regexp.QuoteMeta(template), func(s string) string {
+			names = append(names, placeholder.FindStringSubmatch(s)[1])
+			return "(.*?)"
+		})
+
+		match, err := regexp.Compile("^" + expr + "$")
+		if err != nil {
+			return nil, nil, fmt.Errorf("template %q: %w", template, err)
+		}
+
+		msgs = append(msgs, message{
+			match:       match,
+			names:       names,
+			translation: translation,
+		})
+	}
+
+	// The longest templates are the most specific so they are tried first,
+	// which also keeps the matching the same from run to run.
+	sort.Slice(msgs, func(i, j int) bool {
+		a, b := msgs[i].match.String(), msgs[j].match.String()
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a < b
+	})
+
+	return msgs, byCode, nil
+}
+
+// Translate returns the message in the specified language. Messages are
+// often prefixed with the context they failed in, like "query: ", so when
+// the whole message isn't in the catalog the text after each ": " is tried
+// and the prefix is kept. A message that can't be translated is returned
+// as is.
+func Translate(lang Language, msg string) string {
+	msgs, exists := catalogs[lang]
+	if !exists {
+		return msg
+	}
+
+	for i := 0; ; {
+		if s, ok := translate(msgs, msg[i:]); ok {
+			return msg[:i] + s
+		}
+
+		n := strings.Index(msg[i:], ": ")
+		if n == -1 {
+			return msg
+		}
+		i += n + 2
+	}
+}
+
+func translate(msgs []message, msg string) (string, bool) {
+	for _, m := range msgs {
+		values := m.match.FindStringSubmatch(msg)
+		if values == nil {
+			continue
+		}
+
+		s := m.translation
+		for i, name := range m.names {
+			s = strings.ReplaceAll(s, "{"+name+"}", values[i+1])
+		}
+
+		return s, true
+	}
+
+	return "", false
+}
+
+// Translator translates the messages of errors for the errs package. A
+// message is looked up by its application error code first, so the text of
+// the message can change without breaking the translation.
+type Translator struct{}
+
+// Translate implements the errs.Translator interface.
+func (Translator) Translate(locale string, code errs.AppCode, msg string) string {
+	lang := Language(locale)
+
+	if s, exists := codes[lang][string(code)]; exists && code != "" {
+		return s
+	}
+
+	return Translate(lang, msg)
+}
+
+// =============================================================================
+
+type ctxKey int
+
+const languageKey ctxKey = 1
+
+// Set stores the language of the request in the context.
+func Set(ctx context.Context, lang Language) context.Context {
+	return context.WithValue(ctx, languageKey, lang)
+}
+
+// Get returns the language of the request, the default language is
+// returned if one isn't in the context.
+func Get(ctx context.Context) Language {
+	lang, ok := ctx.Value(languageKey).(Language)
+	if !ok {
+		return Default
+	}
+
+	return lang
+}
@@ -0,0 +1,76 @@
+package i18n_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/i18n"
+)
+
+func Test_Parse(t *testing.T) {
+	tests := []struct {
+		header string
+		exp    i18n.Language
+	}{
+		{"", i18n.English},
+		{"es", i18n.Spanish},
+		{"es-MX,es;q=0.9,en;q=0.8", i18n.Spanish},
+		{"en-US,en;q=0.9,es;q=0.8", i18n.English},
+		{"fr-FR,es;q=0.5", i18n.Spanish},
+		{"de", i18n.English},
+		{"not a language;;", i18n.English},
+	}
+
+	for _, tt := range tests {
+		if got := i18n.Parse(tt.header); got != tt.exp {
+			t.Fatalf("%q: Should get %s: got %s", tt.header, tt.exp, got)
+		}
+	}
+
+	if lang := i18n.Get(context.Background()); lang != i18n.Default {
+		t.Fatalf("Should default to %s: got %s", i18n.Default, lang)
+	}
+
+	if lang := i18n.Get(i18n.Set(context.Background(), i18n.Spanish)); lang != i18n.Spanish {
+		t.Fatalf("Should get the language from the context: got %s", lang)
+	}
+}
+
+func Test_Translate(t *testing.T) {
+	tests := []struct {
+		lang i18n.Language
+		msg  string
+		exp  string
+	}{
+		{i18n.Spanish, "name is a required field", "name es un campo requerido"},
+		{i18n.Spanish, "quantity must be 1 or greater", "quantity debe ser 1 o mayor"},
+		{i18n.Spanish, "passwordConfirm must be equal to Password", "passwordConfirm debe ser igual a Password"},
+		{i18n.Spanish, "querybyid: productID[123]: product not found", "querybyid: productID[123]: producto no encontrado"},
+		{i18n.Spanish, "authorize: you are not authorized for that action, no claims", "authorize: no está autorizado para esa acción, no claims"},
+		{i18n.Spanish, "nothing to see here", "nothing to see here"},
+		{i18n.English, "name is a required field", "name is a required field"},
+	}
+
+	for _, tt := range tests {
+		if got := i18n.Translate(tt.lang, tt.msg); got != tt.exp {
+			t.Fatalf("%q: Should get %q: got %q", tt.msg, tt.exp, got)
+		}
+	}
+}
+
+func Test_Translator(t *testing.T) {
+	var tr i18n.Translator
+
+	if got := tr.Translate("es", errs.AppUserEmailTaken, "create: email is not unique"); got != "el correo electrónico ya está en uso" {
+		t.Fatalf("Should look the message up by code: got %q", got)
+	}
+
+	if got := tr.Translate("es", errs.AppProductNotFound, "querybyid: product not found"); got != "querybyid: producto no encontrado" {
+		t.Fatalf("Should fall back to the text: got %q", got)
+	}
+
+	if got := tr.Translate("en", errs.AppUserEmailTaken, "email is not unique"); got != "email is not unique" {
+		t.Fatalf("Should leave the default language as is: got %q", got)
+	}
+}
@@ -0,0 +1,95 @@
+// Package include provides support for embedding related resources in a
+// response, so a client doesn't have to make a call per item to get them.
+package include
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/google/uuid"
+)
+
+// User is the name used to include the user that owns a resource.
+const User = "user"
+
+// Set represents the related resources to include.
+type Set map[string]struct{}
+
+// Parse parses a comma separated list of related resources against the
+// names the endpoint supports. An empty value includes nothing.
+func Parse(value string, allowed ...string) (Set, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	set := make(Set)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+
+		var found bool
+		for _, a := range allowed {
+			if name == a {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("unknown resource %q, supported: %s", name, strings.Join(allowed, ","))
+		}
+
+		set[name] = struct{}{}
+	}
+
+	return set, nil
+}
+
+// Has reports if the related resource should be included.
+func (s Set) Has(name string) bool {
+	_, exists := s[name]
+	return exists
+}
+
+// =============================================================================
+
+// UserSummary represents the user that owns a resource.
+type UserSummary struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Users returns the summaries for the users with the specified ids, using a
+// single query no matter how many items share a user.
+func Users(ctx context.Context, userBus *userbus.Business, userIDs []string) (map[string]UserSummary, error) {
+	seen := make(map[uuid.UUID]struct{}, len(userIDs))
+	ids := make([]uuid.UUID, 0, len(userIDs))
+
+	for _, s := range userIDs {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse: %w", err)
+		}
+
+		if _, exists := seen[id]; !exists {
+			seen[id] = struct{}{}
+			ids = append(ids, id)
+		}
+	}
+
+	usrs, err := userBus.QueryByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make(map[string]UserSummary, len(usrs))
+	for _, usr := range usrs {
+		summaries[usr.ID.String()] = UserSummary{
+			ID:   usr.ID.String(),
+			Name: usr.Name.String(),
+		}
+	}
+
+	return summaries, nil
+}
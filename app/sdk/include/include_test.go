@@ -0,0 +1,27 @@
+package include_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/encore/app/sdk/include"
+)
+
+func Test_Parse(t *testing.T) {
+	set, err := include.Parse("", include.User)
+	if err != nil || set.Has(include.User) {
+		t.Fatalf("Should include nothing for an empty value: %v %v", set, err)
+	}
+
+	set, err = include.Parse(" user ", include.User)
+	if err != nil {
+		t.Fatalf("Should be able to parse a supported resource: %s", err)
+	}
+
+	if !set.Has(include.User) {
+		t.Fatalf("Should include the user")
+	}
+
+	if _, err := include.Parse("user,orders", include.User); err == nil {
+		t.Fatalf("Should not be able to include an unsupported resource")
+	}
+}
@@ -0,0 +1,132 @@
+// Package limiter provides support for bounding the number of expensive
+// requests an instance executes at the same time, and the number of requests
+// a client makes over a window of time.
+package limiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrQueueFull is returned when the limiter can't accept any more requests.
+var ErrQueueFull = errors.New("too many requests in progress, try again later")
+
+// Status represents the state of the limiter at a point in time.
+type Status struct {
+	Limit     int
+	Remaining int
+	Reset     time.Duration
+}
+
+// Limiter bounds the number of requests that run at the same time. Requests
+// over the limit wait in a queue until a slot is free. When the queue is
+// full, requests are rejected right away.
+type Limiter struct {
+	running    chan struct{}
+	admitted   chan struct{}
+	retryAfter time.Duration
+}
+
+// New constructs a limiter that runs up to maxRunning requests at the same
+// time with up to maxQueued requests waiting. The retryAfter value is how
+// long rejected clients are told to wait before trying again.
+func New(maxRunning int, maxQueued int, retryAfter time.Duration) *Limiter {
+	return &Limiter{
+		running:    make(chan struct{}, maxRunning),
+		admitted:   make(chan struct{}, maxRunning+maxQueued),
+		retryAfter: retryAfter,
+	}
+}
+
+// Acquire waits for a slot to run a request. The returned function must be
+// called to release the slot once the request is complete. ErrQueueFull is
+// returned if the queue is full, and the context error is returned if the
+// context is done while waiting.
+func (l *Limiter) Acquire(ctx context.Context) (func(), error) {
+	select {
+	case l.admitted <- struct{}{}:
+	default:
+		return nil, ErrQueueFull
+	}
+
+	select {
+	case l.running <- struct{}{}:
+	case <-ctx.Done():
+		<-l.admitted
+		return nil, ctx.Err()
+	}
+
+	release := func() {
+		<-l.running
+		<-l.admitted
+	}
+
+	return release, nil
+}
+
+// RetryAfter returns how long rejected clients should wait before trying
+// again.
+func (l *Limiter) RetryAfter() time.Duration {
+	return l.retryAfter
+}
+
+// Status returns the state of the limiter for reporting to clients. The
+// limit is the number of requests that can be running or queued at the same
+// time and the remaining value is how many more would be accepted right now.
+// Since the limiter bounds requests in progress instead of requests over a
+// window, the reset is the time clients are told to wait when rejected.
+func (l *Limiter) Status() Status {
+	limit := cap(l.admitted)
+
+	return Status{
+		Limit:     limit,
+		Remaining: limit - len(l.admitted),
+		Reset:     l.retryAfter,
+	}
+}
+
+// Running returns the number of requests currently running.
+func (l *Limiter) Running() int {
+	return len(l.running)
+}
+
+// Queued returns the number of requests waiting to run.
+func (l *Limiter) Queued() int {
+	return len(l.admitted) - len(l.running)
+}
+
+// =============================================================================
+
+// Groups holds a limiter for each group of endpoints, so a burst on one
+// group can't take the slots of another.
+type Groups struct {
+	limiters  map[string]*Limiter
+	endpoints map[string]string
+}
+
+// NewGroups constructs the set of limiters by group name with the group each
+// endpoint belongs to. Every group an endpoint belongs to must have a
+// limiter.
+func NewGroups(limiters map[string]*Limiter, endpoints map[string]string) (*Groups, error) {
+	for endpoint, group := range endpoints {
+		if _, exists := limiters[group]; !exists {
+			return nil, fmt.Errorf("endpoint[%s]: group[%s] has no limiter", endpoint, group)
+		}
+	}
+
+	g := Groups{
+		limiters:  limiters,
+		endpoints: endpoints,
+	}
+
+	return &g, nil
+}
+
+// For returns the limiter for the group the endpoint belongs to. False is
+// returned when the endpoint isn't limited.
+func (g *Groups) For(endpoint string) (*Limiter, bool) {
+	l, exists := g.limiters[g.endpoints[endpoint]]
+	return l, exists
+}
@@ -0,0 +1,171 @@
+package limiter_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/encore/app/sdk/limiter"
+	"github.com/ardanlabs/encore/foundation/clock"
+)
+
+func Test_Limiter(t *testing.T) {
+	l := limiter.New(1, 1, time.Second)
+
+	release1, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Should be able to acquire the first slot: %s", err)
+	}
+
+	// The second request waits in the queue until the first is released.
+	acquired := make(chan func())
+	go func() {
+		release, err := l.Acquire(context.Background())
+		if err != nil {
+			t.Errorf("Should be able to acquire a slot after waiting: %s", err)
+		}
+		acquired <- release
+	}()
+
+	for l.Queued() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := l.Acquire(context.Background()); !errors.Is(err, limiter.ErrQueueFull) {
+		t.Fatalf("Should get ErrQueueFull when the queue is full: %v", err)
+	}
+
+	release1()
+
+	release2 := <-acquired
+	if l.Running() != 1 || l.Queued() != 0 {
+		t.Fatalf("Should have one running and none queued: running[%d] queued[%d]", l.Running(), l.Queued())
+	}
+
+	release2()
+
+	if l.Running() != 0 || l.Queued() != 0 {
+		t.Fatalf("Should have nothing running or queued: running[%d] queued[%d]", l.Running(), l.Queued())
+	}
+}
+
+func Test_LimiterContext(t *testing.T) {
+	l := limiter.New(1, 1, time.Second)
+
+	release, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Should be able to acquire the first slot: %s", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := l.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Should get the context error while waiting: %v", err)
+	}
+
+	if l.Queued() != 0 {
+		t.Fatalf("Should release the queue slot when the context is done: queued[%d]", l.Queued())
+	}
+}
+
+func Test_LimiterStatus(t *testing.T) {
+	l := limiter.New(1, 2, 5*time.Second)
+
+	exp := limiter.Status{Limit: 3, Remaining: 3, Reset: 5 * time.Second}
+	if st := l.Status(); st != exp {
+		t.Fatalf("Should have all slots remaining: exp[%+v] got[%+v]", exp, st)
+	}
+
+	release, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Should be able to acquire the first slot: %s", err)
+	}
+
+	if st := l.Status(); st.Remaining != 2 {
+		t.Fatalf("Should have two slots remaining: got[%d]", st.Remaining)
+	}
+
+	release()
+
+	if st := l.Status(); st.Remaining != 3 {
+		t.Fatalf("Should have all slots remaining after release: got[%d]", st.Remaining)
+	}
+}
+
+func Test_Groups(t *testing.T) {
+	limiters := map[string]*limiter.Limiter{
+		"search": limiter.New(1, 0, time.Second),
+		"export": limiter.New(1, 0, time.Second),
+	}
+
+	g, err := limiter.NewGroups(limiters, map[string]string{"Search": "search", "Export": "export"})
+	if err != nil {
+		t.Fatalf("Should be able to construct the groups: %s", err)
+	}
+
+	search, _ := g.For("Search")
+	release, err := search.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Should be able to acquire a search slot: %s", err)
+	}
+	defer release()
+
+	if _, err := search.Acquire(context.Background()); !errors.Is(err, limiter.ErrQueueFull) {
+		t.Fatalf("Should reject a second search: got %v", err)
+	}
+
+	export, _ := g.For("Export")
+	exportRelease, err := export.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Should be able to export while the search group is full: %s", err)
+	}
+	exportRelease()
+
+	if _, exists := g.For("ProductQuery"); exists {
+		t.Fatalf("Should not limit an endpoint outside the groups")
+	}
+
+	if _, err := limiter.NewGroups(limiters, map[string]string{"Stream": "stream"}); err == nil {
+		t.Fatalf("Should not allow a group without a limiter")
+	}
+}
+
+func Test_Window(t *testing.T) {
+	clk := clock.NewFrozen(time.Now())
+	l := limiter.NewWindow(clk, 2, time.Minute)
+
+	for i := range 2 {
+		st, err := l.Allow("bill@example.com")
+		if err != nil {
+			t.Fatalf("Should accept the request %d: %s", i, err)
+		}
+
+		if st.Remaining != 1-i {
+			t.Fatalf("Should get the remaining requests: got %d, exp %d", st.Remaining, 1-i)
+		}
+	}
+
+	clk.Advance(10 * time.Second)
+
+	st, err := l.Allow("bill@example.com")
+	if !errors.Is(err, limiter.ErrRateLimited) {
+		t.Fatalf("Should get ErrRateLimited when the window is used up: %v", err)
+	}
+
+	if st.Remaining != 0 || st.Reset != 50*time.Second {
+		t.Fatalf("Should get the time until the window resets: %+v", st)
+	}
+
+	if _, err := l.Allow("jill@example.com"); err != nil {
+		t.Fatalf("Should accept a request for another key: %s", err)
+	}
+
+	clk.Advance(time.Minute)
+
+	if _, err := l.Allow("bill@example.com"); err != nil {
+		t.Fatalf("Should accept a request in the next window: %s", err)
+	}
+}
@@ -0,0 +1,88 @@
+package limiter
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ardanlabs/encore/foundation/clock"
+)
+
+// ErrRateLimited is returned when a key has used up its requests for the
+// window.
+var ErrRateLimited = errors.New("too many requests, try again later")
+
+// Window bounds the number of requests for each key, like a client or an
+// email address, over a fixed window of time. The counts are per instance.
+type Window struct {
+	clock   clock.Clock
+	limit   int
+	window  time.Duration
+	mu      sync.Mutex
+	windows map[string]window
+	pruneAt time.Time
+}
+
+type window struct {
+	count int
+	reset time.Time
+}
+
+// NewWindow constructs a limiter that accepts up to limit requests for a key
+// in each window.
+func NewWindow(clk clock.Clock, limit int, w time.Duration) *Window {
+	return &Window{
+		clock:   clk,
+		limit:   limit,
+		window:  w,
+		windows: make(map[string]window),
+	}
+}
+
+// Allow counts a request for the key and returns the state of its window.
+// ErrRateLimited is returned when the key has used up its requests, and the
+// reset in the status is how long until it can make more.
+func (l *Window) Allow(key string) (Status, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+
+	l.prune(now)
+
+	w, exists := l.windows[key]
+	if !exists || !now.Before(w.reset) {
+		w = window{reset: now.Add(l.window)}
+	}
+
+	st := Status{
+		Limit: l.limit,
+		Reset: w.reset.Sub(now),
+	}
+
+	if w.count >= l.limit {
+		return st, ErrRateLimited
+	}
+
+	w.count++
+	l.windows[key] = w
+
+	st.Remaining = l.limit - w.count
+
+	return st, nil
+}
+
+// prune removes the windows that are over, so keys that stop making requests
+// don't use memory forever. This is done once a window at most.
+func (l *Window) prune(now time.Time) {
+	if now.Before(l.pruneAt) {
+		return
+	}
+	l.pruneAt = now.Add(l.window)
+
+	for key, w := range l.windows {
+		if !now.Before(w.reset) {
+			delete(l.windows, key)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// Package links provides support for hypermedia links in responses so
+// clients can follow the relationships between resources and pages instead
+// of building URLs themselves.
+package links
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Set of relation names used by the links in a response.
+const (
+	Self    = "self"
+	Next    = "next"
+	Prev    = "prev"
+	Owner   = "owner"
+	Related = "related"
+)
+
+// Link represents a link to a related resource.
+type Link struct {
+	Href string `json:"href"`
+}
+
+// Links represents the set of links for a resource keyed by the relation.
+type Links map[string]Link
+
+// Builder constructs links using the base URL of the service. A nil builder
+// constructs links relative to the service.
+type Builder struct {
+	base string
+}
+
+// New constructs a builder for links relative to the base URL.
+func New(baseURL string) *Builder {
+	return &Builder{
+		base: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// URL returns the absolute URL for the path and query string values.
+func (b *Builder) URL(path string, values url.Values) string {
+	var u string
+	if b != nil {
+		u = b.base
+	}
+
+	u += path
+	if len(values) > 0 {
+		u += "?" + values.Encode()
+	}
+
+	return u
+}
+
+// Link returns a link for the path and query string values.
+func (b *Builder) Link(path string, values url.Values) Link {
+	return Link{
+		Href: b.URL(path, values),
+	}
+}
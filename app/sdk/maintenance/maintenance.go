@@ -0,0 +1,65 @@
+// Package maintenance provides support for taking the service out of use
+// while work is done on it.
+package maintenance
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// Tag is the endpoint tag for endpoints that keep working in maintenance
+// mode, so the service can still be monitored and brought back.
+const Tag = "operations"
+
+// DefaultRetryAfter is how long clients are told to wait when maintenance
+// mode is turned on without an estimate of how long it will last.
+const DefaultRetryAfter = 5 * time.Minute
+
+// ErrEnabled is returned for requests made while the service is in
+// maintenance mode.
+var ErrEnabled = errors.New("service is in maintenance mode")
+
+// Mode maintains if the service is in maintenance mode. The zero value is
+// ready to use with maintenance mode off.
+type Mode struct {
+	enabled    atomic.Bool
+	retryAfter atomic.Int64
+}
+
+// Set turns maintenance mode on or off.
+func (m *Mode) Set(enabled bool) {
+	m.enabled.Store(enabled)
+}
+
+// SetRetryAfter sets how long clients are told to wait before trying again
+// while maintenance mode is on. Zero or less uses DefaultRetryAfter.
+func (m *Mode) SetRetryAfter(d time.Duration) {
+	m.retryAfter.Store(int64(d))
+}
+
+// RetryAfter returns how long clients are told to wait before trying again.
+func (m *Mode) RetryAfter() time.Duration {
+	if d := time.Duration(m.retryAfter.Load()); d > 0 {
+		return d
+	}
+
+	return DefaultRetryAfter
+}
+
+// Enabled reports if the service is in maintenance mode.
+func (m *Mode) Enabled() bool {
+	return m.enabled.Load()
+}
+
+// Exempt reports if an endpoint with the specified tags keeps working in
+// maintenance mode.
+func Exempt(tags []string) bool {
+	for _, tag := range tags {
+		if tag == Tag {
+			return true
+		}
+	}
+
+	return false
+}
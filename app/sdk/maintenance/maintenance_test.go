@@ -0,0 +1,54 @@
+package maintenance_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/encore/app/sdk/maintenance"
+)
+
+func Test_Mode(t *testing.T) {
+	var mode maintenance.Mode
+
+	if mode.Enabled() {
+		t.Fatalf("Should start with maintenance mode off")
+	}
+
+	mode.Set(true)
+	if !mode.Enabled() {
+		t.Fatalf("Should be able to turn maintenance mode on")
+	}
+
+	mode.Set(false)
+	if mode.Enabled() {
+		t.Fatalf("Should be able to turn maintenance mode off")
+	}
+}
+
+func Test_RetryAfter(t *testing.T) {
+	var mode maintenance.Mode
+
+	if d := mode.RetryAfter(); d != maintenance.DefaultRetryAfter {
+		t.Fatalf("Should start with the default retry: got %s", d)
+	}
+
+	mode.SetRetryAfter(time.Minute)
+	if d := mode.RetryAfter(); d != time.Minute {
+		t.Fatalf("Should be able to set the retry: got %s", d)
+	}
+
+	mode.SetRetryAfter(0)
+	if d := mode.RetryAfter(); d != maintenance.DefaultRetryAfter {
+		t.Fatalf("Should go back to the default retry: got %s", d)
+	}
+}
+
+func Test_Exempt(t *testing.T) {
+	if !maintenance.Exempt([]string{"metrics", maintenance.Tag}) {
+		t.Fatalf("Should exempt an endpoint tagged for operations")
+	}
+
+	if maintenance.Exempt([]string{"metrics", "cache"}) {
+		t.Fatalf("Should not exempt an endpoint that isn't tagged for operations")
+	}
+}
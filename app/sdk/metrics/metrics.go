@@ -4,6 +4,7 @@ package metrics
 import (
 	"expvar"
 	"runtime"
+	"time"
 
 	"encore.dev"
 	"encore.dev/metrics"
@@ -13,6 +14,24 @@ var devGoroutines = expvar.NewInt("goroutines")
 var devRequests = expvar.NewInt("requests")
 var devFailures = expvar.NewInt("errors")
 var devPanics = expvar.NewInt("panics")
+var devDeprecated = expvar.NewMap("deprecated_requests")
+var devEndpoints = expvar.NewMap("endpoint_requests")
+var devDenied = expvar.NewMap("denied_requests")
+var devCacheHits = expvar.NewMap("cache_hits")
+var devCacheMisses = expvar.NewMap("cache_misses")
+var devCacheEvictions = expvar.NewMap("cache_evictions")
+
+// EndpointLabels are the labels for metrics tracked per endpoint.
+type EndpointLabels struct {
+	Endpoint string
+}
+
+// EndpointStatusLabels are the labels for metrics tracked per endpoint and
+// the status of the response.
+type EndpointStatusLabels struct {
+	Endpoint string
+	Status   string
+}
 
 // Config lists the set of metrics that is tracked.
 type Config struct {
@@ -20,6 +39,10 @@ type Config struct {
 	Requests   *metrics.Counter[uint64]
 	Failures   *metrics.Counter[uint64]
 	Panics     *metrics.Counter[uint64]
+	Deprecated *metrics.CounterGroup[EndpointLabels, uint64]
+	Endpoints  *metrics.CounterGroup[EndpointStatusLabels, uint64]
+	Duration   *metrics.CounterGroup[EndpointStatusLabels, uint64]
+	Denied     *metrics.CounterGroup[EndpointLabels, uint64]
 }
 
 // Values provides an api to work with metrics.
@@ -29,10 +52,17 @@ type Values struct {
 	requests      *metrics.Counter[uint64]
 	failures      *metrics.Counter[uint64]
 	panics        *metrics.Counter[uint64]
+	deprecated    *metrics.CounterGroup[EndpointLabels, uint64]
+	endpoints     *metrics.CounterGroup[EndpointStatusLabels, uint64]
+	duration      *metrics.CounterGroup[EndpointStatusLabels, uint64]
+	denied        *metrics.CounterGroup[EndpointLabels, uint64]
 	devGoroutines *expvar.Int
 	devRequests   *expvar.Int
 	devFailures   *expvar.Int
 	devPanics     *expvar.Int
+	devDeprecated *expvar.Map
+	devEndpoints  *expvar.Map
+	devDenied     *expvar.Map
 }
 
 // New constructs a Values for working with metrics.
@@ -43,10 +73,17 @@ func New(cfg Config) *Values {
 		requests:      cfg.Requests,
 		failures:      cfg.Failures,
 		panics:        cfg.Panics,
+		deprecated:    cfg.Deprecated,
+		endpoints:     cfg.Endpoints,
+		duration:      cfg.Duration,
+		denied:        cfg.Denied,
 		devGoroutines: devGoroutines,
 		devRequests:   devRequests,
 		devFailures:   devFailures,
 		devPanics:     devPanics,
+		devDeprecated: devDeprecated,
+		devEndpoints:  devEndpoints,
+		devDenied:     devDenied,
 	}
 }
 
@@ -85,3 +122,102 @@ func (v *Values) IncPanics() {
 		v.devPanics.Add(1)
 	}
 }
+
+// IncDeprecated increments the requests made to a deprecated endpoint by 1.
+func (v *Values) IncDeprecated(endpoint string) {
+	v.deprecated.With(EndpointLabels{Endpoint: endpoint}).Add(1)
+
+	if v.devEnv {
+		v.devDeprecated.Add(endpoint, 1)
+	}
+}
+
+// AddEndpoint records a request to the endpoint with the status of its
+// response and how long it took. The duration is kept as a running total in
+// milliseconds so the average can be computed with the request count.
+func (v *Values) AddEndpoint(endpoint string, status string, d time.Duration) {
+	labels := EndpointStatusLabels{Endpoint: endpoint, Status: status}
+
+	v.endpoints.With(labels).Add(1)
+	v.duration.With(labels).Add(uint64(d.Milliseconds()))
+
+	if v.devEnv {
+		v.devEndpoints.Add(endpoint+" "+status, 1)
+	}
+}
+
+// IncDenied increments the requests to the endpoint refused because of the
+// address they came from by 1.
+func (v *Values) IncDenied(endpoint string) {
+	v.denied.With(EndpointLabels{Endpoint: endpoint}).Add(1)
+
+	if v.devEnv {
+		v.devDenied.Add(endpoint, 1)
+	}
+}
+
+// =============================================================================
+
+// CacheLabels are the labels for metrics tracked per cache.
+type CacheLabels struct {
+	Cache string
+}
+
+// CacheConfig lists the set of metrics that is tracked for caches.
+type CacheConfig struct {
+	Hits      *metrics.CounterGroup[CacheLabels, uint64]
+	Misses    *metrics.CounterGroup[CacheLabels, uint64]
+	Evictions *metrics.CounterGroup[CacheLabels, uint64]
+}
+
+// Caches provides an api to work with the metrics of caches. It implements
+// the counters the business layer caches report to.
+type Caches struct {
+	devEnv       bool
+	hits         *metrics.CounterGroup[CacheLabels, uint64]
+	misses       *metrics.CounterGroup[CacheLabels, uint64]
+	evictions    *metrics.CounterGroup[CacheLabels, uint64]
+	devHits      *expvar.Map
+	devMisses    *expvar.Map
+	devEvictions *expvar.Map
+}
+
+// NewCaches constructs a Caches for working with the metrics of caches.
+func NewCaches(cfg CacheConfig) *Caches {
+	return &Caches{
+		devEnv:       encore.Meta().Environment.Type == encore.EnvDevelopment,
+		hits:         cfg.Hits,
+		misses:       cfg.Misses,
+		evictions:    cfg.Evictions,
+		devHits:      devCacheHits,
+		devMisses:    devCacheMisses,
+		devEvictions: devCacheEvictions,
+	}
+}
+
+// IncCacheHit increments the keys found in the cache by 1.
+func (c *Caches) IncCacheHit(cache string) {
+	c.hits.With(CacheLabels{Cache: cache}).Add(1)
+
+	if c.devEnv {
+		c.devHits.Add(cache, 1)
+	}
+}
+
+// IncCacheMiss increments the keys not found in the cache by 1.
+func (c *Caches) IncCacheMiss(cache string) {
+	c.misses.With(CacheLabels{Cache: cache}).Add(1)
+
+	if c.devEnv {
+		c.devMisses.Add(cache, 1)
+	}
+}
+
+// AddCacheEvictions adds the entries the cache evicted.
+func (c *Caches) AddCacheEvictions(cache string, n int) {
+	c.evictions.With(CacheLabels{Cache: cache}).Add(uint64(n))
+
+	if c.devEnv {
+		c.devEvictions.Add(cache, int64(n))
+	}
+}
@@ -0,0 +1,28 @@
+package mid
+
+import (
+	"encore.dev/middleware"
+	"github.com/ardanlabs/encore/app/sdk/allowlist"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/metrics"
+	"github.com/ardanlabs/encore/foundation/logger"
+)
+
+// AllowList rejects a request from a client that isn't connecting from one
+// of the networks in the list. Encore doesn't provide the peer address to
+// middleware, so the client is taken from the hops the gateway and the
+// trusted proxies appended to the X-Forwarded-For header. A request without
+// one is rejected. Denied attempts are logged and counted.
+func AllowList(log *logger.Logger, v *metrics.Values, list *allowlist.List, proxies *allowlist.List, req middleware.Request, next middleware.Next) middleware.Response {
+	data := req.Data()
+
+	ip := allowlist.ForwardedIP(data.Headers, proxies)
+	if !list.Allowed(ip) {
+		log.Warn(req.Context(), "allowlist", "status", "denied", "endpoint", data.Endpoint, "path", data.Path, "ip", ip)
+		v.IncDenied(data.Endpoint)
+
+		return errs.NewResponse(errs.PermissionDenied, allowlist.ErrDenied)
+	}
+
+	return next(req)
+}
@@ -0,0 +1,105 @@
+package mid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"encore.dev"
+	"encore.dev/middleware"
+	"github.com/ardanlabs/encore/app/sdk/reqlog"
+	bpubsub "github.com/ardanlabs/encore/business/sdk/pubsub"
+	"github.com/ardanlabs/encore/foundation/logger"
+)
+
+// AuditAction is the action recorded in the audit log for a change made
+// through the API.
+const AuditAction = "mutation"
+
+// Audit records who made a successful change, to what and with which
+// request in the audit log. The entry is published to the audits topic so
+// the request doesn't wait on the write. Encore doesn't provide the method
+// to the middleware, so this must only be applied to endpoints that change
+// data. Secrets in the request are redacted before they are recorded.
+func Audit(log *logger.Logger, req middleware.Request, next middleware.Next) middleware.Response {
+	resp := next(req)
+
+	if resp.Err != nil {
+		return resp
+	}
+
+	data := req.Data()
+	ctx := req.Context()
+
+	ad, err := NewAudit(ctx, data.Endpoint, data.PathParams, data.Payload, resp.Payload)
+	if err != nil {
+		log.Error(ctx, "audit", "msg", "build entry", "endpoint", data.Endpoint, "ERROR", err)
+		return resp
+	}
+
+	PublishAudit(ctx, log, ad)
+
+	return resp
+}
+
+// NewAudit builds the audit entry for a change the authenticated user made
+// through the endpoint, with the request redacted. The entity is taken from
+// the path parameters or the response like the middleware does.
+func NewAudit(ctx context.Context, endpoint string, params encore.PathParams, request any, response any) (bpubsub.AuditData, error) {
+	actorID, err := GetUserID(ctx)
+	if err != nil {
+		return bpubsub.AuditData{}, fmt.Errorf("actor missing: %w", err)
+	}
+
+	payload := []byte("{}")
+	if request != nil {
+		if payload, err = reqlog.Redact(request); err != nil {
+			return bpubsub.AuditData{}, fmt.Errorf("marshal request: %w", err)
+		}
+	}
+
+	ad := bpubsub.AuditData{
+		ActorID:  actorID,
+		Action:   AuditAction,
+		Endpoint: endpoint,
+		EntityID: entityID(params, response),
+		Payload:  payload,
+	}
+
+	return ad, nil
+}
+
+// PublishAudit publishes the audit entry to the audits topic. A failure is
+// logged since the change it records has already been made.
+func PublishAudit(ctx context.Context, log *logger.Logger, ad bpubsub.AuditData) {
+	if _, err := bpubsub.Audits.Publish(ctx, ad); err != nil {
+		log.Error(ctx, "audit", "msg", "publish", "endpoint", ad.Endpoint, "ERROR", err)
+	}
+}
+
+// entityID returns the ID of the entity that was changed, from the route
+// when it names one, else from the ID of the entity in the response, as for
+// an entity that was just created.
+func entityID(params encore.PathParams, payload any) string {
+	if len(params) > 0 {
+		return params[len(params)-1].Value
+	}
+
+	if payload == nil {
+		return ""
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return ""
+	}
+
+	var v struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return ""
+	}
+
+	return v.ID
+}
@@ -6,13 +6,12 @@ import (
 	"fmt"
 	"net/mail"
 	"strings"
-	"time"
 
 	eauth "encore.dev/beta/auth"
 	"github.com/ardanlabs/encore/app/sdk/auth"
 	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/apikeybus"
 	"github.com/ardanlabs/encore/business/domain/userbus"
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 )
 
@@ -32,6 +31,10 @@ func Bearer(ctx context.Context, ath *auth.Auth, authorization string) (eauth.UI
 		return "", nil, errs.New(errs.Unauthenticated, fmt.Errorf("parsing subject: %w", err))
 	}
 
+	if err := ath.LoadPermissions(ctx, &claims); err != nil {
+		return "", nil, errs.New(errs.Internal, err)
+	}
+
 	return eauth.UID(subjectID.String()), &claims, nil
 }
 
@@ -52,24 +55,51 @@ func Basic(ctx context.Context, ath *auth.Auth, userBus *userbus.Business, autho
 		return "", nil, errs.New(errs.Unauthenticated, err)
 	}
 
-	claims := auth.Claims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   usr.ID.String(),
-			Issuer:    ath.Issuer(),
-			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(8760 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
-		},
-		Roles: userbus.ParseRolesToString(usr.Roles),
-	}
+	claims := ath.NewClaims(usr)
 
 	subjectID, err := uuid.Parse(claims.Subject)
 	if err != nil {
 		return "", nil, errs.Newf(errs.Unauthenticated, "parsing subject: %s", err)
 	}
 
+	if err := ath.LoadPermissions(ctx, &claims); err != nil {
+		return "", nil, errs.New(errs.Internal, err)
+	}
+
 	return eauth.UID(subjectID.String()), &claims, nil
 }
 
+// APIKey processes API key authentication logic. The claims only grant the
+// roles the key is scoped to.
+func APIKey(ctx context.Context, ath *auth.Auth, apiKeyBus *apikeybus.Business, authorization string) (eauth.UID, *auth.Claims, error) {
+	key, ok := parseAPIKey(authorization)
+	if !ok {
+		return "", nil, errs.Newf(errs.Unauthenticated, "expected authorization header format: ApiKey <key>")
+	}
+
+	apiKey, err := apiKeyBus.Authenticate(ctx, key)
+	if err != nil {
+		return "", nil, errs.New(errs.Unauthenticated, err)
+	}
+
+	claims := ath.NewScopedClaims(apiKey.UserID, apiKey.Roles)
+
+	if err := ath.LoadPermissions(ctx, &claims); err != nil {
+		return "", nil, errs.New(errs.Internal, err)
+	}
+
+	return eauth.UID(apiKey.UserID.String()), &claims, nil
+}
+
+func parseAPIKey(auth string) (string, bool) {
+	parts := strings.Split(auth, " ")
+	if len(parts) != 2 || parts[0] != "ApiKey" || parts[1] == "" {
+		return "", false
+	}
+
+	return parts[1], true
+}
+
 func parseBasicAuth(auth string) (string, string, bool) {
 	parts := strings.Split(auth, " ")
 	if len(parts) != 2 || parts[0] != "Basic" {
@@ -1,6 +1,7 @@
 package mid
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -8,32 +9,30 @@ import (
 	"encore.dev/middleware"
 	"github.com/ardanlabs/encore/app/sdk/auth"
 	"github.com/ardanlabs/encore/business/domain/homebus"
+	"github.com/ardanlabs/encore/business/domain/jobbus"
 	"github.com/ardanlabs/encore/business/domain/productbus"
 	"github.com/ardanlabs/encore/business/domain/userbus"
 	"github.com/google/uuid"
 )
 
-// ErrInvalidID represents a condition where the id is not a uuid.
-var ErrInvalidID = errors.New("ID is not in its proper form")
-
-// Authorize checks the user making the request is an admin or user.
-func Authorize(req middleware.Request) (AuthInfo, middleware.Request, error) {
-	claims := eauth.Data().(*auth.Claims)
+// Set of errors for loading the entity specified on the route.
+var (
+	ErrInvalidID = errors.New("ID is not in its proper form")
+	ErrNotFound  = errors.New("not found")
+)
 
-	rule := auth.RuleAdminOnly
-	for _, tag := range req.Data().API.Tags {
-		switch tag {
-		case "as_any_role":
-			rule = auth.RuleAny
-		case "as_user_role":
-			rule = auth.RuleUserOnly
-		}
+// Authorize checks the user making the request against the rule for the
+// endpoint. Endpoints that aren't in the rule table are for admins only.
+func Authorize(rules Rules, req middleware.Request) (AuthInfo, middleware.Request, error) {
+	claims, ok := eauth.Data().(*auth.Claims)
+	if !ok {
+		return AuthInfo{}, req, errors.New("claims missing from request")
 	}
 
 	authInfo := AuthInfo{
 		Claims: *claims,
 		UserID: uuid.UUID{},
-		Rule:   rule,
+		Rule:   rules.For(req.Data().Endpoint, auth.RuleAdminOnly),
 	}
 
 	// We should call the Auth Service from here and keep things in the app
@@ -45,39 +44,18 @@ func Authorize(req middleware.Request) (AuthInfo, middleware.Request, error) {
 }
 
 // AuthorizeUser checks the user making the call has specified a user id on
-// the route that matches the claims.
-func AuthorizeUser(userBus *userbus.Business, req middleware.Request) (AuthInfo, middleware.Request, error) {
-	ctx := req.Context()
+// the route that matches the claims. Endpoints that aren't in the rule table
+// are for admins or the user themselves.
+func AuthorizeUser(rules Rules, userBus *userbus.Business, req middleware.Request) (AuthInfo, middleware.Request, error) {
 	var userID uuid.UUID
 
-	rule := auth.RuleAdminOrSubject
-	for _, tag := range req.Data().API.Tags {
-		if tag == "as_admin_role" {
-			rule = auth.RuleAdminOnly
-			break
-		}
+	usr, found, err := loadByPathID(req, userBus.QueryByID, userbus.ErrNotFound)
+	if err != nil {
+		return AuthInfo{}, req, err
 	}
 
-	if len(req.Data().PathParams) == 1 {
-		id := req.Data().PathParams[0]
-
-		var err error
-		userID, err = uuid.Parse(id.Value)
-		if err != nil {
-			return AuthInfo{}, req, ErrInvalidID
-		}
-
-		usr, err := userBus.QueryByID(ctx, userID)
-		if err != nil {
-			switch {
-			case errors.Is(err, userbus.ErrNotFound):
-				return AuthInfo{}, req, err
-
-			default:
-				return AuthInfo{}, req, fmt.Errorf("querybyid: userID[%s]: %s", userID, err)
-			}
-		}
-
+	if found {
+		userID = usr.ID
 		req = setUser(req, usr)
 	}
 
@@ -86,37 +64,24 @@ func AuthorizeUser(userBus *userbus.Business, req middleware.Request) (AuthInfo,
 	authInfo := AuthInfo{
 		Claims: *claims,
 		UserID: userID,
-		Rule:   rule,
+		Rule:   rules.For(req.Data().Endpoint, auth.RuleAdminOrSubject),
 	}
 
 	return authInfo, req, nil
 }
 
 // AuthorizeProduct checks the user making the call has specified a product id on
-// the route that matches the claims.
-func AuthorizeProduct(productBus *productbus.Business, req middleware.Request) (AuthInfo, middleware.Request, error) {
-	ctx := req.Context()
+// the route that matches the claims. Endpoints that aren't in the rule table
+// are for admins or the owner of the product.
+func AuthorizeProduct(rules Rules, productBus *productbus.Business, req middleware.Request) (AuthInfo, middleware.Request, error) {
 	var userID uuid.UUID
 
-	if len(req.Data().PathParams) == 1 {
-		id := req.Data().PathParams[0]
-
-		productID, err := uuid.Parse(id.Value)
-		if err != nil {
-			return AuthInfo{}, req, ErrInvalidID
-		}
-
-		prd, err := productBus.QueryByID(ctx, productID)
-		if err != nil {
-			switch {
-			case errors.Is(err, productbus.ErrNotFound):
-				return AuthInfo{}, req, err
-
-			default:
-				return AuthInfo{}, req, fmt.Errorf("querybyid: productID[%s]: %s", productID, err)
-			}
-		}
+	prd, found, err := loadByPathID(req, productBus.QueryByID, productbus.ErrNotFound)
+	if err != nil {
+		return AuthInfo{}, req, err
+	}
 
+	if found {
 		userID = prd.UserID
 		req = setProduct(req, prd)
 	}
@@ -126,39 +91,53 @@ func AuthorizeProduct(productBus *productbus.Business, req middleware.Request) (
 	authInfo := AuthInfo{
 		Claims: *claims,
 		UserID: userID,
-		Rule:   auth.RuleAdminOrSubject,
+		Rule:   rules.For(req.Data().Endpoint, auth.RuleAdminOrSubject),
 	}
 
 	return authInfo, req, nil
 }
 
 // AuthorizeHome checks the user making the call has specified a home id on
-// the route that matches the claims.
-func AuthorizeHome(homeBus *homebus.Business, req middleware.Request) (AuthInfo, middleware.Request, error) {
-	ctx := req.Context()
+// the route that matches the claims. Endpoints that aren't in the rule table
+// are for admins or the owner of the home.
+func AuthorizeHome(rules Rules, homeBus *homebus.Business, req middleware.Request) (AuthInfo, middleware.Request, error) {
 	var userID uuid.UUID
 
-	if len(req.Data().PathParams) == 1 {
-		id := req.Data().PathParams[0]
+	hme, found, err := loadByPathID(req, homeBus.QueryByID, homebus.ErrNotFound)
+	if err != nil {
+		return AuthInfo{}, req, err
+	}
 
-		homeID, err := uuid.Parse(id.Value)
-		if err != nil {
-			return AuthInfo{}, req, ErrInvalidID
-		}
+	if found {
+		userID = hme.UserID
+		req = setHome(req, hme)
+	}
 
-		hme, err := homeBus.QueryByID(ctx, homeID)
-		if err != nil {
-			switch {
-			case errors.Is(err, homebus.ErrNotFound):
-				return AuthInfo{}, req, err
+	claims := eauth.Data().(*auth.Claims)
 
-			default:
-				return AuthInfo{}, req, fmt.Errorf("querybyid: homeID[%s]: %s", homeID, err)
-			}
-		}
+	authInfo := AuthInfo{
+		Claims: *claims,
+		UserID: userID,
+		Rule:   rules.For(req.Data().Endpoint, auth.RuleAdminOrSubject),
+	}
 
-		userID = hme.UserID
-		req = setHome(req, hme)
+	return authInfo, req, nil
+}
+
+// AuthorizeJob checks the user making the call has specified a job id on
+// the route that matches the claims. Endpoints that aren't in the rule table
+// are for admins or the owner of the job.
+func AuthorizeJob(rules Rules, jobBus *jobbus.Business, req middleware.Request) (AuthInfo, middleware.Request, error) {
+	var userID uuid.UUID
+
+	job, found, err := loadByPathID(req, jobBus.QueryByID, jobbus.ErrNotFound)
+	if err != nil {
+		return AuthInfo{}, req, err
+	}
+
+	if found {
+		userID = job.UserID
+		req = setJob(req, job)
 	}
 
 	claims := eauth.Data().(*auth.Claims)
@@ -166,8 +145,36 @@ func AuthorizeHome(homeBus *homebus.Business, req middleware.Request) (AuthInfo,
 	authInfo := AuthInfo{
 		Claims: *claims,
 		UserID: userID,
-		Rule:   auth.RuleAdminOrSubject,
+		Rule:   rules.For(req.Data().Endpoint, auth.RuleAdminOrSubject),
 	}
 
 	return authInfo, req, nil
 }
+
+// loadByPathID loads the entity specified by the ID on the route, so the
+// handler and the authorization rules work with the same value and the
+// handler doesn't have to query for it again. False is returned when the
+// route doesn't have an ID.
+func loadByPathID[T any](req middleware.Request, queryByID func(ctx context.Context, id uuid.UUID) (T, error), notFound error) (T, bool, error) {
+	var zero T
+
+	params := req.Data().PathParams
+	if len(params) != 1 {
+		return zero, false, nil
+	}
+
+	id, err := uuid.Parse(params[0].Value)
+	if err != nil {
+		return zero, false, ErrInvalidID
+	}
+
+	v, err := queryByID(req.Context(), id)
+	if err != nil {
+		if errors.Is(err, notFound) {
+			return zero, false, fmt.Errorf("%s[%s]: %w", params[0].Name, id, ErrNotFound)
+		}
+		return zero, false, fmt.Errorf("querybyid: %s[%s]: %w", params[0].Name, id, err)
+	}
+
+	return v, true, nil
+}
@@ -0,0 +1,21 @@
+package mid
+
+import (
+	"encore.dev/middleware"
+	"github.com/ardanlabs/encore/app/sdk/bodylimit"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+)
+
+// BodyLimit rejects requests with a body larger than the limit for the
+// endpoint. Encore has decoded typed requests by the time middleware runs,
+// so the limit is checked against the Content-Length header which stops an
+// oversized request before the handler does any work with it.
+func BodyLimit(limits bodylimit.Limits, req middleware.Request, next middleware.Next) middleware.Response {
+	limit := limits.For(req.Data().API.Tags)
+
+	if err := bodylimit.Check(limit, req.Data().Headers.Get("Content-Length")); err != nil {
+		return errs.NewResponse(errs.FailedPrecondition, err)
+	}
+
+	return next(req)
+}
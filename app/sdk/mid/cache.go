@@ -0,0 +1,48 @@
+package mid
+
+import (
+	eauth "encore.dev/beta/auth"
+	"encore.dev/middleware"
+	"github.com/ardanlabs/encore/app/sdk/auth"
+	"github.com/ardanlabs/encore/app/sdk/cache"
+	"github.com/ardanlabs/encore/app/sdk/prefs"
+)
+
+// Cache returns a cached response for the request if one exists, else the
+// response from the handler is cached for future requests. This must only
+// be applied to idempotent GET endpoints since Encore doesn't provide the
+// method to the middleware.
+func Cache(c *cache.Cache, req middleware.Request, next middleware.Next) middleware.Response {
+	data := req.Data()
+
+	if data.API == nil || data.API.Raw {
+		return next(req)
+	}
+
+	var roles []string
+	if claims, ok := eauth.Data().(*auth.Claims); ok {
+		roles = claims.Roles
+	}
+
+	key := cache.Key(data.Path, data.Payload, roles)
+
+	// Responses built with a user's list preferences are only shared with
+	// users that have the same preferences.
+	if pk := prefs.Key(req.Context()); pk != "" {
+		key += "|" + pk
+	}
+
+	if payload, exists := c.Get(key); exists {
+		return middleware.Response{
+			Payload: payload,
+		}
+	}
+
+	resp := next(req)
+
+	if resp.Err == nil && resp.Payload != nil {
+		c.Set(key, resp.Payload)
+	}
+
+	return resp
+}
@@ -0,0 +1,36 @@
+package mid
+
+import (
+	"encore.dev/middleware"
+	"github.com/ardanlabs/encore/app/sdk/deprecation"
+	"github.com/ardanlabs/encore/app/sdk/metrics"
+)
+
+// deprecated is implemented by response types that can carry the
+// deprecation headers.
+type deprecated interface {
+	WithDeprecation(n deprecation.Notice) any
+}
+
+// Deprecation counts the requests made to the endpoints in the registry so
+// removing them can be based on usage, and sets the deprecation headers when
+// the response supports them.
+func Deprecation(v *metrics.Values, reg *deprecation.Registry, req middleware.Request, next middleware.Next) middleware.Response {
+	e, exists := reg.Lookup(req.Data().Endpoint)
+	if !exists {
+		return next(req)
+	}
+
+	v.IncDeprecated(e.Name)
+
+	resp := next(req)
+	if resp.Err != nil {
+		return resp
+	}
+
+	if p, ok := resp.Payload.(deprecated); ok {
+		resp.Payload = p.WithDeprecation(e.Notice())
+	}
+
+	return resp
+}
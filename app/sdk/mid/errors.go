@@ -0,0 +1,52 @@
+package mid
+
+import (
+	"errors"
+
+	eerrs "encore.dev/beta/errs"
+	"encore.dev/middleware"
+	"github.com/ardanlabs/encore/app/sdk/auth"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/foundation/logger"
+)
+
+// init registers the sentinel errors returned by the middleware so they
+// reach clients with the right code.
+func init() {
+	errs.Register(ErrInvalidID, errs.Class{Code: errs.InvalidArgument, AppCode: errs.AppInvalidID})
+	errs.Register(ErrNotFound, errs.Class{Code: errs.NotFound, AppCode: errs.AppNotFound})
+	errs.Register(auth.ErrForbidden, errs.Class{Code: errs.PermissionDenied, AppCode: errs.AppForbidden})
+}
+
+// Errors classifies an error returned by the handler that isn't an encore
+// error with the registered sentinel errors, so the errors from the business
+// layer reach the client with the right code. The full cause chain of the
+// error is logged since the client may only get the safe message.
+func Errors(log *logger.Logger, req middleware.Request, next middleware.Next) middleware.Response {
+	resp := next(req)
+
+	if resp.Err == nil {
+		return resp
+	}
+
+	var ee *eerrs.Error
+	if errors.As(resp.Err, &ee) {
+		return resp
+	}
+
+	ctx := req.Context()
+	endpoint := req.Data().Endpoint
+
+	e := errs.From(resp.Err)
+
+	switch e.Code {
+	case eerrs.Internal:
+		log.Error(ctx, "request failed", "endpoint", endpoint, "ERROR", resp.Err, "causes", errs.Causes(resp.Err))
+	default:
+		log.Info(ctx, "request failed", "endpoint", endpoint, "code", e.Code, "causes", errs.Causes(resp.Err))
+	}
+
+	resp.Err = e
+
+	return resp
+}
@@ -0,0 +1,209 @@
+package mid
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+
+	eauth "encore.dev/beta/auth"
+	"encore.dev/middleware"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/business/domain/idempotencybus"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// IdempotencyKeyHeader is the request header a client sets so a retried
+// request isn't applied twice.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// storeTimeout bounds storing the outcome of a request once the request is
+// done.
+const storeTimeout = 5 * time.Second
+
+// Set of error variables for requests made with an idempotency key.
+var (
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is in progress")
+)
+
+// Idempotency replays the stored response when a request is retried with
+// the same idempotency key by the same user. The key is reserved before the
+// request is applied so a concurrent request with the same key gets a
+// conflict instead of being applied twice. The first successful response
+// for a key is stored, errors aren't so the request can be retried. This
+// must only be applied to endpoints that require authentication.
+func Idempotency(log *logger.Logger, idempotencyBus *idempotencybus.Business, req middleware.Request, next middleware.Next) middleware.Response {
+	ctx := req.Context()
+	data := req.Data()
+
+	key := data.Headers.Get(IdempotencyKeyHeader)
+	if key == "" || data.API == nil || data.API.Raw {
+		return next(req)
+	}
+
+	uid, ok := eauth.UserID()
+	if !ok {
+		return next(req)
+	}
+
+	userID, err := uuid.Parse(string(uid))
+	if err != nil {
+		return errs.NewResponse(errs.Unauthenticated, err)
+	}
+
+	hash, err := requestHash(data.Payload)
+	if err != nil {
+		return errs.NewResponse(errs.Internal, err)
+	}
+
+	nr := idempotencybus.NewResponse{
+		UserID:      userID,
+		Key:         key,
+		Endpoint:    data.Path,
+		RequestHash: hash,
+	}
+
+	reserved, err := idempotencyBus.Reserve(ctx, nr)
+	if err != nil {
+		if !errors.Is(err, idempotencybus.ErrExists) {
+			return errs.NewResponse(errs.Internal, err)
+		}
+
+		return existing(ctx, idempotencyBus, nr, data.API.ResponseType)
+	}
+
+	resp := next(req)
+
+	// A failed request releases the key so it can be retried. The request
+	// context may be done, so the store isn't tied to it, but it's still
+	// bounded since the database only runs queries that can be cancelled.
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), storeTimeout)
+	defer cancel()
+
+	if resp.Err != nil {
+		if err := idempotencyBus.Release(storeCtx, reserved); err != nil {
+			log.Error(ctx, "idempotency", "msg", "release key", "ERROR", err)
+		}
+		return resp
+	}
+
+	payload, err := json.Marshal(resp.Payload)
+	if err != nil {
+		log.Error(ctx, "idempotency", "msg", "marshal response", "ERROR", err)
+		return resp
+	}
+
+	// The request has already been applied, so a failure to store the
+	// response is logged and the response is still returned.
+	if _, err := idempotencyBus.Complete(storeCtx, reserved, responseStatus(resp), payload); err != nil {
+		log.Error(ctx, "idempotency", "msg", "store response", "ERROR", err)
+	}
+
+	return resp
+}
+
+// existing handles a request whose key is already reserved, replaying the
+// stored response when the first request has completed.
+func existing(ctx context.Context, idempotencyBus *idempotencybus.Business, nr idempotencybus.NewResponse, responseType reflect.Type) middleware.Response {
+	rsp, err := idempotencyBus.QueryByKey(ctx, nr.UserID, nr.Key)
+	if err != nil {
+		// The key was released after the reservation failed, so the
+		// first request failed and this one can be retried.
+		if errors.Is(err, idempotencybus.ErrNotFound) {
+			return errs.NewResponse(errs.Aborted, ErrIdempotencyKeyInProgress)
+		}
+		return errs.NewResponse(errs.Internal, err)
+	}
+
+	if rsp.Endpoint != nr.Endpoint || rsp.RequestHash != nr.RequestHash {
+		return errs.NewResponse(errs.FailedPrecondition, ErrIdempotencyKeyReused)
+	}
+
+	if rsp.InProgress() {
+		return errs.NewResponse(errs.Aborted, ErrIdempotencyKeyInProgress)
+	}
+
+	return replay(rsp, responseType)
+}
+
+func requestHash(payload any) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// responseStatus returns the status the response is written with. A status
+// set by the endpoint is in a field of the payload with the httpstatus tag,
+// which isn't part of the JSON.
+func responseStatus(resp middleware.Response) int {
+	if resp.HTTPStatus != 0 {
+		return resp.HTTPStatus
+	}
+
+	v := reflect.ValueOf(resp.Payload)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return 0
+	}
+
+	if f, ok := statusField(v.Type()); ok {
+		return int(v.FieldByIndex(f.Index).Int())
+	}
+
+	return 0
+}
+
+// statusField finds the field of the struct with the httpstatus tag.
+func statusField(t reflect.Type) (reflect.StructField, bool) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if f.Tag.Get("encore") == "httpstatus" && f.Type.Kind() == reflect.Int {
+			return f, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// replay decodes the stored response into the type the endpoint returns
+// and restores the status it was written with.
+func replay(rsp idempotencybus.Response, responseType reflect.Type) middleware.Response {
+	if responseType == nil {
+		return middleware.Response{
+			HTTPStatus: rsp.HTTPStatus,
+		}
+	}
+
+	v := reflect.New(responseType)
+	if err := json.Unmarshal(rsp.Payload, v.Interface()); err != nil {
+		return errs.NewResponse(errs.Internal, err)
+	}
+
+	elem := v.Elem()
+	for elem.Kind() == reflect.Pointer && !elem.IsNil() {
+		elem = elem.Elem()
+	}
+
+	if rsp.HTTPStatus != 0 && elem.Kind() == reflect.Struct {
+		if f, ok := statusField(elem.Type()); ok {
+			elem.FieldByIndex(f.Index).SetInt(int64(rsp.HTTPStatus))
+		}
+	}
+
+	return middleware.Response{
+		Payload:    v.Elem().Interface(),
+		HTTPStatus: rsp.HTTPStatus,
+	}
+}
@@ -0,0 +1,132 @@
+package mid_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"encore.dev"
+	eauth "encore.dev/beta/auth"
+	eerrs "encore.dev/beta/errs"
+	"encore.dev/et"
+	"encore.dev/middleware"
+	"github.com/ardanlabs/encore/app/sdk/auth"
+	"github.com/ardanlabs/encore/app/sdk/mid"
+	"github.com/ardanlabs/encore/business/sdk/dbtest"
+	"github.com/google/uuid"
+)
+
+type accepted struct {
+	ID         string `json:"id"`
+	HTTPStatus int    `json:"-" encore:"httpstatus"`
+}
+
+func Test_Idempotency(t *testing.T) {
+	edb, err := et.NewTestDatabase(context.Background(), "app")
+	if err != nil {
+		t.Fatalf("Creating new database: %s", err)
+	}
+
+	db := dbtest.NewDatabase(t, edb)
+
+	et.OverrideAuthInfo(eauth.UID(uuid.NewString()), &auth.Claims{})
+
+	ctx, cancel := dbtest.Context()
+	defer cancel()
+
+	newRequest := func(key string, payload any) middleware.Request {
+		return middleware.NewRequest(ctx, &encore.Request{
+			Path:    "/v1/jobs",
+			Headers: http.Header{mid.IdempotencyKeyHeader: {key}},
+			Payload: payload,
+			API: &encore.APIDesc{
+				ResponseType: reflect.TypeFor[accepted](),
+			},
+		})
+	}
+
+	idempotency := func(req middleware.Request, next middleware.Next) middleware.Response {
+		return mid.Idempotency(db.Log, db.BusDomain.Idempotency, req, next)
+	}
+
+	var calls int
+	handler := func(req middleware.Request) middleware.Response {
+		calls++
+
+		return middleware.Response{
+			Payload: accepted{ID: "1", HTTPStatus: http.StatusAccepted},
+		}
+	}
+
+	// -------------------------------------------------------------------------
+
+	t.Run("replay", func(t *testing.T) {
+		calls = 0
+
+		for range 2 {
+			resp := idempotency(newRequest("replay", map[string]string{"kind": "report"}), handler)
+			if resp.Err != nil {
+				t.Fatalf("Should get the response: %s", resp.Err)
+			}
+
+			exp := accepted{ID: "1", HTTPStatus: http.StatusAccepted}
+			if got, _ := resp.Payload.(accepted); got != exp {
+				t.Fatalf("Should get the payload: got %+v, exp %+v", resp.Payload, exp)
+			}
+		}
+
+		if calls != 1 {
+			t.Fatalf("Should apply the request once: got %d", calls)
+		}
+
+		resp := idempotency(newRequest("replay", map[string]string{"kind": "report"}), handler)
+		if resp.HTTPStatus != http.StatusAccepted {
+			t.Fatalf("Should replay the status: got %d, exp %d", resp.HTTPStatus, http.StatusAccepted)
+		}
+	})
+
+	t.Run("reused", func(t *testing.T) {
+		resp := idempotency(newRequest("replay", map[string]string{"kind": "other"}), handler)
+		if code := eerrs.Code(resp.Err); code != eerrs.FailedPrecondition {
+			t.Fatalf("Should reject a key used with a different request: got %s", code)
+		}
+	})
+
+	t.Run("in-progress", func(t *testing.T) {
+		var nested middleware.Response
+
+		outer := func(req middleware.Request) middleware.Response {
+			nested = idempotency(newRequest("in-progress", nil), handler)
+			return handler(req)
+		}
+
+		if resp := idempotency(newRequest("in-progress", nil), outer); resp.Err != nil {
+			t.Fatalf("Should get the response: %s", resp.Err)
+		}
+
+		if code := eerrs.Code(nested.Err); code != eerrs.Aborted {
+			t.Fatalf("Should get a conflict for a request in progress: got %s", code)
+		}
+	})
+
+	t.Run("release", func(t *testing.T) {
+		calls = 0
+
+		fail := func(req middleware.Request) middleware.Response {
+			calls++
+			return middleware.Response{Err: errors.New("failed")}
+		}
+
+		idempotency(newRequest("release", nil), fail)
+
+		if resp := idempotency(newRequest("release", nil), handler); resp.Err != nil {
+			t.Fatalf("Should be able to retry a failed request: %s", resp.Err)
+		}
+
+		if calls != 2 {
+			t.Fatalf("Should apply the retried request: got %d", calls)
+		}
+	})
+}
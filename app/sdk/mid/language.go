@@ -0,0 +1,20 @@
+package mid
+
+import (
+	"encore.dev/middleware"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/i18n"
+)
+
+// Language stores the language the client asked for with the Accept-Language
+// header in the context and localizes the message of any error returned.
+func Language(req middleware.Request, next middleware.Next) middleware.Response {
+	lang := i18n.Parse(req.Data().Headers.Get(i18n.Header))
+
+	resp := next(req.WithContext(i18n.Set(req.Context(), lang)))
+	if resp.Err != nil && lang != i18n.Default {
+		resp.Err = errs.Localize(resp.Err, string(lang))
+	}
+
+	return resp
+}
@@ -0,0 +1,58 @@
+package mid
+
+import (
+	"errors"
+
+	"encore.dev/middleware"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/limiter"
+)
+
+// rateLimited is implemented by response types that can carry the rate
+// limit headers.
+type rateLimited interface {
+	WithRateLimit(rl errs.RateLimit) any
+}
+
+// Limit bounds the number of requests to the endpoint's group that execute
+// at the same time. When the limiter's queue is full, a ResourceExhausted
+// error is returned with the time the client should wait before trying
+// again. The state of the limiter is returned in the error details, or the
+// rate limit headers when the response supports them, so clients can back
+// off intelligently.
+func Limit(g *limiter.Groups, req middleware.Request, next middleware.Next) middleware.Response {
+	l, exists := g.For(req.Data().Endpoint)
+	if !exists {
+		return next(req)
+	}
+
+	release, err := l.Acquire(req.Context())
+	if err != nil {
+		if errors.Is(err, limiter.ErrQueueFull) {
+			return errs.NewRateLimitResponse(errs.ResourceExhausted, rateLimit(l), err)
+		}
+
+		return errs.NewResponse(errs.Canceled, err)
+	}
+	defer release()
+
+	// Capture the state while this request holds its slot so the remaining
+	// value reflects what the client saw.
+	rl := rateLimit(l)
+
+	resp := next(req)
+	if resp.Err != nil {
+		return resp
+	}
+
+	if p, ok := resp.Payload.(rateLimited); ok {
+		resp.Payload = p.WithRateLimit(rl)
+	}
+
+	return resp
+}
+
+func rateLimit(l *limiter.Limiter) errs.RateLimit {
+	st := l.Status()
+	return errs.NewRateLimit(st.Limit, st.Remaining, st.Reset)
+}
@@ -0,0 +1,38 @@
+package mid
+
+import (
+	"time"
+
+	"encore.dev/middleware"
+	"github.com/ardanlabs/encore/app/sdk/reqlog"
+	"github.com/ardanlabs/encore/foundation/logger"
+)
+
+// Logging logs each request with the status and duration of its response.
+// Encore doesn't provide the method to the middleware so the endpoint is
+// logged in its place. The body of a sampled request is logged with its
+// secrets redacted.
+func Logging(log *logger.Logger, cfg reqlog.Config, req middleware.Request, next middleware.Next) middleware.Response {
+	data := req.Data()
+	ctx := req.Context()
+
+	args := []any{"service", data.Service, "endpoint", data.Endpoint, "path", data.Path}
+
+	if data.Payload != nil && cfg.Sample() {
+		body, err := cfg.Body(data.Payload)
+		if err != nil {
+			body = "ERROR: " + err.Error()
+		}
+		args = append(args, "body", body)
+	}
+
+	log.Info(ctx, "request started", args...)
+
+	start := time.Now()
+
+	resp := next(req)
+
+	log.Info(ctx, "request completed", "service", data.Service, "endpoint", data.Endpoint, "path", data.Path, "status", status(resp.Err), "duration", time.Since(start).String())
+
+	return resp
+}
@@ -0,0 +1,18 @@
+package mid
+
+import (
+	"encore.dev/middleware"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/maintenance"
+)
+
+// Maintenance rejects requests while the service is in maintenance mode,
+// telling clients how long to wait before trying again. Endpoints tagged for
+// operations keep working.
+func Maintenance(mode *maintenance.Mode, req middleware.Request, next middleware.Next) middleware.Response {
+	if !mode.Enabled() || maintenance.Exempt(req.Data().API.Tags) {
+		return next(req)
+	}
+
+	return errs.NewRetryResponse(errs.Unavailable, mode.RetryAfter(), maintenance.ErrEnabled)
+}
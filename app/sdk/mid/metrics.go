@@ -1,11 +1,16 @@
 package mid
 
 import (
+	"errors"
+	"time"
+
+	eerrs "encore.dev/beta/errs"
 	"encore.dev/middleware"
 	"github.com/ardanlabs/encore/app/sdk/metrics"
 )
 
-// Metrics sets the basic counters and guages.
+// Metrics sets the basic counters and guages, and records each request by
+// endpoint and the status of its response.
 func Metrics(v *metrics.Values, req middleware.Request, next middleware.Next) middleware.Response {
 	n := v.IncRequests()
 
@@ -13,11 +18,30 @@ func Metrics(v *metrics.Values, req middleware.Request, next middleware.Next) mi
 		v.SetGoroutines()
 	}
 
+	start := time.Now()
+
 	resp := next(req)
 
 	if resp.Err != nil {
 		v.IncFailures()
 	}
 
+	v.AddEndpoint(req.Data().Endpoint, status(resp.Err), time.Since(start))
+
 	return resp
 }
+
+// status returns the error code of the response as the status label, ok
+// when there is no error.
+func status(err error) string {
+	if err == nil {
+		return eerrs.OK.String()
+	}
+
+	var eerr *eerrs.Error
+	if errors.As(err, &eerr) {
+		return eerr.Code.String()
+	}
+
+	return eerrs.Unknown.String()
+}
@@ -9,6 +9,7 @@ import (
 	"encore.dev/middleware"
 	"github.com/ardanlabs/encore/app/sdk/auth"
 	"github.com/ardanlabs/encore/business/domain/homebus"
+	"github.com/ardanlabs/encore/business/domain/jobbus"
 	"github.com/ardanlabs/encore/business/domain/productbus"
 	"github.com/ardanlabs/encore/business/domain/userbus"
 	"github.com/ardanlabs/encore/business/sdk/sqldb"
@@ -32,6 +33,7 @@ const (
 	productKey
 	homeKey
 	trKey
+	jobKey
 )
 
 func setUser(req middleware.Request, usr userbus.User) middleware.Request {
@@ -54,6 +56,17 @@ func GetUserID(ctx context.Context) (uuid.UUID, error) {
 	return v, nil
 }
 
+// GetClaims extracts the claims of the authenticated caller from the
+// context.
+func GetClaims(ctx context.Context) (auth.Claims, error) {
+	claims, ok := eauth.Data().(*auth.Claims)
+	if !ok {
+		return auth.Claims{}, errors.New("claims not found")
+	}
+
+	return *claims, nil
+}
+
 // GetUser extracts the user from the context.
 func GetUser(ctx context.Context) (userbus.User, error) {
 	v, ok := ctx.Value(userKey).(userbus.User)
@@ -65,8 +78,13 @@ func GetUser(ctx context.Context) (userbus.User, error) {
 }
 
 func setProduct(req middleware.Request, prd productbus.Product) middleware.Request {
-	ctx := context.WithValue(req.Context(), productKey, prd)
-	return req.WithContext(ctx)
+	return req.WithContext(WithProduct(req.Context(), prd))
+}
+
+// WithProduct stores the product in the context for handlers that load it
+// themselves instead of through the authorization middleware.
+func WithProduct(ctx context.Context, prd productbus.Product) context.Context {
+	return context.WithValue(ctx, productKey, prd)
 }
 
 // GetProduct returns the product from the context.
@@ -80,8 +98,13 @@ func GetProduct(ctx context.Context) (productbus.Product, error) {
 }
 
 func setHome(req middleware.Request, hme homebus.Home) middleware.Request {
-	ctx := context.WithValue(req.Context(), homeKey, hme)
-	return req.WithContext(ctx)
+	return req.WithContext(WithHome(req.Context(), hme))
+}
+
+// WithHome stores the home in the context for handlers that load it
+// themselves instead of through the authorization middleware.
+func WithHome(ctx context.Context, hme homebus.Home) context.Context {
+	return context.WithValue(ctx, homeKey, hme)
 }
 
 // GetHome returns the home from the context.
@@ -94,11 +117,31 @@ func GetHome(ctx context.Context) (homebus.Home, error) {
 	return v, nil
 }
 
-func setTran(req middleware.Request, tx sqldb.CommitRollbacker) middleware.Request {
-	ctx := context.WithValue(req.Context(), trKey, tx)
+func setJob(req middleware.Request, job jobbus.Job) middleware.Request {
+	ctx := context.WithValue(req.Context(), jobKey, job)
 	return req.WithContext(ctx)
 }
 
+// GetJob returns the job from the context.
+func GetJob(ctx context.Context) (jobbus.Job, error) {
+	v, ok := ctx.Value(jobKey).(jobbus.Job)
+	if !ok {
+		return jobbus.Job{}, errors.New("job not found in context")
+	}
+
+	return v, nil
+}
+
+func setTran(req middleware.Request, tx sqldb.CommitRollbacker) middleware.Request {
+	return req.WithContext(WithTran(req.Context(), tx))
+}
+
+// WithTran stores the transaction in the context for work that spans more
+// than one request, like the operations of a batch.
+func WithTran(ctx context.Context, tx sqldb.CommitRollbacker) context.Context {
+	return context.WithValue(ctx, trKey, tx)
+}
+
 // GetTran retrieves the value that can manage a transaction.
 func GetTran(ctx context.Context) (sqldb.CommitRollbacker, error) {
 	v, ok := ctx.Value(trKey).(sqldb.CommitRollbacker)
@@ -0,0 +1,40 @@
+package mid
+
+import (
+	eauth "encore.dev/beta/auth"
+	"encore.dev/middleware"
+	"github.com/ardanlabs/encore/foundation/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Otel starts the OpenTelemetry span for the request, so the spans for the
+// bus calls and store queries it makes are part of one trace. The user and
+// the IDs on the route are added to the span.
+func Otel(req middleware.Request, next middleware.Next) middleware.Response {
+	data := req.Data()
+
+	attrs := []attribute.KeyValue{
+		attribute.String("endpoint", data.Endpoint),
+		attribute.String("path", data.Path),
+	}
+
+	if userID, ok := eauth.UserID(); ok {
+		attrs = append(attrs, attribute.String("user_id", string(userID)))
+	}
+
+	for _, param := range data.PathParams {
+		attrs = append(attrs, attribute.String("param."+param.Name, param.Value))
+	}
+
+	ctx, span := otel.AddSpan(req.Context(), "api."+data.Service+"."+data.Endpoint, attrs...)
+	defer span.End()
+
+	resp := next(req.WithContext(ctx))
+
+	if resp.Err != nil {
+		span.SetStatus(codes.Error, status(resp.Err))
+	}
+
+	return resp
+}
@@ -6,16 +6,24 @@ import (
 	"encore.dev/middleware"
 	"github.com/ardanlabs/encore/app/sdk/errs"
 	"github.com/ardanlabs/encore/app/sdk/metrics"
+	"github.com/ardanlabs/encore/foundation/logger"
 )
 
-// Panics handles panics that occur when processing a request.
-func Panics(v *metrics.Values, req middleware.Request, next middleware.Next) (resp middleware.Response) {
+// Panics recovers from a panic in the handler or the middleware that runs
+// after it. The panic and its stack are logged with the request so it can
+// be found by the request ID, and the client gets an Internal error that
+// doesn't expose the details.
+func Panics(log *logger.Logger, v *metrics.Values, req middleware.Request, next middleware.Next) (resp middleware.Response) {
 	defer func() {
 		if rec := recover(); rec != nil {
 			trace := debug.Stack()
-			resp = errs.NewResponsef(errs.Internal, "PANIC [%v] TRACE[%s]", rec, string(trace))
+			data := req.Data()
+
+			log.Error(req.Context(), "panic", "service", data.Service, "endpoint", data.Endpoint, "path", data.Path, "panic", rec, "trace", string(trace))
 
 			v.IncPanics()
+
+			resp = errs.NewResponsef(errs.Internal, "internal server error")
 		}
 	}()
 
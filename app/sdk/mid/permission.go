@@ -0,0 +1,35 @@
+package mid
+
+import (
+	"fmt"
+
+	eauth "encore.dev/beta/auth"
+	"encore.dev/middleware"
+	"github.com/ardanlabs/encore/app/sdk/auth"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+)
+
+// AuthorizePermission checks the claims of the user making the request grant
+// the permission the endpoint requires. The permissions are loaded into the
+// claims when the request is authenticated, so unlike the rules this doesn't
+// need the auth service. An endpoint that isn't in the table is denied, so a
+// missing entry can't open an endpoint up.
+func AuthorizePermission(perms Permissions, req middleware.Request, next middleware.Next) middleware.Response {
+	claims, ok := eauth.Data().(*auth.Claims)
+	if !ok {
+		return errs.NewResponsef(errs.Unauthenticated, "claims missing from request")
+	}
+
+	endpoint := req.Data().Endpoint
+
+	perm, exists := perms[endpoint]
+	if !exists {
+		return middleware.Response{Err: errs.From(fmt.Errorf("endpoint[%s] has no permission: %w", endpoint, auth.ErrForbidden))}
+	}
+
+	if !claims.HasPermission(perm) {
+		return middleware.Response{Err: errs.From(fmt.Errorf("permission[%s]: %w", perm, auth.ErrForbidden))}
+	}
+
+	return next(req)
+}
@@ -0,0 +1,47 @@
+package mid
+
+import (
+	eauth "encore.dev/beta/auth"
+	"encore.dev/middleware"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/prefs"
+	"github.com/ardanlabs/encore/business/domain/userprefsbus"
+	"github.com/google/uuid"
+)
+
+// Preferences loads the list preferences the authenticated user has stored
+// into the context, so a query that doesn't say how many rows to return or
+// how to order them uses what the user prefers. This must run before the
+// cache middleware so the cache key reflects the preferences.
+func Preferences(userPrefsBus *userprefsbus.Business, req middleware.Request, next middleware.Next) middleware.Response {
+	uid, ok := eauth.UserID()
+	if !ok {
+		return next(req)
+	}
+
+	userID, err := uuid.Parse(string(uid))
+	if err != nil {
+		return errs.NewResponse(errs.Unauthenticated, err)
+	}
+
+	ctx := req.Context()
+
+	stored, err := userPrefsBus.QueryByUserID(ctx, userID)
+	if err != nil {
+		return errs.NewResponse(errs.Internal, err)
+	}
+
+	if len(stored) == 0 {
+		return next(req)
+	}
+
+	defaults := make(prefs.Defaults, len(stored))
+	for _, pref := range stored {
+		defaults[pref.Entity] = prefs.Default{
+			Rows:    pref.Rows,
+			OrderBy: pref.OrderBy,
+		}
+	}
+
+	return next(req.WithContext(prefs.Set(ctx, defaults)))
+}
@@ -0,0 +1,30 @@
+package mid
+
+import (
+	"encore.dev"
+	"encore.dev/middleware"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/requestid"
+)
+
+// RequestID stores the ID of the request in the context so it's written with
+// every log entry, and adds it to the details of any error returned. The ID
+// provided by the client is used when valid, then the trace ID, otherwise a
+// new ID is generated.
+func RequestID(req middleware.Request, next middleware.Next) middleware.Response {
+	id := req.Data().Headers.Get(requestid.Header)
+
+	if !requestid.Valid(id) {
+		id = requestid.New()
+		if trace := encore.CurrentRequest().Trace; trace != nil && trace.TraceID != "" {
+			id = trace.TraceID
+		}
+	}
+
+	resp := next(req.WithContext(requestid.Set(req.Context(), id)))
+	if resp.Err != nil {
+		resp.Err = errs.WithRequestID(resp.Err, id)
+	}
+
+	return resp
+}
@@ -0,0 +1,21 @@
+package mid
+
+// Rules maps the name of an endpoint to the authorization rule it requires,
+// so the rules for a service are declared in one table instead of on each
+// route or in the handlers.
+type Rules map[string]string
+
+// For returns the rule for the endpoint, or the default when the endpoint
+// isn't in the table.
+func (r Rules) For(endpoint string, defaultRule string) string {
+	if rule, exists := r[endpoint]; exists {
+		return rule
+	}
+
+	return defaultRule
+}
+
+// Permissions maps the name of an endpoint to the permission it requires,
+// like product:write, so the permissions for a service are declared in one
+// table like the rules.
+type Permissions map[string]string
@@ -0,0 +1,52 @@
+package mid
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.dev/middleware"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+)
+
+// Timeouts provides the deadline for each endpoint, so the deadlines for a
+// service are declared in one table. A deadline of zero means the endpoint
+// has none.
+type Timeouts struct {
+	Default   time.Duration
+	Endpoints map[string]time.Duration
+}
+
+// For returns the deadline for the endpoint, or the default when the
+// endpoint isn't in the table.
+func (t Timeouts) For(endpoint string) time.Duration {
+	if d, exists := t.Endpoints[endpoint]; exists {
+		return d
+	}
+
+	return t.Default
+}
+
+// Timeout applies the deadline for the endpoint to the request context, which
+// cancels any store query still running when it expires. A request that runs
+// past its deadline gets a deadline exceeded error. Raw endpoints write their
+// own response so only the deadline is applied.
+func Timeout(t Timeouts, req middleware.Request, next middleware.Next) middleware.Response {
+	data := req.Data()
+
+	d := t.For(data.Endpoint)
+	if d <= 0 {
+		return next(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), d)
+	defer cancel()
+
+	resp := next(req.WithContext(ctx))
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && (data.API == nil || !data.API.Raw) {
+		return errs.NewResponsef(errs.DeadlineExceeded, "request took longer than %s", d)
+	}
+
+	return resp
+}
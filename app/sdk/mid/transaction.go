@@ -1,7 +1,6 @@
 package mid
 
 import (
-	"context"
 	"database/sql"
 	"errors"
 
@@ -11,14 +10,17 @@ import (
 	"github.com/ardanlabs/encore/foundation/logger"
 )
 
-// BeginCommitRollback starts a transaction for the domain call.
+// BeginCommitRollback starts a transaction for the domain call. The
+// transaction is bound to the request context, so it's rolled back when the
+// request runs past its deadline. The error from the domain call is returned
+// as is so it keeps its code.
 func BeginCommitRollback(log *logger.Logger, bgn sqldb.Beginner, req middleware.Request, next middleware.Next) middleware.Response {
-	ctx := context.Background()
+	ctx := req.Context()
 
 	hasCommitted := false
 
 	log.Info(ctx, "BEGIN TRANSACTION")
-	tx, err := bgn.Begin()
+	tx, err := bgn.Begin(ctx)
 	if err != nil {
 		return errs.NewResponsef(errs.Internal, "BEGIN TRANSACTION: %s", err)
 	}
@@ -40,7 +42,7 @@ func BeginCommitRollback(log *logger.Logger, bgn sqldb.Beginner, req middleware.
 
 	resp := next(req)
 	if resp.Err != nil {
-		return errs.NewResponsef(errs.Internal, "EXECUTE TRANSACTION: %s", resp.Err)
+		return resp
 	}
 
 	log.Info(ctx, "COMMIT TRANSACTION")
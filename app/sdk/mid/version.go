@@ -0,0 +1,26 @@
+package mid
+
+import (
+	"encore.dev/middleware"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/version"
+)
+
+// Version stores the version of the API from the request path in the
+// context. When the client states the version it expects with the version
+// header, the header must match the path so a client written against one
+// version can't silently call another.
+func Version(req middleware.Request, next middleware.Next) middleware.Response {
+	v, ok := version.FromPath(req.Data().Path)
+	if !ok {
+		return next(req)
+	}
+
+	if h := req.Data().Headers.Get(version.Header); h != "" {
+		if hv, _ := version.Parse(h); hv != v {
+			return errs.NewResponsef(errs.InvalidArgument, "%s header %q doesn't match the %s endpoint", version.Header, h, v)
+		}
+	}
+
+	return next(req.WithContext(version.Set(req.Context(), v)))
+}
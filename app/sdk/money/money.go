@@ -0,0 +1,86 @@
+// Package money provides support for representing an amount of money in the
+// API. The amount is a decimal string so clients never see the rounding
+// errors that come with encoding money as a float.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+)
+
+// DefaultCurrency is the only currency the system currently supports.
+const DefaultCurrency = "USD"
+
+// MaxAmount is the largest amount that can be stored, which is the limit of
+// the NUMERIC(10,2) columns money is stored in.
+const MaxAmount = 99_999_999.99
+
+// Set of error variables for parsing money.
+var (
+	ErrCurrency = errors.New("unsupported currency")
+	ErrAmount   = errors.New("amount must be a positive decimal with at most 2 decimal places")
+	ErrRange    = errors.New("amount must be less than 100000000")
+)
+
+// amountRE matches the only form of amount that is accepted, so values like
+// NaN, Inf, 1e9 or +3 that the float parser allows are rejected.
+var amountRE = regexp.MustCompile(`^\d+(\.\d{1,2})?$`)
+
+// init registers the money validator so a model can declare an amount of
+// money with the money tag.
+func init() {
+	errs.RegisterValidation("money", "{0} must be a valid amount of money", func(value Money) error {
+		_, err := value.Float()
+		return err
+	})
+}
+
+// Money represents an amount in a currency.
+type Money struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// New constructs money in the default currency from an amount stored as a
+// float, rounded to cents.
+func New(amount float64) Money {
+	cents := math.Round(amount * 100)
+
+	return Money{
+		Amount:   strconv.FormatFloat(cents/100, 'f', 2, 64),
+		Currency: DefaultCurrency,
+	}
+}
+
+// Float returns the amount as a float after checking the currency and the
+// amount are valid. An empty currency means the default currency.
+func (m Money) Float() (float64, error) {
+	if m.Currency != "" && m.Currency != DefaultCurrency {
+		return 0, fmt.Errorf("%w: %s", ErrCurrency, m.Currency)
+	}
+
+	if !amountRE.MatchString(m.Amount) {
+		return 0, ErrAmount
+	}
+
+	amount, err := strconv.ParseFloat(m.Amount, 64)
+	if err != nil {
+		return 0, ErrAmount
+	}
+
+	if amount > MaxAmount {
+		return 0, ErrRange
+	}
+
+	return amount, nil
+}
+
+// String returns the amount with the currency.
+func (m Money) String() string {
+	return m.Amount + " " + m.Currency
+}
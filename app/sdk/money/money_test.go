@@ -0,0 +1,68 @@
+package money_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/money"
+)
+
+func Test_Money(t *testing.T) {
+	m := money.New(10.005)
+	if m.Amount != "10.01" || m.Currency != money.DefaultCurrency {
+		t.Fatalf("Should round the amount to cents: %s", m)
+	}
+
+	tests := []struct {
+		name  string
+		money money.Money
+		exp   float64
+		err   error
+	}{
+		{"valid", money.Money{Amount: "12.50", Currency: "USD"}, 12.5, nil},
+		{"default", money.Money{Amount: "3"}, 3, nil},
+		{"currency", money.Money{Amount: "3", Currency: "EUR"}, 0, money.ErrCurrency},
+		{"precision", money.Money{Amount: "3.001", Currency: "USD"}, 0, money.ErrAmount},
+		{"negative", money.Money{Amount: "-3", Currency: "USD"}, 0, money.ErrAmount},
+		{"text", money.Money{Amount: "abc", Currency: "USD"}, 0, money.ErrAmount},
+		{"nan", money.Money{Amount: "NaN", Currency: "USD"}, 0, money.ErrAmount},
+		{"inf", money.Money{Amount: "Inf", Currency: "USD"}, 0, money.ErrAmount},
+		{"exponent", money.Money{Amount: "1e9", Currency: "USD"}, 0, money.ErrAmount},
+		{"sign", money.Money{Amount: "+3", Currency: "USD"}, 0, money.ErrAmount},
+		{"trailing", money.Money{Amount: "3.", Currency: "USD"}, 0, money.ErrAmount},
+		{"leading", money.Money{Amount: ".5", Currency: "USD"}, 0, money.ErrAmount},
+		{"max", money.Money{Amount: "99999999.99", Currency: "USD"}, 99999999.99, nil},
+		{"range", money.Money{Amount: "100000000", Currency: "USD"}, 0, money.ErrRange},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.money.Float()
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: Should get error %v: got %v", tt.name, tt.err, err)
+		}
+
+		if got != tt.exp {
+			t.Fatalf("%s: Should get %v: got %v", tt.name, tt.exp, got)
+		}
+	}
+}
+
+func Test_Validate(t *testing.T) {
+	type product struct {
+		Cost *money.Money `json:"cost" validate:"omitempty,money"`
+	}
+
+	if err := errs.Check(product{Cost: &money.Money{Amount: "12.50"}}); err != nil {
+		t.Fatalf("Should accept a valid amount: %s", err)
+	}
+
+	if err := errs.Check(product{}); err != nil {
+		t.Fatalf("Should accept a missing amount: %s", err)
+	}
+
+	fields := errs.GetFieldErrors(errs.Check(product{Cost: &money.Money{Amount: "3", Currency: "EUR"}}))
+	if len(fields) != 1 || fields[0].Err != "cost must be a valid amount of money" {
+		t.Fatalf("Should reject an invalid amount: got %v", fields)
+	}
+}
@@ -0,0 +1,510 @@
+// Package openapi provides support for describing the API as an OpenAPI 3
+// document. The schemas are reflected from the same app models and query
+// params the endpoints use so the document can't drift from the code.
+package openapi
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	eerrs "encore.dev/beta/errs"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/query"
+)
+
+// Version is the version of the OpenAPI specification that is produced.
+const Version = "3.0.3"
+
+// Document represents an OpenAPI document.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info provides metadata about the API.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem represents the operations for a path keyed by the lower case
+// HTTP method.
+type PathItem map[string]*Operation
+
+// Operation describes a single endpoint.
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+// Parameter describes a path, query or header parameter.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody describes the body of a request.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes a response for a status code.
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Header describes a response header.
+type Header struct {
+	Schema *Schema `json:"schema"`
+}
+
+// MediaType provides the schema for a content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema describes the shape of a value.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Components holds the schemas that are referenced by the operations.
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme describes how a request is authenticated.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat"`
+}
+
+// =============================================================================
+
+// Route describes an endpoint to add to the document. The Request is a
+// value of the type the endpoint accepts, which is decoded from the query
+// string for GET and DELETE requests and from the body otherwise, and the
+// Response is a value of the type the endpoint returns. Either can be nil.
+type Route struct {
+	Name       string
+	Method     string
+	Path       string
+	Summary    string
+	Tag        string
+	Auth       bool
+	Deprecated bool
+	Request    any
+	Response   any
+}
+
+// Generator builds a document from a set of routes.
+type Generator struct {
+	doc Document
+}
+
+// New constructs a generator for a document describing the API.
+func New(title string, version string) *Generator {
+	g := Generator{
+		doc: Document{
+			OpenAPI: Version,
+			Info: Info{
+				Title:   title,
+				Version: version,
+			},
+			Paths: make(map[string]PathItem),
+			Components: Components{
+				Schemas: make(map[string]*Schema),
+				SecuritySchemes: map[string]SecurityScheme{
+					"bearerAuth": {
+						Type:         "http",
+						Scheme:       "bearer",
+						BearerFormat: "JWT",
+					},
+				},
+			},
+		},
+	}
+
+	g.doc.Components.Schemas["Error"] = g.errorSchema()
+
+	return &g
+}
+
+// Add describes the route in the document.
+func (g *Generator) Add(r Route) {
+	p, params := pathParams(r.Path)
+
+	op := Operation{
+		OperationID: r.Name,
+		Summary:     r.Summary,
+		Parameters:  params,
+		Responses: map[string]Response{
+			"default": {
+				Description: "Error",
+				Content:     jsonContent(&Schema{Ref: ref("Error")}),
+			},
+		},
+	}
+
+	if r.Tag != "" {
+		op.Tags = []string{r.Tag}
+	}
+
+	if r.Deprecated {
+		op.Deprecated = true
+	}
+
+	if r.Auth {
+		op.Security = []map[string][]string{{"bearerAuth": {}}}
+	}
+
+	if r.Request != nil {
+		t := indirect(reflect.TypeOf(r.Request))
+
+		switch r.Method {
+		case http.MethodGet, http.MethodDelete:
+			op.Parameters = append(op.Parameters, g.queryParams(t)...)
+
+		default:
+			op.Parameters = append(op.Parameters, headerParams(t)...)
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  jsonContent(g.schema(t)),
+			}
+		}
+	}
+
+	resp := Response{
+		Description: "OK",
+	}
+
+	if r.Response != nil {
+		t := indirect(reflect.TypeOf(r.Response))
+		resp.Headers = responseHeaders(t)
+		resp.Content = jsonContent(g.schema(t))
+	}
+
+	op.Responses["200"] = resp
+
+	item, exists := g.doc.Paths[p]
+	if !exists {
+		item = make(PathItem)
+		g.doc.Paths[p] = item
+	}
+
+	item[strings.ToLower(r.Method)] = &op
+}
+
+// Document returns the document describing the routes that were added.
+func (g *Generator) Document() Document {
+	return g.doc
+}
+
+// =============================================================================
+
+// schemaTyper is implemented by types that are encoded in JSON as another
+// type, like a merge patch field.
+type schemaTyper interface {
+	SchemaType() reflect.Type
+}
+
+var (
+	timeType        = reflect.TypeFor[time.Time]()
+	rawMessageType  = reflect.TypeFor[json.RawMessage]()
+	schemaTyperType = reflect.TypeFor[schemaTyper]()
+)
+
+func (g *Generator) schema(t reflect.Type) *Schema {
+	if t.Kind() != reflect.Pointer && t.Implements(schemaTyperType) {
+		s := *g.schema(reflect.Zero(t).Interface().(schemaTyper).SchemaType())
+		s.Nullable = true
+		return &s
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := *g.schema(t.Elem())
+		s.Nullable = true
+		return &s
+
+	case reflect.String:
+		return &Schema{Type: "string"}
+
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+
+	case reflect.Struct:
+		return g.structSchema(t)
+	}
+
+	return &Schema{}
+}
+
+// structSchema returns a reference to the component for a named struct,
+// adding the component the first time the struct is seen.
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	name := componentName(t)
+	if name == "" {
+		return g.objectSchema(t)
+	}
+
+	if _, exists := g.doc.Components.Schemas[name]; !exists {
+
+		// The entry is added before the fields are reflected so a struct
+		// that refers to itself doesn't recurse forever.
+		g.doc.Components.Schemas[name] = &Schema{}
+		*g.doc.Components.Schemas[name] = *g.objectSchema(t)
+	}
+
+	return &Schema{Ref: ref(name)}
+}
+
+func (g *Generator) objectSchema(t reflect.Type) *Schema {
+	s := Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() || f.Tag.Get("header") != "" {
+			continue
+		}
+
+		name, omitEmpty, skip := jsonName(f)
+		if skip {
+			continue
+		}
+
+		s.Properties[name] = g.schema(f.Type)
+
+		if !omitEmpty && strings.Contains(f.Tag.Get("validate"), "required") {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	return &s
+}
+
+func (g *Generator) queryParams(t reflect.Type) []Parameter {
+	var params []Parameter
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		if name := f.Tag.Get("header"); name != "" {
+			params = append(params, Parameter{Name: name, In: "header", Schema: g.schema(f.Type)})
+			continue
+		}
+
+		params = append(params, Parameter{Name: query.ParamName(f), In: "query", Schema: g.schema(f.Type)})
+	}
+
+	return params
+}
+
+func (g *Generator) errorSchema() *Schema {
+	codes := make([]string, 0, eerrs.Unauthenticated+1)
+	for code := eerrs.OK; code <= eerrs.Unauthenticated; code++ {
+		codes = append(codes, code.String())
+	}
+
+	// The details depend on the error and have the request ID merged in, so
+	// the known fields are described and any others are allowed.
+	details := Schema{
+		Type:                 "object",
+		Nullable:             true,
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: &Schema{},
+	}
+
+	for _, t := range []reflect.Type{
+		reflect.TypeFor[errs.CodeDetails](),
+		reflect.TypeFor[errs.RetryDetails](),
+		reflect.TypeFor[errs.FieldDetails](),
+		reflect.TypeFor[errs.StackDetails](),
+	} {
+		maps.Copy(details.Properties, g.objectSchema(t).Properties)
+	}
+	details.Properties["requestID"] = &Schema{Type: "string"}
+
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code":    {Type: "string", Enum: codes},
+			"message": {Type: "string"},
+			"details": &details,
+		},
+		Required: []string{"code", "message"},
+	}
+}
+
+// =============================================================================
+
+// pathParams converts an Encore path like /v1/users/:userID into an OpenAPI
+// path like /v1/users/{userID} and returns the parameters in the path.
+func pathParams(p string) (string, []Parameter) {
+	var params []Parameter
+
+	segments := strings.Split(p, "/")
+	for i, seg := range segments {
+		if !strings.HasPrefix(seg, ":") && !strings.HasPrefix(seg, "*") {
+			continue
+		}
+
+		name := seg[1:]
+		segments[i] = "{" + name + "}"
+		params = append(params, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+func headerParams(t reflect.Type) []Parameter {
+	var params []Parameter
+
+	for i := range t.NumField() {
+		if name := t.Field(i).Tag.Get("header"); name != "" {
+			params = append(params, Parameter{Name: name, In: "header", Schema: &Schema{Type: "string"}})
+		}
+	}
+
+	return params
+}
+
+func responseHeaders(t reflect.Type) map[string]Header {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var headers map[string]Header
+
+	for i := range t.NumField() {
+		if name := t.Field(i).Tag.Get("header"); name != "" {
+			if headers == nil {
+				headers = make(map[string]Header)
+			}
+			headers[name] = Header{Schema: &Schema{Type: "string"}}
+		}
+	}
+
+	return headers
+}
+
+func jsonName(f reflect.StructField) (name string, omitEmpty bool, skip bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+
+	return name, strings.Contains(opts, "omitempty"), false
+}
+
+// componentName returns the name of the component for a named type using
+// the last element of the package path, ie productapp.Product. The type
+// arguments of a generic type are shortened the same way.
+func componentName(t reflect.Type) string {
+	name := t.Name()
+	if name == "" {
+		return ""
+	}
+
+	name = path.Base(t.PkgPath()) + "." + name
+
+	base, args, generic := strings.Cut(name, "[")
+	if !generic {
+		return name
+	}
+
+	args = strings.TrimSuffix(args, "]")
+
+	var short []string
+	for _, arg := range strings.Split(args, ",") {
+		short = append(short, path.Base(arg))
+	}
+
+	return base + "_" + strings.Join(short, "_")
+}
+
+func ref(name string) string {
+	return "#/components/schemas/" + name
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+func jsonContent(s *Schema) map[string]MediaType {
+	return map[string]MediaType{
+		"application/json": {Schema: s},
+	}
+}
@@ -0,0 +1,207 @@
+package openapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ardanlabs/encore/app/sdk/openapi"
+	"github.com/ardanlabs/encore/app/sdk/patch"
+	"github.com/ardanlabs/encore/app/sdk/query"
+)
+
+type queryParams struct {
+	Page    string
+	OrderBy string
+	CostGTE string `query:"cost[gte]"`
+}
+
+type item struct {
+	ID    string   `json:"id"`
+	Tags  []string `json:"tags"`
+	Cost  float64  `json:"cost"`
+	ETag  string   `json:"etag,omitempty" header:"ETag"`
+	Inner *item    `json:"inner"`
+}
+
+type newItem struct {
+	Name    string              `json:"name" validate:"required"`
+	Note    patch.Field[string] `json:"note"`
+	IfMatch string              `header:"If-Match"`
+}
+
+func Test_Generator(t *testing.T) {
+	gen := openapi.New("Test API", "1.0")
+
+	gen.Add(openapi.Route{Name: "ItemQuery", Method: http.MethodGet, Path: "/v1/items", Auth: true, Request: queryParams{}, Response: query.Result[item]{}})
+	gen.Add(openapi.Route{Name: "ItemCreate", Method: http.MethodPut, Path: "/v1/items/:itemID", Request: newItem{}, Response: item{}})
+
+	doc := gen.Document()
+
+	// -------------------------------------------------------------------------
+
+	qry := doc.Paths["/v1/items"]["get"]
+	if qry == nil {
+		t.Fatalf("Should find the query operation: %v", doc.Paths)
+	}
+
+	var names []string
+	for _, p := range qry.Parameters {
+		names = append(names, p.In+":"+p.Name)
+	}
+
+	exp := []string{"query:page", "query:order_by", "query:cost[gte]"}
+	if len(names) != len(exp) {
+		t.Fatalf("Should get the query params:\ngot: %v\nexp: %v", names, exp)
+	}
+	for i := range exp {
+		if names[i] != exp[i] {
+			t.Fatalf("Should get the query params:\ngot: %v\nexp: %v", names, exp)
+		}
+	}
+
+	if len(qry.Security) != 1 {
+		t.Fatalf("Should require a bearer token for an auth route")
+	}
+
+	if _, exists := doc.Components.Schemas["query.Result_openapi_test.item"]; !exists {
+		t.Fatalf("Should name the generic result component: %v", keys(doc.Components.Schemas))
+	}
+
+	// -------------------------------------------------------------------------
+
+	crt := doc.Paths["/v1/items/{itemID}"]["put"]
+	if crt == nil {
+		t.Fatalf("Should convert the path params: %v", keys(doc.Paths))
+	}
+
+	if len(crt.Parameters) != 2 || crt.Parameters[0].In != "path" || crt.Parameters[1].Name != "If-Match" {
+		t.Fatalf("Should get the path and header params: %+v", crt.Parameters)
+	}
+
+	if _, exists := crt.Responses["200"].Headers["ETag"]; !exists {
+		t.Fatalf("Should describe the response headers: %+v", crt.Responses["200"])
+	}
+
+	body := doc.Components.Schemas["openapi_test.newItem"]
+	if body == nil {
+		t.Fatalf("Should find the request body component: %v", keys(doc.Components.Schemas))
+	}
+
+	if _, exists := body.Properties["IfMatch"]; exists {
+		t.Fatalf("Should not include header fields in the body")
+	}
+
+	if note := body.Properties["note"]; note == nil || note.Type != "string" || !note.Nullable {
+		t.Fatalf("Should describe a patch field by its value: %+v", note)
+	}
+
+	if len(body.Required) != 1 || body.Required[0] != "name" {
+		t.Fatalf("Should mark required fields: %v", body.Required)
+	}
+
+	itm := doc.Components.Schemas["openapi_test.item"]
+	if itm == nil || itm.Properties["inner"].Ref == "" {
+		t.Fatalf("Should reference a struct that refers to itself: %+v", itm)
+	}
+
+	if _, exists := doc.Components.Schemas["Error"]; !exists {
+		t.Fatalf("Should describe the error shape")
+	}
+}
+
+func keys[T any](m map[string]T) []string {
+	var k []string
+	for key := range m {
+		k = append(k, key)
+	}
+
+	return k
+}
+
+func Test_Validate(t *testing.T) {
+	gen := openapi.New("Test API", "1.0")
+
+	gen.Add(openapi.Route{Name: "ItemQuery", Method: http.MethodGet, Path: "/v1/items", Auth: true, Request: queryParams{}, Response: query.Result[item]{}})
+	gen.Add(openapi.Route{Name: "ItemCreate", Method: http.MethodPut, Path: "/v1/items/:itemID", Request: newItem{}, Response: item{}})
+	gen.Add(openapi.Route{Name: "ItemDelete", Method: http.MethodDelete, Path: "/v1/items/:itemID"})
+
+	doc := gen.Document()
+
+	table := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		body   string
+		exp    string
+	}{
+		{
+			name:   "query",
+			method: http.MethodGet,
+			path:   "/v1/items?page=1&rows=2",
+			status: http.StatusOK,
+			body:   `{"items":[{"id":"1","tags":null,"cost":1.5,"inner":null}],"total":1,"page":1,"rowsPerPage":2}`,
+		},
+		{
+			name:   "wrongtype",
+			method: http.MethodGet,
+			path:   "/v1/items",
+			status: http.StatusOK,
+			body:   `{"items":[{"id":"1","cost":"1.5"}],"total":1,"page":1,"rowsPerPage":2}`,
+			exp:    "body.items[0].cost: got a string for a number",
+		},
+		{
+			name:   "unknown",
+			method: http.MethodPut,
+			path:   "/v1/items/1",
+			status: http.StatusOK,
+			body:   `{"id":"1","inner":{"id":"2","price":3}}`,
+			exp:    "body.inner.price: isn't described",
+		},
+		{
+			name:   "error",
+			method: http.MethodPut,
+			path:   "/v1/items/1",
+			status: http.StatusUnauthorized,
+			body:   `{"code":"unauthenticated","message":"expected authorization header format: Bearer <token>","details":null}`,
+		},
+		{
+			name:   "nocontent",
+			method: http.MethodDelete,
+			path:   "/v1/items/1",
+			status: http.StatusOK,
+			body:   `{"id":"1"}`,
+			exp:    `body isn't described: {"id":"1"}`,
+		},
+		{
+			name:   "nopath",
+			method: http.MethodPost,
+			path:   "/v1/items",
+			status: http.StatusOK,
+			exp:    "POST /v1/items: no operation is described",
+		},
+	}
+
+	for _, tt := range table {
+		err := doc.ValidateResponse(tt.method, tt.path, tt.status, []byte(tt.body))
+
+		var got string
+		if err != nil {
+			got = err.Error()
+		}
+
+		if got != tt.exp {
+			t.Errorf("%s: Should validate the response:\ngot: %q\nexp: %q", tt.name, got, tt.exp)
+		}
+	}
+
+	// -------------------------------------------------------------------------
+
+	if err := doc.ValidateRequest(http.MethodPut, "/v1/items/1", []byte(`{"note":null}`)); err == nil || err.Error() != "body.name: is required" {
+		t.Fatalf("Should require the required fields: %v", err)
+	}
+
+	if err := doc.ValidateRequest(http.MethodPut, "/v1/items/1", []byte(`{"name":"a","note":null}`)); err != nil {
+		t.Fatalf("Should accept a null patch field: %s", err)
+	}
+}
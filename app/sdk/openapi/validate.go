@@ -0,0 +1,262 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidateRequest validates the JSON body of a request to the path against
+// the operation for the method. The path is the one the request was sent
+// to, like /v1/products/4c1c0d5e-3c24-4bbe-8c39-6b7e8c1c6bd7?page=1.
+func (d Document) ValidateRequest(method string, path string, body []byte) error {
+	op, err := d.operation(method, path)
+	if err != nil {
+		return err
+	}
+
+	var schema *Schema
+	if op.RequestBody != nil {
+		schema = op.RequestBody.Content["application/json"].Schema
+	}
+
+	return d.validateBody(schema, body)
+}
+
+// ValidateResponse validates the JSON body of a response with the status
+// code against the operation for the method and path. The error response
+// is used for a status code that isn't described.
+func (d Document) ValidateResponse(method string, path string, status int, body []byte) error {
+	op, err := d.operation(method, path)
+	if err != nil {
+		return err
+	}
+
+	resp, exists := op.Responses[strconv.Itoa(status)]
+	if !exists {
+		resp, exists = op.Responses["default"]
+		if !exists {
+			return fmt.Errorf("%s %s: status %d isn't described", method, path, status)
+		}
+	}
+
+	return d.validateBody(resp.Content["application/json"].Schema, body)
+}
+
+// operation finds the operation for the method with the path that matches
+// the path a request was sent to. A literal segment is preferred over a
+// parameter.
+func (d Document) operation(method string, path string) (*Operation, error) {
+	path, _, _ = strings.Cut(path, "?")
+	segments := strings.Split(path, "/")
+
+	var found *Operation
+	var foundParams int
+
+	for p, item := range d.Paths {
+		op, exists := item[strings.ToLower(method)]
+		if !exists {
+			continue
+		}
+
+		params, match := matchPath(strings.Split(p, "/"), segments)
+		if !match {
+			continue
+		}
+
+		if found == nil || params < foundParams {
+			found = op
+			foundParams = params
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("%s %s: no operation is described", method, path)
+	}
+
+	return found, nil
+}
+
+func matchPath(template []string, segments []string) (int, bool) {
+	if len(template) != len(segments) {
+		return 0, false
+	}
+
+	var params int
+	for i, seg := range template {
+		switch {
+		case strings.HasPrefix(seg, "{"):
+			params++
+		case seg != segments[i]:
+			return 0, false
+		}
+	}
+
+	return params, true
+}
+
+// =============================================================================
+
+func (d Document) validateBody(schema *Schema, body []byte) error {
+	body = bytes.TrimSpace(body)
+
+	switch {
+	case schema == nil && len(body) == 0:
+		return nil
+
+	case schema == nil:
+		return fmt.Errorf("body isn't described: %s", body)
+
+	case len(body) == 0:
+		return fmt.Errorf("body is missing")
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+
+	return d.validate(schema, v, "body")
+}
+
+func (d Document) validate(s *Schema, v any, at string) error {
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, ref(""))
+
+		rs, exists := d.Components.Schemas[name]
+		if !exists {
+			return fmt.Errorf("%s: schema %s isn't described", at, name)
+		}
+
+		return d.validate(rs, v, at)
+	}
+
+	if v == nil {
+
+		// Go writes nil slices and maps as null, so they are accepted like
+		// the values that are nullable.
+		if s.Nullable || s.Type == "" || s.Type == "array" || s.Type == "object" {
+			return nil
+		}
+
+		return fmt.Errorf("%s: got null for a %s", at, s.Type)
+	}
+
+	switch s.Type {
+	case "":
+		return nil
+
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return typeError(at, s.Type, v)
+		}
+
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			return fmt.Errorf("%s: %q isn't one of %v", at, str, s.Enum)
+		}
+
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				return fmt.Errorf("%s: %q isn't a date-time", at, str)
+			}
+		}
+
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return typeError(at, s.Type, v)
+		}
+
+		if _, err := n.Int64(); err != nil {
+			return fmt.Errorf("%s: %s isn't an integer", at, n)
+		}
+
+	case "number":
+		if _, ok := v.(json.Number); !ok {
+			return typeError(at, s.Type, v)
+		}
+
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return typeError(at, s.Type, v)
+		}
+
+	case "array":
+		items, ok := v.([]any)
+		if !ok {
+			return typeError(at, s.Type, v)
+		}
+
+		if s.Items == nil {
+			return nil
+		}
+
+		for i, item := range items {
+			if err := d.validate(s.Items, item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return typeError(at, s.Type, v)
+		}
+
+		return d.validateObject(s, obj, at)
+	}
+
+	return nil
+}
+
+func (d Document) validateObject(s *Schema, obj map[string]any, at string) error {
+	for _, name := range s.Required {
+		if _, exists := obj[name]; !exists {
+			return fmt.Errorf("%s.%s: is required", at, name)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(obj)) {
+		ps, exists := s.Properties[name]
+		switch {
+		case exists:
+		case s.AdditionalProperties != nil:
+			ps = s.AdditionalProperties
+		default:
+			return fmt.Errorf("%s.%s: isn't described", at, name)
+		}
+
+		if err := d.validate(ps, obj[name], at+"."+name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func typeError(at string, typ string, v any) error {
+	var got string
+	switch v.(type) {
+	case string:
+		got = "string"
+	case json.Number:
+		got = "number"
+	case bool:
+		got = "boolean"
+	case []any:
+		got = "array"
+	case map[string]any:
+		got = "object"
+	}
+
+	return fmt.Errorf("%s: got a %s for a %s", at, got, typ)
+}
@@ -0,0 +1,87 @@
+// Package patch provides support for JSON merge patch (RFC 7386) documents.
+// In a merge patch a field that is missing is left alone and a field set to
+// null is removed, which pointer fields in an update model can't tell apart.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+// ErrNull is returned when a field that can't be removed is set to null.
+var ErrNull = errors.New("field can't be null")
+
+// Field represents a single field in a merge patch document. The fields are
+// not part of the JSON schema since the document only contains the value.
+type Field[T any] struct {
+	Set   bool `json:"-"`
+	Null  bool `json:"-"`
+	Value T    `json:"-"`
+}
+
+// Value constructs a field set to the specified value.
+func Value[T any](v T) Field[T] {
+	return Field[T]{
+		Set:   true,
+		Value: v,
+	}
+}
+
+// Null constructs a field set to null.
+func Null[T any]() Field[T] {
+	return Field[T]{
+		Set:  true,
+		Null: true,
+	}
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It is only called
+// when the field is present in the document.
+func (f *Field[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		f.Null = true
+		return nil
+	}
+
+	return json.Unmarshal(data, &f.Value)
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (f Field[T]) MarshalJSON() ([]byte, error) {
+	if !f.Set || f.Null {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(f.Value)
+}
+
+// SchemaType returns the type the field is encoded as so the API
+// documentation can describe the value instead of the field.
+func (Field[T]) SchemaType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+// Optional returns the field for use in an update model. A missing field is
+// nil and a null field points to the zero value so it is cleared.
+func (f Field[T]) Optional() *T {
+	if !f.Set {
+		return nil
+	}
+
+	v := f.Value
+	return &v
+}
+
+// Required returns the field for use in an update model like Optional, but
+// fails when the field is null since the field can't be cleared.
+func (f Field[T]) Required() (*T, error) {
+	if f.Null {
+		return nil, ErrNull
+	}
+
+	return f.Optional(), nil
+}
@@ -0,0 +1,42 @@
+package patch_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ardanlabs/encore/app/sdk/patch"
+)
+
+type address struct {
+	Address1 patch.Field[string] `json:"address1"`
+	Address2 patch.Field[string] `json:"address2"`
+	City     patch.Field[string] `json:"city"`
+}
+
+func Test_Field(t *testing.T) {
+	var addr address
+	if err := json.Unmarshal([]byte(`{"address1":"123 Main","address2":null}`), &addr); err != nil {
+		t.Fatalf("Should be able to decode the patch: %s", err)
+	}
+
+	if v := addr.Address1.Optional(); v == nil || *v != "123 Main" {
+		t.Fatalf("Should get the value for a field that is set: %v", v)
+	}
+
+	if v := addr.Address2.Optional(); v == nil || *v != "" {
+		t.Fatalf("Should get the zero value for a field that is null: %v", v)
+	}
+
+	if v := addr.City.Optional(); v != nil {
+		t.Fatalf("Should get nil for a field that is missing: %v", *v)
+	}
+
+	if _, err := addr.Address2.Required(); !errors.Is(err, patch.ErrNull) {
+		t.Fatalf("Should get ErrNull for a required field that is null: %v", err)
+	}
+
+	if v, err := addr.City.Required(); err != nil || v != nil {
+		t.Fatalf("Should get nil for a required field that is missing: %v %v", v, err)
+	}
+}
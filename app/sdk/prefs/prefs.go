@@ -0,0 +1,90 @@
+// Package prefs carries the list preferences a user has stored through the
+// request context, so an app can use them when a query doesn't say how many
+// rows to return or how to order them.
+package prefs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Tag is the endpoint tag that has the user's preferences loaded.
+const Tag = "preferences"
+
+// Set of entities a user can store preferences for.
+const (
+	Homes    = "homes"
+	Products = "products"
+	Users    = "users"
+)
+
+// Default represents what to use for an entity when a query doesn't say.
+// A zero value field means the app default is used.
+type Default struct {
+	Rows    int
+	OrderBy string
+}
+
+// Defaults represents a user's preferences by entity.
+type Defaults map[string]Default
+
+type ctxKey int
+
+const defaultsKey ctxKey = 1
+
+// Set stores the user's preferences in the context.
+func Set(ctx context.Context, d Defaults) context.Context {
+	return context.WithValue(ctx, defaultsKey, d)
+}
+
+// Get returns the user's preferences from the context.
+func Get(ctx context.Context) Defaults {
+	d, _ := ctx.Value(defaultsKey).(Defaults)
+	return d
+}
+
+// Apply returns the rows and order for the entity, using the user's
+// preferences for the values the query didn't provide.
+func Apply(ctx context.Context, entity string, rows string, orderBy string) (string, string) {
+	d, exists := Get(ctx)[entity]
+	if !exists {
+		return rows, orderBy
+	}
+
+	if rows == "" && d.Rows > 0 {
+		rows = strconv.Itoa(d.Rows)
+	}
+
+	if orderBy == "" {
+		orderBy = d.OrderBy
+	}
+
+	return rows, orderBy
+}
+
+// Key returns a stable representation of the user's preferences in the
+// context, so a cached response built with them isn't returned to a user
+// with different preferences. An empty string is returned when there are
+// none.
+func Key(ctx context.Context) string {
+	d := Get(ctx)
+	if len(d) == 0 {
+		return ""
+	}
+
+	entities := make([]string, 0, len(d))
+	for entity := range d {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+
+	parts := make([]string, len(entities))
+	for i, entity := range entities {
+		parts[i] = fmt.Sprintf("%s:%d:%s", entity, d[entity].Rows, d[entity].OrderBy)
+	}
+
+	return strings.Join(parts, ";")
+}
@@ -0,0 +1,72 @@
+package prefs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ardanlabs/encore/app/sdk/prefs"
+)
+
+func Test_Apply(t *testing.T) {
+	ctx := prefs.Set(context.Background(), prefs.Defaults{
+		prefs.Products: {Rows: 25, OrderBy: "name,DESC"},
+		prefs.Homes:    {Rows: 50},
+	})
+
+	table := []struct {
+		name    string
+		entity  string
+		rows    string
+		orderBy string
+		expRows string
+		expBy   string
+	}{
+		{name: "defaults", entity: prefs.Products, expRows: "25", expBy: "name,DESC"},
+		{name: "rows", entity: prefs.Products, rows: "5", expRows: "5", expBy: "name,DESC"},
+		{name: "orderBy", entity: prefs.Products, orderBy: "cost", expRows: "25", expBy: "cost"},
+		{name: "partial", entity: prefs.Homes, expRows: "50", expBy: ""},
+		{name: "none", entity: prefs.Users, expRows: "", expBy: ""},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, orderBy := prefs.Apply(ctx, tt.entity, tt.rows, tt.orderBy)
+
+			if rows != tt.expRows {
+				t.Fatalf("Should get back the right rows: got %q, exp %q", rows, tt.expRows)
+			}
+
+			if orderBy != tt.expBy {
+				t.Fatalf("Should get back the right orderBy: got %q, exp %q", orderBy, tt.expBy)
+			}
+		})
+	}
+}
+
+func Test_Key(t *testing.T) {
+	if key := prefs.Key(context.Background()); key != "" {
+		t.Fatalf("Should get an empty key without preferences: got %q", key)
+	}
+
+	a := prefs.Set(context.Background(), prefs.Defaults{
+		prefs.Products: {Rows: 25},
+		prefs.Homes:    {OrderBy: "type"},
+	})
+
+	b := prefs.Set(context.Background(), prefs.Defaults{
+		prefs.Homes:    {OrderBy: "type"},
+		prefs.Products: {Rows: 25},
+	})
+
+	if prefs.Key(a) != prefs.Key(b) {
+		t.Fatalf("Should get the same key for the same preferences: %q, %q", prefs.Key(a), prefs.Key(b))
+	}
+
+	c := prefs.Set(context.Background(), prefs.Defaults{
+		prefs.Products: {Rows: 10},
+	})
+
+	if prefs.Key(a) == prefs.Key(c) {
+		t.Fatalf("Should get a different key for different preferences: %q", prefs.Key(a))
+	}
+}
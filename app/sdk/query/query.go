@@ -2,15 +2,42 @@
 package query
 
 import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ardanlabs/encore/app/sdk/deprecation"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/fields"
+	"github.com/ardanlabs/encore/app/sdk/links"
 	"github.com/ardanlabs/encore/business/sdk/page"
 )
 
-// Result is the data model used when returning a query result.
+// Result is the data model used when returning a query result. When keyset
+// paging is used, Page is zero and the cursors identify the adjacent pages.
+// When Fields is set, only those fields of each item are returned. The rate
+// limit and deprecation headers are only set when the endpoint is rate
+// limited or deprecated.
 type Result[T any] struct {
-	Items       []T `json:"items"`
-	Total       int `json:"total"`
-	Page        int `json:"page"`
-	RowsPerPage int `json:"rowsPerPage"`
+	Items       []T         `json:"items"`
+	Total       int         `json:"total"`
+	Page        int         `json:"page"`
+	RowsPerPage int         `json:"rowsPerPage"`
+	NextCursor  string      `json:"nextCursor,omitempty"`
+	PrevCursor  string      `json:"prevCursor,omitempty"`
+	Links       links.Links `json:"links,omitempty"`
+	Fields      fields.Set  `json:"-"`
+
+	RateLimitLimit     string `json:"-" header:"X-RateLimit-Limit"`
+	RateLimitRemaining string `json:"-" header:"X-RateLimit-Remaining"`
+	RateLimitReset     string `json:"-" header:"X-RateLimit-Reset"`
+
+	Deprecation string `json:"-" header:"Deprecation"`
+	Sunset      string `json:"-" header:"Sunset"`
+	Link        string `json:"-" header:"Link"`
 }
 
 // NewResult constructs a result value to return query results.
@@ -22,3 +49,176 @@ func NewResult[T any](items []T, total int, page page.Page) Result[T] {
 		RowsPerPage: page.RowsPerPage(),
 	}
 }
+
+// NewKeysetResult constructs a result value to return keyset paged query
+// results.
+func NewKeysetResult[T any](items []T, total int, keyset page.Keyset, next string, prev string) Result[T] {
+	return Result[T]{
+		Items:       items,
+		Total:       total,
+		RowsPerPage: keyset.Limit(),
+		NextCursor:  next,
+		PrevCursor:  prev,
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface so the items can be
+// projected to the requested fields.
+func (r Result[T]) MarshalJSON() ([]byte, error) {
+	items := make([]json.RawMessage, len(r.Items))
+	for i, item := range r.Items {
+		data, err := fields.Marshal(item, r.Fields)
+		if err != nil {
+			return nil, err
+		}
+
+		items[i] = data
+	}
+
+	result := struct {
+		Items       []json.RawMessage `json:"items"`
+		Total       int               `json:"total"`
+		Page        int               `json:"page"`
+		RowsPerPage int               `json:"rowsPerPage"`
+		NextCursor  string            `json:"nextCursor,omitempty"`
+		PrevCursor  string            `json:"prevCursor,omitempty"`
+		Links       links.Links       `json:"links,omitempty"`
+	}{
+		Items:       items,
+		Total:       r.Total,
+		Page:        r.Page,
+		RowsPerPage: r.RowsPerPage,
+		NextCursor:  r.NextCursor,
+		PrevCursor:  r.PrevCursor,
+		Links:       r.Links,
+	}
+
+	return json.Marshal(result)
+}
+
+// WithLinks returns the result with links to this page and the adjacent
+// pages of the collection at the path. The query params are carried over
+// to the links so the filters and ordering are kept.
+func (r Result[T]) WithLinks(lb *links.Builder, path string, qp any) Result[T] {
+	values := paramValues(qp)
+
+	r.Links = links.Links{
+		links.Self: lb.Link(path, values),
+	}
+
+	add := func(rel string, key string, value string) {
+		v := cloneValues(values)
+		v.Set(key, value)
+		r.Links[rel] = lb.Link(path, v)
+	}
+
+	switch {
+	case r.Page == 0:
+		values.Del("page")
+		if r.NextCursor != "" {
+			add(links.Next, "cursor", r.NextCursor)
+		}
+		if r.PrevCursor != "" {
+			add(links.Prev, "cursor", r.PrevCursor)
+		}
+
+	default:
+		values.Del("cursor")
+		if r.Page*r.RowsPerPage < r.Total {
+			add(links.Next, "page", strconv.Itoa(r.Page+1))
+		}
+		if r.Page > 1 {
+			add(links.Prev, "page", strconv.Itoa(r.Page-1))
+		}
+	}
+
+	return r
+}
+
+// WithRateLimit returns the result with the rate limit headers set. It
+// returns any so middleware can set the headers without knowing the type of
+// the items.
+func (r Result[T]) WithRateLimit(rl errs.RateLimit) any {
+	r.RateLimitLimit = strconv.Itoa(rl.Limit)
+	r.RateLimitRemaining = strconv.Itoa(rl.Remaining)
+	r.RateLimitReset = strconv.Itoa(rl.ResetSeconds)
+
+	return r
+}
+
+// WithDeprecation returns the result with the deprecation headers set. It
+// returns any so middleware can set the headers without knowing the type of
+// the items.
+func (r Result[T]) WithDeprecation(n deprecation.Notice) any {
+	r.Deprecation = n.Deprecation
+	r.Sunset = n.Sunset
+	r.Link = n.Link
+
+	return r
+}
+
+// =============================================================================
+
+// paramValues returns the query string values for the string fields in a
+// query params struct that are set.
+func paramValues(qp any) url.Values {
+	values := make(url.Values)
+
+	v := reflect.Indirect(reflect.ValueOf(qp))
+	if v.Kind() != reflect.Struct {
+		return values
+	}
+
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() || f.Type.Kind() != reflect.String {
+			continue
+		}
+
+		if value := v.Field(i).String(); value != "" {
+			values.Set(ParamName(f), value)
+		}
+	}
+
+	return values
+}
+
+func cloneValues(values url.Values) url.Values {
+	clone := make(url.Values, len(values))
+	for k, v := range values {
+		clone[k] = append([]string(nil), v...)
+	}
+
+	return clone
+}
+
+// ParamName returns the name of the query string for a field in a query
+// params struct. The name comes from its query tag, otherwise its name is
+// converted to snake case like Encore does.
+func ParamName(f reflect.StructField) string {
+	if name := f.Tag.Get("query"); name != "" {
+		return name
+	}
+
+	return snakeCase(f.Name)
+}
+
+func snakeCase(name string) string {
+	var b strings.Builder
+
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && unicode.IsLower(runes[i-1])
+			nextLower := i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])
+			if prevLower || nextLower {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package query_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/links"
+	"github.com/ardanlabs/encore/app/sdk/query"
+	"github.com/ardanlabs/encore/business/sdk/page"
+)
+
+type queryParams struct {
+	Page    string
+	Rows    string
+	Cursor  string
+	OrderBy string
+	CostGTE string `query:"cost[gte]"`
+}
+
+func Test_WithLinks(t *testing.T) {
+	lb := links.New("http://localhost:4000/")
+
+	qp := queryParams{Page: "2", Rows: "10", OrderBy: "name", CostGTE: "5"}
+	result := query.NewResult([]string{"a"}, 35, page.MustParse(qp.Page, qp.Rows)).WithLinks(lb, "/v1/products", qp)
+
+	exp := links.Links{
+		links.Self: {Href: "http://localhost:4000/v1/products?cost%5Bgte%5D=5&order_by=name&page=2&rows=10"},
+		links.Next: {Href: "http://localhost:4000/v1/products?cost%5Bgte%5D=5&order_by=name&page=3&rows=10"},
+		links.Prev: {Href: "http://localhost:4000/v1/products?cost%5Bgte%5D=5&order_by=name&page=1&rows=10"},
+	}
+
+	for rel, link := range exp {
+		if result.Links[rel] != link {
+			t.Fatalf("Should get the %s link:\ngot: %s\nexp: %s", rel, result.Links[rel].Href, link.Href)
+		}
+	}
+
+	// The last page doesn't have a next page.
+	qp.Page = "4"
+	result = query.NewResult([]string{"a"}, 35, page.MustParse(qp.Page, qp.Rows)).WithLinks(lb, "/v1/products", qp)
+	if _, exists := result.Links[links.Next]; exists {
+		t.Fatalf("Should not get a next link on the last page")
+	}
+
+	// Keyset paging follows the cursors.
+	kqp := queryParams{Cursor: "abc"}
+	result = query.Result[string]{NextCursor: "def"}.WithLinks(lb, "/v1/products", kqp)
+	if got := result.Links[links.Next].Href; got != "http://localhost:4000/v1/products?cursor=def" {
+		t.Fatalf("Should get the next cursor link: %s", got)
+	}
+	if _, exists := result.Links[links.Prev]; exists {
+		t.Fatalf("Should not get a prev link without a cursor")
+	}
+}
+
+func Test_WithRateLimit(t *testing.T) {
+	r := query.Result[string]{Items: []string{"a"}}
+
+	got, ok := r.WithRateLimit(errs.RateLimit{Limit: 20, Remaining: 19, ResetSeconds: 5}).(query.Result[string])
+	if !ok {
+		t.Fatalf("Should keep the type of the result")
+	}
+
+	if got.RateLimitLimit != "20" || got.RateLimitRemaining != "19" || got.RateLimitReset != "5" {
+		t.Fatalf("Should set the rate limit headers: got %s/%s/%s", got.RateLimitLimit, got.RateLimitRemaining, got.RateLimitReset)
+	}
+}
@@ -0,0 +1,124 @@
+// Package reqlog provides support for logging requests, deciding which
+// request bodies are logged and removing the secrets they hold first.
+package reqlog
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"strings"
+
+	"encore.dev"
+)
+
+// Redacted replaces the value of a sensitive field in a logged body.
+const Redacted = "[REDACTED]"
+
+// sensitive are the parts of a field name that mark its value as a secret.
+var sensitive = []string{"password", "token", "secret", "apikey", "authorization"}
+
+// Config decides what is logged about a request.
+type Config struct {
+	BodySampleRate float64 // Share of requests with the body logged, 0 to 1.
+	MaxBodyBytes   int     // Bodies are cut to this size once redacted.
+}
+
+// ForEnvironment returns the config for the specified environment. Every
+// body is logged when running locally, in tests or in a preview environment,
+// a small share in a cloud development environment and none in production.
+func ForEnvironment(env encore.EnvironmentMeta) Config {
+	cfg := Config{
+		MaxBodyBytes: 2 << 10,
+	}
+
+	switch {
+	case env.Type == encore.EnvProduction:
+		cfg.BodySampleRate = 0
+
+	case env.Type == encore.EnvEphemeral, env.Type == encore.EnvTest:
+		cfg.BodySampleRate = 1
+
+	case env.Cloud == encore.CloudLocal:
+		cfg.BodySampleRate = 1
+
+	default:
+		cfg.BodySampleRate = 0.1
+	}
+
+	return cfg
+}
+
+// Sample reports whether the body of the current request should be logged.
+func (c Config) Sample() bool {
+	switch {
+	case c.BodySampleRate <= 0:
+		return false
+
+	case c.BodySampleRate >= 1:
+		return true
+	}
+
+	return rand.Float64() < c.BodySampleRate
+}
+
+// Body returns the payload as JSON with the value of any sensitive field
+// replaced, cut to the max size of the config.
+func (c Config) Body(payload any) (string, error) {
+	data, err := Redact(payload)
+	if err != nil {
+		return "", err
+	}
+
+	if c.MaxBodyBytes > 0 && len(data) > c.MaxBodyBytes {
+		return string(data[:c.MaxBodyBytes]) + "...", nil
+	}
+
+	return string(data), nil
+}
+
+// Redact returns the payload as JSON with the value of any sensitive field
+// replaced.
+func Redact(payload any) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(redact(v))
+}
+
+func redact(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if isSensitive(key) {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = redact(value)
+		}
+
+	case []any:
+		for i := range v {
+			v[i] = redact(v[i])
+		}
+	}
+
+	return v
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+
+	for _, part := range sensitive {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,71 @@
+package reqlog_test
+
+import (
+	"testing"
+
+	"encore.dev"
+	"github.com/ardanlabs/encore/app/sdk/reqlog"
+)
+
+func Test_Body(t *testing.T) {
+	type user struct {
+		Name            string   `json:"name"`
+		Password        string   `json:"password"`
+		PasswordConfirm string   `json:"passwordConfirm"`
+		Tokens          []string `json:"refresh_tokens"`
+		Roles           []string `json:"roles"`
+	}
+
+	cfg := reqlog.Config{MaxBodyBytes: 1 << 10}
+
+	body, err := cfg.Body(user{Name: "bill", Password: "gophers", PasswordConfirm: "gophers", Tokens: []string{"abc"}, Roles: []string{"USER"}})
+	if err != nil {
+		t.Fatalf("Should be able to get the body: %s", err)
+	}
+
+	exp := `{"name":"bill","password":"[REDACTED]","passwordConfirm":"[REDACTED]","refresh_tokens":"[REDACTED]","roles":["USER"]}`
+	if body != exp {
+		t.Fatalf("Should redact the secrets:\nexp: %s\ngot: %s", exp, body)
+	}
+
+	cfg.MaxBodyBytes = 10
+
+	body, err = cfg.Body(map[string]any{"items": []any{map[string]any{"token": "abc", "name": "a long name"}}})
+	if err != nil {
+		t.Fatalf("Should be able to get the body: %s", err)
+	}
+
+	if exp := `{"items":[...`; body != exp {
+		t.Fatalf("Should cut the body to the max size: got %s", body)
+	}
+}
+
+func Test_ForEnvironment(t *testing.T) {
+	tt := []struct {
+		name string
+		env  encore.EnvironmentMeta
+		rate float64
+	}{
+		{name: "local", env: encore.EnvironmentMeta{Type: encore.EnvDevelopment, Cloud: encore.CloudLocal}, rate: 1},
+		{name: "ephemeral", env: encore.EnvironmentMeta{Type: encore.EnvEphemeral, Cloud: encore.CloudGCP}, rate: 1},
+		{name: "development", env: encore.EnvironmentMeta{Type: encore.EnvDevelopment, Cloud: encore.CloudGCP}, rate: 0.1},
+		{name: "production", env: encore.EnvironmentMeta{Type: encore.EnvProduction, Cloud: encore.CloudGCP}, rate: 0},
+	}
+
+	for _, tst := range tt {
+		t.Run(tst.name, func(t *testing.T) {
+			cfg := reqlog.ForEnvironment(tst.env)
+			if cfg.BodySampleRate != tst.rate {
+				t.Fatalf("Should get a rate of %v: got %v", tst.rate, cfg.BodySampleRate)
+			}
+		})
+	}
+
+	if (reqlog.Config{BodySampleRate: 0}).Sample() {
+		t.Fatalf("Should never sample with a rate of 0")
+	}
+
+	if !(reqlog.Config{BodySampleRate: 1}).Sample() {
+		t.Fatalf("Should always sample with a rate of 1")
+	}
+}
@@ -0,0 +1,56 @@
+// Package requestid provides support for identifying a request so a user
+// can quote the ID when reporting a failure and it can be found in the logs.
+package requestid
+
+import (
+	"context"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// Header is the request header a client can use to provide its own ID.
+const Header = "X-Request-ID"
+
+// maxLen is the longest ID accepted from a client so the logs can't be
+// flooded through the header.
+const maxLen = 128
+
+// New generates a new request ID.
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid reports if the ID provided by a client can be used. Only printable
+// ASCII without spaces is accepted.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLen {
+		return false
+	}
+
+	for _, r := range id {
+		if r > unicode.MaxASCII || !unicode.IsGraphic(r) || unicode.IsSpace(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// =============================================================================
+
+type ctxKey int
+
+const requestIDKey ctxKey = 1
+
+// Set stores the request ID in the context.
+func Set(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// Get returns the request ID from the context or an empty string if there
+// isn't one.
+func Get(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
@@ -0,0 +1,42 @@
+package requestid_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/encore/app/sdk/requestid"
+)
+
+func Test_Valid(t *testing.T) {
+	tests := []struct {
+		id  string
+		exp bool
+	}{
+		{"", false},
+		{"6f0b8f8e-1b6a-4c8e-9d0a-2f1f5b1d7c11", true},
+		{"client-42", true},
+		{"has space", false},
+		{"new\nline", false},
+		{"café", false},
+		{strings.Repeat("a", 129), false},
+	}
+
+	for _, tt := range tests {
+		if got := requestid.Valid(tt.id); got != tt.exp {
+			t.Fatalf("%q: Should get %t: got %t", tt.id, tt.exp, got)
+		}
+	}
+
+	if id := requestid.New(); !requestid.Valid(id) {
+		t.Fatalf("Should generate a valid id: got %q", id)
+	}
+
+	if id := requestid.Get(context.Background()); id != "" {
+		t.Fatalf("Should get an empty id without one in the context: got %q", id)
+	}
+
+	if id := requestid.Get(requestid.Set(context.Background(), "abc")); id != "abc" {
+		t.Fatalf("Should get the id from the context: got %q", id)
+	}
+}
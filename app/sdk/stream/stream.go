@@ -0,0 +1,140 @@
+// Package stream provides support for streaming query results as newline
+// delimited JSON, so a client can process a large result one item at a
+// time. The results come from the app layer stream functions which read
+// the rows from the database one at a time.
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"mime"
+	"net/url"
+	"strings"
+
+	eerrs "encore.dev/beta/errs"
+	"github.com/ardanlabs/encore/app/sdk/errs"
+	"github.com/ardanlabs/encore/app/sdk/export"
+	"github.com/ardanlabs/encore/app/sdk/fields"
+	"github.com/ardanlabs/encore/foundation/web"
+)
+
+// ContentType is the media type for newline delimited JSON.
+const ContentType = web.ContentTypeNDJSON
+
+// ErrInterrupted is returned when the stream fails after items have been
+// written. The error has been written to the stream as the last line since
+// the status code has already been sent.
+var ErrInterrupted = errors.New("stream interrupted")
+
+// QueryFunc represents an app layer function that returns every item that
+// matches the query one at a time.
+type QueryFunc[Q any, T any] func(ctx context.Context, qp Q) (iter.Seq2[T, error], error)
+
+// Func represents a function that writes the results of a query described
+// by the query string values.
+type Func func(ctx context.Context, w io.Writer, values url.Values) error
+
+// Line represents the last line written when the stream fails part way.
+type Line struct {
+	Error Error `json:"error"`
+}
+
+// Error represents the error that interrupted the stream.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// NDJSON constructs a function that writes every item from the query
+// function as a line of JSON. The values are decoded into the query params
+// the same way Encore decodes a query string, and the fields value limits
+// the fields that are written. Paging values are ignored since every item
+// is written.
+func NDJSON[Q any, T any](fn QueryFunc[Q, T]) Func {
+	return func(ctx context.Context, w io.Writer, values url.Values) error {
+		set, err := fields.Parse[T](values.Get("fields"))
+		if err != nil {
+			return errs.NewFieldsError("fields", err)
+		}
+
+		var qp Q
+		if err := export.Decode(values, &qp); err != nil {
+			return errs.New(errs.InvalidArgument, err)
+		}
+
+		seq, err := fn(ctx, qp)
+		if err != nil {
+			return err
+		}
+
+		enc := web.NewNDJSON(w)
+
+		for item, err := range seq {
+			if err != nil {
+				return interrupted(enc, err)
+			}
+
+			data, err := fields.Marshal(item, set)
+			if err != nil {
+				return interrupted(enc, err)
+			}
+
+			if err := enc.WriteLine(data); err != nil {
+				return err
+			}
+		}
+
+		enc.Flush()
+
+		return nil
+	}
+}
+
+// Accepts reports if the Accept header allows newline delimited JSON. No
+// header accepts anything.
+func Accepts(accept string) bool {
+	if accept == "" {
+		return true
+	}
+
+	for _, value := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+
+		switch mediaType {
+		case ContentType, "application/*", "*/*":
+			return true
+		}
+	}
+
+	return false
+}
+
+// =============================================================================
+
+// interrupted writes the error as the last line of the stream.
+func interrupted(enc *web.NDJSON, err error) error {
+	line := Line{
+		Error: Error{
+			Code:    errs.Internal.String(),
+			Message: "internal error",
+		},
+	}
+
+	var eerr *eerrs.Error
+	if errors.As(err, &eerr) {
+		line.Error.Code = eerr.Code.String()
+		line.Error.Message = eerr.Message
+	}
+
+	if enc.Encode(line) == nil {
+		enc.Flush()
+	}
+
+	return fmt.Errorf("%w: %w", ErrInterrupted, err)
+}
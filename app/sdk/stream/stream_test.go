@@ -0,0 +1,116 @@
+package stream_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"iter"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/encore/app/sdk/stream"
+)
+
+type queryParams struct {
+	Name string
+}
+
+type product struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func query(items []product, failAt int) stream.QueryFunc[queryParams, product] {
+	return func(ctx context.Context, qp queryParams) (iter.Seq2[product, error], error) {
+		if qp.Name == "invalid" {
+			return nil, errors.New("invalid name")
+		}
+
+		seq := func(yield func(product, error) bool) {
+			for i, item := range items {
+				if i == failAt {
+					yield(product{}, errors.New("connection reset"))
+					return
+				}
+
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+
+		return seq, nil
+	}
+}
+
+func Test_NDJSON(t *testing.T) {
+	items := []product{{ID: "1", Name: "Comic"}, {ID: "2", Name: "Toy"}}
+
+	var buf bytes.Buffer
+	if err := stream.NDJSON(query(items, -1))(context.Background(), &buf, url.Values{}); err != nil {
+		t.Fatalf("Should be able to stream the items: %s", err)
+	}
+
+	exp := `{"id":"1","name":"Comic"}` + "\n" + `{"id":"2","name":"Toy"}` + "\n"
+	if buf.String() != exp {
+		t.Fatalf("Should get a line per item:\nexp: %s\ngot: %s", exp, buf.String())
+	}
+
+	buf.Reset()
+	if err := stream.NDJSON(query(items, -1))(context.Background(), &buf, url.Values{"fields": {"name"}}); err != nil {
+		t.Fatalf("Should be able to stream the items with fields: %s", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), `{"name":"Comic"}`+"\n") {
+		t.Fatalf("Should only get the requested fields: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := stream.NDJSON(query(items, -1))(context.Background(), &buf, url.Values{"name": {"invalid"}}); err == nil || errors.Is(err, stream.ErrInterrupted) {
+		t.Fatalf("Should get the query error before anything is written: %v", err)
+	}
+
+	if buf.Len() != 0 {
+		t.Fatalf("Should not write anything for an invalid query: %s", buf.String())
+	}
+}
+
+func Test_NDJSONInterrupted(t *testing.T) {
+	items := []product{{ID: "1", Name: "Comic"}, {ID: "2", Name: "Toy"}}
+
+	var buf bytes.Buffer
+	err := stream.NDJSON(query(items, 1))(context.Background(), &buf, url.Values{})
+	if !errors.Is(err, stream.ErrInterrupted) {
+		t.Fatalf("Should get an interrupted error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Should get the item and the error lines: %q", lines)
+	}
+
+	if !strings.HasPrefix(lines[1], `{"error":{"code":"internal"`) {
+		t.Fatalf("Should get the error as the last line: %s", lines[1])
+	}
+}
+
+func Test_Accepts(t *testing.T) {
+	tests := []struct {
+		accept string
+		exp    bool
+	}{
+		{"", true},
+		{"application/x-ndjson", true},
+		{"application/json, application/x-ndjson;q=0.9", true},
+		{"*/*", true},
+		{"application/json", false},
+		{"text/csv", false},
+	}
+
+	for _, tt := range tests {
+		if got := stream.Accepts(tt.accept); got != tt.exp {
+			t.Fatalf("%q: Should get %t: got %t", tt.accept, tt.exp, got)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Package version provides support for serving more than one version of the
+// API. A breaking change to a model ships as a new version under its own
+// path prefix, like /v2/products, while the older version keeps its shape.
+package version
+
+import (
+	"context"
+	"strings"
+)
+
+// Header is the request header a client can use to state the version it
+// expects. A request whose header doesn't match the path is rejected.
+const Header = "API-Version"
+
+// Version represents a version of the API.
+type Version string
+
+// Set of versions the API supports.
+const (
+	V1 Version = "v1"
+	V2 Version = "v2"
+)
+
+// Latest is the newest version of the API.
+const Latest = V2
+
+var supported = map[Version]bool{
+	V1: true,
+	V2: true,
+}
+
+// Parse returns the version for the string if it's supported.
+func Parse(value string) (Version, bool) {
+	v := Version(strings.ToLower(value))
+	return v, supported[v]
+}
+
+// FromPath returns the version from the first segment of the path. False
+// is returned when the path isn't versioned.
+func FromPath(path string) (Version, bool) {
+	seg, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return Parse(seg)
+}
+
+// =============================================================================
+
+type ctxKey int
+
+const versionKey ctxKey = 1
+
+// Set stores the version of the API handling the request in the context.
+func Set(ctx context.Context, v Version) context.Context {
+	return context.WithValue(ctx, versionKey, v)
+}
+
+// Get returns the version of the API handling the request. Requests that
+// weren't versioned are treated as version 1.
+func Get(ctx context.Context) Version {
+	v, ok := ctx.Value(versionKey).(Version)
+	if !ok {
+		return V1
+	}
+
+	return v
+}
@@ -0,0 +1,36 @@
+package version_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ardanlabs/encore/app/sdk/version"
+)
+
+func Test_FromPath(t *testing.T) {
+	tests := []struct {
+		path string
+		exp  version.Version
+		ok   bool
+	}{
+		{"/v1/products", version.V1, true},
+		{"/v2/products/123", version.V2, true},
+		{"/v3/products", "v3", false},
+		{"/about", "about", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := version.FromPath(tt.path)
+		if got != tt.exp || ok != tt.ok {
+			t.Fatalf("%s: Should get %s/%t: got %s/%t", tt.path, tt.exp, tt.ok, got, ok)
+		}
+	}
+
+	if v := version.Get(context.Background()); v != version.V1 {
+		t.Fatalf("Should default to version 1: got %s", v)
+	}
+
+	if v := version.Get(version.Set(context.Background(), version.V2)); v != version.V2 {
+		t.Fatalf("Should get the version from the context: got %s", v)
+	}
+}
@@ -0,0 +1,246 @@
+package apikeybus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"encore.dev/et"
+	"github.com/ardanlabs/encore/business/domain/apikeybus"
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/sdk/dbtest"
+	"github.com/ardanlabs/encore/business/sdk/unitest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_APIKey(t *testing.T) {
+	t.Parallel()
+
+	edb, err := et.NewTestDatabase(context.Background(), "app")
+	if err != nil {
+		t.Fatalf("Creating new database: %s", err)
+	}
+
+	db := dbtest.NewDatabase(t, edb)
+
+	sd, err := insertSeedData(db)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, create(db.BusDomain, sd), "create")
+	unitest.Run(t, authenticate(db, sd), "authenticate")
+}
+
+// =============================================================================
+
+type seedData struct {
+	user  userbus.User
+	admin userbus.User
+}
+
+func insertSeedData(db *dbtest.Database) (seedData, error) {
+	ctx, cancel := dbtest.Context()
+	defer cancel()
+
+	usrs, err := userbus.TestSeedUsers(ctx, db.Rand, 1, userbus.Roles.User, db.BusDomain.User)
+	if err != nil {
+		return seedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	admins, err := userbus.TestSeedUsers(ctx, db.Rand, 1, userbus.Roles.Admin, db.BusDomain.User)
+	if err != nil {
+		return seedData{}, fmt.Errorf("seeding admins : %w", err)
+	}
+
+	sd := seedData{
+		user:  usrs[0],
+		admin: admins[0],
+	}
+
+	return sd, nil
+}
+
+func newAPIKey(usr userbus.User, scope []userbus.Role, roles ...userbus.Role) apikeybus.NewAPIKey {
+	return apikeybus.NewAPIKey{
+		UserID: usr.ID,
+		Name:   "service",
+		Roles:  roles,
+		Scope:  scope,
+	}
+}
+
+func cmpError(got any, exp any) string {
+	gotErr, _ := got.(error)
+	if !errors.Is(gotErr, exp.(error)) {
+		return fmt.Sprintf("got %v, exp %v", got, exp)
+	}
+
+	return ""
+}
+
+func create(busDomain dbtest.BusDomain, sd seedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "hashed",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				key, err := busDomain.APIKey.Create(ctx, newAPIKey(sd.user, sd.user.Roles, userbus.Roles.User))
+				if err != nil {
+					return err
+				}
+
+				if !strings.HasPrefix(key.Key, "ek_") || key.Hash == key.Key {
+					return fmt.Errorf("should get a prefixed key stored as a hash: %+v", key)
+				}
+
+				stored, err := busDomain.APIKey.QueryByID(ctx, key.ID)
+				if err != nil {
+					return err
+				}
+
+				return stored.Key == "" && stored.Hash == key.Hash
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "user-roles",
+			ExpResp: apikeybus.ErrRoles,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.APIKey.Create(ctx, newAPIKey(sd.user, []userbus.Role{userbus.Roles.Admin}, userbus.Roles.Admin))
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+		{
+			Name:    "caller-scope",
+			ExpResp: apikeybus.ErrRoles,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.APIKey.Create(ctx, newAPIKey(sd.admin, []userbus.Role{userbus.Roles.User}, userbus.Roles.Admin))
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+	}
+
+	return table
+}
+
+func authenticate(db *dbtest.Database, sd seedData) []unitest.Table {
+	busDomain := db.BusDomain
+
+	table := []unitest.Table{
+		{
+			Name:    "key",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				key, err := busDomain.APIKey.Create(ctx, newAPIKey(sd.admin, sd.admin.Roles, userbus.Roles.User))
+				if err != nil {
+					return err
+				}
+
+				got, err := busDomain.APIKey.Authenticate(ctx, key.Key)
+				if err != nil {
+					return err
+				}
+
+				return got.ID == key.ID && got.UserID == sd.admin.ID
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "demoted",
+			ExpResp: []userbus.Role{userbus.Roles.User},
+			ExcFunc: func(ctx context.Context) any {
+				admins, err := userbus.TestSeedUsers(ctx, db.Rand, 1, userbus.Roles.Admin, busDomain.User)
+				if err != nil {
+					return err
+				}
+
+				both := []userbus.Role{userbus.Roles.Admin, userbus.Roles.User}
+
+				usr, err := busDomain.User.Update(ctx, admins[0], userbus.UpdateUser{Roles: both})
+				if err != nil {
+					return err
+				}
+
+				key, err := busDomain.APIKey.Create(ctx, newAPIKey(usr, both, both...))
+				if err != nil {
+					return err
+				}
+
+				if _, err := busDomain.User.Update(ctx, usr, userbus.UpdateUser{Roles: []userbus.Role{userbus.Roles.User}}); err != nil {
+					return err
+				}
+
+				got, err := busDomain.APIKey.Authenticate(ctx, key.Key)
+				if err != nil {
+					return err
+				}
+
+				return got.Roles
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp, cmp.Comparer(userbus.Role.Equal))
+			},
+		},
+		{
+			Name:    "unknown",
+			ExpResp: apikeybus.ErrNotFound,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.APIKey.Authenticate(ctx, "ek_unknown")
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+		{
+			Name:    "expired",
+			ExpResp: apikeybus.ErrExpired,
+			ExcFunc: func(ctx context.Context) any {
+				nk := newAPIKey(sd.admin, sd.admin.Roles, userbus.Roles.Admin)
+				nk.DateExpires = db.Clock.Now().Add(time.Hour)
+
+				key, err := busDomain.APIKey.Create(ctx, nk)
+				if err != nil {
+					return err
+				}
+
+				db.Clock.Advance(2 * time.Hour)
+
+				_, err = busDomain.APIKey.Authenticate(ctx, key.Key)
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+		{
+			Name:    "disabled",
+			ExpResp: apikeybus.ErrUserDisabled,
+			ExcFunc: func(ctx context.Context) any {
+				key, err := busDomain.APIKey.Create(ctx, newAPIKey(sd.user, sd.user.Roles, userbus.Roles.User))
+				if err != nil {
+					return err
+				}
+
+				enabled := false
+				if _, err := busDomain.User.Update(ctx, sd.user, userbus.UpdateUser{Enabled: &enabled}); err != nil {
+					return err
+				}
+
+				_, err = busDomain.APIKey.Authenticate(ctx, key.Key)
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,195 @@
+// Package apikeybus provides business access to the API keys callers, like
+// other services, authenticate with instead of a token.
+package apikeybus
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/foundation/clock"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for API key operations.
+var (
+	ErrNotFound     = errors.New("api key not found")
+	ErrExpired      = errors.New("api key expired")
+	ErrUserDisabled = errors.New("user disabled")
+	ErrRoles        = errors.New("api key roles not held by the caller")
+)
+
+// keyPrefix marks the keys issued by the system, so a leaked key is easy to
+// spot and tell apart from a token.
+const keyPrefix = "ek_"
+
+// Storer interface declares the behaviour this package needs to persist and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, key APIKey) error
+	Delete(ctx context.Context, key APIKey) error
+	DeleteUser(ctx context.Context, userID uuid.UUID) error
+	QueryByID(ctx context.Context, keyID uuid.UUID) (APIKey, error)
+	QueryByHash(ctx context.Context, hash string) (APIKey, error)
+	QueryByUserID(ctx context.Context, userID uuid.UUID) ([]APIKey, error)
+}
+
+// Business manages the set of APIs for API key access.
+type Business struct {
+	log     *logger.Logger
+	clock   clock.Clock
+	userBus *userbus.Business
+	storer  Storer
+}
+
+// NewBusiness constructs an API key business API for use.
+func NewBusiness(log *logger.Logger, clock clock.Clock, userBus *userbus.Business, storer Storer) *Business {
+	return &Business{
+		log:     log,
+		clock:   clock,
+		userBus: userBus,
+		storer:  storer,
+	}
+}
+
+// Create adds a new API key for the user. The key can only be scoped to the
+// roles the user holds that are also in the scope of the caller, so a key
+// can't grant more than the token or key it was created with. The key is
+// only returned this once.
+func (b *Business) Create(ctx context.Context, nk NewAPIKey) (APIKey, error) {
+	usr, err := b.userBus.QueryByID(ctx, nk.UserID)
+	if err != nil {
+		return APIKey{}, fmt.Errorf("user.querybyid: %s: %w", nk.UserID, err)
+	}
+
+	if !usr.Enabled {
+		return APIKey{}, ErrUserDisabled
+	}
+
+	for _, role := range nk.Roles {
+		if !slices.ContainsFunc(usr.Roles, role.Equal) || !slices.ContainsFunc(nk.Scope, role.Equal) {
+			return APIKey{}, fmt.Errorf("role[%s]: %w", role, ErrRoles)
+		}
+	}
+
+	secret, err := generate()
+	if err != nil {
+		return APIKey{}, fmt.Errorf("generate: %w", err)
+	}
+
+	key := APIKey{
+		ID:          uuid.New(),
+		UserID:      nk.UserID,
+		Name:        nk.Name,
+		Key:         secret,
+		Hash:        hash(secret),
+		Roles:       nk.Roles,
+		DateExpires: nk.DateExpires,
+		DateCreated: b.clock.Now(),
+	}
+
+	if err := b.storer.Create(ctx, key); err != nil {
+		return APIKey{}, fmt.Errorf("create: %w", err)
+	}
+
+	return key, nil
+}
+
+// Delete removes the specified API key, so it can no longer be used.
+func (b *Business) Delete(ctx context.Context, key APIKey) error {
+	if err := b.storer.Delete(ctx, key); err != nil {
+		return fmt.Errorf("delete: keyID[%s]: %w", key.ID, err)
+	}
+
+	return nil
+}
+
+// DeleteUser removes every API key of the user, so none of them can be used
+// anymore.
+func (b *Business) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	if err := b.storer.DeleteUser(ctx, userID); err != nil {
+		return fmt.Errorf("deleteuser: userID[%s]: %w", userID, err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the API key by the specified ID.
+func (b *Business) QueryByID(ctx context.Context, keyID uuid.UUID) (APIKey, error) {
+	key, err := b.storer.QueryByID(ctx, keyID)
+	if err != nil {
+		return APIKey{}, fmt.Errorf("query: keyID[%s]: %w", keyID, err)
+	}
+
+	return key, nil
+}
+
+// QueryByUserID finds the API keys of the user.
+func (b *Business) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]APIKey, error) {
+	keys, err := b.storer.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return keys, nil
+}
+
+// Authenticate finds the API key a caller presented. The key has to not have
+// expired and the user it belongs to has to still be enabled. The roles of
+// the key returned are only the ones the user still holds, so a user that
+// loses a role loses it through their keys too.
+func (b *Business) Authenticate(ctx context.Context, secret string) (APIKey, error) {
+	key, err := b.storer.QueryByHash(ctx, hash(secret))
+	if err != nil {
+		return APIKey{}, fmt.Errorf("query: %w", err)
+	}
+
+	if !key.DateExpires.IsZero() && !b.clock.Now().Before(key.DateExpires) {
+		return APIKey{}, fmt.Errorf("authenticate: keyID[%s]: %w", key.ID, ErrExpired)
+	}
+
+	usr, err := b.userBus.QueryByID(ctx, key.UserID)
+	if err != nil {
+		return APIKey{}, fmt.Errorf("user.querybyid: %s: %w", key.UserID, err)
+	}
+
+	if !usr.Enabled {
+		return APIKey{}, fmt.Errorf("authenticate: keyID[%s]: %w", key.ID, ErrUserDisabled)
+	}
+
+	var roles []userbus.Role
+	for _, role := range key.Roles {
+		if slices.ContainsFunc(usr.Roles, role.Equal) {
+			roles = append(roles, role)
+		}
+	}
+	key.Roles = roles
+
+	return key, nil
+}
+
+// =============================================================================
+
+// generate returns a random key that is safe to use in a header.
+func generate() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return keyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hash returns the hash of the key that is stored in its place. The keys are
+// random enough that they don't need to be salted.
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
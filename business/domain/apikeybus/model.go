@@ -0,0 +1,33 @@
+package apikeybus
+
+import (
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/google/uuid"
+)
+
+// APIKey represents a long-lived key a caller authenticates with on behalf
+// of a user. Only the hash of the key is stored, so the key itself is only
+// known when it's created. The key grants the roles it was scoped to, which
+// are never more than the user's. A key without an expiry never expires.
+type APIKey struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Name        string
+	Key         string
+	Hash        string
+	Roles       []userbus.Role
+	DateExpires time.Time
+	DateCreated time.Time
+}
+
+// NewAPIKey is what we require from clients when adding an API key. The
+// scope is the roles of the caller creating the key.
+type NewAPIKey struct {
+	UserID      uuid.UUID
+	Name        string
+	Roles       []userbus.Role
+	Scope       []userbus.Role
+	DateExpires time.Time
+}
@@ -0,0 +1,161 @@
+// Package apikeydb contains API key related CRUD functionality.
+package apikeydb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ardanlabs/encore/business/domain/apikeybus"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for API key database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new API key into the database.
+func (s *Store) Create(ctx context.Context, key apikeybus.APIKey) error {
+	const q = `
+    INSERT INTO api_keys
+        (api_key_id, user_id, name, key_hash, roles, date_expires, date_created)
+    VALUES
+        (:api_key_id, :user_id, :name, :key_hash, :roles, :date_expires, :date_created)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBAPIKey(key)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes an API key from the database.
+func (s *Store) Delete(ctx context.Context, key apikeybus.APIKey) error {
+	data := struct {
+		ID string `db:"api_key_id"`
+	}{
+		ID: key.ID.String(),
+	}
+
+	const q = `
+    DELETE FROM
+        api_keys
+    WHERE
+        api_key_id = :api_key_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteUser removes every API key of the user from the database.
+func (s *Store) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+    DELETE FROM
+        api_keys
+    WHERE
+        user_id = :user_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID gets the specified API key from the database.
+func (s *Store) QueryByID(ctx context.Context, keyID uuid.UUID) (apikeybus.APIKey, error) {
+	data := struct {
+		ID string `db:"api_key_id"`
+	}{
+		ID: keyID.String(),
+	}
+
+	const q = `
+    SELECT
+        api_key_id, user_id, name, key_hash, roles, date_expires, date_created
+    FROM
+        api_keys
+    WHERE
+        api_key_id = :api_key_id`
+
+	return s.queryStruct(ctx, q, data)
+}
+
+// QueryByHash gets the API key with the hash from the database.
+func (s *Store) QueryByHash(ctx context.Context, hash string) (apikeybus.APIKey, error) {
+	data := struct {
+		Hash string `db:"key_hash"`
+	}{
+		Hash: hash,
+	}
+
+	const q = `
+    SELECT
+        api_key_id, user_id, name, key_hash, roles, date_expires, date_created
+    FROM
+        api_keys
+    WHERE
+        key_hash = :key_hash`
+
+	return s.queryStruct(ctx, q, data)
+}
+
+// QueryByUserID gets the API keys of the user from the database.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]apikeybus.APIKey, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+    SELECT
+        api_key_id, user_id, name, key_hash, roles, date_expires, date_created
+    FROM
+        api_keys
+    WHERE
+        user_id = :user_id
+    ORDER BY
+        date_created`
+
+	var dbKeys []apiKey
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbKeys); err != nil {
+		return nil, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusAPIKeys(dbKeys)
+}
+
+func (s *Store) queryStruct(ctx context.Context, q string, data any) (apikeybus.APIKey, error) {
+	var dbKey apiKey
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbKey); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return apikeybus.APIKey{}, fmt.Errorf("db: %w", apikeybus.ErrNotFound)
+		}
+		return apikeybus.APIKey{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusAPIKey(dbKey)
+}
@@ -0,0 +1,73 @@
+package apikeydb
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/apikeybus"
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/sdk/sqldb/dbarray"
+	"github.com/google/uuid"
+)
+
+type apiKey struct {
+	ID          uuid.UUID      `db:"api_key_id"`
+	UserID      uuid.UUID      `db:"user_id"`
+	Name        string         `db:"name"`
+	Hash        string         `db:"key_hash"`
+	Roles       dbarray.String `db:"roles"`
+	DateExpires sql.NullTime   `db:"date_expires"`
+	DateCreated time.Time      `db:"date_created"`
+}
+
+func toDBAPIKey(bus apikeybus.APIKey) apiKey {
+	return apiKey{
+		ID:     bus.ID,
+		UserID: bus.UserID,
+		Name:   bus.Name,
+		Hash:   bus.Hash,
+		Roles:  userbus.ParseRolesToString(bus.Roles),
+		DateExpires: sql.NullTime{
+			Time:  bus.DateExpires.UTC(),
+			Valid: !bus.DateExpires.IsZero(),
+		},
+		DateCreated: bus.DateCreated.UTC(),
+	}
+}
+
+func toBusAPIKey(db apiKey) (apikeybus.APIKey, error) {
+	roles, err := userbus.ParseRoles(db.Roles)
+	if err != nil {
+		return apikeybus.APIKey{}, fmt.Errorf("parse roles: %w", err)
+	}
+
+	bus := apikeybus.APIKey{
+		ID:          db.ID,
+		UserID:      db.UserID,
+		Name:        db.Name,
+		Hash:        db.Hash,
+		Roles:       roles,
+		DateCreated: db.DateCreated.In(time.Local),
+	}
+
+	if db.DateExpires.Valid {
+		bus.DateExpires = db.DateExpires.Time.In(time.Local)
+	}
+
+	return bus, nil
+}
+
+func toBusAPIKeys(dbs []apiKey) ([]apikeybus.APIKey, error) {
+	bus := make([]apikeybus.APIKey, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusAPIKey(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
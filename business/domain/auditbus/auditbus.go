@@ -0,0 +1,51 @@
+// Package auditbus provides business access to the audit log, a record of
+// who did what to the system.
+package auditbus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Storer interface declares the behaviour this package needs to persist and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, adt Audit) error
+}
+
+// Business manages the set of APIs for audit access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs an audit business API for use.
+func NewBusiness(log *logger.Logger, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// Create adds a new entry to the audit log.
+func (b *Business) Create(ctx context.Context, na NewAudit) (Audit, error) {
+	adt := Audit{
+		ID:          uuid.New(),
+		ActorID:     na.ActorID,
+		Action:      na.Action,
+		Endpoint:    na.Endpoint,
+		EntityID:    na.EntityID,
+		Payload:     na.Payload,
+		DateCreated: time.Now(),
+	}
+
+	if err := b.storer.Create(ctx, adt); err != nil {
+		return Audit{}, fmt.Errorf("create: %w", err)
+	}
+
+	return adt, nil
+}
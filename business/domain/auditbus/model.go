@@ -0,0 +1,27 @@
+package auditbus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit represents an action taken by a user against the system.
+type Audit struct {
+	ID          uuid.UUID
+	ActorID     uuid.UUID
+	Action      string
+	Endpoint    string
+	EntityID    string
+	Payload     []byte
+	DateCreated time.Time
+}
+
+// NewAudit is what we require to record an action.
+type NewAudit struct {
+	ActorID  uuid.UUID
+	Action   string
+	Endpoint string
+	EntityID string
+	Payload  []byte
+}
@@ -0,0 +1,41 @@
+// Package auditdb contains audit related CRUD functionality.
+package auditdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ardanlabs/encore/business/domain/auditbus"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for audit database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new audit into the database.
+func (s *Store) Create(ctx context.Context, adt auditbus.Audit) error {
+	const q = `
+    INSERT INTO audits
+        (audit_id, actor_id, action, endpoint, entity_id, payload, date_created)
+    VALUES
+        (:audit_id, :actor_id, :action, :endpoint, :entity_id, :payload, :date_created)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBAudit(adt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package auditdb
+
+import (
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/auditbus"
+	"github.com/google/uuid"
+)
+
+type audit struct {
+	ID          uuid.UUID `db:"audit_id"`
+	ActorID     uuid.UUID `db:"actor_id"`
+	Action      string    `db:"action"`
+	Endpoint    string    `db:"endpoint"`
+	EntityID    string    `db:"entity_id"`
+	Payload     string    `db:"payload"`
+	DateCreated time.Time `db:"date_created"`
+}
+
+func toDBAudit(bus auditbus.Audit) audit {
+	return audit{
+		ID:          bus.ID,
+		ActorID:     bus.ActorID,
+		Action:      bus.Action,
+		Endpoint:    bus.Endpoint,
+		EntityID:    bus.EntityID,
+		Payload:     string(bus.Payload),
+		DateCreated: bus.DateCreated.UTC(),
+	}
+}
@@ -0,0 +1,321 @@
+package deadletterbus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"encore.dev/et"
+	"github.com/ardanlabs/encore/business/domain/deadletterbus"
+	"github.com/ardanlabs/encore/business/sdk/dbtest"
+	"github.com/ardanlabs/encore/business/sdk/page"
+	"github.com/ardanlabs/encore/business/sdk/unitest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_DeadLetter(t *testing.T) {
+	t.Parallel()
+
+	edb, err := et.NewTestDatabase(context.Background(), "app")
+	if err != nil {
+		t.Fatalf("Creating new database: %s", err)
+	}
+
+	db := dbtest.NewDatabase(t, edb)
+
+	var replayed []string
+	db.BusDomain.DeadLetter.RegisterReplay("test", func(ctx context.Context, payload []byte) error {
+		replayed = append(replayed, string(payload))
+		return nil
+	})
+
+	var failed bool
+	db.BusDomain.DeadLetter.RegisterReplay("failing", func(ctx context.Context, payload []byte) error {
+		if !failed {
+			failed = true
+			return errors.New("publish failed")
+		}
+		return nil
+	})
+
+	dls, err := insertSeedData(db.BusDomain)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, query(db.BusDomain, dls), "query")
+	unitest.Run(t, replay(db.BusDomain, dls, &replayed), "replay")
+	unitest.Run(t, handle(db), "handle")
+}
+
+// =============================================================================
+
+func insertSeedData(busDomain dbtest.BusDomain) ([]deadletterbus.DeadLetter, error) {
+	ctx, cancel := dbtest.Context()
+	defer cancel()
+
+	topics := []string{"test", "unknown", "failing"}
+
+	dls := make([]deadletterbus.DeadLetter, len(topics))
+	for i, topic := range topics {
+		ndl := deadletterbus.NewDeadLetter{
+			Topic:        topic,
+			Subscription: "test-sub",
+			MessageID:    fmt.Sprintf("msg%d", i),
+			Payload:      []byte(fmt.Sprintf(`{"idx":%d}`, i)),
+			Error:        "poison message",
+			Attempts:     10,
+		}
+
+		dl, err := busDomain.DeadLetter.Create(ctx, ndl)
+		if err != nil {
+			return nil, fmt.Errorf("seeding dead letter: idx: %d : %w", i, err)
+		}
+
+		dls[i] = dl
+	}
+
+	return dls, nil
+}
+
+// =============================================================================
+
+func query(busDomain dbtest.BusDomain, dls []deadletterbus.DeadLetter) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "count",
+			ExpResp: len(dls),
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.DeadLetter.Count(ctx)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "all",
+			ExpResp: len(dls),
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.DeadLetter.Query(ctx, page.MustParse("1", "10"))
+				if err != nil {
+					return err
+				}
+
+				return len(resp)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "byid",
+			ExpResp: dls[0].Payload,
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.DeadLetter.QueryByID(ctx, dls[0].ID)
+				if err != nil {
+					return err
+				}
+
+				return resp.Payload
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func replay(busDomain dbtest.BusDomain, dls []deadletterbus.DeadLetter, replayed *[]string) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "basic",
+			ExpResp: []string{string(dls[0].Payload)},
+			ExcFunc: func(ctx context.Context) any {
+				dl, err := busDomain.DeadLetter.QueryByID(ctx, dls[0].ID)
+				if err != nil {
+					return err
+				}
+
+				dl, err = busDomain.DeadLetter.Replay(ctx, dl)
+				if err != nil {
+					return err
+				}
+
+				if dl.DateReplayed.IsZero() {
+					return errors.New("should record when the dead letter was replayed")
+				}
+
+				return *replayed
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "already-replayed",
+			ExpResp: deadletterbus.ErrAlreadyReplayed,
+			ExcFunc: func(ctx context.Context) any {
+				dl, err := busDomain.DeadLetter.QueryByID(ctx, dls[0].ID)
+				if err != nil {
+					return err
+				}
+
+				_, err = busDomain.DeadLetter.Replay(ctx, dl)
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotErr, _ := got.(error)
+				if !errors.Is(gotErr, exp.(error)) {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+
+				return ""
+			},
+		},
+		{
+			Name:    "release",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				if _, err := busDomain.DeadLetter.Replay(ctx, dls[2]); err == nil {
+					return errors.New("should fail to publish the dead letter")
+				}
+
+				dl, err := busDomain.DeadLetter.QueryByID(ctx, dls[2].ID)
+				if err != nil {
+					return err
+				}
+
+				if !dl.DateReplayed.IsZero() {
+					return errors.New("should release the dead letter when the publish fails")
+				}
+
+				dl, err = busDomain.DeadLetter.Replay(ctx, dl)
+				if err != nil {
+					return err
+				}
+
+				return !dl.DateReplayed.IsZero()
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "no-replay",
+			ExpResp: deadletterbus.ErrNoReplay,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.DeadLetter.Replay(ctx, dls[1])
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotErr, _ := got.(error)
+				if !errors.Is(gotErr, exp.(error)) {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+
+				return ""
+			},
+		},
+	}
+
+	return table
+}
+
+func handle(db *dbtest.Database) []unitest.Table {
+	busDomain := db.BusDomain
+
+	const maxAttempts = 3
+
+	errPoison := errors.New("poison message")
+
+	fail := func(ctx context.Context, msg map[string]int) error {
+		return errPoison
+	}
+
+	table := []unitest.Table{
+		{
+			Name:    "retry",
+			ExpResp: errPoison,
+			ExcFunc: func(ctx context.Context) any {
+				dlv := deadletterbus.Delivery{
+					Topic:        "test",
+					Subscription: "test-sub",
+					MessageID:    "retry",
+					Attempt:      maxAttempts - 1,
+				}
+
+				return deadletterbus.Handle(ctx, busDomain.DeadLetter, maxAttempts, dlv, map[string]int{"idx": 1}, fail)
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotErr, _ := got.(error)
+				if !errors.Is(gotErr, exp.(error)) {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+
+				return ""
+			},
+		},
+		{
+			Name: "dead-letter",
+			ExpResp: deadletterbus.DeadLetter{
+				Topic:        "test",
+				Subscription: "test-sub",
+				MessageID:    "dead",
+				Payload:      []byte(`{"idx":2}`),
+				Error:        errPoison.Error(),
+				Attempts:     maxAttempts,
+			},
+			ExcFunc: func(ctx context.Context) any {
+				dlv := deadletterbus.Delivery{
+					Topic:        "test",
+					Subscription: "test-sub",
+					MessageID:    "dead",
+					Attempt:      maxAttempts,
+				}
+
+				// The dead letter has to be the newest one to be queried.
+				db.Clock.Advance(time.Second)
+
+				if err := deadletterbus.Handle(ctx, busDomain.DeadLetter, maxAttempts, dlv, map[string]int{"idx": 2}, fail); err != nil {
+					return err
+				}
+
+				resp, err := busDomain.DeadLetter.Query(ctx, page.MustParse("1", "1"))
+				if err != nil {
+					return err
+				}
+
+				if len(resp) != 1 {
+					return fmt.Errorf("expected a dead letter, got %d", len(resp))
+				}
+
+				return resp[0]
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(deadletterbus.DeadLetter)
+				if !exists {
+					return fmt.Sprintf("error occurred: %v", got)
+				}
+
+				expResp := exp.(deadletterbus.DeadLetter)
+				expResp.ID = gotResp.ID
+				expResp.DateCreated = gotResp.DateCreated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,196 @@
+// Package deadletterbus provides business access to the dead letter domain.
+package deadletterbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/business/sdk/page"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/foundation/clock"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for dead letter operations.
+var (
+	ErrNotFound        = errors.New("dead letter not found")
+	ErrAlreadyReplayed = errors.New("dead letter already replayed")
+	ErrNoReplay        = errors.New("topic can't be replayed")
+)
+
+// Storer interface declares the behaviour this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, dl DeadLetter) error
+	Update(ctx context.Context, dl DeadLetter) error
+	Claim(ctx context.Context, dl DeadLetter) (DeadLetter, error)
+	Query(ctx context.Context, page page.Page) ([]DeadLetter, error)
+	Count(ctx context.Context) (int, error)
+	QueryByID(ctx context.Context, deadLetterID uuid.UUID) (DeadLetter, error)
+}
+
+// ReplayFunc represents a function that publishes a dead letter's payload
+// back to its topic.
+type ReplayFunc func(ctx context.Context, payload []byte) error
+
+// Business manages the set of APIs for dead letter access.
+type Business struct {
+	log     *logger.Logger
+	clock   clock.Clock
+	storer  Storer
+	replays map[string]ReplayFunc
+}
+
+// NewBusiness constructs a dead letter business API for use.
+func NewBusiness(log *logger.Logger, clk clock.Clock, storer Storer) *Business {
+	return &Business{
+		log:     log,
+		clock:   clk,
+		storer:  storer,
+		replays: make(map[string]ReplayFunc),
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:     b.log,
+		clock:   b.clock,
+		storer:  storer,
+		replays: b.replays,
+	}
+
+	return &bus, nil
+}
+
+// RegisterReplay adds the function used to replay dead letters for the
+// specified topic.
+func (b *Business) RegisterReplay(topic string, fn ReplayFunc) {
+	b.replays[topic] = fn
+}
+
+// Create records a new dead letter.
+func (b *Business) Create(ctx context.Context, ndl NewDeadLetter) (DeadLetter, error) {
+	dl := DeadLetter{
+		ID:           uuid.New(),
+		Topic:        ndl.Topic,
+		Subscription: ndl.Subscription,
+		MessageID:    ndl.MessageID,
+		Payload:      ndl.Payload,
+		Error:        ndl.Error,
+		Attempts:     ndl.Attempts,
+		DateCreated:  b.clock.Now(),
+	}
+
+	if err := b.storer.Create(ctx, dl); err != nil {
+		return DeadLetter{}, fmt.Errorf("create: %w", err)
+	}
+
+	return dl, nil
+}
+
+// Query retrieves a list of dead letters, newest first.
+func (b *Business) Query(ctx context.Context, page page.Page) ([]DeadLetter, error) {
+	dls, err := b.storer.Query(ctx, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return dls, nil
+}
+
+// Count returns the total number of dead letters.
+func (b *Business) Count(ctx context.Context) (int, error) {
+	return b.storer.Count(ctx)
+}
+
+// QueryByID finds the dead letter by the specified ID.
+func (b *Business) QueryByID(ctx context.Context, deadLetterID uuid.UUID) (DeadLetter, error) {
+	dl, err := b.storer.QueryByID(ctx, deadLetterID)
+	if err != nil {
+		return DeadLetter{}, fmt.Errorf("query: deadLetterID[%s]: %w", deadLetterID, err)
+	}
+
+	return dl, nil
+}
+
+// Replay publishes the dead letter's payload back to its topic so the
+// subscribers can try again. A dead letter can only be replayed once, so it's
+// claimed before it's published and concurrent replays don't both publish.
+// The claim is released if the publish fails so it can be replayed again.
+func (b *Business) Replay(ctx context.Context, dl DeadLetter) (DeadLetter, error) {
+	fn, exists := b.replays[dl.Topic]
+	if !exists {
+		return DeadLetter{}, fmt.Errorf("topic[%s]: %w", dl.Topic, ErrNoReplay)
+	}
+
+	dl.DateReplayed = b.clock.Now()
+
+	claimed, err := b.storer.Claim(ctx, dl)
+	if err != nil {
+		return DeadLetter{}, fmt.Errorf("claim: deadLetterID[%s]: %w", dl.ID, err)
+	}
+
+	if err := fn(ctx, claimed.Payload); err != nil {
+		claimed.DateReplayed = time.Time{}
+
+		if errUpd := b.storer.Update(ctx, claimed); errUpd != nil {
+			return DeadLetter{}, fmt.Errorf("release: deadLetterID[%s]: %w: %w", dl.ID, errUpd, err)
+		}
+
+		return DeadLetter{}, fmt.Errorf("replay: deadLetterID[%s]: %w", dl.ID, err)
+	}
+
+	return claimed, nil
+}
+
+// =============================================================================
+
+// Handle runs the subscriber function for a message. Once the message has
+// been delivered the specified number of times and still fails, it is
+// recorded as a dead letter and acknowledged so it is no longer retried.
+func Handle[T any](ctx context.Context, bus *Business, maxAttempts int, dlv Delivery, msg T, fn func(ctx context.Context, msg T) error) error {
+	err := fn(ctx, msg)
+	if err == nil {
+		return nil
+	}
+
+	if dlv.Attempt < maxAttempts {
+		return err
+	}
+
+	payload, errMarshal := json.Marshal(msg)
+	if errMarshal != nil {
+		return fmt.Errorf("marshal: %w: %w", errMarshal, err)
+	}
+
+	ndl := NewDeadLetter{
+		Topic:        dlv.Topic,
+		Subscription: dlv.Subscription,
+		MessageID:    dlv.MessageID,
+		Payload:      payload,
+		Error:        err.Error(),
+		Attempts:     dlv.Attempt,
+	}
+
+	// If the dead letter can't be recorded the original error is returned
+	// so the message is retried instead of being lost.
+	if _, errDL := bus.Create(ctx, ndl); errDL != nil {
+		return fmt.Errorf("dead letter: %w: %w", errDL, err)
+	}
+
+	bus.log.Error(ctx, "dead letter", "topic", dlv.Topic, "subscription", dlv.Subscription, "messageID", dlv.MessageID, "attempts", dlv.Attempt, "ERROR", err)
+
+	return nil
+}
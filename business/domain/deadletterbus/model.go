@@ -0,0 +1,40 @@
+package deadletterbus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeadLetter represents a message a subscriber could not process.
+type DeadLetter struct {
+	ID           uuid.UUID
+	Topic        string
+	Subscription string
+	MessageID    string
+	Payload      []byte
+	Error        string
+	Attempts     int
+	DateCreated  time.Time
+	DateReplayed time.Time
+}
+
+// NewDeadLetter is what we require to record a dead letter.
+type NewDeadLetter struct {
+	Topic        string
+	Subscription string
+	MessageID    string
+	Payload      []byte
+	Error        string
+	Attempts     int
+}
+
+// Delivery describes a single delivery of a message to a subscriber. It's
+// provided by the caller so the business layer doesn't depend on how the
+// messages are delivered.
+type Delivery struct {
+	Topic        string
+	Subscription string
+	MessageID    string
+	Attempt      int
+}
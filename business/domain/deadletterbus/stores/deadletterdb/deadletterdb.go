@@ -0,0 +1,173 @@
+// Package deadletterdb contains dead letter related CRUD functionality.
+package deadletterdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ardanlabs/encore/business/domain/deadletterbus"
+	"github.com/ardanlabs/encore/business/sdk/page"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for dead letter database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (deadletterbus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new dead letter into the database.
+func (s *Store) Create(ctx context.Context, dl deadletterbus.DeadLetter) error {
+	const q = `
+    INSERT INTO dead_letters
+        (dead_letter_id, topic, subscription, message_id, payload, error, attempts, date_created, date_replayed)
+    VALUES
+        (:dead_letter_id, :topic, :subscription, :message_id, :payload, :error, :attempts, :date_created, :date_replayed)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBDeadLetter(dl)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces a dead letter document in the database.
+func (s *Store) Update(ctx context.Context, dl deadletterbus.DeadLetter) error {
+	const q = `
+    UPDATE
+        dead_letters
+    SET
+        "date_replayed" = :date_replayed
+    WHERE
+        dead_letter_id = :dead_letter_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBDeadLetter(dl)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Claim marks a dead letter as replayed as long as it hasn't been already,
+// and returns it. Only one caller can claim a dead letter.
+func (s *Store) Claim(ctx context.Context, dl deadletterbus.DeadLetter) (deadletterbus.DeadLetter, error) {
+	const q = `
+    UPDATE
+        dead_letters
+    SET
+        "date_replayed" = :date_replayed
+    WHERE
+        dead_letter_id = :dead_letter_id AND
+        date_replayed IS NULL
+    RETURNING
+        dead_letter_id, topic, subscription, message_id, payload, error, attempts, date_created, date_replayed`
+
+	var dbDL deadLetter
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, toDBDeadLetter(dl), &dbDL); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return deadletterbus.DeadLetter{}, fmt.Errorf("db: %w", deadletterbus.ErrAlreadyReplayed)
+		}
+		return deadletterbus.DeadLetter{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusDeadLetter(dbDL), nil
+}
+
+// Query retrieves a list of dead letters from the database, newest first.
+func (s *Store) Query(ctx context.Context, page page.Page) ([]deadletterbus.DeadLetter, error) {
+	data := map[string]any{
+		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+    SELECT
+        dead_letter_id, topic, subscription, message_id, payload, error, attempts, date_created, date_replayed
+    FROM
+        dead_letters
+    ORDER BY
+        date_created DESC
+    OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY`
+
+	var dbDLs []deadLetter
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbDLs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusDeadLetters(dbDLs), nil
+}
+
+// Count returns the total number of dead letters in the DB.
+func (s *Store) Count(ctx context.Context) (int, error) {
+	data := map[string]any{}
+
+	const q = `
+    SELECT
+        count(1)
+    FROM
+        dead_letters`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByID gets the specified dead letter from the database.
+func (s *Store) QueryByID(ctx context.Context, deadLetterID uuid.UUID) (deadletterbus.DeadLetter, error) {
+	data := struct {
+		ID string `db:"dead_letter_id"`
+	}{
+		ID: deadLetterID.String(),
+	}
+
+	const q = `
+    SELECT
+        dead_letter_id, topic, subscription, message_id, payload, error, attempts, date_created, date_replayed
+    FROM
+        dead_letters
+    WHERE
+        dead_letter_id = :dead_letter_id`
+
+	var dbDL deadLetter
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbDL); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return deadletterbus.DeadLetter{}, fmt.Errorf("db: %w", deadletterbus.ErrNotFound)
+		}
+		return deadletterbus.DeadLetter{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusDeadLetter(dbDL), nil
+}
@@ -0,0 +1,66 @@
+package deadletterdb
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/deadletterbus"
+	"github.com/google/uuid"
+)
+
+type deadLetter struct {
+	ID           uuid.UUID    `db:"dead_letter_id"`
+	Topic        string       `db:"topic"`
+	Subscription string       `db:"subscription"`
+	MessageID    string       `db:"message_id"`
+	Payload      string       `db:"payload"`
+	Error        string       `db:"error"`
+	Attempts     int          `db:"attempts"`
+	DateCreated  time.Time    `db:"date_created"`
+	DateReplayed sql.NullTime `db:"date_replayed"`
+}
+
+func toDBDeadLetter(bus deadletterbus.DeadLetter) deadLetter {
+	return deadLetter{
+		ID:           bus.ID,
+		Topic:        bus.Topic,
+		Subscription: bus.Subscription,
+		MessageID:    bus.MessageID,
+		Payload:      string(bus.Payload),
+		Error:        bus.Error,
+		Attempts:     bus.Attempts,
+		DateCreated:  bus.DateCreated.UTC(),
+		DateReplayed: sql.NullTime{
+			Time:  bus.DateReplayed.UTC(),
+			Valid: !bus.DateReplayed.IsZero(),
+		},
+	}
+}
+
+func toBusDeadLetter(db deadLetter) deadletterbus.DeadLetter {
+	var replayed time.Time
+	if db.DateReplayed.Valid {
+		replayed = db.DateReplayed.Time.In(time.Local)
+	}
+
+	return deadletterbus.DeadLetter{
+		ID:           db.ID,
+		Topic:        db.Topic,
+		Subscription: db.Subscription,
+		MessageID:    db.MessageID,
+		Payload:      []byte(db.Payload),
+		Error:        db.Error,
+		Attempts:     db.Attempts,
+		DateCreated:  db.DateCreated.In(time.Local),
+		DateReplayed: replayed,
+	}
+}
+
+func toBusDeadLetters(dbs []deadLetter) []deadletterbus.DeadLetter {
+	bus := make([]deadletterbus.DeadLetter, len(dbs))
+	for i, db := range dbs {
+		bus[i] = toBusDeadLetter(db)
+	}
+
+	return bus
+}
@@ -1 +1,54 @@
 package homebus
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ardanlabs/encore/business/sdk/delegate"
+	"github.com/google/uuid"
+)
+
+// DomainName represents the name of this domain.
+const DomainName = "home"
+
+// Set of delegate actions.
+const (
+	ActionCreated = "created"
+	ActionUpdated = "updated"
+	ActionDeleted = "deleted"
+)
+
+// ActionParms represents the parameters for the home actions.
+type ActionParms struct {
+	HomeID uuid.UUID
+	UserID uuid.UUID
+}
+
+// String returns a string representation of the action parameters.
+func (ap *ActionParms) String() string {
+	return fmt.Sprintf("&EventParams{HomeID:%v, UserID:%v}", ap.HomeID, ap.UserID)
+}
+
+// Marshal returns the event parameters encoded as JSON.
+func (ap *ActionParms) Marshal() ([]byte, error) {
+	return json.Marshal(ap)
+}
+
+// ActionData constructs the data for the specified action on the home.
+func ActionData(action string, hme Home) delegate.Data {
+	params := ActionParms{
+		HomeID: hme.ID,
+		UserID: hme.UserID,
+	}
+
+	rawParams, err := params.Marshal()
+	if err != nil {
+		panic(err)
+	}
+
+	return delegate.Data{
+		Domain:    DomainName,
+		Action:    action,
+		RawParams: rawParams,
+	}
+}
@@ -3,6 +3,7 @@ package homebus
 import (
 	"time"
 
+	"github.com/ardanlabs/encore/business/sdk/where"
 	"github.com/google/uuid"
 )
 
@@ -11,7 +12,8 @@ import (
 type QueryFilter struct {
 	ID               *uuid.UUID
 	UserID           *uuid.UUID
-	Type             *Type
+	Address          *string
+	Type             []where.Cond[Type]
 	StartCreatedDate *time.Time
 	EndCreatedDate   *time.Time
 }
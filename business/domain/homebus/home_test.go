@@ -2,6 +2,7 @@ package homebus_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"testing"
@@ -9,7 +10,6 @@ import (
 
 	"encore.dev/et"
 	"github.com/ardanlabs/encore/business/domain/homebus"
-	"github.com/ardanlabs/encore/business/domain/userbus"
 	"github.com/ardanlabs/encore/business/sdk/dbtest"
 	"github.com/ardanlabs/encore/business/sdk/page"
 	"github.com/ardanlabs/encore/business/sdk/unitest"
@@ -26,10 +26,7 @@ func Test_Home(t *testing.T) {
 
 	db := dbtest.NewDatabase(t, edb)
 
-	sd, err := insertSeedData(db.BusDomain)
-	if err != nil {
-		t.Fatalf("Seeding error: %s", err)
-	}
+	sd := db.LoadFixtures(t, "fixtures/seed.yaml")
 
 	// -------------------------------------------------------------------------
 
@@ -37,75 +34,21 @@ func Test_Home(t *testing.T) {
 	unitest.Run(t, create(db.BusDomain, sd), "create")
 	unitest.Run(t, update(db.BusDomain, sd), "update")
 	unitest.Run(t, delete(db.BusDomain, sd), "delete")
-}
-
-// =============================================================================
-
-func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
-	ctx := context.Background()
-
-	usrs, err := userbus.TestSeedUsers(ctx, 1, userbus.Roles.User, busDomain.User)
-	if err != nil {
-		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
-	}
-
-	hmes, err := homebus.TestGenerateSeedHomes(ctx, 2, busDomain.Home, usrs[0].ID)
-	if err != nil {
-		return unitest.SeedData{}, fmt.Errorf("seeding homes : %w", err)
-	}
-
-	tu1 := unitest.User{
-		User:  usrs[0],
-		Homes: hmes,
-	}
-
-	// -------------------------------------------------------------------------
 
-	usrs, err = userbus.TestSeedUsers(ctx, 1, userbus.Roles.User, busDomain.User)
-	if err != nil {
-		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
-	}
-
-	tu2 := unitest.User{
-		User: usrs[0],
-	}
-
-	// -------------------------------------------------------------------------
-
-	usrs, err = userbus.TestSeedUsers(ctx, 1, userbus.Roles.Admin, busDomain.User)
-	if err != nil {
-		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
-	}
-
-	hmes, err = homebus.TestGenerateSeedHomes(ctx, 2, busDomain.Home, usrs[0].ID)
-	if err != nil {
-		return unitest.SeedData{}, fmt.Errorf("seeding homes : %w", err)
-	}
-
-	tu3 := unitest.User{
-		User:  usrs[0],
-		Homes: hmes,
-	}
-
-	// -------------------------------------------------------------------------
-
-	usrs, err = userbus.TestSeedUsers(ctx, 1, userbus.Roles.Admin, busDomain.User)
-	if err != nil {
-		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
-	}
-
-	tu4 := unitest.User{
-		User: usrs[0],
-	}
-
-	// -------------------------------------------------------------------------
-
-	sd := unitest.SeedData{
-		Users:  []unitest.User{tu1, tu2},
-		Admins: []unitest.User{tu3, tu4},
-	}
-
-	return sd, nil
+	dbtest.CheckContext(t, "context", map[string]func(ctx context.Context) error{
+		"query": func(ctx context.Context) error {
+			_, err := db.BusDomain.Home.Query(ctx, homebus.QueryFilter{}, homebus.DefaultOrderBy, page.MustParse("1", "10"))
+			return err
+		},
+		"count": func(ctx context.Context) error {
+			_, err := db.BusDomain.Home.Count(ctx, homebus.QueryFilter{})
+			return err
+		},
+		"querybyid": func(ctx context.Context) error {
+			_, err := db.BusDomain.Home.QueryByID(ctx, sd.Users[0].Homes[0].ID)
+			return err
+		},
+	})
 }
 
 // =============================================================================
@@ -296,6 +239,25 @@ func update(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 				return cmp.Diff(gotResp, expResp)
 			},
 		},
+		{
+			Name:    "modified",
+			ExpResp: homebus.ErrModified,
+			ExcFunc: func(ctx context.Context) any {
+
+				// The seeded home was changed by the basic update, so this
+				// copy is out of date.
+				_, err := busDomain.Home.Update(ctx, sd.Users[0].Homes[0], homebus.UpdateHome{Type: &homebus.Types.Condo})
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotErr, _ := got.(error)
+				if !errors.Is(gotErr, exp.(error)) {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+
+				return ""
+			},
+		},
 	}
 
 	return table
@@ -331,6 +293,21 @@ func delete(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 				return cmp.Diff(got, exp)
 			},
 		},
+		{
+			Name:    "modified",
+			ExpResp: homebus.ErrModified,
+			ExcFunc: func(ctx context.Context) any {
+				return busDomain.Home.Delete(ctx, sd.Users[0].Homes[0])
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotErr, _ := got.(error)
+				if !errors.Is(gotErr, exp.(error)) {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+
+				return ""
+			},
+		},
 	}
 
 	return table
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"iter"
 	"time"
 
 	"github.com/ardanlabs/encore/business/domain/userbus"
@@ -12,14 +13,18 @@ import (
 	"github.com/ardanlabs/encore/business/sdk/order"
 	"github.com/ardanlabs/encore/business/sdk/page"
 	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/foundation/clock"
 	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/ardanlabs/encore/foundation/otel"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Set of error variables for CRUD operations.
 var (
 	ErrNotFound     = errors.New("home not found")
 	ErrUserDisabled = errors.New("user disabled")
+	ErrModified     = errors.New("home has been modified")
 )
 
 // Storer interface declares the behaviour this package needs to persist and
@@ -27,9 +32,11 @@ var (
 type Storer interface {
 	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
 	Create(ctx context.Context, hme Home) error
-	Update(ctx context.Context, hme Home) error
+	Update(ctx context.Context, hme Home, lastUpdated time.Time) error
 	Delete(ctx context.Context, hme Home) error
 	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Home, error)
+	QueryByKeyset(ctx context.Context, filter QueryFilter, keyset page.Keyset) ([]Home, error)
+	QueryStream(ctx context.Context, filter QueryFilter, orderBy order.By) iter.Seq2[Home, error]
 	Count(ctx context.Context, filter QueryFilter) (int, error)
 	QueryByID(ctx context.Context, homeID uuid.UUID) (Home, error)
 	QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Home, error)
@@ -38,15 +45,17 @@ type Storer interface {
 // Business manages the set of APIs for home api access.
 type Business struct {
 	log      *logger.Logger
+	clock    clock.Clock
 	userBus  *userbus.Business
 	delegate *delegate.Delegate
 	storer   Storer
 }
 
 // NewBusiness constructs a home business API for use.
-func NewBusiness(log *logger.Logger, userBus *userbus.Business, delegate *delegate.Delegate, storer Storer) *Business {
+func NewBusiness(log *logger.Logger, clock clock.Clock, userBus *userbus.Business, delegate *delegate.Delegate, storer Storer) *Business {
 	return &Business{
 		log:      log,
+		clock:    clock,
 		userBus:  userBus,
 		delegate: delegate,
 		storer:   storer,
@@ -68,6 +77,7 @@ func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
 
 	bus := Business{
 		log:      b.log,
+		clock:    b.clock,
 		userBus:  userBus,
 		delegate: b.delegate,
 		storer:   storer,
@@ -78,6 +88,9 @@ func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
 
 // Create adds a new home to the system.
 func (b *Business) Create(ctx context.Context, nh NewHome) (Home, error) {
+	ctx, span := otel.AddSpan(ctx, "business.homebus.create")
+	defer span.End()
+
 	usr, err := b.userBus.QueryByID(ctx, nh.UserID)
 	if err != nil {
 		return Home{}, fmt.Errorf("user.querybyid: %s: %w", nh.UserID, err)
@@ -87,7 +100,7 @@ func (b *Business) Create(ctx context.Context, nh NewHome) (Home, error) {
 		return Home{}, ErrUserDisabled
 	}
 
-	now := time.Now()
+	now := b.clock.Now().Truncate(time.Microsecond)
 
 	hme := Home{
 		ID:   uuid.New(),
@@ -109,11 +122,20 @@ func (b *Business) Create(ctx context.Context, nh NewHome) (Home, error) {
 		return Home{}, fmt.Errorf("create: %w", err)
 	}
 
+	// Other domains may need to know when a home is created. This
+	// represents a delegate call to other domains.
+	if err := b.delegate.Call(ctx, ActionData(ActionCreated, hme)); err != nil {
+		return Home{}, fmt.Errorf("failed to execute `%s` action: %w", ActionCreated, err)
+	}
+
 	return hme, nil
 }
 
 // Update modifies information about a home.
 func (b *Business) Update(ctx context.Context, hme Home, uh UpdateHome) (Home, error) {
+	ctx, span := otel.AddSpan(ctx, "business.homebus.update", attribute.String("home_id", hme.ID.String()))
+	defer span.End()
+
 	if uh.Type != nil {
 		hme.Type = *uh.Type
 	}
@@ -144,26 +166,45 @@ func (b *Business) Update(ctx context.Context, hme Home, uh UpdateHome) (Home, e
 		}
 	}
 
-	hme.DateUpdated = time.Now()
+	lastUpdated := hme.DateUpdated
+	hme.DateUpdated = b.clock.Now().Truncate(time.Microsecond)
 
-	if err := b.storer.Update(ctx, hme); err != nil {
+	if err := b.storer.Update(ctx, hme, lastUpdated); err != nil {
 		return Home{}, fmt.Errorf("update: %w", err)
 	}
 
+	// Other domains may need to know when a home is updated. This
+	// represents a delegate call to other domains.
+	if err := b.delegate.Call(ctx, ActionData(ActionUpdated, hme)); err != nil {
+		return Home{}, fmt.Errorf("failed to execute `%s` action: %w", ActionUpdated, err)
+	}
+
 	return hme, nil
 }
 
 // Delete removes the specified home.
 func (b *Business) Delete(ctx context.Context, hme Home) error {
+	ctx, span := otel.AddSpan(ctx, "business.homebus.delete", attribute.String("home_id", hme.ID.String()))
+	defer span.End()
+
 	if err := b.storer.Delete(ctx, hme); err != nil {
 		return fmt.Errorf("delete: %w", err)
 	}
 
+	// Other domains may need to know when a home is deleted. This
+	// represents a delegate call to other domains.
+	if err := b.delegate.Call(ctx, ActionData(ActionDeleted, hme)); err != nil {
+		return fmt.Errorf("failed to execute `%s` action: %w", ActionDeleted, err)
+	}
+
 	return nil
 }
 
 // Query retrieves a list of existing homes.
 func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Home, error) {
+	ctx, span := otel.AddSpan(ctx, "business.homebus.query")
+	defer span.End()
+
 	hmes, err := b.storer.Query(ctx, filter, orderBy, page)
 	if err != nil {
 		return nil, fmt.Errorf("query: %w", err)
@@ -172,13 +213,50 @@ func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.
 	return hmes, nil
 }
 
+// QueryStream retrieves the existing homes one at a time in the
+// specified order. The sequence ends at the first error.
+func (b *Business) QueryStream(ctx context.Context, filter QueryFilter, orderBy order.By) iter.Seq2[Home, error] {
+	return func(yield func(Home, error) bool) {
+		for hme, err := range b.storer.QueryStream(ctx, filter, orderBy) {
+			if err != nil {
+				yield(Home{}, fmt.Errorf("querystream: %w", err))
+				return
+			}
+
+			if !yield(hme, nil) {
+				return
+			}
+		}
+	}
+}
+
+// QueryByKeyset retrieves a list of existing homes using keyset paging.
+// One more home than the limit is returned when there are more homes.
+func (b *Business) QueryByKeyset(ctx context.Context, filter QueryFilter, keyset page.Keyset) ([]Home, error) {
+	ctx, span := otel.AddSpan(ctx, "business.homebus.querybykeyset")
+	defer span.End()
+
+	hmes, err := b.storer.QueryByKeyset(ctx, filter, keyset)
+	if err != nil {
+		return nil, fmt.Errorf("querybykeyset: %w", err)
+	}
+
+	return hmes, nil
+}
+
 // Count returns the total number of homes.
 func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.homebus.count")
+	defer span.End()
+
 	return b.storer.Count(ctx, filter)
 }
 
 // QueryByID finds the home by the specified Ib.
 func (b *Business) QueryByID(ctx context.Context, homeID uuid.UUID) (Home, error) {
+	ctx, span := otel.AddSpan(ctx, "business.homebus.querybyid", attribute.String("home_id", homeID.String()))
+	defer span.End()
+
 	hme, err := b.storer.QueryByID(ctx, homeID)
 	if err != nil {
 		return Home{}, fmt.Errorf("query: homeID[%s]: %w", homeID, err)
@@ -189,6 +267,9 @@ func (b *Business) QueryByID(ctx context.Context, homeID uuid.UUID) (Home, error
 
 // QueryByUserID finds the homes by a specified User Ib.
 func (b *Business) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Home, error) {
+	ctx, span := otel.AddSpan(ctx, "business.homebus.querybyuserid", attribute.String("user_id", userID.String()))
+	defer span.End()
+
 	hmes, err := b.storer.QueryByUserID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("query: %w", err)
@@ -0,0 +1,131 @@
+// Package homecache contains home related CRUD functionality with
+// caching.
+package homecache
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/homebus"
+	"github.com/ardanlabs/encore/business/sdk/cachemetrics"
+	"github.com/ardanlabs/encore/business/sdk/order"
+	"github.com/ardanlabs/encore/business/sdk/page"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/business/sdk/storecache"
+	"github.com/ardanlabs/encore/foundation/clock"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Store manages the set of APIs for home data and caching.
+type Store struct {
+	log    *logger.Logger
+	storer homebus.Storer
+	cache  *storecache.Cache[homebus.Home]
+}
+
+// CacheName is the name the cache reports its metrics with.
+const CacheName = "homes"
+
+// NewStore constructs the api for data and caching access. The homes are
+// cached by their id.
+func NewStore(log *logger.Logger, storer homebus.Storer, cfg storecache.Config, clock clock.Clock, counters cachemetrics.Counters) *Store {
+	entity := storecache.Entity[homebus.Home]{
+		Name:     CacheName,
+		NotFound: homebus.ErrNotFound,
+		ID:       func(hme homebus.Home) string { return hme.ID.String() },
+	}
+
+	return &Store{
+		log:    log,
+		storer: storer,
+		cache:  storecache.New(entity, cfg, clock, counters),
+	}
+}
+
+// Stats returns what the cache has done with the top number of homes it
+// has served the most, keyed by their id.
+func (s *Store) Stats(top int) cachemetrics.Stats {
+	return s.cache.Stats(top)
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (homebus.Storer, error) {
+	return s.storer.NewWithTx(tx)
+}
+
+// Create inserts a new home into the database.
+func (s *Store) Create(ctx context.Context, hme homebus.Home) error {
+	if err := s.storer.Create(ctx, hme); err != nil {
+		return err
+	}
+
+	s.cache.Set(hme)
+
+	return nil
+}
+
+// Update replaces a home document in the database.
+func (s *Store) Update(ctx context.Context, hme homebus.Home, lastUpdated time.Time) error {
+	if err := s.storer.Update(ctx, hme, lastUpdated); err != nil {
+
+		// The cached home is out of date when another change got there
+		// first.
+		if errors.Is(err, homebus.ErrModified) {
+			s.cache.Delete(hme)
+		}
+		return err
+	}
+
+	s.cache.Set(hme)
+
+	return nil
+}
+
+// Delete removes a home from the database.
+func (s *Store) Delete(ctx context.Context, hme homebus.Home) error {
+	if err := s.storer.Delete(ctx, hme); err != nil {
+		return err
+	}
+
+	s.cache.Delete(hme)
+
+	return nil
+}
+
+// Query retrieves a list of existing homes from the database.
+func (s *Store) Query(ctx context.Context, filter homebus.QueryFilter, orderBy order.By, page page.Page) ([]homebus.Home, error) {
+	return s.storer.Query(ctx, filter, orderBy, page)
+}
+
+// QueryByKeyset retrieves a list of existing homes from the database
+// using keyset paging.
+func (s *Store) QueryByKeyset(ctx context.Context, filter homebus.QueryFilter, keyset page.Keyset) ([]homebus.Home, error) {
+	return s.storer.QueryByKeyset(ctx, filter, keyset)
+}
+
+// QueryStream retrieves the existing homes from the database one at a
+// time.
+func (s *Store) QueryStream(ctx context.Context, filter homebus.QueryFilter, orderBy order.By) iter.Seq2[homebus.Home, error] {
+	return s.storer.QueryStream(ctx, filter, orderBy)
+}
+
+// Count returns the total number of homes in the DB.
+func (s *Store) Count(ctx context.Context, filter homebus.QueryFilter) (int, error) {
+	return s.storer.Count(ctx, filter)
+}
+
+// QueryByID gets the specified home from the database.
+func (s *Store) QueryByID(ctx context.Context, homeID uuid.UUID) (homebus.Home, error) {
+	return s.cache.Get(ctx, homeID.String(), func(ctx context.Context) (homebus.Home, error) {
+		return s.storer.QueryByID(ctx, homeID)
+	})
+}
+
+// QueryByUserID gets the homes of the specified user from the database.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]homebus.Home, error) {
+	return s.storer.QueryByUserID(ctx, userID)
+}
@@ -0,0 +1,129 @@
+// Package homechaos contains a home store that injects faults into the calls
+// made to another home store, for testing how the failures are handled.
+package homechaos
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/homebus"
+	"github.com/ardanlabs/encore/business/sdk/chaos"
+	"github.com/ardanlabs/encore/business/sdk/order"
+	"github.com/ardanlabs/encore/business/sdk/page"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/google/uuid"
+)
+
+// Store manages the set of APIs for home access with injected faults.
+type Store struct {
+	storer   homebus.Storer
+	injector *chaos.Injector
+}
+
+// NewStore constructs a store that injects the faults chosen by the
+// injector before calling the storer.
+func NewStore(storer homebus.Storer, injector *chaos.Injector) *Store {
+	return &Store{
+		storer:   storer,
+		injector: injector,
+	}
+}
+
+// NewWithTx constructs a new Store value that injects faults into the calls
+// made inside the transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (homebus.Storer, error) {
+	if err := s.injector.Inject(context.Background(), "NewWithTx"); err != nil {
+		return nil, err
+	}
+
+	storer, err := s.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStore(storer, s.injector), nil
+}
+
+// Create inserts a new home.
+func (s *Store) Create(ctx context.Context, hme homebus.Home) error {
+	if err := s.injector.Inject(ctx, "Create"); err != nil {
+		return err
+	}
+
+	return s.storer.Create(ctx, hme)
+}
+
+// Update replaces a home.
+func (s *Store) Update(ctx context.Context, hme homebus.Home, lastUpdated time.Time) error {
+	if err := s.injector.Inject(ctx, "Update"); err != nil {
+		return err
+	}
+
+	return s.storer.Update(ctx, hme, lastUpdated)
+}
+
+// Delete removes a home.
+func (s *Store) Delete(ctx context.Context, hme homebus.Home) error {
+	if err := s.injector.Inject(ctx, "Delete"); err != nil {
+		return err
+	}
+
+	return s.storer.Delete(ctx, hme)
+}
+
+// Query retrieves a list of existing homes.
+func (s *Store) Query(ctx context.Context, filter homebus.QueryFilter, orderBy order.By, page page.Page) ([]homebus.Home, error) {
+	if err := s.injector.Inject(ctx, "Query"); err != nil {
+		return nil, err
+	}
+
+	return s.storer.Query(ctx, filter, orderBy, page)
+}
+
+// QueryByKeyset retrieves a list of existing homes using keyset paging.
+func (s *Store) QueryByKeyset(ctx context.Context, filter homebus.QueryFilter, keyset page.Keyset) ([]homebus.Home, error) {
+	if err := s.injector.Inject(ctx, "QueryByKeyset"); err != nil {
+		return nil, err
+	}
+
+	return s.storer.QueryByKeyset(ctx, filter, keyset)
+}
+
+// QueryStream retrieves the existing homes one at a time.
+func (s *Store) QueryStream(ctx context.Context, filter homebus.QueryFilter, orderBy order.By) iter.Seq2[homebus.Home, error] {
+	if err := s.injector.Inject(ctx, "QueryStream"); err != nil {
+		return func(yield func(homebus.Home, error) bool) {
+			yield(homebus.Home{}, err)
+		}
+	}
+
+	return s.storer.QueryStream(ctx, filter, orderBy)
+}
+
+// Count returns the total number of homes.
+func (s *Store) Count(ctx context.Context, filter homebus.QueryFilter) (int, error) {
+	if err := s.injector.Inject(ctx, "Count"); err != nil {
+		return 0, err
+	}
+
+	return s.storer.Count(ctx, filter)
+}
+
+// QueryByID gets the specified home.
+func (s *Store) QueryByID(ctx context.Context, homeID uuid.UUID) (homebus.Home, error) {
+	if err := s.injector.Inject(ctx, "QueryByID"); err != nil {
+		return homebus.Home{}, err
+	}
+
+	return s.storer.QueryByID(ctx, homeID)
+}
+
+// QueryByUserID gets the homes of the specified user.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]homebus.Home, error) {
+	if err := s.injector.Inject(ctx, "QueryByUserID"); err != nil {
+		return nil, err
+	}
+
+	return s.storer.QueryByUserID(ctx, userID)
+}
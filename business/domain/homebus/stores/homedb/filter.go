@@ -2,9 +2,11 @@ package homedb
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
 
 	"github.com/ardanlabs/encore/business/domain/homebus"
+	"github.com/ardanlabs/encore/business/sdk/where"
 )
 
 func (s *Store) applyFilter(filter homebus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
@@ -20,11 +22,13 @@ func (s *Store) applyFilter(filter homebus.QueryFilter, data map[string]any, buf
 		wc = append(wc, "user_id = :user_id")
 	}
 
-	if filter.Type != nil {
-		data["type"] = filter.Type.String()
-		wc = append(wc, "type = :type")
+	if filter.Address != nil {
+		data["address"] = fmt.Sprintf("%%%s%%", *filter.Address)
+		wc = append(wc, "(address_1 LIKE :address OR address_2 LIKE :address OR zip_code LIKE :address OR city LIKE :address OR state LIKE :address)")
 	}
 
+	wc = append(wc, where.Apply(where.Map(filter.Type, homebus.Type.String), "type", data)...)
+
 	if filter.StartCreatedDate != nil {
 		data["start_date_created"] = filter.StartCreatedDate.UTC()
 		wc = append(wc, "date_created >= :start_date_created")
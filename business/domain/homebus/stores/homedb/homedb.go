@@ -6,14 +6,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"iter"
+	"time"
 
 	"github.com/ardanlabs/encore/business/domain/homebus"
 	"github.com/ardanlabs/encore/business/sdk/order"
 	"github.com/ardanlabs/encore/business/sdk/page"
 	"github.com/ardanlabs/encore/business/sdk/sqldb"
 	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/ardanlabs/encore/foundation/otel"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Store manages the set of APIs for home database access.
@@ -48,6 +52,9 @@ func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (homebus.Storer, error) {
 
 // Create inserts a new home into the database.
 func (s *Store) Create(ctx context.Context, hme homebus.Home) error {
+	ctx, span := otel.AddSpan(ctx, "business.homedb.create", attribute.String("db.sql.table", "homes"))
+	defer span.End()
+
 	const q = `
     INSERT INTO homes
         (home_id, user_id, type, address_1, address_2, zip_code, city, state, country, date_created, date_updated)
@@ -61,29 +68,47 @@ func (s *Store) Create(ctx context.Context, hme homebus.Home) error {
 	return nil
 }
 
-// Delete removes a home from the database.
+// Delete removes a home from the database. ErrModified is returned when the
+// home was updated after it was read.
 func (s *Store) Delete(ctx context.Context, hme homebus.Home) error {
+	ctx, span := otel.AddSpan(ctx, "business.homedb.delete", attribute.String("db.sql.table", "homes"))
+	defer span.End()
+
 	data := struct {
-		ID string `db:"home_id"`
+		ID          string    `db:"home_id"`
+		DateUpdated time.Time `db:"date_updated"`
 	}{
-		ID: hme.ID.String(),
+		ID:          hme.ID.String(),
+		DateUpdated: hme.DateUpdated.UTC(),
 	}
 
 	const q = `
     DELETE FROM
 	    homes
 	WHERE
-	  	home_id = :home_id`
+	  	home_id = :home_id AND
+	  	date_updated = :date_updated
+	RETURNING
+	    home_id`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
-		return fmt.Errorf("namedexeccontext: %w", err)
+	var dbHme home
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbHme); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", homebus.ErrModified)
+		}
+		return fmt.Errorf("db: %w", err)
 	}
 
 	return nil
 }
 
-// Update replaces a home document in the database.
-func (s *Store) Update(ctx context.Context, hme homebus.Home) error {
+// Update replaces a home document in the database. The home is only replaced
+// when it was last updated at the specified time, otherwise ErrModified is
+// returned so a concurrent change isn't lost.
+func (s *Store) Update(ctx context.Context, hme homebus.Home, lastUpdated time.Time) error {
+	ctx, span := otel.AddSpan(ctx, "business.homedb.update", attribute.String("db.sql.table", "homes"))
+	defer span.End()
+
 	const q = `
     UPDATE
         homes
@@ -97,10 +122,25 @@ func (s *Store) Update(ctx context.Context, hme homebus.Home) error {
         "type"          = :type,
         "date_updated"  = :date_updated
     WHERE
-        home_id = :home_id`
+        home_id = :home_id AND
+        date_updated = :last_updated
+    RETURNING
+        home_id`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBHome(hme)); err != nil {
-		return fmt.Errorf("namedexeccontext: %w", err)
+	data := struct {
+		home
+		LastUpdated time.Time `db:"last_updated"`
+	}{
+		home:        toDBHome(hme),
+		LastUpdated: lastUpdated.UTC(),
+	}
+
+	var dbHme home
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbHme); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", homebus.ErrModified)
+		}
+		return fmt.Errorf("db: %w", err)
 	}
 
 	return nil
@@ -108,6 +148,9 @@ func (s *Store) Update(ctx context.Context, hme homebus.Home) error {
 
 // Query retrieves a list of existing homes from the database.
 func (s *Store) Query(ctx context.Context, filter homebus.QueryFilter, orderBy order.By, page page.Page) ([]homebus.Home, error) {
+	ctx, span := otel.AddSpan(ctx, "business.homedb.query", attribute.String("db.sql.table", "homes"))
+	defer span.End()
+
 	data := map[string]any{
 		"offset":        (page.Number() - 1) * page.RowsPerPage(),
 		"rows_per_page": page.RowsPerPage(),
@@ -143,8 +186,87 @@ func (s *Store) Query(ctx context.Context, filter homebus.QueryFilter, orderBy o
 	return hmes, nil
 }
 
+// QueryStream retrieves the existing homes from the database one at a
+// time, so every home can be read without holding them all in memory.
+func (s *Store) QueryStream(ctx context.Context, filter homebus.QueryFilter, orderBy order.By) iter.Seq2[homebus.Home, error] {
+	return func(yield func(homebus.Home, error) bool) {
+		ctx, span := otel.AddSpan(ctx, "business.homedb.querystream", attribute.String("db.sql.table", "homes"))
+		defer span.End()
+
+		data := map[string]any{}
+
+		const q = `
+	SELECT
+	    home_id, user_id, type, address_1, address_2, zip_code, city, state, country, date_created, date_updated
+	FROM
+		homes`
+
+		buf := bytes.NewBufferString(q)
+		s.applyFilter(filter, data, buf)
+
+		orderByClause, err := orderByClause(orderBy)
+		if err != nil {
+			yield(homebus.Home{}, err)
+			return
+		}
+
+		buf.WriteString(orderByClause)
+
+		for dbHme, err := range sqldb.NamedQueryIter[home](ctx, s.log, s.db, buf.String(), data) {
+			if err != nil {
+				yield(homebus.Home{}, fmt.Errorf("namedqueryiter: %w", err))
+				return
+			}
+
+			hme, err := toBusHome(dbHme)
+			if err != nil {
+				yield(homebus.Home{}, err)
+				return
+			}
+
+			if !yield(hme, nil) {
+				return
+			}
+		}
+	}
+}
+
+// QueryByKeyset retrieves a list of existing homes from the database using
+// keyset paging.
+func (s *Store) QueryByKeyset(ctx context.Context, filter homebus.QueryFilter, keyset page.Keyset) ([]homebus.Home, error) {
+	ctx, span := otel.AddSpan(ctx, "business.homedb.querybykeyset", attribute.String("db.sql.table", "homes"))
+	defer span.End()
+
+	data := map[string]any{}
+
+	const q = `
+    SELECT
+	    home_id, user_id, type, address_1, address_2, zip_code, city, state, country, date_created, date_updated
+	FROM
+	  	homes`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+	keyset.Apply("home_id", data, buf)
+
+	var dbHmes []home
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbHmes); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	hmes, err := toBusHomes(dbHmes)
+	if err != nil {
+		return nil, err
+	}
+
+	return hmes, nil
+}
+
 // Count returns the total number of homes in the DB.
 func (s *Store) Count(ctx context.Context, filter homebus.QueryFilter) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.homedb.count", attribute.String("db.sql.table", "homes"))
+	defer span.End()
+
 	data := map[string]any{}
 
 	const q = `
@@ -168,6 +290,9 @@ func (s *Store) Count(ctx context.Context, filter homebus.QueryFilter) (int, err
 
 // QueryByID gets the specified home from the database.
 func (s *Store) QueryByID(ctx context.Context, homeID uuid.UUID) (homebus.Home, error) {
+	ctx, span := otel.AddSpan(ctx, "business.homedb.querybyid", attribute.String("db.sql.table", "homes"))
+	defer span.End()
+
 	data := struct {
 		ID string `db:"home_id"`
 	}{
@@ -195,6 +320,9 @@ func (s *Store) QueryByID(ctx context.Context, homeID uuid.UUID) (homebus.Home,
 
 // QueryByUserID gets the specified home from the database by user id.
 func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]homebus.Home, error) {
+	ctx, span := otel.AddSpan(ctx, "business.homedb.querybyuserid", attribute.String("db.sql.table", "homes"))
+	defer span.End()
+
 	data := struct {
 		ID string `db:"user_id"`
 	}{
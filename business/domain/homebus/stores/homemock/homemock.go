@@ -0,0 +1,157 @@
+// Package homemock contains a home store that keeps the homes in memory and
+// records the calls made to it, for testing without a database.
+package homemock
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/homebus"
+	"github.com/ardanlabs/encore/business/sdk/mockstore"
+	"github.com/ardanlabs/encore/business/sdk/order"
+	"github.com/ardanlabs/encore/business/sdk/page"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/google/uuid"
+)
+
+// Store manages the set of APIs for home access in memory. The filters and
+// ordering of the queries aren't applied, the homes are returned in the
+// order they were created.
+type Store struct {
+	mockstore.Recorder
+	homes *mockstore.Table[homebus.Home]
+}
+
+// NewStore constructs an empty store.
+func NewStore() *Store {
+	return &Store{
+		homes: mockstore.NewTable(func(hme homebus.Home) uuid.UUID {
+			return hme.ID
+		}),
+	}
+}
+
+// NewWithTx returns the same store since transactions aren't supported.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (homebus.Storer, error) {
+	if err := s.Record("NewWithTx", tx); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Create inserts a new home into the store.
+func (s *Store) Create(ctx context.Context, hme homebus.Home) error {
+	if err := s.Record("Create", hme); err != nil {
+		return err
+	}
+
+	s.homes.Insert(hme)
+
+	return nil
+}
+
+// Update replaces a home in the store when it was last updated at the
+// specified time.
+func (s *Store) Update(ctx context.Context, hme homebus.Home, lastUpdated time.Time) error {
+	if err := s.Record("Update", hme, lastUpdated); err != nil {
+		return err
+	}
+
+	if cur, found := s.homes.Find(hme.ID); !found || !cur.DateUpdated.Equal(lastUpdated) {
+		return homebus.ErrModified
+	}
+
+	s.homes.Update(hme)
+
+	return nil
+}
+
+// Delete removes a home from the store when it hasn't been updated since it
+// was read.
+func (s *Store) Delete(ctx context.Context, hme homebus.Home) error {
+	if err := s.Record("Delete", hme); err != nil {
+		return err
+	}
+
+	if cur, found := s.homes.Find(hme.ID); !found || !cur.DateUpdated.Equal(hme.DateUpdated) {
+		return homebus.ErrModified
+	}
+
+	s.homes.Delete(hme)
+
+	return nil
+}
+
+// Query retrieves a page of the homes.
+func (s *Store) Query(ctx context.Context, filter homebus.QueryFilter, orderBy order.By, page page.Page) ([]homebus.Home, error) {
+	if err := s.Record("Query", filter, orderBy, page); err != nil {
+		return nil, err
+	}
+
+	return mockstore.Page(s.homes.Rows(), page), nil
+}
+
+// QueryByKeyset retrieves the first page of the homes using keyset paging.
+func (s *Store) QueryByKeyset(ctx context.Context, filter homebus.QueryFilter, keyset page.Keyset) ([]homebus.Home, error) {
+	if err := s.Record("QueryByKeyset", filter, keyset); err != nil {
+		return nil, err
+	}
+
+	return mockstore.Keyset(s.homes.Rows(), keyset), nil
+}
+
+// QueryStream retrieves all the homes one at a time.
+func (s *Store) QueryStream(ctx context.Context, filter homebus.QueryFilter, orderBy order.By) iter.Seq2[homebus.Home, error] {
+	err := s.Record("QueryStream", filter, orderBy)
+
+	return func(yield func(homebus.Home, error) bool) {
+		if err != nil {
+			yield(homebus.Home{}, err)
+			return
+		}
+
+		for _, hme := range s.homes.Rows() {
+			if !yield(hme, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Count returns the total number of homes in the store.
+func (s *Store) Count(ctx context.Context, filter homebus.QueryFilter) (int, error) {
+	if err := s.Record("Count", filter); err != nil {
+		return 0, err
+	}
+
+	return len(s.homes.Rows()), nil
+}
+
+// QueryByID gets the specified home from the store.
+func (s *Store) QueryByID(ctx context.Context, homeID uuid.UUID) (homebus.Home, error) {
+	if err := s.Record("QueryByID", homeID); err != nil {
+		return homebus.Home{}, err
+	}
+
+	hme, found := s.homes.Find(homeID)
+	if !found {
+		return homebus.Home{}, homebus.ErrNotFound
+	}
+
+	return hme, nil
+}
+
+// QueryByUserID gets the homes of the specified user from the store.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]homebus.Home, error) {
+	if err := s.Record("QueryByUserID", userID); err != nil {
+		return nil, err
+	}
+
+	hmes := s.homes.Select(func(hme homebus.Home) bool {
+		return hme.UserID == userID
+	})
+
+	return hmes, nil
+}
@@ -9,10 +9,10 @@ import (
 )
 
 // TestGenerateNewHomes is a helper method for testing.
-func TestGenerateNewHomes(n int, userID uuid.UUID) []NewHome {
+func TestGenerateNewHomes(rnd *rand.Rand, n int, userID uuid.UUID) []NewHome {
 	newHmes := make([]NewHome, n)
 
-	idx := rand.Intn(10000)
+	idx := rnd.Intn(10000)
 	for i := 0; i < n; i++ {
 		idx++
 
@@ -41,8 +41,8 @@ func TestGenerateNewHomes(n int, userID uuid.UUID) []NewHome {
 }
 
 // TestGenerateSeedHomes is a helper method for testing.
-func TestGenerateSeedHomes(ctx context.Context, n int, api *Business, userID uuid.UUID) ([]Home, error) {
-	newHmes := TestGenerateNewHomes(n, userID)
+func TestGenerateSeedHomes(ctx context.Context, rnd *rand.Rand, n int, api *Business, userID uuid.UUID) ([]Home, error) {
+	newHmes := TestGenerateNewHomes(rnd, n, userID)
 
 	hmes := make([]Home, len(newHmes))
 	for i, nh := range newHmes {
@@ -1,6 +1,6 @@
 package homebus
 
-import "fmt"
+import "github.com/ardanlabs/encore/business/sdk/enum"
 
 type typeSet struct {
 	Single Type
@@ -15,8 +15,8 @@ var Types = typeSet{
 
 // =============================================================================
 
-// Set of known housing types.
-var types = make(map[string]Type)
+// Set of known housing types, registered for the enum validation tag.
+var types = enum.New[Type]("hometype")
 
 // Type represents a type in the system.
 type Type struct {
@@ -24,9 +24,7 @@ type Type struct {
 }
 
 func newType(typ string) Type {
-	t := Type{typ}
-	types[typ] = t
-	return t
+	return types.Add(Type{typ})
 }
 
 // String returns the name of the type.
@@ -43,12 +41,7 @@ func (t Type) Equal(t2 Type) bool {
 
 // ParseType parses the string value and returns a type if one exists.
 func ParseType(value string) (Type, error) {
-	typ, exists := types[value]
-	if !exists {
-		return Type{}, fmt.Errorf("invalid type %q", value)
-	}
-
-	return typ, nil
+	return types.Parse(value)
 }
 
 // MustParseType parses the string value and returns a type if one exists. If
@@ -0,0 +1,281 @@
+package idempotencybus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"encore.dev/et"
+	"github.com/ardanlabs/encore/business/domain/idempotencybus"
+	"github.com/ardanlabs/encore/business/sdk/dbtest"
+	"github.com/ardanlabs/encore/business/sdk/unitest"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+func Test_Idempotency(t *testing.T) {
+	t.Parallel()
+
+	edb, err := et.NewTestDatabase(context.Background(), "app")
+	if err != nil {
+		t.Fatalf("Creating new database: %s", err)
+	}
+
+	db := dbtest.NewDatabase(t, edb)
+
+	userID := uuid.New()
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, reserve(db.BusDomain, userID), "reserve")
+	unitest.Run(t, release(db.BusDomain, userID), "release")
+	unitest.Run(t, expire(db, userID), "expire")
+}
+
+// =============================================================================
+
+func newResponse(userID uuid.UUID, key string) idempotencybus.NewResponse {
+	return idempotencybus.NewResponse{
+		UserID:      userID,
+		Key:         key,
+		Endpoint:    "/v1/products",
+		RequestHash: "hash",
+	}
+}
+
+func cmpError(got any, exp any) string {
+	gotErr, _ := got.(error)
+	if !errors.Is(gotErr, exp.(error)) {
+		return fmt.Sprintf("got %v, exp %v", got, exp)
+	}
+
+	return ""
+}
+
+func reserve(busDomain dbtest.BusDomain, userID uuid.UUID) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "in-progress",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				if _, err := busDomain.Idempotency.Reserve(ctx, newResponse(userID, "reserve")); err != nil {
+					return err
+				}
+
+				rsp, err := busDomain.Idempotency.QueryByKey(ctx, userID, "reserve")
+				if err != nil {
+					return err
+				}
+
+				return rsp.InProgress()
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "reserved",
+			ExpResp: idempotencybus.ErrExists,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.Idempotency.Reserve(ctx, newResponse(userID, "reserve"))
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+		{
+			Name: "complete",
+			ExpResp: idempotencybus.Response{
+				UserID:      userID,
+				Key:         "reserve",
+				Endpoint:    "/v1/products",
+				RequestHash: "hash",
+				HTTPStatus:  http.StatusAccepted,
+				Payload:     []byte(`{"id":"1"}`),
+			},
+			ExcFunc: func(ctx context.Context) any {
+				rsp, err := busDomain.Idempotency.QueryByKey(ctx, userID, "reserve")
+				if err != nil {
+					return err
+				}
+
+				if _, err := busDomain.Idempotency.Complete(ctx, rsp, http.StatusAccepted, []byte(`{"id":"1"}`)); err != nil {
+					return err
+				}
+
+				rsp, err = busDomain.Idempotency.QueryByKey(ctx, userID, "reserve")
+				if err != nil {
+					return err
+				}
+
+				if rsp.InProgress() {
+					return errors.New("should be completed")
+				}
+
+				return rsp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(idempotencybus.Response)
+				if !exists {
+					return fmt.Sprintf("error occurred: %v", got)
+				}
+
+				expResp := exp.(idempotencybus.Response)
+				expResp.DateCreated = gotResp.DateCreated
+				expResp.DateCompleted = gotResp.DateCompleted
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:    "completed",
+			ExpResp: idempotencybus.ErrExists,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.Idempotency.Reserve(ctx, newResponse(userID, "reserve"))
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+		{
+			Name:    "other-user",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				if _, err := busDomain.Idempotency.Reserve(ctx, newResponse(uuid.New(), "reserve")); err != nil {
+					return err
+				}
+
+				return true
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func release(busDomain dbtest.BusDomain, userID uuid.UUID) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "not-found",
+			ExpResp: idempotencybus.ErrNotFound,
+			ExcFunc: func(ctx context.Context) any {
+				rsp, err := busDomain.Idempotency.Reserve(ctx, newResponse(userID, "release"))
+				if err != nil {
+					return err
+				}
+
+				if err := busDomain.Idempotency.Release(ctx, rsp); err != nil {
+					return err
+				}
+
+				_, err = busDomain.Idempotency.QueryByKey(ctx, userID, "release")
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+		{
+			Name:    "reserve-again",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				rsp, err := busDomain.Idempotency.Reserve(ctx, newResponse(userID, "release"))
+				if err != nil {
+					return err
+				}
+
+				return rsp.InProgress()
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "completed",
+			ExpResp: false,
+			ExcFunc: func(ctx context.Context) any {
+				rsp, err := busDomain.Idempotency.QueryByKey(ctx, userID, "release")
+				if err != nil {
+					return err
+				}
+
+				if _, err := busDomain.Idempotency.Complete(ctx, rsp, http.StatusOK, []byte(`{}`)); err != nil {
+					return err
+				}
+
+				// A completed response isn't released.
+				if err := busDomain.Idempotency.Release(ctx, rsp); err != nil {
+					return err
+				}
+
+				rsp, err = busDomain.Idempotency.QueryByKey(ctx, userID, "release")
+				if err != nil {
+					return err
+				}
+
+				return rsp.InProgress()
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func expire(db *dbtest.Database, userID uuid.UUID) []unitest.Table {
+	busDomain := db.BusDomain
+
+	table := []unitest.Table{
+		{
+			Name:    "query",
+			ExpResp: idempotencybus.ErrNotFound,
+			ExcFunc: func(ctx context.Context) any {
+				if _, err := busDomain.Idempotency.Reserve(ctx, newResponse(userID, "expire")); err != nil {
+					return err
+				}
+
+				db.Clock.Advance(2 * time.Hour)
+
+				_, err := busDomain.Idempotency.QueryByKey(ctx, userID, "expire")
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+		{
+			Name:    "reserve-expired",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				rsp, err := busDomain.Idempotency.Reserve(ctx, newResponse(userID, "expire"))
+				if err != nil {
+					return err
+				}
+
+				return rsp.InProgress()
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "delete",
+			ExpResp: 3,
+			ExcFunc: func(ctx context.Context) any {
+				n, err := busDomain.Idempotency.DeleteExpired(ctx)
+				if err != nil {
+					return err
+				}
+
+				return n
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
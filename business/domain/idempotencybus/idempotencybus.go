@@ -0,0 +1,124 @@
+// Package idempotencybus provides business access to the responses stored
+// for requests made with an idempotency key, so a retried request gets the
+// original response instead of being applied twice.
+package idempotencybus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/foundation/clock"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for idempotency operations.
+var (
+	ErrNotFound = errors.New("idempotency key not found")
+	ErrExists   = errors.New("idempotency key already used")
+)
+
+// Storer interface declares the behaviour this package needs to persist and
+// retrieve data.
+type Storer interface {
+	Reserve(ctx context.Context, rsp Response, expired time.Time) error
+	Complete(ctx context.Context, rsp Response) error
+	Release(ctx context.Context, rsp Response) error
+	QueryByKey(ctx context.Context, userID uuid.UUID, key string) (Response, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int, error)
+}
+
+// Business manages the set of APIs for idempotency key access.
+type Business struct {
+	log    *logger.Logger
+	clock  clock.Clock
+	ttl    time.Duration
+	storer Storer
+}
+
+// NewBusiness constructs an idempotency business API for use. Responses
+// are replayed for the ttl after they are stored.
+func NewBusiness(log *logger.Logger, clk clock.Clock, ttl time.Duration, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		clock:  clk,
+		ttl:    ttl,
+		storer: storer,
+	}
+}
+
+// Reserve claims the user's key for a request that is about to be applied,
+// before the response is known. ErrExists is returned when the key is
+// already reserved or has a response, so only one of two concurrent
+// requests with the same key is applied. A key older than the ttl can be
+// reserved again.
+func (b *Business) Reserve(ctx context.Context, nr NewResponse) (Response, error) {
+	now := b.clock.Now()
+
+	rsp := Response{
+		UserID:      nr.UserID,
+		Key:         nr.Key,
+		Endpoint:    nr.Endpoint,
+		RequestHash: nr.RequestHash,
+		DateCreated: now,
+	}
+
+	if err := b.storer.Reserve(ctx, rsp, now.Add(-b.ttl)); err != nil {
+		return Response{}, fmt.Errorf("reserve: %w", err)
+	}
+
+	return rsp, nil
+}
+
+// Complete stores the response for a reserved key so it's replayed when
+// the request is retried.
+func (b *Business) Complete(ctx context.Context, rsp Response, httpStatus int, payload []byte) (Response, error) {
+	rsp.HTTPStatus = httpStatus
+	rsp.Payload = payload
+	rsp.DateCompleted = b.clock.Now()
+
+	if err := b.storer.Complete(ctx, rsp); err != nil {
+		return Response{}, fmt.Errorf("complete: %w", err)
+	}
+
+	return rsp, nil
+}
+
+// Release removes the reservation for a key that didn't get a response,
+// so the request can be retried with the same key.
+func (b *Business) Release(ctx context.Context, rsp Response) error {
+	if err := b.storer.Release(ctx, rsp); err != nil {
+		return fmt.Errorf("release: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByKey finds the response stored for the user's key. A response that
+// is older than the ttl is treated as not found, even if it hasn't been
+// deleted yet.
+func (b *Business) QueryByKey(ctx context.Context, userID uuid.UUID, key string) (Response, error) {
+	rsp, err := b.storer.QueryByKey(ctx, userID, key)
+	if err != nil {
+		return Response{}, fmt.Errorf("query: userID[%s] key[%s]: %w", userID, key, err)
+	}
+
+	if b.clock.Now().Sub(rsp.DateCreated) > b.ttl {
+		return Response{}, fmt.Errorf("query: userID[%s] key[%s]: %w", userID, key, ErrNotFound)
+	}
+
+	return rsp, nil
+}
+
+// DeleteExpired removes the responses that are older than the ttl and
+// returns the number removed.
+func (b *Business) DeleteExpired(ctx context.Context) (int, error) {
+	n, err := b.storer.DeleteBefore(ctx, b.clock.Now().Add(-b.ttl))
+	if err != nil {
+		return 0, fmt.Errorf("deletebefore: %w", err)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,35 @@
+package idempotencybus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Response represents the stored response for a request made with an
+// idempotency key. A response without a completed date is still being
+// applied.
+type Response struct {
+	UserID        uuid.UUID
+	Key           string
+	Endpoint      string
+	RequestHash   string
+	HTTPStatus    int
+	Payload       []byte
+	DateCreated   time.Time
+	DateCompleted time.Time
+}
+
+// InProgress reports if the request the key was reserved for hasn't
+// completed yet.
+func (r Response) InProgress() bool {
+	return r.DateCompleted.IsZero()
+}
+
+// NewResponse is what we require to reserve a key for a request.
+type NewResponse struct {
+	UserID      uuid.UUID
+	Key         string
+	Endpoint    string
+	RequestHash string
+}
@@ -0,0 +1,182 @@
+// Package idempotencydb contains idempotency key related CRUD functionality.
+package idempotencydb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/idempotencybus"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for idempotency key database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Reserve inserts a response without a result for the user's key. A key
+// that was created before the expired time is taken over, otherwise
+// ErrExists is returned.
+func (s *Store) Reserve(ctx context.Context, rsp idempotencybus.Response, expired time.Time) error {
+	data := struct {
+		response
+		Expired time.Time `db:"expired"`
+	}{
+		response: toDBResponse(rsp),
+		Expired:  expired.UTC(),
+	}
+
+	const q = `
+    INSERT INTO idempotency_keys
+        (user_id, idempotency_key, endpoint, request_hash, http_status, payload, date_created, date_completed)
+    VALUES
+        (:user_id, :idempotency_key, :endpoint, :request_hash, 0, '', :date_created, NULL)
+    ON CONFLICT (user_id, idempotency_key) DO UPDATE SET
+        endpoint       = EXCLUDED.endpoint,
+        request_hash   = EXCLUDED.request_hash,
+        http_status    = 0,
+        payload        = '',
+        date_created   = EXCLUDED.date_created,
+        date_completed = NULL
+    WHERE
+        idempotency_keys.date_created < :expired
+    RETURNING
+        idempotency_key`
+
+	var dest struct {
+		Key string `db:"idempotency_key"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", idempotencybus.ErrExists)
+		}
+		return fmt.Errorf("db: %w", err)
+	}
+
+	return nil
+}
+
+// Complete stores the result for a key that is still reserved by the
+// request.
+func (s *Store) Complete(ctx context.Context, rsp idempotencybus.Response) error {
+	const q = `
+    UPDATE
+        idempotency_keys
+    SET
+        http_status    = :http_status,
+        payload        = :payload,
+        date_completed = :date_completed
+    WHERE
+        user_id = :user_id AND
+        idempotency_key = :idempotency_key AND
+        date_created = :date_created
+    RETURNING
+        idempotency_key`
+
+	var dest struct {
+		Key string `db:"idempotency_key"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, toDBResponse(rsp), &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", idempotencybus.ErrNotFound)
+		}
+		return fmt.Errorf("db: %w", err)
+	}
+
+	return nil
+}
+
+// Release deletes a key that is still reserved by the request and has no
+// result.
+func (s *Store) Release(ctx context.Context, rsp idempotencybus.Response) error {
+	const q = `
+    DELETE FROM
+        idempotency_keys
+    WHERE
+        user_id = :user_id AND
+        idempotency_key = :idempotency_key AND
+        date_created = :date_created AND
+        date_completed IS NULL`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBResponse(rsp)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByKey gets the response stored for the user's key from the database.
+func (s *Store) QueryByKey(ctx context.Context, userID uuid.UUID, key string) (idempotencybus.Response, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+		Key    string `db:"idempotency_key"`
+	}{
+		UserID: userID.String(),
+		Key:    key,
+	}
+
+	const q = `
+    SELECT
+        user_id, idempotency_key, endpoint, request_hash, http_status, payload, date_created, date_completed
+    FROM
+        idempotency_keys
+    WHERE
+        user_id = :user_id AND
+        idempotency_key = :idempotency_key`
+
+	var dbRsp response
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbRsp); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return idempotencybus.Response{}, fmt.Errorf("db: %w", idempotencybus.ErrNotFound)
+		}
+		return idempotencybus.Response{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusResponse(dbRsp), nil
+}
+
+// DeleteBefore removes the responses created before the specified time and
+// returns the number removed.
+func (s *Store) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before.UTC(),
+	}
+
+	const q = `
+    WITH deleted AS (
+        DELETE FROM
+            idempotency_keys
+        WHERE
+            date_created < :before
+        RETURNING 1
+    )
+    SELECT
+        count(1)
+    FROM
+        deleted`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
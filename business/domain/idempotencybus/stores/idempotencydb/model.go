@@ -0,0 +1,62 @@
+package idempotencydb
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/idempotencybus"
+	"github.com/google/uuid"
+)
+
+type response struct {
+	UserID        uuid.UUID    `db:"user_id"`
+	Key           string       `db:"idempotency_key"`
+	Endpoint      string       `db:"endpoint"`
+	RequestHash   string       `db:"request_hash"`
+	HTTPStatus    int          `db:"http_status"`
+	Payload       string       `db:"payload"`
+	DateCreated   time.Time    `db:"date_created"`
+	DateCompleted sql.NullTime `db:"date_completed"`
+}
+
+// toDBResponse converts the response for the database. The created date is
+// truncated to what the database stores since a reservation is matched on
+// it.
+func toDBResponse(bus idempotencybus.Response) response {
+	db := response{
+		UserID:      bus.UserID,
+		Key:         bus.Key,
+		Endpoint:    bus.Endpoint,
+		RequestHash: bus.RequestHash,
+		HTTPStatus:  bus.HTTPStatus,
+		Payload:     string(bus.Payload),
+		DateCreated: bus.DateCreated.UTC().Truncate(time.Microsecond),
+	}
+
+	if !bus.DateCompleted.IsZero() {
+		db.DateCompleted = sql.NullTime{
+			Time:  bus.DateCompleted.UTC(),
+			Valid: true,
+		}
+	}
+
+	return db
+}
+
+func toBusResponse(db response) idempotencybus.Response {
+	bus := idempotencybus.Response{
+		UserID:      db.UserID,
+		Key:         db.Key,
+		Endpoint:    db.Endpoint,
+		RequestHash: db.RequestHash,
+		HTTPStatus:  db.HTTPStatus,
+		Payload:     []byte(db.Payload),
+		DateCreated: db.DateCreated.In(time.Local),
+	}
+
+	if db.DateCompleted.Valid {
+		bus.DateCompleted = db.DateCompleted.Time.In(time.Local)
+	}
+
+	return bus
+}
@@ -0,0 +1,262 @@
+package jobbus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"encore.dev/et"
+	"github.com/ardanlabs/encore/business/domain/jobbus"
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/sdk/dbtest"
+	"github.com/ardanlabs/encore/business/sdk/pubsub"
+	"github.com/ardanlabs/encore/business/sdk/pubsubtest"
+	"github.com/ardanlabs/encore/business/sdk/unitest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Job(t *testing.T) {
+	t.Parallel()
+
+	edb, err := et.NewTestDatabase(context.Background(), "app")
+	if err != nil {
+		t.Fatalf("Creating new database: %s", err)
+	}
+
+	db := dbtest.NewDatabase(t, edb)
+
+	db.BusDomain.Job.Register("succeed", func(ctx context.Context, job jobbus.Job) ([]byte, error) {
+		return []byte(`{"done":true}`), nil
+	})
+
+	db.BusDomain.Job.Register("fail", func(ctx context.Context, job jobbus.Job) ([]byte, error) {
+		return nil, errors.New("job failed")
+	})
+
+	sd, err := insertSeedData(db)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, enqueue(db.BusDomain, sd), "enqueue")
+	unitest.Run(t, run(db.BusDomain, sd), "run")
+
+	t.Run("publish", func(t *testing.T) {
+		ctx, cancel := dbtest.Context()
+		defer cancel()
+
+		nj := jobbus.NewJob{
+			UserID: sd.Users[0].ID,
+			Kind:   "succeed",
+		}
+
+		job, err := db.BusDomain.Job.Enqueue(ctx, nj)
+		if err != nil {
+			t.Fatalf("Should be able to enqueue the job: %s", err)
+		}
+
+		pubsubtest.Expect(t, pubsub.Jobs, pubsubtest.Job(job.ID))
+	})
+}
+
+// =============================================================================
+
+func insertSeedData(db *dbtest.Database) (unitest.SeedData, error) {
+	ctx, cancel := dbtest.Context()
+	defer cancel()
+
+	usrs, err := userbus.TestSeedUsers(ctx, db.Rand, 1, userbus.Roles.User, db.BusDomain.User)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu1 := unitest.User{
+		User: usrs[0],
+	}
+
+	sd := unitest.SeedData{
+		Users: []unitest.User{tu1},
+	}
+
+	return sd, nil
+}
+
+// =============================================================================
+
+func enqueue(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "unknown-kind",
+			ExpResp: jobbus.ErrUnknownKind,
+			ExcFunc: func(ctx context.Context) any {
+				nj := jobbus.NewJob{
+					UserID: sd.Users[0].ID,
+					Kind:   "unknown",
+				}
+
+				_, err := busDomain.Job.Enqueue(ctx, nj)
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotErr, _ := got.(error)
+				if !errors.Is(gotErr, exp.(error)) {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+
+				return ""
+			},
+		},
+		{
+			Name: "basic",
+			ExpResp: jobbus.Job{
+				UserID:      sd.Users[0].ID,
+				Kind:        "succeed",
+				Payload:     []byte(`{}`),
+				Status:      jobbus.Statuses.Queued,
+				MaxAttempts: jobbus.DefaultMaxAttempts,
+			},
+			ExcFunc: func(ctx context.Context) any {
+				nj := jobbus.NewJob{
+					UserID:  sd.Users[0].ID,
+					Kind:    "succeed",
+					Payload: []byte(`{}`),
+				}
+
+				job, err := busDomain.Job.Enqueue(ctx, nj)
+				if err != nil {
+					return err
+				}
+
+				resp, err := busDomain.Job.QueryByID(ctx, job.ID)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(jobbus.Job)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(jobbus.Job)
+
+				expResp.ID = gotResp.ID
+				expResp.DateCreated = gotResp.DateCreated
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func run(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name: "succeed",
+			ExpResp: jobbus.Job{
+				Status:   jobbus.Statuses.Succeeded,
+				Attempts: 1,
+				Result:   []byte(`{"done":true}`),
+			},
+			ExcFunc: func(ctx context.Context) any {
+				nj := jobbus.NewJob{
+					UserID: sd.Users[0].ID,
+					Kind:   "succeed",
+				}
+
+				job, err := busDomain.Job.Enqueue(ctx, nj)
+				if err != nil {
+					return err
+				}
+
+				if err := busDomain.Job.Run(ctx, job.ID); err != nil {
+					return err
+				}
+
+				resp, err := busDomain.Job.QueryByID(ctx, job.ID)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(jobbus.Job)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(jobbus.Job)
+
+				gotResp = jobbus.Job{
+					Status:   gotResp.Status,
+					Attempts: gotResp.Attempts,
+					Result:   gotResp.Result,
+				}
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name: "fail-after-attempts",
+			ExpResp: jobbus.Job{
+				Status:   jobbus.Statuses.Failed,
+				Attempts: 2,
+				Error:    "job failed",
+			},
+			ExcFunc: func(ctx context.Context) any {
+				nj := jobbus.NewJob{
+					UserID:      sd.Users[0].ID,
+					Kind:        "fail",
+					MaxAttempts: 2,
+				}
+
+				job, err := busDomain.Job.Enqueue(ctx, nj)
+				if err != nil {
+					return err
+				}
+
+				if err := busDomain.Job.Run(ctx, job.ID); err == nil {
+					return errors.New("expected the first attempt to be retried")
+				}
+
+				if err := busDomain.Job.Run(ctx, job.ID); err != nil {
+					return err
+				}
+
+				resp, err := busDomain.Job.QueryByID(ctx, job.ID)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(jobbus.Job)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(jobbus.Job)
+
+				gotResp = jobbus.Job{
+					Status:   gotResp.Status,
+					Attempts: gotResp.Attempts,
+					Error:    gotResp.Error,
+				}
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
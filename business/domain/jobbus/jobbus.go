@@ -0,0 +1,198 @@
+// Package jobbus provides business access to the background job domain.
+package jobbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for job operations.
+var (
+	ErrNotFound    = errors.New("job not found")
+	ErrUnknownKind = errors.New("job kind not registered")
+)
+
+// DefaultMaxAttempts represents the number of times a job is attempted when
+// the caller doesn't specify a value.
+const DefaultMaxAttempts = 5
+
+// Storer interface declares the behaviour this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, job Job) error
+	Update(ctx context.Context, job Job) error
+	QueryByID(ctx context.Context, jobID uuid.UUID) (Job, error)
+}
+
+// Publisher interface declares the behaviour this package needs to hand a
+// job off to a worker.
+type Publisher interface {
+	Publish(ctx context.Context, jobID uuid.UUID) error
+}
+
+// HandlerFunc represents a function that performs the work for a kind of job.
+// The returned result is stored with the job for clients to retrieve.
+type HandlerFunc func(ctx context.Context, job Job) ([]byte, error)
+
+// Business manages the set of APIs for job access.
+type Business struct {
+	log       *logger.Logger
+	publisher Publisher
+	storer    Storer
+	handlers  map[string]HandlerFunc
+}
+
+// NewBusiness constructs a job business API for use.
+func NewBusiness(log *logger.Logger, publisher Publisher, storer Storer) *Business {
+	return &Business{
+		log:       log,
+		publisher: publisher,
+		storer:    storer,
+		handlers:  make(map[string]HandlerFunc),
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:       b.log,
+		publisher: b.publisher,
+		storer:    storer,
+		handlers:  b.handlers,
+	}
+
+	return &bus, nil
+}
+
+// Register adds the handler that performs the work for the specified kind of
+// job. Handlers must be registered before the service starts taking requests.
+func (b *Business) Register(kind string, fn HandlerFunc) {
+	b.handlers[kind] = fn
+}
+
+// Enqueue stores a new job and hands it off to a worker.
+func (b *Business) Enqueue(ctx context.Context, nj NewJob) (Job, error) {
+	if _, exists := b.handlers[nj.Kind]; !exists {
+		return Job{}, fmt.Errorf("kind[%s]: %w", nj.Kind, ErrUnknownKind)
+	}
+
+	maxAttempts := nj.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	now := time.Now()
+
+	job := Job{
+		ID:          uuid.New(),
+		UserID:      nj.UserID,
+		Kind:        nj.Kind,
+		Payload:     nj.Payload,
+		Status:      Statuses.Queued,
+		MaxAttempts: maxAttempts,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := b.storer.Create(ctx, job); err != nil {
+		return Job{}, fmt.Errorf("create: %w", err)
+	}
+
+	if err := b.publisher.Publish(ctx, job.ID); err != nil {
+		return Job{}, fmt.Errorf("publish: %w", err)
+	}
+
+	return job, nil
+}
+
+// QueryByID finds the job by the specified ID.
+func (b *Business) QueryByID(ctx context.Context, jobID uuid.UUID) (Job, error) {
+	job, err := b.storer.QueryByID(ctx, jobID)
+	if err != nil {
+		return Job{}, fmt.Errorf("query: jobID[%s]: %w", jobID, err)
+	}
+
+	return job, nil
+}
+
+// Run performs a single attempt of the specified job. An error is returned
+// when the attempt failed and the job should be retried. Once the job runs
+// out of attempts it is marked as failed and no error is returned.
+func (b *Business) Run(ctx context.Context, jobID uuid.UUID) error {
+	job, err := b.storer.QueryByID(ctx, jobID)
+	if err != nil {
+		return fmt.Errorf("query: jobID[%s]: %w", jobID, err)
+	}
+
+	// Messages can be delivered more than once so a job that has already
+	// completed is ignored.
+	if job.Status.Done() {
+		return nil
+	}
+
+	fn, exists := b.handlers[job.Kind]
+	if !exists {
+		job.Status = Statuses.Failed
+		job.Error = ErrUnknownKind.Error()
+
+		return b.update(ctx, job)
+	}
+
+	job.Status = Statuses.Running
+	job.Attempts++
+
+	if err := b.update(ctx, job); err != nil {
+		return err
+	}
+
+	result, err := fn(ctx, job)
+	if err != nil {
+		b.log.Error(ctx, "job run", "jobID", job.ID, "kind", job.Kind, "attempt", job.Attempts, "ERROR", err)
+
+		job.Error = err.Error()
+		job.Status = Statuses.Queued
+
+		if job.Attempts >= job.MaxAttempts {
+			job.Status = Statuses.Failed
+		}
+
+		if err := b.update(ctx, job); err != nil {
+			return err
+		}
+
+		if job.Status == Statuses.Failed {
+			return nil
+		}
+
+		return fmt.Errorf("run: jobID[%s] attempt[%d]: %w", job.ID, job.Attempts, err)
+	}
+
+	job.Status = Statuses.Succeeded
+	job.Result = result
+	job.Error = ""
+
+	return b.update(ctx, job)
+}
+
+func (b *Business) update(ctx context.Context, job Job) error {
+	job.DateUpdated = time.Now()
+
+	if err := b.storer.Update(ctx, job); err != nil {
+		return fmt.Errorf("update: jobID[%s]: %w", job.ID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package jobbus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job represents a unit of long running work that is processed in the
+// background.
+type Job struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Kind        string
+	Payload     []byte
+	Status      Status
+	Attempts    int
+	MaxAttempts int
+	Result      []byte
+	Error       string
+	DateCreated time.Time
+	DateUpdated time.Time
+}
+
+// NewJob is what we require to enqueue a job. If MaxAttempts is zero, the
+// default number of attempts is used.
+type NewJob struct {
+	UserID      uuid.UUID
+	Kind        string
+	Payload     []byte
+	MaxAttempts int
+}
@@ -0,0 +1,72 @@
+package jobbus
+
+import "fmt"
+
+type statusSet struct {
+	Queued    Status
+	Running   Status
+	Succeeded Status
+	Failed    Status
+}
+
+// Statuses represents the set of statuses a job can be in.
+var Statuses = statusSet{
+	Queued:    newStatus("QUEUED"),
+	Running:   newStatus("RUNNING"),
+	Succeeded: newStatus("SUCCEEDED"),
+	Failed:    newStatus("FAILED"),
+}
+
+// =============================================================================
+
+// Set of known job statuses.
+var statuses = make(map[string]Status)
+
+// Status represents a job status in the system.
+type Status struct {
+	name string
+}
+
+func newStatus(status string) Status {
+	s := Status{status}
+	statuses[status] = s
+	return s
+}
+
+// String returns the name of the status.
+func (s Status) String() string {
+	return s.name
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (s Status) Equal(s2 Status) bool {
+	return s.name == s2.name
+}
+
+// Done reports whether the job has reached a final status.
+func (s Status) Done() bool {
+	return s == Statuses.Succeeded || s == Statuses.Failed
+}
+
+// =============================================================================
+
+// ParseStatus parses the string value and returns a status if one exists.
+func ParseStatus(value string) (Status, error) {
+	status, exists := statuses[value]
+	if !exists {
+		return Status{}, fmt.Errorf("invalid status %q", value)
+	}
+
+	return status, nil
+}
+
+// MustParseStatus parses the string value and returns a status if one exists.
+// If an error occurs the function panics.
+func MustParseStatus(value string) Status {
+	status, err := ParseStatus(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return status
+}
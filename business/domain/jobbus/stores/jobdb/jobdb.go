@@ -0,0 +1,107 @@
+// Package jobdb contains job related CRUD functionality.
+package jobdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ardanlabs/encore/business/domain/jobbus"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for job database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (jobbus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new job into the database.
+func (s *Store) Create(ctx context.Context, job jobbus.Job) error {
+	const q = `
+    INSERT INTO jobs
+        (job_id, user_id, kind, payload, status, attempts, max_attempts, result, error, date_created, date_updated)
+    VALUES
+        (:job_id, :user_id, :kind, :payload, :status, :attempts, :max_attempts, :result, :error, :date_created, :date_updated)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBJob(job)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces a job document in the database.
+func (s *Store) Update(ctx context.Context, job jobbus.Job) error {
+	const q = `
+    UPDATE
+        jobs
+    SET
+        "status"       = :status,
+        "attempts"     = :attempts,
+        "result"       = :result,
+        "error"        = :error,
+        "date_updated" = :date_updated
+    WHERE
+        job_id = :job_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBJob(job)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID gets the specified job from the database.
+func (s *Store) QueryByID(ctx context.Context, jobID uuid.UUID) (jobbus.Job, error) {
+	data := struct {
+		ID string `db:"job_id"`
+	}{
+		ID: jobID.String(),
+	}
+
+	const q = `
+    SELECT
+        job_id, user_id, kind, payload, status, attempts, max_attempts, result, error, date_created, date_updated
+    FROM
+        jobs
+    WHERE
+        job_id = :job_id`
+
+	var dbJob job
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbJob); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return jobbus.Job{}, fmt.Errorf("db: %w", jobbus.ErrNotFound)
+		}
+		return jobbus.Job{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusJob(dbJob)
+}
@@ -0,0 +1,74 @@
+package jobdb
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/jobbus"
+	"github.com/google/uuid"
+)
+
+type job struct {
+	ID          uuid.UUID      `db:"job_id"`
+	UserID      uuid.UUID      `db:"user_id"`
+	Kind        string         `db:"kind"`
+	Payload     string         `db:"payload"`
+	Status      string         `db:"status"`
+	Attempts    int            `db:"attempts"`
+	MaxAttempts int            `db:"max_attempts"`
+	Result      sql.NullString `db:"result"`
+	Error       sql.NullString `db:"error"`
+	DateCreated time.Time      `db:"date_created"`
+	DateUpdated time.Time      `db:"date_updated"`
+}
+
+func toDBJob(bus jobbus.Job) job {
+	return job{
+		ID:          bus.ID,
+		UserID:      bus.UserID,
+		Kind:        bus.Kind,
+		Payload:     string(bus.Payload),
+		Status:      bus.Status.String(),
+		Attempts:    bus.Attempts,
+		MaxAttempts: bus.MaxAttempts,
+		Result: sql.NullString{
+			String: string(bus.Result),
+			Valid:  len(bus.Result) > 0,
+		},
+		Error: sql.NullString{
+			String: bus.Error,
+			Valid:  bus.Error != "",
+		},
+		DateCreated: bus.DateCreated.UTC(),
+		DateUpdated: bus.DateUpdated.UTC(),
+	}
+}
+
+func toBusJob(db job) (jobbus.Job, error) {
+	status, err := jobbus.ParseStatus(db.Status)
+	if err != nil {
+		return jobbus.Job{}, fmt.Errorf("parse status: %w", err)
+	}
+
+	var result []byte
+	if db.Result.Valid {
+		result = []byte(db.Result.String)
+	}
+
+	bus := jobbus.Job{
+		ID:          db.ID,
+		UserID:      db.UserID,
+		Kind:        db.Kind,
+		Payload:     []byte(db.Payload),
+		Status:      status,
+		Attempts:    db.Attempts,
+		MaxAttempts: db.MaxAttempts,
+		Result:      result,
+		Error:       db.Error.String,
+		DateCreated: db.DateCreated.In(time.Local),
+		DateUpdated: db.DateUpdated.In(time.Local),
+	}
+
+	return bus, nil
+}
@@ -0,0 +1,46 @@
+package oauthbus
+
+import (
+	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// State represents a login that was started with a provider. The state is
+// sent to the provider and comes back with the user, which ties the two
+// halves of the login together. The nonce does the same for the ID token and
+// the PKCE verifier for the code. A state started by a user that is already
+// logged in links the provider's user to them instead of logging in.
+type State struct {
+	State       string
+	Provider    string
+	Nonce       string
+	Verifier    string
+	UserID      uuid.UUID
+	DateExpires time.Time
+}
+
+// Linking reports if the state links a user instead of logging one in.
+func (st State) Linking() bool {
+	return st.UserID != uuid.Nil
+}
+
+// Profile represents the user a provider logged in.
+type Profile struct {
+	Provider      string
+	Subject       string
+	Email         mail.Address
+	EmailVerified bool
+	Name          string
+}
+
+// Identity represents the link between a user of a provider and a user of
+// the system. A user with an identity can log in with the provider.
+type Identity struct {
+	Provider    string
+	Subject     string
+	UserID      uuid.UUID
+	Email       mail.Address
+	DateCreated time.Time
+}
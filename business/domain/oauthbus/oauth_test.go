@@ -0,0 +1,294 @@
+package oauthbus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"testing"
+	"time"
+
+	"encore.dev/et"
+	"github.com/ardanlabs/encore/business/domain/oauthbus"
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/sdk/dbtest"
+	"github.com/ardanlabs/encore/business/sdk/unitest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_OAuth(t *testing.T) {
+	t.Parallel()
+
+	edb, err := et.NewTestDatabase(context.Background(), "app")
+	if err != nil {
+		t.Fatalf("Creating new database: %s", err)
+	}
+
+	db := dbtest.NewDatabase(t, edb)
+
+	sd, err := insertSeedData(db)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, state(db), "state")
+	unitest.Run(t, login(db.BusDomain, sd), "login")
+	unitest.Run(t, link(db.BusDomain, sd), "link")
+}
+
+// =============================================================================
+
+type seedData struct {
+	user  userbus.User
+	admin userbus.User
+}
+
+func insertSeedData(db *dbtest.Database) (seedData, error) {
+	ctx, cancel := dbtest.Context()
+	defer cancel()
+
+	usrs, err := userbus.TestSeedUsers(ctx, db.Rand, 1, userbus.Roles.User, db.BusDomain.User)
+	if err != nil {
+		return seedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	admins, err := userbus.TestSeedUsers(ctx, db.Rand, 1, userbus.Roles.Admin, db.BusDomain.User)
+	if err != nil {
+		return seedData{}, fmt.Errorf("seeding admins : %w", err)
+	}
+
+	sd := seedData{
+		user:  usrs[0],
+		admin: admins[0],
+	}
+
+	return sd, nil
+}
+
+func newProfile(subject string, email mail.Address) oauthbus.Profile {
+	return oauthbus.Profile{
+		Provider:      "google",
+		Subject:       subject,
+		Email:         email,
+		EmailVerified: true,
+		Name:          "Bill Kennedy",
+	}
+}
+
+func cmpError(got any, exp any) string {
+	gotErr, _ := got.(error)
+	if !errors.Is(gotErr, exp.(error)) {
+		return fmt.Sprintf("got %v, exp %v", got, exp)
+	}
+
+	return ""
+}
+
+func state(db *dbtest.Database) []unitest.Table {
+	busDomain := db.BusDomain
+
+	table := []unitest.Table{
+		{
+			Name:    "consume",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				st, err := busDomain.OAuth.Start(ctx, "google")
+				if err != nil {
+					return err
+				}
+
+				got, err := busDomain.OAuth.Consume(ctx, st.State)
+				if err != nil {
+					return err
+				}
+
+				return got.Nonce == st.Nonce && got.Verifier == st.Verifier && got.Verifier != "" && !got.Linking()
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "replay",
+			ExpResp: oauthbus.ErrStateNotFound,
+			ExcFunc: func(ctx context.Context) any {
+				st, err := busDomain.OAuth.Start(ctx, "google")
+				if err != nil {
+					return err
+				}
+
+				if _, err := busDomain.OAuth.Consume(ctx, st.State); err != nil {
+					return err
+				}
+
+				_, err = busDomain.OAuth.Consume(ctx, st.State)
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+		{
+			Name:    "expired",
+			ExpResp: oauthbus.ErrStateExpired,
+			ExcFunc: func(ctx context.Context) any {
+				st, err := busDomain.OAuth.Start(ctx, "google")
+				if err != nil {
+					return err
+				}
+
+				db.Clock.Advance(time.Hour)
+
+				_, err = busDomain.OAuth.Consume(ctx, st.State)
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+	}
+
+	return table
+}
+
+func login(busDomain dbtest.BusDomain, sd seedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "email",
+			ExpResp: sd.user.ID,
+			ExcFunc: func(ctx context.Context) any {
+				usr, err := busDomain.OAuth.Login(ctx, newProfile("user", sd.user.Email), false)
+				if err != nil {
+					return err
+				}
+
+				return usr.ID
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "linked",
+			ExpResp: sd.user.ID,
+			ExcFunc: func(ctx context.Context) any {
+				prof := newProfile("user", mail.Address{Address: "changed@example.com"})
+				prof.EmailVerified = false
+
+				usr, err := busDomain.OAuth.Login(ctx, prof, false)
+				if err != nil {
+					return err
+				}
+
+				return usr.ID
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "elevated",
+			ExpResp: oauthbus.ErrLinkRequired,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.OAuth.Login(ctx, newProfile("admin", sd.admin.Email), true)
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+		{
+			Name:    "not-verified",
+			ExpResp: oauthbus.ErrEmailNotVerified,
+			ExcFunc: func(ctx context.Context) any {
+				prof := newProfile("not-verified", mail.Address{Address: "not-verified@example.com"})
+				prof.EmailVerified = false
+
+				_, err := busDomain.OAuth.Login(ctx, prof, true)
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+		{
+			Name:    "not-provisioned",
+			ExpResp: oauthbus.ErrNotProvisioned,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.OAuth.Login(ctx, newProfile("new", mail.Address{Address: "new@example.com"}), false)
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+		{
+			Name:    "provision",
+			ExpResp: []userbus.Role{userbus.Roles.User},
+			ExcFunc: func(ctx context.Context) any {
+				usr, err := busDomain.OAuth.Login(ctx, newProfile("new", mail.Address{Address: "new@example.com"}), true)
+				if err != nil {
+					return err
+				}
+
+				return usr.Roles
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp, cmp.Comparer(userbus.Role.Equal))
+			},
+		},
+	}
+
+	return table
+}
+
+func link(busDomain dbtest.BusDomain, sd seedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "scope",
+			ExpResp: oauthbus.ErrLinkScope,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.OAuth.StartLink(ctx, "google", sd.admin.ID, []userbus.Role{userbus.Roles.User})
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+		{
+			Name:    "link",
+			ExpResp: sd.admin.ID,
+			ExcFunc: func(ctx context.Context) any {
+				st, err := busDomain.OAuth.StartLink(ctx, "google", sd.admin.ID, sd.admin.Roles)
+				if err != nil {
+					return err
+				}
+
+				st, err = busDomain.OAuth.Consume(ctx, st.State)
+				if err != nil {
+					return err
+				}
+
+				if !st.Linking() {
+					return errors.New("should link the user")
+				}
+
+				if _, err := busDomain.OAuth.Link(ctx, newProfile("admin", sd.admin.Email), st.UserID); err != nil {
+					return err
+				}
+
+				// The admin can log in with the provider once it's linked.
+				usr, err := busDomain.OAuth.Login(ctx, newProfile("admin", sd.admin.Email), false)
+				if err != nil {
+					return err
+				}
+
+				return usr.ID
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "other-user",
+			ExpResp: oauthbus.ErrIdentityLinked,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.OAuth.Link(ctx, newProfile("admin", sd.admin.Email), sd.user.ID)
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+	}
+
+	return table
+}
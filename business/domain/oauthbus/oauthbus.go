@@ -0,0 +1,318 @@
+// Package oauthbus provides business access to logging users in with an
+// external identity provider. It keeps the state of the logins in flight and
+// links the users of the providers to the users of the system, provisioning
+// a new user the first time someone logs in when that's allowed.
+package oauthbus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/foundation/clock"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for logging in with a provider.
+var (
+	ErrStateNotFound    = errors.New("login state not found")
+	ErrStateExpired     = errors.New("login state expired")
+	ErrIdentityNotFound = errors.New("identity not found")
+	ErrEmailNotVerified = errors.New("email not verified by the provider")
+	ErrNotProvisioned   = errors.New("no user for the identity")
+	ErrUserDisabled     = errors.New("user disabled")
+	ErrLinkRequired     = errors.New("identity has to be linked by the user")
+	ErrIdentityLinked   = errors.New("identity linked to another user")
+	ErrLinkScope        = errors.New("linking needs every role of the user")
+)
+
+// stateTTL is how long a user has to log in with the provider.
+const stateTTL = 10 * time.Minute
+
+// Storer interface declares the behaviour this package needs to persist and
+// retrieve data.
+type Storer interface {
+	CreateState(ctx context.Context, st State) error
+	ConsumeState(ctx context.Context, state string) (State, error)
+	DeleteStatesBefore(ctx context.Context, before time.Time) (int, error)
+	CreateIdentity(ctx context.Context, ident Identity) error
+	QueryIdentity(ctx context.Context, provider string, subject string) (Identity, error)
+}
+
+// Business manages the set of APIs for logging in with a provider.
+type Business struct {
+	log     *logger.Logger
+	clock   clock.Clock
+	userBus *userbus.Business
+	storer  Storer
+}
+
+// NewBusiness constructs an oauth business API for use.
+func NewBusiness(log *logger.Logger, clock clock.Clock, userBus *userbus.Business, storer Storer) *Business {
+	return &Business{
+		log:     log,
+		clock:   clock,
+		userBus: userBus,
+		storer:  storer,
+	}
+}
+
+// Start begins a login with the provider, returning the state, nonce and
+// verifier to send along with the user.
+func (b *Business) Start(ctx context.Context, provider string) (State, error) {
+	return b.start(ctx, provider, uuid.Nil)
+}
+
+// StartLink begins linking the user to who they are with the provider. The
+// caller has to hold every role of the user, since the user can log in with
+// the provider once it's linked. A caller scoped to fewer roles, like with an
+// API key, would gain the rest otherwise.
+func (b *Business) StartLink(ctx context.Context, provider string, userID uuid.UUID, scope []userbus.Role) (State, error) {
+	usr, err := b.userBus.QueryByID(ctx, userID)
+	if err != nil {
+		return State{}, fmt.Errorf("querybyid: userID[%s]: %w", userID, err)
+	}
+
+	if _, err := enabled(usr); err != nil {
+		return State{}, err
+	}
+
+	for _, role := range usr.Roles {
+		if !slices.ContainsFunc(scope, role.Equal) {
+			return State{}, fmt.Errorf("startlink: userID[%s] role[%s]: %w", userID, role, ErrLinkScope)
+		}
+	}
+
+	return b.start(ctx, provider, userID)
+}
+
+// Consume returns the login the state was issued for. A state can only be
+// consumed once, so a login can't be replayed.
+func (b *Business) Consume(ctx context.Context, state string) (State, error) {
+	st, err := b.storer.ConsumeState(ctx, state)
+	if err != nil {
+		return State{}, fmt.Errorf("consumestate: %w", err)
+	}
+
+	if !b.clock.Now().Before(st.DateExpires) {
+		return State{}, fmt.Errorf("consume: provider[%s]: %w", st.Provider, ErrStateExpired)
+	}
+
+	return st, nil
+}
+
+// Login returns the user the profile is linked to. A profile that isn't
+// linked yet is linked to the user with the same email, as long as the
+// provider verified the email and the user only has the user role. Anyone
+// with more has to link the profile themselves with StartLink. When there is
+// no user with the email one is provisioned with the user role if provision
+// is set, otherwise ErrNotProvisioned is returned.
+func (b *Business) Login(ctx context.Context, prof Profile, provision bool) (userbus.User, error) {
+	ident, err := b.storer.QueryIdentity(ctx, prof.Provider, prof.Subject)
+	switch {
+	case err == nil:
+		usr, err := b.userBus.QueryByID(ctx, ident.UserID)
+		if err != nil {
+			return userbus.User{}, fmt.Errorf("querybyid: userID[%s]: %w", ident.UserID, err)
+		}
+
+		return enabled(usr)
+
+	case !errors.Is(err, ErrIdentityNotFound):
+		return userbus.User{}, fmt.Errorf("queryidentity: %w", err)
+	}
+
+	// Linking by email hands the account over to whoever the provider says
+	// owns the email, so the provider has to have verified it.
+	if !prof.EmailVerified {
+		return userbus.User{}, fmt.Errorf("login: provider[%s] subject[%s]: %w", prof.Provider, prof.Subject, ErrEmailNotVerified)
+	}
+
+	usr, err := b.userBus.QueryByEmail(ctx, prof.Email)
+	switch {
+	case errors.Is(err, userbus.ErrNotFound):
+		if !provision {
+			return userbus.User{}, fmt.Errorf("login: provider[%s] subject[%s]: %w", prof.Provider, prof.Subject, ErrNotProvisioned)
+		}
+
+		if usr, err = b.provision(ctx, prof); err != nil {
+			return userbus.User{}, fmt.Errorf("provision: %w", err)
+		}
+
+	case err != nil:
+		return userbus.User{}, fmt.Errorf("querybyemail: %w", err)
+
+	case slices.ContainsFunc(usr.Roles, elevated):
+		return userbus.User{}, fmt.Errorf("login: provider[%s] subject[%s] userID[%s]: %w", prof.Provider, prof.Subject, usr.ID, ErrLinkRequired)
+	}
+
+	if err := b.link(ctx, prof, usr.ID); err != nil {
+		return userbus.User{}, err
+	}
+
+	return enabled(usr)
+}
+
+// Link links the profile to the user that started linking it with StartLink
+// and returns the user. Linking a profile that is already linked to the user
+// does nothing.
+func (b *Business) Link(ctx context.Context, prof Profile, userID uuid.UUID) (userbus.User, error) {
+	ident, err := b.storer.QueryIdentity(ctx, prof.Provider, prof.Subject)
+	switch {
+	case err == nil:
+		if ident.UserID != userID {
+			return userbus.User{}, fmt.Errorf("link: provider[%s] subject[%s]: %w", prof.Provider, prof.Subject, ErrIdentityLinked)
+		}
+
+	case errors.Is(err, ErrIdentityNotFound):
+		if err := b.link(ctx, prof, userID); err != nil {
+			return userbus.User{}, err
+		}
+
+	default:
+		return userbus.User{}, fmt.Errorf("queryidentity: %w", err)
+	}
+
+	usr, err := b.userBus.QueryByID(ctx, userID)
+	if err != nil {
+		return userbus.User{}, fmt.Errorf("querybyid: userID[%s]: %w", userID, err)
+	}
+
+	return enabled(usr)
+}
+
+// DeleteExpired removes the states of the logins that were never finished
+// and returns the number removed.
+func (b *Business) DeleteExpired(ctx context.Context) (int, error) {
+	n, err := b.storer.DeleteStatesBefore(ctx, b.clock.Now())
+	if err != nil {
+		return 0, fmt.Errorf("deletestatesbefore: %w", err)
+	}
+
+	return n, nil
+}
+
+// =============================================================================
+
+// start stores the state of a new login, which links the user when one is
+// specified.
+func (b *Business) start(ctx context.Context, provider string, userID uuid.UUID) (State, error) {
+	state, err := generate()
+	if err != nil {
+		return State{}, fmt.Errorf("generate state: %w", err)
+	}
+
+	nonce, err := generate()
+	if err != nil {
+		return State{}, fmt.Errorf("generate nonce: %w", err)
+	}
+
+	verifier, err := generate()
+	if err != nil {
+		return State{}, fmt.Errorf("generate verifier: %w", err)
+	}
+
+	st := State{
+		State:       state,
+		Provider:    provider,
+		Nonce:       nonce,
+		Verifier:    verifier,
+		UserID:      userID,
+		DateExpires: b.clock.Now().Add(stateTTL),
+	}
+
+	if err := b.storer.CreateState(ctx, st); err != nil {
+		return State{}, fmt.Errorf("createstate: %w", err)
+	}
+
+	return st, nil
+}
+
+// link stores the identity that links the profile to the user.
+func (b *Business) link(ctx context.Context, prof Profile, userID uuid.UUID) error {
+	ident := Identity{
+		Provider:    prof.Provider,
+		Subject:     prof.Subject,
+		UserID:      userID,
+		Email:       prof.Email,
+		DateCreated: b.clock.Now(),
+	}
+
+	if err := b.storer.CreateIdentity(ctx, ident); err != nil {
+		return fmt.Errorf("createidentity: %w", err)
+	}
+
+	b.log.Info(ctx, "oauth identity linked", "provider", prof.Provider, "subject", prof.Subject, "userID", userID)
+
+	return nil
+}
+
+// provision creates a user for the profile. The user can only log in with
+// the provider until a password is set, since the one it's created with is
+// random and never returned.
+func (b *Business) provision(ctx context.Context, prof Profile) (userbus.User, error) {
+	// The names providers have don't always fit the rules for a name, so
+	// the local part of the email is tried next, and a placeholder the user
+	// can change later after that.
+	local, _, _ := strings.Cut(prof.Email.Address, "@")
+
+	name := userbus.MustParseName("New User")
+	for _, candidate := range []string{prof.Name, local} {
+		if n, err := userbus.ParseName(candidate); err == nil {
+			name = n
+			break
+		}
+	}
+
+	password, err := generate()
+	if err != nil {
+		return userbus.User{}, fmt.Errorf("generate password: %w", err)
+	}
+
+	nu := userbus.NewUser{
+		Name:     name,
+		Email:    prof.Email,
+		Roles:    []userbus.Role{userbus.Roles.User},
+		Password: password,
+	}
+
+	usr, err := b.userBus.Create(ctx, nu)
+	if err != nil {
+		return userbus.User{}, fmt.Errorf("create: %w", err)
+	}
+
+	b.log.Info(ctx, "oauth user provisioned", "provider", prof.Provider, "subject", prof.Subject, "userID", usr.ID)
+
+	return usr, nil
+}
+
+// elevated reports if the role grants more than the user role.
+func elevated(role userbus.Role) bool {
+	return !role.Equal(userbus.Roles.User)
+}
+
+// enabled returns the user when it's enabled.
+func enabled(usr userbus.User) (userbus.User, error) {
+	if !usr.Enabled {
+		return userbus.User{}, fmt.Errorf("login: userID[%s]: %w", usr.ID, ErrUserDisabled)
+	}
+
+	return usr, nil
+}
+
+// generate returns a random value that is safe to use in a url.
+func generate() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,73 @@
+package oauthdb
+
+import (
+	"net/mail"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/oauthbus"
+	"github.com/google/uuid"
+)
+
+type oauthState struct {
+	State       string        `db:"state"`
+	Provider    string        `db:"provider"`
+	Nonce       string        `db:"nonce"`
+	Verifier    string        `db:"verifier"`
+	UserID      uuid.NullUUID `db:"user_id"`
+	DateExpires time.Time     `db:"date_expires"`
+}
+
+func toDBState(bus oauthbus.State) oauthState {
+	return oauthState{
+		State:    bus.State,
+		Provider: bus.Provider,
+		Nonce:    bus.Nonce,
+		Verifier: bus.Verifier,
+		UserID: uuid.NullUUID{
+			UUID:  bus.UserID,
+			Valid: bus.Linking(),
+		},
+		DateExpires: bus.DateExpires.UTC(),
+	}
+}
+
+func toBusState(db oauthState) oauthbus.State {
+	return oauthbus.State{
+		State:       db.State,
+		Provider:    db.Provider,
+		Nonce:       db.Nonce,
+		Verifier:    db.Verifier,
+		UserID:      db.UserID.UUID,
+		DateExpires: db.DateExpires.In(time.Local),
+	}
+}
+
+// =============================================================================
+
+type identity struct {
+	Provider    string    `db:"provider"`
+	Subject     string    `db:"subject"`
+	UserID      uuid.UUID `db:"user_id"`
+	Email       string    `db:"email"`
+	DateCreated time.Time `db:"date_created"`
+}
+
+func toDBIdentity(bus oauthbus.Identity) identity {
+	return identity{
+		Provider:    bus.Provider,
+		Subject:     bus.Subject,
+		UserID:      bus.UserID,
+		Email:       bus.Email.Address,
+		DateCreated: bus.DateCreated.UTC(),
+	}
+}
+
+func toBusIdentity(db identity) oauthbus.Identity {
+	return oauthbus.Identity{
+		Provider:    db.Provider,
+		Subject:     db.Subject,
+		UserID:      db.UserID,
+		Email:       mail.Address{Address: db.Email},
+		DateCreated: db.DateCreated.In(time.Local),
+	}
+}
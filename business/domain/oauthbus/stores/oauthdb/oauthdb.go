@@ -0,0 +1,148 @@
+// Package oauthdb contains oauth login related CRUD functionality.
+package oauthdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/oauthbus"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for oauth login database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// CreateState inserts the state of a new login into the database.
+func (s *Store) CreateState(ctx context.Context, st oauthbus.State) error {
+	const q = `
+    INSERT INTO oauth_states
+        (state, provider, nonce, verifier, user_id, date_expires)
+    VALUES
+        (:state, :provider, :nonce, :verifier, :user_id, :date_expires)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBState(st)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeState removes the state from the database and returns it, so only
+// one caller can consume a state.
+func (s *Store) ConsumeState(ctx context.Context, state string) (oauthbus.State, error) {
+	data := struct {
+		State string `db:"state"`
+	}{
+		State: state,
+	}
+
+	const q = `
+    DELETE FROM
+        oauth_states
+    WHERE
+        state = :state
+    RETURNING
+        state, provider, nonce, verifier, user_id, date_expires`
+
+	var dbState oauthState
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbState); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return oauthbus.State{}, fmt.Errorf("db: %w", oauthbus.ErrStateNotFound)
+		}
+		return oauthbus.State{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusState(dbState), nil
+}
+
+// DeleteStatesBefore removes the states that expired before the specified
+// time and returns the number removed.
+func (s *Store) DeleteStatesBefore(ctx context.Context, before time.Time) (int, error) {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before.UTC(),
+	}
+
+	const q = `
+    WITH deleted AS (
+        DELETE FROM
+            oauth_states
+        WHERE
+            date_expires < :before
+        RETURNING 1
+    )
+    SELECT
+        count(1)
+    FROM
+        deleted`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// CreateIdentity inserts a new identity into the database.
+func (s *Store) CreateIdentity(ctx context.Context, ident oauthbus.Identity) error {
+	const q = `
+    INSERT INTO user_identities
+        (provider, subject, user_id, email, date_created)
+    VALUES
+        (:provider, :subject, :user_id, :email, :date_created)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBIdentity(ident)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryIdentity gets the identity of the provider's user from the database.
+func (s *Store) QueryIdentity(ctx context.Context, provider string, subject string) (oauthbus.Identity, error) {
+	data := struct {
+		Provider string `db:"provider"`
+		Subject  string `db:"subject"`
+	}{
+		Provider: provider,
+		Subject:  subject,
+	}
+
+	const q = `
+    SELECT
+        provider, subject, user_id, email, date_created
+    FROM
+        user_identities
+    WHERE
+        provider = :provider AND
+        subject = :subject`
+
+	var dbIdent identity
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbIdent); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return oauthbus.Identity{}, fmt.Errorf("db: %w", oauthbus.ErrIdentityNotFound)
+		}
+		return oauthbus.Identity{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusIdentity(dbIdent), nil
+}
@@ -0,0 +1,11 @@
+package permissionbus
+
+import (
+	"github.com/ardanlabs/encore/business/domain/userbus"
+)
+
+// RolePermissions represents the permissions granted to a role.
+type RolePermissions struct {
+	Role        userbus.Role
+	Permissions []Permission
+}
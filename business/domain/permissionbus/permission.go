@@ -0,0 +1,76 @@
+package permissionbus
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Permission represents an action that can be taken on a resource, like
+// product:write. The action can be * to grant every action on the resource,
+// and the permission * grants everything.
+type Permission struct {
+	value string
+}
+
+// String returns the value of the permission.
+func (p Permission) String() string {
+	return p.value
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (p Permission) Equal(p2 Permission) bool {
+	return p.value == p2.value
+}
+
+// Grants reports if holding the permission allows the required permission.
+func (p Permission) Grants(required Permission) bool {
+	if p.value == "*" || p.value == required.value {
+		return true
+	}
+
+	resource, action, _ := strings.Cut(p.value, ":")
+	if action != "*" {
+		return false
+	}
+
+	reqResource, _, _ := strings.Cut(required.value, ":")
+
+	return resource == reqResource
+}
+
+// =============================================================================
+
+var validPermission = regexp.MustCompile(`^(\*|[a-z][a-z_]*:(\*|[a-z][a-z_]*))$`)
+
+// ParsePermission parses the string value and returns a permission if the
+// value is of the form resource:action.
+func ParsePermission(value string) (Permission, error) {
+	if !validPermission.MatchString(value) {
+		return Permission{}, fmt.Errorf("invalid permission %q", value)
+	}
+
+	return Permission{value}, nil
+}
+
+// MustParsePermission parses the string value and returns a permission. If
+// an error occurs the function panics.
+func MustParsePermission(value string) Permission {
+	p, err := ParsePermission(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+// ParsePermissionsToString takes a collection of permissions and converts
+// them to a slice of string.
+func ParsePermissionsToString(perms []Permission) []string {
+	values := make([]string, len(perms))
+	for i, p := range perms {
+		values[i] = p.String()
+	}
+
+	return values
+}
@@ -0,0 +1,86 @@
+// Package permissionbus provides business access to the permissions granted
+// to each role, so access can be checked for an action on a resource instead
+// of only by role.
+package permissionbus
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/foundation/logger"
+)
+
+// Storer interface declares the behaviour this package needs to persist and
+// retrieve data.
+type Storer interface {
+	Grant(ctx context.Context, role userbus.Role, perm Permission) error
+	Revoke(ctx context.Context, role userbus.Role, perm Permission) error
+	Query(ctx context.Context) ([]RolePermissions, error)
+	QueryByRole(ctx context.Context, role userbus.Role) (RolePermissions, error)
+}
+
+// Business manages the set of APIs for permission access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs a permission business API for use.
+func NewBusiness(log *logger.Logger, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// Grant grants the permission to the role. Granting a permission the role
+// already has does nothing.
+func (b *Business) Grant(ctx context.Context, role userbus.Role, perm Permission) error {
+	if err := b.storer.Grant(ctx, role, perm); err != nil {
+		return fmt.Errorf("grant: role[%s] permission[%s]: %w", role, perm, err)
+	}
+
+	return nil
+}
+
+// Revoke takes the permission away from the role.
+func (b *Business) Revoke(ctx context.Context, role userbus.Role, perm Permission) error {
+	if err := b.storer.Revoke(ctx, role, perm); err != nil {
+		return fmt.Errorf("revoke: role[%s] permission[%s]: %w", role, perm, err)
+	}
+
+	return nil
+}
+
+// Query returns the permissions granted to every role that has any.
+func (b *Business) Query(ctx context.Context) ([]RolePermissions, error) {
+	rps, err := b.storer.Query(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return rps, nil
+}
+
+// QueryByRoles returns the permissions granted to any of the roles, each
+// listed once.
+func (b *Business) QueryByRoles(ctx context.Context, roles []userbus.Role) ([]Permission, error) {
+	var perms []Permission
+
+	for _, role := range roles {
+		rp, err := b.storer.QueryByRole(ctx, role)
+		if err != nil {
+			return nil, fmt.Errorf("query: role[%s]: %w", role, err)
+		}
+
+		for _, perm := range rp.Permissions {
+			if !slices.ContainsFunc(perms, perm.Equal) {
+				perms = append(perms, perm)
+			}
+		}
+	}
+
+	return perms, nil
+}
@@ -0,0 +1,88 @@
+// Package permissioncache contains permission related CRUD functionality
+// with caching.
+package permissioncache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ardanlabs/encore/business/domain/permissionbus"
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/sdk/cachemetrics"
+	"github.com/ardanlabs/encore/business/sdk/storecache"
+	"github.com/ardanlabs/encore/foundation/clock"
+	"github.com/ardanlabs/encore/foundation/logger"
+)
+
+// Store manages the set of APIs for permission data and caching.
+type Store struct {
+	log    *logger.Logger
+	storer permissionbus.Storer
+	cache  *storecache.Cache[permissionbus.RolePermissions]
+}
+
+// CacheName is the name the cache reports its metrics with.
+const CacheName = "permissions"
+
+// errNotFound is never returned by the store, since a role without
+// permissions has an empty list.
+var errNotFound = errors.New("permissions not found")
+
+// NewStore constructs the api for data and caching access. The permissions
+// are cached by the role they are granted to. A change made on another
+// instance is only seen once the cached role expires.
+func NewStore(log *logger.Logger, storer permissionbus.Storer, cfg storecache.Config, clock clock.Clock, counters cachemetrics.Counters) *Store {
+	entity := storecache.Entity[permissionbus.RolePermissions]{
+		Name:     CacheName,
+		NotFound: errNotFound,
+		ID:       func(rp permissionbus.RolePermissions) string { return rp.Role.String() },
+	}
+
+	return &Store{
+		log:    log,
+		storer: storer,
+		cache:  storecache.New(entity, cfg, clock, counters),
+	}
+}
+
+// Stats returns what the cache has done with the top number of roles it
+// has served the most.
+func (s *Store) Stats(top int) cachemetrics.Stats {
+	return s.cache.Stats(top)
+}
+
+// Grant inserts the permission for the role. The cached permissions of the
+// role are dropped so they're read again with the grant.
+func (s *Store) Grant(ctx context.Context, role userbus.Role, perm permissionbus.Permission) error {
+	if err := s.storer.Grant(ctx, role, perm); err != nil {
+		return err
+	}
+
+	s.cache.Delete(permissionbus.RolePermissions{Role: role})
+
+	return nil
+}
+
+// Revoke removes the permission for the role. The cached permissions of the
+// role are dropped so they're read again without it.
+func (s *Store) Revoke(ctx context.Context, role userbus.Role, perm permissionbus.Permission) error {
+	if err := s.storer.Revoke(ctx, role, perm); err != nil {
+		return err
+	}
+
+	s.cache.Delete(permissionbus.RolePermissions{Role: role})
+
+	return nil
+}
+
+// Query gets the permissions of every role.
+func (s *Store) Query(ctx context.Context) ([]permissionbus.RolePermissions, error) {
+	return s.storer.Query(ctx)
+}
+
+// QueryByRole gets the permissions of the role.
+func (s *Store) QueryByRole(ctx context.Context, role userbus.Role) (permissionbus.RolePermissions, error) {
+	return s.cache.Get(ctx, role.String(), func(ctx context.Context) (permissionbus.RolePermissions, error) {
+		return s.storer.QueryByRole(ctx, role)
+	})
+}
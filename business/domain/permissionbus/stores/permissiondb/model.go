@@ -0,0 +1,50 @@
+package permissiondb
+
+import (
+	"fmt"
+
+	"github.com/ardanlabs/encore/business/domain/permissionbus"
+	"github.com/ardanlabs/encore/business/domain/userbus"
+)
+
+type rolePermission struct {
+	Role       string `db:"role"`
+	Permission string `db:"permission"`
+}
+
+func toDBRolePermission(role userbus.Role, perm permissionbus.Permission) rolePermission {
+	return rolePermission{
+		Role:       role.String(),
+		Permission: perm.String(),
+	}
+}
+
+// toBusRolePermissions groups the rows by role, keeping the order the roles
+// are first seen in.
+func toBusRolePermissions(dbs []rolePermission) ([]permissionbus.RolePermissions, error) {
+	var bus []permissionbus.RolePermissions
+	index := make(map[string]int)
+
+	for _, db := range dbs {
+		perm, err := permissionbus.ParsePermission(db.Permission)
+		if err != nil {
+			return nil, fmt.Errorf("parse permission: %w", err)
+		}
+
+		i, exists := index[db.Role]
+		if !exists {
+			role, err := userbus.ParseRole(db.Role)
+			if err != nil {
+				return nil, fmt.Errorf("parse role: %w", err)
+			}
+
+			i = len(bus)
+			index[db.Role] = i
+			bus = append(bus, permissionbus.RolePermissions{Role: role})
+		}
+
+		bus[i].Permissions = append(bus[i].Permissions, perm)
+	}
+
+	return bus, nil
+}
@@ -0,0 +1,113 @@
+// Package permissiondb contains permission related CRUD functionality.
+package permissiondb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ardanlabs/encore/business/domain/permissionbus"
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for permission database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Grant inserts the permission for the role unless the role already has it.
+func (s *Store) Grant(ctx context.Context, role userbus.Role, perm permissionbus.Permission) error {
+	const q = `
+    INSERT INTO role_permissions
+        (role, permission)
+    VALUES
+        (:role, :permission)
+    ON CONFLICT (role, permission) DO NOTHING`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBRolePermission(role, perm)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Revoke removes the permission for the role.
+func (s *Store) Revoke(ctx context.Context, role userbus.Role, perm permissionbus.Permission) error {
+	const q = `
+    DELETE FROM
+        role_permissions
+    WHERE
+        role = :role AND
+        permission = :permission`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBRolePermission(role, perm)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query gets the permissions of every role from the database.
+func (s *Store) Query(ctx context.Context) ([]permissionbus.RolePermissions, error) {
+	const q = `
+    SELECT
+        role, permission
+    FROM
+        role_permissions
+    ORDER BY
+        role, permission`
+
+	var dbRPs []rolePermission
+	if err := sqldb.QuerySlice(ctx, s.log, s.db, q, &dbRPs); err != nil {
+		return nil, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusRolePermissions(dbRPs)
+}
+
+// QueryByRole gets the permissions of the role from the database. A role
+// without permissions has an empty list.
+func (s *Store) QueryByRole(ctx context.Context, role userbus.Role) (permissionbus.RolePermissions, error) {
+	data := struct {
+		Role string `db:"role"`
+	}{
+		Role: role.String(),
+	}
+
+	const q = `
+    SELECT
+        role, permission
+    FROM
+        role_permissions
+    WHERE
+        role = :role
+    ORDER BY
+        permission`
+
+	var dbRPs []rolePermission
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbRPs); err != nil {
+		return permissionbus.RolePermissions{}, fmt.Errorf("db: %w", err)
+	}
+
+	rps, err := toBusRolePermissions(dbRPs)
+	if err != nil {
+		return permissionbus.RolePermissions{}, err
+	}
+
+	if len(rps) == 0 {
+		return permissionbus.RolePermissions{Role: role}, nil
+	}
+
+	return rps[0], nil
+}
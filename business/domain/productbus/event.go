@@ -7,8 +7,56 @@ import (
 
 	"github.com/ardanlabs/encore/business/domain/userbus"
 	"github.com/ardanlabs/encore/business/sdk/delegate"
+	"github.com/google/uuid"
 )
 
+// DomainName represents the name of this domain.
+const DomainName = "product"
+
+// Set of delegate actions.
+const (
+	ActionCreated = "created"
+	ActionUpdated = "updated"
+	ActionDeleted = "deleted"
+)
+
+// ActionParms represents the parameters for the product actions.
+type ActionParms struct {
+	ProductID uuid.UUID
+	UserID    uuid.UUID
+}
+
+// String returns a string representation of the action parameters.
+func (ap *ActionParms) String() string {
+	return fmt.Sprintf("&EventParams{ProductID:%v, UserID:%v}", ap.ProductID, ap.UserID)
+}
+
+// Marshal returns the event parameters encoded as JSON.
+func (ap *ActionParms) Marshal() ([]byte, error) {
+	return json.Marshal(ap)
+}
+
+// ActionData constructs the data for the specified action on the product.
+func ActionData(action string, prd Product) delegate.Data {
+	params := ActionParms{
+		ProductID: prd.ID,
+		UserID:    prd.UserID,
+	}
+
+	rawParams, err := params.Marshal()
+	if err != nil {
+		panic(err)
+	}
+
+	return delegate.Data{
+		Domain:    DomainName,
+		Action:    action,
+		RawParams: rawParams,
+	}
+}
+
+// =============================================================================
+
 // registerDelegateFunctions will register action functions with the delegate
 // system. If the business was constructed for query only, there won't be a
 // delegate provided.
@@ -1,6 +1,7 @@
 package productbus
 
 import (
+	"github.com/ardanlabs/encore/business/sdk/where"
 	"github.com/google/uuid"
 )
 
@@ -9,6 +10,6 @@ import (
 type QueryFilter struct {
 	ID       *uuid.UUID
 	Name     *Name
-	Cost     *float64
-	Quantity *int
+	Cost     []where.Cond[float64]
+	Quantity []where.Cond[int]
 }
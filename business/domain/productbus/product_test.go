@@ -2,6 +2,7 @@ package productbus_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"testing"
@@ -9,7 +10,6 @@ import (
 
 	"encore.dev/et"
 	"github.com/ardanlabs/encore/business/domain/productbus"
-	"github.com/ardanlabs/encore/business/domain/userbus"
 	"github.com/ardanlabs/encore/business/sdk/dbtest"
 	"github.com/ardanlabs/encore/business/sdk/page"
 	"github.com/ardanlabs/encore/business/sdk/unitest"
@@ -26,10 +26,7 @@ func Test_Product(t *testing.T) {
 
 	db := dbtest.NewDatabase(t, edb)
 
-	sd, err := insertSeedData(db.BusDomain)
-	if err != nil {
-		t.Fatalf("Seeding error: %s", err)
-	}
+	sd := db.LoadFixtures(t, "fixtures/seed.yaml")
 
 	// -------------------------------------------------------------------------
 
@@ -37,53 +34,21 @@ func Test_Product(t *testing.T) {
 	unitest.Run(t, create(db.BusDomain, sd), "create")
 	unitest.Run(t, update(db.BusDomain, sd), "update")
 	unitest.Run(t, delete(db.BusDomain, sd), "delete")
-}
-
-// =============================================================================
-
-func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
-	ctx := context.Background()
 
-	usrs, err := userbus.TestSeedUsers(ctx, 1, userbus.Roles.User, busDomain.User)
-	if err != nil {
-		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
-	}
-
-	prds, err := productbus.TestGenerateSeedProducts(ctx, 2, busDomain.Product, usrs[0].ID)
-	if err != nil {
-		return unitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
-	}
-
-	tu1 := unitest.User{
-		User:     usrs[0],
-		Products: prds,
-	}
-
-	// -------------------------------------------------------------------------
-
-	usrs, err = userbus.TestSeedUsers(ctx, 1, userbus.Roles.Admin, busDomain.User)
-	if err != nil {
-		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
-	}
-
-	prds, err = productbus.TestGenerateSeedProducts(ctx, 2, busDomain.Product, usrs[0].ID)
-	if err != nil {
-		return unitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
-	}
-
-	tu2 := unitest.User{
-		User:     usrs[0],
-		Products: prds,
-	}
-
-	// -------------------------------------------------------------------------
-
-	sd := unitest.SeedData{
-		Admins: []unitest.User{tu2},
-		Users:  []unitest.User{tu1},
-	}
-
-	return sd, nil
+	dbtest.CheckContext(t, "context", map[string]func(ctx context.Context) error{
+		"query": func(ctx context.Context) error {
+			_, err := db.BusDomain.Product.Query(ctx, productbus.QueryFilter{}, productbus.DefaultOrderBy, page.MustParse("1", "10"))
+			return err
+		},
+		"count": func(ctx context.Context) error {
+			_, err := db.BusDomain.Product.Count(ctx, productbus.QueryFilter{})
+			return err
+		},
+		"querybyid": func(ctx context.Context) error {
+			_, err := db.BusDomain.Product.QueryByID(ctx, sd.Users[0].Products[0].ID)
+			return err
+		},
+	})
 }
 
 // =============================================================================
@@ -134,6 +99,45 @@ func query(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 				return cmp.Diff(gotResp, expResp)
 			},
 		},
+		{
+			Name:    "stream",
+			ExpResp: prds,
+			ExcFunc: func(ctx context.Context) any {
+				filter := productbus.QueryFilter{
+					Name: dbtest.ProductNamePointer("Name"),
+				}
+
+				var resp []productbus.Product
+				for prd, err := range busDomain.Product.QueryStream(ctx, filter, productbus.DefaultOrderBy) {
+					if err != nil {
+						return err
+					}
+					resp = append(resp, prd)
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.([]productbus.Product)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.([]productbus.Product)
+
+				for i := range gotResp {
+					if gotResp[i].DateCreated.Format(time.RFC3339) == expResp[i].DateCreated.Format(time.RFC3339) {
+						expResp[i].DateCreated = gotResp[i].DateCreated
+					}
+
+					if gotResp[i].DateUpdated.Format(time.RFC3339) == expResp[i].DateUpdated.Format(time.RFC3339) {
+						expResp[i].DateUpdated = gotResp[i].DateUpdated
+					}
+				}
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
 		{
 			Name:    "byid",
 			ExpResp: sd.Users[0].Products[0],
@@ -254,6 +258,25 @@ func update(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 				return cmp.Diff(gotResp, expResp)
 			},
 		},
+		{
+			Name:    "modified",
+			ExpResp: productbus.ErrModified,
+			ExcFunc: func(ctx context.Context) any {
+
+				// The seeded product was changed by the basic update, so this
+				// copy is out of date.
+				_, err := busDomain.Product.Update(ctx, sd.Users[0].Products[0], productbus.UpdateProduct{Quantity: dbtest.IntPointer(1)})
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotErr, _ := got.(error)
+				if !errors.Is(gotErr, exp.(error)) {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+
+				return ""
+			},
+		},
 	}
 
 	return table
@@ -289,6 +312,21 @@ func delete(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 				return cmp.Diff(got, exp)
 			},
 		},
+		{
+			Name:    "modified",
+			ExpResp: productbus.ErrModified,
+			ExcFunc: func(ctx context.Context) any {
+				return busDomain.Product.Delete(ctx, sd.Users[0].Products[0])
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotErr, _ := got.(error)
+				if !errors.Is(gotErr, exp.(error)) {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+
+				return ""
+			},
+		},
 	}
 
 	return table
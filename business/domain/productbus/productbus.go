@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"iter"
 	"time"
 
 	"github.com/ardanlabs/encore/business/domain/userbus"
@@ -12,8 +13,11 @@ import (
 	"github.com/ardanlabs/encore/business/sdk/order"
 	"github.com/ardanlabs/encore/business/sdk/page"
 	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/foundation/clock"
 	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/ardanlabs/encore/foundation/otel"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Set of error variables for CRUD operations.
@@ -21,6 +25,7 @@ var (
 	ErrNotFound     = errors.New("product not found")
 	ErrUserDisabled = errors.New("user disabled")
 	ErrInvalidCost  = errors.New("cost not valid")
+	ErrModified     = errors.New("product has been modified")
 )
 
 // Storer interface declares the behavior this package needs to perists and
@@ -28,9 +33,11 @@ var (
 type Storer interface {
 	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
 	Create(ctx context.Context, prd Product) error
-	Update(ctx context.Context, prd Product) error
+	Update(ctx context.Context, prd Product, lastUpdated time.Time) error
 	Delete(ctx context.Context, prd Product) error
 	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Product, error)
+	QueryByKeyset(ctx context.Context, filter QueryFilter, keyset page.Keyset) ([]Product, error)
+	QueryStream(ctx context.Context, filter QueryFilter, orderBy order.By) iter.Seq2[Product, error]
 	Count(ctx context.Context, filter QueryFilter) (int, error)
 	QueryByID(ctx context.Context, productID uuid.UUID) (Product, error)
 	QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Product, error)
@@ -39,15 +46,17 @@ type Storer interface {
 // Business manages the set of APIs for product access.
 type Business struct {
 	log      *logger.Logger
+	clock    clock.Clock
 	userBus  *userbus.Business
 	delegate *delegate.Delegate
 	storer   Storer
 }
 
 // NewBusiness constructs a product business API for use.
-func NewBusiness(log *logger.Logger, userBus *userbus.Business, delegate *delegate.Delegate, storer Storer) *Business {
+func NewBusiness(log *logger.Logger, clock clock.Clock, userBus *userbus.Business, delegate *delegate.Delegate, storer Storer) *Business {
 	b := Business{
 		log:      log,
+		clock:    clock,
 		userBus:  userBus,
 		delegate: delegate,
 		storer:   storer,
@@ -73,6 +82,7 @@ func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
 
 	bus := Business{
 		log:      b.log,
+		clock:    b.clock,
 		userBus:  userBus,
 		delegate: b.delegate,
 		storer:   storer,
@@ -83,6 +93,9 @@ func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
 
 // Create adds a new product to the system.
 func (b *Business) Create(ctx context.Context, np NewProduct) (Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.create")
+	defer span.End()
+
 	usr, err := b.userBus.QueryByID(ctx, np.UserID)
 	if err != nil {
 		return Product{}, fmt.Errorf("user.querybyid: %s: %w", np.UserID, err)
@@ -96,7 +109,7 @@ func (b *Business) Create(ctx context.Context, np NewProduct) (Product, error) {
 		return Product{}, ErrUserDisabled
 	}
 
-	now := time.Now()
+	now := b.clock.Now().Truncate(time.Microsecond)
 
 	prd := Product{
 		ID:          uuid.New(),
@@ -112,11 +125,20 @@ func (b *Business) Create(ctx context.Context, np NewProduct) (Product, error) {
 		return Product{}, fmt.Errorf("create: %w", err)
 	}
 
+	// Other domains may need to know when a product is created. This
+	// represents a delegate call to other domains.
+	if err := b.delegate.Call(ctx, ActionData(ActionCreated, prd)); err != nil {
+		return Product{}, fmt.Errorf("failed to execute `%s` action: %w", ActionCreated, err)
+	}
+
 	return prd, nil
 }
 
 // Update modifies information about a product.
 func (b *Business) Update(ctx context.Context, prd Product, up UpdateProduct) (Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.update", attribute.String("product_id", prd.ID.String()))
+	defer span.End()
+
 	if up.Name != nil {
 		prd.Name = *up.Name
 	}
@@ -129,26 +151,45 @@ func (b *Business) Update(ctx context.Context, prd Product, up UpdateProduct) (P
 		prd.Quantity = *up.Quantity
 	}
 
-	prd.DateUpdated = time.Now()
+	lastUpdated := prd.DateUpdated
+	prd.DateUpdated = b.clock.Now().Truncate(time.Microsecond)
 
-	if err := b.storer.Update(ctx, prd); err != nil {
+	if err := b.storer.Update(ctx, prd, lastUpdated); err != nil {
 		return Product{}, fmt.Errorf("update: %w", err)
 	}
 
+	// Other domains may need to know when a product is updated. This
+	// represents a delegate call to other domains.
+	if err := b.delegate.Call(ctx, ActionData(ActionUpdated, prd)); err != nil {
+		return Product{}, fmt.Errorf("failed to execute `%s` action: %w", ActionUpdated, err)
+	}
+
 	return prd, nil
 }
 
 // Delete removes the specified product.
 func (b *Business) Delete(ctx context.Context, prd Product) error {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.delete", attribute.String("product_id", prd.ID.String()))
+	defer span.End()
+
 	if err := b.storer.Delete(ctx, prd); err != nil {
 		return fmt.Errorf("delete: %w", err)
 	}
 
+	// Other domains may need to know when a product is deleted. This
+	// represents a delegate call to other domains.
+	if err := b.delegate.Call(ctx, ActionData(ActionDeleted, prd)); err != nil {
+		return fmt.Errorf("failed to execute `%s` action: %w", ActionDeleted, err)
+	}
+
 	return nil
 }
 
 // Query retrieves a list of existing products.
 func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.query")
+	defer span.End()
+
 	prds, err := b.storer.Query(ctx, filter, orderBy, page)
 	if err != nil {
 		return nil, fmt.Errorf("query: %w", err)
@@ -157,13 +198,50 @@ func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.
 	return prds, nil
 }
 
+// QueryStream retrieves the existing products one at a time in the
+// specified order. The sequence ends at the first error.
+func (b *Business) QueryStream(ctx context.Context, filter QueryFilter, orderBy order.By) iter.Seq2[Product, error] {
+	return func(yield func(Product, error) bool) {
+		for prd, err := range b.storer.QueryStream(ctx, filter, orderBy) {
+			if err != nil {
+				yield(Product{}, fmt.Errorf("querystream: %w", err))
+				return
+			}
+
+			if !yield(prd, nil) {
+				return
+			}
+		}
+	}
+}
+
+// QueryByKeyset retrieves a list of existing products using keyset paging.
+// One more product than the limit is returned when there are more products.
+func (b *Business) QueryByKeyset(ctx context.Context, filter QueryFilter, keyset page.Keyset) ([]Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.querybykeyset")
+	defer span.End()
+
+	prds, err := b.storer.QueryByKeyset(ctx, filter, keyset)
+	if err != nil {
+		return nil, fmt.Errorf("querybykeyset: %w", err)
+	}
+
+	return prds, nil
+}
+
 // Count returns the total number of products.
 func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.count")
+	defer span.End()
+
 	return b.storer.Count(ctx, filter)
 }
 
 // QueryByID finds the product by the specified Ib.
 func (b *Business) QueryByID(ctx context.Context, productID uuid.UUID) (Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.querybyid", attribute.String("product_id", productID.String()))
+	defer span.End()
+
 	prd, err := b.storer.QueryByID(ctx, productID)
 	if err != nil {
 		return Product{}, fmt.Errorf("query: productID[%s]: %w", productID, err)
@@ -174,6 +252,9 @@ func (b *Business) QueryByID(ctx context.Context, productID uuid.UUID) (Product,
 
 // QueryByUserID finds the products by a specified User Ib.
 func (b *Business) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.querybyuserid", attribute.String("user_id", userID.String()))
+	defer span.End()
+
 	prds, err := b.storer.QueryByUserID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("query: %w", err)
@@ -0,0 +1,131 @@
+// Package productcache contains product related CRUD functionality with
+// caching.
+package productcache
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/productbus"
+	"github.com/ardanlabs/encore/business/sdk/cachemetrics"
+	"github.com/ardanlabs/encore/business/sdk/order"
+	"github.com/ardanlabs/encore/business/sdk/page"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/business/sdk/storecache"
+	"github.com/ardanlabs/encore/foundation/clock"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Store manages the set of APIs for product data and caching.
+type Store struct {
+	log    *logger.Logger
+	storer productbus.Storer
+	cache  *storecache.Cache[productbus.Product]
+}
+
+// CacheName is the name the cache reports its metrics with.
+const CacheName = "products"
+
+// NewStore constructs the api for data and caching access. The products are
+// cached by their id.
+func NewStore(log *logger.Logger, storer productbus.Storer, cfg storecache.Config, clock clock.Clock, counters cachemetrics.Counters) *Store {
+	entity := storecache.Entity[productbus.Product]{
+		Name:     CacheName,
+		NotFound: productbus.ErrNotFound,
+		ID:       func(prd productbus.Product) string { return prd.ID.String() },
+	}
+
+	return &Store{
+		log:    log,
+		storer: storer,
+		cache:  storecache.New(entity, cfg, clock, counters),
+	}
+}
+
+// Stats returns what the cache has done with the top number of products it
+// has served the most, keyed by their id.
+func (s *Store) Stats(top int) cachemetrics.Stats {
+	return s.cache.Stats(top)
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (productbus.Storer, error) {
+	return s.storer.NewWithTx(tx)
+}
+
+// Create inserts a new product into the database.
+func (s *Store) Create(ctx context.Context, prd productbus.Product) error {
+	if err := s.storer.Create(ctx, prd); err != nil {
+		return err
+	}
+
+	s.cache.Set(prd)
+
+	return nil
+}
+
+// Update replaces a product document in the database.
+func (s *Store) Update(ctx context.Context, prd productbus.Product, lastUpdated time.Time) error {
+	if err := s.storer.Update(ctx, prd, lastUpdated); err != nil {
+
+		// The cached product is out of date when another change got there
+		// first.
+		if errors.Is(err, productbus.ErrModified) {
+			s.cache.Delete(prd)
+		}
+		return err
+	}
+
+	s.cache.Set(prd)
+
+	return nil
+}
+
+// Delete removes a product from the database.
+func (s *Store) Delete(ctx context.Context, prd productbus.Product) error {
+	if err := s.storer.Delete(ctx, prd); err != nil {
+		return err
+	}
+
+	s.cache.Delete(prd)
+
+	return nil
+}
+
+// Query retrieves a list of existing products from the database.
+func (s *Store) Query(ctx context.Context, filter productbus.QueryFilter, orderBy order.By, page page.Page) ([]productbus.Product, error) {
+	return s.storer.Query(ctx, filter, orderBy, page)
+}
+
+// QueryByKeyset retrieves a list of existing products from the database
+// using keyset paging.
+func (s *Store) QueryByKeyset(ctx context.Context, filter productbus.QueryFilter, keyset page.Keyset) ([]productbus.Product, error) {
+	return s.storer.QueryByKeyset(ctx, filter, keyset)
+}
+
+// QueryStream retrieves the existing products from the database one at a
+// time.
+func (s *Store) QueryStream(ctx context.Context, filter productbus.QueryFilter, orderBy order.By) iter.Seq2[productbus.Product, error] {
+	return s.storer.QueryStream(ctx, filter, orderBy)
+}
+
+// Count returns the total number of products in the DB.
+func (s *Store) Count(ctx context.Context, filter productbus.QueryFilter) (int, error) {
+	return s.storer.Count(ctx, filter)
+}
+
+// QueryByID gets the specified product from the database.
+func (s *Store) QueryByID(ctx context.Context, productID uuid.UUID) (productbus.Product, error) {
+	return s.cache.Get(ctx, productID.String(), func(ctx context.Context) (productbus.Product, error) {
+		return s.storer.QueryByID(ctx, productID)
+	})
+}
+
+// QueryByUserID gets the products of the specified user from the database.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]productbus.Product, error) {
+	return s.storer.QueryByUserID(ctx, userID)
+}
@@ -0,0 +1,130 @@
+// Package productchaos contains a product store that injects faults into the
+// calls made to another product store, for testing how the failures are
+// handled.
+package productchaos
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/productbus"
+	"github.com/ardanlabs/encore/business/sdk/chaos"
+	"github.com/ardanlabs/encore/business/sdk/order"
+	"github.com/ardanlabs/encore/business/sdk/page"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/google/uuid"
+)
+
+// Store manages the set of APIs for product access with injected faults.
+type Store struct {
+	storer   productbus.Storer
+	injector *chaos.Injector
+}
+
+// NewStore constructs a store that injects the faults chosen by the
+// injector before calling the storer.
+func NewStore(storer productbus.Storer, injector *chaos.Injector) *Store {
+	return &Store{
+		storer:   storer,
+		injector: injector,
+	}
+}
+
+// NewWithTx constructs a new Store value that injects faults into the calls
+// made inside the transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (productbus.Storer, error) {
+	if err := s.injector.Inject(context.Background(), "NewWithTx"); err != nil {
+		return nil, err
+	}
+
+	storer, err := s.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStore(storer, s.injector), nil
+}
+
+// Create inserts a new product.
+func (s *Store) Create(ctx context.Context, prd productbus.Product) error {
+	if err := s.injector.Inject(ctx, "Create"); err != nil {
+		return err
+	}
+
+	return s.storer.Create(ctx, prd)
+}
+
+// Update replaces a product.
+func (s *Store) Update(ctx context.Context, prd productbus.Product, lastUpdated time.Time) error {
+	if err := s.injector.Inject(ctx, "Update"); err != nil {
+		return err
+	}
+
+	return s.storer.Update(ctx, prd, lastUpdated)
+}
+
+// Delete removes a product.
+func (s *Store) Delete(ctx context.Context, prd productbus.Product) error {
+	if err := s.injector.Inject(ctx, "Delete"); err != nil {
+		return err
+	}
+
+	return s.storer.Delete(ctx, prd)
+}
+
+// Query retrieves a list of existing products.
+func (s *Store) Query(ctx context.Context, filter productbus.QueryFilter, orderBy order.By, page page.Page) ([]productbus.Product, error) {
+	if err := s.injector.Inject(ctx, "Query"); err != nil {
+		return nil, err
+	}
+
+	return s.storer.Query(ctx, filter, orderBy, page)
+}
+
+// QueryByKeyset retrieves a list of existing products using keyset paging.
+func (s *Store) QueryByKeyset(ctx context.Context, filter productbus.QueryFilter, keyset page.Keyset) ([]productbus.Product, error) {
+	if err := s.injector.Inject(ctx, "QueryByKeyset"); err != nil {
+		return nil, err
+	}
+
+	return s.storer.QueryByKeyset(ctx, filter, keyset)
+}
+
+// QueryStream retrieves the existing products one at a time.
+func (s *Store) QueryStream(ctx context.Context, filter productbus.QueryFilter, orderBy order.By) iter.Seq2[productbus.Product, error] {
+	if err := s.injector.Inject(ctx, "QueryStream"); err != nil {
+		return func(yield func(productbus.Product, error) bool) {
+			yield(productbus.Product{}, err)
+		}
+	}
+
+	return s.storer.QueryStream(ctx, filter, orderBy)
+}
+
+// Count returns the total number of products.
+func (s *Store) Count(ctx context.Context, filter productbus.QueryFilter) (int, error) {
+	if err := s.injector.Inject(ctx, "Count"); err != nil {
+		return 0, err
+	}
+
+	return s.storer.Count(ctx, filter)
+}
+
+// QueryByID gets the specified product.
+func (s *Store) QueryByID(ctx context.Context, productID uuid.UUID) (productbus.Product, error) {
+	if err := s.injector.Inject(ctx, "QueryByID"); err != nil {
+		return productbus.Product{}, err
+	}
+
+	return s.storer.QueryByID(ctx, productID)
+}
+
+// QueryByUserID gets the products of the specified user.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]productbus.Product, error) {
+	if err := s.injector.Inject(ctx, "QueryByUserID"); err != nil {
+		return nil, err
+	}
+
+	return s.storer.QueryByUserID(ctx, userID)
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/ardanlabs/encore/business/domain/productbus"
+	"github.com/ardanlabs/encore/business/sdk/where"
 )
 
 func (s *Store) applyFilter(filter productbus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
@@ -21,15 +22,8 @@ func (s *Store) applyFilter(filter productbus.QueryFilter, data map[string]any,
 		wc = append(wc, "name LIKE :name")
 	}
 
-	if filter.Cost != nil {
-		data["cost"] = *filter.Cost
-		wc = append(wc, "cost = :cost")
-	}
-
-	if filter.Quantity != nil {
-		data["quantity"] = *filter.Quantity
-		wc = append(wc, "quantity = :quantity")
-	}
+	wc = append(wc, where.Apply(filter.Cost, "cost", data)...)
+	wc = append(wc, where.Apply(filter.Quantity, "quantity", data)...)
 
 	if len(wc) > 0 {
 		buf.WriteString(" WHERE ")
@@ -2,6 +2,7 @@ package productdb
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/ardanlabs/encore/business/domain/productbus"
 	"github.com/ardanlabs/encore/business/sdk/order"
@@ -16,10 +17,17 @@ var orderByFields = map[string]string{
 }
 
 func orderByClause(orderBy order.By) (string, error) {
-	by, exists := orderByFields[orderBy.Field]
-	if !exists {
-		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	fields := orderBy.Fields()
+
+	terms := make([]string, len(fields))
+	for i, ob := range fields {
+		by, exists := orderByFields[ob.Field]
+		if !exists {
+			return "", fmt.Errorf("field %q does not exist", ob.Field)
+		}
+
+		terms[i] = by + " " + ob.Direction
 	}
 
-	return " ORDER BY " + by + " " + orderBy.Direction, nil
+	return " ORDER BY " + strings.Join(terms, ", "), nil
 }
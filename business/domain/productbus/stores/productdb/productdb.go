@@ -6,14 +6,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"iter"
+	"time"
 
 	"github.com/ardanlabs/encore/business/domain/productbus"
 	"github.com/ardanlabs/encore/business/sdk/order"
 	"github.com/ardanlabs/encore/business/sdk/page"
 	"github.com/ardanlabs/encore/business/sdk/sqldb"
 	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/ardanlabs/encore/foundation/otel"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Store manages the set of APIs for product database access.
@@ -49,6 +53,9 @@ func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (productbus.Storer, error)
 // Create adds a Product to the sqldb. It returns the created Product with
 // fields like ID and DateCreated populated.
 func (s *Store) Create(ctx context.Context, prd productbus.Product) error {
+	ctx, span := otel.AddSpan(ctx, "business.productdb.create", attribute.String("db.sql.table", "products"))
+	defer span.End()
+
 	const q = `
 	INSERT INTO products
 		(product_id, user_id, name, cost, quantity, date_created, date_updated)
@@ -63,8 +70,13 @@ func (s *Store) Create(ctx context.Context, prd productbus.Product) error {
 }
 
 // Update modifies data about a productbus. It will error if the specified ID is
-// invalid or does not reference an existing productbus.
-func (s *Store) Update(ctx context.Context, prd productbus.Product) error {
+// invalid or does not reference an existing productbus. The product is only
+// modified when it was last updated at the specified time, otherwise
+// ErrModified is returned so a concurrent change isn't lost.
+func (s *Store) Update(ctx context.Context, prd productbus.Product, lastUpdated time.Time) error {
+	ctx, span := otel.AddSpan(ctx, "business.productdb.update", attribute.String("db.sql.table", "products"))
+	defer span.End()
+
 	const q = `
 	UPDATE
 		products
@@ -74,31 +86,59 @@ func (s *Store) Update(ctx context.Context, prd productbus.Product) error {
 		"quantity" = :quantity,
 		"date_updated" = :date_updated
 	WHERE
-		product_id = :product_id`
+		product_id = :product_id AND
+		date_updated = :last_updated
+	RETURNING
+		product_id`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBProduct(prd)); err != nil {
-		return fmt.Errorf("namedexeccontext: %w", err)
+	data := struct {
+		product
+		LastUpdated time.Time `db:"last_updated"`
+	}{
+		product:     toDBProduct(prd),
+		LastUpdated: lastUpdated.UTC(),
+	}
+
+	var dbPrd product
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPrd); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", productbus.ErrModified)
+		}
+		return fmt.Errorf("db: %w", err)
 	}
 
 	return nil
 }
 
-// Delete removes the product identified by a given ID.
+// Delete removes the product identified by a given ID. ErrModified is
+// returned when the product was updated after it was read.
 func (s *Store) Delete(ctx context.Context, prd productbus.Product) error {
+	ctx, span := otel.AddSpan(ctx, "business.productdb.delete", attribute.String("db.sql.table", "products"))
+	defer span.End()
+
 	data := struct {
-		ID string `db:"product_id"`
+		ID          string    `db:"product_id"`
+		DateUpdated time.Time `db:"date_updated"`
 	}{
-		ID: prd.ID.String(),
+		ID:          prd.ID.String(),
+		DateUpdated: prd.DateUpdated.UTC(),
 	}
 
 	const q = `
 	DELETE FROM
 		products
 	WHERE
-		product_id = :product_id`
+		product_id = :product_id AND
+		date_updated = :date_updated
+	RETURNING
+		product_id`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
-		return fmt.Errorf("namedexeccontext: %w", err)
+	var dbPrd product
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPrd); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", productbus.ErrModified)
+		}
+		return fmt.Errorf("db: %w", err)
 	}
 
 	return nil
@@ -106,6 +146,9 @@ func (s *Store) Delete(ctx context.Context, prd productbus.Product) error {
 
 // Query gets all Products from the database.
 func (s *Store) Query(ctx context.Context, filter productbus.QueryFilter, orderBy order.By, page page.Page) ([]productbus.Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productdb.query", attribute.String("db.sql.table", "products"))
+	defer span.End()
+
 	data := map[string]any{
 		"offset":        (page.Number() - 1) * page.RowsPerPage(),
 		"rows_per_page": page.RowsPerPage(),
@@ -136,8 +179,82 @@ func (s *Store) Query(ctx context.Context, filter productbus.QueryFilter, orderB
 	return toBusProducts(dbPrds)
 }
 
+// QueryStream retrieves the existing products from the database one at a
+// time, so every product can be read without holding them all in memory.
+func (s *Store) QueryStream(ctx context.Context, filter productbus.QueryFilter, orderBy order.By) iter.Seq2[productbus.Product, error] {
+	return func(yield func(productbus.Product, error) bool) {
+		ctx, span := otel.AddSpan(ctx, "business.productdb.querystream", attribute.String("db.sql.table", "products"))
+		defer span.End()
+
+		data := map[string]any{}
+
+		const q = `
+	SELECT
+	    product_id, user_id, name, cost, quantity, date_created, date_updated
+	FROM
+		products`
+
+		buf := bytes.NewBufferString(q)
+		s.applyFilter(filter, data, buf)
+
+		orderByClause, err := orderByClause(orderBy)
+		if err != nil {
+			yield(productbus.Product{}, err)
+			return
+		}
+
+		buf.WriteString(orderByClause)
+
+		for dbPrd, err := range sqldb.NamedQueryIter[product](ctx, s.log, s.db, buf.String(), data) {
+			if err != nil {
+				yield(productbus.Product{}, fmt.Errorf("namedqueryiter: %w", err))
+				return
+			}
+
+			prd, err := toBusProduct(dbPrd)
+			if err != nil {
+				yield(productbus.Product{}, err)
+				return
+			}
+
+			if !yield(prd, nil) {
+				return
+			}
+		}
+	}
+}
+
+// QueryByKeyset retrieves a list of existing products from the database using
+// keyset paging.
+func (s *Store) QueryByKeyset(ctx context.Context, filter productbus.QueryFilter, keyset page.Keyset) ([]productbus.Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productdb.querybykeyset", attribute.String("db.sql.table", "products"))
+	defer span.End()
+
+	data := map[string]any{}
+
+	const q = `
+	SELECT
+	    product_id, user_id, name, cost, quantity, date_created, date_updated
+	FROM
+		products`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+	keyset.Apply("product_id", data, buf)
+
+	var dbPrds []product
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbPrds); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusProducts(dbPrds)
+}
+
 // Count returns the total number of users in the DB.
 func (s *Store) Count(ctx context.Context, filter productbus.QueryFilter) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productdb.count", attribute.String("db.sql.table", "products"))
+	defer span.End()
+
 	data := map[string]any{}
 
 	const q = `
@@ -163,6 +280,9 @@ func (s *Store) Count(ctx context.Context, filter productbus.QueryFilter) (int,
 
 // QueryByID finds the product identified by a given ID.
 func (s *Store) QueryByID(ctx context.Context, productID uuid.UUID) (productbus.Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productdb.querybyid", attribute.String("db.sql.table", "products"))
+	defer span.End()
+
 	data := struct {
 		ID string `db:"product_id"`
 	}{
@@ -190,6 +310,9 @@ func (s *Store) QueryByID(ctx context.Context, productID uuid.UUID) (productbus.
 
 // QueryByUserID finds the product identified by a given User ID.
 func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]productbus.Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productdb.querybyuserid", attribute.String("db.sql.table", "products"))
+	defer span.End()
+
 	data := struct {
 		ID string `db:"user_id"`
 	}{
@@ -0,0 +1,157 @@
+// Package productmock contains a product store that keeps the products in
+// memory and records the calls made to it, for testing without a database.
+package productmock
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/productbus"
+	"github.com/ardanlabs/encore/business/sdk/mockstore"
+	"github.com/ardanlabs/encore/business/sdk/order"
+	"github.com/ardanlabs/encore/business/sdk/page"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/google/uuid"
+)
+
+// Store manages the set of APIs for product access in memory. The filters
+// and ordering of the queries aren't applied, the products are returned in
+// the order they were created.
+type Store struct {
+	mockstore.Recorder
+	products *mockstore.Table[productbus.Product]
+}
+
+// NewStore constructs an empty store.
+func NewStore() *Store {
+	return &Store{
+		products: mockstore.NewTable(func(prd productbus.Product) uuid.UUID {
+			return prd.ID
+		}),
+	}
+}
+
+// NewWithTx returns the same store since transactions aren't supported.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (productbus.Storer, error) {
+	if err := s.Record("NewWithTx", tx); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Create inserts a new product into the store.
+func (s *Store) Create(ctx context.Context, prd productbus.Product) error {
+	if err := s.Record("Create", prd); err != nil {
+		return err
+	}
+
+	s.products.Insert(prd)
+
+	return nil
+}
+
+// Update replaces a product in the store when it was last updated at the
+// specified time.
+func (s *Store) Update(ctx context.Context, prd productbus.Product, lastUpdated time.Time) error {
+	if err := s.Record("Update", prd, lastUpdated); err != nil {
+		return err
+	}
+
+	if cur, found := s.products.Find(prd.ID); !found || !cur.DateUpdated.Equal(lastUpdated) {
+		return productbus.ErrModified
+	}
+
+	s.products.Update(prd)
+
+	return nil
+}
+
+// Delete removes a product from the store when it hasn't been updated since it
+// was read.
+func (s *Store) Delete(ctx context.Context, prd productbus.Product) error {
+	if err := s.Record("Delete", prd); err != nil {
+		return err
+	}
+
+	if cur, found := s.products.Find(prd.ID); !found || !cur.DateUpdated.Equal(prd.DateUpdated) {
+		return productbus.ErrModified
+	}
+
+	s.products.Delete(prd)
+
+	return nil
+}
+
+// Query retrieves a page of the products.
+func (s *Store) Query(ctx context.Context, filter productbus.QueryFilter, orderBy order.By, page page.Page) ([]productbus.Product, error) {
+	if err := s.Record("Query", filter, orderBy, page); err != nil {
+		return nil, err
+	}
+
+	return mockstore.Page(s.products.Rows(), page), nil
+}
+
+// QueryByKeyset retrieves the first page of the products using keyset paging.
+func (s *Store) QueryByKeyset(ctx context.Context, filter productbus.QueryFilter, keyset page.Keyset) ([]productbus.Product, error) {
+	if err := s.Record("QueryByKeyset", filter, keyset); err != nil {
+		return nil, err
+	}
+
+	return mockstore.Keyset(s.products.Rows(), keyset), nil
+}
+
+// QueryStream retrieves all the products one at a time.
+func (s *Store) QueryStream(ctx context.Context, filter productbus.QueryFilter, orderBy order.By) iter.Seq2[productbus.Product, error] {
+	err := s.Record("QueryStream", filter, orderBy)
+
+	return func(yield func(productbus.Product, error) bool) {
+		if err != nil {
+			yield(productbus.Product{}, err)
+			return
+		}
+
+		for _, prd := range s.products.Rows() {
+			if !yield(prd, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Count returns the total number of products in the store.
+func (s *Store) Count(ctx context.Context, filter productbus.QueryFilter) (int, error) {
+	if err := s.Record("Count", filter); err != nil {
+		return 0, err
+	}
+
+	return len(s.products.Rows()), nil
+}
+
+// QueryByID gets the specified product from the store.
+func (s *Store) QueryByID(ctx context.Context, productID uuid.UUID) (productbus.Product, error) {
+	if err := s.Record("QueryByID", productID); err != nil {
+		return productbus.Product{}, err
+	}
+
+	prd, found := s.products.Find(productID)
+	if !found {
+		return productbus.Product{}, productbus.ErrNotFound
+	}
+
+	return prd, nil
+}
+
+// QueryByUserID gets the products of the specified user from the store.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]productbus.Product, error) {
+	if err := s.Record("QueryByUserID", userID); err != nil {
+		return nil, err
+	}
+
+	prds := s.products.Select(func(prd productbus.Product) bool {
+		return prd.UserID == userID
+	})
+
+	return prds, nil
+}
@@ -0,0 +1,78 @@
+package productmock_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/productbus"
+	"github.com/ardanlabs/encore/business/domain/productbus/stores/productmock"
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/domain/userbus/stores/usermock"
+	"github.com/ardanlabs/encore/business/sdk/delegate"
+	"github.com/ardanlabs/encore/foundation/clock"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+)
+
+func Test_Business(t *testing.T) {
+	ctx := context.Background()
+
+	log := logger.NewWithHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), logger.Events{}, nil)
+	clk := clock.NewFrozen(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+	dlg := delegate.New(log)
+	rnd := rand.New(rand.NewSource(1))
+
+	usrStore := usermock.NewStore()
+	prdStore := productmock.NewStore()
+
+	userBus := userbus.NewBusiness(log, clk, dlg, usrStore)
+	productBus := productbus.NewBusiness(log, clk, userBus, dlg, prdStore)
+
+	usr, err := userBus.Create(ctx, userbus.TestNewUsers(rnd, 1, userbus.Roles.User)[0])
+	if err != nil {
+		t.Fatalf("Should be able to create a user: %s", err)
+	}
+
+	prd, err := productBus.Create(ctx, productbus.TestGenerateNewProducts(rnd, 1, usr.ID)[0])
+	if err != nil {
+		t.Fatalf("Should be able to create a product: %s", err)
+	}
+
+	if !prd.DateCreated.Equal(clk.Now()) {
+		t.Fatalf("Should create the product at the time of the clock, got %s", prd.DateCreated)
+	}
+
+	calls := prdStore.CallsTo("Create")
+	if len(calls) != 1 || calls[0].Args[0] != prd {
+		t.Fatalf("Should record the product the store was called with, got %v", calls)
+	}
+
+	got, err := productBus.QueryByID(ctx, prd.ID)
+	if err != nil || got != prd {
+		t.Fatalf("Should be able to query the product, got %v %v", got, err)
+	}
+
+	if _, err := productBus.QueryByID(ctx, uuid.New()); !errors.Is(err, productbus.ErrNotFound) {
+		t.Fatalf("Should not find an unknown product, got %v", err)
+	}
+
+	if _, err := productBus.Create(ctx, productbus.TestGenerateNewProducts(rnd, 1, uuid.New())[0]); !errors.Is(err, userbus.ErrNotFound) {
+		t.Fatalf("Should not create a product for an unknown user, got %v", err)
+	}
+
+	errFail := errors.New("store failure")
+	prdStore.SetError("Create", errFail)
+
+	if _, err := productBus.Create(ctx, productbus.TestGenerateNewProducts(rnd, 1, usr.ID)[0]); !errors.Is(err, errFail) {
+		t.Fatalf("Should get the error the store was set to return, got %v", err)
+	}
+
+	if n, _ := productBus.Count(ctx, productbus.QueryFilter{}); n != 1 {
+		t.Fatalf("Should only count the product that was stored, got %d", n)
+	}
+}
@@ -9,17 +9,17 @@ import (
 )
 
 // TestGenerateNewProducts is a helper method for testing.
-func TestGenerateNewProducts(n int, userID uuid.UUID) []NewProduct {
+func TestGenerateNewProducts(rnd *rand.Rand, n int, userID uuid.UUID) []NewProduct {
 	newPrds := make([]NewProduct, n)
 
-	idx := rand.Intn(10000)
+	idx := rnd.Intn(10000)
 	for i := 0; i < n; i++ {
 		idx++
 
 		np := NewProduct{
 			Name:     MustParseName(fmt.Sprintf("Name%d", idx)),
-			Cost:     float64(rand.Intn(500)),
-			Quantity: rand.Intn(50),
+			Cost:     float64(rnd.Intn(500)),
+			Quantity: rnd.Intn(50),
 			UserID:   userID,
 		}
 
@@ -30,8 +30,8 @@ func TestGenerateNewProducts(n int, userID uuid.UUID) []NewProduct {
 }
 
 // TestGenerateSeedProducts is a helper method for testing.
-func TestGenerateSeedProducts(ctx context.Context, n int, api *Business, userID uuid.UUID) ([]Product, error) {
-	newPrds := TestGenerateNewProducts(n, userID)
+func TestGenerateSeedProducts(ctx context.Context, rnd *rand.Rand, n int, api *Business, userID uuid.UUID) ([]Product, error) {
+	newPrds := TestGenerateNewProducts(rnd, n, userID)
 
 	prds := make([]Product, len(newPrds))
 	for i, np := range newPrds {
@@ -0,0 +1,23 @@
+package reportbus
+
+import (
+	"fmt"
+	"time"
+)
+
+// Report represents the aggregates for a single day.
+type Report struct {
+	Date           time.Time
+	NewUsers       int
+	NewProducts    int
+	InventoryValue float64
+	DateCreated    time.Time
+}
+
+// String returns a summary of the report suitable for a notification.
+func (r Report) String() string {
+	return fmt.Sprintf(
+		"Daily Report %s: new users: %d, new products: %d, inventory value: %.2f",
+		r.Date.Format(time.DateOnly), r.NewUsers, r.NewProducts, r.InventoryValue,
+	)
+}
@@ -0,0 +1,104 @@
+package reportbus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"encore.dev/et"
+	"github.com/ardanlabs/encore/business/domain/reportbus"
+	"github.com/ardanlabs/encore/business/sdk/dbtest"
+	"github.com/ardanlabs/encore/business/sdk/unitest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Report(t *testing.T) {
+	t.Parallel()
+
+	edb, err := et.NewTestDatabase(context.Background(), "app")
+	if err != nil {
+		t.Fatalf("Creating new database: %s", err)
+	}
+
+	db := dbtest.NewDatabase(t, edb)
+
+	sd := db.LoadFixtures(t, "fixtures/seed.yaml")
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, buildDaily(db.BusDomain, sd), "builddaily")
+}
+
+// =============================================================================
+
+func buildDaily(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	var value float64
+	for _, prd := range sd.Users[0].Products {
+		value += prd.Cost * float64(prd.Quantity)
+	}
+
+	now := time.Now()
+
+	table := []unitest.Table{
+		{
+			Name: "today",
+			ExpResp: reportbus.Report{
+				Date:           now.UTC().Truncate(24 * time.Hour),
+				NewUsers:       1,
+				NewProducts:    len(sd.Users[0].Products),
+				InventoryValue: value,
+			},
+			ExcFunc: func(ctx context.Context) any {
+				if _, err := busDomain.Report.BuildDaily(ctx, now); err != nil {
+					return err
+				}
+
+				resp, err := busDomain.Report.QueryByDate(ctx, now)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(reportbus.Report)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(reportbus.Report)
+				expResp.DateCreated = gotResp.DateCreated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name: "yesterday",
+			ExpResp: reportbus.Report{
+				Date:           now.UTC().Truncate(24 * time.Hour).Add(-24 * time.Hour),
+				InventoryValue: value,
+			},
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Report.BuildDaily(ctx, now.Add(-24*time.Hour))
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(reportbus.Report)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(reportbus.Report)
+				expResp.DateCreated = gotResp.DateCreated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
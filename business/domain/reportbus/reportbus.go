@@ -0,0 +1,108 @@
+// Package reportbus provides business access to the reporting domain.
+package reportbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/foundation/logger"
+)
+
+// Set of error variables for report operations.
+var (
+	ErrNotFound = errors.New("report not found")
+)
+
+// Storer interface declares the behaviour this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Aggregate(ctx context.Context, start time.Time, end time.Time) (Report, error)
+	Upsert(ctx context.Context, rpt Report) error
+	QueryByDate(ctx context.Context, date time.Time) (Report, error)
+}
+
+// Notifier interface declares the behaviour this package needs to send a
+// summary of a report to interested parties.
+type Notifier interface {
+	Notify(ctx context.Context, subject string, body string) error
+}
+
+// Business manages the set of APIs for report access.
+type Business struct {
+	log      *logger.Logger
+	notifier Notifier
+	storer   Storer
+}
+
+// NewBusiness constructs a report business API for use. The notifier is
+// optional and when nil, no summary is sent after a report is built.
+func NewBusiness(log *logger.Logger, notifier Notifier, storer Storer) *Business {
+	return &Business{
+		log:      log,
+		notifier: notifier,
+		storer:   storer,
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:      b.log,
+		notifier: b.notifier,
+		storer:   storer,
+	}
+
+	return &bus, nil
+}
+
+// BuildDaily builds the aggregates for the UTC day that contains the
+// specified time and stores them. Building a day more than once replaces
+// the previously stored aggregates.
+func (b *Business) BuildDaily(ctx context.Context, day time.Time) (Report, error) {
+	start := day.UTC().Truncate(24 * time.Hour)
+	end := start.Add(24 * time.Hour)
+
+	rpt, err := b.storer.Aggregate(ctx, start, end)
+	if err != nil {
+		return Report{}, fmt.Errorf("aggregate: day[%s]: %w", start.Format(time.DateOnly), err)
+	}
+
+	rpt.Date = start
+	rpt.DateCreated = time.Now()
+
+	if err := b.storer.Upsert(ctx, rpt); err != nil {
+		return Report{}, fmt.Errorf("upsert: day[%s]: %w", start.Format(time.DateOnly), err)
+	}
+
+	// The report is already stored so a failure to notify is only logged.
+	if b.notifier != nil {
+		if err := b.notifier.Notify(ctx, "Daily Report", rpt.String()); err != nil {
+			b.log.Error(ctx, "report notify", "day", start.Format(time.DateOnly), "ERROR", err)
+		}
+	}
+
+	return rpt, nil
+}
+
+// QueryByDate finds the report for the UTC day that contains the specified
+// time.
+func (b *Business) QueryByDate(ctx context.Context, day time.Time) (Report, error) {
+	date := day.UTC().Truncate(24 * time.Hour)
+
+	rpt, err := b.storer.QueryByDate(ctx, date)
+	if err != nil {
+		return Report{}, fmt.Errorf("query: day[%s]: %w", date.Format(time.DateOnly), err)
+	}
+
+	return rpt, nil
+}
@@ -0,0 +1,35 @@
+package reportdb
+
+import (
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/reportbus"
+)
+
+type report struct {
+	Date           time.Time `db:"report_date"`
+	NewUsers       int       `db:"new_users"`
+	NewProducts    int       `db:"new_products"`
+	InventoryValue float64   `db:"inventory_value"`
+	DateCreated    time.Time `db:"date_created"`
+}
+
+func toDBReport(bus reportbus.Report) report {
+	return report{
+		Date:           bus.Date.UTC(),
+		NewUsers:       bus.NewUsers,
+		NewProducts:    bus.NewProducts,
+		InventoryValue: bus.InventoryValue,
+		DateCreated:    bus.DateCreated.UTC(),
+	}
+}
+
+func toBusReport(db report) reportbus.Report {
+	return reportbus.Report{
+		Date:           db.Date.UTC(),
+		NewUsers:       db.NewUsers,
+		NewProducts:    db.NewProducts,
+		InventoryValue: db.InventoryValue,
+		DateCreated:    db.DateCreated.In(time.Local),
+	}
+}
@@ -0,0 +1,123 @@
+// Package reportdb contains report related CRUD functionality.
+package reportdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/reportbus"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for report database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (reportbus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Aggregate calculates the report values for the specified time range. The
+// inventory value is a snapshot of the current inventory.
+func (s *Store) Aggregate(ctx context.Context, start time.Time, end time.Time) (reportbus.Report, error) {
+	data := map[string]any{
+		"start": start.UTC(),
+		"end":   end.UTC(),
+	}
+
+	const q = `
+    SELECT
+        (SELECT count(1) FROM users WHERE date_created >= :start AND date_created < :end) AS new_users,
+        (SELECT count(1) FROM products WHERE date_created >= :start AND date_created < :end) AS new_products,
+        (SELECT COALESCE(SUM(cost * quantity), 0) FROM products) AS inventory_value`
+
+	var agg struct {
+		NewUsers       int     `db:"new_users"`
+		NewProducts    int     `db:"new_products"`
+		InventoryValue float64 `db:"inventory_value"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &agg); err != nil {
+		return reportbus.Report{}, fmt.Errorf("db: %w", err)
+	}
+
+	rpt := reportbus.Report{
+		NewUsers:       agg.NewUsers,
+		NewProducts:    agg.NewProducts,
+		InventoryValue: agg.InventoryValue,
+	}
+
+	return rpt, nil
+}
+
+// Upsert stores the report, replacing any report already stored for the day.
+func (s *Store) Upsert(ctx context.Context, rpt reportbus.Report) error {
+	const q = `
+    INSERT INTO daily_reports
+        (report_date, new_users, new_products, inventory_value, date_created)
+    VALUES
+        (:report_date, :new_users, :new_products, :inventory_value, :date_created)
+    ON CONFLICT (report_date) DO UPDATE SET
+        new_users       = EXCLUDED.new_users,
+        new_products    = EXCLUDED.new_products,
+        inventory_value = EXCLUDED.inventory_value,
+        date_created    = EXCLUDED.date_created`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBReport(rpt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByDate gets the report for the specified day from the database.
+func (s *Store) QueryByDate(ctx context.Context, date time.Time) (reportbus.Report, error) {
+	data := struct {
+		Date time.Time `db:"report_date"`
+	}{
+		Date: date.UTC(),
+	}
+
+	const q = `
+    SELECT
+        report_date, new_users, new_products, inventory_value, date_created
+    FROM
+        daily_reports
+    WHERE
+        report_date = :report_date`
+
+	var dbRpt report
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbRpt); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return reportbus.Report{}, fmt.Errorf("db: %w", reportbus.ErrNotFound)
+		}
+		return reportbus.Report{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusReport(dbRpt), nil
+}
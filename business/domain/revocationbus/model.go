@@ -0,0 +1,42 @@
+package revocationbus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Revocation represents a revoked token. A revocation without a token id
+// revokes every token issued to the user up to when it was made. It's kept
+// until the tokens it revokes would have expired anyway.
+type Revocation struct {
+	UserID      uuid.UUID
+	TokenID     uuid.UUID
+	DateExpires time.Time
+	DateCreated time.Time
+}
+
+// UserRevocations represents the revocations made for a user.
+type UserRevocations struct {
+	UserID uuid.UUID
+	Items  []Revocation
+}
+
+// IsRevoked reports if the token with the id that was issued at the time is
+// revoked. A token issued in the same second as a revocation of every token
+// is treated as revoked, since tokens only carry their time to the second.
+func (ur UserRevocations) IsRevoked(tokenID uuid.UUID, issuedAt time.Time) bool {
+	for _, rev := range ur.Items {
+		switch rev.TokenID {
+		case uuid.Nil:
+			if !issuedAt.After(rev.DateCreated) {
+				return true
+			}
+
+		case tokenID:
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,98 @@
+// Package revocationbus provides business access to the list of tokens that
+// have been revoked before they expire, like the tokens of a user that logged
+// out or an account an admin has forced to log out.
+package revocationbus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/foundation/clock"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Storer interface declares the behaviour this package needs to persist and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, rev Revocation) error
+	DeleteBefore(ctx context.Context, before time.Time) (int, error)
+	QueryByUserID(ctx context.Context, userID uuid.UUID) (UserRevocations, error)
+}
+
+// Business manages the set of APIs for revocation access.
+type Business struct {
+	log    *logger.Logger
+	clock  clock.Clock
+	ttl    time.Duration
+	storer Storer
+}
+
+// NewBusiness constructs a revocation business API for use. The ttl is the
+// longest a token can be used for, which is how long a revocation of every
+// token of a user has to be kept.
+func NewBusiness(log *logger.Logger, clock clock.Clock, ttl time.Duration, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		clock:  clock,
+		ttl:    ttl,
+		storer: storer,
+	}
+}
+
+// RevokeToken revokes the user's token with the id until it expires.
+func (b *Business) RevokeToken(ctx context.Context, userID uuid.UUID, tokenID uuid.UUID, expires time.Time) error {
+	rev := Revocation{
+		UserID:      userID,
+		TokenID:     tokenID,
+		DateExpires: expires,
+		DateCreated: b.clock.Now(),
+	}
+
+	if err := b.storer.Create(ctx, rev); err != nil {
+		return fmt.Errorf("create: userID[%s] tokenID[%s]: %w", userID, tokenID, err)
+	}
+
+	return nil
+}
+
+// RevokeUser revokes every token issued to the user so far.
+func (b *Business) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	now := b.clock.Now()
+
+	rev := Revocation{
+		UserID:      userID,
+		TokenID:     uuid.Nil,
+		DateExpires: now.Add(b.ttl),
+		DateCreated: now,
+	}
+
+	if err := b.storer.Create(ctx, rev); err != nil {
+		return fmt.Errorf("create: userID[%s]: %w", userID, err)
+	}
+
+	return nil
+}
+
+// IsRevoked reports if the user's token with the id that was issued at the
+// time has been revoked.
+func (b *Business) IsRevoked(ctx context.Context, userID uuid.UUID, tokenID uuid.UUID, issuedAt time.Time) (bool, error) {
+	ur, err := b.storer.QueryByUserID(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return ur.IsRevoked(tokenID, issuedAt), nil
+}
+
+// DeleteExpired removes the revocations of tokens that have expired and
+// returns the number removed.
+func (b *Business) DeleteExpired(ctx context.Context) (int, error) {
+	n, err := b.storer.DeleteBefore(ctx, b.clock.Now())
+	if err != nil {
+		return 0, fmt.Errorf("deletebefore: %w", err)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,80 @@
+// Package revocationcache contains revocation related CRUD functionality
+// with caching.
+package revocationcache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/revocationbus"
+	"github.com/ardanlabs/encore/business/sdk/cachemetrics"
+	"github.com/ardanlabs/encore/business/sdk/storecache"
+	"github.com/ardanlabs/encore/foundation/clock"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Store manages the set of APIs for revocation data and caching.
+type Store struct {
+	log    *logger.Logger
+	storer revocationbus.Storer
+	cache  *storecache.Cache[revocationbus.UserRevocations]
+}
+
+// CacheName is the name the cache reports its metrics with.
+const CacheName = "revocations"
+
+// errNotFound is never returned by the store, since a user without
+// revocations has an empty list.
+var errNotFound = errors.New("revocations not found")
+
+// NewStore constructs the api for data and caching access. The revocations
+// are cached by the id of the user they were made for. A revocation made on
+// another instance is only seen once the cached list expires, so the TTL
+// should be short.
+func NewStore(log *logger.Logger, storer revocationbus.Storer, cfg storecache.Config, clock clock.Clock, counters cachemetrics.Counters) *Store {
+	entity := storecache.Entity[revocationbus.UserRevocations]{
+		Name:     CacheName,
+		NotFound: errNotFound,
+		ID:       func(ur revocationbus.UserRevocations) string { return ur.UserID.String() },
+	}
+
+	return &Store{
+		log:    log,
+		storer: storer,
+		cache:  storecache.New(entity, cfg, clock, counters),
+	}
+}
+
+// Stats returns what the cache has done with the top number of users it
+// has served the most, keyed by their id.
+func (s *Store) Stats(top int) cachemetrics.Stats {
+	return s.cache.Stats(top)
+}
+
+// Create inserts a new revocation into the database. The cached list of the
+// user is dropped so it's read again with the revocation.
+func (s *Store) Create(ctx context.Context, rev revocationbus.Revocation) error {
+	if err := s.storer.Create(ctx, rev); err != nil {
+		return err
+	}
+
+	s.cache.Delete(revocationbus.UserRevocations{UserID: rev.UserID})
+
+	return nil
+}
+
+// DeleteBefore removes the revocations that expired before the specified
+// time. The cached lists can keep them until they expire, since they only
+// revoke tokens that have expired too.
+func (s *Store) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	return s.storer.DeleteBefore(ctx, before)
+}
+
+// QueryByUserID gets the revocations made for the user.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) (revocationbus.UserRevocations, error) {
+	return s.cache.Get(ctx, userID.String(), func(ctx context.Context) (revocationbus.UserRevocations, error) {
+		return s.storer.QueryByUserID(ctx, userID)
+	})
+}
@@ -0,0 +1,134 @@
+package revocationcache_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/revocationbus"
+	"github.com/ardanlabs/encore/business/domain/revocationbus/stores/revocationcache"
+	"github.com/ardanlabs/encore/business/sdk/storecache"
+	"github.com/ardanlabs/encore/foundation/clock"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+)
+
+func Test_Revoke(t *testing.T) {
+	ctx := context.Background()
+
+	log := logger.NewWithHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), logger.Events{}, nil)
+	clk := clock.NewFrozen(time.Now())
+
+	store := newMemStore()
+	cache := revocationcache.NewStore(log, store, storecache.Config{TTL: time.Minute}, clk, nil)
+	bus := revocationbus.NewBusiness(log, clk, time.Hour, cache)
+
+	userID := uuid.New()
+	tokenID := uuid.New()
+	issuedAt := clk.Now().Add(-time.Minute)
+
+	for range 2 {
+		revoked, err := bus.IsRevoked(ctx, userID, tokenID, issuedAt)
+		if err != nil {
+			t.Fatalf("Should be able to check the token: %s", err)
+		}
+
+		if revoked {
+			t.Fatalf("Should not revoke a token that wasn't revoked")
+		}
+	}
+
+	if store.queries != 1 {
+		t.Fatalf("Should get the revocations from the cache, got %d queries to the store", store.queries)
+	}
+
+	if err := bus.RevokeToken(ctx, userID, tokenID, clk.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Should be able to revoke the token: %s", err)
+	}
+
+	revoked, err := bus.IsRevoked(ctx, userID, tokenID, issuedAt)
+	if err != nil {
+		t.Fatalf("Should be able to check the token: %s", err)
+	}
+
+	if !revoked {
+		t.Fatalf("Should see the revocation right after it's made")
+	}
+
+	if revoked, _ := bus.IsRevoked(ctx, userID, uuid.New(), issuedAt); revoked {
+		t.Fatalf("Should not revoke the other tokens of the user")
+	}
+}
+
+func Test_RevokeUser(t *testing.T) {
+	ctx := context.Background()
+
+	log := logger.NewWithHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), logger.Events{}, nil)
+	clk := clock.NewFrozen(time.Now())
+
+	cache := revocationcache.NewStore(log, newMemStore(), storecache.Config{TTL: time.Minute}, clk, nil)
+	bus := revocationbus.NewBusiness(log, clk, time.Hour, cache)
+
+	userID := uuid.New()
+	before := clk.Now().Add(-time.Minute)
+
+	if err := bus.RevokeUser(ctx, userID); err != nil {
+		t.Fatalf("Should be able to revoke the user: %s", err)
+	}
+
+	if revoked, _ := bus.IsRevoked(ctx, userID, uuid.New(), before); !revoked {
+		t.Fatalf("Should revoke a token issued before the user was revoked")
+	}
+
+	if revoked, _ := bus.IsRevoked(ctx, userID, uuid.New(), clk.Now().Add(time.Second)); revoked {
+		t.Fatalf("Should not revoke a token issued after the user was revoked")
+	}
+
+	if revoked, _ := bus.IsRevoked(ctx, uuid.New(), uuid.New(), before); revoked {
+		t.Fatalf("Should not revoke the tokens of other users")
+	}
+}
+
+// =============================================================================
+
+type memStore struct {
+	mu      sync.Mutex
+	revs    []revocationbus.Revocation
+	queries int
+}
+
+func newMemStore() *memStore {
+	return &memStore{}
+}
+
+func (s *memStore) Create(ctx context.Context, rev revocationbus.Revocation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revs = append(s.revs, rev)
+
+	return nil
+}
+
+func (s *memStore) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	return 0, nil
+}
+
+func (s *memStore) QueryByUserID(ctx context.Context, userID uuid.UUID) (revocationbus.UserRevocations, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.queries++
+
+	ur := revocationbus.UserRevocations{UserID: userID}
+	for _, rev := range s.revs {
+		if rev.UserID == userID {
+			ur.Items = append(ur.Items, rev)
+		}
+	}
+
+	return ur, nil
+}
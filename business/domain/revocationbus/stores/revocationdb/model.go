@@ -0,0 +1,46 @@
+package revocationdb
+
+import (
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/revocationbus"
+	"github.com/google/uuid"
+)
+
+type revocation struct {
+	UserID      uuid.UUID `db:"user_id"`
+	TokenID     uuid.UUID `db:"token_id"`
+	DateExpires time.Time `db:"date_expires"`
+	DateCreated time.Time `db:"date_created"`
+}
+
+func toDBRevocation(bus revocationbus.Revocation) revocation {
+	return revocation{
+		UserID:      bus.UserID,
+		TokenID:     bus.TokenID,
+		DateExpires: bus.DateExpires.UTC(),
+		DateCreated: bus.DateCreated.UTC(),
+	}
+}
+
+func toBusRevocation(db revocation) revocationbus.Revocation {
+	return revocationbus.Revocation{
+		UserID:      db.UserID,
+		TokenID:     db.TokenID,
+		DateExpires: db.DateExpires.In(time.Local),
+		DateCreated: db.DateCreated.In(time.Local),
+	}
+}
+
+func toBusUserRevocations(userID uuid.UUID, dbs []revocation) revocationbus.UserRevocations {
+	ur := revocationbus.UserRevocations{
+		UserID: userID,
+		Items:  make([]revocationbus.Revocation, len(dbs)),
+	}
+
+	for i, db := range dbs {
+		ur.Items[i] = toBusRevocation(db)
+	}
+
+	return ur
+}
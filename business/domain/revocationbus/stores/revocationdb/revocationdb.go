@@ -0,0 +1,103 @@
+// Package revocationdb contains revocation related CRUD functionality.
+package revocationdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/revocationbus"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for revocation database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts the revocation or replaces the one already stored for the
+// user's token, so revoking every token again moves the revocation forward.
+func (s *Store) Create(ctx context.Context, rev revocationbus.Revocation) error {
+	const q = `
+    INSERT INTO token_revocations
+        (user_id, token_id, date_expires, date_created)
+    VALUES
+        (:user_id, :token_id, :date_expires, :date_created)
+    ON CONFLICT (user_id, token_id) DO UPDATE SET
+        date_expires = EXCLUDED.date_expires,
+        date_created = EXCLUDED.date_created`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBRevocation(rev)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteBefore removes the revocations that expired before the specified
+// time and returns the number removed.
+func (s *Store) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before.UTC(),
+	}
+
+	const q = `
+    WITH deleted AS (
+        DELETE FROM
+            token_revocations
+        WHERE
+            date_expires < :before
+        RETURNING 1
+    )
+    SELECT
+        count(1)
+    FROM
+        deleted`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByUserID gets the revocations made for the user.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) (revocationbus.UserRevocations, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+    SELECT
+        user_id, token_id, date_expires, date_created
+    FROM
+        token_revocations
+    WHERE
+        user_id = :user_id`
+
+	var dbRevs []revocation
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbRevs); err != nil {
+		return revocationbus.UserRevocations{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusUserRevocations(userID, dbRevs), nil
+}
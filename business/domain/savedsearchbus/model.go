@@ -0,0 +1,27 @@
+package savedsearchbus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SavedSearch represents a named filter and order a user stored for an
+// entity so they can run it again later. The query holds the query string
+// parameters the search was saved with.
+type SavedSearch struct {
+	UserID      uuid.UUID
+	Entity      string
+	Name        string
+	Query       string
+	DateCreated time.Time
+	DateUpdated time.Time
+}
+
+// SaveSearch is what we require to store a saved search.
+type SaveSearch struct {
+	UserID uuid.UUID
+	Entity string
+	Name   string
+	Query  string
+}
@@ -0,0 +1,99 @@
+// Package savedsearchbus provides business access to the searches users have
+// saved by name so they can be run again.
+package savedsearchbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound = errors.New("saved search not found")
+)
+
+// Storer interface declares the behaviour this package needs to persist and
+// retrieve data.
+type Storer interface {
+	Upsert(ctx context.Context, ss SavedSearch) error
+	Delete(ctx context.Context, ss SavedSearch) error
+	QueryByName(ctx context.Context, userID uuid.UUID, entity string, name string) (SavedSearch, error)
+	QueryByUserID(ctx context.Context, userID uuid.UUID) ([]SavedSearch, error)
+}
+
+// Business manages the set of APIs for saved search access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs a saved search business API for use.
+func NewBusiness(log *logger.Logger, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// Save stores the search under its name, replacing a search the user saved
+// for the entity with the same name.
+func (b *Business) Save(ctx context.Context, ss SaveSearch) (SavedSearch, error) {
+	now := time.Now()
+
+	search := SavedSearch{
+		UserID:      ss.UserID,
+		Entity:      ss.Entity,
+		Name:        ss.Name,
+		Query:       ss.Query,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	existing, err := b.storer.QueryByName(ctx, ss.UserID, ss.Entity, ss.Name)
+	switch {
+	case err == nil:
+		search.DateCreated = existing.DateCreated
+	case !errors.Is(err, ErrNotFound):
+		return SavedSearch{}, fmt.Errorf("querybyname: %w", err)
+	}
+
+	if err := b.storer.Upsert(ctx, search); err != nil {
+		return SavedSearch{}, fmt.Errorf("upsert: %w", err)
+	}
+
+	return search, nil
+}
+
+// Delete removes the specified saved search.
+func (b *Business) Delete(ctx context.Context, ss SavedSearch) error {
+	if err := b.storer.Delete(ctx, ss); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByName finds the search the user saved for the entity by its name.
+func (b *Business) QueryByName(ctx context.Context, userID uuid.UUID, entity string, name string) (SavedSearch, error) {
+	ss, err := b.storer.QueryByName(ctx, userID, entity, name)
+	if err != nil {
+		return SavedSearch{}, fmt.Errorf("query: userID[%s] entity[%s] name[%s]: %w", userID, entity, name, err)
+	}
+
+	return ss, nil
+}
+
+// QueryByUserID returns every search the user has saved.
+func (b *Business) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]SavedSearch, error) {
+	searches, err := b.storer.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return searches, nil
+}
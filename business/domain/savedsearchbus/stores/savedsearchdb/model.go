@@ -0,0 +1,49 @@
+package savedsearchdb
+
+import (
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/savedsearchbus"
+	"github.com/google/uuid"
+)
+
+type savedSearch struct {
+	UserID      uuid.UUID `db:"user_id"`
+	Entity      string    `db:"entity"`
+	Name        string    `db:"name"`
+	Query       string    `db:"query"`
+	DateCreated time.Time `db:"date_created"`
+	DateUpdated time.Time `db:"date_updated"`
+}
+
+func toDBSavedSearch(bus savedsearchbus.SavedSearch) savedSearch {
+	return savedSearch{
+		UserID:      bus.UserID,
+		Entity:      bus.Entity,
+		Name:        bus.Name,
+		Query:       bus.Query,
+		DateCreated: bus.DateCreated.UTC(),
+		DateUpdated: bus.DateUpdated.UTC(),
+	}
+}
+
+func toBusSavedSearch(db savedSearch) savedsearchbus.SavedSearch {
+	return savedsearchbus.SavedSearch{
+		UserID:      db.UserID,
+		Entity:      db.Entity,
+		Name:        db.Name,
+		Query:       db.Query,
+		DateCreated: db.DateCreated.In(time.Local),
+		DateUpdated: db.DateUpdated.In(time.Local),
+	}
+}
+
+func toBusSavedSearches(dbs []savedSearch) []savedsearchbus.SavedSearch {
+	bus := make([]savedsearchbus.SavedSearch, len(dbs))
+
+	for i, db := range dbs {
+		bus[i] = toBusSavedSearch(db)
+	}
+
+	return bus
+}
@@ -0,0 +1,123 @@
+// Package savedsearchdb contains saved search related CRUD functionality.
+package savedsearchdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ardanlabs/encore/business/domain/savedsearchbus"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for saved search database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Upsert inserts the saved search or replaces the one already stored for
+// the user, entity and name.
+func (s *Store) Upsert(ctx context.Context, ss savedsearchbus.SavedSearch) error {
+	const q = `
+    INSERT INTO saved_searches
+        (user_id, entity, name, query, date_created, date_updated)
+    VALUES
+        (:user_id, :entity, :name, :query, :date_created, :date_updated)
+    ON CONFLICT (user_id, entity, name) DO UPDATE SET
+        query = EXCLUDED.query,
+        date_updated = EXCLUDED.date_updated`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBSavedSearch(ss)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a saved search from the database.
+func (s *Store) Delete(ctx context.Context, ss savedsearchbus.SavedSearch) error {
+	const q = `
+    DELETE FROM
+        saved_searches
+    WHERE
+        user_id = :user_id AND
+        entity = :entity AND
+        name = :name`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBSavedSearch(ss)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByName gets the search the user saved for the entity by its name.
+func (s *Store) QueryByName(ctx context.Context, userID uuid.UUID, entity string, name string) (savedsearchbus.SavedSearch, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+		Entity string `db:"entity"`
+		Name   string `db:"name"`
+	}{
+		UserID: userID.String(),
+		Entity: entity,
+		Name:   name,
+	}
+
+	const q = `
+    SELECT
+        user_id, entity, name, query, date_created, date_updated
+    FROM
+        saved_searches
+    WHERE
+        user_id = :user_id AND
+        entity = :entity AND
+        name = :name`
+
+	var dbSS savedSearch
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbSS); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return savedsearchbus.SavedSearch{}, fmt.Errorf("db: %w", savedsearchbus.ErrNotFound)
+		}
+		return savedsearchbus.SavedSearch{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusSavedSearch(dbSS), nil
+}
+
+// QueryByUserID gets every search the user has saved.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]savedsearchbus.SavedSearch, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+    SELECT
+        user_id, entity, name, query, date_created, date_updated
+    FROM
+        saved_searches
+    WHERE
+        user_id = :user_id
+    ORDER BY
+        entity, name`
+
+	var dbSSs []savedSearch
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbSSs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusSavedSearches(dbSSs), nil
+}
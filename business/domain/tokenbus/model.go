@@ -0,0 +1,25 @@
+package tokenbus
+
+import (
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/google/uuid"
+)
+
+// RefreshToken represents a refresh token issued to a user. Only the hash of
+// the token is stored, so the token itself is only known when it's issued.
+// The tokens rotated from the same login share a family, so the whole chain
+// can be revoked when a token is used twice. The roles are the ones the
+// login was granted, which the tokens issued for it never exceed.
+type RefreshToken struct {
+	ID          uuid.UUID
+	FamilyID    uuid.UUID
+	UserID      uuid.UUID
+	Roles       []userbus.Role
+	Token       string
+	Hash        string
+	Revoked     bool
+	DateExpires time.Time
+	DateCreated time.Time
+}
@@ -0,0 +1,55 @@
+package tokendb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/tokenbus"
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/sdk/sqldb/dbarray"
+	"github.com/google/uuid"
+)
+
+type refreshToken struct {
+	ID          uuid.UUID      `db:"token_id"`
+	FamilyID    uuid.UUID      `db:"family_id"`
+	UserID      uuid.UUID      `db:"user_id"`
+	Roles       dbarray.String `db:"roles"`
+	Hash        string         `db:"token_hash"`
+	Revoked     bool           `db:"revoked"`
+	DateExpires time.Time      `db:"date_expires"`
+	DateCreated time.Time      `db:"date_created"`
+}
+
+func toDBRefreshToken(bus tokenbus.RefreshToken) refreshToken {
+	return refreshToken{
+		ID:          bus.ID,
+		FamilyID:    bus.FamilyID,
+		UserID:      bus.UserID,
+		Roles:       userbus.ParseRolesToString(bus.Roles),
+		Hash:        bus.Hash,
+		Revoked:     bus.Revoked,
+		DateExpires: bus.DateExpires.UTC(),
+		DateCreated: bus.DateCreated.UTC(),
+	}
+}
+
+func toBusRefreshToken(db refreshToken) (tokenbus.RefreshToken, error) {
+	roles, err := userbus.ParseRoles(db.Roles)
+	if err != nil {
+		return tokenbus.RefreshToken{}, fmt.Errorf("parse roles: %w", err)
+	}
+
+	bus := tokenbus.RefreshToken{
+		ID:          db.ID,
+		FamilyID:    db.FamilyID,
+		UserID:      db.UserID,
+		Roles:       roles,
+		Hash:        db.Hash,
+		Revoked:     db.Revoked,
+		DateExpires: db.DateExpires.In(time.Local),
+		DateCreated: db.DateCreated.In(time.Local),
+	}
+
+	return bus, nil
+}
@@ -0,0 +1,193 @@
+// Package tokendb contains refresh token related CRUD functionality.
+package tokendb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/tokenbus"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for refresh token database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new refresh token into the database.
+func (s *Store) Create(ctx context.Context, rt tokenbus.RefreshToken) error {
+	const q = `
+    INSERT INTO refresh_tokens
+        (token_id, family_id, user_id, roles, token_hash, revoked, date_expires, date_created)
+    VALUES
+        (:token_id, :family_id, :user_id, :roles, :token_hash, :revoked, :date_expires, :date_created)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBRefreshToken(rt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Revoke marks the refresh token as revoked. ErrRevoked is returned when the
+// token was already revoked, so only one caller can revoke a token.
+func (s *Store) Revoke(ctx context.Context, rt tokenbus.RefreshToken) error {
+	data := struct {
+		ID string `db:"token_id"`
+	}{
+		ID: rt.ID.String(),
+	}
+
+	const q = `
+    WITH revoked AS (
+        UPDATE
+            refresh_tokens
+        SET
+            revoked = TRUE
+        WHERE
+            token_id = :token_id AND
+            revoked = FALSE
+        RETURNING 1
+    )
+    SELECT
+        count(1)
+    FROM
+        revoked`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return fmt.Errorf("db: %w", err)
+	}
+
+	if count.Count == 0 {
+		return fmt.Errorf("db: %w", tokenbus.ErrRevoked)
+	}
+
+	return nil
+}
+
+// RevokeFamily marks every refresh token in the family as revoked.
+func (s *Store) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	data := struct {
+		FamilyID string `db:"family_id"`
+	}{
+		FamilyID: familyID.String(),
+	}
+
+	const q = `
+    UPDATE
+        refresh_tokens
+    SET
+        revoked = TRUE
+    WHERE
+        family_id = :family_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeUser marks every refresh token of the user as revoked.
+func (s *Store) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+    UPDATE
+        refresh_tokens
+    SET
+        revoked = TRUE
+    WHERE
+        user_id = :user_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteBefore removes the refresh tokens that expired before the specified
+// time and returns the number removed.
+func (s *Store) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before.UTC(),
+	}
+
+	const q = `
+    WITH deleted AS (
+        DELETE FROM
+            refresh_tokens
+        WHERE
+            date_expires < :before
+        RETURNING 1
+    )
+    SELECT
+        count(1)
+    FROM
+        deleted`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByHash gets the refresh token with the hash from the database.
+func (s *Store) QueryByHash(ctx context.Context, hash string) (tokenbus.RefreshToken, error) {
+	data := struct {
+		Hash string `db:"token_hash"`
+	}{
+		Hash: hash,
+	}
+
+	const q = `
+    SELECT
+        token_id, family_id, user_id, roles, token_hash, revoked, date_expires, date_created
+    FROM
+        refresh_tokens
+    WHERE
+        token_hash = :token_hash`
+
+	var dbRT refreshToken
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbRT); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return tokenbus.RefreshToken{}, fmt.Errorf("db: %w", tokenbus.ErrNotFound)
+		}
+		return tokenbus.RefreshToken{}, fmt.Errorf("db: %w", err)
+	}
+
+	rt, err := toBusRefreshToken(dbRT)
+	if err != nil {
+		return tokenbus.RefreshToken{}, fmt.Errorf("db: %w", err)
+	}
+
+	return rt, nil
+}
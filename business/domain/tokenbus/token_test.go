@@ -0,0 +1,259 @@
+package tokenbus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"encore.dev/et"
+	"github.com/ardanlabs/encore/business/domain/tokenbus"
+	"github.com/ardanlabs/encore/business/domain/tokenbus/stores/tokendb"
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/sdk/dbtest"
+	"github.com/ardanlabs/encore/business/sdk/unitest"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+func Test_Token(t *testing.T) {
+	t.Parallel()
+
+	edb, err := et.NewTestDatabase(context.Background(), "app")
+	if err != nil {
+		t.Fatalf("Creating new database: %s", err)
+	}
+
+	db := dbtest.NewDatabase(t, edb)
+
+	userID, err := insertSeedData(db)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, rotate(db, userID), "rotate")
+	unitest.Run(t, revoke(db.BusDomain, userID), "revoke")
+	unitest.Run(t, deleteExpired(db, userID), "delete")
+}
+
+// =============================================================================
+
+func insertSeedData(db *dbtest.Database) (uuid.UUID, error) {
+	ctx, cancel := dbtest.Context()
+	defer cancel()
+
+	usrs, err := userbus.TestSeedUsers(ctx, db.Rand, 1, userbus.Roles.User, db.BusDomain.User)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	return usrs[0].ID, nil
+}
+
+var roles = []userbus.Role{userbus.Roles.User}
+
+// racingStore revokes a token before the business does, like another call
+// rotating the same token at the same time would.
+type racingStore struct {
+	*tokendb.Store
+}
+
+func (s racingStore) Revoke(ctx context.Context, rt tokenbus.RefreshToken) error {
+	if err := s.Store.Revoke(ctx, rt); err != nil {
+		return err
+	}
+
+	return s.Store.Revoke(ctx, rt)
+}
+
+func cmpError(got any, exp any) string {
+	gotErr, _ := got.(error)
+	if !errors.Is(gotErr, exp.(error)) {
+		return fmt.Sprintf("got %v, exp %v", got, exp)
+	}
+
+	return ""
+}
+
+func rotate(db *dbtest.Database, userID uuid.UUID) []unitest.Table {
+	busDomain := db.BusDomain
+
+	table := []unitest.Table{
+		{
+			Name:    "rotate",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				rt, err := busDomain.Token.Issue(ctx, userID, roles)
+				if err != nil {
+					return err
+				}
+
+				nrt, err := busDomain.Token.Rotate(ctx, rt.Token)
+				if err != nil {
+					return err
+				}
+
+				return nrt.FamilyID == rt.FamilyID && nrt.UserID == userID && nrt.Token != rt.Token && slices.Equal(nrt.Roles, roles)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "reuse",
+			ExpResp: tokenbus.ErrRevoked,
+			ExcFunc: func(ctx context.Context) any {
+				rt, err := busDomain.Token.Issue(ctx, userID, roles)
+				if err != nil {
+					return err
+				}
+
+				nrt, err := busDomain.Token.Rotate(ctx, rt.Token)
+				if err != nil {
+					return err
+				}
+
+				if _, err := busDomain.Token.Rotate(ctx, rt.Token); !errors.Is(err, tokenbus.ErrRevoked) {
+					return fmt.Errorf("should reject a reused token: %w", err)
+				}
+
+				// The token rotated from the reused one is revoked with the
+				// rest of the family.
+				_, err = busDomain.Token.Rotate(ctx, nrt.Token)
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+		{
+			Name:    "not-found",
+			ExpResp: tokenbus.ErrNotFound,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.Token.Rotate(ctx, "unknown")
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+		{
+			Name:    "expired",
+			ExpResp: tokenbus.ErrExpired,
+			ExcFunc: func(ctx context.Context) any {
+				rt, err := busDomain.Token.Issue(ctx, userID, roles)
+				if err != nil {
+					return err
+				}
+
+				db.Clock.Advance(2 * time.Hour)
+
+				_, err = busDomain.Token.Rotate(ctx, rt.Token)
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+		{
+			Name:    "lost-race",
+			ExpResp: tokenbus.ErrRevoked,
+			ExcFunc: func(ctx context.Context) any {
+				tokenBus := tokenbus.NewBusiness(db.Log, db.Clock, time.Hour, racingStore{tokendb.NewStore(db.Log, db.DB)})
+
+				rt, err := tokenBus.Issue(ctx, userID, roles)
+				if err != nil {
+					return err
+				}
+
+				_, err = tokenBus.Rotate(ctx, rt.Token)
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+	}
+
+	return table
+}
+
+func revoke(busDomain dbtest.BusDomain, userID uuid.UUID) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "family",
+			ExpResp: tokenbus.ErrRevoked,
+			ExcFunc: func(ctx context.Context) any {
+				rt, err := busDomain.Token.Issue(ctx, userID, roles)
+				if err != nil {
+					return err
+				}
+
+				nrt, err := busDomain.Token.Rotate(ctx, rt.Token)
+				if err != nil {
+					return err
+				}
+
+				if err := busDomain.Token.Revoke(ctx, rt.Token); err != nil {
+					return err
+				}
+
+				_, err = busDomain.Token.Rotate(ctx, nrt.Token)
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+		{
+			Name:    "user",
+			ExpResp: tokenbus.ErrRevoked,
+			ExcFunc: func(ctx context.Context) any {
+				rt, err := busDomain.Token.Issue(ctx, userID, roles)
+				if err != nil {
+					return err
+				}
+
+				if err := busDomain.Token.RevokeUser(ctx, userID); err != nil {
+					return err
+				}
+
+				_, err = busDomain.Token.Rotate(ctx, rt.Token)
+				return err
+			},
+			CmpFunc: cmpError,
+		},
+	}
+
+	return table
+}
+
+func deleteExpired(db *dbtest.Database, userID uuid.UUID) []unitest.Table {
+	busDomain := db.BusDomain
+
+	table := []unitest.Table{
+		{
+			Name:    "delete",
+			ExpResp: 9,
+			ExcFunc: func(ctx context.Context) any {
+				db.Clock.Advance(2 * time.Hour)
+
+				rt, err := busDomain.Token.Issue(ctx, userID, roles)
+				if err != nil {
+					return err
+				}
+
+				n, err := busDomain.Token.DeleteExpired(ctx)
+				if err != nil {
+					return err
+				}
+
+				// The token that hasn't expired is kept.
+				if _, err := busDomain.Token.Rotate(ctx, rt.Token); err != nil {
+					return err
+				}
+
+				return n
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,204 @@
+// Package tokenbus provides business access to the refresh tokens users trade
+// for new access tokens. A refresh token can only be used once: using it
+// rotates it for a new one, and using it again revokes every token rotated
+// from the same login.
+package tokenbus
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/foundation/clock"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for refresh token operations.
+var (
+	ErrNotFound = errors.New("refresh token not found")
+	ErrExpired  = errors.New("refresh token expired")
+	ErrRevoked  = errors.New("refresh token revoked")
+)
+
+// Storer interface declares the behaviour this package needs to persist and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, rt RefreshToken) error
+	Revoke(ctx context.Context, rt RefreshToken) error
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeUser(ctx context.Context, userID uuid.UUID) error
+	DeleteBefore(ctx context.Context, before time.Time) (int, error)
+	QueryByHash(ctx context.Context, hash string) (RefreshToken, error)
+}
+
+// Business manages the set of APIs for refresh token access.
+type Business struct {
+	log    *logger.Logger
+	clock  clock.Clock
+	ttl    time.Duration
+	storer Storer
+}
+
+// NewBusiness constructs a refresh token business API for use. Tokens can be
+// used for the ttl after they are issued.
+func NewBusiness(log *logger.Logger, clock clock.Clock, ttl time.Duration, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		clock:  clock,
+		ttl:    ttl,
+		storer: storer,
+	}
+}
+
+// Issue creates a refresh token for the user that starts a new family and
+// is bound to the roles the login was granted. The token is only returned
+// this once.
+func (b *Business) Issue(ctx context.Context, userID uuid.UUID, roles []userbus.Role) (RefreshToken, error) {
+	rt, err := b.issue(ctx, userID, uuid.New(), roles)
+	if err != nil {
+		return RefreshToken{}, fmt.Errorf("issue: userID[%s]: %w", userID, err)
+	}
+
+	return rt, nil
+}
+
+// Rotate revokes the refresh token and issues a new one in the same family.
+// A token that was already revoked is being used again, which means it has
+// leaked, so the whole family is revoked and ErrRevoked is returned.
+func (b *Business) Rotate(ctx context.Context, token string) (RefreshToken, error) {
+	rt, err := b.storer.QueryByHash(ctx, hash(token))
+	if err != nil {
+		return RefreshToken{}, fmt.Errorf("query: %w", err)
+	}
+
+	if rt.Revoked {
+		return RefreshToken{}, b.reused(ctx, rt)
+	}
+
+	if !b.clock.Now().Before(rt.DateExpires) {
+		return RefreshToken{}, fmt.Errorf("rotate: tokenID[%s]: %w", rt.ID, ErrExpired)
+	}
+
+	// Only one call can revoke the token, so when two calls race with the
+	// same token the one that loses is treated as a reuse.
+	if err := b.storer.Revoke(ctx, rt); err != nil {
+		if errors.Is(err, ErrRevoked) {
+			return RefreshToken{}, b.reused(ctx, rt)
+		}
+		return RefreshToken{}, fmt.Errorf("revoke: tokenID[%s]: %w", rt.ID, err)
+	}
+
+	nrt, err := b.issue(ctx, rt.UserID, rt.FamilyID, rt.Roles)
+	if err != nil {
+		return RefreshToken{}, fmt.Errorf("issue: userID[%s]: %w", rt.UserID, err)
+	}
+
+	return nrt, nil
+}
+
+// Revoke revokes the refresh token along with every token in its family, so
+// the login it came from can't be refreshed anymore.
+func (b *Business) Revoke(ctx context.Context, token string) error {
+	rt, err := b.storer.QueryByHash(ctx, hash(token))
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	if err := b.storer.RevokeFamily(ctx, rt.FamilyID); err != nil {
+		return fmt.Errorf("revokefamily: familyID[%s]: %w", rt.FamilyID, err)
+	}
+
+	return nil
+}
+
+// RevokeFamily revokes every refresh token in the family, so the login it
+// started can't be refreshed anymore.
+func (b *Business) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	if err := b.storer.RevokeFamily(ctx, familyID); err != nil {
+		return fmt.Errorf("revokefamily: familyID[%s]: %w", familyID, err)
+	}
+
+	return nil
+}
+
+// RevokeUser revokes every refresh token of the user.
+func (b *Business) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	if err := b.storer.RevokeUser(ctx, userID); err != nil {
+		return fmt.Errorf("revokeuser: userID[%s]: %w", userID, err)
+	}
+
+	return nil
+}
+
+// DeleteExpired removes the refresh tokens that have expired and returns the
+// number removed.
+func (b *Business) DeleteExpired(ctx context.Context) (int, error) {
+	n, err := b.storer.DeleteBefore(ctx, b.clock.Now())
+	if err != nil {
+		return 0, fmt.Errorf("deletebefore: %w", err)
+	}
+
+	return n, nil
+}
+
+// =============================================================================
+
+func (b *Business) issue(ctx context.Context, userID uuid.UUID, familyID uuid.UUID, roles []userbus.Role) (RefreshToken, error) {
+	token, err := generate()
+	if err != nil {
+		return RefreshToken{}, fmt.Errorf("generate: %w", err)
+	}
+
+	now := b.clock.Now()
+
+	rt := RefreshToken{
+		ID:          uuid.New(),
+		FamilyID:    familyID,
+		UserID:      userID,
+		Roles:       roles,
+		Token:       token,
+		Hash:        hash(token),
+		DateExpires: now.Add(b.ttl),
+		DateCreated: now,
+	}
+
+	if err := b.storer.Create(ctx, rt); err != nil {
+		return RefreshToken{}, fmt.Errorf("create: %w", err)
+	}
+
+	return rt, nil
+}
+
+// reused revokes the family of a token that was used after it was revoked.
+func (b *Business) reused(ctx context.Context, rt RefreshToken) error {
+	b.log.Info(ctx, "refresh token reused", "tokenID", rt.ID, "familyID", rt.FamilyID, "userID", rt.UserID)
+
+	if err := b.storer.RevokeFamily(ctx, rt.FamilyID); err != nil {
+		return fmt.Errorf("revokefamily: familyID[%s]: %w", rt.FamilyID, err)
+	}
+
+	return fmt.Errorf("rotate: tokenID[%s]: %w", rt.ID, ErrRevoked)
+}
+
+// generate returns a random token that is safe to use in a url.
+func generate() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hash returns the hash of the token that is stored in its place.
+func hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
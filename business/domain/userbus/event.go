@@ -13,7 +13,9 @@ const DomainName = "user"
 
 // Set of delegate actions.
 const (
+	ActionCreated = "created"
 	ActionUpdated = "updated"
+	ActionDeleted = "deleted"
 )
 
 // ActionUpdatedParms represents the parameters for the updated action.
@@ -52,3 +54,36 @@ func ActionUpdatedData(uu UpdateUser, userID uuid.UUID) delegate.Data {
 		RawParams: rawParams,
 	}
 }
+
+// ActionParms represents the parameters for the created and deleted actions.
+type ActionParms struct {
+	UserID uuid.UUID
+}
+
+// String returns a string representation of the action parameters.
+func (ap *ActionParms) String() string {
+	return fmt.Sprintf("&EventParams{UserID:%v}", ap.UserID)
+}
+
+// Marshal returns the event parameters encoded as JSON.
+func (ap *ActionParms) Marshal() ([]byte, error) {
+	return json.Marshal(ap)
+}
+
+// ActionData constructs the data for the specified action on the user.
+func ActionData(action string, userID uuid.UUID) delegate.Data {
+	params := ActionParms{
+		UserID: userID,
+	}
+
+	rawParams, err := params.Marshal()
+	if err != nil {
+		panic(err)
+	}
+
+	return delegate.Data{
+		Domain:    DomainName,
+		Action:    action,
+		RawParams: rawParams,
+	}
+}
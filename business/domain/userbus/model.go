@@ -29,6 +29,18 @@ type NewUser struct {
 	Password   string
 }
 
+// PasswordReset represents a request to reset the password of a user. Only
+// the hash of the token is stored, so the token itself is only known when the
+// reset is requested.
+type PasswordReset struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Token       string
+	Hash        string
+	DateExpires time.Time
+	DateCreated time.Time
+}
+
 // UpdateUser contains information needed to update a user.
 type UpdateUser struct {
 	Name       *Name
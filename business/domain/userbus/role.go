@@ -1,6 +1,6 @@
 package userbus
 
-import "fmt"
+import "github.com/ardanlabs/encore/business/sdk/enum"
 
 type roleSet struct {
 	Admin Role
@@ -15,8 +15,8 @@ var Roles = roleSet{
 
 // =============================================================================
 
-// Set of known roles.
-var roles = make(map[string]Role)
+// Set of known roles, registered for the enum validation tag.
+var roles = enum.New[Role]("role")
 
 // Role represents a role in the system.
 type Role struct {
@@ -24,9 +24,7 @@ type Role struct {
 }
 
 func newRole(role string) Role {
-	r := Role{role}
-	roles[role] = r
-	return r
+	return roles.Add(Role{role})
 }
 
 // String returns the name of the role.
@@ -43,12 +41,7 @@ func (r Role) Equal(r2 Role) bool {
 
 // ParseRole parses the string value and returns a role if one exists.
 func ParseRole(value string) (Role, error) {
-	role, exists := roles[value]
-	if !exists {
-		return Role{}, fmt.Errorf("invalid role %q", value)
-	}
-
-	return role, nil
+	return roles.Parse(value)
 }
 
 // MustParseRole parses the string value and returns a role if one exists. If
@@ -3,38 +3,55 @@ package usercache
 
 import (
 	"context"
+	"errors"
+	"iter"
 	"net/mail"
 	"time"
 
 	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/sdk/cachemetrics"
 	"github.com/ardanlabs/encore/business/sdk/order"
 	"github.com/ardanlabs/encore/business/sdk/page"
 	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/business/sdk/storecache"
+	"github.com/ardanlabs/encore/foundation/clock"
 	"github.com/ardanlabs/encore/foundation/logger"
 	"github.com/google/uuid"
-	"github.com/viccon/sturdyc"
 )
 
 // Store manages the set of APIs for user data and caching.
 type Store struct {
 	log    *logger.Logger
 	storer userbus.Storer
-	cache  *sturdyc.Client[userbus.User]
+	cache  *storecache.Cache[userbus.User]
 }
 
-// NewStore constructs the api for data and caching access.
-func NewStore(log *logger.Logger, storer userbus.Storer, ttl time.Duration) *Store {
-	const capacity = 10000
-	const numShards = 10
-	const evictionPercentage = 10
+// CacheName is the name the cache reports its metrics with.
+const CacheName = "users"
+
+// NewStore constructs the api for data and caching access. The users are
+// cached by their id and email.
+func NewStore(log *logger.Logger, storer userbus.Storer, cfg storecache.Config, clock clock.Clock, counters cachemetrics.Counters) *Store {
+	entity := storecache.Entity[userbus.User]{
+		Name:     CacheName,
+		NotFound: userbus.ErrNotFound,
+		ID:       func(usr userbus.User) string { return usr.ID.String() },
+		Keys:     []func(userbus.User) string{func(usr userbus.User) string { return usr.Email.Address }},
+	}
 
 	return &Store{
 		log:    log,
 		storer: storer,
-		cache:  sturdyc.New[userbus.User](capacity, numShards, ttl, evictionPercentage),
+		cache:  storecache.New(entity, cfg, clock, counters),
 	}
 }
 
+// Stats returns what the cache has done with the top number of users it
+// has served the most, keyed by their id.
+func (s *Store) Stats(top int) cachemetrics.Stats {
+	return s.cache.Stats(top)
+}
+
 // NewWithTx constructs a new Store value replacing the sqlx DB
 // value with a sqlx DB value that is currently inside a transaction.
 func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (userbus.Storer, error) {
@@ -47,18 +64,24 @@ func (s *Store) Create(ctx context.Context, usr userbus.User) error {
 		return err
 	}
 
-	s.writeCache(usr)
+	s.cache.Set(usr)
 
 	return nil
 }
 
 // Update replaces a user document in the database.
-func (s *Store) Update(ctx context.Context, usr userbus.User) error {
-	if err := s.storer.Update(ctx, usr); err != nil {
+func (s *Store) Update(ctx context.Context, usr userbus.User, lastUpdated time.Time) error {
+	if err := s.storer.Update(ctx, usr, lastUpdated); err != nil {
+
+		// The cached user is out of date when another change got there
+		// first.
+		if errors.Is(err, userbus.ErrModified) {
+			s.cache.Delete(usr)
+		}
 		return err
 	}
 
-	s.writeCache(usr)
+	s.cache.Set(usr)
 
 	return nil
 }
@@ -69,7 +92,7 @@ func (s *Store) Delete(ctx context.Context, usr userbus.User) error {
 		return err
 	}
 
-	s.deleteCache(usr)
+	s.cache.Delete(usr)
 
 	return nil
 }
@@ -79,6 +102,17 @@ func (s *Store) Query(ctx context.Context, filter userbus.QueryFilter, orderBy o
 	return s.storer.Query(ctx, filter, orderBy, page)
 }
 
+// QueryByKeyset retrieves a list of existing users from the database using
+// keyset paging.
+func (s *Store) QueryByKeyset(ctx context.Context, filter userbus.QueryFilter, keyset page.Keyset) ([]userbus.User, error) {
+	return s.storer.QueryByKeyset(ctx, filter, keyset)
+}
+
+// QueryStream retrieves the existing users from the database one at a time.
+func (s *Store) QueryStream(ctx context.Context, filter userbus.QueryFilter, orderBy order.By) iter.Seq2[userbus.User, error] {
+	return s.storer.QueryStream(ctx, filter, orderBy)
+}
+
 // Count returns the total number of cards in the DB.
 func (s *Store) Count(ctx context.Context, filter userbus.QueryFilter) (int, error) {
 	return s.storer.Count(ctx, filter)
@@ -86,56 +120,42 @@ func (s *Store) Count(ctx context.Context, filter userbus.QueryFilter) (int, err
 
 // QueryByID gets the specified user from the database.
 func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User, error) {
-	cachedUsr, ok := s.readCache(userID.String())
-	if ok {
-		return cachedUsr, nil
-	}
-
-	usr, err := s.storer.QueryByID(ctx, userID)
-	if err != nil {
-		return userbus.User{}, err
-	}
-
-	s.writeCache(usr)
+	return s.cache.Get(ctx, userID.String(), func(ctx context.Context) (userbus.User, error) {
+		return s.storer.QueryByID(ctx, userID)
+	})
+}
 
-	return usr, nil
+// QueryByIDs gets the specified users from the database.
+func (s *Store) QueryByIDs(ctx context.Context, userIDs []uuid.UUID) ([]userbus.User, error) {
+	return s.storer.QueryByIDs(ctx, userIDs)
 }
 
 // QueryByEmail gets the specified user from the database by email.
 func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.User, error) {
-	cachedUsr, ok := s.readCache(email.Address)
-	if ok {
-		return cachedUsr, nil
-	}
-
-	usr, err := s.storer.QueryByEmail(ctx, email)
-	if err != nil {
-		return userbus.User{}, err
-	}
-
-	s.writeCache(usr)
-
-	return usr, nil
+	return s.cache.Get(ctx, email.Address, func(ctx context.Context) (userbus.User, error) {
+		return s.storer.QueryByEmail(ctx, email)
+	})
 }
 
-// readCache performs a safe search in the cache for the specified key.
-func (s *Store) readCache(key string) (userbus.User, bool) {
-	usr, exists := s.cache.Get(key)
-	if !exists {
-		return userbus.User{}, false
-	}
+// CreatePasswordReset inserts a new password reset into the database.
+func (s *Store) CreatePasswordReset(ctx context.Context, pr userbus.PasswordReset) error {
+	return s.storer.CreatePasswordReset(ctx, pr)
+}
 
-	return usr, true
+// ConsumePasswordReset removes the password reset with the hash from the
+// database and returns it.
+func (s *Store) ConsumePasswordReset(ctx context.Context, hash string) (userbus.PasswordReset, error) {
+	return s.storer.ConsumePasswordReset(ctx, hash)
 }
 
-// writeCache performs a safe write to the cache for the specified userbus.
-func (s *Store) writeCache(bus userbus.User) {
-	s.cache.Set(bus.ID.String(), bus)
-	s.cache.Set(bus.Email.Address, bus)
+// DeletePasswordResets removes the password resets of the user from the
+// database.
+func (s *Store) DeletePasswordResets(ctx context.Context, userID uuid.UUID) error {
+	return s.storer.DeletePasswordResets(ctx, userID)
 }
 
-// deleteCache performs a safe removal from the cache for the specified userbus.
-func (s *Store) deleteCache(bus userbus.User) {
-	s.cache.Delete(bus.ID.String())
-	s.cache.Delete(bus.Email.Address)
+// DeletePasswordResetsBefore removes the password resets that expired before
+// the specified time and returns the number removed.
+func (s *Store) DeletePasswordResetsBefore(ctx context.Context, before time.Time) (int, error) {
+	return s.storer.DeletePasswordResetsBefore(ctx, before)
 }
@@ -0,0 +1,412 @@
+package usercache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/mail"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/domain/userbus/stores/usercache"
+	"github.com/ardanlabs/encore/business/domain/userbus/stores/usermock"
+	"github.com/ardanlabs/encore/business/sdk/racetest"
+	"github.com/ardanlabs/encore/business/sdk/storecache"
+	"github.com/ardanlabs/encore/foundation/clock"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+)
+
+func Test_Expiry(t *testing.T) {
+	ctx := context.Background()
+
+	log := logger.NewWithHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), logger.Events{}, nil)
+	clk := clock.NewFrozen(time.Now())
+
+	mock := usermock.NewStore()
+	store := usercache.NewStore(log, mock, storecache.Config{TTL: time.Minute}, clk, nil)
+
+	usr := userbus.User{
+		ID:    uuid.New(),
+		Email: mail.Address{Address: "bill@example.com"},
+	}
+
+	if err := store.Create(ctx, usr); err != nil {
+		t.Fatalf("Should be able to create the user: %s", err)
+	}
+
+	if _, err := store.QueryByID(ctx, usr.ID); err != nil {
+		t.Fatalf("Should be able to query the user: %s", err)
+	}
+
+	if calls := mock.CallsTo("QueryByID"); len(calls) != 0 {
+		t.Fatalf("Should get the user from the cache, got %d calls to the store", len(calls))
+	}
+
+	clk.Advance(2 * time.Minute)
+
+	if _, err := store.QueryByID(ctx, usr.ID); err != nil {
+		t.Fatalf("Should be able to query the user: %s", err)
+	}
+
+	if calls := mock.CallsTo("QueryByID"); len(calls) != 1 {
+		t.Fatalf("Should get the user from the store once the entry expires, got %d calls", len(calls))
+	}
+}
+
+func Test_Refresh(t *testing.T) {
+	ctx := context.Background()
+
+	log := logger.NewWithHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), logger.Events{}, nil)
+	clk := clock.NewFrozen(time.Now())
+
+	cfg := storecache.Config{
+		TTL:        10 * time.Minute,
+		RefreshMin: time.Minute,
+		RefreshMax: time.Minute,
+	}
+
+	mock := usermock.NewStore()
+	store := usercache.NewStore(log, mock, cfg, clk, nil)
+
+	usr := userbus.User{
+		ID:    uuid.New(),
+		Name:  userbus.MustParseName("Bill"),
+		Email: mail.Address{Address: "bill@example.com"},
+	}
+
+	if err := store.Create(ctx, usr); err != nil {
+		t.Fatalf("Should be able to create the user: %s", err)
+	}
+
+	// The user is changed behind the cache's back, like by another instance.
+	usr.Name = userbus.MustParseName("William")
+	if err := mock.Update(ctx, usr, usr.DateUpdated); err != nil {
+		t.Fatalf("Should be able to update the user: %s", err)
+	}
+
+	got, err := store.QueryByID(ctx, usr.ID)
+	if err != nil {
+		t.Fatalf("Should be able to query the user: %s", err)
+	}
+
+	if got.Name.String() != "Bill" {
+		t.Fatalf("Should get the cached user before the refresh window, got %s", got.Name)
+	}
+
+	clk.Advance(2 * time.Minute)
+
+	if got, _ := store.QueryByID(ctx, usr.ID); got.Name.String() != "Bill" {
+		t.Fatalf("Should get the cached user while it refreshes, got %s", got.Name)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		got, err := store.QueryByID(ctx, usr.ID)
+		if err != nil {
+			t.Fatalf("Should be able to query the user: %s", err)
+		}
+
+		if got.Name.String() == "William" {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("Should refresh the user in the background, got %s", got.Name)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func Test_Coalesce(t *testing.T) {
+	const requests = 10
+
+	ctx := context.Background()
+
+	log := logger.NewWithHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), logger.Events{}, nil)
+
+	mock := usermock.NewStore()
+	slow := slowStore{Storer: mock, release: make(chan struct{})}
+	store := usercache.NewStore(log, &slow, storecache.Config{TTL: time.Hour}, clock.System{}, nil)
+
+	usr := userbus.User{
+		ID:    uuid.New(),
+		Email: mail.Address{Address: "bill@example.com"},
+	}
+
+	// The user is only in the store, so every request misses the cache.
+	if err := mock.Create(ctx, usr); err != nil {
+		t.Fatalf("Should be able to create the user: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(requests)
+
+	for range requests {
+		go func() {
+			defer wg.Done()
+
+			if _, err := store.QueryByID(ctx, usr.ID); err != nil {
+				t.Errorf("Should be able to query the user: %s", err)
+			}
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(slow.release)
+	wg.Wait()
+
+	if calls := slow.calls.Load(); calls != 1 {
+		t.Fatalf("Should share a single call to the store, got %d calls", calls)
+	}
+
+	if _, err := store.QueryByID(ctx, uuid.New()); !errors.Is(err, userbus.ErrNotFound) {
+		t.Fatalf("Should get not found for an unknown user, got %v", err)
+	}
+}
+
+// slowStore holds the queries for a user until it's released.
+type slowStore struct {
+	userbus.Storer
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (s *slowStore) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User, error) {
+	s.calls.Add(1)
+	<-s.release
+
+	return s.Storer.QueryByID(ctx, userID)
+}
+
+func Test_Missing(t *testing.T) {
+	ctx := context.Background()
+
+	log := logger.NewWithHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), logger.Events{}, nil)
+
+	cfg := storecache.Config{
+		TTL:        time.Hour,
+		MissingTTL: time.Minute,
+	}
+
+	mock := usermock.NewStore()
+	store := usercache.NewStore(log, mock, cfg, clock.System{}, nil)
+
+	email := mail.Address{Address: "probe@example.com"}
+
+	for range 3 {
+		if _, err := store.QueryByEmail(ctx, email); !errors.Is(err, userbus.ErrNotFound) {
+			t.Fatalf("Should get not found for an unknown email, got %v", err)
+		}
+	}
+
+	if calls := mock.CallsTo("QueryByEmail"); len(calls) != 1 {
+		t.Fatalf("Should remember the email is missing, got %d calls to the store", len(calls))
+	}
+
+	usr := userbus.User{ID: uuid.New(), Email: email}
+	if err := store.Create(ctx, usr); err != nil {
+		t.Fatalf("Should be able to create the user: %s", err)
+	}
+
+	if _, err := store.QueryByEmail(ctx, email); err != nil {
+		t.Fatalf("Should get the user once it's created: %s", err)
+	}
+}
+
+func Test_Stats(t *testing.T) {
+	ctx := context.Background()
+
+	log := logger.NewWithHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), logger.Events{}, nil)
+
+	var counters counters
+	store := usercache.NewStore(log, usermock.NewStore(), storecache.Config{TTL: time.Hour}, clock.System{}, &counters)
+
+	hot := userbus.User{ID: uuid.New(), Email: mail.Address{Address: "hot@example.com"}}
+	cold := userbus.User{ID: uuid.New(), Email: mail.Address{Address: "cold@example.com"}}
+
+	for _, usr := range []userbus.User{hot, cold} {
+		if err := store.Create(ctx, usr); err != nil {
+			t.Fatalf("Should be able to create the user: %s", err)
+		}
+	}
+
+	store.QueryByID(ctx, hot.ID)
+	store.QueryByID(ctx, hot.ID)
+	store.QueryByEmail(ctx, hot.Email)
+	store.QueryByID(ctx, cold.ID)
+	store.QueryByID(ctx, uuid.New())
+
+	stats := store.Stats(1)
+
+	if stats.Cache != usercache.CacheName || stats.Size != 4 {
+		t.Fatalf("Should report the name and the 4 keys of the cache, got %q %d", stats.Cache, stats.Size)
+	}
+
+	if stats.Hits != 4 || stats.Misses != 1 {
+		t.Fatalf("Should report 4 hits and 1 miss, got %d hits %d misses", stats.Hits, stats.Misses)
+	}
+
+	if counters.hits != stats.Hits || counters.misses != stats.Misses {
+		t.Fatalf("Should report the hits and misses to the counters, got %d hits %d misses", counters.hits, counters.misses)
+	}
+
+	if len(stats.Hottest) != 1 || stats.Hottest[0].Key != hot.ID.String() || stats.Hottest[0].Hits != 3 {
+		t.Fatalf("Should report the hot user with 3 hits by id and email, got %+v", stats.Hottest)
+	}
+
+	if err := store.Delete(ctx, hot); err != nil {
+		t.Fatalf("Should be able to delete the user: %s", err)
+	}
+
+	stats = store.Stats(10)
+
+	if len(stats.Hottest) != 1 || stats.Hottest[0].Key != cold.ID.String() {
+		t.Fatalf("Should stop reporting the deleted user, got %+v", stats.Hottest)
+	}
+}
+
+type counters struct {
+	mu        sync.Mutex
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+func (c *counters) IncCacheHit(cache string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hits++
+}
+
+func (c *counters) IncCacheMiss(cache string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.misses++
+}
+
+func (c *counters) AddCacheEvictions(cache string, n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evictions += uint64(n)
+}
+
+// Test_Race hammers the cache with concurrent calls for the race detector.
+// The users shared by the workers are only read and updated, since the cache
+// doesn't order the writes made by concurrent calls for the same user. The
+// invariants are checked on the users that belong to a worker.
+func Test_Race(t *testing.T) {
+	const workers = 8
+
+	log := logger.NewWithHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), logger.Events{}, nil)
+
+	mock := usermock.NewStore()
+	store := usercache.NewStore(log, mock, storecache.Config{TTL: time.Hour}, clock.System{}, nil)
+
+	newUser := func(name string) userbus.User {
+		return userbus.User{
+			ID:    uuid.New(),
+			Name:  userbus.MustParseName(name),
+			Email: mail.Address{Address: name + "@example.com"},
+		}
+	}
+
+	shared := make([]userbus.User, 4)
+	for i := range shared {
+		shared[i] = newUser(fmt.Sprintf("shared%d", i))
+	}
+
+	owned := make([]userbus.User, workers)
+	for i := range owned {
+		owned[i] = newUser(fmt.Sprintf("worker%d", i))
+	}
+
+	for _, usr := range append(shared, owned...) {
+		if err := store.Create(context.Background(), usr); err != nil {
+			t.Fatalf("Should be able to create the user: %s", err)
+		}
+	}
+
+	// -------------------------------------------------------------------------
+
+	readShared := racetest.Op{
+		Name:   "read shared",
+		Weight: 4,
+		Run: func(ctx context.Context, worker int, rnd *rand.Rand) error {
+			usr := shared[rnd.Intn(len(shared))]
+
+			if _, err := store.QueryByID(ctx, usr.ID); err != nil {
+				return err
+			}
+
+			_, err := store.QueryByEmail(ctx, usr.Email)
+			return err
+		},
+	}
+
+	updateShared := racetest.Op{
+		Name:   "update shared",
+		Weight: 2,
+		Run: func(ctx context.Context, worker int, rnd *rand.Rand) error {
+			usr := shared[rnd.Intn(len(shared))]
+			usr.Name = userbus.MustParseName(fmt.Sprintf("Name %d", rnd.Intn(1000)))
+
+			return store.Update(ctx, usr, usr.DateUpdated)
+		},
+	}
+
+	updateOwned := racetest.Op{
+		Name:   "update owned",
+		Weight: 2,
+		Run: func(ctx context.Context, worker int, rnd *rand.Rand) error {
+			usr := owned[worker]
+			usr.Name = userbus.MustParseName(fmt.Sprintf("Name %d", rnd.Intn(1000)))
+
+			if err := store.Update(ctx, usr, usr.DateUpdated); err != nil {
+				return err
+			}
+
+			got, err := store.QueryByID(ctx, usr.ID)
+			if err != nil {
+				return err
+			}
+
+			if got.Name != usr.Name {
+				return fmt.Errorf("stale entry after update: got %s, exp %s", got.Name, usr.Name)
+			}
+
+			return nil
+		},
+	}
+
+	deleteOwned := racetest.Op{
+		Name:   "delete owned",
+		Weight: 1,
+		Run: func(ctx context.Context, worker int, rnd *rand.Rand) error {
+			usr := owned[worker]
+
+			if err := store.Delete(ctx, usr); err != nil {
+				return err
+			}
+
+			if _, err := store.QueryByID(ctx, usr.ID); !errors.Is(err, userbus.ErrNotFound) {
+				return fmt.Errorf("stale entry by id after delete: %v", err)
+			}
+
+			if _, err := store.QueryByEmail(ctx, usr.Email); !errors.Is(err, userbus.ErrNotFound) {
+				return fmt.Errorf("stale entry by email after delete: %v", err)
+			}
+
+			return store.Create(ctx, usr)
+		},
+	}
+
+	racetest.Run(t, racetest.Config{Workers: workers}, readShared, updateShared, updateOwned, deleteOwned)
+}
@@ -0,0 +1,177 @@
+// Package userchaos contains a user store that injects faults into the calls
+// made to another user store, for testing how the failures are handled.
+package userchaos
+
+import (
+	"context"
+	"iter"
+	"net/mail"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/sdk/chaos"
+	"github.com/ardanlabs/encore/business/sdk/order"
+	"github.com/ardanlabs/encore/business/sdk/page"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/google/uuid"
+)
+
+// Store manages the set of APIs for user access with injected faults.
+type Store struct {
+	storer   userbus.Storer
+	injector *chaos.Injector
+}
+
+// NewStore constructs a store that injects the faults chosen by the
+// injector before calling the storer.
+func NewStore(storer userbus.Storer, injector *chaos.Injector) *Store {
+	return &Store{
+		storer:   storer,
+		injector: injector,
+	}
+}
+
+// NewWithTx constructs a new Store value that injects faults into the calls
+// made inside the transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (userbus.Storer, error) {
+	if err := s.injector.Inject(context.Background(), "NewWithTx"); err != nil {
+		return nil, err
+	}
+
+	storer, err := s.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStore(storer, s.injector), nil
+}
+
+// Create inserts a new user.
+func (s *Store) Create(ctx context.Context, usr userbus.User) error {
+	if err := s.injector.Inject(ctx, "Create"); err != nil {
+		return err
+	}
+
+	return s.storer.Create(ctx, usr)
+}
+
+// Update replaces a user.
+func (s *Store) Update(ctx context.Context, usr userbus.User, lastUpdated time.Time) error {
+	if err := s.injector.Inject(ctx, "Update"); err != nil {
+		return err
+	}
+
+	return s.storer.Update(ctx, usr, lastUpdated)
+}
+
+// Delete removes a user.
+func (s *Store) Delete(ctx context.Context, usr userbus.User) error {
+	if err := s.injector.Inject(ctx, "Delete"); err != nil {
+		return err
+	}
+
+	return s.storer.Delete(ctx, usr)
+}
+
+// Query retrieves a list of existing users.
+func (s *Store) Query(ctx context.Context, filter userbus.QueryFilter, orderBy order.By, page page.Page) ([]userbus.User, error) {
+	if err := s.injector.Inject(ctx, "Query"); err != nil {
+		return nil, err
+	}
+
+	return s.storer.Query(ctx, filter, orderBy, page)
+}
+
+// QueryByKeyset retrieves a list of existing users using keyset paging.
+func (s *Store) QueryByKeyset(ctx context.Context, filter userbus.QueryFilter, keyset page.Keyset) ([]userbus.User, error) {
+	if err := s.injector.Inject(ctx, "QueryByKeyset"); err != nil {
+		return nil, err
+	}
+
+	return s.storer.QueryByKeyset(ctx, filter, keyset)
+}
+
+// QueryStream retrieves the existing users one at a time.
+func (s *Store) QueryStream(ctx context.Context, filter userbus.QueryFilter, orderBy order.By) iter.Seq2[userbus.User, error] {
+	if err := s.injector.Inject(ctx, "QueryStream"); err != nil {
+		return func(yield func(userbus.User, error) bool) {
+			yield(userbus.User{}, err)
+		}
+	}
+
+	return s.storer.QueryStream(ctx, filter, orderBy)
+}
+
+// Count returns the total number of users.
+func (s *Store) Count(ctx context.Context, filter userbus.QueryFilter) (int, error) {
+	if err := s.injector.Inject(ctx, "Count"); err != nil {
+		return 0, err
+	}
+
+	return s.storer.Count(ctx, filter)
+}
+
+// QueryByID gets the specified user.
+func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User, error) {
+	if err := s.injector.Inject(ctx, "QueryByID"); err != nil {
+		return userbus.User{}, err
+	}
+
+	return s.storer.QueryByID(ctx, userID)
+}
+
+// QueryByIDs gets the specified users.
+func (s *Store) QueryByIDs(ctx context.Context, userIDs []uuid.UUID) ([]userbus.User, error) {
+	if err := s.injector.Inject(ctx, "QueryByIDs"); err != nil {
+		return nil, err
+	}
+
+	return s.storer.QueryByIDs(ctx, userIDs)
+}
+
+// QueryByEmail gets the specified user by email.
+func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.User, error) {
+	if err := s.injector.Inject(ctx, "QueryByEmail"); err != nil {
+		return userbus.User{}, err
+	}
+
+	return s.storer.QueryByEmail(ctx, email)
+}
+
+// CreatePasswordReset inserts a new password reset.
+func (s *Store) CreatePasswordReset(ctx context.Context, pr userbus.PasswordReset) error {
+	if err := s.injector.Inject(ctx, "CreatePasswordReset"); err != nil {
+		return err
+	}
+
+	return s.storer.CreatePasswordReset(ctx, pr)
+}
+
+// ConsumePasswordReset removes the password reset with the hash and returns
+// it.
+func (s *Store) ConsumePasswordReset(ctx context.Context, hash string) (userbus.PasswordReset, error) {
+	if err := s.injector.Inject(ctx, "ConsumePasswordReset"); err != nil {
+		return userbus.PasswordReset{}, err
+	}
+
+	return s.storer.ConsumePasswordReset(ctx, hash)
+}
+
+// DeletePasswordResets removes the password resets of the user.
+func (s *Store) DeletePasswordResets(ctx context.Context, userID uuid.UUID) error {
+	if err := s.injector.Inject(ctx, "DeletePasswordResets"); err != nil {
+		return err
+	}
+
+	return s.storer.DeletePasswordResets(ctx, userID)
+}
+
+// DeletePasswordResetsBefore removes the password resets that expired before
+// the specified time.
+func (s *Store) DeletePasswordResetsBefore(ctx context.Context, before time.Time) (int, error) {
+	if err := s.injector.Inject(ctx, "DeletePasswordResetsBefore"); err != nil {
+		return 0, err
+	}
+
+	return s.storer.DeletePasswordResetsBefore(ctx, before)
+}
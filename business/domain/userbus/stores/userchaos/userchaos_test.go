@@ -0,0 +1,55 @@
+package userchaos_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/mail"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/domain/userbus/stores/usercache"
+	"github.com/ardanlabs/encore/business/domain/userbus/stores/userchaos"
+	"github.com/ardanlabs/encore/business/domain/userbus/stores/usermock"
+	"github.com/ardanlabs/encore/business/sdk/chaos"
+	"github.com/ardanlabs/encore/business/sdk/storecache"
+	"github.com/ardanlabs/encore/foundation/clock"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+)
+
+func Test_Cache(t *testing.T) {
+	ctx := context.Background()
+
+	log := logger.NewWithHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), logger.Events{}, nil)
+
+	mock := usermock.NewStore()
+	inj := chaos.New(chaos.Config{
+		ErrorRate: 1,
+		Methods:   []string{"QueryByID"},
+	})
+	store := usercache.NewStore(log, userchaos.NewStore(mock, inj), storecache.Config{TTL: time.Hour}, clock.System{}, nil)
+
+	usr := userbus.User{
+		ID:    uuid.New(),
+		Email: mail.Address{Address: "bill@example.com"},
+	}
+
+	if err := store.Create(ctx, usr); err != nil {
+		t.Fatalf("Should be able to create the user: %s", err)
+	}
+
+	if _, err := store.QueryByID(ctx, usr.ID); err != nil {
+		t.Fatalf("Should get the user from the cache while the store fails: %s", err)
+	}
+
+	if calls := mock.CallsTo("QueryByID"); len(calls) != 0 {
+		t.Fatalf("Should not call the store for a cached user, got %d calls", len(calls))
+	}
+
+	if _, err := store.QueryByID(ctx, uuid.New()); !errors.Is(err, chaos.ErrTransient) {
+		t.Fatalf("Should get the store failure for a user that isn't cached, got %v", err)
+	}
+}
@@ -92,3 +92,33 @@ func toBusUsers(dbs []user) ([]userbus.User, error) {
 
 	return bus, nil
 }
+
+// =============================================================================
+
+type passwordReset struct {
+	ID          uuid.UUID `db:"reset_id"`
+	UserID      uuid.UUID `db:"user_id"`
+	Hash        string    `db:"token_hash"`
+	DateExpires time.Time `db:"date_expires"`
+	DateCreated time.Time `db:"date_created"`
+}
+
+func toDBPasswordReset(bus userbus.PasswordReset) passwordReset {
+	return passwordReset{
+		ID:          bus.ID,
+		UserID:      bus.UserID,
+		Hash:        bus.Hash,
+		DateExpires: bus.DateExpires.UTC(),
+		DateCreated: bus.DateCreated.UTC(),
+	}
+}
+
+func toBusPasswordReset(db passwordReset) userbus.PasswordReset {
+	return userbus.PasswordReset{
+		ID:          db.ID,
+		UserID:      db.UserID,
+		Hash:        db.Hash,
+		DateExpires: db.DateExpires.In(time.Local),
+		DateCreated: db.DateCreated.In(time.Local),
+	}
+}
@@ -6,15 +6,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"iter"
 	"net/mail"
+	"time"
 
 	"github.com/ardanlabs/encore/business/domain/userbus"
 	"github.com/ardanlabs/encore/business/sdk/order"
 	"github.com/ardanlabs/encore/business/sdk/page"
 	"github.com/ardanlabs/encore/business/sdk/sqldb"
 	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/ardanlabs/encore/foundation/otel"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Store manages the set of APIs for user database access.
@@ -49,6 +53,9 @@ func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (userbus.Storer, error) {
 
 // Create inserts a new user into the database.
 func (s *Store) Create(ctx context.Context, usr userbus.User) error {
+	ctx, span := otel.AddSpan(ctx, "business.userdb.create", attribute.String("db.sql.table", "users"))
+	defer span.End()
+
 	const q = `
 	INSERT INTO users
 		(user_id, name, email, password_hash, roles, department, enabled, date_created, date_updated)
@@ -65,8 +72,13 @@ func (s *Store) Create(ctx context.Context, usr userbus.User) error {
 	return nil
 }
 
-// Update replaces a user document in the database.
-func (s *Store) Update(ctx context.Context, usr userbus.User) error {
+// Update replaces a user document in the database. The user is only replaced
+// when it was last updated at the specified time, otherwise ErrModified is
+// returned so a concurrent change isn't lost.
+func (s *Store) Update(ctx context.Context, usr userbus.User, lastUpdated time.Time) error {
+	ctx, span := otel.AddSpan(ctx, "business.userdb.update", attribute.String("db.sql.table", "users"))
+	defer span.End()
+
 	const q = `
 	UPDATE
 		users
@@ -79,28 +91,54 @@ func (s *Store) Update(ctx context.Context, usr userbus.User) error {
 		"enabled" = :enabled,
 		"date_updated" = :date_updated
 	WHERE
-		user_id = :user_id`
+		user_id = :user_id AND
+		date_updated = :last_updated
+	RETURNING
+		user_id`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBUser(usr)); err != nil {
+	data := struct {
+		user
+		LastUpdated time.Time `db:"last_updated"`
+	}{
+		user:        toDBUser(usr),
+		LastUpdated: lastUpdated.UTC(),
+	}
+
+	var dbUsr user
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbUsr); err != nil {
 		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
 			return userbus.ErrUniqueEmail
 		}
-		return fmt.Errorf("namedexeccontext: %w", err)
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", userbus.ErrModified)
+		}
+		return fmt.Errorf("db: %w", err)
 	}
 
 	return nil
 }
 
-// Delete removes a user from the database.
+// Delete removes a user from the database. ErrModified is returned when the
+// user was updated after it was read.
 func (s *Store) Delete(ctx context.Context, usr userbus.User) error {
+	ctx, span := otel.AddSpan(ctx, "business.userdb.delete", attribute.String("db.sql.table", "users"))
+	defer span.End()
+
 	const q = `
 	DELETE FROM
 		users
 	WHERE
-		user_id = :user_id`
+		user_id = :user_id AND
+		date_updated = :date_updated
+	RETURNING
+		user_id`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBUser(usr)); err != nil {
-		return fmt.Errorf("namedexeccontext: %w", err)
+	var dbUsr user
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, toDBUser(usr), &dbUsr); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", userbus.ErrModified)
+		}
+		return fmt.Errorf("db: %w", err)
 	}
 
 	return nil
@@ -108,6 +146,9 @@ func (s *Store) Delete(ctx context.Context, usr userbus.User) error {
 
 // Query retrieves a list of existing users from the database.
 func (s *Store) Query(ctx context.Context, filter userbus.QueryFilter, orderBy order.By, page page.Page) ([]userbus.User, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userdb.query", attribute.String("db.sql.table", "users"))
+	defer span.End()
+
 	data := map[string]any{
 		"offset":        (page.Number() - 1) * page.RowsPerPage(),
 		"rows_per_page": page.RowsPerPage(),
@@ -138,8 +179,82 @@ func (s *Store) Query(ctx context.Context, filter userbus.QueryFilter, orderBy o
 	return toBusUsers(dbUsrs)
 }
 
+// QueryStream retrieves the existing users from the database one at a
+// time, so every user can be read without holding them all in memory.
+func (s *Store) QueryStream(ctx context.Context, filter userbus.QueryFilter, orderBy order.By) iter.Seq2[userbus.User, error] {
+	return func(yield func(userbus.User, error) bool) {
+		ctx, span := otel.AddSpan(ctx, "business.userdb.querystream", attribute.String("db.sql.table", "users"))
+		defer span.End()
+
+		data := map[string]any{}
+
+		const q = `
+	SELECT
+		user_id, name, email, password_hash, roles, department, enabled, date_created, date_updated
+	FROM
+		users`
+
+		buf := bytes.NewBufferString(q)
+		applyFilter(filter, data, buf)
+
+		orderByClause, err := orderByClause(orderBy)
+		if err != nil {
+			yield(userbus.User{}, err)
+			return
+		}
+
+		buf.WriteString(orderByClause)
+
+		for dbUsr, err := range sqldb.NamedQueryIter[user](ctx, s.log, s.db, buf.String(), data) {
+			if err != nil {
+				yield(userbus.User{}, fmt.Errorf("namedqueryiter: %w", err))
+				return
+			}
+
+			usr, err := toBusUser(dbUsr)
+			if err != nil {
+				yield(userbus.User{}, err)
+				return
+			}
+
+			if !yield(usr, nil) {
+				return
+			}
+		}
+	}
+}
+
+// QueryByKeyset retrieves a list of existing users from the database using
+// keyset paging.
+func (s *Store) QueryByKeyset(ctx context.Context, filter userbus.QueryFilter, keyset page.Keyset) ([]userbus.User, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userdb.querybykeyset", attribute.String("db.sql.table", "users"))
+	defer span.End()
+
+	data := map[string]any{}
+
+	const q = `
+	SELECT
+		user_id, name, email, password_hash, roles, department, enabled, date_created, date_updated
+	FROM
+		users`
+
+	buf := bytes.NewBufferString(q)
+	applyFilter(filter, data, buf)
+	keyset.Apply("user_id", data, buf)
+
+	var dbUsrs []user
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbUsrs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusUsers(dbUsrs)
+}
+
 // Count returns the total number of users in the DB.
 func (s *Store) Count(ctx context.Context, filter userbus.QueryFilter) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userdb.count", attribute.String("db.sql.table", "users"))
+	defer span.End()
+
 	data := map[string]any{}
 
 	const q = `
@@ -163,6 +278,9 @@ func (s *Store) Count(ctx context.Context, filter userbus.QueryFilter) (int, err
 
 // QueryByID gets the specified user from the database.
 func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userdb.querybyid", attribute.String("db.sql.table", "users"))
+	defer span.End()
+
 	data := struct {
 		ID string `db:"user_id"`
 	}{
@@ -188,8 +306,43 @@ func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User,
 	return toBusUser(dbUsr)
 }
 
+// QueryByIDs gets the specified users from the database.
+func (s *Store) QueryByIDs(ctx context.Context, userIDs []uuid.UUID) ([]userbus.User, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userdb.querybyids", attribute.String("db.sql.table", "users"))
+	defer span.End()
+
+	ids := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		ids[i] = id.String()
+	}
+
+	data := struct {
+		IDs []string `db:"user_ids"`
+	}{
+		IDs: ids,
+	}
+
+	const q = `
+	SELECT
+        user_id, name, email, password_hash, roles, department, enabled, date_created, date_updated
+	FROM
+		users
+	WHERE
+		user_id IN (:user_ids)`
+
+	var dbUsrs []user
+	if err := sqldb.NamedQuerySliceUsingIn(ctx, s.log, s.db, q, data, &dbUsrs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusUsers(dbUsrs)
+}
+
 // QueryByEmail gets the specified user from the database by email.
 func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.User, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userdb.querybyemail", attribute.String("db.sql.table", "users"))
+	defer span.End()
+
 	data := struct {
 		Email string `db:"email"`
 	}{
@@ -214,3 +367,112 @@ func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.U
 
 	return toBusUser(dbUsr)
 }
+
+// CreatePasswordReset inserts a new password reset into the database.
+func (s *Store) CreatePasswordReset(ctx context.Context, pr userbus.PasswordReset) error {
+	ctx, span := otel.AddSpan(ctx, "business.userdb.createpasswordreset", attribute.String("db.sql.table", "password_resets"))
+	defer span.End()
+
+	const q = `
+	INSERT INTO password_resets
+		(reset_id, user_id, token_hash, date_expires, date_created)
+	VALUES
+		(:reset_id, :user_id, :token_hash, :date_expires, :date_created)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBPasswordReset(pr)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumePasswordReset removes the password reset with the hash from the
+// database and returns it, so only one caller can consume a reset.
+func (s *Store) ConsumePasswordReset(ctx context.Context, hash string) (userbus.PasswordReset, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userdb.consumepasswordreset", attribute.String("db.sql.table", "password_resets"))
+	defer span.End()
+
+	data := struct {
+		Hash string `db:"token_hash"`
+	}{
+		Hash: hash,
+	}
+
+	const q = `
+	DELETE FROM
+		password_resets
+	WHERE
+		token_hash = :token_hash
+	RETURNING
+		reset_id, user_id, token_hash, date_expires, date_created`
+
+	var dbPR passwordReset
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPR); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return userbus.PasswordReset{}, fmt.Errorf("db: %w", userbus.ErrResetNotFound)
+		}
+		return userbus.PasswordReset{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusPasswordReset(dbPR), nil
+}
+
+// DeletePasswordResets removes the password resets of the user from the
+// database.
+func (s *Store) DeletePasswordResets(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := otel.AddSpan(ctx, "business.userdb.deletepasswordresets", attribute.String("db.sql.table", "password_resets"))
+	defer span.End()
+
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+	DELETE FROM
+		password_resets
+	WHERE
+		user_id = :user_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePasswordResetsBefore removes the password resets that expired before
+// the specified time and returns the number removed.
+func (s *Store) DeletePasswordResetsBefore(ctx context.Context, before time.Time) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userdb.deletepasswordresetsbefore", attribute.String("db.sql.table", "password_resets"))
+	defer span.End()
+
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before.UTC(),
+	}
+
+	const q = `
+	WITH deleted AS (
+		DELETE FROM
+			password_resets
+		WHERE
+			date_expires < :before
+		RETURNING 1
+	)
+	SELECT
+		count(1)
+	FROM
+		deleted`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
@@ -0,0 +1,263 @@
+// Package usermock contains a user store that keeps the users in memory and
+// records the calls made to it, for testing without a database.
+package usermock
+
+import (
+	"context"
+	"iter"
+	"net/mail"
+	"slices"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/sdk/mockstore"
+	"github.com/ardanlabs/encore/business/sdk/order"
+	"github.com/ardanlabs/encore/business/sdk/page"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/google/uuid"
+)
+
+// Store manages the set of APIs for user access in memory. The filters and
+// ordering of the queries aren't applied, the users are returned in the
+// order they were created.
+type Store struct {
+	mockstore.Recorder
+	users  *mockstore.Table[userbus.User]
+	resets *mockstore.Table[userbus.PasswordReset]
+}
+
+// NewStore constructs an empty store.
+func NewStore() *Store {
+	return &Store{
+		users: mockstore.NewTable(func(usr userbus.User) uuid.UUID {
+			return usr.ID
+		}),
+		resets: mockstore.NewTable(func(pr userbus.PasswordReset) uuid.UUID {
+			return pr.ID
+		}),
+	}
+}
+
+// NewWithTx returns the same store since transactions aren't supported.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (userbus.Storer, error) {
+	if err := s.Record("NewWithTx", tx); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Create inserts a new user into the store.
+func (s *Store) Create(ctx context.Context, usr userbus.User) error {
+	if err := s.Record("Create", usr); err != nil {
+		return err
+	}
+
+	if s.emailTaken(usr) {
+		return userbus.ErrUniqueEmail
+	}
+
+	s.users.Insert(usr)
+
+	return nil
+}
+
+// Update replaces a user in the store when it was last updated at the
+// specified time.
+func (s *Store) Update(ctx context.Context, usr userbus.User, lastUpdated time.Time) error {
+	if err := s.Record("Update", usr, lastUpdated); err != nil {
+		return err
+	}
+
+	if cur, found := s.users.Find(usr.ID); !found || !cur.DateUpdated.Equal(lastUpdated) {
+		return userbus.ErrModified
+	}
+
+	if s.emailTaken(usr) {
+		return userbus.ErrUniqueEmail
+	}
+
+	s.users.Update(usr)
+
+	return nil
+}
+
+// Delete removes a user from the store when it hasn't been updated since it
+// was read.
+func (s *Store) Delete(ctx context.Context, usr userbus.User) error {
+	if err := s.Record("Delete", usr); err != nil {
+		return err
+	}
+
+	if cur, found := s.users.Find(usr.ID); !found || !cur.DateUpdated.Equal(usr.DateUpdated) {
+		return userbus.ErrModified
+	}
+
+	s.users.Delete(usr)
+
+	return nil
+}
+
+// Query retrieves a page of the users.
+func (s *Store) Query(ctx context.Context, filter userbus.QueryFilter, orderBy order.By, page page.Page) ([]userbus.User, error) {
+	if err := s.Record("Query", filter, orderBy, page); err != nil {
+		return nil, err
+	}
+
+	return mockstore.Page(s.users.Rows(), page), nil
+}
+
+// QueryByKeyset retrieves the first page of the users using keyset paging.
+func (s *Store) QueryByKeyset(ctx context.Context, filter userbus.QueryFilter, keyset page.Keyset) ([]userbus.User, error) {
+	if err := s.Record("QueryByKeyset", filter, keyset); err != nil {
+		return nil, err
+	}
+
+	return mockstore.Keyset(s.users.Rows(), keyset), nil
+}
+
+// QueryStream retrieves all the users one at a time.
+func (s *Store) QueryStream(ctx context.Context, filter userbus.QueryFilter, orderBy order.By) iter.Seq2[userbus.User, error] {
+	err := s.Record("QueryStream", filter, orderBy)
+
+	return func(yield func(userbus.User, error) bool) {
+		if err != nil {
+			yield(userbus.User{}, err)
+			return
+		}
+
+		for _, usr := range s.users.Rows() {
+			if !yield(usr, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Count returns the total number of users in the store.
+func (s *Store) Count(ctx context.Context, filter userbus.QueryFilter) (int, error) {
+	if err := s.Record("Count", filter); err != nil {
+		return 0, err
+	}
+
+	return len(s.users.Rows()), nil
+}
+
+// QueryByID gets the specified user from the store.
+func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User, error) {
+	if err := s.Record("QueryByID", userID); err != nil {
+		return userbus.User{}, err
+	}
+
+	usr, found := s.users.Find(userID)
+	if !found {
+		return userbus.User{}, userbus.ErrNotFound
+	}
+
+	return usr, nil
+}
+
+// QueryByIDs gets the specified users from the store.
+func (s *Store) QueryByIDs(ctx context.Context, userIDs []uuid.UUID) ([]userbus.User, error) {
+	if err := s.Record("QueryByIDs", userIDs); err != nil {
+		return nil, err
+	}
+
+	usrs := s.users.Select(func(usr userbus.User) bool {
+		return slices.Contains(userIDs, usr.ID)
+	})
+
+	return usrs, nil
+}
+
+// QueryByEmail gets the specified user from the store by email.
+func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.User, error) {
+	if err := s.Record("QueryByEmail", email); err != nil {
+		return userbus.User{}, err
+	}
+
+	usrs := s.users.Select(func(usr userbus.User) bool {
+		return usr.Email.Address == email.Address
+	})
+
+	if len(usrs) == 0 {
+		return userbus.User{}, userbus.ErrNotFound
+	}
+
+	return usrs[0], nil
+}
+
+// CreatePasswordReset inserts a new password reset into the store.
+func (s *Store) CreatePasswordReset(ctx context.Context, pr userbus.PasswordReset) error {
+	if err := s.Record("CreatePasswordReset", pr); err != nil {
+		return err
+	}
+
+	s.resets.Insert(pr)
+
+	return nil
+}
+
+// ConsumePasswordReset removes the password reset with the hash from the
+// store and returns it.
+func (s *Store) ConsumePasswordReset(ctx context.Context, hash string) (userbus.PasswordReset, error) {
+	if err := s.Record("ConsumePasswordReset", hash); err != nil {
+		return userbus.PasswordReset{}, err
+	}
+
+	prs := s.resets.Select(func(pr userbus.PasswordReset) bool {
+		return pr.Hash == hash
+	})
+
+	if len(prs) == 0 {
+		return userbus.PasswordReset{}, userbus.ErrResetNotFound
+	}
+
+	s.resets.Delete(prs[0])
+
+	return prs[0], nil
+}
+
+// DeletePasswordResets removes the password resets of the user from the
+// store.
+func (s *Store) DeletePasswordResets(ctx context.Context, userID uuid.UUID) error {
+	if err := s.Record("DeletePasswordResets", userID); err != nil {
+		return err
+	}
+
+	prs := s.resets.Select(func(pr userbus.PasswordReset) bool {
+		return pr.UserID == userID
+	})
+
+	for _, pr := range prs {
+		s.resets.Delete(pr)
+	}
+
+	return nil
+}
+
+// DeletePasswordResetsBefore removes the password resets that expired before
+// the specified time and returns the number removed.
+func (s *Store) DeletePasswordResetsBefore(ctx context.Context, before time.Time) (int, error) {
+	if err := s.Record("DeletePasswordResetsBefore", before); err != nil {
+		return 0, err
+	}
+
+	prs := s.resets.Select(func(pr userbus.PasswordReset) bool {
+		return pr.DateExpires.Before(before)
+	})
+
+	for _, pr := range prs {
+		s.resets.Delete(pr)
+	}
+
+	return len(prs), nil
+}
+
+func (s *Store) emailTaken(usr userbus.User) bool {
+	usrs := s.users.Select(func(u userbus.User) bool {
+		return u.Email.Address == usr.Email.Address && u.ID != usr.ID
+	})
+
+	return len(usrs) > 0
+}
@@ -0,0 +1,76 @@
+package usermock_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/domain/userbus/stores/usermock"
+	"github.com/ardanlabs/encore/business/sdk/delegate"
+	"github.com/ardanlabs/encore/foundation/clock"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func Test_PasswordReset(t *testing.T) {
+	ctx := context.Background()
+
+	log := logger.NewWithHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), logger.Events{}, nil)
+	clk := clock.NewFrozen(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+	rnd := rand.New(rand.NewSource(1))
+
+	store := usermock.NewStore()
+	userBus := userbus.NewBusiness(log, clk, delegate.New(log), store)
+
+	usr, err := userBus.Create(ctx, userbus.TestNewUsers(rnd, 1, userbus.Roles.User)[0])
+	if err != nil {
+		t.Fatalf("Should be able to create a user: %s", err)
+	}
+
+	_, first, err := userBus.RequestPasswordReset(ctx, usr.Email)
+	if err != nil {
+		t.Fatalf("Should be able to request a reset: %s", err)
+	}
+
+	_, pr, err := userBus.RequestPasswordReset(ctx, usr.Email)
+	if err != nil {
+		t.Fatalf("Should be able to request a reset: %s", err)
+	}
+
+	if pr.Token == "" || pr.Hash == pr.Token {
+		t.Fatalf("Should return the token and store its hash, got %+v", pr)
+	}
+
+	if _, err := userBus.ResetPassword(ctx, first.Token, "newpassword"); !errors.Is(err, userbus.ErrResetNotFound) {
+		t.Fatalf("Should not accept a token once a newer one is requested, got %v", err)
+	}
+
+	got, err := userBus.ResetPassword(ctx, pr.Token, "newpassword")
+	if err != nil {
+		t.Fatalf("Should be able to reset the password: %s", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword(got.PasswordHash, []byte("newpassword")); err != nil {
+		t.Fatalf("Should set the new password: %s", err)
+	}
+
+	if _, err := userBus.ResetPassword(ctx, pr.Token, "otherpassword"); !errors.Is(err, userbus.ErrResetNotFound) {
+		t.Fatalf("Should only accept a token once, got %v", err)
+	}
+
+	_, pr, err = userBus.RequestPasswordReset(ctx, usr.Email)
+	if err != nil {
+		t.Fatalf("Should be able to request a reset: %s", err)
+	}
+
+	clk.Advance(2 * time.Hour)
+
+	if _, err := userBus.ResetPassword(ctx, pr.Token, "otherpassword"); !errors.Is(err, userbus.ErrResetExpired) {
+		t.Fatalf("Should not accept an expired token, got %v", err)
+	}
+}
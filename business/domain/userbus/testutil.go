@@ -8,10 +8,10 @@ import (
 )
 
 // TestNewUsers is a helper method for testing.
-func TestNewUsers(n int, role Role) []NewUser {
+func TestNewUsers(rnd *rand.Rand, n int, role Role) []NewUser {
 	newUsrs := make([]NewUser, n)
 
-	idx := rand.Intn(10000)
+	idx := rnd.Intn(10000)
 	for i := 0; i < n; i++ {
 		idx++
 
@@ -30,8 +30,8 @@ func TestNewUsers(n int, role Role) []NewUser {
 }
 
 // TestSeedUsers is a helper method for testing.
-func TestSeedUsers(ctx context.Context, n int, role Role, api *Business) ([]User, error) {
-	newUsrs := TestNewUsers(n, role)
+func TestSeedUsers(ctx context.Context, rnd *rand.Rand, n int, role Role, api *Business) ([]User, error) {
+	newUsrs := TestNewUsers(rnd, n, role)
 
 	usrs := make([]User, len(newUsrs))
 	for i, nu := range newUsrs {
@@ -3,8 +3,13 @@ package userbus
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"iter"
 	"net/mail"
 	"time"
 
@@ -12,8 +17,11 @@ import (
 	"github.com/ardanlabs/encore/business/sdk/order"
 	"github.com/ardanlabs/encore/business/sdk/page"
 	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/foundation/clock"
 	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/ardanlabs/encore/foundation/otel"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -22,32 +30,48 @@ var (
 	ErrNotFound              = errors.New("user not found")
 	ErrUniqueEmail           = errors.New("email is not unique")
 	ErrAuthenticationFailure = errors.New("authentication failed")
+	ErrResetNotFound         = errors.New("password reset not found")
+	ErrResetExpired          = errors.New("password reset expired")
+	ErrModified              = errors.New("user has been modified")
 )
 
+// passwordResetTTL is how long a user has to reset the password once the
+// reset is requested.
+const passwordResetTTL = time.Hour
+
 // Storer interface declares the behavior this package needs to perists and
 // retrieve data.
 type Storer interface {
 	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
 	Create(ctx context.Context, usr User) error
-	Update(ctx context.Context, usr User) error
+	Update(ctx context.Context, usr User, lastUpdated time.Time) error
 	Delete(ctx context.Context, usr User) error
 	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]User, error)
+	QueryByKeyset(ctx context.Context, filter QueryFilter, keyset page.Keyset) ([]User, error)
+	QueryStream(ctx context.Context, filter QueryFilter, orderBy order.By) iter.Seq2[User, error]
 	Count(ctx context.Context, filter QueryFilter) (int, error)
 	QueryByID(ctx context.Context, userID uuid.UUID) (User, error)
+	QueryByIDs(ctx context.Context, userIDs []uuid.UUID) ([]User, error)
 	QueryByEmail(ctx context.Context, email mail.Address) (User, error)
+	CreatePasswordReset(ctx context.Context, pr PasswordReset) error
+	ConsumePasswordReset(ctx context.Context, hash string) (PasswordReset, error)
+	DeletePasswordResets(ctx context.Context, userID uuid.UUID) error
+	DeletePasswordResetsBefore(ctx context.Context, before time.Time) (int, error)
 }
 
 // Business manages the set of APIs for user access.
 type Business struct {
 	log      *logger.Logger
+	clock    clock.Clock
 	storer   Storer
 	delegate *delegate.Delegate
 }
 
 // NewBusiness constructs a user business API for use.
-func NewBusiness(log *logger.Logger, delegate *delegate.Delegate, storer Storer) *Business {
+func NewBusiness(log *logger.Logger, clock clock.Clock, delegate *delegate.Delegate, storer Storer) *Business {
 	return &Business{
 		log:      log,
+		clock:    clock,
 		delegate: delegate,
 		storer:   storer,
 	}
@@ -63,6 +87,7 @@ func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
 
 	bus := Business{
 		log:      b.log,
+		clock:    b.clock,
 		delegate: b.delegate,
 		storer:   storer,
 	}
@@ -72,12 +97,17 @@ func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
 
 // Create adds a new user to the system.
 func (b *Business) Create(ctx context.Context, nu NewUser) (User, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userbus.create")
+	defer span.End()
+
 	hash, err := bcrypt.GenerateFromPassword([]byte(nu.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return User{}, fmt.Errorf("generatefrompassword: %w", err)
 	}
 
-	now := time.Now()
+	// The database keeps microseconds, so the times are truncated to what
+	// is read back and the entity tags match.
+	now := b.clock.Now().Truncate(time.Microsecond)
 
 	usr := User{
 		ID:           uuid.New(),
@@ -95,11 +125,20 @@ func (b *Business) Create(ctx context.Context, nu NewUser) (User, error) {
 		return User{}, fmt.Errorf("create: %w", err)
 	}
 
+	// Other domains may need to know when a user is created. This
+	// represents a delegate call to other domains.
+	if err := b.delegate.Call(ctx, ActionData(ActionCreated, usr.ID)); err != nil {
+		return User{}, fmt.Errorf("failed to execute `%s` action: %w", ActionCreated, err)
+	}
+
 	return usr, nil
 }
 
 // Update modifies information about a user.
 func (b *Business) Update(ctx context.Context, usr User, uu UpdateUser) (User, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userbus.update", attribute.String("user_id", usr.ID.String()))
+	defer span.End()
+
 	if uu.Name != nil {
 		usr.Name = *uu.Name
 	}
@@ -127,9 +166,10 @@ func (b *Business) Update(ctx context.Context, usr User, uu UpdateUser) (User, e
 	if uu.Enabled != nil {
 		usr.Enabled = *uu.Enabled
 	}
-	usr.DateUpdated = time.Now()
+	lastUpdated := usr.DateUpdated
+	usr.DateUpdated = b.clock.Now().Truncate(time.Microsecond)
 
-	if err := b.storer.Update(ctx, usr); err != nil {
+	if err := b.storer.Update(ctx, usr, lastUpdated); err != nil {
 		return User{}, fmt.Errorf("update: %w", err)
 	}
 
@@ -144,15 +184,27 @@ func (b *Business) Update(ctx context.Context, usr User, uu UpdateUser) (User, e
 
 // Delete removes the specified user.
 func (b *Business) Delete(ctx context.Context, usr User) error {
+	ctx, span := otel.AddSpan(ctx, "business.userbus.delete", attribute.String("user_id", usr.ID.String()))
+	defer span.End()
+
 	if err := b.storer.Delete(ctx, usr); err != nil {
 		return fmt.Errorf("delete: %w", err)
 	}
 
+	// Other domains may need to know when a user is deleted. This
+	// represents a delegate call to other domains.
+	if err := b.delegate.Call(ctx, ActionData(ActionDeleted, usr.ID)); err != nil {
+		return fmt.Errorf("failed to execute `%s` action: %w", ActionDeleted, err)
+	}
+
 	return nil
 }
 
 // Query retrieves a list of existing users.
 func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]User, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userbus.query")
+	defer span.End()
+
 	users, err := b.storer.Query(ctx, filter, orderBy, page)
 	if err != nil {
 		return nil, fmt.Errorf("query: %w", err)
@@ -161,13 +213,50 @@ func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.
 	return users, nil
 }
 
+// QueryStream retrieves the existing users one at a time in the
+// specified order. The sequence ends at the first error.
+func (b *Business) QueryStream(ctx context.Context, filter QueryFilter, orderBy order.By) iter.Seq2[User, error] {
+	return func(yield func(User, error) bool) {
+		for usr, err := range b.storer.QueryStream(ctx, filter, orderBy) {
+			if err != nil {
+				yield(User{}, fmt.Errorf("querystream: %w", err))
+				return
+			}
+
+			if !yield(usr, nil) {
+				return
+			}
+		}
+	}
+}
+
+// QueryByKeyset retrieves a list of existing users using keyset paging.
+// One more user than the limit is returned when there are more users.
+func (b *Business) QueryByKeyset(ctx context.Context, filter QueryFilter, keyset page.Keyset) ([]User, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userbus.querybykeyset")
+	defer span.End()
+
+	usrs, err := b.storer.QueryByKeyset(ctx, filter, keyset)
+	if err != nil {
+		return nil, fmt.Errorf("querybykeyset: %w", err)
+	}
+
+	return usrs, nil
+}
+
 // Count returns the total number of users.
 func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userbus.count")
+	defer span.End()
+
 	return b.storer.Count(ctx, filter)
 }
 
 // QueryByID finds the user by the specified Ib.
 func (b *Business) QueryByID(ctx context.Context, userID uuid.UUID) (User, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userbus.querybyid", attribute.String("user_id", userID.String()))
+	defer span.End()
+
 	user, err := b.storer.QueryByID(ctx, userID)
 	if err != nil {
 		return User{}, fmt.Errorf("query: userID[%s]: %w", userID, err)
@@ -176,8 +265,29 @@ func (b *Business) QueryByID(ctx context.Context, userID uuid.UUID) (User, error
 	return user, nil
 }
 
+// QueryByIDs finds the users with the specified ids in a single query. Ids
+// that don't match a user are ignored.
+func (b *Business) QueryByIDs(ctx context.Context, userIDs []uuid.UUID) ([]User, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userbus.querybyids")
+	defer span.End()
+
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	users, err := b.storer.QueryByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("querybyids: %w", err)
+	}
+
+	return users, nil
+}
+
 // QueryByEmail finds the user by a specified user email.
 func (b *Business) QueryByEmail(ctx context.Context, email mail.Address) (User, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userbus.querybyemail")
+	defer span.End()
+
 	user, err := b.storer.QueryByEmail(ctx, email)
 	if err != nil {
 		return User{}, fmt.Errorf("query: email[%s]: %w", email, err)
@@ -190,6 +300,9 @@ func (b *Business) QueryByEmail(ctx context.Context, email mail.Address) (User,
 // success it returns a Claims User representing this user. The claims can be
 // used to generate a token for future authentication.
 func (b *Business) Authenticate(ctx context.Context, email mail.Address, password string) (User, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userbus.authenticate")
+	defer span.End()
+
 	usr, err := b.QueryByEmail(ctx, email)
 	if err != nil {
 		return User{}, fmt.Errorf("query: email[%s]: %w", email, err)
@@ -201,3 +314,99 @@ func (b *Business) Authenticate(ctx context.Context, email mail.Address, passwor
 
 	return usr, nil
 }
+
+// RequestPasswordReset issues a token the user with the email can reset the
+// password with, and returns it along with the user so it can be sent to
+// them. Only the latest token of a user can be used, and only once.
+func (b *Business) RequestPasswordReset(ctx context.Context, email mail.Address) (User, PasswordReset, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userbus.requestpasswordreset")
+	defer span.End()
+
+	usr, err := b.QueryByEmail(ctx, email)
+	if err != nil {
+		return User{}, PasswordReset{}, fmt.Errorf("query: email[%s]: %w", email, err)
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return User{}, PasswordReset{}, fmt.Errorf("generate: %w", err)
+	}
+
+	if err := b.storer.DeletePasswordResets(ctx, usr.ID); err != nil {
+		return User{}, PasswordReset{}, fmt.Errorf("deletepasswordresets: userID[%s]: %w", usr.ID, err)
+	}
+
+	now := b.clock.Now()
+
+	pr := PasswordReset{
+		ID:          uuid.New(),
+		UserID:      usr.ID,
+		Token:       token,
+		Hash:        hashToken(token),
+		DateExpires: now.Add(passwordResetTTL),
+		DateCreated: now,
+	}
+
+	if err := b.storer.CreatePasswordReset(ctx, pr); err != nil {
+		return User{}, PasswordReset{}, fmt.Errorf("createpasswordreset: userID[%s]: %w", usr.ID, err)
+	}
+
+	return usr, pr, nil
+}
+
+// ResetPassword sets the password of the user the token was issued to. The
+// token is used up even when it has expired.
+func (b *Business) ResetPassword(ctx context.Context, token string, password string) (User, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userbus.resetpassword")
+	defer span.End()
+
+	pr, err := b.storer.ConsumePasswordReset(ctx, hashToken(token))
+	if err != nil {
+		return User{}, fmt.Errorf("consumepasswordreset: %w", err)
+	}
+
+	if !b.clock.Now().Before(pr.DateExpires) {
+		return User{}, fmt.Errorf("reset: userID[%s]: %w", pr.UserID, ErrResetExpired)
+	}
+
+	usr, err := b.QueryByID(ctx, pr.UserID)
+	if err != nil {
+		return User{}, fmt.Errorf("querybyid: userID[%s]: %w", pr.UserID, err)
+	}
+
+	usr, err = b.Update(ctx, usr, UpdateUser{Password: &password})
+	if err != nil {
+		return User{}, fmt.Errorf("update: userID[%s]: %w", usr.ID, err)
+	}
+
+	return usr, nil
+}
+
+// DeleteExpiredPasswordResets removes the password resets that have expired
+// and returns the number removed.
+func (b *Business) DeleteExpiredPasswordResets(ctx context.Context) (int, error) {
+	n, err := b.storer.DeletePasswordResetsBefore(ctx, b.clock.Now())
+	if err != nil {
+		return 0, fmt.Errorf("deletepasswordresetsbefore: %w", err)
+	}
+
+	return n, nil
+}
+
+// =============================================================================
+
+// generateToken returns a random token that is safe to use in a url.
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hash of the token that is stored in its place.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
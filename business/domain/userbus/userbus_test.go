@@ -2,6 +2,7 @@ package userbus_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/mail"
 	"sort"
@@ -14,6 +15,7 @@ import (
 	"github.com/ardanlabs/encore/business/sdk/page"
 	"github.com/ardanlabs/encore/business/sdk/unitest"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -27,60 +29,31 @@ func Test_User(t *testing.T) {
 
 	db := dbtest.NewDatabase(t, edb)
 
-	sd, err := insertSeedData(db.BusDomain)
-	if err != nil {
-		t.Fatalf("Seeding error: %s", err)
-	}
+	sd := db.LoadFixtures(t, "fixtures/seed.yaml")
 
 	// -------------------------------------------------------------------------
 
+	db.Events.Reset()
+
 	unitest.Run(t, query(db.BusDomain, sd), "query")
 	unitest.Run(t, create(db.BusDomain), "create")
 	unitest.Run(t, update(db.BusDomain, sd), "update")
 	unitest.Run(t, delete(db.BusDomain, sd), "delete")
-}
-
-// =============================================================================
-
-func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
-	ctx := context.Background()
-
-	usrs, err := userbus.TestSeedUsers(ctx, 2, userbus.Roles.Admin, busDomain.User)
-	if err != nil {
-		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
-	}
-
-	tu1 := unitest.User{
-		User: usrs[0],
-	}
-
-	tu2 := unitest.User{
-		User: usrs[1],
-	}
-
-	// -------------------------------------------------------------------------
-
-	usrs, err = userbus.TestSeedUsers(ctx, 2, userbus.Roles.User, busDomain.User)
-	if err != nil {
-		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
-	}
 
-	tu3 := unitest.User{
-		User: usrs[0],
-	}
-
-	tu4 := unitest.User{
-		User: usrs[1],
-	}
-
-	// -------------------------------------------------------------------------
+	db.Events.Expect(t, userbus.DomainName, userbus.ActionUpdated, sd.Users[0].ID)
+	db.Events.Expect(t, userbus.DomainName, userbus.ActionDeleted, sd.Users[1].ID)
+	db.Events.Expect(t, userbus.DomainName, userbus.ActionDeleted, sd.Admins[1].ID)
 
-	sd := unitest.SeedData{
-		Users:  []unitest.User{tu3, tu4},
-		Admins: []unitest.User{tu1, tu2},
-	}
-
-	return sd, nil
+	dbtest.CheckContext(t, "context", map[string]func(ctx context.Context) error{
+		"query": func(ctx context.Context) error {
+			_, err := db.BusDomain.User.Query(ctx, userbus.QueryFilter{}, userbus.DefaultOrderBy, page.MustParse("1", "10"))
+			return err
+		},
+		"count": func(ctx context.Context) error {
+			_, err := db.BusDomain.User.Count(ctx, userbus.QueryFilter{})
+			return err
+		},
+	})
 }
 
 // =============================================================================
@@ -164,6 +137,38 @@ func query(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 					expResp.DateUpdated = gotResp.DateUpdated
 				}
 
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:    "byids",
+			ExpResp: []userbus.User{sd.Users[0].User},
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.User.QueryByIDs(ctx, []uuid.UUID{sd.Users[0].ID, uuid.New()})
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.([]userbus.User)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.([]userbus.User)
+
+				for i := range gotResp {
+					if gotResp[i].DateCreated.Format(time.RFC3339) == expResp[i].DateCreated.Format(time.RFC3339) {
+						expResp[i].DateCreated = gotResp[i].DateCreated
+					}
+
+					if gotResp[i].DateUpdated.Format(time.RFC3339) == expResp[i].DateUpdated.Format(time.RFC3339) {
+						expResp[i].DateUpdated = gotResp[i].DateUpdated
+					}
+				}
+
 				return cmp.Diff(gotResp, expResp)
 			},
 		},
@@ -275,6 +280,25 @@ func update(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 				return cmp.Diff(gotResp, expResp)
 			},
 		},
+		{
+			Name:    "modified",
+			ExpResp: userbus.ErrModified,
+			ExcFunc: func(ctx context.Context) any {
+
+				// The seeded user was changed by the basic update, so this
+				// copy is out of date.
+				_, err := busDomain.User.Update(ctx, sd.Users[0].User, userbus.UpdateUser{Department: dbtest.StringPointer("Stale")})
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotErr, _ := got.(error)
+				if !errors.Is(gotErr, exp.(error)) {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+
+				return ""
+			},
+		},
 	}
 
 	return table
@@ -310,6 +334,21 @@ func delete(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 				return cmp.Diff(got, exp)
 			},
 		},
+		{
+			Name:    "modified",
+			ExpResp: userbus.ErrModified,
+			ExcFunc: func(ctx context.Context) any {
+				return busDomain.User.Delete(ctx, sd.Users[0].User)
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotErr, _ := got.(error)
+				if !errors.Is(gotErr, exp.(error)) {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+
+				return ""
+			},
+		},
 	}
 
 	return table
@@ -0,0 +1,25 @@
+package userprefsbus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Preference represents how a user wants the items of an entity to be
+// listed when a query doesn't say.
+type Preference struct {
+	UserID      uuid.UUID
+	Entity      string
+	Rows        int
+	OrderBy     string
+	DateUpdated time.Time
+}
+
+// SetPreference is what we require to store a preference.
+type SetPreference struct {
+	UserID  uuid.UUID
+	Entity  string
+	Rows    int
+	OrderBy string
+}
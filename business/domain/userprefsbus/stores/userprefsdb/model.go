@@ -0,0 +1,46 @@
+package userprefsdb
+
+import (
+	"time"
+
+	"github.com/ardanlabs/encore/business/domain/userprefsbus"
+	"github.com/google/uuid"
+)
+
+type preference struct {
+	UserID      uuid.UUID `db:"user_id"`
+	Entity      string    `db:"entity"`
+	Rows        int       `db:"rows_per_page"`
+	OrderBy     string    `db:"order_by"`
+	DateUpdated time.Time `db:"date_updated"`
+}
+
+func toDBPreference(bus userprefsbus.Preference) preference {
+	return preference{
+		UserID:      bus.UserID,
+		Entity:      bus.Entity,
+		Rows:        bus.Rows,
+		OrderBy:     bus.OrderBy,
+		DateUpdated: bus.DateUpdated.UTC(),
+	}
+}
+
+func toBusPreference(db preference) userprefsbus.Preference {
+	return userprefsbus.Preference{
+		UserID:      db.UserID,
+		Entity:      db.Entity,
+		Rows:        db.Rows,
+		OrderBy:     db.OrderBy,
+		DateUpdated: db.DateUpdated.In(time.Local),
+	}
+}
+
+func toBusPreferences(dbs []preference) []userprefsbus.Preference {
+	bus := make([]userprefsbus.Preference, len(dbs))
+
+	for i, db := range dbs {
+		bus[i] = toBusPreference(db)
+	}
+
+	return bus
+}
@@ -0,0 +1,97 @@
+// Package userprefsdb contains preference related CRUD functionality.
+package userprefsdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ardanlabs/encore/business/domain/userprefsbus"
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for preference database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Upsert inserts the preference or replaces the one already stored for the
+// user and entity.
+func (s *Store) Upsert(ctx context.Context, pref userprefsbus.Preference) error {
+	const q = `
+    INSERT INTO user_preferences
+        (user_id, entity, rows_per_page, order_by, date_updated)
+    VALUES
+        (:user_id, :entity, :rows_per_page, :order_by, :date_updated)
+    ON CONFLICT (user_id, entity) DO UPDATE SET
+        rows_per_page = EXCLUDED.rows_per_page,
+        order_by = EXCLUDED.order_by,
+        date_updated = EXCLUDED.date_updated`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBPreference(pref)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the preference stored for the user and entity.
+func (s *Store) Delete(ctx context.Context, userID uuid.UUID, entity string) error {
+	data := struct {
+		UserID string `db:"user_id"`
+		Entity string `db:"entity"`
+	}{
+		UserID: userID.String(),
+		Entity: entity,
+	}
+
+	const q = `
+    DELETE FROM
+        user_preferences
+    WHERE
+        user_id = :user_id AND
+        entity = :entity`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByUserID gets every preference stored for the user.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]userprefsbus.Preference, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+    SELECT
+        user_id, entity, rows_per_page, order_by, date_updated
+    FROM
+        user_preferences
+    WHERE
+        user_id = :user_id
+    ORDER BY
+        entity`
+
+	var dbPrefs []preference
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbPrefs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusPreferences(dbPrefs), nil
+}
@@ -0,0 +1,77 @@
+// Package userprefsbus provides business access to the preferences a user
+// has stored for how lists are returned to them.
+package userprefsbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound = errors.New("preference not found")
+)
+
+// Storer interface declares the behaviour this package needs to persist and
+// retrieve data.
+type Storer interface {
+	Upsert(ctx context.Context, pref Preference) error
+	Delete(ctx context.Context, userID uuid.UUID, entity string) error
+	QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Preference, error)
+}
+
+// Business manages the set of APIs for preference access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs a preference business API for use.
+func NewBusiness(log *logger.Logger, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// Set stores the user's preference for the entity, replacing any existing
+// preference.
+func (b *Business) Set(ctx context.Context, sp SetPreference) (Preference, error) {
+	pref := Preference{
+		UserID:      sp.UserID,
+		Entity:      sp.Entity,
+		Rows:        sp.Rows,
+		OrderBy:     sp.OrderBy,
+		DateUpdated: time.Now(),
+	}
+
+	if err := b.storer.Upsert(ctx, pref); err != nil {
+		return Preference{}, fmt.Errorf("upsert: %w", err)
+	}
+
+	return pref, nil
+}
+
+// Delete removes the user's preference for the entity.
+func (b *Business) Delete(ctx context.Context, userID uuid.UUID, entity string) error {
+	if err := b.storer.Delete(ctx, userID, entity); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByUserID returns every preference the user has stored.
+func (b *Business) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Preference, error) {
+	prefs, err := b.storer.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return prefs, nil
+}
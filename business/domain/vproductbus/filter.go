@@ -1,17 +1,24 @@
 package vproductbus
 
 import (
+	"net/mail"
+
 	"github.com/ardanlabs/encore/business/domain/productbus"
 	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/sdk/where"
 	"github.com/google/uuid"
 )
 
 // QueryFilter holds the available fields a query can be filtered on.
 // We are using pointer semantics because the With API mutates the value.
 type QueryFilter struct {
-	ID       *uuid.UUID
-	Name     *productbus.Name
-	Cost     *float64
-	Quantity *int
-	UserName *userbus.Name
+	ID           *uuid.UUID
+	Name         *productbus.Name
+	Cost         []where.Cond[float64]
+	Quantity     []where.Cond[int]
+	UserID       *uuid.UUID
+	UserName     *userbus.Name
+	UserEmail    *mail.Address
+	Value        []where.Cond[float64]
+	UserProducts []where.Cond[int]
 }
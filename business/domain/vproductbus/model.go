@@ -1,6 +1,7 @@
 package vproductbus
 
 import (
+	"net/mail"
 	"time"
 
 	"github.com/ardanlabs/encore/business/domain/productbus"
@@ -8,14 +9,19 @@ import (
 	"github.com/google/uuid"
 )
 
-// Product represents an individual product with extended information.
+// Product represents an individual product with extended information. The
+// value is the cost of the quantity in stock and the user products is the
+// number of products the owner has.
 type Product struct {
-	ID          uuid.UUID
-	UserID      uuid.UUID
-	Name        productbus.Name
-	Cost        float64
-	Quantity    int
-	DateCreated time.Time
-	DateUpdated time.Time
-	UserName    userbus.Name
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	Name         productbus.Name
+	Cost         float64
+	Quantity     int
+	DateCreated  time.Time
+	DateUpdated  time.Time
+	UserName     userbus.Name
+	UserEmail    mail.Address
+	Value        float64
+	UserProducts int
 }
@@ -7,10 +7,13 @@ var DefaultOrderBy = order.NewBy(OrderByProductID, order.ASC)
 
 // Set of fields that the results can be ordered by.
 const (
-	OrderByProductID = "product_id"
-	OrderByUserID    = "user_id"
-	OrderByName      = "name"
-	OrderByCost      = "cost"
-	OrderByQuantity  = "quantity"
-	OrderByUserName  = "user_name"
+	OrderByProductID    = "product_id"
+	OrderByUserID       = "user_id"
+	OrderByName         = "name"
+	OrderByCost         = "cost"
+	OrderByQuantity     = "quantity"
+	OrderByUserName     = "user_name"
+	OrderByUserEmail    = "user_email"
+	OrderByValue        = "value"
+	OrderByUserProducts = "user_products"
 )
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/ardanlabs/encore/business/domain/vproductbus"
+	"github.com/ardanlabs/encore/business/sdk/where"
 )
 
 func (s *Store) applyFilter(filter vproductbus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
@@ -21,21 +22,27 @@ func (s *Store) applyFilter(filter vproductbus.QueryFilter, data map[string]any,
 		wc = append(wc, "name LIKE :name")
 	}
 
-	if filter.Cost != nil {
-		data["cost"] = *filter.Cost
-		wc = append(wc, "cost = :cost")
-	}
+	wc = append(wc, where.Apply(filter.Cost, "cost", data)...)
+	wc = append(wc, where.Apply(filter.Quantity, "quantity", data)...)
 
-	if filter.Quantity != nil {
-		data["quantity"] = *filter.Quantity
-		wc = append(wc, "quantity = :quantity")
+	if filter.UserID != nil {
+		data["user_id"] = *filter.UserID
+		wc = append(wc, "user_id = :user_id")
 	}
 
 	if filter.UserName != nil {
-		data["user_name"] = fmt.Sprintf("%%%s%%", *filter.Name)
+		data["user_name"] = fmt.Sprintf("%%%s%%", *filter.UserName)
 		wc = append(wc, "user_name LIKE :user_name")
 	}
 
+	if filter.UserEmail != nil {
+		data["user_email"] = filter.UserEmail.Address
+		wc = append(wc, "user_email = :user_email")
+	}
+
+	wc = append(wc, where.Apply(filter.Value, "value", data)...)
+	wc = append(wc, where.Apply(filter.UserProducts, "user_products", data)...)
+
 	if len(wc) > 0 {
 		buf.WriteString(" WHERE ")
 		buf.WriteString(strings.Join(wc, " AND "))
@@ -2,6 +2,7 @@ package vproductdb
 
 import (
 	"fmt"
+	"net/mail"
 	"time"
 
 	"github.com/ardanlabs/encore/business/domain/productbus"
@@ -11,14 +12,17 @@ import (
 )
 
 type product struct {
-	ID          uuid.UUID `db:"product_id"`
-	UserID      uuid.UUID `db:"user_id"`
-	Name        string    `db:"name"`
-	Cost        float64   `db:"cost"`
-	Quantity    int       `db:"quantity"`
-	DateCreated time.Time `db:"date_created"`
-	DateUpdated time.Time `db:"date_updated"`
-	UserName    string    `db:"user_name"`
+	ID           uuid.UUID `db:"product_id"`
+	UserID       uuid.UUID `db:"user_id"`
+	Name         string    `db:"name"`
+	Cost         float64   `db:"cost"`
+	Quantity     int       `db:"quantity"`
+	DateCreated  time.Time `db:"date_created"`
+	DateUpdated  time.Time `db:"date_updated"`
+	UserName     string    `db:"user_name"`
+	UserEmail    string    `db:"user_email"`
+	Value        float64   `db:"value"`
+	UserProducts int       `db:"user_products"`
 }
 
 func toBusProduct(db product) (vproductbus.Product, error) {
@@ -33,14 +37,17 @@ func toBusProduct(db product) (vproductbus.Product, error) {
 	}
 
 	bus := vproductbus.Product{
-		ID:          db.ID,
-		UserID:      db.UserID,
-		Name:        name,
-		Cost:        db.Cost,
-		Quantity:    db.Quantity,
-		DateCreated: db.DateCreated.In(time.Local),
-		DateUpdated: db.DateUpdated.In(time.Local),
-		UserName:    userName,
+		ID:           db.ID,
+		UserID:       db.UserID,
+		Name:         name,
+		Cost:         db.Cost,
+		Quantity:     db.Quantity,
+		DateCreated:  db.DateCreated.In(time.Local),
+		DateUpdated:  db.DateUpdated.In(time.Local),
+		UserName:     userName,
+		UserEmail:    mail.Address{Address: db.UserEmail},
+		Value:        db.Value,
+		UserProducts: db.UserProducts,
 	}
 
 	return bus, nil
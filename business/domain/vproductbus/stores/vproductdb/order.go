@@ -2,25 +2,36 @@ package vproductdb
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/ardanlabs/encore/business/domain/vproductbus"
 	"github.com/ardanlabs/encore/business/sdk/order"
 )
 
 var orderByFields = map[string]string{
-	vproductbus.OrderByProductID: "product_id",
-	vproductbus.OrderByUserID:    "user_id",
-	vproductbus.OrderByName:      "name",
-	vproductbus.OrderByCost:      "cost",
-	vproductbus.OrderByQuantity:  "quantity",
-	vproductbus.OrderByUserName:  "user_name",
+	vproductbus.OrderByProductID:    "product_id",
+	vproductbus.OrderByUserID:       "user_id",
+	vproductbus.OrderByName:         "name",
+	vproductbus.OrderByCost:         "cost",
+	vproductbus.OrderByQuantity:     "quantity",
+	vproductbus.OrderByUserName:     "user_name",
+	vproductbus.OrderByUserEmail:    "user_email",
+	vproductbus.OrderByValue:        "value",
+	vproductbus.OrderByUserProducts: "user_products",
 }
 
 func orderByClause(orderBy order.By) (string, error) {
-	by, exists := orderByFields[orderBy.Field]
-	if !exists {
-		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	fields := orderBy.Fields()
+
+	terms := make([]string, len(fields))
+	for i, ob := range fields {
+		by, exists := orderByFields[ob.Field]
+		if !exists {
+			return "", fmt.Errorf("field %q does not exist", ob.Field)
+		}
+
+		terms[i] = by + " " + ob.Direction
 	}
 
-	return " ORDER BY " + by + " " + orderBy.Direction, nil
+	return " ORDER BY " + strings.Join(terms, ", "), nil
 }
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"iter"
 
 	"github.com/ardanlabs/encore/business/domain/vproductbus"
 	"github.com/ardanlabs/encore/business/sdk/order"
@@ -44,7 +45,10 @@ func (s *Store) Query(ctx context.Context, filter vproductbus.QueryFilter, order
 		quantity,
 		date_created,
 		date_updated,
-		user_name
+		user_name,
+		user_email,
+		value,
+		user_products
 	FROM
 		view_products`
 
@@ -94,3 +98,55 @@ func (s *Store) Count(ctx context.Context, filter vproductbus.QueryFilter) (int,
 
 	return count.Count, nil
 }
+
+// QueryStream retrieves the existing products from the database one at a
+// time, so every product can be read without holding them all in memory.
+func (s *Store) QueryStream(ctx context.Context, filter vproductbus.QueryFilter, orderBy order.By) iter.Seq2[vproductbus.Product, error] {
+	return func(yield func(vproductbus.Product, error) bool) {
+		data := map[string]any{}
+
+		const q = `
+	SELECT
+		product_id,
+		user_id,
+		name,
+		cost,
+		quantity,
+		date_created,
+		date_updated,
+		user_name,
+		user_email,
+		value,
+		user_products
+	FROM
+		view_products`
+
+		buf := bytes.NewBufferString(q)
+		s.applyFilter(filter, data, buf)
+
+		orderByClause, err := orderByClause(orderBy)
+		if err != nil {
+			yield(vproductbus.Product{}, err)
+			return
+		}
+
+		buf.WriteString(orderByClause)
+
+		for dbPrd, err := range sqldb.NamedQueryIter[product](ctx, s.log, s.db, buf.String(), data) {
+			if err != nil {
+				yield(vproductbus.Product{}, fmt.Errorf("namedqueryiter: %w", err))
+				return
+			}
+
+			prd, err := toBusProduct(dbPrd)
+			if err != nil {
+				yield(vproductbus.Product{}, err)
+				return
+			}
+
+			if !yield(prd, nil) {
+				return
+			}
+		}
+	}
+}
@@ -2,7 +2,7 @@ package vproductbus_test
 
 import (
 	"context"
-	"fmt"
+	"math"
 	"sort"
 	"testing"
 	"time"
@@ -12,6 +12,7 @@ import (
 	"github.com/ardanlabs/encore/business/domain/userbus"
 	"github.com/ardanlabs/encore/business/domain/vproductbus"
 	"github.com/ardanlabs/encore/business/sdk/dbtest"
+	"github.com/ardanlabs/encore/business/sdk/order"
 	"github.com/ardanlabs/encore/business/sdk/page"
 	"github.com/ardanlabs/encore/business/sdk/unitest"
 	"github.com/google/go-cmp/cmp"
@@ -27,10 +28,7 @@ func Test_Product(t *testing.T) {
 
 	db := dbtest.NewDatabase(t, edb)
 
-	sd, err := insertSeedData(db.BusDomain)
-	if err != nil {
-		t.Fatalf("Seeding error: %s", err)
-	}
+	sd := db.LoadFixtures(t, "fixtures/seed.yaml")
 
 	// -------------------------------------------------------------------------
 
@@ -39,70 +37,28 @@ func Test_Product(t *testing.T) {
 
 // =============================================================================
 
-func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
-	ctx := context.Background()
-
-	usrs, err := userbus.TestSeedUsers(ctx, 1, userbus.Roles.User, busDomain.User)
-	if err != nil {
-		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
-	}
-
-	prds, err := productbus.TestGenerateSeedProducts(ctx, 2, busDomain.Product, usrs[0].ID)
-	if err != nil {
-		return unitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
-	}
-
-	tu1 := unitest.User{
-		User:     usrs[0],
-		Products: prds,
-	}
-
-	// -------------------------------------------------------------------------
-
-	usrs, err = userbus.TestSeedUsers(ctx, 1, userbus.Roles.Admin, busDomain.User)
-	if err != nil {
-		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
-	}
-
-	prds, err = productbus.TestGenerateSeedProducts(ctx, 2, busDomain.Product, usrs[0].ID)
-	if err != nil {
-		return unitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
-	}
-
-	tu2 := unitest.User{
-		User:     usrs[0],
-		Products: prds,
-	}
-
-	// -------------------------------------------------------------------------
-
-	sd := unitest.SeedData{
-		Admins: []unitest.User{tu2},
-		Users:  []unitest.User{tu1},
-	}
-
-	return sd, nil
-}
-
-// =============================================================================
-
-func toVProduct(usr userbus.User, prd productbus.Product) vproductbus.Product {
+// toVProduct builds the view of one of the products of the user, who has the
+// number of products given.
+func toVProduct(usr userbus.User, prd productbus.Product, userProducts int) vproductbus.Product {
 	return vproductbus.Product{
-		ID:          prd.ID,
-		UserID:      prd.UserID,
-		Name:        prd.Name,
-		Cost:        prd.Cost,
-		Quantity:    prd.Quantity,
-		DateCreated: prd.DateCreated,
-		DateUpdated: prd.DateUpdated,
-		UserName:    usr.Name,
+		ID:           prd.ID,
+		UserID:       prd.UserID,
+		Name:         prd.Name,
+		Cost:         prd.Cost,
+		Quantity:     prd.Quantity,
+		DateCreated:  prd.DateCreated,
+		DateUpdated:  prd.DateUpdated,
+		UserName:     usr.Name,
+		UserEmail:    usr.Email,
+		Value:        math.Round(prd.Cost*float64(prd.Quantity)*100) / 100,
+		UserProducts: userProducts,
 	}
 }
 
 func toVProducts(usr userbus.User, prds []productbus.Product) []vproductbus.Product {
 	items := make([]vproductbus.Product, len(prds))
 	for i, prd := range prds {
-		items[i] = toVProduct(usr, prd)
+		items[i] = toVProduct(usr, prd, len(prds))
 	}
 
 	return items
@@ -118,6 +74,12 @@ func query(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 		return prds[i].ID.String() <= prds[j].ID.String()
 	})
 
+	byValue := toVProducts(sd.Users[0].User, sd.Users[0].Products)
+
+	sort.Slice(byValue, func(i, j int) bool {
+		return byValue[i].Value > byValue[j].Value
+	})
+
 	table := []unitest.Table{
 		{
 			Name:    "all",
@@ -134,28 +96,49 @@ func query(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 
 				return resp
 			},
-			CmpFunc: func(got any, exp any) string {
-				gotResp, exists := got.([]vproductbus.Product)
-				if !exists {
-					return "error occurred"
+			CmpFunc: cmpProducts,
+		},
+		{
+			Name:    "user-email-by-value",
+			ExpResp: byValue,
+			ExcFunc: func(ctx context.Context) any {
+				filter := vproductbus.QueryFilter{
+					UserEmail: &sd.Users[0].User.Email,
 				}
 
-				expResp := exp.([]vproductbus.Product)
+				orderBy := order.NewBy(vproductbus.OrderByValue, order.DESC)
 
-				for i := range gotResp {
-					if gotResp[i].DateCreated.Format(time.RFC3339) == expResp[i].DateCreated.Format(time.RFC3339) {
-						expResp[i].DateCreated = gotResp[i].DateCreated
-					}
-
-					if gotResp[i].DateUpdated.Format(time.RFC3339) == expResp[i].DateUpdated.Format(time.RFC3339) {
-						expResp[i].DateUpdated = gotResp[i].DateUpdated
-					}
+				resp, err := busDomain.VProduct.Query(ctx, filter, orderBy, page.MustParse("1", "10"))
+				if err != nil {
+					return err
 				}
 
-				return cmp.Diff(gotResp, expResp)
+				return resp
 			},
+			CmpFunc: cmpProducts,
 		},
 	}
 
 	return table
 }
+
+func cmpProducts(got any, exp any) string {
+	gotResp, exists := got.([]vproductbus.Product)
+	if !exists {
+		return "error occurred"
+	}
+
+	expResp := exp.([]vproductbus.Product)
+
+	for i := range gotResp {
+		if gotResp[i].DateCreated.Format(time.RFC3339) == expResp[i].DateCreated.Format(time.RFC3339) {
+			expResp[i].DateCreated = gotResp[i].DateCreated
+		}
+
+		if gotResp[i].DateUpdated.Format(time.RFC3339) == expResp[i].DateUpdated.Format(time.RFC3339) {
+			expResp[i].DateUpdated = gotResp[i].DateUpdated
+		}
+	}
+
+	return cmp.Diff(gotResp, expResp)
+}
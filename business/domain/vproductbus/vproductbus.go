@@ -4,6 +4,7 @@ package vproductbus
 import (
 	"context"
 	"fmt"
+	"iter"
 
 	"github.com/ardanlabs/encore/business/sdk/order"
 	"github.com/ardanlabs/encore/business/sdk/page"
@@ -14,6 +15,7 @@ import (
 type Storer interface {
 	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Product, error)
 	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryStream(ctx context.Context, filter QueryFilter, orderBy order.By) iter.Seq2[Product, error]
 }
 
 // Business manages the set of APIs for view product access.
@@ -42,3 +44,20 @@ func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.
 func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
 	return b.storer.Count(ctx, filter)
 }
+
+// QueryStream retrieves the existing products one at a time in the
+// specified order. The sequence ends at the first error.
+func (b *Business) QueryStream(ctx context.Context, filter QueryFilter, orderBy order.By) iter.Seq2[Product, error] {
+	return func(yield func(Product, error) bool) {
+		for prd, err := range b.storer.QueryStream(ctx, filter, orderBy) {
+			if err != nil {
+				yield(Product{}, fmt.Errorf("querystream: %w", err))
+				return
+			}
+
+			if !yield(prd, nil) {
+				return
+			}
+		}
+	}
+}
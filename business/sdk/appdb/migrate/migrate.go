@@ -8,7 +8,9 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"time"
 
+	"encore.dev"
 	"github.com/ardanlabs/encore/business/sdk/sqldb"
 	"github.com/jmoiron/sqlx"
 )
@@ -16,7 +18,27 @@ import (
 //go:embed seeds/seed.sql
 var seedDoc string
 
-// Seed will insert data needed for a new database.
+// seedName is the name recorded in the seeds table once the demo data has
+// been applied to the database.
+const seedName = "demo"
+
+// CanSeed reports whether demo data can be seeded into the database for the
+// specified environment. Demo data is only seeded when running locally or in
+// a preview environment, never in production.
+func CanSeed(env encore.EnvironmentMeta) bool {
+	switch {
+	case env.Type == encore.EnvEphemeral:
+		return true
+
+	case env.Type == encore.EnvDevelopment && env.Cloud == encore.CloudLocal:
+		return true
+	}
+
+	return false
+}
+
+// Seed will insert data needed for a new database. A marker is recorded in
+// the seeds table so repeated calls will not apply the seed data again.
 func Seed(ctx context.Context, db *sqlx.DB) (err error) {
 	if err := sqldb.StatusCheck(ctx, db); err != nil {
 		return fmt.Errorf("status check database: %w", err)
@@ -38,7 +60,29 @@ func Seed(ctx context.Context, db *sqlx.DB) (err error) {
 		}
 	}()
 
-	if _, err := tx.Exec(seedDoc); err != nil {
+	const q = `
+	INSERT INTO seeds
+		(name, date_applied)
+	VALUES
+		($1, $2)
+	ON CONFLICT DO NOTHING`
+
+	res, err := tx.ExecContext(ctx, q, seedName, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("marker: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("marker rows: %w", err)
+	}
+
+	// The seed has already been applied to this database.
+	if n == 0 {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, seedDoc); err != nil {
 		return fmt.Errorf("exec: %w", err)
 	}
 
@@ -48,3 +92,20 @@ func Seed(ctx context.Context, db *sqlx.DB) (err error) {
 
 	return nil
 }
+
+// SchemaVersion returns the version of the last migration Encore applied to
+// the database and if that migration failed part way through.
+func SchemaVersion(ctx context.Context, db *sqlx.DB) (version int, dirty bool, err error) {
+	const q = `
+	SELECT
+		version, dirty
+	FROM
+		schema_migrations
+	LIMIT 1`
+
+	if err := db.QueryRowContext(ctx, q).Scan(&version, &dirty); err != nil {
+		return 0, false, fmt.Errorf("query: %w", err)
+	}
+
+	return version, dirty, nil
+}
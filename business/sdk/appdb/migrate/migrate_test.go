@@ -0,0 +1,30 @@
+package migrate_test
+
+import (
+	"testing"
+
+	"encore.dev"
+	"github.com/ardanlabs/encore/business/sdk/appdb/migrate"
+)
+
+func Test_CanSeed(t *testing.T) {
+	table := []struct {
+		name string
+		env  encore.EnvironmentMeta
+		exp  bool
+	}{
+		{"local", encore.EnvironmentMeta{Type: encore.EnvDevelopment, Cloud: encore.CloudLocal}, true},
+		{"preview", encore.EnvironmentMeta{Type: encore.EnvEphemeral, Cloud: encore.EncoreCloud}, true},
+		{"development", encore.EnvironmentMeta{Type: encore.EnvDevelopment, Cloud: encore.CloudGCP}, false},
+		{"production", encore.EnvironmentMeta{Type: encore.EnvProduction, Cloud: encore.CloudAWS}, false},
+		{"test", encore.EnvironmentMeta{Type: encore.EnvTest, Cloud: encore.CloudLocal}, false},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := migrate.CanSeed(tt.env); got != tt.exp {
+				t.Fatalf("Should get the expected result for %s: got %v exp %v", tt.name, got, tt.exp)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+CREATE OR REPLACE VIEW view_products AS
+SELECT
+    p.product_id,
+    p.user_id,
+	p.name,
+    p.cost,
+	p.quantity,
+    p.date_created,
+    p.date_updated,
+    u.name AS user_name,
+    u.email AS user_email,
+    p.cost * p.quantity AS value,
+    count(1) OVER (PARTITION BY p.user_id) AS user_products
+FROM
+    products AS p
+JOIN
+    users AS u ON u.user_id = p.user_id;
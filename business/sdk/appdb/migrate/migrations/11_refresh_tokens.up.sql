@@ -0,0 +1,16 @@
+CREATE TABLE refresh_tokens (
+	token_id     UUID      NOT NULL,
+	family_id    UUID      NOT NULL,
+	user_id      UUID      NOT NULL,
+	token_hash   TEXT      NOT NULL,
+	revoked      BOOLEAN   NOT NULL DEFAULT FALSE,
+	date_expires TIMESTAMP NOT NULL,
+	date_created TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (token_id),
+	UNIQUE (token_hash),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX refresh_tokens_family_id_idx ON refresh_tokens (family_id);
+CREATE INDEX refresh_tokens_user_id_idx ON refresh_tokens (user_id);
@@ -0,0 +1,11 @@
+CREATE TABLE token_revocations (
+	user_id      UUID      NOT NULL,
+	token_id     UUID      NOT NULL,
+	date_expires TIMESTAMP NOT NULL,
+	date_created TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (user_id, token_id),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX token_revocations_date_expires_idx ON token_revocations (date_expires);
@@ -0,0 +1,15 @@
+CREATE TABLE api_keys (
+	api_key_id   UUID      NOT NULL,
+	user_id      UUID      NOT NULL,
+	name         TEXT      NOT NULL,
+	key_hash     TEXT      NOT NULL,
+	roles        TEXT[]    NOT NULL,
+	date_expires TIMESTAMP NULL,
+	date_created TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (api_key_id),
+	UNIQUE (key_hash),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX api_keys_user_id_idx ON api_keys (user_id);
@@ -0,0 +1,15 @@
+CREATE TABLE role_permissions (
+	role       TEXT NOT NULL,
+	permission TEXT NOT NULL,
+
+	PRIMARY KEY (role, permission)
+);
+
+INSERT INTO role_permissions (role, permission) VALUES
+	('ADMIN', '*'),
+	('USER', 'home:read'),
+	('USER', 'home:write'),
+	('USER', 'home:delete'),
+	('USER', 'product:read'),
+	('USER', 'product:write'),
+	('USER', 'product:delete');
@@ -0,0 +1,21 @@
+CREATE TABLE oauth_states (
+	state        TEXT      NOT NULL,
+	provider     TEXT      NOT NULL,
+	nonce        TEXT      NOT NULL,
+	date_expires TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (state)
+);
+
+CREATE TABLE user_identities (
+	provider     TEXT      NOT NULL,
+	subject      TEXT      NOT NULL,
+	user_id      UUID      NOT NULL,
+	email        TEXT      NOT NULL,
+	date_created TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (provider, subject),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX user_identities_user_id_idx ON user_identities (user_id);
@@ -0,0 +1,13 @@
+CREATE TABLE password_resets (
+	reset_id     UUID      NOT NULL,
+	user_id      UUID      NOT NULL,
+	token_hash   TEXT      NOT NULL,
+	date_expires TIMESTAMP NOT NULL,
+	date_created TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (reset_id),
+	UNIQUE (token_hash),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX password_resets_user_id_idx ON password_resets (user_id);
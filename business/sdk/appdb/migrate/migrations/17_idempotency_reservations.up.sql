@@ -0,0 +1,5 @@
+ALTER TABLE idempotency_keys
+	ADD COLUMN http_status    INT       NOT NULL DEFAULT 0,
+	ADD COLUMN date_completed TIMESTAMP NULL;
+
+UPDATE idempotency_keys SET date_completed = date_created;
@@ -0,0 +1,21 @@
+CREATE INDEX products_user_id_idx ON products (user_id);
+
+CREATE OR REPLACE VIEW view_products AS
+SELECT
+    p.product_id,
+    p.user_id,
+	p.name,
+    p.cost,
+	p.quantity,
+    p.date_created,
+    p.date_updated,
+    u.name AS user_name,
+    u.email AS user_email,
+    p.cost * p.quantity AS value,
+    c.user_products
+FROM
+    products AS p
+JOIN
+    users AS u ON u.user_id = p.user_id
+CROSS JOIN LATERAL
+    (SELECT count(1) AS user_products FROM products AS up WHERE up.user_id = p.user_id) AS c;
@@ -0,0 +1,4 @@
+ALTER TABLE refresh_tokens
+	ADD COLUMN roles TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE refresh_tokens SET revoked = TRUE;
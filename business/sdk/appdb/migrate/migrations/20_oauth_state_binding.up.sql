@@ -0,0 +1,5 @@
+ALTER TABLE oauth_states
+	ADD COLUMN verifier TEXT NOT NULL DEFAULT '',
+	ADD COLUMN user_id  UUID NULL REFERENCES users(user_id) ON DELETE CASCADE;
+
+DELETE FROM oauth_states;
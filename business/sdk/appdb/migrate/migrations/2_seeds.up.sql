@@ -0,0 +1,6 @@
+CREATE TABLE seeds (
+	name         TEXT      NOT NULL,
+	date_applied TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (name)
+);
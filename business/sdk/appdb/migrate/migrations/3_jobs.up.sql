@@ -0,0 +1,16 @@
+CREATE TABLE jobs (
+	job_id       UUID      NOT NULL,
+	user_id      UUID      NOT NULL,
+	kind         TEXT      NOT NULL,
+	payload      TEXT      NOT NULL,
+	status       TEXT      NOT NULL,
+	attempts     INT       NOT NULL,
+	max_attempts INT       NOT NULL,
+	result       TEXT      NULL,
+	error        TEXT      NULL,
+	date_created TIMESTAMP NOT NULL,
+	date_updated TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (job_id),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
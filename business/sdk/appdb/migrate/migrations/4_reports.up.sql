@@ -0,0 +1,9 @@
+CREATE TABLE daily_reports (
+	report_date     DATE           NOT NULL,
+	new_users       INT            NOT NULL,
+	new_products    INT            NOT NULL,
+	inventory_value NUMERIC(14, 2) NOT NULL,
+	date_created    TIMESTAMP      NOT NULL,
+
+	PRIMARY KEY (report_date)
+);
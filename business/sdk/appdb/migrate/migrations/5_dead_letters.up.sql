@@ -0,0 +1,13 @@
+CREATE TABLE dead_letters (
+	dead_letter_id UUID      NOT NULL,
+	topic          TEXT      NOT NULL,
+	subscription   TEXT      NOT NULL,
+	message_id     TEXT      NOT NULL,
+	payload        TEXT      NOT NULL,
+	error          TEXT      NOT NULL,
+	attempts       INT       NOT NULL,
+	date_created   TIMESTAMP NOT NULL,
+	date_replayed  TIMESTAMP NULL,
+
+	PRIMARY KEY (dead_letter_id)
+);
@@ -0,0 +1,12 @@
+CREATE TABLE idempotency_keys (
+	user_id         UUID      NOT NULL,
+	idempotency_key TEXT      NOT NULL,
+	endpoint        TEXT      NOT NULL,
+	request_hash    TEXT      NOT NULL,
+	payload         TEXT      NOT NULL,
+	date_created    TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (user_id, idempotency_key)
+);
+
+CREATE INDEX idempotency_keys_date_created_idx ON idempotency_keys (date_created);
@@ -0,0 +1,14 @@
+CREATE TABLE audits (
+	audit_id     UUID      NOT NULL,
+	actor_id     UUID      NOT NULL,
+	action       TEXT      NOT NULL,
+	endpoint     TEXT      NOT NULL,
+	entity_id    TEXT      NOT NULL,
+	payload      TEXT      NOT NULL,
+	date_created TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (audit_id)
+);
+
+CREATE INDEX audits_actor_id_idx ON audits (actor_id);
+CREATE INDEX audits_date_created_idx ON audits (date_created);
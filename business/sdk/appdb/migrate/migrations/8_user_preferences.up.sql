@@ -0,0 +1,10 @@
+CREATE TABLE user_preferences (
+	user_id       UUID      NOT NULL,
+	entity        TEXT      NOT NULL,
+	rows_per_page INT       NOT NULL,
+	order_by      TEXT      NOT NULL,
+	date_updated  TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (user_id, entity),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
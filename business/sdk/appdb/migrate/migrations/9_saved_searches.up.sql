@@ -0,0 +1,11 @@
+CREATE TABLE saved_searches (
+	user_id      UUID      NOT NULL,
+	entity       TEXT      NOT NULL,
+	name         TEXT      NOT NULL,
+	query        TEXT      NOT NULL,
+	date_created TIMESTAMP NOT NULL,
+	date_updated TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (user_id, entity, name),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
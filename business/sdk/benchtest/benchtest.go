@@ -0,0 +1,112 @@
+// Package benchtest provides the standard benchmarks for the business and
+// store layers. They run against a dbtest database so a change made for
+// performance can be measured the same way before and after it's made.
+package benchtest
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/ardanlabs/encore/business/domain/productbus"
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/domain/userbus/stores/userdb"
+	"github.com/ardanlabs/encore/business/sdk/dbtest"
+	"github.com/ardanlabs/encore/business/sdk/page"
+)
+
+// ProductCreate benchmarks creating a product, which includes looking up the
+// user who owns it.
+func ProductCreate(b *testing.B, db *dbtest.Database) {
+	ctx, cancel := dbtest.Context()
+	defer cancel()
+
+	usr := seedUser(b, db)
+	nps := productbus.TestGenerateNewProducts(db.Rand, b.N, usr.ID)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := range b.N {
+		if _, err := db.BusDomain.Product.Create(ctx, nps[i]); err != nil {
+			b.Fatalf("Should be able to create a product: %s", err)
+		}
+	}
+}
+
+// UserQueryByID benchmarks querying a user by id through the cache the
+// service uses and straight from the database.
+func UserQueryByID(b *testing.B, db *dbtest.Database) {
+	usr := seedUser(b, db)
+
+	uncached := userbus.NewBusiness(db.Log, db.Clock, db.BusDomain.Delegate, userdb.NewStore(db.Log, db.DB))
+
+	buses := []struct {
+		name string
+		bus  *userbus.Business
+	}{
+		{name: "cached", bus: db.BusDomain.User},
+		{name: "uncached", bus: uncached},
+	}
+
+	for _, bb := range buses {
+		b.Run(bb.name, func(b *testing.B) {
+			ctx, cancel := dbtest.Context()
+			defer cancel()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for range b.N {
+				if _, err := bb.bus.QueryByID(ctx, usr.ID); err != nil {
+					b.Fatalf("Should be able to query the user: %s", err)
+				}
+			}
+		})
+	}
+}
+
+// ProductQuery benchmarks querying a page of products at the first page,
+// the middle page and the last page of the total number of products, which
+// shows the cost of offset paging as a client moves deeper.
+func ProductQuery(b *testing.B, db *dbtest.Database, total int, rows int) {
+	ctx, cancel := dbtest.Context()
+	defer cancel()
+
+	usr := seedUser(b, db)
+
+	if _, err := productbus.TestGenerateSeedProducts(ctx, db.Rand, total, db.BusDomain.Product, usr.ID); err != nil {
+		b.Fatalf("Seeding products: %s", err)
+	}
+
+	last := max(total/rows, 1)
+
+	for _, number := range []int{1, max(last/2, 1), last} {
+		b.Run(fmt.Sprintf("page-%d", number), func(b *testing.B) {
+			pg := page.MustParse(strconv.Itoa(number), strconv.Itoa(rows))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for range b.N {
+				if _, err := db.BusDomain.Product.Query(ctx, productbus.QueryFilter{}, productbus.DefaultOrderBy, pg); err != nil {
+					b.Fatalf("Should be able to query the products: %s", err)
+				}
+			}
+		})
+	}
+}
+
+func seedUser(b *testing.B, db *dbtest.Database) userbus.User {
+	b.Helper()
+
+	ctx, cancel := dbtest.Context()
+	defer cancel()
+
+	usr, err := dbtest.NewUserBuilder(db).Build(ctx)
+	if err != nil {
+		b.Fatalf("Seeding user: %s", err)
+	}
+
+	return usr.User
+}
@@ -0,0 +1,37 @@
+package benchtest_test
+
+import (
+	"context"
+	"testing"
+
+	"encore.dev/et"
+	"github.com/ardanlabs/encore/business/sdk/benchtest"
+	"github.com/ardanlabs/encore/business/sdk/dbtest"
+)
+
+// The benchmarks run with:
+//
+//	$ encore test -run none -bench . ./business/sdk/benchtest
+
+func Benchmark_Business(b *testing.B) {
+	edb, err := et.NewTestDatabase(context.Background(), "app")
+	if err != nil {
+		b.Fatalf("Creating new database: %s", err)
+	}
+
+	db := dbtest.NewDatabase(b, edb)
+
+	// The products are queried first so the products other benchmarks
+	// create don't change the pages.
+	b.Run("product-query", func(b *testing.B) {
+		benchtest.ProductQuery(b, db, 1000, 10)
+	})
+
+	b.Run("user-querybyid", func(b *testing.B) {
+		benchtest.UserQueryByID(b, db)
+	})
+
+	b.Run("product-create", func(b *testing.B) {
+		benchtest.ProductCreate(b, db)
+	})
+}
@@ -0,0 +1,186 @@
+// Package cachemetrics provides support for observing how well a cache is
+// working, with the hits, misses and evictions it reports and the keys it
+// serves the most.
+package cachemetrics
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Counters represents the metrics a cache reports to, labelled by the name
+// of the cache. The app layer provides them so the business layer doesn't
+// depend on how the metrics are exported.
+type Counters interface {
+	IncCacheHit(cache string)
+	IncCacheMiss(cache string)
+	AddCacheEvictions(cache string, n int)
+}
+
+// Key represents a key with the number of times it was served from the cache.
+type Key struct {
+	Key  string `json:"key"`
+	Hits uint64 `json:"hits"`
+}
+
+// Stats represents what a cache has done since it was constructed.
+type Stats struct {
+	Cache     string `json:"cache"`
+	Size      int    `json:"size"`
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Missing   uint64 `json:"missing"`
+	Hottest   []Key  `json:"hottest"`
+}
+
+// Recorder counts what a cache does and reports it to the counters. It
+// implements the sturdyc metrics recorder, so it's given to a cache with
+// sturdyc.WithMetrics.
+type Recorder struct {
+	name      string
+	counters  Counters
+	size      func() int
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+	missing   atomic.Uint64
+	mu        sync.Mutex
+	keys      map[string]uint64
+}
+
+// New constructs a recorder for the named cache. The counters can be nil
+// when the metrics aren't exported, like in tests.
+func New(name string, counters Counters) *Recorder {
+	return &Recorder{
+		name:     name,
+		counters: counters,
+		keys:     make(map[string]uint64),
+	}
+}
+
+// Touch records that the key was served from the cache. A cache that stores
+// a value under several keys touches the one that identifies the value, so
+// the hottest keys are counted once per value.
+func (r *Recorder) Touch(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.keys[key]++
+}
+
+// Forget drops the count of the key once it's removed from the cache.
+func (r *Recorder) Forget(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.keys, key)
+}
+
+// Stats returns what the cache has done with the top number of keys it has
+// served the most. The keys the cache holds are passed so the counts of the
+// keys that expired or were evicted are dropped.
+func (r *Recorder) Stats(top int, keys []string) Stats {
+	held := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		held[key] = true
+	}
+
+	r.mu.Lock()
+	hottest := make([]Key, 0, len(r.keys))
+	for key, hits := range r.keys {
+		if !held[key] {
+			delete(r.keys, key)
+			continue
+		}
+		hottest = append(hottest, Key{Key: key, Hits: hits})
+	}
+	r.mu.Unlock()
+
+	slices.SortFunc(hottest, func(a Key, b Key) int {
+		if c := cmp.Compare(b.Hits, a.Hits); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Key, b.Key)
+	})
+
+	var size int
+	if r.size != nil {
+		size = r.size()
+	}
+
+	return Stats{
+		Cache:     r.name,
+		Size:      size,
+		Hits:      r.hits.Load(),
+		Misses:    r.misses.Load(),
+		Evictions: r.evictions.Load(),
+		Missing:   r.missing.Load(),
+		Hottest:   hottest[:min(top, len(hottest))],
+	}
+}
+
+// =============================================================================
+// sturdyc.MetricsRecorder
+
+// CacheHit is called for every key that is found in the cache.
+func (r *Recorder) CacheHit() {
+	r.hits.Add(1)
+
+	if r.counters != nil {
+		r.counters.IncCacheHit(r.name)
+	}
+}
+
+// CacheMiss is called for every key that isn't found in the cache.
+func (r *Recorder) CacheMiss() {
+	r.misses.Add(1)
+
+	if r.counters != nil {
+		r.counters.IncCacheMiss(r.name)
+	}
+}
+
+// EntriesEvicted is called when the cache evicts entries, either because
+// they expired or because the cache is full.
+func (r *Recorder) EntriesEvicted(n int) {
+	if n == 0 {
+		return
+	}
+
+	r.evictions.Add(uint64(n))
+
+	if r.counters != nil {
+		r.counters.AddCacheEvictions(r.name, n)
+	}
+}
+
+// ObserveCacheSize is called by the cache with the function that reports
+// how many entries it holds.
+func (r *Recorder) ObserveCacheSize(size func() int) {
+	r.size = size
+}
+
+// AsynchronousRefresh isn't tracked.
+func (r *Recorder) AsynchronousRefresh() {}
+
+// SynchronousRefresh isn't tracked.
+func (r *Recorder) SynchronousRefresh() {}
+
+// MissingRecord is called for every key that is answered from the cache as
+// missing from the store.
+func (r *Recorder) MissingRecord() {
+	r.missing.Add(1)
+}
+
+// ForcedEviction isn't tracked since the entries it evicts are reported by
+// EntriesEvicted.
+func (r *Recorder) ForcedEviction() {}
+
+// ShardIndex isn't tracked.
+func (r *Recorder) ShardIndex(int) {}
+
+// CacheBatchRefreshSize isn't tracked.
+func (r *Recorder) CacheBatchRefreshSize(int) {}
@@ -0,0 +1,42 @@
+package cachemetrics_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/encore/business/sdk/cachemetrics"
+)
+
+func Test_Stats(t *testing.T) {
+	r := cachemetrics.New("test", nil)
+	r.ObserveCacheSize(func() int { return 2 })
+
+	for _, key := range []string{"a", "b", "b", "c", "c", "c"} {
+		r.CacheHit()
+		r.Touch(key)
+	}
+	r.CacheMiss()
+	r.EntriesEvicted(3)
+	r.EntriesEvicted(0)
+
+	// The cache no longer holds c, so its count is dropped.
+	stats := r.Stats(5, []string{"a", "b"})
+
+	if stats.Size != 2 || stats.Hits != 6 || stats.Misses != 1 || stats.Evictions != 3 {
+		t.Fatalf("Should report the size and counts, got %+v", stats)
+	}
+
+	exp := []cachemetrics.Key{{Key: "b", Hits: 2}, {Key: "a", Hits: 1}}
+	if len(stats.Hottest) != len(exp) {
+		t.Fatalf("Should report %d hot keys, got %+v", len(exp), stats.Hottest)
+	}
+
+	for i := range exp {
+		if stats.Hottest[i] != exp[i] {
+			t.Fatalf("Should report %+v as hot key %d, got %+v", exp[i], i, stats.Hottest[i])
+		}
+	}
+
+	if stats = r.Stats(5, []string{"a", "b", "c"}); len(stats.Hottest) != 2 {
+		t.Fatalf("Should not count a dropped key again, got %+v", stats.Hottest)
+	}
+}
@@ -0,0 +1,93 @@
+// Package chaos provides support for injecting faults into the calls made to
+// a store, like latency, transient errors and cancelled contexts. The faults
+// are chosen from a seeded source so a test sees the same faults every time
+// it makes the same calls.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrTransient is the error returned for an injected failure when the
+// configuration doesn't set one.
+var ErrTransient = errors.New("chaos: transient failure")
+
+// Config represents the faults to inject.
+type Config struct {
+	// Latency is added to every call before it's made.
+	Latency time.Duration
+
+	// ErrorRate is the fraction of calls, between 0 and 1, that fail with Err.
+	ErrorRate float64
+	Err       error
+
+	// CancelRate is the fraction of calls, between 0 and 1, that fail as if
+	// their context was cancelled.
+	CancelRate float64
+
+	// Methods limits the faults to the calls to these methods. All the
+	// methods get faults when it's empty.
+	Methods []string
+
+	// Seed seeds the source the faults are chosen from.
+	Seed int64
+}
+
+// Injector chooses the faults for the calls made to a store.
+type Injector struct {
+	cfg Config
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// New constructs an injector for the configured faults.
+func New(cfg Config) *Injector {
+	if cfg.Err == nil {
+		cfg.Err = ErrTransient
+	}
+
+	return &Injector{
+		cfg: cfg,
+		rnd: rand.New(rand.NewSource(cfg.Seed)),
+	}
+}
+
+// Inject waits for the latency and returns the fault for a call to the
+// method, or nil when the call should be made. The context error is
+// returned when the context is done while waiting.
+func (inj *Injector) Inject(ctx context.Context, method string) error {
+	if len(inj.cfg.Methods) > 0 && !slices.Contains(inj.cfg.Methods, method) {
+		return nil
+	}
+
+	if inj.cfg.Latency > 0 {
+		timer := time.NewTimer(inj.cfg.Latency)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	inj.mu.Lock()
+	roll := inj.rnd.Float64()
+	inj.mu.Unlock()
+
+	switch {
+	case roll < inj.cfg.CancelRate:
+		return context.Canceled
+
+	case roll < inj.cfg.CancelRate+inj.cfg.ErrorRate:
+		return inj.cfg.Err
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package chaos_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/encore/business/sdk/chaos"
+)
+
+func Test_Deterministic(t *testing.T) {
+	cfg := chaos.Config{
+		ErrorRate:  0.3,
+		CancelRate: 0.2,
+		Seed:       42,
+	}
+
+	faults := func() []error {
+		inj := chaos.New(cfg)
+
+		errs := make([]error, 100)
+		for i := range errs {
+			errs[i] = inj.Inject(context.Background(), "Create")
+		}
+
+		return errs
+	}
+
+	first := faults()
+	second := faults()
+
+	var failed, cancelled int
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("Should inject the same faults with the same seed, call %d got %v and %v", i, first[i], second[i])
+		}
+
+		switch {
+		case errors.Is(first[i], chaos.ErrTransient):
+			failed++
+		case errors.Is(first[i], context.Canceled):
+			cancelled++
+		}
+	}
+
+	if failed == 0 || cancelled == 0 || failed+cancelled == len(first) {
+		t.Fatalf("Should inject some of each fault, got %d failed and %d cancelled", failed, cancelled)
+	}
+}
+
+func Test_Methods(t *testing.T) {
+	errFail := errors.New("fail")
+
+	inj := chaos.New(chaos.Config{
+		ErrorRate: 1,
+		Err:       errFail,
+		Methods:   []string{"Update"},
+	})
+
+	if err := inj.Inject(context.Background(), "Create"); err != nil {
+		t.Fatalf("Should not inject a fault for another method: %s", err)
+	}
+
+	if err := inj.Inject(context.Background(), "Update"); !errors.Is(err, errFail) {
+		t.Fatalf("Should inject the configured error, got %v", err)
+	}
+}
+
+func Test_Latency(t *testing.T) {
+	inj := chaos.New(chaos.Config{
+		Latency: time.Hour,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := inj.Inject(ctx, "Query"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Should stop waiting when the context is done, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package dbtest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ardanlabs/encore/business/domain/homebus"
+	"github.com/ardanlabs/encore/business/domain/productbus"
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/sdk/unitest"
+)
+
+// UserBuilder builds a user for a test with the products and homes it owns,
+// so seed data can be composed per test:
+//
+//	usr, err := dbtest.NewUserBuilder(db).Role(userbus.Roles.Admin).WithProducts(3).Build(ctx)
+type UserBuilder struct {
+	db       *Database
+	role     userbus.Role
+	products int
+	homes    int
+}
+
+// NewUserBuilder constructs a builder for a user with the USER role and
+// nothing else.
+func NewUserBuilder(db *Database) *UserBuilder {
+	return &UserBuilder{
+		db:   db,
+		role: userbus.Roles.User,
+	}
+}
+
+// Role sets the role of the user.
+func (ub *UserBuilder) Role(role userbus.Role) *UserBuilder {
+	ub.role = role
+	return ub
+}
+
+// WithProducts sets the number of products the user owns.
+func (ub *UserBuilder) WithProducts(n int) *UserBuilder {
+	ub.products = n
+	return ub
+}
+
+// WithHomes sets the number of homes the user owns.
+func (ub *UserBuilder) WithHomes(n int) *UserBuilder {
+	ub.homes = n
+	return ub
+}
+
+// Build inserts the user with the generated products and homes through the
+// business layer with the values generated from the random source of the
+// database. The builder can be used again to build another user.
+func (ub *UserBuilder) Build(ctx context.Context) (unitest.User, error) {
+	usrs, err := userbus.TestSeedUsers(ctx, ub.db.Rand, 1, ub.role, ub.db.BusDomain.User)
+	if err != nil {
+		return unitest.User{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu := unitest.User{
+		User: usrs[0],
+	}
+
+	if ub.products > 0 {
+		tu.Products, err = productbus.TestGenerateSeedProducts(ctx, ub.db.Rand, ub.products, ub.db.BusDomain.Product, tu.ID)
+		if err != nil {
+			return unitest.User{}, fmt.Errorf("seeding products : %w", err)
+		}
+	}
+
+	if ub.homes > 0 {
+		tu.Homes, err = homebus.TestGenerateSeedHomes(ctx, ub.db.Rand, ub.homes, ub.db.BusDomain.Home, tu.ID)
+		if err != nil {
+			return unitest.User{}, fmt.Errorf("seeding homes : %w", err)
+		}
+	}
+
+	return tu, nil
+}
@@ -3,63 +3,129 @@ package dbtest
 
 import (
 	"context"
+	"errors"
+	"math/rand"
+	"os"
+	"strconv"
 	"testing"
 	"time"
 
 	esqldb "encore.dev/storage/sqldb"
+	"github.com/ardanlabs/encore/business/domain/apikeybus"
+	"github.com/ardanlabs/encore/business/domain/apikeybus/stores/apikeydb"
+	"github.com/ardanlabs/encore/business/domain/auditbus"
+	"github.com/ardanlabs/encore/business/domain/auditbus/stores/auditdb"
+	"github.com/ardanlabs/encore/business/domain/deadletterbus"
+	"github.com/ardanlabs/encore/business/domain/deadletterbus/stores/deadletterdb"
 	"github.com/ardanlabs/encore/business/domain/homebus"
 	"github.com/ardanlabs/encore/business/domain/homebus/stores/homedb"
+	"github.com/ardanlabs/encore/business/domain/idempotencybus"
+	"github.com/ardanlabs/encore/business/domain/idempotencybus/stores/idempotencydb"
+	"github.com/ardanlabs/encore/business/domain/jobbus"
+	"github.com/ardanlabs/encore/business/domain/jobbus/stores/jobdb"
+	"github.com/ardanlabs/encore/business/domain/oauthbus"
+	"github.com/ardanlabs/encore/business/domain/oauthbus/stores/oauthdb"
 	"github.com/ardanlabs/encore/business/domain/productbus"
 	"github.com/ardanlabs/encore/business/domain/productbus/stores/productdb"
+	"github.com/ardanlabs/encore/business/domain/reportbus"
+	"github.com/ardanlabs/encore/business/domain/reportbus/stores/reportdb"
+	"github.com/ardanlabs/encore/business/domain/savedsearchbus"
+	"github.com/ardanlabs/encore/business/domain/savedsearchbus/stores/savedsearchdb"
+	"github.com/ardanlabs/encore/business/domain/tokenbus"
+	"github.com/ardanlabs/encore/business/domain/tokenbus/stores/tokendb"
 	"github.com/ardanlabs/encore/business/domain/userbus"
 	"github.com/ardanlabs/encore/business/domain/userbus/stores/usercache"
 	"github.com/ardanlabs/encore/business/domain/userbus/stores/userdb"
+	"github.com/ardanlabs/encore/business/domain/userprefsbus"
+	"github.com/ardanlabs/encore/business/domain/userprefsbus/stores/userprefsdb"
 	"github.com/ardanlabs/encore/business/domain/vproductbus"
 	"github.com/ardanlabs/encore/business/domain/vproductbus/stores/vproductdb"
 	"github.com/ardanlabs/encore/business/sdk/delegate"
+	"github.com/ardanlabs/encore/business/sdk/pubsub"
 	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/business/sdk/storecache"
+	"github.com/ardanlabs/encore/foundation/clock"
 	"github.com/ardanlabs/encore/foundation/logger"
 	"github.com/jmoiron/sqlx"
 )
 
 // BusDomain represents all the business domain apis needed for testing.
 type BusDomain struct {
-	Delegate *delegate.Delegate
-	Home     *homebus.Business
-	Product  *productbus.Business
-	User     *userbus.Business
-	VProduct *vproductbus.Business
+	Delegate    *delegate.Delegate
+	APIKey      *apikeybus.Business
+	Audit       *auditbus.Business
+	DeadLetter  *deadletterbus.Business
+	Home        *homebus.Business
+	Idempotency *idempotencybus.Business
+	Job         *jobbus.Business
+	OAuth       *oauthbus.Business
+	Product     *productbus.Business
+	Report      *reportbus.Business
+	SavedSearch *savedsearchbus.Business
+	Token       *tokenbus.Business
+	User        *userbus.Business
+	UserPrefs   *userprefsbus.Business
+	VProduct    *vproductbus.Business
 }
 
-func newBusDomains(log *logger.Logger, db *sqlx.DB) BusDomain {
+func newBusDomains(log *logger.Logger, clk clock.Clock, db *sqlx.DB) BusDomain {
 	delegate := delegate.New(log)
-	userBus := userbus.NewBusiness(log, delegate, usercache.NewStore(log, userdb.NewStore(log, db), time.Hour))
-	productBus := productbus.NewBusiness(log, userBus, delegate, productdb.NewStore(log, db))
-	homeBus := homebus.NewBusiness(log, userBus, delegate, homedb.NewStore(log, db))
+	userBus := userbus.NewBusiness(log, clk, delegate, usercache.NewStore(log, userdb.NewStore(log, db), storecache.Config{TTL: time.Hour}, clk, nil))
+	productBus := productbus.NewBusiness(log, clk, userBus, delegate, productdb.NewStore(log, db))
+	homeBus := homebus.NewBusiness(log, clk, userBus, delegate, homedb.NewStore(log, db))
 	vproductBus := vproductbus.NewBusiness(vproductdb.NewStore(log, db))
+	jobBus := jobbus.NewBusiness(log, pubsub.JobPublisher{}, jobdb.NewStore(log, db))
+	reportBus := reportbus.NewBusiness(log, nil, reportdb.NewStore(log, db))
+	idempotencyBus := idempotencybus.NewBusiness(log, clk, time.Hour, idempotencydb.NewStore(log, db))
+	deadLetterBus := deadletterbus.NewBusiness(log, clk, deadletterdb.NewStore(log, db))
+	auditBus := auditbus.NewBusiness(log, auditdb.NewStore(log, db))
+	savedSearchBus := savedsearchbus.NewBusiness(log, savedsearchdb.NewStore(log, db))
+	userPrefsBus := userprefsbus.NewBusiness(log, userprefsdb.NewStore(log, db))
+	tokenBus := tokenbus.NewBusiness(log, clk, time.Hour, tokendb.NewStore(log, db))
+	apiKeyBus := apikeybus.NewBusiness(log, clk, userBus, apikeydb.NewStore(log, db))
+	oauthBus := oauthbus.NewBusiness(log, clk, userBus, oauthdb.NewStore(log, db))
 
 	return BusDomain{
-		Delegate: delegate,
-		Home:     homeBus,
-		Product:  productBus,
-		User:     userBus,
-		VProduct: vproductBus,
+		Delegate:    delegate,
+		APIKey:      apiKeyBus,
+		Audit:       auditBus,
+		DeadLetter:  deadLetterBus,
+		Home:        homeBus,
+		Idempotency: idempotencyBus,
+		Job:         jobBus,
+		OAuth:       oauthBus,
+		Product:     productBus,
+		Report:      reportBus,
+		SavedSearch: savedSearchBus,
+		Token:       tokenBus,
+		User:        userBus,
+		UserPrefs:   userPrefsBus,
+		VProduct:    vproductBus,
 	}
 }
 
 // =============================================================================
 
-// Database owns state for running and shutting down tests.
+// Database owns state for running and shutting down tests. The business
+// layer, the user cache and the tokens of the api tests read the time from
+// the clock, so a test can advance it to exercise expiry without sleeping.
+// The delegate events the business layer sends are recorded in Events.
 type Database struct {
 	DB        *sqlx.DB
 	Log       *logger.Logger
+	Clock     *clock.Frozen
+	Rand      *rand.Rand
 	BusDomain BusDomain
+	Events    *Events
 }
 
 // NewDatabase uses the specified database to perform testing. This database
-// should be created using the `et.NewTestDatabase` call. A connection pool
-// is provided with business domain packages.
-func NewDatabase(t *testing.T, edb *esqldb.Database) *Database {
+// should be created using the `et.NewTestDatabase` call, which clones it
+// from a template database encore migrates once per run, so a test doesn't
+// pay for the migrations. A connection pool is provided with business
+// domain packages. The pool is closed when the test ends. There is nothing
+// else to stop since encore runs the database server and owns the clones.
+func NewDatabase(t testing.TB, edb *esqldb.Database) *Database {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -85,10 +151,60 @@ func NewDatabase(t *testing.T, edb *esqldb.Database) *Database {
 
 	log := logger.New("test")
 
+	// The business layer reads the time from a frozen clock so the dates it
+	// records are known. The time is truncated to what the database stores.
+	clk := clock.NewFrozen(time.Now().UTC().Truncate(time.Microsecond))
+
+	// The seed data is generated from a seed that is logged, so a failing
+	// test can be run again with the same data by setting DBTEST_SEED.
+	seed := time.Now().UnixNano()
+	if v := os.Getenv("DBTEST_SEED"); v != "" {
+		seed, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			t.Fatalf("parsing DBTEST_SEED: %v", err)
+		}
+	}
+	t.Logf("seed data is generated with DBTEST_SEED=%d", seed)
+
+	busDomain := newBusDomains(log, clk, db)
+
 	return &Database{
 		Log:       log,
 		DB:        db,
-		BusDomain: newBusDomains(log, db),
+		Clock:     clk,
+		Rand:      rand.New(rand.NewSource(seed)),
+		BusDomain: busDomain,
+		Events:    newEvents(busDomain.Delegate),
+	}
+}
+
+// =============================================================================
+
+// Timeout is how long a test has to make its calls to the database.
+const Timeout = time.Minute
+
+// Context returns a context for the calls a test makes to the database
+// outside of a request. Like a request context it can be cancelled, which
+// the stores require.
+func Context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), Timeout)
+}
+
+// CheckContext fails the test when a call doesn't stop once its context is
+// cancelled, which means a store is calling the database with a context
+// other than the one it was given and would ignore the request deadline.
+func CheckContext(t *testing.T, testName string, calls map[string]func(ctx context.Context) error) {
+	for name, call := range calls {
+		f := func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			if err := call(ctx); !errors.Is(err, context.Canceled) {
+				t.Fatalf("Should stop when the context is cancelled: got %v", err)
+			}
+		}
+
+		t.Run(testName+"-"+name, f)
 	}
 }
 
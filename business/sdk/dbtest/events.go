@@ -0,0 +1,99 @@
+package dbtest
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/ardanlabs/encore/business/domain/homebus"
+	"github.com/ardanlabs/encore/business/domain/productbus"
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/sdk/delegate"
+	"github.com/google/uuid"
+)
+
+// Events records the delegate events the business layer sends during a test,
+// so a test can check the side effects of a call like the events it triggers.
+type Events struct {
+	mu     sync.Mutex
+	events []delegate.Data
+}
+
+// newEvents constructs a recorder registered for the actions of the domains
+// that send events.
+func newEvents(d *delegate.Delegate) *Events {
+	var e Events
+
+	domains := map[string][]string{
+		userbus.DomainName:    {userbus.ActionCreated, userbus.ActionUpdated, userbus.ActionDeleted},
+		productbus.DomainName: {productbus.ActionCreated, productbus.ActionUpdated, productbus.ActionDeleted},
+		homebus.DomainName:    {homebus.ActionCreated, homebus.ActionUpdated, homebus.ActionDeleted},
+	}
+
+	for domain, actions := range domains {
+		for _, action := range actions {
+			d.Register(domain, action, e.record)
+		}
+	}
+
+	return &e
+}
+
+func (e *Events) record(ctx context.Context, data delegate.Data) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.events = append(e.events, data)
+
+	return nil
+}
+
+// Events returns the events recorded since the last reset.
+func (e *Events) Events() []delegate.Data {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]delegate.Data(nil), e.events...)
+}
+
+// Reset removes the events recorded so far, like the ones sent while the seed
+// data was inserted.
+func (e *Events) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.events = nil
+}
+
+// Expect fails the test when no event was recorded for the action in the
+// domain that carries the id in its parameters, like:
+//
+//	db.Events.Expect(t, userbus.DomainName, userbus.ActionUpdated, usr.ID)
+func (e *Events) Expect(t *testing.T, domain string, action string, id uuid.UUID) {
+	t.Helper()
+
+	for _, data := range e.Events() {
+		if data.Domain == domain && data.Action == action && carries(data, id) {
+			return
+		}
+	}
+
+	t.Fatalf("Should send the %s event for %s in the %s domain, got %v", action, id, domain, e.Events())
+}
+
+// carries reports if one of the parameters of the event is the id.
+func carries(data delegate.Data, id uuid.UUID) bool {
+	var params map[string]any
+	if err := json.Unmarshal(data.RawParams, &params); err != nil {
+		return false
+	}
+
+	for _, v := range params {
+		if s, ok := v.(string); ok && s == id.String() {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,89 @@
+package dbtest
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/ardanlabs/encore/business/domain/userbus"
+	"github.com/ardanlabs/encore/business/sdk/unitest"
+	"gopkg.in/yaml.v3"
+)
+
+// Fixtures represents the data a test needs declared in a fixtures file.
+// The users are inserted in the order they are declared.
+//
+//	users:
+//	  - role: USER
+//	    products: 2
+//	  - role: ADMIN
+//	    count: 2
+type Fixtures struct {
+	Users []UserFixture `yaml:"users"`
+}
+
+// UserFixture represents users with the same role and the number of
+// products and homes each of them owns. The values are generated.
+type UserFixture struct {
+	Role     string `yaml:"role"`
+	Count    int    `yaml:"count"`
+	Products int    `yaml:"products"`
+	Homes    int    `yaml:"homes"`
+}
+
+// LoadFixtures inserts the data declared in the fixtures file through the
+// business layer. The users are returned as admins or users by their role
+// in the order they were inserted. The test fails if the data can't be
+// inserted.
+func (db *Database) LoadFixtures(t *testing.T, path string) unitest.SeedData {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Reading fixtures: %s", err)
+	}
+
+	var fxs Fixtures
+	if err := yaml.Unmarshal(data, &fxs); err != nil {
+		t.Fatalf("Parsing fixtures %s: %s", path, err)
+	}
+
+	sd, err := db.insertFixtures(fxs)
+	if err != nil {
+		t.Fatalf("Seeding fixtures %s: %s", path, err)
+	}
+
+	return sd
+}
+
+func (db *Database) insertFixtures(fxs Fixtures) (unitest.SeedData, error) {
+	ctx, cancel := Context()
+	defer cancel()
+
+	var sd unitest.SeedData
+
+	for i, fx := range fxs.Users {
+		role, err := userbus.ParseRole(fx.Role)
+		if err != nil {
+			return unitest.SeedData{}, fmt.Errorf("users[%d]: %w", i, err)
+		}
+
+		ub := NewUserBuilder(db).Role(role).WithProducts(fx.Products).WithHomes(fx.Homes)
+
+		for range max(fx.Count, 1) {
+			tu, err := ub.Build(ctx)
+			if err != nil {
+				return unitest.SeedData{}, fmt.Errorf("users[%d]: %w", i, err)
+			}
+
+			switch role {
+			case userbus.Roles.Admin:
+				sd.Admins = append(sd.Admins, tu)
+			default:
+				sd.Users = append(sd.Users, tu)
+			}
+		}
+	}
+
+	return sd, nil
+}
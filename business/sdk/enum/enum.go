@@ -0,0 +1,97 @@
+// Package enum provides support for business types that have a fixed set of
+// values. The values are declared once in a set, which parses them and is
+// registered by name so the values can be validated before they reach the
+// business layer, like by the enum validation tag.
+package enum
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Enum represents the behavior of a set that doesn't depend on the type of
+// its values.
+type Enum interface {
+	Name() string
+	Names() []string
+	Valid(value string) bool
+}
+
+var registry = struct {
+	sync.RWMutex
+	enums map[string]Enum
+}{
+	enums: make(map[string]Enum),
+}
+
+// Lookup returns the registered set with the specified name.
+func Lookup(name string) (Enum, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	e, exists := registry.enums[name]
+	return e, exists
+}
+
+// =============================================================================
+
+// Set represents the values of an enum type by their names.
+type Set[T fmt.Stringer] struct {
+	name   string
+	values map[string]T
+	names  []string
+}
+
+// New constructs and registers an empty set with the specified name. It's
+// meant to be called when a package is initialized and panics if the name
+// is already registered.
+func New[T fmt.Stringer](name string) *Set[T] {
+	s := Set[T]{
+		name:   name,
+		values: make(map[string]T),
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, exists := registry.enums[name]; exists {
+		panic(fmt.Sprintf("enum %s is already registered", name))
+	}
+	registry.enums[name] = &s
+
+	return &s
+}
+
+// Add adds the value to the set by its name and returns it.
+func (s *Set[T]) Add(value T) T {
+	s.values[value.String()] = value
+	s.names = append(s.names, value.String())
+	return value
+}
+
+// Parse returns the value with the specified name.
+func (s *Set[T]) Parse(name string) (T, error) {
+	value, exists := s.values[name]
+	if !exists {
+		var zero T
+		return zero, fmt.Errorf("invalid %s %q", s.name, name)
+	}
+
+	return value, nil
+}
+
+// Name returns the name the set is registered with.
+func (s *Set[T]) Name() string {
+	return s.name
+}
+
+// Names returns the names of the values in the order they were added.
+func (s *Set[T]) Names() []string {
+	return append([]string(nil), s.names...)
+}
+
+// Valid reports whether a value with the specified name is in the set.
+func (s *Set[T]) Valid(name string) bool {
+	_, exists := s.values[name]
+	return exists
+}
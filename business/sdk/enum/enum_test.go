@@ -0,0 +1,59 @@
+package enum_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/encore/business/sdk/enum"
+)
+
+type color struct {
+	name string
+}
+
+func (c color) String() string {
+	return c.name
+}
+
+func Test_Set(t *testing.T) {
+	colors := enum.New[color]("color")
+	red := colors.Add(color{"RED"})
+	colors.Add(color{"BLUE"})
+
+	c, err := colors.Parse("RED")
+	if err != nil {
+		t.Fatalf("Should be able to parse a value in the set: %s", err)
+	}
+
+	if c != red {
+		t.Fatalf("Should get the value added to the set: got %s", c)
+	}
+
+	if _, err := colors.Parse("GREEN"); err == nil || err.Error() != `invalid color "GREEN"` {
+		t.Fatalf("Should not be able to parse a value not in the set: got %v", err)
+	}
+
+	e, exists := enum.Lookup("color")
+	if !exists {
+		t.Fatalf("Should be able to look up the set by name")
+	}
+
+	if !e.Valid("BLUE") || e.Valid("GREEN") {
+		t.Fatalf("Should get the same values from the registered set")
+	}
+
+	if names := e.Names(); len(names) != 2 || names[0] != "RED" || names[1] != "BLUE" {
+		t.Fatalf("Should get the names in the order they were added: got %v", names)
+	}
+}
+
+func Test_NewDuplicate(t *testing.T) {
+	enum.New[color]("shade")
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Should panic when a name is registered twice")
+		}
+	}()
+
+	enum.New[color]("shade")
+}
@@ -0,0 +1,194 @@
+// Package mockstore provides support for the mock stores of the domains. The
+// mock stores keep their rows in memory and record the calls made to them
+// so the business APIs can be tested without a database.
+package mockstore
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/ardanlabs/encore/business/sdk/page"
+	"github.com/google/uuid"
+)
+
+// Call represents a call made to a store. The context isn't recorded.
+type Call struct {
+	Method string
+	Args   []any
+}
+
+// Recorder records the calls made to a store and holds the errors the store
+// is set to return. The zero value is ready for use.
+type Recorder struct {
+	mu    sync.Mutex
+	calls []Call
+	errs  map[string]error
+}
+
+// Record records a call to the method and returns the error the method is
+// set to return, if any.
+func (r *Recorder) Record(method string, args ...any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+
+	return r.errs[method]
+}
+
+// Calls returns the calls made to the store in the order they were made.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.calls)
+}
+
+// CallsTo returns the calls made to the method in the order they were made.
+func (r *Recorder) CallsTo(method string) []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var calls []Call
+	for _, call := range r.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// SetError sets the error the method returns without touching the rows. A
+// nil error clears it.
+func (r *Recorder) SetError(method string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.errs == nil {
+		r.errs = make(map[string]error)
+	}
+
+	switch err {
+	case nil:
+		delete(r.errs, method)
+	default:
+		r.errs[method] = err
+	}
+}
+
+// Reset clears the recorded calls and the errors that were set.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = nil
+	r.errs = nil
+}
+
+// =============================================================================
+
+// Table holds rows identified by an id in the order they were inserted.
+type Table[T any] struct {
+	mu   sync.RWMutex
+	id   func(T) uuid.UUID
+	rows []T
+}
+
+// NewTable constructs an empty table that identifies rows with the id
+// function.
+func NewTable[T any](id func(T) uuid.UUID) *Table[T] {
+	return &Table[T]{
+		id: id,
+	}
+}
+
+// Insert adds the row to the end of the table.
+func (t *Table[T]) Insert(row T) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rows = append(t.rows, row)
+}
+
+// Update replaces the row with the same id. It does nothing when there is
+// no such row, like an UPDATE statement.
+func (t *Table[T]) Update(row T) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if i := t.index(t.id(row)); i >= 0 {
+		t.rows[i] = row
+	}
+}
+
+// Delete removes the row with the same id. It does nothing when there is no
+// such row, like a DELETE statement.
+func (t *Table[T]) Delete(row T) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if i := t.index(t.id(row)); i >= 0 {
+		t.rows = slices.Delete(t.rows, i, i+1)
+	}
+}
+
+// Find returns the row with the specified id.
+func (t *Table[T]) Find(id uuid.UUID) (T, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if i := t.index(id); i >= 0 {
+		return t.rows[i], true
+	}
+
+	var zero T
+	return zero, false
+}
+
+// Select returns the rows the match function reports true for, in the order
+// they were inserted.
+func (t *Table[T]) Select(match func(T) bool) []T {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var rows []T
+	for _, row := range t.rows {
+		if match(row) {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
+}
+
+// Rows returns all the rows in the order they were inserted.
+func (t *Table[T]) Rows() []T {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return slices.Clone(t.rows)
+}
+
+func (t *Table[T]) index(id uuid.UUID) int {
+	return slices.IndexFunc(t.rows, func(row T) bool {
+		return t.id(row) == id
+	})
+}
+
+// =============================================================================
+
+// Page returns the rows of the requested page.
+func Page[T any](rows []T, pg page.Page) []T {
+	start := min((pg.Number()-1)*pg.RowsPerPage(), len(rows))
+	end := min(start+pg.RowsPerPage(), len(rows))
+
+	return rows[start:end]
+}
+
+// Keyset returns the rows of the first page of the keyset, with the extra
+// row the stores fetch so it's known if there are more rows. The cursor
+// isn't applied.
+func Keyset[T any](rows []T, keyset page.Keyset) []T {
+	return rows[:min(keyset.Limit()+1, len(rows))]
+}
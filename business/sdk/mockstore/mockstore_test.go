@@ -0,0 +1,105 @@
+package mockstore_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ardanlabs/encore/business/sdk/mockstore"
+	"github.com/ardanlabs/encore/business/sdk/page"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+func Test_Recorder(t *testing.T) {
+	var r mockstore.Recorder
+
+	if err := r.Record("Create", 1); err != nil {
+		t.Fatalf("Should not get an error when none is set: %s", err)
+	}
+
+	errFail := errors.New("fail")
+	r.SetError("Update", errFail)
+
+	if err := r.Record("Update", 2); !errors.Is(err, errFail) {
+		t.Fatalf("Should get the error that was set, got %v", err)
+	}
+
+	exp := []mockstore.Call{
+		{Method: "Create", Args: []any{1}},
+		{Method: "Update", Args: []any{2}},
+	}
+
+	if diff := cmp.Diff(r.Calls(), exp); diff != "" {
+		t.Fatalf("Should record the calls in order: %s", diff)
+	}
+
+	if diff := cmp.Diff(r.CallsTo("Update"), exp[1:]); diff != "" {
+		t.Fatalf("Should record the calls to the method: %s", diff)
+	}
+
+	r.Reset()
+
+	if err := r.Record("Update"); err != nil {
+		t.Fatalf("Should not get an error after the reset: %s", err)
+	}
+
+	if len(r.Calls()) != 1 {
+		t.Fatalf("Should only have the call made after the reset, got %d", len(r.Calls()))
+	}
+}
+
+func Test_Table(t *testing.T) {
+	type row struct {
+		ID   uuid.UUID
+		Name string
+	}
+
+	tbl := mockstore.NewTable(func(r row) uuid.UUID {
+		return r.ID
+	})
+
+	rows := []row{
+		{ID: uuid.New(), Name: "a"},
+		{ID: uuid.New(), Name: "b"},
+		{ID: uuid.New(), Name: "c"},
+	}
+
+	for _, r := range rows {
+		tbl.Insert(r)
+	}
+
+	rows[1].Name = "B"
+	tbl.Update(rows[1])
+	tbl.Update(row{ID: uuid.New(), Name: "missing"})
+
+	got, found := tbl.Find(rows[1].ID)
+	if !found || got.Name != "B" {
+		t.Fatalf("Should find the updated row, got %v %t", got, found)
+	}
+
+	tbl.Delete(rows[0])
+
+	if diff := cmp.Diff(tbl.Rows(), rows[1:]); diff != "" {
+		t.Fatalf("Should keep the rows in the order they were inserted: %s", diff)
+	}
+
+	sel := tbl.Select(func(r row) bool {
+		return r.Name == "c"
+	})
+
+	if diff := cmp.Diff(sel, rows[2:]); diff != "" {
+		t.Fatalf("Should select the matching rows: %s", diff)
+	}
+
+	if diff := cmp.Diff(mockstore.Page(rows, page.MustParse("2", "2")), rows[2:]); diff != "" {
+		t.Fatalf("Should get the rows of the page: %s", diff)
+	}
+
+	if got := mockstore.Page(rows, page.MustParse("3", "2")); len(got) != 0 {
+		t.Fatalf("Should get no rows past the last page, got %d", len(got))
+	}
+
+	if diff := cmp.Diff(mockstore.Keyset(rows, page.MustParseKeyset("", "1")), rows[:2]); diff != "" {
+		t.Fatalf("Should get the first page with the extra row: %s", diff)
+	}
+}
@@ -17,10 +17,12 @@ var directions = map[string]string{
 	DESC: "DESC",
 }
 
-// By represents a field used to order by and direction.
+// By represents a field used to order by and direction. Additional fields
+// can be added with Then to break ties in the ordering.
 type By struct {
 	Field     string
 	Direction string
+	then      []By
 }
 
 // NewBy constructs a new By value with no checks.
@@ -38,34 +40,81 @@ func NewBy(field string, direction string) By {
 	}
 }
 
+// Then returns a copy of the By value with the specified field added to the
+// end of the ordering.
+func (b By) Then(field string, direction string) By {
+	then := make([]By, len(b.then), len(b.then)+1)
+	copy(then, b.then)
+
+	b.then = append(then, NewBy(field, direction))
+
+	return b
+}
+
+// Fields returns each field and direction in the order they are applied.
+func (b By) Fields() []By {
+	fields := make([]By, 0, len(b.then)+1)
+	fields = append(fields, By{Field: b.Field, Direction: b.Direction})
+	fields = append(fields, b.then...)
+
+	return fields
+}
+
 // Parse constructs a By value by parsing a string in the form of
-// "field,direction" ie "user_id,ASC".
+// "field,direction" ie "user_id,ASC". Multiple fields are separated by
+// a semicolon ie "cost,DESC;name,ASC".
 func Parse(fieldMappings map[string]string, orderBy string, defaultOrder By) (By, error) {
 	if orderBy == "" {
 		return defaultOrder, nil
 	}
 
-	orderParts := strings.Split(orderBy, ",")
+	var by By
+	seen := make(map[string]struct{})
+
+	for i, term := range strings.Split(orderBy, ";") {
+		field, direction, err := parseTerm(fieldMappings, term)
+		if err != nil {
+			return By{}, err
+		}
+
+		if _, exists := seen[field]; exists {
+			return By{}, fmt.Errorf("duplicate order: %s", field)
+		}
+		seen[field] = struct{}{}
+
+		switch i {
+		case 0:
+			by = NewBy(field, direction)
+		default:
+			by = by.Then(field, direction)
+		}
+	}
+
+	return by, nil
+}
+
+func parseTerm(fieldMappings map[string]string, term string) (string, string, error) {
+	orderParts := strings.Split(term, ",")
 
 	orgFieldName := strings.TrimSpace(orderParts[0])
 	fieldName, exists := fieldMappings[orgFieldName]
 	if !exists {
-		return By{}, fmt.Errorf("unknown order: %s", orgFieldName)
+		return "", "", fmt.Errorf("unknown order: %s", orgFieldName)
 	}
 
 	switch len(orderParts) {
 	case 1:
-		return NewBy(fieldName, ASC), nil
+		return fieldName, ASC, nil
 
 	case 2:
 		direction := strings.TrimSpace(orderParts[1])
 		if _, exists := directions[direction]; !exists {
-			return By{}, fmt.Errorf("unknown direction: %s", direction)
+			return "", "", fmt.Errorf("unknown direction: %s", direction)
 		}
 
-		return NewBy(fieldName, direction), nil
+		return fieldName, direction, nil
 
 	default:
-		return By{}, fmt.Errorf("unknown order: %s", orderBy)
+		return "", "", fmt.Errorf("unknown order: %s", term)
 	}
 }
@@ -0,0 +1,55 @@
+package order_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/encore/business/sdk/order"
+	"github.com/google/go-cmp/cmp"
+)
+
+var fieldMappings = map[string]string{
+	"product_id": "ProductID",
+	"name":       "Name",
+	"cost":       "Cost",
+}
+
+func Test_Parse(t *testing.T) {
+	defaultOrder := order.NewBy("ProductID", order.ASC)
+
+	by, err := order.Parse(fieldMappings, "", defaultOrder)
+	if err != nil {
+		t.Fatalf("Should be able to parse an empty order: %s", err)
+	}
+
+	if diff := cmp.Diff(by.Fields(), defaultOrder.Fields(), cmp.AllowUnexported(order.By{})); diff != "" {
+		t.Fatalf("Should get the default order: %s", diff)
+	}
+
+	by, err = order.Parse(fieldMappings, "cost,DESC;name,ASC;product_id", defaultOrder)
+	if err != nil {
+		t.Fatalf("Should be able to parse multiple fields: %s", err)
+	}
+
+	exp := []order.By{
+		order.NewBy("Cost", order.DESC),
+		order.NewBy("Name", order.ASC),
+		order.NewBy("ProductID", order.ASC),
+	}
+
+	if diff := cmp.Diff(by.Fields(), exp, cmp.AllowUnexported(order.By{})); diff != "" {
+		t.Fatalf("Should get each field in order: %s", diff)
+	}
+
+	tests := []string{
+		"unknown,ASC",
+		"cost,DOWN",
+		"cost,DESC;name,ASC,extra",
+		"cost,DESC;cost,ASC",
+	}
+
+	for _, orderBy := range tests {
+		if _, err := order.Parse(fieldMappings, orderBy, defaultOrder); err == nil {
+			t.Fatalf("Should get an error parsing %q", orderBy)
+		}
+	}
+}
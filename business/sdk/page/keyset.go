@@ -0,0 +1,172 @@
+package page
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Keyset represents a request for the rows that come after or before the
+// row identified by a cursor. Keyset paging doesn't slow down as clients
+// move deeper into a result set like offset paging does, and rows added
+// while paging don't cause rows to be skipped or repeated.
+type Keyset struct {
+	id       string
+	backward bool
+	limit    int
+}
+
+// ParseKeyset parses the cursor and limit strings and validates the values
+// are in reason. An empty cursor represents the first page and the id in a
+// cursor has to be a uuid.
+func ParseKeyset(cursor string, limit string) (Keyset, error) {
+	rows := 10
+	if limit != "" {
+		var err error
+		rows, err = strconv.Atoi(limit)
+		if err != nil {
+			return Keyset{}, fmt.Errorf("limit conversion: %w", err)
+		}
+	}
+
+	if rows <= 0 {
+		return Keyset{}, fmt.Errorf("limit value too small, must be larger than 0")
+	}
+
+	if rows > 100 {
+		return Keyset{}, fmt.Errorf("limit value too large, must be 100 or less")
+	}
+
+	ks := Keyset{
+		limit: rows,
+	}
+
+	if cursor == "" {
+		return ks, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return Keyset{}, fmt.Errorf("cursor decode: %w", err)
+	}
+
+	dir, id, found := strings.Cut(string(raw), ":")
+	if !found || id == "" {
+		return Keyset{}, fmt.Errorf("cursor is not in its proper form")
+	}
+
+	switch dir {
+	case "n":
+	case "p":
+		ks.backward = true
+	default:
+		return Keyset{}, fmt.Errorf("cursor is not in its proper form")
+	}
+
+	if _, err := uuid.Parse(id); err != nil {
+		return Keyset{}, fmt.Errorf("cursor id: %w", err)
+	}
+
+	ks.id = id
+
+	return ks, nil
+}
+
+// MustParseKeyset creates a keyset value for testing.
+func MustParseKeyset(cursor string, limit string) Keyset {
+	ks, err := ParseKeyset(cursor, limit)
+	if err != nil {
+		panic(err)
+	}
+
+	return ks
+}
+
+// String implements the stringer interface.
+func (k Keyset) String() string {
+	return fmt.Sprintf("id: %s backward: %t limit: %d", k.id, k.backward, k.limit)
+}
+
+// Limit returns the number of rows requested.
+func (k Keyset) Limit() int {
+	return k.limit
+}
+
+// Apply writes the keyset condition, ordering and row limit for the specified
+// key column into the query. The key column must be unique and is expected
+// to be trusted input. One more row than the limit is fetched so it's known
+// if there are more rows.
+func (k Keyset) Apply(keyField string, data map[string]any, buf *bytes.Buffer) {
+	direction := "ASC"
+
+	if k.id != "" {
+		op := ">"
+		if k.backward {
+			op = "<"
+			direction = "DESC"
+		}
+
+		switch bytes.Contains(buf.Bytes(), []byte(" WHERE ")) {
+		case true:
+			buf.WriteString(" AND ")
+		default:
+			buf.WriteString(" WHERE ")
+		}
+
+		data["keyset_id"] = k.id
+		fmt.Fprintf(buf, "%s %s :keyset_id", keyField, op)
+	}
+
+	data["keyset_rows"] = k.limit + 1
+	fmt.Fprintf(buf, " ORDER BY %s %s FETCH FIRST :keyset_rows ROWS ONLY", keyField, direction)
+}
+
+// =============================================================================
+
+// Cursors trims the extra row fetched by Apply and restores the order of rows
+// fetched backwards. It returns the cursors for the next and previous pages
+// with an empty cursor meaning there is no page in that direction.
+func Cursors[T any](k Keyset, items []T, key func(T) string) ([]T, string, string) {
+	more := len(items) > k.limit
+	if more {
+		items = items[:k.limit]
+	}
+
+	if k.backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	if len(items) == 0 {
+		return items, "", ""
+	}
+
+	var next, prev string
+
+	switch k.backward {
+	case true:
+		next = encodeCursor("n", key(items[len(items)-1]))
+		if more {
+			prev = encodeCursor("p", key(items[0]))
+		}
+
+	default:
+		if more {
+			next = encodeCursor("n", key(items[len(items)-1]))
+		}
+		if k.id != "" {
+			prev = encodeCursor("p", key(items[0]))
+		}
+	}
+
+	return items, next, prev
+}
+
+func encodeCursor(dir string, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(dir + ":" + id))
+}
@@ -0,0 +1,97 @@
+package page_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/ardanlabs/encore/business/sdk/page"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Keyset(t *testing.T) {
+	ids := []string{
+		"2a7d8f3e-1c4b-4e5a-9f60-0b1c2d3e4f01",
+		"2a7d8f3e-1c4b-4e5a-9f60-0b1c2d3e4f02",
+		"2a7d8f3e-1c4b-4e5a-9f60-0b1c2d3e4f03",
+		"2a7d8f3e-1c4b-4e5a-9f60-0b1c2d3e4f04",
+		"2a7d8f3e-1c4b-4e5a-9f60-0b1c2d3e4f05",
+	}
+	key := func(s string) string { return s }
+
+	// First page, fetched in ascending order with one extra row.
+	ks := page.MustParseKeyset("", "2")
+
+	items, next, prev := page.Cursors(ks, ids[:3], key)
+	if diff := cmp.Diff(items, ids[:2]); diff != "" {
+		t.Fatalf("Should get the first page: %s", diff)
+	}
+	if next == "" || prev != "" {
+		t.Fatalf("Should get only a next cursor on the first page: next[%s] prev[%s]", next, prev)
+	}
+
+	// Second page, following the next cursor.
+	ks = page.MustParseKeyset(next, "2")
+
+	data := map[string]any{}
+	buf := bytes.NewBufferString("SELECT id FROM t")
+	ks.Apply("id", data, buf)
+
+	exp := "SELECT id FROM t WHERE id > :keyset_id ORDER BY id ASC FETCH FIRST :keyset_rows ROWS ONLY"
+	if buf.String() != exp {
+		t.Fatalf("Should get the forward query:\ngot: %s\nexp: %s", buf.String(), exp)
+	}
+	if data["keyset_id"] != ids[1] || data["keyset_rows"] != 3 {
+		t.Fatalf("Should get the keyset data: %v", data)
+	}
+
+	items, next, prev = page.Cursors(ks, ids[2:], key)
+	if diff := cmp.Diff(items, ids[2:4]); diff != "" {
+		t.Fatalf("Should get the second page: %s", diff)
+	}
+	if next == "" || prev == "" {
+		t.Fatalf("Should get both cursors on the second page: next[%s] prev[%s]", next, prev)
+	}
+
+	// Back to the first page, following the prev cursor. Rows are fetched in
+	// descending order.
+	ks = page.MustParseKeyset(prev, "2")
+
+	data = map[string]any{}
+	buf = bytes.NewBufferString("SELECT id FROM t WHERE x = :x")
+	ks.Apply("id", data, buf)
+
+	exp = "SELECT id FROM t WHERE x = :x AND id < :keyset_id ORDER BY id DESC FETCH FIRST :keyset_rows ROWS ONLY"
+	if buf.String() != exp {
+		t.Fatalf("Should get the backward query:\ngot: %s\nexp: %s", buf.String(), exp)
+	}
+
+	items, next, prev = page.Cursors(ks, []string{ids[1], ids[0]}, key)
+	if diff := cmp.Diff(items, ids[:2]); diff != "" {
+		t.Fatalf("Should get the first page in order: %s", diff)
+	}
+	if next == "" || prev != "" {
+		t.Fatalf("Should get only a next cursor back on the first page: next[%s] prev[%s]", next, prev)
+	}
+}
+
+func Test_ParseKeyset(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+		limit  string
+	}{
+		{"bad-limit", "", "abc"},
+		{"zero-limit", "", "0"},
+		{"large-limit", "", "101"},
+		{"bad-encoding", "!!!", "10"},
+		{"bad-form", "eDp5", "10"},
+		{"bad-id", base64.RawURLEncoding.EncodeToString([]byte("n:x")), "10"},
+	}
+
+	for _, tt := range tests {
+		if _, err := page.ParseKeyset(tt.cursor, tt.limit); err == nil {
+			t.Fatalf("%s: Should get an error parsing the keyset", tt.name)
+		}
+	}
+}
@@ -2,11 +2,77 @@
 package pubsub
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+
 	"encore.dev/pubsub"
 	"github.com/ardanlabs/encore/business/sdk/delegate"
+	"github.com/google/uuid"
 )
 
 // Delegate represents a topic for handling delegate calls.
 var Delegate = pubsub.NewTopic[delegate.Data]("delegate", pubsub.TopicConfig{
 	DeliveryGuarantee: pubsub.AtLeastOnce,
 })
+
+// =============================================================================
+
+// JobData represents the message that hands a job off to a worker.
+type JobData struct {
+	JobID uuid.UUID
+}
+
+// Jobs represents a topic for running background jobs.
+var Jobs = pubsub.NewTopic[JobData]("jobs", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.AtLeastOnce,
+})
+
+// JobPublisher publishes jobs to the Jobs topic.
+type JobPublisher struct{}
+
+// Publish hands the specified job off to a worker.
+func (JobPublisher) Publish(ctx context.Context, jobID uuid.UUID) error {
+	if _, err := Jobs.Publish(ctx, JobData{JobID: jobID}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// AuditData represents the message that records a change made through the
+// API in the audit log.
+type AuditData struct {
+	ActorID  uuid.UUID
+	Action   string
+	Endpoint string
+	EntityID string
+	Payload  []byte
+}
+
+// Audits represents a topic for recording changes in the audit log without
+// slowing down the request that made them.
+var Audits = pubsub.NewTopic[AuditData]("audits", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.AtLeastOnce,
+})
+
+// =============================================================================
+
+// Replay returns a function that decodes a JSON encoded message and publishes
+// it to the specified topic. This is used to replay dead letters.
+func Replay[T any](topic *pubsub.Topic[T]) func(ctx context.Context, payload []byte) error {
+	return func(ctx context.Context, payload []byte) error {
+		var msg T
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			return fmt.Errorf("unmarshal: %w", err)
+		}
+
+		if _, err := topic.Publish(ctx, msg); err != nil {
+			return fmt.Errorf("publish: %w", err)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,45 @@
+package pubsub_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ardanlabs/encore/business/sdk/pubsub"
+	"github.com/ardanlabs/encore/business/sdk/pubsubtest"
+	"github.com/google/uuid"
+)
+
+// These tests need the encore runtime for the topics, like:
+//
+//	$ encore test ./business/sdk/pubsub
+
+func Test_JobPublisher(t *testing.T) {
+	jobID := uuid.New()
+
+	if err := (pubsub.JobPublisher{}).Publish(context.Background(), jobID); err != nil {
+		t.Fatalf("Should be able to publish the job: %s", err)
+	}
+
+	pubsubtest.Expect(t, pubsub.Jobs, pubsubtest.Job(jobID))
+}
+
+func Test_Replay(t *testing.T) {
+	replay := pubsub.Replay(pubsub.Delegate)
+
+	payload := []byte(`{"Domain":"user","Action":"updated","RawParams":"e30="}`)
+
+	if err := replay(context.Background(), payload); err != nil {
+		t.Fatalf("Should be able to replay the message: %s", err)
+	}
+
+	msg := pubsubtest.Expect(t, pubsub.Delegate, pubsubtest.Delegate("user", "updated"))
+	if string(msg.RawParams) != "{}" {
+		t.Fatalf("Should publish the params of the message, got %s", msg.RawParams)
+	}
+
+	if err := replay(context.Background(), []byte("not json")); err == nil {
+		t.Fatalf("Should not replay a message that can't be decoded")
+	}
+
+	pubsubtest.ExpectNone(t, pubsub.Delegate, pubsubtest.Delegate("user", "deleted"))
+}
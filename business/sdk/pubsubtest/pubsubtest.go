@@ -0,0 +1,72 @@
+// Package pubsubtest provides support for checking the messages published to
+// the topics during a test. Encore keeps the messages published by each test
+// apart, so the tests need to run with encore test.
+package pubsubtest
+
+import (
+	"testing"
+
+	"encore.dev/et"
+	"encore.dev/pubsub"
+	"github.com/ardanlabs/encore/business/sdk/delegate"
+	bpubsub "github.com/ardanlabs/encore/business/sdk/pubsub"
+	"github.com/google/uuid"
+)
+
+// Messages returns the messages published to the topic during the test.
+func Messages[T any](topic *pubsub.Topic[T]) []T {
+	return et.Topic(topic).PublishedMessages()
+}
+
+// Expect fails the test when no message published to the topic during the
+// test matches, and returns the first message that does.
+func Expect[T any](t *testing.T, topic *pubsub.Topic[T], match func(T) bool) T {
+	t.Helper()
+
+	msgs := Messages(topic)
+	for _, msg := range msgs {
+		if match(msg) {
+			return msg
+		}
+	}
+
+	t.Fatalf("Should publish a matching message to %s, got %+v", topic.Meta().Name, msgs)
+
+	var zero T
+	return zero
+}
+
+// ExpectNone fails the test when a message published to the topic during the
+// test matches.
+func ExpectNone[T any](t *testing.T, topic *pubsub.Topic[T], match func(T) bool) {
+	t.Helper()
+
+	for _, msg := range Messages(topic) {
+		if match(msg) {
+			t.Fatalf("Should not publish a matching message to %s, got %+v", topic.Meta().Name, msg)
+		}
+	}
+}
+
+// =============================================================================
+
+// Delegate matches the delegate calls for the action in the domain.
+func Delegate(domain string, action string) func(delegate.Data) bool {
+	return func(data delegate.Data) bool {
+		return data.Domain == domain && data.Action == action
+	}
+}
+
+// Job matches the hand off of the job to a worker.
+func Job(jobID uuid.UUID) func(bpubsub.JobData) bool {
+	return func(data bpubsub.JobData) bool {
+		return data.JobID == jobID
+	}
+}
+
+// Audit matches the audit entry for the action on the entity.
+func Audit(action string, entityID string) func(bpubsub.AuditData) bool {
+	return func(data bpubsub.AuditData) bool {
+		return data.Action == action && data.EntityID == entityID
+	}
+}
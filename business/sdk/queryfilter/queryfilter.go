@@ -0,0 +1,146 @@
+// Package queryfilter provides support for parsing query string values into
+// the values the business layer filters use. Every invalid value is reported
+// together as field errors, so a client can fix a query in one pass.
+package queryfilter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/encore/business/sdk/where"
+	"github.com/google/uuid"
+)
+
+// Func represents a function that parses a query string value.
+type Func[T any] func(value string) (T, error)
+
+// FieldError is a query string value that couldn't be parsed.
+type FieldError struct {
+	Field string `json:"field"`
+	Err   string `json:"error"`
+}
+
+// FieldErrors represents every query string value that couldn't be parsed.
+type FieldErrors []FieldError
+
+// Error implements the error interface.
+func (fe FieldErrors) Error() string {
+	d, err := json.Marshal(fe)
+	if err != nil {
+		return err.Error()
+	}
+	return string(d)
+}
+
+// =============================================================================
+
+// Parser collects the errors for the values it parses.
+type Parser struct {
+	fieldErrs FieldErrors
+}
+
+// New constructs a parser for the values of a query.
+func New() *Parser {
+	return &Parser{}
+}
+
+// Value parses the value for the field. An empty value wasn't provided and
+// nil is returned, as it is when the value is invalid.
+func Value[T any](p *Parser, field string, value string, fn Func[T]) *T {
+	if value == "" {
+		return nil
+	}
+
+	v, err := fn(value)
+	if err != nil {
+		p.add(field, err)
+		return nil
+	}
+
+	return &v
+}
+
+// Where parses the values for the operators of a field into conditions.
+func Where[T any](p *Parser, field string, values map[where.Op]string, fn Func[T]) []where.Cond[T] {
+	conds, err := where.Parse(values, fn)
+	if err != nil {
+		p.add(field, err)
+		return nil
+	}
+
+	return conds
+}
+
+// Err returns the FieldErrors for every field that couldn't be parsed, or
+// nil if all the values were parsed.
+func (p *Parser) Err() error {
+	if len(p.fieldErrs) == 0 {
+		return nil
+	}
+
+	return p.fieldErrs
+}
+
+func (p *Parser) add(field string, err error) {
+	p.fieldErrs = append(p.fieldErrs, FieldError{
+		Field: field,
+		Err:   err.Error(),
+	})
+}
+
+// =============================================================================
+
+// UUID parses an id.
+func UUID(value string) (uuid.UUID, error) {
+	return uuid.Parse(value)
+}
+
+// Time parses a date in the RFC3339 format.
+func Time(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339, value)
+}
+
+// Enum returns a parser that accepts the name of one of the values.
+func Enum[T fmt.Stringer](values ...T) Func[T] {
+	return func(value string) (T, error) {
+		names := make([]string, len(values))
+		for i, v := range values {
+			if v.String() == value {
+				return v, nil
+			}
+			names[i] = v.String()
+		}
+
+		var zero T
+		return zero, fmt.Errorf("%q is not one of %s", value, strings.Join(names, ", "))
+	}
+}
+
+// Email parses an email address.
+func Email(value string) (mail.Address, error) {
+	addr, err := mail.ParseAddress(value)
+	if err != nil {
+		return mail.Address{}, err
+	}
+
+	return *addr, nil
+}
+
+// String accepts any value.
+func String(value string) (string, error) {
+	return value, nil
+}
+
+// Int parses a whole number.
+func Int(value string) (int, error) {
+	return strconv.Atoi(value)
+}
+
+// Float parses a number.
+func Float(value string) (float64, error) {
+	return strconv.ParseFloat(value, 64)
+}
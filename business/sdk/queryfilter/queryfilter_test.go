@@ -0,0 +1,73 @@
+package queryfilter_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ardanlabs/encore/business/domain/homebus"
+	"github.com/ardanlabs/encore/business/sdk/queryfilter"
+	"github.com/ardanlabs/encore/business/sdk/where"
+)
+
+func Test_Parser(t *testing.T) {
+	p := queryfilter.New()
+
+	id := queryfilter.Value(p, "user_id", "5cf37266-3473-4006-984f-9325122678b7", queryfilter.UUID)
+	empty := queryfilter.Value(p, "name", "", queryfilter.String)
+	qty := queryfilter.Where(p, "quantity", map[where.Op]string{where.GT: "10"}, queryfilter.Int)
+
+	if err := p.Err(); err != nil {
+		t.Fatalf("Should be able to parse the values: %v", err)
+	}
+
+	if id == nil || id.String() != "5cf37266-3473-4006-984f-9325122678b7" {
+		t.Fatalf("Should get the id: %v", id)
+	}
+
+	if empty != nil {
+		t.Fatalf("Should get nil for an empty value: %v", *empty)
+	}
+
+	if len(qty) != 1 || qty[0].Op != where.GT || len(qty[0].Values) != 1 || qty[0].Values[0] != 10 {
+		t.Fatalf("Should get the quantity condition: %+v", qty)
+	}
+}
+
+func Test_ParserErrors(t *testing.T) {
+	p := queryfilter.New()
+
+	queryfilter.Value(p, "user_id", "abc", queryfilter.UUID)
+	queryfilter.Value(p, "start_created_date", "yesterday", queryfilter.Time)
+	queryfilter.Value(p, "email", "bill@example.com", queryfilter.Email)
+
+	var fe queryfilter.FieldErrors
+	if !errors.As(p.Err(), &fe) {
+		t.Fatalf("Should get the field errors")
+	}
+
+	exp := queryfilter.FieldErrors{
+		{Field: "user_id", Err: "invalid UUID length: 3"},
+		{Field: "start_created_date", Err: `parsing time "yesterday" as "2006-01-02T15:04:05Z07:00": cannot parse "yesterday" as "2006"`},
+	}
+
+	if fe.Error() != exp.Error() {
+		t.Fatalf("Should get every field that failed:\nexp: %s\ngot: %s", exp.Error(), fe.Error())
+	}
+}
+
+func Test_Enum(t *testing.T) {
+	fn := queryfilter.Enum(homebus.Types.Single, homebus.Types.Condo)
+
+	typ, err := fn("CONDO")
+	if err != nil {
+		t.Fatalf("Should be able to parse a value in the set: %s", err)
+	}
+
+	if typ != homebus.Types.Condo {
+		t.Fatalf("Should get the matching value: got %s", typ)
+	}
+
+	if _, err := fn("TENT"); err == nil || err.Error() != `"TENT" is not one of SINGLE FAMILY, CONDO` {
+		t.Fatalf("Should get the accepted values for a value not in the set: got %v", err)
+	}
+}
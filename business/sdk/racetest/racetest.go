@@ -0,0 +1,87 @@
+// Package racetest provides support for hammering a store with concurrent
+// calls, so the race detector sees the locking of caches and other stores
+// that share state between requests. Run the tests with -race.
+package racetest
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// Op represents an operation the workers choose from. The worker number is
+// passed so an operation can keep to the keys that belong to the worker when
+// it checks an invariant that another worker could break. The random source
+// is owned by the worker.
+type Op struct {
+	Name   string
+	Weight int
+	Run    func(ctx context.Context, worker int, rnd *rand.Rand) error
+}
+
+// Config represents how hard to hammer the store.
+type Config struct {
+	Workers    int
+	Iterations int
+	Seed       int64
+}
+
+// Run starts the workers, which each run the operations chosen at random by
+// their weight for the number of iterations, and waits for them to finish.
+// The test fails for every operation that returns an error.
+func Run(t testing.TB, cfg Config, ops ...Op) {
+	t.Helper()
+
+	if cfg.Workers <= 0 {
+		cfg.Workers = 8
+	}
+
+	if cfg.Iterations <= 0 {
+		cfg.Iterations = 500
+	}
+
+	var total int
+	for _, op := range ops {
+		total += max(op.Weight, 1)
+	}
+
+	if total == 0 {
+		t.Fatalf("Should be given operations to run")
+	}
+
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	wg.Add(cfg.Workers)
+
+	for worker := range cfg.Workers {
+		go func() {
+			defer wg.Done()
+
+			rnd := rand.New(rand.NewSource(cfg.Seed + int64(worker)))
+
+			for i := range cfg.Iterations {
+				op := choose(ops, rnd.Intn(total))
+
+				if err := op.Run(ctx, worker, rnd); err != nil {
+					t.Errorf("Should be able to run %s on worker %d iteration %d: %s", op.Name, worker, i, err)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func choose(ops []Op, roll int) Op {
+	for _, op := range ops {
+		roll -= max(op.Weight, 1)
+		if roll < 0 {
+			return op
+		}
+	}
+
+	return ops[len(ops)-1]
+}
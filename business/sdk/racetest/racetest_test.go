@@ -0,0 +1,43 @@
+package racetest_test
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/ardanlabs/encore/business/sdk/racetest"
+)
+
+func Test_Run(t *testing.T) {
+	var mu sync.Mutex
+	counts := make(map[string]int)
+
+	op := func(name string) func(ctx context.Context, worker int, rnd *rand.Rand) error {
+		return func(ctx context.Context, worker int, rnd *rand.Rand) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			counts[name]++
+			return nil
+		}
+	}
+
+	cfg := racetest.Config{
+		Workers:    4,
+		Iterations: 100,
+	}
+
+	racetest.Run(t, cfg,
+		racetest.Op{Name: "read", Weight: 3, Run: op("read")},
+		racetest.Op{Name: "write", Weight: 1, Run: op("write")},
+	)
+
+	if total := counts["read"] + counts["write"]; total != 400 {
+		t.Fatalf("Should run every iteration on every worker, got %d", total)
+	}
+
+	if counts["read"] <= counts["write"] {
+		t.Fatalf("Should choose the operations by their weight, got %v", counts)
+	}
+}
@@ -6,14 +6,17 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"iter"
 	"strings"
 	"time"
 
 	edb "encore.dev/storage/sqldb"
 	"github.com/ardanlabs/encore/foundation/logger"
+	"github.com/ardanlabs/encore/foundation/otel"
 	"github.com/jackc/pgx/v5/pgconn"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // lib/pq errorCodeNames
@@ -28,6 +31,7 @@ var (
 	ErrDBNotFound        = sql.ErrNoRows
 	ErrDBDuplicatedEntry = errors.New("duplicated entry")
 	ErrUndefinedTable    = errors.New("undefined table")
+	ErrNoRequestContext  = errors.New("query made with a context that can't be cancelled")
 )
 
 // Config is the required properties to use the database.
@@ -101,15 +105,24 @@ func ExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, qu
 // NamedExecContext is a helper function to execute a CUD operation with
 // logging and tracing where field replacement is necessary.
 func NamedExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any) (err error) {
+	if err := CheckContext(ctx); err != nil {
+		return err
+	}
+
 	q := queryString(query, data)
 
+	ctx, span := otel.AddSpan(ctx, "business.sdk.sqldb.exec", attribute.String("db.operation", operation(query)))
+	defer span.End()
+
 	defer func() {
 		if err != nil {
+			otel.RecordError(span, err)
 			log.Info(ctx, "database.NamedExecContext", "query", q, "ERROR", err)
 		}
 	}()
 
-	if _, err := sqlx.NamedExecContext(ctx, db, query, data); err != nil {
+	res, err := sqlx.NamedExecContext(ctx, db, query, data)
+	if err != nil {
 		var pqerr *pgconn.PgError
 		if errors.As(err, &pqerr) {
 			switch pqerr.Code {
@@ -122,6 +135,10 @@ func NamedExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContex
 		return err
 	}
 
+	if n, err := res.RowsAffected(); err == nil {
+		span.SetAttributes(attribute.Int64("db.rows", n))
+	}
+
 	return nil
 }
 
@@ -146,10 +163,18 @@ func NamedQuerySliceUsingIn[T any](ctx context.Context, log *logger.Logger, db s
 }
 
 func namedQuerySlice[T any](ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any, dest *[]T, withIn bool) (err error) {
+	if err := CheckContext(ctx); err != nil {
+		return err
+	}
+
 	q := queryString(query, data)
 
+	ctx, span := otel.AddSpan(ctx, "business.sdk.sqldb.queryslice", attribute.String("db.operation", operation(query)))
+	defer span.End()
+
 	defer func() {
 		if err != nil {
+			otel.RecordError(span, err)
 			log.Info(ctx, "database.NamedQuerySlice", "query", q, "ERROR", err)
 		}
 	}()
@@ -196,9 +221,68 @@ func namedQuerySlice[T any](ctx context.Context, log *logger.Logger, db sqlx.Ext
 	}
 	*dest = slice
 
+	span.SetAttributes(attribute.Int("db.rows", len(slice)))
+
 	return nil
 }
 
+// NamedQueryIter is a helper function for executing queries that return a
+// collection of data that is read one row at a time where field replacement
+// is necessary. The query runs when the sequence is ranged over and the rows
+// are closed when the range ends, so a large result is never held in memory.
+// An error ends the sequence.
+func NamedQueryIter[T any](ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		if err := CheckContext(ctx); err != nil {
+			yield(zero, err)
+			return
+		}
+
+		ctx, span := otel.AddSpan(ctx, "business.sdk.sqldb.queryiter", attribute.String("db.operation", operation(query)))
+		defer span.End()
+
+		rows, err := sqlx.NamedQueryContext(ctx, db, query, data)
+		if err != nil {
+			otel.RecordError(span, err)
+			log.Info(ctx, "database.NamedQueryIter", "query", queryString(query, data), "ERROR", err)
+
+			var pqerr *pgconn.PgError
+			if errors.As(err, &pqerr) && pqerr.Code == undefinedTable {
+				err = ErrUndefinedTable
+			}
+			yield(zero, err)
+			return
+		}
+		defer rows.Close()
+
+		// The rows read are counted when the range ends, which can be
+		// before the last row.
+		var n int
+		defer func() {
+			span.SetAttributes(attribute.Int("db.rows", n))
+		}()
+
+		for rows.Next() {
+			v := new(T)
+			if err := rows.StructScan(v); err != nil {
+				yield(zero, err)
+				return
+			}
+			n++
+
+			if !yield(*v, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
+
 // QueryStruct is a helper function for executing queries that return a
 // single value to be unmarshalled into a struct type where field replacement is necessary.
 func QueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, dest any) error {
@@ -219,10 +303,21 @@ func NamedQueryStructUsingIn(ctx context.Context, log *logger.Logger, db sqlx.Ex
 }
 
 func namedQueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any, dest any, withIn bool) (err error) {
+	if err := CheckContext(ctx); err != nil {
+		return err
+	}
+
 	q := queryString(query, data)
 
+	ctx, span := otel.AddSpan(ctx, "business.sdk.sqldb.querystruct", attribute.String("db.operation", operation(query)))
+	defer span.End()
+
 	defer func() {
 		if err != nil {
+			// Not finding a row is an answer, not a failure of the query.
+			if !errors.Is(err, ErrDBNotFound) {
+				otel.RecordError(span, err)
+			}
 			log.Info(ctx, "database.NamedQuerySlice", "query", q, "ERROR", err)
 		}
 	}()
@@ -251,15 +346,19 @@ func namedQueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContex
 	}
 
 	if err != nil {
-		var pqerr *pgconn.PgError
-		if errors.As(err, &pqerr) && pqerr.Code == undefinedTable {
-			return ErrUndefinedTable
-		}
-		return err
+		return queryError(err)
 	}
 	defer rows.Close()
 
 	if !rows.Next() {
+
+		// A statement that changes data, like an UPDATE with a RETURNING
+		// clause, reports a failure once the rows are read.
+		if err := rows.Err(); err != nil {
+			return queryError(err)
+		}
+
+		span.SetAttributes(attribute.Int("db.rows", 0))
 		return ErrDBNotFound
 	}
 
@@ -267,9 +366,50 @@ func namedQueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContex
 		return err
 	}
 
+	span.SetAttributes(attribute.Int("db.rows", 1))
+
+	return nil
+}
+
+// queryError converts the postgres errors callers act on into the
+// package errors.
+func queryError(err error) error {
+	var pqerr *pgconn.PgError
+	if errors.As(err, &pqerr) {
+		switch pqerr.Code {
+		case undefinedTable:
+			return ErrUndefinedTable
+		case uniqueViolation:
+			return ErrDBDuplicatedEntry
+		}
+	}
+
+	return err
+}
+
+// CheckContext makes sure a query runs under a context that can be cancelled,
+// which is one derived from the request. A query made with the background
+// context would ignore the deadline of the request and keep a connection
+// after the client is gone.
+func CheckContext(ctx context.Context) error {
+	if ctx.Done() == nil {
+		return ErrNoRequestContext
+	}
+
 	return nil
 }
 
+// operation returns the SQL operation of the query, like SELECT or INSERT,
+// for the span attributes.
+func operation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	return strings.ToUpper(fields[0])
+}
+
 // queryString provides a pretty print version of the query and parameters.
 func queryString(query string, args any) string {
 	query, params, err := sqlx.Named(query, args)
@@ -1,6 +1,7 @@
 package sqldb
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/jmoiron/sqlx"
@@ -8,7 +9,7 @@ import (
 
 // Beginner represents a value that can begin a transaction.
 type Beginner interface {
-	Begin() (CommitRollbacker, error)
+	Begin(ctx context.Context) (CommitRollbacker, error)
 }
 
 // CommitRollbacker represents a value that can commit or rollback a transaction.
@@ -32,9 +33,10 @@ func NewBeginner(sqlxDB *sqlx.DB) *DBBeginner {
 }
 
 // Begin implements the Beginner interface and returns a concrete value that
-// implements the CommitRollbacker interface.
-func (db *DBBeginner) Begin() (CommitRollbacker, error) {
-	return db.sqlxDB.Beginx()
+// implements the CommitRollbacker interface. The transaction is rolled back
+// if the context is cancelled before it's committed.
+func (db *DBBeginner) Begin(ctx context.Context) (CommitRollbacker, error) {
+	return db.sqlxDB.BeginTxx(ctx, nil)
 }
 
 // GetExtContext is a helper function that extracts the sqlx value
@@ -0,0 +1,238 @@
+// Package storecache provides a read-through cache for the stores of the
+// business layer. A domain wraps its store with a cache that serves the
+// queries for a single entity and keeps the cache current on writes.
+package storecache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/ardanlabs/encore/business/sdk/cachemetrics"
+	"github.com/ardanlabs/encore/foundation/clock"
+	"github.com/viccon/sturdyc"
+)
+
+// refreshTimeout bounds a refresh the cache runs in the background.
+const refreshTimeout = 10 * time.Second
+
+// Config represents the settings of a cache. The TTL is required and the
+// capacity and number of shards default to 10000 and 10.
+//
+// An entry a request asks for again once it's older than a random time
+// between RefreshMin and RefreshMax is refreshed from the store in the
+// background, so the entities in active rotation don't expire and a burst
+// of requests for them never reaches the database. The refresh window has
+// to end before the TTL, and leaving RefreshMax at zero turns it off.
+//
+// A key the store doesn't have a value for is remembered as missing for the
+// MissingTTL, so repeated lookups of it, like a login probing for emails, are
+// answered without the database. Caching a value under the key clears it, but
+// only on this instance, so the TTL should be short. Leaving MissingTTL at
+// zero turns it off.
+type Config struct {
+	TTL        time.Duration
+	Capacity   int
+	Shards     int
+	RefreshMin time.Duration
+	RefreshMax time.Duration
+	MissingTTL time.Duration
+}
+
+// Entity represents how the values of an entity are cached. The ID is the
+// key the value is counted by in the stats, and the value is also cached
+// under the other keys, like a unique email. The not found error is the one
+// the store returns for a value it doesn't have.
+type Entity[T any] struct {
+	Name     string
+	NotFound error
+	ID       func(T) string
+	Keys     []func(T) string
+}
+
+// Cache represents a cache of the values of an entity.
+type Cache[T any] struct {
+	entity  Entity[T]
+	cache   *sturdyc.Client[T]
+	missing *sturdyc.Client[struct{}]
+	stats   *cachemetrics.Recorder
+}
+
+// New constructs a cache for the entity. The entries expire by the time of
+// the clock. The hits, misses and evictions are reported to the counters,
+// which can be nil.
+//
+// The requests for a value that isn't cached share a single call to the
+// store, so a hot key that expires doesn't send a storm of queries to the
+// database.
+func New[T any](entity Entity[T], cfg Config, clock clock.Clock, counters cachemetrics.Counters) *Cache[T] {
+	const evictionPercentage = 10
+	const retryBaseDelay = time.Second
+
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = 10000
+	}
+
+	if cfg.Shards <= 0 {
+		cfg.Shards = 10
+	}
+
+	stats := cachemetrics.New(entity.Name, counters)
+
+	opts := []sturdyc.Option{
+		sturdyc.WithClock(cacheClock{clock}),
+		sturdyc.WithMetrics(stats),
+	}
+
+	if cfg.RefreshMax > 0 {
+		opts = append(opts, sturdyc.WithEarlyRefreshes(cfg.RefreshMin, cfg.RefreshMax, cfg.TTL, retryBaseDelay))
+	}
+
+	var missing *sturdyc.Client[struct{}]
+	if cfg.MissingTTL > 0 {
+		missing = sturdyc.New[struct{}](cfg.Capacity, cfg.Shards, cfg.MissingTTL, evictionPercentage, sturdyc.WithClock(cacheClock{clock}))
+	}
+
+	return &Cache[T]{
+		entity:  entity,
+		cache:   sturdyc.New[T](cfg.Capacity, cfg.Shards, cfg.TTL, evictionPercentage, opts...),
+		missing: missing,
+		stats:   stats,
+	}
+}
+
+// Get returns the value cached under the key and calls the query when the
+// value isn't cached or is due for a refresh. The value is cached under all
+// of its keys. A value the store no longer has is removed from the cache
+// when a refresh finds it missing.
+func (c *Cache[T]) Get(ctx context.Context, key string, query func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+
+	if c.isMissing(key) {
+		c.stats.MissingRecord()
+		return zero, c.entity.NotFound
+	}
+
+	// A refresh runs the fetch in the background after the cached value is
+	// returned.
+	var queried atomic.Bool
+
+	fetch := func(ctx context.Context) (T, error) {
+		queried.Store(true)
+
+		// The cache runs a refresh with the background context, which the
+		// database won't run a query with, so it's bounded here.
+		if ctx.Done() == nil {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, refreshTimeout)
+			defer cancel()
+		}
+
+		v, err := query(ctx)
+		if err != nil {
+			if errors.Is(err, c.entity.NotFound) {
+				return zero, sturdyc.ErrNotFound
+			}
+			return zero, err
+		}
+
+		c.Set(v)
+
+		return v, nil
+	}
+
+	v, err := c.cache.GetOrFetch(ctx, key, fetch)
+	switch {
+	case errors.Is(err, sturdyc.ErrNotFound):
+		c.setMissing(key)
+		return zero, c.entity.NotFound
+
+	case errors.Is(err, sturdyc.ErrOnlyCachedRecords):
+		// The refresh failed, so the cached value is served until it expires.
+
+	case err != nil:
+		return zero, err
+	}
+
+	if !queried.Load() {
+		c.stats.Touch(c.entity.ID(v))
+	}
+
+	return v, nil
+}
+
+// Set caches the value under all of its keys, which are no longer missing.
+func (c *Cache[T]) Set(v T) {
+	id := c.entity.ID(v)
+
+	c.cache.Set(id, v)
+	c.clearMissing(id)
+
+	for _, key := range c.entity.Keys {
+		c.cache.Set(key(v), v)
+		c.clearMissing(key(v))
+	}
+}
+
+// Delete removes the value from the cache under all of its keys.
+func (c *Cache[T]) Delete(v T) {
+	id := c.entity.ID(v)
+
+	c.cache.Delete(id)
+	for _, key := range c.entity.Keys {
+		c.cache.Delete(key(v))
+	}
+
+	c.stats.Forget(id)
+}
+
+// Stats returns what the cache has done with the top number of values it
+// has served the most, keyed by their id.
+func (c *Cache[T]) Stats(top int) cachemetrics.Stats {
+	return c.stats.Stats(top, c.cache.ScanKeys())
+}
+
+func (c *Cache[T]) isMissing(key string) bool {
+	if c.missing == nil {
+		return false
+	}
+
+	_, missing := c.missing.Get(key)
+	return missing
+}
+
+func (c *Cache[T]) setMissing(key string) {
+	if c.missing != nil {
+		c.missing.Set(key, struct{}{})
+	}
+}
+
+func (c *Cache[T]) clearMissing(key string) {
+	if c.missing != nil {
+		c.missing.Delete(key)
+	}
+}
+
+// =============================================================================
+
+// cacheClock lets the cache read the time from a clock, so the entries
+// expire when a test advances the clock. The tickers and timers the cache
+// evicts entries with still run on the system time.
+type cacheClock struct {
+	clock.Clock
+}
+
+func (cc cacheClock) NewTicker(d time.Duration) (<-chan time.Time, func()) {
+	t := time.NewTicker(d)
+	return t.C, t.Stop
+}
+
+func (cc cacheClock) NewTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	t := time.NewTimer(d)
+	return t.C, t.Stop
+}
+
+func (cc cacheClock) Since(t time.Time) time.Duration {
+	return cc.Now().Sub(t)
+}
@@ -0,0 +1,211 @@
+package storecache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/encore/business/sdk/sqldb"
+	"github.com/ardanlabs/encore/business/sdk/storecache"
+	"github.com/ardanlabs/encore/foundation/clock"
+)
+
+var errNotFound = errors.New("thing not found")
+
+type thing struct {
+	ID   string
+	Name string
+}
+
+func Test_Cache(t *testing.T) {
+	ctx := context.Background()
+
+	entity := storecache.Entity[thing]{
+		Name:     "things",
+		NotFound: errNotFound,
+		ID:       func(th thing) string { return th.ID },
+		Keys:     []func(thing) string{func(th thing) string { return "name:" + th.Name }},
+	}
+
+	cache := storecache.New(entity, storecache.Config{TTL: time.Hour}, clock.System{}, nil)
+
+	store := map[string]thing{"1": {ID: "1", Name: "one"}}
+	var queries int
+
+	query := func(id string) func(ctx context.Context) (thing, error) {
+		return func(ctx context.Context) (thing, error) {
+			queries++
+
+			th, exists := store[id]
+			if !exists {
+				return thing{}, errNotFound
+			}
+
+			return th, nil
+		}
+	}
+
+	for range 2 {
+		th, err := cache.Get(ctx, "1", query("1"))
+		if err != nil {
+			t.Fatalf("Should be able to get the thing: %s", err)
+		}
+
+		if th.Name != "one" {
+			t.Fatalf("Should get the thing, got %+v", th)
+		}
+	}
+
+	if queries != 1 {
+		t.Fatalf("Should query the store once, got %d queries", queries)
+	}
+
+	if _, err := cache.Get(ctx, "name:one", query("none")); err != nil || queries != 1 {
+		t.Fatalf("Should get the thing by its other key from the cache, got %v with %d queries", err, queries)
+	}
+
+	if _, err := cache.Get(ctx, "2", query("2")); !errors.Is(err, errNotFound) {
+		t.Fatalf("Should get the not found error of the entity, got %v", err)
+	}
+
+	failed := errors.New("database is down")
+	if _, err := cache.Get(ctx, "3", func(ctx context.Context) (thing, error) { return thing{}, failed }); !errors.Is(err, failed) {
+		t.Fatalf("Should get the error of the store, got %v", err)
+	}
+
+	cache.Delete(thing{ID: "1", Name: "one"})
+
+	if stats := cache.Stats(10); stats.Size != 0 || len(stats.Hottest) != 0 {
+		t.Fatalf("Should remove the thing under all of its keys, got %+v", stats)
+	}
+}
+
+func Test_Missing(t *testing.T) {
+	ctx := context.Background()
+
+	entity := storecache.Entity[thing]{
+		Name:     "things",
+		NotFound: errNotFound,
+		ID:       func(th thing) string { return th.ID },
+		Keys:     []func(thing) string{func(th thing) string { return "name:" + th.Name }},
+	}
+
+	clk := clock.NewFrozen(time.Now())
+
+	cfg := storecache.Config{
+		TTL:        time.Hour,
+		MissingTTL: 10 * time.Second,
+	}
+
+	cache := storecache.New(entity, cfg, clk, nil)
+
+	var queries int
+	query := func(ctx context.Context) (thing, error) {
+		queries++
+		return thing{}, errNotFound
+	}
+
+	for range 3 {
+		if _, err := cache.Get(ctx, "name:two", query); !errors.Is(err, errNotFound) {
+			t.Fatalf("Should get the not found error of the entity, got %v", err)
+		}
+	}
+
+	if queries != 1 {
+		t.Fatalf("Should remember the key is missing after the first query, got %d queries", queries)
+	}
+
+	if stats := cache.Stats(10); stats.Missing != 2 {
+		t.Fatalf("Should report 2 lookups answered as missing, got %d", stats.Missing)
+	}
+
+	// Caching a value under the key, like when it's created, clears it.
+	cache.Set(thing{ID: "2", Name: "two"})
+
+	if th, err := cache.Get(ctx, "name:two", query); err != nil || th.ID != "2" {
+		t.Fatalf("Should get the thing once it's cached, got %+v %v", th, err)
+	}
+
+	if _, err := cache.Get(ctx, "name:three", query); !errors.Is(err, errNotFound) {
+		t.Fatalf("Should get the not found error of the entity, got %v", err)
+	}
+
+	clk.Advance(time.Minute)
+
+	if _, err := cache.Get(ctx, "name:three", query); !errors.Is(err, errNotFound) || queries != 3 {
+		t.Fatalf("Should query the store again once the key expires as missing, got %v with %d queries", err, queries)
+	}
+}
+
+func Test_Refresh(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	entity := storecache.Entity[thing]{
+		Name:     "things",
+		NotFound: errNotFound,
+		ID:       func(th thing) string { return th.ID },
+	}
+
+	clk := clock.NewFrozen(time.Now())
+
+	cfg := storecache.Config{
+		TTL:        time.Hour,
+		RefreshMin: time.Minute,
+		RefreshMax: 2 * time.Minute,
+	}
+
+	cache := storecache.New(entity, cfg, clk, nil)
+
+	// The query checks the context like the database stores do, which
+	// rejects the background context the cache refreshes with.
+	refreshed := make(chan error, 1)
+	query := func(name string) func(ctx context.Context) (thing, error) {
+		return func(ctx context.Context) (thing, error) {
+			err := sqldb.CheckContext(ctx)
+			if name == "two" {
+				refreshed <- err
+			}
+
+			if err != nil {
+				return thing{}, err
+			}
+
+			return thing{ID: "1", Name: name}, nil
+		}
+	}
+
+	if _, err := cache.Get(ctx, "1", query("one")); err != nil {
+		t.Fatalf("Should be able to get the thing: %s", err)
+	}
+
+	clk.Advance(5 * time.Minute)
+
+	if th, err := cache.Get(ctx, "1", query("two")); err != nil || th.Name != "one" {
+		t.Fatalf("Should get the cached thing while it's refreshed, got %+v %v", th, err)
+	}
+
+	select {
+	case err := <-refreshed:
+		if err != nil {
+			t.Fatalf("Should refresh with a context the store accepts: %s", err)
+		}
+
+	case <-ctx.Done():
+		t.Fatal("Should refresh the thing in the background")
+	}
+
+	for {
+		th, err := cache.Get(ctx, "1", query("three"))
+		if err != nil {
+			t.Fatalf("Should be able to get the thing: %s", err)
+		}
+
+		if th.Name == "two" {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -4,13 +4,17 @@ package unitest
 import (
 	"context"
 	"testing"
+	"time"
 )
 
 // Run performs the actual test logic based on the table data.
 func Run(t *testing.T, table []Table, testName string) {
 	for _, tt := range table {
 		f := func(t *testing.T) {
-			gotResp := tt.ExcFunc(context.Background())
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			gotResp := tt.ExcFunc(ctx)
 
 			diff := tt.CmpFunc(gotResp, tt.ExpResp)
 			if diff != "" {
@@ -0,0 +1,148 @@
+// Package where provides support for filtering data using comparison
+// operators like greater than or in.
+package where
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op represents a comparison operator used to filter a field.
+type Op string
+
+// Set of operators for filtering data.
+const (
+	EQ   Op = "eq"
+	GT   Op = "gt"
+	GTE  Op = "gte"
+	LT   Op = "lt"
+	LTE  Op = "lte"
+	IN   Op = "in"
+	LIKE Op = "like"
+)
+
+// ops lists the operators in the order they are applied so the generated
+// queries are stable.
+var ops = []Op{EQ, GT, GTE, LT, LTE, IN, LIKE}
+
+var sqlOps = map[Op]string{
+	EQ:   "=",
+	GT:   ">",
+	GTE:  ">=",
+	LT:   "<",
+	LTE:  "<=",
+	IN:   "IN",
+	LIKE: "LIKE",
+}
+
+// MaxIn is the most values the IN operator accepts, so a single request
+// can't build a query with an unbounded number of parameters.
+const MaxIn = 100
+
+// Cond represents a single comparison against a field. Only the IN operator
+// uses more than one value.
+type Cond[T any] struct {
+	Op     Op
+	Values []T
+}
+
+// Parse constructs the conditions for a field from the raw query values
+// keyed by operator. Empty values are ignored and values for the IN operator
+// are separated by a comma, up to MaxIn of them. The parse function converts
+// a raw value into the field's type.
+func Parse[T any](values map[Op]string, parse func(string) (T, error)) ([]Cond[T], error) {
+	var conds []Cond[T]
+
+	for _, op := range ops {
+		raw, exists := values[op]
+		if !exists || raw == "" {
+			continue
+		}
+
+		parts := []string{raw}
+		if op == IN {
+			parts = strings.Split(raw, ",")
+			if len(parts) > MaxIn {
+				return nil, fmt.Errorf("%s: too many values, must be %d or less", op, MaxIn)
+			}
+		}
+
+		cond := Cond[T]{
+			Op:     op,
+			Values: make([]T, len(parts)),
+		}
+
+		for i, part := range parts {
+			v, err := parse(strings.TrimSpace(part))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+
+			cond.Values[i] = v
+		}
+
+		conds = append(conds, cond)
+	}
+
+	return conds, nil
+}
+
+// Map converts the values of the conditions using the specified function.
+// This is used when the field's type needs converting before it can be
+// stored in the database.
+func Map[T any, U any](conds []Cond[T], fn func(T) U) []Cond[U] {
+	if conds == nil {
+		return nil
+	}
+
+	mapped := make([]Cond[U], len(conds))
+	for i, cond := range conds {
+		values := make([]U, len(cond.Values))
+		for j, v := range cond.Values {
+			values[j] = fn(v)
+		}
+
+		mapped[i] = Cond[U]{
+			Op:     cond.Op,
+			Values: values,
+		}
+	}
+
+	return mapped
+}
+
+// Apply returns the where clauses for the conditions against the specified
+// column and adds the named parameters to the data. The column is expected
+// to be trusted input.
+func Apply[T any](conds []Cond[T], column string, data map[string]any) []string {
+	wc := make([]string, 0, len(conds))
+
+	for _, cond := range conds {
+		sqlOp, exists := sqlOps[cond.Op]
+		if !exists || len(cond.Values) == 0 {
+			continue
+		}
+
+		names := make([]string, len(cond.Values))
+		for i, v := range cond.Values {
+			name := fmt.Sprintf("%s_%s_%d", column, cond.Op, i)
+			names[i] = ":" + name
+
+			switch cond.Op {
+			case LIKE:
+				data[name] = fmt.Sprintf("%%%v%%", v)
+			default:
+				data[name] = v
+			}
+		}
+
+		switch cond.Op {
+		case IN:
+			wc = append(wc, fmt.Sprintf("%s IN (%s)", column, strings.Join(names, ", ")))
+		default:
+			wc = append(wc, fmt.Sprintf("%s %s %s", column, sqlOp, names[0]))
+		}
+	}
+
+	return wc
+}
@@ -0,0 +1,77 @@
+package where_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/encore/business/sdk/where"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Parse(t *testing.T) {
+	conds, err := where.Parse(map[where.Op]string{
+		where.EQ:  "",
+		where.GTE: "10",
+		where.IN:  "1, 2,3",
+	}, strconv.Atoi)
+	if err != nil {
+		t.Fatalf("Should be able to parse the conditions: %s", err)
+	}
+
+	exp := []where.Cond[int]{
+		{Op: where.GTE, Values: []int{10}},
+		{Op: where.IN, Values: []int{1, 2, 3}},
+	}
+
+	if diff := cmp.Diff(conds, exp); diff != "" {
+		t.Fatalf("Should get the conditions in order: %s", diff)
+	}
+
+	if _, err := where.Parse(map[where.Op]string{where.LT: "ten"}, strconv.Atoi); err == nil {
+		t.Fatalf("Should get an error for a value that can't be parsed")
+	}
+
+	in := strings.Repeat("1,", where.MaxIn) + "1"
+	if _, err := where.Parse(map[where.Op]string{where.IN: in}, strconv.Atoi); err == nil {
+		t.Fatalf("Should get an error for too many in values")
+	}
+}
+
+func Test_Apply(t *testing.T) {
+	conds := []where.Cond[int]{
+		{Op: where.GTE, Values: []int{10}},
+		{Op: where.IN, Values: []int{1, 2}},
+	}
+
+	data := map[string]any{}
+	wc := where.Apply(conds, "cost", data)
+
+	expWC := []string{
+		"cost >= :cost_gte_0",
+		"cost IN (:cost_in_0, :cost_in_1)",
+	}
+
+	if diff := cmp.Diff(wc, expWC); diff != "" {
+		t.Fatalf("Should get a where clause for each condition: %s", diff)
+	}
+
+	expData := map[string]any{
+		"cost_gte_0": 10,
+		"cost_in_0":  1,
+		"cost_in_1":  2,
+	}
+
+	if diff := cmp.Diff(data, expData); diff != "" {
+		t.Fatalf("Should get the named parameters: %s", diff)
+	}
+
+	names := where.Map([]where.Cond[string]{{Op: where.LIKE, Values: []string{"bill"}}}, func(s string) string { return s })
+
+	data = map[string]any{}
+	wc = where.Apply(names, "name", data)
+
+	if wc[0] != "name LIKE :name_like_0" || data["name_like_0"] != "%bill%" {
+		t.Fatalf("Should wrap the value for a like condition: %v %v", wc, data)
+	}
+}
@@ -0,0 +1,61 @@
+// Package clock provides support for reading the current time, so code that
+// records when something happened can be tested with a time that doesn't
+// change between calls.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock represents the behavior for reading the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// System reads the current time from the system.
+type System struct{}
+
+// Now returns the current time.
+func (System) Now() time.Time {
+	return time.Now()
+}
+
+// =============================================================================
+
+// Frozen returns the same time until it's changed, for use in tests.
+type Frozen struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+// NewFrozen constructs a clock frozen at the specified time.
+func NewFrozen(now time.Time) *Frozen {
+	return &Frozen{
+		now: now,
+	}
+}
+
+// Now returns the time the clock is frozen at.
+func (f *Frozen) Now() time.Time {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.now
+}
+
+// Set freezes the clock at the specified time.
+func (f *Frozen) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = now
+}
+
+// Advance moves the time the clock is frozen at forward by the duration.
+func (f *Frozen) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+}
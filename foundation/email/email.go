@@ -0,0 +1,112 @@
+// Package email provides support for sending emails. The Sender interface lets
+// the way emails are sent be chosen when the app starts, so development can
+// log them while production hands them to an SMTP server.
+package email
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"github.com/ardanlabs/encore/foundation/logger"
+)
+
+// Message represents an email to send.
+type Message struct {
+	To      mail.Address
+	Subject string
+	Body    string
+}
+
+// Sender represents the behavior of sending an email.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// =============================================================================
+
+// LogSender writes the emails to the log instead of sending them. It's meant
+// for development, where there is nowhere to send them.
+type LogSender struct {
+	log *logger.Logger
+}
+
+// NewLogSender constructs a sender that writes to the log.
+func NewLogSender(log *logger.Logger) *LogSender {
+	return &LogSender{
+		log: log,
+	}
+}
+
+// Send writes the email to the log.
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	s.log.Info(ctx, "email", "to", msg.To.Address, "subject", msg.Subject, "body", msg.Body)
+	return nil
+}
+
+// =============================================================================
+
+// SMTPConfig represents the settings for sending emails through an SMTP
+// server. The username and password are only used when a username is set.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     mail.Address
+}
+
+// SMTP sends emails through an SMTP server.
+type SMTP struct {
+	cfg SMTPConfig
+}
+
+// NewSMTP constructs a sender for the SMTP server.
+func NewSMTP(cfg SMTPConfig) *SMTP {
+	return &SMTP{
+		cfg: cfg,
+	}
+}
+
+// Send sends the email as plain text. The smtp package doesn't take a
+// context, so the call can't be cancelled once it's made.
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+
+	if err := smtp.SendMail(addr, auth, s.cfg.From.Address, []string{msg.To.Address}, s.encode(msg)); err != nil {
+		return fmt.Errorf("sendmail: to[%s]: %w", msg.To.Address, err)
+	}
+
+	return nil
+}
+
+// encode returns the message with its headers. Line breaks are removed from
+// the subject so it can't add headers of its own.
+func (s *SMTP) encode(msg Message) []byte {
+	subject := strings.NewReplacer("\r", "", "\n", "").Replace(msg.Subject)
+
+	var b strings.Builder
+	b.WriteString("From: " + s.cfg.From.String() + "\r\n")
+	b.WriteString("To: " + msg.To.String() + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("UTF-8", subject) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return []byte(b.String())
+}
@@ -1,77 +1,342 @@
 // Package keystore implements the auth.KeyLookup interface. This implements
-// an in-memory keystore for JWT support.
+// an in-memory keystore for JWT support that can be loaded from files, the
+// environment or a secret manager. Keys can be rotated: the newest key that
+// is active signs the tokens, and every key that hasn't expired verifies
+// them.
 package keystore
 
 import (
 	"bytes"
+	"context"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ardanlabs/encore/foundation/clock"
+)
+
+// Set of error variables for looking up keys.
+var (
+	ErrNotFound  = errors.New("kid lookup failed")
+	ErrExpired   = errors.New("key expired")
+	ErrNotActive = errors.New("key not active yet")
+	ErrNoActive  = errors.New("no active key")
 )
 
-// key represents key information.
+// key represents key information. The private key is parsed once when it's
+// loaded so signing doesn't have to parse it again. A key signs tokens from
+// the time it's active from, and verifies them until it expires. A zero
+// expiry means the key doesn't expire.
 type key struct {
+	private    *rsa.PrivateKey
 	privatePEM string
 	publicPEM  string
+	activeFrom time.Time
+	expires    time.Time
+	order      int
+}
+
+// expired reports if the key can no longer be used at the time.
+func (k key) expired(now time.Time) bool {
+	return !k.expires.IsZero() && !now.Before(k.expires)
 }
 
 // KeyStore represents an in memory store implementation of the
-// KeyLookup interface for use with the auth package.
+// KeyLookup interface for use with the auth package. It holds any number of
+// keys by their kid so keys can be rotated.
 type KeyStore struct {
-	store map[string]key
+	mu     sync.RWMutex
+	store  map[string]key
+	clock  clock.Clock
+	loaded int
 }
 
 // New constructs an empty KeyStore ready for use.
 func New() *KeyStore {
+	return NewWithClock(clock.System{})
+}
+
+// NewWithClock constructs an empty KeyStore that checks if keys are active
+// or expired with the clock.
+func NewWithClock(clock clock.Clock) *KeyStore {
 	return &KeyStore{
 		store: make(map[string]key),
+		clock: clock,
 	}
 }
 
 // LoadKey takes an id and the private PEM string.
 func (ks *KeyStore) LoadKey(id string, pem string) error {
-	privatePEM := string(pem)
-	publicPEM, err := toPublicPEM(privatePEM)
+	private, err := parsePrivatePEM(pem)
+	if err != nil {
+		return fmt.Errorf("parsing private PEM: %w", err)
+	}
+
+	publicPEM, err := toPublicPEM(private)
 	if err != nil {
 		return fmt.Errorf("converting private PEM to public: %w", err)
 	}
 
-	key := key{
-		privatePEM: privatePEM,
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.loaded++
+
+	ks.store[id] = key{
+		private:    private,
+		privatePEM: pem,
 		publicPEM:  publicPEM,
+		order:      ks.loaded,
 	}
 
-	ks.store[id] = key
-
 	return nil
 }
 
-// PrivateKey searches the key store for a given kid and returns the private key.
-func (ks *KeyStore) PrivateKey(kid string) (string, error) {
+// SetValidity sets when the key starts signing tokens and when it expires.
+// A key that isn't active yet can already verify tokens, so it can be
+// published before it's used. A zero expiry means the key doesn't expire.
+// Keys are active from when they're loaded until this is called.
+func (ks *KeyStore) SetValidity(kid string, activeFrom time.Time, expires time.Time) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
 	key, found := ks.store[kid]
 	if !found {
-		return "", errors.New("kid lookup failed")
+		return fmt.Errorf("kid[%s]: %w", kid, ErrNotFound)
+	}
+
+	key.activeFrom = activeFrom
+	key.expires = expires
+	ks.store[kid] = key
+
+	return nil
+}
+
+// ActiveKID returns the kid of the newest key that is active and hasn't
+// expired, which is the key tokens should be signed with. Keys that became
+// active at the same time are ordered by when they were loaded.
+func (ks *KeyStore) ActiveKID() (string, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	now := ks.clock.Now()
+
+	var activeKID string
+	var active key
+	for kid, k := range ks.store {
+		if k.activeFrom.After(now) || k.expired(now) {
+			continue
+		}
+
+		newer := k.activeFrom.After(active.activeFrom) ||
+			(k.activeFrom.Equal(active.activeFrom) && k.order > active.order)
+
+		if activeKID == "" || newer {
+			activeKID = kid
+			active = k
+		}
+	}
+
+	if activeKID == "" {
+		return "", ErrNoActive
+	}
+
+	return activeKID, nil
+}
+
+// LoadByFS loads every .pem file in the file system, using the name of the
+// file without the extension as the kid. It returns the number of keys that
+// were loaded.
+func (ks *KeyStore) LoadByFS(fsys fs.FS) (int, error) {
+	var loaded int
+
+	fn := func(fileName string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("walkdir failure: %w", err)
+		}
+
+		if d.IsDir() || path.Ext(fileName) != ".pem" {
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, fileName)
+		if err != nil {
+			return fmt.Errorf("reading private key: %w", err)
+		}
+
+		kid := strings.TrimSuffix(path.Base(fileName), ".pem")
+		if err := ks.LoadKey(kid, string(data)); err != nil {
+			return fmt.Errorf("loading key[%s]: %w", kid, err)
+		}
+
+		loaded++
+
+		return nil
+	}
+
+	if err := fs.WalkDir(fsys, ".", fn); err != nil {
+		return 0, fmt.Errorf("walking directory: %w", err)
+	}
+
+	return loaded, nil
+}
+
+// LoadByEnv loads every environment variable that starts with the prefix,
+// using the rest of the name as the kid and the value as the private PEM.
+// It returns the number of keys that were loaded.
+func (ks *KeyStore) LoadByEnv(prefix string) (int, error) {
+	var loaded int
+
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+
+		kid, found := strings.CutPrefix(name, prefix)
+		if !found || kid == "" {
+			continue
+		}
+
+		if err := ks.LoadKey(kid, value); err != nil {
+			return 0, fmt.Errorf("loading key[%s]: %w", kid, err)
+		}
+
+		loaded++
+	}
+
+	return loaded, nil
+}
+
+// SecretManager declares the behavior for looking up a secret by name from
+// a store like Vault.
+type SecretManager interface {
+	Secret(ctx context.Context, name string) (string, error)
+}
+
+// LoadBySecrets loads the private PEM for each kid from the secret manager,
+// using the kid as the name of the secret.
+func (ks *KeyStore) LoadBySecrets(ctx context.Context, sm SecretManager, kids ...string) error {
+	for _, kid := range kids {
+		pem, err := sm.Secret(ctx, kid)
+		if err != nil {
+			return fmt.Errorf("fetching secret[%s]: %w", kid, err)
+		}
+
+		if err := ks.LoadKey(kid, pem); err != nil {
+			return fmt.Errorf("loading key[%s]: %w", kid, err)
+		}
+	}
+
+	return nil
+}
+
+// KIDs returns the kids of the keys in the store.
+func (ks *KeyStore) KIDs() []string {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	kids := make([]string, 0, len(ks.store))
+	for kid := range ks.store {
+		kids = append(kids, kid)
+	}
+
+	return kids
+}
+
+// PrivateKey searches the key store for a given kid and returns the private
+// key. Only a key that is active can sign tokens.
+func (ks *KeyStore) PrivateKey(kid string) (string, error) {
+	key, err := ks.signing(kid)
+	if err != nil {
+		return "", err
 	}
 
 	return key.privatePEM, nil
 }
 
-// PublicKey searches the key store for a given kid and returns the public key.
+// PublicKey searches the key store for a given kid and returns the public
+// key. Any key that hasn't expired can verify tokens.
 func (ks *KeyStore) PublicKey(kid string) (string, error) {
+	key, err := ks.lookup(kid)
+	if err != nil {
+		return "", err
+	}
+
+	return key.publicPEM, nil
+}
+
+// RSAPrivateKey searches the key store for a given kid and returns the
+// parsed private key. Only a key that is active can sign tokens.
+func (ks *KeyStore) RSAPrivateKey(kid string) (*rsa.PrivateKey, error) {
+	key, err := ks.signing(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	return key.private, nil
+}
+
+// RSAPublicKeys returns the public keys that haven't expired by their kid,
+// which is the set of keys tokens can be verified with.
+func (ks *KeyStore) RSAPublicKeys() map[string]*rsa.PublicKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	now := ks.clock.Now()
+
+	keys := make(map[string]*rsa.PublicKey, len(ks.store))
+	for kid, k := range ks.store {
+		if !k.expired(now) {
+			keys[kid] = &k.private.PublicKey
+		}
+	}
+
+	return keys
+}
+
+// lookup returns the key for the kid as long as it hasn't expired.
+func (ks *KeyStore) lookup(kid string) (key, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
 	key, found := ks.store[kid]
 	if !found {
-		return "", errors.New("kid lookup failed")
+		return key, ErrNotFound
 	}
 
-	return key.publicPEM, nil
+	if key.expired(ks.clock.Now()) {
+		return key, fmt.Errorf("kid[%s]: %w", kid, ErrExpired)
+	}
+
+	return key, nil
 }
 
-func toPublicPEM(privatePEM string) (string, error) {
+// signing returns the key for the kid as long as it can sign tokens.
+func (ks *KeyStore) signing(kid string) (key, error) {
+	key, err := ks.lookup(kid)
+	if err != nil {
+		return key, err
+	}
+
+	if key.activeFrom.After(ks.clock.Now()) {
+		return key, fmt.Errorf("kid[%s]: %w", kid, ErrNotActive)
+	}
+
+	return key, nil
+}
+
+// =============================================================================
+
+func parsePrivatePEM(privatePEM string) (*rsa.PrivateKey, error) {
 	block, _ := pem.Decode([]byte(privatePEM))
 	if block == nil {
-		return "", errors.New("invalid key: Key must be a PEM encoded PKCS1 or PKCS8 key")
+		return nil, errors.New("invalid key: Key must be a PEM encoded PKCS1 or PKCS8 key")
 	}
 
 	var parsedKey any
@@ -79,15 +344,19 @@ func toPublicPEM(privatePEM string) (string, error) {
 	if err != nil {
 		parsedKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 	}
 
 	pk, ok := parsedKey.(*rsa.PrivateKey)
 	if !ok {
-		return "", errors.New("key is not a valid RSA private key")
+		return nil, errors.New("key is not a valid RSA private key")
 	}
 
+	return pk, nil
+}
+
+func toPublicPEM(pk *rsa.PrivateKey) (string, error) {
 	asn1Bytes, err := x509.MarshalPKIXPublicKey(&pk.PublicKey)
 	if err != nil {
 		return "", fmt.Errorf("marshaling public key: %w", err)
@@ -0,0 +1,293 @@
+package keystore_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ardanlabs/encore/foundation/clock"
+	"github.com/ardanlabs/encore/foundation/keystore"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Load(t *testing.T) {
+	privatePEM := newPrivatePEM(t)
+
+	tests := []struct {
+		name string
+		load func(ks *keystore.KeyStore) error
+		kids []string
+	}{
+		{
+			name: "fs",
+			load: func(ks *keystore.KeyStore) error {
+				fsys := fstest.MapFS{
+					"keys/k1.pem":    {Data: []byte(privatePEM)},
+					"keys/k2.pem":    {Data: []byte(privatePEM)},
+					"keys/README.md": {Data: []byte("not a key")},
+				}
+
+				n, err := ks.LoadByFS(fsys)
+				if err != nil {
+					return err
+				}
+
+				if n != 2 {
+					return fmt.Errorf("loaded %d keys, exp 2", n)
+				}
+
+				return nil
+			},
+			kids: []string{"k1", "k2"},
+		},
+		{
+			name: "env",
+			load: func(ks *keystore.KeyStore) error {
+				t.Setenv("KEYSTORE_TEST_k1", privatePEM)
+				t.Setenv("KEYSTORE_TEST_", privatePEM)
+
+				n, err := ks.LoadByEnv("KEYSTORE_TEST_")
+				if err != nil {
+					return err
+				}
+
+				if n != 1 {
+					return fmt.Errorf("loaded %d keys, exp 1", n)
+				}
+
+				return nil
+			},
+			kids: []string{"k1"},
+		},
+		{
+			name: "secrets",
+			load: func(ks *keystore.KeyStore) error {
+				sm := secrets{"k1": privatePEM, "k2": privatePEM, "k3": privatePEM}
+
+				return ks.LoadBySecrets(context.Background(), sm, "k1", "k2")
+			},
+			kids: []string{"k1", "k2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ks := keystore.New()
+
+			if err := tt.load(ks); err != nil {
+				t.Fatalf("Should be able to load the keys: %s", err)
+			}
+
+			kids := ks.KIDs()
+			slices.Sort(kids)
+
+			if diff := cmp.Diff(kids, tt.kids); diff != "" {
+				t.Fatalf("Should get the kids loaded: %s", diff)
+			}
+
+			for _, kid := range kids {
+				if _, err := ks.PublicKey(kid); err != nil {
+					t.Fatalf("Should be able to get the public key for %s: %s", kid, err)
+				}
+			}
+		})
+	}
+}
+
+func Test_LoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		load func(ks *keystore.KeyStore) error
+	}{
+		{
+			name: "bad-pem",
+			load: func(ks *keystore.KeyStore) error {
+				return ks.LoadKey("k1", "not a pem")
+			},
+		},
+		{
+			name: "bad-file",
+			load: func(ks *keystore.KeyStore) error {
+				_, err := ks.LoadByFS(fstest.MapFS{"k1.pem": {Data: []byte("not a pem")}})
+				return err
+			},
+		},
+		{
+			name: "missing-secret",
+			load: func(ks *keystore.KeyStore) error {
+				return ks.LoadBySecrets(context.Background(), secrets{}, "k1")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.load(keystore.New()); err == nil {
+				t.Fatalf("Should get an error loading the key")
+			}
+		})
+	}
+}
+
+func Test_Rotation(t *testing.T) {
+	privatePEM := newPrivatePEM(t)
+
+	now := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		validity  map[string][2]time.Time
+		advance   time.Duration
+		activeKID string
+		activeErr error
+		expired   []string
+	}{
+		{
+			name:      "newest-loaded",
+			activeKID: "k2",
+		},
+		{
+			name: "newest-active",
+			validity: map[string][2]time.Time{
+				"k1": {now, time.Time{}},
+				"k2": {now.Add(-time.Hour), time.Time{}},
+			},
+			activeKID: "k1",
+		},
+		{
+			name: "not-active-yet",
+			validity: map[string][2]time.Time{
+				"k2": {now.Add(time.Hour), time.Time{}},
+			},
+			activeKID: "k1",
+		},
+		{
+			name: "activates",
+			validity: map[string][2]time.Time{
+				"k2": {now.Add(time.Hour), time.Time{}},
+			},
+			advance:   time.Hour,
+			activeKID: "k2",
+		},
+		{
+			name: "expired",
+			validity: map[string][2]time.Time{
+				"k2": {time.Time{}, now.Add(time.Hour)},
+			},
+			advance:   time.Hour,
+			activeKID: "k1",
+			expired:   []string{"k2"},
+		},
+		{
+			name: "none-active",
+			validity: map[string][2]time.Time{
+				"k1": {time.Time{}, now},
+				"k2": {now.Add(time.Hour), time.Time{}},
+			},
+			activeErr: keystore.ErrNoActive,
+			expired:   []string{"k1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewFrozen(now)
+
+			ks := keystore.NewWithClock(clk)
+			for _, kid := range []string{"k1", "k2"} {
+				if err := ks.LoadKey(kid, privatePEM); err != nil {
+					t.Fatalf("Should be able to load key %s: %s", kid, err)
+				}
+			}
+
+			for kid, v := range tt.validity {
+				if err := ks.SetValidity(kid, v[0], v[1]); err != nil {
+					t.Fatalf("Should be able to set the validity of %s: %s", kid, err)
+				}
+			}
+
+			clk.Advance(tt.advance)
+
+			kid, err := ks.ActiveKID()
+			if !errors.Is(err, tt.activeErr) {
+				t.Fatalf("Should get the active kid error: got %v, exp %v", err, tt.activeErr)
+			}
+
+			if kid != tt.activeKID {
+				t.Fatalf("Should get the active kid: got %q, exp %q", kid, tt.activeKID)
+			}
+
+			for _, kid := range tt.expired {
+				if _, err := ks.PublicKey(kid); !errors.Is(err, keystore.ErrExpired) {
+					t.Fatalf("Should not verify with expired key %s: %v", kid, err)
+				}
+
+				if _, exists := ks.RSAPublicKeys()[kid]; exists {
+					t.Fatalf("Should not publish expired key %s", kid)
+				}
+			}
+		})
+	}
+}
+
+func Test_SetValidity(t *testing.T) {
+	privatePEM := newPrivatePEM(t)
+
+	now := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	ks := keystore.NewWithClock(clock.NewFrozen(now))
+	if err := ks.LoadKey("k1", privatePEM); err != nil {
+		t.Fatalf("Should be able to load the key: %s", err)
+	}
+
+	if err := ks.SetValidity("unknown", now, time.Time{}); !errors.Is(err, keystore.ErrNotFound) {
+		t.Fatalf("Should not set the validity of an unknown key: %v", err)
+	}
+
+	if err := ks.SetValidity("k1", now.Add(time.Hour), time.Time{}); err != nil {
+		t.Fatalf("Should be able to set the validity: %s", err)
+	}
+
+	if _, err := ks.PrivateKey("k1"); !errors.Is(err, keystore.ErrNotActive) {
+		t.Fatalf("Should not sign with a key that isn't active yet: %v", err)
+	}
+
+	if _, err := ks.PublicKey("k1"); err != nil {
+		t.Fatalf("Should verify with a key that isn't active yet: %s", err)
+	}
+}
+
+// =============================================================================
+
+type secrets map[string]string
+
+func (s secrets) Secret(ctx context.Context, name string) (string, error) {
+	v, exists := s[name]
+	if !exists {
+		return "", fmt.Errorf("secret[%s] not found", name)
+	}
+
+	return v, nil
+}
+
+func newPrivatePEM(t *testing.T) string {
+	pk, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Should be able to generate a key: %s", err)
+	}
+
+	block := pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(pk),
+	}
+
+	return string(pem.EncodeToMemory(&block))
+}
@@ -3,24 +3,57 @@ package logger
 
 import (
 	"context"
+	"sync/atomic"
 
 	"encore.dev/rlog"
 )
 
 // Logger represents a logger for logging information.
 type Logger struct {
-	handler rlog.Ctx
-	events  Events
+	handler     Handler
+	events      Events
+	requestIDFn RequestIDFn
+	level       atomic.Int64
 }
 
 // New constructs a new log for application use.
 func New(serviceName string) *Logger {
-	return new(serviceName, Events{})
+	return new(RLog(serviceName), Events{}, nil)
 }
 
 // NewWithEvents constructs a new log for application use with events.
 func NewWithEvents(serviceName string, events Events) *Logger {
-	return new(serviceName, events)
+	return new(RLog(serviceName), events, nil)
+}
+
+// NewWithRequestID constructs a new log for application use that adds the
+// ID of the request to every log entry.
+func NewWithRequestID(serviceName string, events Events, requestIDFn RequestIDFn) *Logger {
+	return new(RLog(serviceName), events, requestIDFn)
+}
+
+// NewWithHandler constructs a new log that writes to the specified handler.
+// This allows the business layer to be used and tested without the encore
+// runtime, like with a *slog.Logger as the handler.
+func NewWithHandler(handler Handler, events Events, requestIDFn RequestIDFn) *Logger {
+	return new(handler, events, requestIDFn)
+}
+
+// RLog returns the encore structured logger as a handler, with the name of
+// the service added to every log entry.
+func RLog(serviceName string) Handler {
+	return rlog.With("service", serviceName)
+}
+
+// SetLevel changes the minimum level that is logged. Entries below the
+// level are dropped, which allows the verbosity to be changed at runtime.
+func (log *Logger) SetLevel(level Level) {
+	log.level.Store(int64(level))
+}
+
+// Level returns the minimum level that is logged.
+func (log *Logger) Level() Level {
+	return Level(log.level.Load())
 }
 
 // Debug logs at LevelDebug with the given context.
@@ -66,6 +99,16 @@ func (log *Logger) Errorc(ctx context.Context, caller int, msg string, args ...a
 // The caller parameter is being used for backwards compatibility support with
 // the service project. At this time in encore we can't use it. :(
 func (log *Logger) write(ctx context.Context, level Level, caller int, msg string, args ...any) {
+	if level < log.Level() {
+		return
+	}
+
+	if log.requestIDFn != nil {
+		if id := log.requestIDFn(ctx); id != "" {
+			args = append(args, "request_id", id)
+		}
+	}
+
 	switch level {
 	case LevelDebug:
 		log.handler.Debug(msg, args...)
@@ -93,9 +136,14 @@ func (log *Logger) write(ctx context.Context, level Level, caller int, msg strin
 	}
 }
 
-func new(serviceName string, events Events) *Logger {
-	return &Logger{
-		handler: rlog.With("service", serviceName),
-		events:  events,
+func new(handler Handler, events Events, requestIDFn RequestIDFn) *Logger {
+	log := Logger{
+		handler:     handler,
+		events:      events,
+		requestIDFn: requestIDFn,
 	}
+
+	log.SetLevel(LevelDebug)
+
+	return &log
 }
@@ -2,6 +2,8 @@ package logger
 
 import (
 	"context"
+	"fmt"
+	"strings"
 )
 
 // Level represents a logging level.
@@ -15,9 +17,54 @@ const (
 	LevelError Level = 8
 )
 
+// String returns the name of the level.
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// ParseLevel parses the name of a level.
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+
+	return 0, fmt.Errorf("unknown level %q", name)
+}
+
+// Handler declares the behavior of the log system the entries are written to.
+// Both the encore rlog.Ctx and *slog.Logger implement it.
+type Handler interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
 // EventFn is a function to be executed when configured against a log level.
 type EventFn func(ctx context.Context, msg string, args ...any)
 
+// RequestIDFn is a function that returns the ID of the request being
+// processed from the context.
+type RequestIDFn func(ctx context.Context) string
+
 // Events contains an assignment of an event function to a log level.
 type Events struct {
 	Debug EventFn
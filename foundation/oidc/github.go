@@ -0,0 +1,98 @@
+package oidc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// The endpoints GitHub is called on.
+const (
+	githubAuthURL  = "https://github.com/login/oauth/authorize"
+	githubTokenURL = "https://github.com/login/oauth/access_token"
+	githubAPIURL   = "https://api.github.com"
+)
+
+// GitHub represents GitHub as a provider. GitHub doesn't issue ID tokens, so
+// the user is read from its API with the access token and the nonce isn't
+// used.
+type GitHub struct {
+	cfg    Config
+	client *http.Client
+}
+
+// NewGitHub constructs the provider for logging in with GitHub.
+func NewGitHub(cfg Config) *GitHub {
+	return &GitHub{
+		cfg:    cfg,
+		client: newClient(),
+	}
+}
+
+// Name returns the name the provider is known by.
+func (p *GitHub) Name() string {
+	return "github"
+}
+
+// AuthCodeURL returns the url the user is sent to for logging in.
+func (p *GitHub) AuthCodeURL(ctx context.Context, state string, nonce string, verifier string) (string, error) {
+	values := url.Values{
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {"read:user user:email"},
+		"state":                 {state},
+		"code_challenge":        {challenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	return authURL(githubAuthURL, values)
+}
+
+// Identify trades the code for an access token and returns the user it
+// belongs to. The email is the user's primary email, which is only verified
+// when GitHub says it is.
+func (p *GitHub) Identify(ctx context.Context, code string, nonce string, verifier string) (Identity, error) {
+	tkns, err := exchange(ctx, p.client, githubTokenURL, p.cfg, code, verifier)
+	if err != nil {
+		return Identity{}, fmt.Errorf("exchange: %w", err)
+	}
+
+	var usr struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := get(ctx, p.client, githubAPIURL+"/user", tkns.AccessToken, &usr); err != nil {
+		return Identity{}, fmt.Errorf("get user: %w", err)
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := get(ctx, p.client, githubAPIURL+"/user/emails", tkns.AccessToken, &emails); err != nil {
+		return Identity{}, fmt.Errorf("get emails: %w", err)
+	}
+
+	ident := Identity{
+		Subject: strconv.FormatInt(usr.ID, 10),
+		Name:    usr.Name,
+	}
+
+	if ident.Name == "" {
+		ident.Name = usr.Login
+	}
+
+	for _, e := range emails {
+		if e.Primary {
+			ident.Email = e.Email
+			ident.EmailVerified = e.Verified
+			break
+		}
+	}
+
+	return ident, nil
+}
@@ -0,0 +1,177 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/encore/foundation/oidc"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const clientID = "encore"
+
+func Test_OpenID(t *testing.T) {
+	ctx := context.Background()
+
+	idp := newProvider(t)
+	defer idp.srv.Close()
+
+	p := oidc.NewOpenID("test", idp.srv.URL, oidc.Config{ClientID: clientID, RedirectURL: "http://localhost/callback"})
+
+	authURL, err := p.AuthCodeURL(ctx, "state", "nonce", "verifier")
+	if err != nil {
+		t.Fatalf("Should be able to build the auth code url: %s", err)
+	}
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("Should be able to parse the auth code url: %s", err)
+	}
+
+	if u.Query().Get("state") != "state" || u.Query().Get("nonce") != "nonce" {
+		t.Fatalf("Should send the state and nonce to the provider, got %q", u.RawQuery)
+	}
+
+	sum := sha256.Sum256([]byte("verifier"))
+	if u.Query().Get("code_challenge") != base64.RawURLEncoding.EncodeToString(sum[:]) || u.Query().Get("code_challenge_method") != "S256" {
+		t.Fatalf("Should send the PKCE challenge to the provider, got %q", u.RawQuery)
+	}
+
+	idp.claims = claims(idp.srv.URL, clientID, "nonce")
+
+	ident, err := p.Identify(ctx, "code", "nonce", "verifier")
+	if err != nil {
+		t.Fatalf("Should be able to identify the user: %s", err)
+	}
+
+	if idp.verifier != "verifier" {
+		t.Fatalf("Should send the PKCE verifier with the code, got %q", idp.verifier)
+	}
+
+	if ident.Subject != "1234" || ident.Email != "bill@example.com" || !ident.EmailVerified {
+		t.Fatalf("Should get the user from the id token, got %+v", ident)
+	}
+}
+
+func Test_OpenIDInvalid(t *testing.T) {
+	ctx := context.Background()
+
+	idp := newProvider(t)
+	defer idp.srv.Close()
+
+	p := oidc.NewOpenID("test", idp.srv.URL, oidc.Config{ClientID: clientID})
+
+	table := []struct {
+		name   string
+		claims jwt.MapClaims
+	}{
+		{"nonce", claims(idp.srv.URL, clientID, "other")},
+		{"audience", claims(idp.srv.URL, "other", "nonce")},
+		{"issuer", claims("http://other", clientID, "nonce")},
+		{"expired", func() jwt.MapClaims {
+			c := claims(idp.srv.URL, clientID, "nonce")
+			c["exp"] = time.Now().Add(-time.Minute).Unix()
+			return c
+		}()},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			idp.claims = tt.claims
+
+			_, err := p.Identify(ctx, "code", "nonce", "verifier")
+			if !errors.Is(err, oidc.ErrInvalidToken) {
+				t.Fatalf("Should not accept the id token, got %v", err)
+			}
+		})
+	}
+}
+
+// =============================================================================
+
+type provider struct {
+	srv      *httptest.Server
+	key      *rsa.PrivateKey
+	claims   jwt.MapClaims
+	verifier string
+}
+
+func newProvider(t *testing.T) *provider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Should be able to generate a key: %s", err)
+	}
+
+	p := provider{
+		key: key,
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.srv.URL,
+			"authorization_endpoint": p.srv.URL + "/authorize",
+			"token_endpoint":         p.srv.URL + "/token",
+			"jwks_uri":               p.srv.URL + "/keys",
+		})
+	})
+
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "k1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		p.verifier = r.PostFormValue("code_verifier")
+
+		tkn := jwt.NewWithClaims(jwt.SigningMethodRS256, p.claims)
+		tkn.Header["kid"] = "k1"
+
+		idToken, err := tkn.SignedString(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "access",
+			"id_token":     idToken,
+		})
+	})
+
+	p.srv = httptest.NewServer(mux)
+
+	return &p
+}
+
+func claims(issuer string, audience string, nonce string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            issuer,
+		"aud":            audience,
+		"sub":            "1234",
+		"exp":            time.Now().Add(time.Minute).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          nonce,
+		"email":          "bill@example.com",
+		"email_verified": true,
+	}
+}
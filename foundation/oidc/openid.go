@@ -0,0 +1,255 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// OpenID represents a provider that implements OpenID Connect. Its endpoints
+// are discovered from the issuer the first time they're needed and its keys
+// are fetched again when a token is signed with a key that isn't known yet.
+type OpenID struct {
+	name   string
+	issuer string
+	cfg    Config
+	client *http.Client
+
+	mu   sync.Mutex
+	meta *metadata
+	keys map[string]*rsa.PublicKey
+}
+
+// NewOpenID constructs a provider for the issuer that is known by the name.
+func NewOpenID(name string, issuer string, cfg Config) *OpenID {
+	return &OpenID{
+		name:   name,
+		issuer: strings.TrimSuffix(issuer, "/"),
+		cfg:    cfg,
+		client: newClient(),
+	}
+}
+
+// NewGoogle constructs the provider for logging in with Google.
+func NewGoogle(cfg Config) *OpenID {
+	return NewOpenID("google", "https://accounts.google.com", cfg)
+}
+
+// Name returns the name the provider is known by.
+func (p *OpenID) Name() string {
+	return p.name
+}
+
+// AuthCodeURL returns the url the user is sent to for logging in. The nonce
+// comes back in the ID token, which ties the token to this login.
+func (p *OpenID) AuthCodeURL(ctx context.Context, state string, nonce string, verifier string) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", fmt.Errorf("discover: %w", err)
+	}
+
+	values := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {"openid email profile"},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {challenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	return authURL(meta.AuthorizationEndpoint, values)
+}
+
+// Identify trades the code for the tokens of the user and returns the user
+// from the ID token once it's verified.
+func (p *OpenID) Identify(ctx context.Context, code string, nonce string, verifier string) (Identity, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return Identity{}, fmt.Errorf("discover: %w", err)
+	}
+
+	tkns, err := exchange(ctx, p.client, meta.TokenEndpoint, p.cfg, code, verifier)
+	if err != nil {
+		return Identity{}, fmt.Errorf("exchange: %w", err)
+	}
+
+	if tkns.IDToken == "" {
+		return Identity{}, fmt.Errorf("%w: no id token", ErrInvalidToken)
+	}
+
+	claims, err := p.verify(ctx, meta, tkns.IDToken, nonce)
+	if err != nil {
+		return Identity{}, fmt.Errorf("verify: %w", err)
+	}
+
+	ident := Identity{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}
+
+	return ident, nil
+}
+
+// =============================================================================
+
+// metadata represents the part of the discovery document that is used.
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// idClaims represents the claims of an ID token.
+type idClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+}
+
+// discover returns the metadata of the issuer, fetching it the first time.
+func (p *OpenID) discover(ctx context.Context) (metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.meta != nil {
+		return *p.meta, nil
+	}
+
+	var meta metadata
+	if err := get(ctx, p.client, p.issuer+"/.well-known/openid-configuration", "", &meta); err != nil {
+		return metadata{}, fmt.Errorf("get: %w", err)
+	}
+
+	// The document has to be the issuer's own, or it could point the
+	// tokens at someone else.
+	if meta.Issuer != p.issuer {
+		return metadata{}, fmt.Errorf("issuer mismatch: expected[%s] got[%s]", p.issuer, meta.Issuer)
+	}
+
+	p.meta = &meta
+
+	return meta, nil
+}
+
+// verify checks the signature of the ID token along with who issued it, who
+// it was issued for and the nonce of the login, and returns its claims.
+func (p *OpenID) verify(ctx context.Context, meta metadata, idToken string, nonce string) (idClaims, error) {
+	keyFunc := func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, meta, kid)
+	}
+
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Name}))
+
+	var claims idClaims
+	if _, err := parser.ParseWithClaims(idToken, &claims, keyFunc); err != nil {
+		return idClaims{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	if claims.Issuer != meta.Issuer {
+		return idClaims{}, fmt.Errorf("%w: issuer[%s]", ErrInvalidToken, claims.Issuer)
+	}
+
+	if !claims.VerifyAudience(p.cfg.ClientID, true) {
+		return idClaims{}, fmt.Errorf("%w: audience%v", ErrInvalidToken, claims.Audience)
+	}
+
+	if claims.Nonce != nonce {
+		return idClaims{}, fmt.Errorf("%w: nonce mismatch", ErrInvalidToken)
+	}
+
+	return claims, nil
+}
+
+// key returns the public key for the kid. The keys are fetched again when the
+// kid isn't known, since providers rotate their keys. A token without a kid
+// can only be verified when the provider has a single key.
+func (p *OpenID) key(ctx context.Context, meta metadata, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	lookup := func() (*rsa.PublicKey, bool) {
+		if kid == "" && len(p.keys) == 1 {
+			for _, key := range p.keys {
+				return key, true
+			}
+		}
+		key, exists := p.keys[kid]
+		return key, exists
+	}
+
+	if key, exists := lookup(); exists {
+		return key, nil
+	}
+
+	keys, err := p.fetchKeys(ctx, meta.JWKSURI)
+	if err != nil {
+		return nil, fmt.Errorf("fetch keys: %w", err)
+	}
+	p.keys = keys
+
+	key, exists := lookup()
+	if !exists {
+		return nil, fmt.Errorf("kid[%s] not found", kid)
+	}
+
+	return key, nil
+}
+
+// jwk represents a key in the key set of a provider.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// fetchKeys returns the RSA signing keys of the provider by their kid.
+func (p *OpenID) fetchKeys(ctx context.Context, jwksURI string) (map[string]*rsa.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := get(ctx, p.client, jwksURI, "", &set); err != nil {
+		return nil, fmt.Errorf("get: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("decode modulus: kid[%s]: %w", k.Kid, err)
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("decode exponent: kid[%s]: %w", k.Kid, err)
+		}
+
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	return keys, nil
+}
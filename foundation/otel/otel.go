@@ -0,0 +1,79 @@
+// Package otel provides support for OpenTelemetry tracing so the traces for
+// a request can be viewed outside of Encore's built-in tracing.
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the name of the tracer used for every span this package
+// adds.
+const tracerName = "github.com/ardanlabs/encore"
+
+// Config defines the information needed to export traces.
+type Config struct {
+	ServiceName string
+	Host        string
+	Probability float64
+}
+
+// InitTracing configures OpenTelemetry to export spans to the OTLP collector
+// at the host over HTTP. When no host is configured nothing is exported and
+// adding a span costs nothing. The returned function flushes the spans that
+// haven't been exported yet and must be called on shutdown.
+func InitTracing(ctx context.Context, cfg Config) (func(ctx context.Context) error, error) {
+	if cfg.Host == "" {
+		return func(ctx context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(
+		ctx,
+		otlptracehttp.WithEndpoint(cfg.Host),
+		otlptracehttp.WithInsecure(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating exporter: %w", err)
+	}
+
+	res := resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	)
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Probability))),
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return tp.Shutdown, nil
+}
+
+// AddSpan starts a span as a child of the span in the context, the caller
+// must end the span.
+func AddSpan(ctx context.Context, spanName string, keyValues ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, spanName, trace.WithAttributes(keyValues...))
+}
+
+// RecordError marks the span as failed with the error.
+func RecordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,109 @@
+package web
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// flushEvery is the number of lines or records written before they are
+// flushed to the client.
+const flushEvery = 100
+
+// NDJSON writes values as newline delimited JSON, flushing them to the
+// client as they are written so a client can process them as they arrive.
+type NDJSON struct {
+	w io.Writer
+	n int
+}
+
+// NewNDJSON constructs an encoder that writes to w.
+func NewNDJSON(w io.Writer) *NDJSON {
+	return &NDJSON{
+		w: w,
+	}
+}
+
+// Encode writes the value as a line of JSON.
+func (e *NDJSON) Encode(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	return e.WriteLine(data)
+}
+
+// WriteLine writes data that is already JSON as a line.
+func (e *NDJSON) WriteLine(data []byte) error {
+	if _, err := e.w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	if e.n++; e.n%flushEvery == 0 {
+		Flush(e.w)
+	}
+
+	return nil
+}
+
+// Flush sends the lines written so far to the client.
+func (e *NDJSON) Flush() {
+	Flush(e.w)
+}
+
+// =============================================================================
+
+// CSV writes records as CSV, with each value written in its default format.
+type CSV struct {
+	w  io.Writer
+	cw *csv.Writer
+}
+
+// NewCSV constructs an encoder that writes to w.
+func NewCSV(w io.Writer) *CSV {
+	return &CSV{
+		w:  w,
+		cw: csv.NewWriter(w),
+	}
+}
+
+// Write writes the values as a record. Values that a spreadsheet would
+// treat as a formula are prefixed with a quote so they are shown as text.
+func (e *CSV) Write(values []any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = escapeFormula(fmt.Sprint(v))
+	}
+
+	return e.cw.Write(record)
+}
+
+// Flush sends the records written so far to the client.
+func (e *CSV) Flush() error {
+	e.cw.Flush()
+	if err := e.cw.Error(); err != nil {
+		return err
+	}
+
+	Flush(e.w)
+
+	return nil
+}
+
+// escapeFormula prefixes a value that starts like a formula with a quote.
+// Numbers are left as is so negative values stay numbers.
+func escapeFormula(value string) string {
+	if value == "" || !strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return value
+	}
+
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+
+	return "'" + value
+}
@@ -0,0 +1,85 @@
+// Package web provides support for writing raw endpoints, which handle the
+// request and response themselves instead of having encore encode them.
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// Set of content types written by the helpers.
+const (
+	ContentTypeJSON   = "application/json"
+	ContentTypeNDJSON = "application/x-ndjson"
+	ContentTypeCSV    = "text/csv"
+)
+
+// ErrBodyTooLarge is returned when a request body is larger than the limit
+// it's decoded with.
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// Respond writes the data as JSON with the status code.
+func Respond(w http.ResponseWriter, statusCode int, data any) error {
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		return fmt.Errorf("respond: %w", err)
+	}
+
+	return nil
+}
+
+// Decode reads the JSON request body into the value pointed to by v. A body
+// larger than maxBytes isn't read past the limit and ErrBodyTooLarge is
+// returned.
+func Decode(w http.ResponseWriter, r *http.Request, maxBytes int64, v any) error {
+	body := http.MaxBytesReader(w, r.Body, maxBytes)
+
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		var mbErr *http.MaxBytesError
+		if errors.As(err, &mbErr) {
+			return fmt.Errorf("%w: exceeds the limit of %d bytes", ErrBodyTooLarge, maxBytes)
+		}
+
+		return fmt.Errorf("decode: %w", err)
+	}
+
+	return nil
+}
+
+// Flush sends any buffered data to the client when the writer supports it.
+func Flush(w io.Writer) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// =============================================================================
+
+// ErrorHandler writes an error to the response of a raw endpoint.
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+
+var errorHandler atomic.Pointer[ErrorHandler]
+
+// SetErrorHandler sets how errors are written by Error. It's meant to be
+// called when a service starts so the errors of raw endpoints are written
+// the same way as the errors of the other endpoints.
+func SetErrorHandler(eh ErrorHandler) {
+	errorHandler.Store(&eh)
+}
+
+// Error writes the error with the handler that was set, or as a plain
+// internal server error when none was.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	if eh := errorHandler.Load(); eh != nil {
+		(*eh)(w, r, err)
+		return
+	}
+
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
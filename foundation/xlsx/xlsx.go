@@ -0,0 +1,204 @@
+// Package xlsx provides support for writing Excel workbooks. A workbook with
+// a single sheet is written as rows are added so large results don't need to
+// be held in memory.
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContentType is the media type for an Excel workbook.
+const ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// ErrClosed is returned when a row is written after the workbook is closed.
+var ErrClosed = errors.New("workbook is closed")
+
+// Writer writes a workbook with a single sheet.
+type Writer struct {
+	zw     *zip.Writer
+	sheet  *bufio.Writer
+	rows   int
+	closed bool
+}
+
+// NewWriter constructs a writer for a workbook with a single sheet using the
+// specified name. The parts of the workbook that come before the rows are
+// written immediately.
+func NewWriter(w io.Writer, sheetName string) (*Writer, error) {
+	zw := zip.NewWriter(w)
+
+	parts := []struct {
+		name string
+		data string
+	}{
+		{"[Content_Types].xml", contentTypes},
+		{"_rels/.rels", rootRels},
+		{"xl/workbook.xml", fmt.Sprintf(workbook, escape(sheetName))},
+		{"xl/_rels/workbook.xml.rels", workbookRels},
+	}
+
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, fmt.Errorf("create part[%s]: %w", part.name, err)
+		}
+
+		if _, err := io.WriteString(f, part.data); err != nil {
+			return nil, fmt.Errorf("write part[%s]: %w", part.name, err)
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, fmt.Errorf("create sheet: %w", err)
+	}
+
+	sheet := bufio.NewWriter(f)
+	if _, err := sheet.WriteString(sheetStart); err != nil {
+		return nil, fmt.Errorf("write sheet: %w", err)
+	}
+
+	xw := Writer{
+		zw:    zw,
+		sheet: sheet,
+	}
+
+	return &xw, nil
+}
+
+// WriteRow adds a row to the sheet. Numbers are written as numeric cells
+// and every other value is written as text.
+func (w *Writer) WriteRow(values []any) error {
+	if w.closed {
+		return ErrClosed
+	}
+
+	w.rows++
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<row r="%d">`, w.rows)
+
+	for i, v := range values {
+		ref := columnName(i) + strconv.Itoa(w.rows)
+
+		switch n := number(v); {
+		case n != "":
+			fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, n)
+		default:
+			fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, escape(text(v)))
+		}
+	}
+
+	b.WriteString(`</row>`)
+
+	if _, err := w.sheet.WriteString(b.String()); err != nil {
+		return fmt.Errorf("write row: %w", err)
+	}
+
+	return nil
+}
+
+// Flush writes any buffered rows to the underlying writer.
+func (w *Writer) Flush() error {
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+
+	return w.zw.Flush()
+}
+
+// Close finishes the sheet and the workbook. It doesn't close the
+// underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+
+	if _, err := w.sheet.WriteString(sheetEnd); err != nil {
+		return fmt.Errorf("write sheet: %w", err)
+	}
+
+	if err := w.sheet.Flush(); err != nil {
+		return fmt.Errorf("flush sheet: %w", err)
+	}
+
+	return w.zw.Close()
+}
+
+// =============================================================================
+
+// columnName returns the spreadsheet name for the zero based column index,
+// ie 0 is A, 25 is Z and 26 is AA.
+func columnName(index int) string {
+	var name []byte
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = append([]byte{byte('A' + (index-1)%26)}, name...)
+	}
+
+	return string(name)
+}
+
+func number(v any) string {
+	switch n := v.(type) {
+	case int:
+		return strconv.Itoa(n)
+	case int32:
+		return strconv.FormatInt(int64(n), 10)
+	case int64:
+		return strconv.FormatInt(n, 10)
+	case float32:
+		return strconv.FormatFloat(float64(n), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+
+	return ""
+}
+
+func text(v any) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case time.Time:
+		return s.Format(time.RFC3339)
+	case fmt.Stringer:
+		return s.String()
+	}
+
+	return fmt.Sprint(v)
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// =============================================================================
+
+const contentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+
+const rootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+const workbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+const workbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+
+const sheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+
+const sheetEnd = `</sheetData></worksheet>`
@@ -0,0 +1,153 @@
+package xlsx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/encore/foundation/xlsx"
+)
+
+func Test_Parts(t *testing.T) {
+	var buf bytes.Buffer
+
+	xw, err := xlsx.NewWriter(&buf, "Sales & <Returns>")
+	if err != nil {
+		t.Fatalf("Should be able to construct a writer: %s", err)
+	}
+
+	if err := xw.WriteRow([]any{"name", "cost"}); err != nil {
+		t.Fatalf("Should be able to write a row: %s", err)
+	}
+
+	if err := xw.WriteRow([]any{"Bill & <Ted>", 10.5}); err != nil {
+		t.Fatalf("Should be able to write a row: %s", err)
+	}
+
+	if err := xw.Close(); err != nil {
+		t.Fatalf("Should be able to close the workbook: %s", err)
+	}
+
+	parts := unzip(t, buf.Bytes())
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		data, exists := parts[name]
+		if !exists {
+			t.Fatalf("Should find the %s part", name)
+		}
+
+		if err := wellFormed(data); err != nil {
+			t.Fatalf("Should get well formed XML for the %s part: %s", name, err)
+		}
+	}
+
+	if !strings.Contains(parts["xl/workbook.xml"], `name="Sales &amp; &lt;Returns&gt;"`) {
+		t.Fatalf("Should escape the sheet name: %s", parts["xl/workbook.xml"])
+	}
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+
+	for _, cell := range []string{
+		`<c r="A1" t="inlineStr"><is><t xml:space="preserve">name</t></is></c>`,
+		`<c r="A2" t="inlineStr"><is><t xml:space="preserve">Bill &amp; &lt;Ted&gt;</t></is></c>`,
+		`<c r="B2"><v>10.5</v></c>`,
+	} {
+		if !strings.Contains(sheet, cell) {
+			t.Fatalf("Should find %s in the sheet: %s", cell, sheet)
+		}
+	}
+}
+
+func Test_ColumnNames(t *testing.T) {
+	var buf bytes.Buffer
+
+	xw, err := xlsx.NewWriter(&buf, "Sheet")
+	if err != nil {
+		t.Fatalf("Should be able to construct a writer: %s", err)
+	}
+
+	values := make([]any, 53)
+	for i := range values {
+		values[i] = i
+	}
+
+	if err := xw.WriteRow(values); err != nil {
+		t.Fatalf("Should be able to write a row: %s", err)
+	}
+
+	if err := xw.Close(); err != nil {
+		t.Fatalf("Should be able to close the workbook: %s", err)
+	}
+
+	sheet := unzip(t, buf.Bytes())["xl/worksheets/sheet1.xml"]
+
+	for _, cell := range []string{
+		`<c r="A1"><v>0</v></c>`,
+		`<c r="Z1"><v>25</v></c>`,
+		`<c r="AA1"><v>26</v></c>`,
+		`<c r="AZ1"><v>51</v></c>`,
+		`<c r="BA1"><v>52</v></c>`,
+	} {
+		if !strings.Contains(sheet, cell) {
+			t.Fatalf("Should find %s in the sheet: %s", cell, sheet)
+		}
+	}
+}
+
+func Test_Closed(t *testing.T) {
+	xw, err := xlsx.NewWriter(io.Discard, "Sheet")
+	if err != nil {
+		t.Fatalf("Should be able to construct a writer: %s", err)
+	}
+
+	if err := xw.Close(); err != nil {
+		t.Fatalf("Should be able to close the workbook: %s", err)
+	}
+
+	if err := xw.WriteRow([]any{"a"}); !errors.Is(err, xlsx.ErrClosed) {
+		t.Fatalf("Should not be able to write after close: %v", err)
+	}
+}
+
+// =============================================================================
+
+func unzip(t *testing.T, data []byte) map[string]string {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Should be able to open the workbook: %s", err)
+	}
+
+	parts := make(map[string]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("Should be able to open the %s part: %s", f.Name, err)
+		}
+
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("Should be able to read the %s part: %s", f.Name, err)
+		}
+
+		parts[f.Name] = string(data)
+	}
+
+	return parts
+}
+
+func wellFormed(data string) error {
+	d := xml.NewDecoder(strings.NewReader(data))
+	for {
+		if _, err := d.Token(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}